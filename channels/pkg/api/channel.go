@@ -58,4 +58,28 @@ type AddonSpec struct {
 	// version of the software we are packaging.  But we always want to reinstall when we
 	// switch kubernetes versions.
 	Id string `json:"id,omitempty"`
+
+	// DependsOn lists the names of the addons which must be healthy before this addon is applied
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// Readiness lists the conditions which must hold for the addon to be healthy.
+	// If empty, the addon is healthy when the deployments and daemonsets matching Selector have rolled out.
+	Readiness []*ReadinessCheck `json:"readiness,omitempty"`
+}
+
+// ReadinessCheck is a condition on the objects of an addon, which must hold for the addon to be healthy
+type ReadinessCheck struct {
+	// Kind is the kind of the objects which are checked: Deployment, DaemonSet or Pod.
+	// Deployments and daemonsets must have rolled out; pods must be ready.
+	Kind string `json:"kind"`
+
+	// Namespace is the namespace of the objects; it defaults to the namespace of the addon
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name is the name of the object which is checked; if empty, all the objects matching Selector are checked
+	Name string `json:"name,omitempty"`
+
+	// Selector is a label query over the objects which are checked, when Name is not set.
+	// At least one object must match.
+	Selector map[string]string `json:"selector,omitempty"`
 }
@@ -22,6 +22,7 @@ import (
 
 	"github.com/golang/glog"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/kops/channels/pkg/api"
 )
@@ -106,7 +107,7 @@ func (a *Addon) GetRequiredUpdates(k8sClient kubernetes.Interface) (*AddonUpdate
 	}, nil
 }
 
-func (a *Addon) EnsureUpdated(k8sClient kubernetes.Interface) (*AddonUpdate, error) {
+func (a *Addon) EnsureUpdated(k8sClient kubernetes.Interface, dynamicClient dynamic.ClientPool) (*AddonUpdate, error) {
 	required, err := a.GetRequiredUpdates(k8sClient)
 	if err != nil {
 		return nil, err
@@ -129,7 +130,8 @@ func (a *Addon) EnsureUpdated(k8sClient kubernetes.Interface) (*AddonUpdate, err
 	}
 	glog.Infof("Applying update from %q", manifestURL)
 
-	err = Apply(manifestURL.String())
+	applier := NewApplier(k8sClient, dynamicClient)
+	err = applier.Apply(a.Name, stringValue(a.Spec.Version), manifestURL.String())
 	if err != nil {
 		return nil, fmt.Errorf("error applying update from %q: %v", manifest, err)
 	}
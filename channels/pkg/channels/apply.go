@@ -17,60 +17,404 @@ limitations under the License.
 package channels
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"os"
-	"os/exec"
-	"path"
+	"io"
+	"reflect"
 	"strings"
 
+	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/jsonmergepatch"
+	"k8s.io/apimachinery/pkg/util/mergepatch"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/kops/util/pkg/vfs"
 )
 
-// Apply calls kubectl apply to apply the manifest.
-// We will likely in future change this to create things directly (or more likely embed this logic into kubectl itself)
-func Apply(manifest string) error {
-	// We copy the manifest to a temp file because it is likely e.g. an s3 URL, which kubectl can't read
+const (
+	// LabelAddon is set on every object applied for an addon, to the name of the addon.
+	// It is used to find the objects which are no longer in the manifest of the addon, and must be pruned.
+	LabelAddon = "channels.kops.k8s.io/addon"
+
+	// AnnotationAddonVersion records the version of the addon which last applied the object.
+	// Only objects with the annotation are pruned, so objects which copy the labels of an addon object
+	// (e.g. the Endpoints of a Service) are left alone.
+	AnnotationAddonVersion = "channels.kops.k8s.io/version"
+)
+
+// Applier applies manifests directly through the API, in the same way as kubectl apply:
+// new objects are created, and existing objects are patched with a three-way merge of the
+// last applied configuration, the manifest and the live object.
+type Applier struct {
+	discovery discovery.DiscoveryInterface
+	dynamic   dynamic.ClientPool
+}
+
+// NewApplier builds an Applier
+func NewApplier(k8sClient kubernetes.Interface, dynamicClient dynamic.ClientPool) *Applier {
+	return &Applier{
+		discovery: k8sClient.Discovery(),
+		dynamic:   dynamicClient,
+	}
+}
+
+// objectKey identifies an object independently of the API group version through which it was read
+type objectKey struct {
+	resource  string
+	namespace string
+	name      string
+}
+
+// Apply applies the objects in the manifest, labelled as belonging to the addon, and then deletes
+// the objects belonging to the addon which are no longer in the manifest.
+func (a *Applier) Apply(addon string, version string, manifest string) error {
+	if errs := validation.IsValidLabelValue(addon); len(errs) != 0 {
+		return fmt.Errorf("addon name %q cannot be used as a label value: %s", addon, strings.Join(errs, ", "))
+	}
+
+	// We read the manifest through vfs because it is likely e.g. an s3 URL
 	data, err := vfs.Context.ReadFile(manifest)
 	if err != nil {
 		return fmt.Errorf("error reading manifest: %v", err)
 	}
 
-	tmpDir, err := ioutil.TempDir("", "channel")
+	objects, err := parseManifest(data)
+	if err != nil {
+		return fmt.Errorf("error parsing manifest %q: %v", manifest, err)
+	}
+
+	resources, err := a.serverResources()
 	if err != nil {
-		return fmt.Errorf("error creating temp dir: %v", err)
+		return err
 	}
 
-	defer func() {
-		if err := os.RemoveAll(tmpDir); err != nil {
-			glog.Warningf("error deleting temp dir %q: %v", tmpDir, err)
+	applied := make(map[objectKey]bool)
+	for _, obj := range objects {
+		setAddon(obj, addon, version)
+
+		key, err := a.applyObject(resources, obj)
+		if err != nil {
+			return fmt.Errorf("error applying %s %q: %v", obj.GetKind(), obj.GetName(), err)
+		}
+		applied[key] = true
+	}
+
+	return a.prune(resources, addon, applied)
+}
+
+// parseManifest splits a manifest into its objects, expanding Lists
+func parseManifest(data []byte) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		var ext runtime.RawExtension
+		if err := decoder.Decode(&ext); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
 		}
-	}()
 
-	localManifestFile := path.Join(tmpDir, "manifest.yaml")
-	if err := ioutil.WriteFile(localManifestFile, data, 0600); err != nil {
-		return fmt.Errorf("error writing temp file: %v", err)
+		raw := bytes.TrimSpace(ext.Raw)
+		if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+			// Empty document, e.g. a trailing ---
+			continue
+		}
+
+		obj, _, err := unstructured.UnstructuredJSONScheme.Decode(raw, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		switch obj := obj.(type) {
+		case *unstructured.Unstructured:
+			objects = append(objects, obj)
+		case *unstructured.UnstructuredList:
+			for i := range obj.Items {
+				objects = append(objects, &obj.Items[i])
+			}
+		default:
+			return nil, fmt.Errorf("unexpected object type %T", obj)
+		}
+	}
+
+	for _, obj := range objects {
+		if obj.GetName() == "" {
+			return nil, fmt.Errorf("%s object does not have a name", obj.GetKind())
+		}
 	}
 
-	_, err = execKubectl("apply", "-f", localManifestFile)
-	return err
+	return objects, nil
 }
 
-func execKubectl(args ...string) (string, error) {
-	kubectlPath := "kubectl" // Assume in PATH
-	cmd := exec.Command(kubectlPath, args...)
-	env := os.Environ()
-	cmd.Env = env
+// setAddon marks the object as belonging to the version of the addon
+func setAddon(obj *unstructured.Unstructured, addon string, version string) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[LabelAddon] = addon
+	obj.SetLabels(labels)
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[AnnotationAddonVersion] = version
+	obj.SetAnnotations(annotations)
+}
 
-	human := strings.Join(cmd.Args, " ")
-	glog.V(2).Infof("Running command: %s", human)
-	output, err := cmd.CombinedOutput()
+// serverResources returns the resources supported by the server, by kind
+func (a *Applier) serverResources() (map[schema.GroupVersionKind]*metav1.APIResource, error) {
+	lists, err := a.discovery.ServerResources()
 	if err != nil {
-		glog.Infof("error running %s", human)
-		glog.Info(string(output))
-		return string(output), fmt.Errorf("error running kubectl")
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return nil, fmt.Errorf("error querying server resources: %v", err)
+		}
+		// An unavailable aggregated API should not block all addons
+		glog.Warningf("ignoring API groups which could not be queried: %v", err)
+	}
+
+	resources := make(map[schema.GroupVersionKind]*metav1.APIResource)
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			glog.Warningf("ignoring resources with unparseable group version %q", list.GroupVersion)
+			continue
+		}
+		for i := range list.APIResources {
+			resource := list.APIResources[i]
+			if strings.Contains(resource.Name, "/") {
+				// Subresource
+				continue
+			}
+			resource.Group = gv.Group
+			resource.Version = gv.Version
+			resources[gv.WithKind(resource.Kind)] = &resource
+		}
+	}
+	return resources, nil
+}
+
+// applyObject creates the object, or patches it if it already exists
+func (a *Applier) applyObject(resources map[schema.GroupVersionKind]*metav1.APIResource, obj *unstructured.Unstructured) (objectKey, error) {
+	gvk := obj.GroupVersionKind()
+	resource := resources[gvk]
+	if resource == nil {
+		return objectKey{}, fmt.Errorf("kind %q is not supported by the server", gvk)
+	}
+
+	namespace := ""
+	if resource.Namespaced {
+		namespace = obj.GetNamespace()
+		if namespace == "" {
+			namespace = metav1.NamespaceDefault
+			obj.SetNamespace(namespace)
+		}
+	} else {
+		obj.SetNamespace("")
 	}
+	key := objectKey{resource: resource.Name, namespace: namespace, name: obj.GetName()}
 
-	return string(output), err
+	modified, err := setLastApplied(obj)
+	if err != nil {
+		return key, err
+	}
+
+	client, err := a.dynamic.ClientForGroupVersionKind(gvk)
+	if err != nil {
+		return key, fmt.Errorf("error building client: %v", err)
+	}
+	rc := client.Resource(resource, namespace)
+
+	existing, err := rc.Get(obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return key, err
+		}
+
+		glog.V(2).Infof("creating %s %s", gvk.Kind, describeKey(key))
+		if _, err := rc.Create(obj); err != nil {
+			return key, err
+		}
+		return key, nil
+	}
+
+	current, err := existing.MarshalJSON()
+	if err != nil {
+		return key, fmt.Errorf("error serializing existing object: %v", err)
+	}
+	original := []byte(existing.GetAnnotations()[v1.LastAppliedConfigAnnotation])
+
+	preconditions := []mergepatch.PreconditionFunc{
+		mergepatch.RequireKeyUnchanged("apiVersion"),
+		mergepatch.RequireKeyUnchanged("kind"),
+		mergepatch.RequireMetadataKeyUnchanged("name"),
+	}
+	patch, err := jsonmergepatch.CreateThreeWayJSONMergePatch(original, modified, current, preconditions...)
+	if err != nil {
+		return key, fmt.Errorf("error building patch: %v", err)
+	}
+	unchanged, err := isNoopPatch(current, patch)
+	if err != nil {
+		return key, err
+	}
+	if unchanged {
+		glog.V(4).Infof("%s %s is unchanged", gvk.Kind, describeKey(key))
+		return key, nil
+	}
+
+	glog.V(2).Infof("patching %s %s", gvk.Kind, describeKey(key))
+	if _, err := rc.Patch(obj.GetName(), types.MergePatchType, patch); err != nil {
+		return key, err
+	}
+	return key, nil
+}
+
+// isNoopPatch returns true if the merge patch does not change the object.  The three-way merge can return
+// a patch with empty maps, e.g. {"data":{}}, when the live object has fields which are not in the manifest.
+func isNoopPatch(current []byte, patch []byte) (bool, error) {
+	if string(patch) == "{}" {
+		return true, nil
+	}
+
+	patched, err := jsonpatch.MergePatch(current, patch)
+	if err != nil {
+		return false, fmt.Errorf("error applying patch: %v", err)
+	}
+
+	var before, after interface{}
+	if err := json.Unmarshal(current, &before); err != nil {
+		return false, fmt.Errorf("error parsing object: %v", err)
+	}
+	if err := json.Unmarshal(patched, &after); err != nil {
+		return false, fmt.Errorf("error parsing patched object: %v", err)
+	}
+	return reflect.DeepEqual(before, after), nil
+}
+
+// setLastApplied records the configuration in the same annotation as kubectl apply, so that objects
+// which were previously applied with kubectl are patched correctly.  It returns the object as JSON.
+func setLastApplied(obj *unstructured.Unstructured) ([]byte, error) {
+	annotations := obj.GetAnnotations()
+	delete(annotations, v1.LastAppliedConfigAnnotation)
+	obj.SetAnnotations(annotations)
+
+	lastApplied, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("error serializing object: %v", err)
+	}
+
+	annotations[v1.LastAppliedConfigAnnotation] = string(lastApplied)
+	obj.SetAnnotations(annotations)
+
+	modified, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("error serializing object: %v", err)
+	}
+	return modified, nil
+}
+
+// prune deletes the objects belonging to the addon which were not applied
+func (a *Applier) prune(resources map[schema.GroupVersionKind]*metav1.APIResource, addon string, applied map[objectKey]bool) error {
+	listOptions := metav1.ListOptions{LabelSelector: LabelAddon + "=" + addon}
+	propagationPolicy := metav1.DeletePropagationBackground
+
+	for _, resource := range prunableResources(resources) {
+		gvk := schema.GroupVersionKind{Group: resource.Group, Version: resource.Version, Kind: resource.Kind}
+		client, err := a.dynamic.ClientForGroupVersionKind(gvk)
+		if err != nil {
+			return fmt.Errorf("error building client: %v", err)
+		}
+
+		list, err := client.Resource(resource, metav1.NamespaceAll).List(listOptions)
+		if err != nil {
+			return fmt.Errorf("error listing %s: %v", resource.Name, err)
+		}
+		items, ok := list.(*unstructured.UnstructuredList)
+		if !ok {
+			return fmt.Errorf("unexpected list type %T", list)
+		}
+
+		for i := range items.Items {
+			item := &items.Items[i]
+			key := objectKey{resource: resource.Name, namespace: item.GetNamespace(), name: item.GetName()}
+			if !shouldPrune(item, key, applied) {
+				continue
+			}
+
+			glog.Infof("deleting %s %s, which is no longer part of addon %q", item.GetKind(), describeKey(key), addon)
+			err := client.Resource(resource, key.namespace).Delete(key.name, &metav1.DeleteOptions{PropagationPolicy: &propagationPolicy})
+			if err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("error deleting %s %s: %v", item.GetKind(), describeKey(key), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// prunableResources returns one version of each resource which can be listed and deleted
+func prunableResources(resources map[schema.GroupVersionKind]*metav1.APIResource) []*metav1.APIResource {
+	byGroupResource := make(map[schema.GroupResource]*metav1.APIResource)
+	for _, resource := range resources {
+		if !hasVerb(resource, "list") || !hasVerb(resource, "delete") {
+			continue
+		}
+		gr := schema.GroupResource{Group: resource.Group, Resource: resource.Name}
+		if existing := byGroupResource[gr]; existing != nil && existing.Version < resource.Version {
+			// Pick a version deterministically
+			continue
+		}
+		byGroupResource[gr] = resource
+	}
+
+	var prunable []*metav1.APIResource
+	for _, resource := range byGroupResource {
+		prunable = append(prunable, resource)
+	}
+	return prunable
+}
+
+func hasVerb(resource *metav1.APIResource, verb string) bool {
+	for _, v := range resource.Verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldPrune returns true if the object was applied for the addon, but is not in the current manifest
+func shouldPrune(obj *unstructured.Unstructured, key objectKey, applied map[objectKey]bool) bool {
+	if applied[key] {
+		return false
+	}
+	if obj.GetDeletionTimestamp() != nil {
+		return false
+	}
+	if _, found := obj.GetAnnotations()[AnnotationAddonVersion]; !found {
+		return false
+	}
+	return true
+}
+
+func describeKey(key objectKey) string {
+	if key.namespace == "" {
+		return key.name
+	}
+	return key.namespace + "/" + key.name
 }
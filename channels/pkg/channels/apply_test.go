@@ -0,0 +1,462 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channels
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_ParseManifest(t *testing.T) {
+	manifest := `
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: kube-dns
+  namespace: kube-system
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: kube-dns
+    namespace: kube-system
+  data:
+    replicas: "2"
+- apiVersion: rbac.authorization.k8s.io/v1beta1
+  kind: ClusterRole
+  metadata:
+    name: kube-dns
+---
+`
+	objects, err := parseManifest([]byte(manifest))
+	if err != nil {
+		t.Fatalf("unexpected error parsing manifest: %v", err)
+	}
+
+	var actual []string
+	for _, obj := range objects {
+		actual = append(actual, obj.GetAPIVersion()+"/"+obj.GetKind()+"/"+obj.GetName())
+	}
+	expected := []string{
+		"v1/ServiceAccount/kube-dns",
+		"v1/ConfigMap/kube-dns",
+		"rbac.authorization.k8s.io/v1beta1/ClusterRole/kube-dns",
+	}
+	if strings.Join(actual, ",") != strings.Join(expected, ",") {
+		t.Fatalf("unexpected objects: %v", actual)
+	}
+}
+
+func Test_ParseManifest_RequiresName(t *testing.T) {
+	manifest := `
+apiVersion: v1
+kind: ConfigMap
+data:
+  a: b
+`
+	if _, err := parseManifest([]byte(manifest)); err == nil {
+		t.Fatalf("expected error parsing object without a name")
+	}
+}
+
+func Test_SetLastApplied(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetName("config")
+	setAddon(obj, "test.addons.k8s.io", "1.0.0")
+
+	modified, err := setLastApplied(obj)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Setting it twice must not nest the previous annotation
+	modified2, err := setLastApplied(obj)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(modified) != string(modified2) {
+		t.Fatalf("last applied configuration changed: %s vs %s", modified, modified2)
+	}
+
+	lastApplied := obj.GetAnnotations()[v1.LastAppliedConfigAnnotation]
+	var applied unstructured.Unstructured
+	if err := json.Unmarshal([]byte(lastApplied), &applied.Object); err != nil {
+		t.Fatalf("error parsing last applied configuration %q: %v", lastApplied, err)
+	}
+	if _, found := applied.GetAnnotations()[v1.LastAppliedConfigAnnotation]; found {
+		t.Fatalf("last applied configuration contains itself: %s", lastApplied)
+	}
+	if applied.GetLabels()[LabelAddon] != "test.addons.k8s.io" {
+		t.Fatalf("last applied configuration does not have the addon label: %s", lastApplied)
+	}
+	if applied.GetAnnotations()[AnnotationAddonVersion] != "1.0.0" {
+		t.Fatalf("last applied configuration does not have the addon version: %s", lastApplied)
+	}
+}
+
+func Test_ShouldPrune(t *testing.T) {
+	applied := map[objectKey]bool{
+		{resource: "configmaps", namespace: "kube-system", name: "kept"}: true,
+	}
+
+	grid := []struct {
+		Name        string
+		Annotations map[string]string
+		Deleting    bool
+		Expected    bool
+	}{
+		{
+			Name:        "kept",
+			Annotations: map[string]string{AnnotationAddonVersion: "1.0.0"},
+			Expected:    false,
+		},
+		{
+			Name:        "removed",
+			Annotations: map[string]string{AnnotationAddonVersion: "1.0.0"},
+			Expected:    true,
+		},
+		{
+			Name:        "deleting",
+			Annotations: map[string]string{AnnotationAddonVersion: "1.0.0"},
+			Deleting:    true,
+			Expected:    false,
+		},
+		{
+			// e.g. Endpoints, which copy the labels of their Service
+			Name:     "copied-labels",
+			Expected: false,
+		},
+	}
+	for _, g := range grid {
+		obj := &unstructured.Unstructured{}
+		obj.SetName(g.Name)
+		obj.SetNamespace("kube-system")
+		obj.SetAnnotations(g.Annotations)
+		if g.Deleting {
+			now := metav1.Now()
+			obj.SetDeletionTimestamp(&now)
+		}
+
+		key := objectKey{resource: "configmaps", namespace: "kube-system", name: g.Name}
+		actual := shouldPrune(obj, key, applied)
+		if actual != g.Expected {
+			t.Errorf("unexpected result for %q: got %v, expected %v", g.Name, actual, g.Expected)
+		}
+	}
+}
+
+func Test_PrunableResources(t *testing.T) {
+	listAndDelete := metav1.Verbs{"create", "delete", "get", "list"}
+	resources := map[schema.GroupVersionKind]*metav1.APIResource{
+		{Group: "extensions", Version: "v1beta1", Kind: "Deployment"}: {Name: "deployments", Group: "extensions", Version: "v1beta1", Kind: "Deployment", Verbs: listAndDelete},
+		{Group: "apps", Version: "v1beta1", Kind: "Deployment"}:       {Name: "deployments", Group: "apps", Version: "v1beta1", Kind: "Deployment", Verbs: listAndDelete},
+		{Group: "apps", Version: "v1beta2", Kind: "Deployment"}:       {Name: "deployments", Group: "apps", Version: "v1beta2", Kind: "Deployment", Verbs: listAndDelete},
+		{Group: "", Version: "v1", Kind: "Binding"}:                   {Name: "bindings", Version: "v1", Kind: "Binding", Verbs: metav1.Verbs{"create"}},
+	}
+
+	var actual []string
+	for _, resource := range prunableResources(resources) {
+		actual = append(actual, resource.Group+"/"+resource.Version+"/"+resource.Name)
+	}
+	if len(actual) != 2 {
+		t.Fatalf("expected one version of each prunable resource, got %v", actual)
+	}
+	for _, a := range actual {
+		if a != "extensions/v1beta1/deployments" && a != "apps/v1beta1/deployments" {
+			t.Errorf("unexpected prunable resource %q", a)
+		}
+	}
+}
+
+// fakeClientPool is an in-memory dynamic.ClientPool, which records the patches and deletions
+type fakeClientPool struct {
+	objects map[objectKey]*unstructured.Unstructured
+	patches map[objectKey][]string
+	deleted []objectKey
+}
+
+var _ dynamic.ClientPool = &fakeClientPool{}
+
+func newFakeClientPool() *fakeClientPool {
+	return &fakeClientPool{
+		objects: make(map[objectKey]*unstructured.Unstructured),
+		patches: make(map[objectKey][]string),
+	}
+}
+
+func (p *fakeClientPool) ClientForGroupVersionResource(resource schema.GroupVersionResource) (dynamic.Interface, error) {
+	return &fakeDynamicClient{pool: p}, nil
+}
+
+func (p *fakeClientPool) ClientForGroupVersionKind(kind schema.GroupVersionKind) (dynamic.Interface, error) {
+	return &fakeDynamicClient{pool: p}, nil
+}
+
+type fakeDynamicClient struct {
+	// Interface is nil; only Resource is implemented
+	dynamic.Interface
+	pool *fakeClientPool
+}
+
+func (c *fakeDynamicClient) Resource(resource *metav1.APIResource, namespace string) dynamic.ResourceInterface {
+	return &fakeResourceClient{pool: c.pool, resource: resource.Name, namespace: namespace}
+}
+
+type fakeResourceClient struct {
+	// ResourceInterface is nil; only the methods used by the Applier are implemented
+	dynamic.ResourceInterface
+	pool      *fakeClientPool
+	resource  string
+	namespace string
+}
+
+func (c *fakeResourceClient) key(name string) objectKey {
+	return objectKey{resource: c.resource, namespace: c.namespace, name: name}
+}
+
+func (c *fakeResourceClient) notFound(name string) error {
+	return errors.NewNotFound(schema.GroupResource{Resource: c.resource}, name)
+}
+
+func (c *fakeResourceClient) List(opts metav1.ListOptions) (runtime.Object, error) {
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	list := &unstructured.UnstructuredList{}
+	for key, obj := range c.pool.objects {
+		if key.resource != c.resource || (c.namespace != "" && key.namespace != c.namespace) {
+			continue
+		}
+		if !selector.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		list.Items = append(list.Items, *obj.DeepCopy())
+	}
+	return list, nil
+}
+
+func (c *fakeResourceClient) Get(name string, opts metav1.GetOptions) (*unstructured.Unstructured, error) {
+	obj := c.pool.objects[c.key(name)]
+	if obj == nil {
+		return nil, c.notFound(name)
+	}
+	return obj.DeepCopy(), nil
+}
+
+func (c *fakeResourceClient) Create(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	key := c.key(obj.GetName())
+	if c.pool.objects[key] != nil {
+		return nil, errors.NewAlreadyExists(schema.GroupResource{Resource: c.resource}, obj.GetName())
+	}
+	c.pool.objects[key] = obj.DeepCopy()
+	return obj, nil
+}
+
+func (c *fakeResourceClient) Patch(name string, pt types.PatchType, data []byte) (*unstructured.Unstructured, error) {
+	key := c.key(name)
+	obj := c.pool.objects[key]
+	if obj == nil {
+		return nil, c.notFound(name)
+	}
+	if pt != types.MergePatchType {
+		return nil, fmt.Errorf("unexpected patch type %q", pt)
+	}
+	current, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	patched, err := jsonpatch.MergePatch(current, data)
+	if err != nil {
+		return nil, err
+	}
+	result := &unstructured.Unstructured{}
+	if err := result.UnmarshalJSON(patched); err != nil {
+		return nil, err
+	}
+	c.pool.objects[key] = result
+	c.pool.patches[key] = append(c.pool.patches[key], string(data))
+	return result, nil
+}
+
+func (c *fakeResourceClient) Delete(name string, opts *metav1.DeleteOptions) error {
+	key := c.key(name)
+	if c.pool.objects[key] == nil {
+		return c.notFound(name)
+	}
+	delete(c.pool.objects, key)
+	c.pool.deleted = append(c.pool.deleted, key)
+	return nil
+}
+
+func Test_Apply(t *testing.T) {
+	dir, err := ioutil.TempDir("", "channels-apply")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	v1Manifest := filepath.Join(dir, "v1.yaml")
+	v2Manifest := filepath.Join(dir, "v2.yaml")
+	manifests := map[string]string{
+		v1Manifest: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: kube-dns
+  namespace: kube-system
+data:
+  replicas: "2"
+  stale: "true"
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: kube-dns
+  namespace: kube-system
+`,
+		v2Manifest: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: kube-dns
+  namespace: kube-system
+data:
+  replicas: "3"
+`,
+	}
+	for p, manifest := range manifests {
+		if err := ioutil.WriteFile(p, []byte(manifest), 0644); err != nil {
+			t.Fatalf("error writing manifest: %v", err)
+		}
+	}
+
+	discovery := fake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	verbs := metav1.Verbs{"create", "delete", "get", "list", "patch"}
+	discovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Namespaced: true, Kind: "ConfigMap", Verbs: verbs},
+				{Name: "serviceaccounts", Namespaced: true, Kind: "ServiceAccount", Verbs: verbs},
+			},
+		},
+	}
+	pool := newFakeClientPool()
+	applier := &Applier{discovery: discovery, dynamic: pool}
+
+	configMap := objectKey{resource: "configmaps", namespace: "kube-system", name: "kube-dns"}
+	serviceAccount := objectKey{resource: "serviceaccounts", namespace: "kube-system", name: "kube-dns"}
+
+	if err := applier.Apply("kube-dns.addons.k8s.io", "1.0.0", v1Manifest); err != nil {
+		t.Fatalf("unexpected error applying v1: %v", err)
+	}
+	if pool.objects[configMap] == nil || pool.objects[serviceAccount] == nil {
+		t.Fatalf("expected the objects to be created, got %v", pool.objects)
+	}
+	if len(pool.patches) != 0 {
+		t.Fatalf("expected no patches when creating the objects, got %v", pool.patches)
+	}
+
+	// The last applied configuration round trips: it is the object as applied, without the annotation itself
+	live := pool.objects[configMap]
+	var lastApplied unstructured.Unstructured
+	if err := json.Unmarshal([]byte(live.GetAnnotations()[v1.LastAppliedConfigAnnotation]), &lastApplied.Object); err != nil {
+		t.Fatalf("error parsing last applied configuration: %v", err)
+	}
+	expected := live.DeepCopy()
+	annotations := expected.GetAnnotations()
+	delete(annotations, v1.LastAppliedConfigAnnotation)
+	expected.SetAnnotations(annotations)
+	if !reflect.DeepEqual(lastApplied.Object, expected.Object) {
+		t.Fatalf("last applied configuration %v does not match the object %v", lastApplied.Object, expected.Object)
+	}
+
+	// A field set by something else must be kept by the three-way merge
+	unstructured.SetNestedField(live.Object, "live", "data", "extra")
+
+	if err := applier.Apply("kube-dns.addons.k8s.io", "2.0.0", v2Manifest); err != nil {
+		t.Fatalf("unexpected error applying v2: %v", err)
+	}
+
+	patches := pool.patches[configMap]
+	if len(patches) != 1 {
+		t.Fatalf("expected one patch of the ConfigMap, got %v", patches)
+	}
+	var patch map[string]interface{}
+	if err := json.Unmarshal([]byte(patches[0]), &patch); err != nil {
+		t.Fatalf("error parsing patch %q: %v", patches[0], err)
+	}
+	expectedData := map[string]interface{}{"replicas": "3", "stale": nil}
+	if data := patch["data"]; !reflect.DeepEqual(data, expectedData) {
+		t.Errorf("expected the patch to change the data to %v, got %v", expectedData, data)
+	}
+	metadata, _ := patch["metadata"].(map[string]interface{})
+	patchAnnotations, _ := metadata["annotations"].(map[string]interface{})
+	if patchAnnotations[AnnotationAddonVersion] != "2.0.0" {
+		t.Errorf("expected the patch to set the addon version, got %s", patches[0])
+	}
+	if _, found := patchAnnotations[v1.LastAppliedConfigAnnotation]; !found {
+		t.Errorf("expected the patch to set the last applied configuration, got %s", patches[0])
+	}
+
+	data, _ := unstructured.NestedStringMap(pool.objects[configMap].Object, "data")
+	if !reflect.DeepEqual(data, map[string]string{"replicas": "3", "extra": "live"}) {
+		t.Errorf("unexpected data after applying v2: %v", data)
+	}
+
+	// The ServiceAccount is no longer in the manifest, so it is pruned
+	if !reflect.DeepEqual(pool.deleted, []objectKey{serviceAccount}) {
+		t.Errorf("expected the ServiceAccount to be deleted, got %v", pool.deleted)
+	}
+	if pool.objects[serviceAccount] != nil {
+		t.Errorf("expected the ServiceAccount to be removed")
+	}
+
+	// Applying the same manifest again does not change anything
+	if err := applier.Apply("kube-dns.addons.k8s.io", "2.0.0", v2Manifest); err != nil {
+		t.Fatalf("unexpected error applying v2 again: %v", err)
+	}
+	if len(pool.patches[configMap]) != 1 || len(pool.deleted) != 1 {
+		t.Errorf("expected no changes when applying v2 again, got patches %v and deletions %v", pool.patches, pool.deleted)
+	}
+}
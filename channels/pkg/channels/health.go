@@ -0,0 +1,219 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channels
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	extensions "k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kops/channels/pkg/api"
+)
+
+// healthPollInterval is the interval at which we check the health of an addon
+var healthPollInterval = 5 * time.Second
+
+// waitFor polls check until it reports nothing pending, or the timeout expires.
+// With a zero timeout, check is called only once.
+func waitFor(timeout time.Duration, check func() ([]string, error)) error {
+	var pending []string
+	condition := func() (bool, error) {
+		var err error
+		pending, err = check()
+		if err != nil {
+			return false, err
+		}
+		for _, p := range pending {
+			glog.V(2).Infof("waiting for %s", p)
+		}
+		return len(pending) == 0, nil
+	}
+
+	var err error
+	if timeout == 0 {
+		// wait.Poll treats a zero timeout as no timeout
+		var done bool
+		done, err = condition()
+		if err == nil && !done {
+			err = wait.ErrWaitTimeout
+		}
+	} else {
+		err = wait.PollImmediate(healthPollInterval, timeout, condition)
+	}
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("not healthy: %s", strings.Join(pending, "; "))
+	}
+	return err
+}
+
+// WaitForHealthy waits until the addon is healthy, as defined by its readiness checks
+func (a *Addon) WaitForHealthy(k8sClient kubernetes.Interface, timeout time.Duration) error {
+	return waitFor(timeout, func() ([]string, error) {
+		return a.healthPending(k8sClient)
+	})
+}
+
+// healthPending returns a description of each readiness check of the addon which does not hold.
+// Without readiness checks, the workloads matching the selector of the addon must have rolled out.
+func (a *Addon) healthPending(k8sClient kubernetes.Interface) ([]string, error) {
+	if len(a.Spec.Readiness) == 0 {
+		if len(a.Spec.Selector) == 0 {
+			return nil, nil
+		}
+		return rolloutPending(k8sClient, a.Spec.Selector)
+	}
+
+	namespace := a.buildChannel().Namespace
+
+	var pending []string
+	for _, check := range a.Spec.Readiness {
+		p, err := readinessPending(k8sClient, namespace, check)
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, p...)
+	}
+	return pending, nil
+}
+
+// readinessPending evaluates a readiness check, returning a description of each object which is not ready
+func readinessPending(k8sClient kubernetes.Interface, defaultNamespace string, check *api.ReadinessCheck) ([]string, error) {
+	namespace := check.Namespace
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+
+	if check.Name == "" && len(check.Selector) == 0 {
+		return nil, fmt.Errorf("readiness check for %s must specify name or selector", check.Kind)
+	}
+	options := metav1.ListOptions{LabelSelector: labels.SelectorFromSet(check.Selector).String()}
+
+	var pending []string
+	switch check.Kind {
+	case "Deployment":
+		var deployments []extensions.Deployment
+		if check.Name != "" {
+			d, err := k8sClient.ExtensionsV1beta1().Deployments(namespace).Get(check.Name, metav1.GetOptions{})
+			if err != nil {
+				if errors.IsNotFound(err) {
+					return []string{fmt.Sprintf("deployment %s/%s: not found", namespace, check.Name)}, nil
+				}
+				return nil, fmt.Errorf("error getting deployment %s/%s: %v", namespace, check.Name, err)
+			}
+			deployments = append(deployments, *d)
+		} else {
+			list, err := k8sClient.ExtensionsV1beta1().Deployments(namespace).List(options)
+			if err != nil {
+				return nil, fmt.Errorf("error listing deployments: %v", err)
+			}
+			deployments = list.Items
+		}
+		for i := range deployments {
+			d := &deployments[i]
+			if msg := deploymentRolloutPending(d); msg != "" {
+				pending = append(pending, fmt.Sprintf("deployment %s/%s: %s", d.Namespace, d.Name, msg))
+			}
+		}
+		if len(deployments) == 0 {
+			pending = append(pending, fmt.Sprintf("no deployments in %s matching %s", namespace, options.LabelSelector))
+		}
+
+	case "DaemonSet":
+		var daemonSets []extensions.DaemonSet
+		if check.Name != "" {
+			ds, err := k8sClient.ExtensionsV1beta1().DaemonSets(namespace).Get(check.Name, metav1.GetOptions{})
+			if err != nil {
+				if errors.IsNotFound(err) {
+					return []string{fmt.Sprintf("daemonset %s/%s: not found", namespace, check.Name)}, nil
+				}
+				return nil, fmt.Errorf("error getting daemonset %s/%s: %v", namespace, check.Name, err)
+			}
+			daemonSets = append(daemonSets, *ds)
+		} else {
+			list, err := k8sClient.ExtensionsV1beta1().DaemonSets(namespace).List(options)
+			if err != nil {
+				return nil, fmt.Errorf("error listing daemonsets: %v", err)
+			}
+			daemonSets = list.Items
+		}
+		for i := range daemonSets {
+			ds := &daemonSets[i]
+			if msg := daemonSetRolloutPending(ds); msg != "" {
+				pending = append(pending, fmt.Sprintf("daemonset %s/%s: %s", ds.Namespace, ds.Name, msg))
+			}
+		}
+		if len(daemonSets) == 0 {
+			pending = append(pending, fmt.Sprintf("no daemonsets in %s matching %s", namespace, options.LabelSelector))
+		}
+
+	case "Pod":
+		var pods []v1.Pod
+		if check.Name != "" {
+			pod, err := k8sClient.CoreV1().Pods(namespace).Get(check.Name, metav1.GetOptions{})
+			if err != nil {
+				if errors.IsNotFound(err) {
+					return []string{fmt.Sprintf("pod %s/%s: not found", namespace, check.Name)}, nil
+				}
+				return nil, fmt.Errorf("error getting pod %s/%s: %v", namespace, check.Name, err)
+			}
+			pods = append(pods, *pod)
+		} else {
+			list, err := k8sClient.CoreV1().Pods(namespace).List(options)
+			if err != nil {
+				return nil, fmt.Errorf("error listing pods: %v", err)
+			}
+			pods = list.Items
+		}
+		running := 0
+		for i := range pods {
+			pod := &pods[i]
+			if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+				// Completed pods (e.g. of a job) are not expected to be ready
+				continue
+			}
+			running++
+			if !isPodReady(pod) {
+				pending = append(pending, fmt.Sprintf("pod %s/%s: not ready", pod.Namespace, pod.Name))
+			}
+		}
+		if running == 0 && check.Name == "" {
+			pending = append(pending, fmt.Sprintf("no pods in %s matching %s", namespace, options.LabelSelector))
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported kind %q in readiness check (supported: Deployment, DaemonSet, Pod)", check.Kind)
+	}
+
+	return pending, nil
+}
+
+func isPodReady(pod *v1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channels
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	extensions "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kops/channels/pkg/api"
+)
+
+func pod(name string, phase v1.PodPhase, ready bool) *v1.Pod {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-system", Labels: map[string]string{"k8s-app": "kube-dns"}},
+		Status: v1.PodStatus{
+			Phase:      phase,
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+		},
+	}
+}
+
+func Test_HealthPending(t *testing.T) {
+	k8sClient := fake.NewSimpleClientset(
+		&extensions.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "kube-dns", Namespace: "kube-system"},
+			Spec:       extensions.DeploymentSpec{Replicas: int32p(2)},
+			Status:     extensions.DeploymentStatus{Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1},
+		},
+		pod("kube-dns-1", v1.PodRunning, true),
+		pod("kube-dns-2", v1.PodRunning, false),
+		pod("kube-dns-job", v1.PodSucceeded, false),
+	)
+
+	grid := []struct {
+		Readiness []*api.ReadinessCheck
+		Expected  string
+		Error     string
+	}{
+		{
+			Readiness: []*api.ReadinessCheck{{Kind: "Deployment", Name: "kube-dns"}},
+			Expected:  "deployment kube-system/kube-dns: 1 of 2 updated replicas available",
+		},
+		{
+			Readiness: []*api.ReadinessCheck{{Kind: "DaemonSet", Name: "kube-dns"}},
+			Expected:  "daemonset kube-system/kube-dns: not found",
+		},
+		{
+			Readiness: []*api.ReadinessCheck{{Kind: "Pod", Selector: map[string]string{"k8s-app": "kube-dns"}}},
+			Expected:  "pod kube-system/kube-dns-2: not ready",
+		},
+		{
+			Readiness: []*api.ReadinessCheck{{Kind: "Pod", Name: "kube-dns-1"}},
+			Expected:  "",
+		},
+		{
+			Readiness: []*api.ReadinessCheck{{Kind: "Pod", Namespace: "default", Selector: map[string]string{"k8s-app": "kube-dns"}}},
+			Expected:  "no pods in default matching k8s-app=kube-dns",
+		},
+		{
+			Readiness: []*api.ReadinessCheck{{Kind: "Service", Name: "kube-dns"}},
+			Error:     `unsupported kind "Service" in readiness check`,
+		},
+		{
+			Readiness: []*api.ReadinessCheck{{Kind: "Pod"}},
+			Error:     "readiness check for Pod must specify name or selector",
+		},
+	}
+	for _, g := range grid {
+		addon := buildAddon("kube-dns")
+		addon.Spec.Readiness = g.Readiness
+
+		pending, err := addon.healthPending(k8sClient)
+		if g.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), g.Error) {
+				t.Errorf("unexpected error for %v: got %v, expected %q", g.Readiness[0], err, g.Error)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for %v: %v", g.Readiness[0], err)
+			continue
+		}
+		if actual := strings.Join(pending, "; "); actual != g.Expected {
+			t.Errorf("unexpected result for %v: got %q, expected %q", g.Readiness[0], actual, g.Expected)
+		}
+	}
+}
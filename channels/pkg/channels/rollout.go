@@ -0,0 +1,113 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channels
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	extensions "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WaitForRollout waits until the deployments and daemonsets matching the selector have rolled out,
+// i.e. all their replicas are updated and available.  An empty selector matches nothing.
+func WaitForRollout(k8sClient kubernetes.Interface, selector map[string]string, timeout time.Duration) error {
+	if len(selector) == 0 {
+		return nil
+	}
+
+	return waitFor(timeout, func() ([]string, error) {
+		return rolloutPending(k8sClient, selector)
+	})
+}
+
+// rolloutPending returns a description of each deployment or daemonset matching the selector which has not rolled out
+func rolloutPending(k8sClient kubernetes.Interface, selector map[string]string) ([]string, error) {
+	options := metav1.ListOptions{LabelSelector: labels.SelectorFromSet(selector).String()}
+
+	var pending []string
+
+	deployments, err := k8sClient.ExtensionsV1beta1().Deployments(metav1.NamespaceAll).List(options)
+	if err != nil {
+		return nil, fmt.Errorf("error listing deployments: %v", err)
+	}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		if msg := deploymentRolloutPending(d); msg != "" {
+			pending = append(pending, fmt.Sprintf("deployment %s/%s: %s", d.Namespace, d.Name, msg))
+		}
+	}
+
+	daemonSets, err := k8sClient.ExtensionsV1beta1().DaemonSets(metav1.NamespaceAll).List(options)
+	if err != nil {
+		return nil, fmt.Errorf("error listing daemonsets: %v", err)
+	}
+	for i := range daemonSets.Items {
+		ds := &daemonSets.Items[i]
+		if msg := daemonSetRolloutPending(ds); msg != "" {
+			pending = append(pending, fmt.Sprintf("daemonset %s/%s: %s", ds.Namespace, ds.Name, msg))
+		}
+	}
+
+	sort.Strings(pending)
+	return pending, nil
+}
+
+// deploymentRolloutPending returns why the deployment has not rolled out, or "" if it has.
+// The checks are the same as kubectl rollout status.
+func deploymentRolloutPending(d *extensions.Deployment) string {
+	if d.Status.ObservedGeneration < d.Generation {
+		return "waiting for the deployment spec to be observed"
+	}
+
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	if d.Status.UpdatedReplicas < replicas {
+		return fmt.Sprintf("%d of %d replicas updated", d.Status.UpdatedReplicas, replicas)
+	}
+	if d.Status.Replicas > d.Status.UpdatedReplicas {
+		return fmt.Sprintf("%d old replicas pending termination", d.Status.Replicas-d.Status.UpdatedReplicas)
+	}
+	if d.Status.AvailableReplicas < d.Status.UpdatedReplicas {
+		return fmt.Sprintf("%d of %d updated replicas available", d.Status.AvailableReplicas, d.Status.UpdatedReplicas)
+	}
+	return ""
+}
+
+// daemonSetRolloutPending returns why the daemonset has not rolled out, or "" if it has
+func daemonSetRolloutPending(ds *extensions.DaemonSet) string {
+	if ds.Status.ObservedGeneration < ds.Generation {
+		return "waiting for the daemonset spec to be observed"
+	}
+
+	// Pods of an OnDelete daemonset are only updated when they are deleted, so we only check availability
+	if ds.Spec.UpdateStrategy.Type == extensions.RollingUpdateDaemonSetStrategyType {
+		if ds.Status.UpdatedNumberScheduled < ds.Status.DesiredNumberScheduled {
+			return fmt.Sprintf("%d of %d pods updated", ds.Status.UpdatedNumberScheduled, ds.Status.DesiredNumberScheduled)
+		}
+	}
+	if ds.Status.NumberAvailable < ds.Status.DesiredNumberScheduled {
+		return fmt.Sprintf("%d of %d pods available", ds.Status.NumberAvailable, ds.Status.DesiredNumberScheduled)
+	}
+	return ""
+}
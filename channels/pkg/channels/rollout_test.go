@@ -0,0 +1,156 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channels
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	extensions "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func int32p(v int32) *int32 {
+	return &v
+}
+
+func Test_WaitForRollout(t *testing.T) {
+	healthPollInterval = 10 * time.Millisecond
+	defer func() { healthPollInterval = 5 * time.Second }()
+
+	labels := map[string]string{"k8s-addon": "kube-dns.addons.k8s.io"}
+
+	readyDeployment := &extensions.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "kube-dns", Namespace: "kube-system", Labels: labels, Generation: 2},
+		Spec:       extensions.DeploymentSpec{Replicas: int32p(2)},
+		Status:     extensions.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+	}
+	rollingDeployment := &extensions.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "kube-dns-autoscaler", Namespace: "kube-system", Labels: labels, Generation: 2},
+		Spec:       extensions.DeploymentSpec{Replicas: int32p(2)},
+		Status:     extensions.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 2, AvailableReplicas: 2},
+	}
+	otherDeployment := &extensions.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "dashboard", Namespace: "kube-system", Generation: 2},
+		Spec:       extensions.DeploymentSpec{Replicas: int32p(1)},
+	}
+
+	{
+		k8sClient := fake.NewSimpleClientset(readyDeployment, otherDeployment)
+		if err := WaitForRollout(k8sClient, labels, time.Second); err != nil {
+			t.Fatalf("unexpected error waiting for ready deployment: %v", err)
+		}
+	}
+
+	{
+		k8sClient := fake.NewSimpleClientset(readyDeployment, rollingDeployment)
+		err := WaitForRollout(k8sClient, labels, 50*time.Millisecond)
+		if err == nil {
+			t.Fatalf("expected timeout waiting for rolling deployment")
+		}
+		if !strings.Contains(err.Error(), "kube-system/kube-dns-autoscaler: 1 old replicas pending termination") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	{
+		// An empty selector does not wait for anything
+		k8sClient := fake.NewSimpleClientset(rollingDeployment)
+		if err := WaitForRollout(k8sClient, nil, 50*time.Millisecond); err != nil {
+			t.Fatalf("unexpected error with empty selector: %v", err)
+		}
+	}
+}
+
+func Test_DeploymentRolloutPending(t *testing.T) {
+	grid := []struct {
+		Deployment extensions.Deployment
+		Expected   string
+	}{
+		{
+			Deployment: extensions.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+				Status:     extensions.DeploymentStatus{ObservedGeneration: 2},
+			},
+			Expected: "waiting for the deployment spec to be observed",
+		},
+		{
+			Deployment: extensions.Deployment{
+				Spec:   extensions.DeploymentSpec{Replicas: int32p(3)},
+				Status: extensions.DeploymentStatus{Replicas: 3, UpdatedReplicas: 1, AvailableReplicas: 3},
+			},
+			Expected: "1 of 3 replicas updated",
+		},
+		{
+			Deployment: extensions.Deployment{
+				Spec:   extensions.DeploymentSpec{Replicas: int32p(3)},
+				Status: extensions.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 2},
+			},
+			Expected: "2 of 3 updated replicas available",
+		},
+		{
+			// Replicas defaults to 1
+			Deployment: extensions.Deployment{
+				Status: extensions.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+			},
+			Expected: "",
+		},
+	}
+	for _, g := range grid {
+		actual := deploymentRolloutPending(&g.Deployment)
+		if actual != g.Expected {
+			t.Errorf("unexpected result for %v: got %q, expected %q", g.Deployment.Status, actual, g.Expected)
+		}
+	}
+}
+
+func Test_DaemonSetRolloutPending(t *testing.T) {
+	grid := []struct {
+		DaemonSet extensions.DaemonSet
+		Expected  string
+	}{
+		{
+			DaemonSet: extensions.DaemonSet{
+				Spec:   extensions.DaemonSetSpec{UpdateStrategy: extensions.DaemonSetUpdateStrategy{Type: extensions.RollingUpdateDaemonSetStrategyType}},
+				Status: extensions.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 2, NumberAvailable: 3},
+			},
+			Expected: "2 of 3 pods updated",
+		},
+		{
+			// OnDelete daemonsets are not updated until their pods are deleted
+			DaemonSet: extensions.DaemonSet{
+				Spec:   extensions.DaemonSetSpec{UpdateStrategy: extensions.DaemonSetUpdateStrategy{Type: extensions.OnDeleteDaemonSetStrategyType}},
+				Status: extensions.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 0, NumberAvailable: 3},
+			},
+			Expected: "",
+		},
+		{
+			DaemonSet: extensions.DaemonSet{
+				Status: extensions.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 1},
+			},
+			Expected: "1 of 3 pods available",
+		},
+	}
+	for _, g := range grid {
+		actual := daemonSetRolloutPending(&g.DaemonSet)
+		if actual != g.Expected {
+			t.Errorf("unexpected result for %v: got %q, expected %q", g.DaemonSet.Status, actual, g.Expected)
+		}
+	}
+}
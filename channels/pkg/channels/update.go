@@ -0,0 +1,234 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channels
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// SortByDependencies returns the addons of the menu ordered so that every addon comes after the addons it depends on.
+// Addons which are not ordered by a dependency are sorted by name.
+func (m *AddonMenu) SortByDependencies() ([]*Addon, error) {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int)
+
+	var ordered []*Addon
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		path = append(path, name)
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("addons have a dependency cycle: %s", strings.Join(path, " -> "))
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+		addon := m.Addons[name]
+		for _, dependency := range sortedStrings(addon.Spec.DependsOn) {
+			if m.Addons[dependency] == nil {
+				return fmt.Errorf("addon %q depends on %q, which is not in any channel", name, dependency)
+			}
+			if err := visit(dependency, path); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+
+		ordered = append(ordered, addon)
+		return nil
+	}
+
+	var names []string
+	for name := range m.Addons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+func sortedStrings(s []string) []string {
+	sorted := append([]string(nil), s...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// AddonStatus is the outcome of an addon update
+type AddonStatus string
+
+const (
+	// AddonStatusUpdated means the addon was applied, and is healthy if we waited for it
+	AddonStatusUpdated AddonStatus = "Updated"
+	// AddonStatusUnchanged means the addon had already been updated, probably by a concurrent run
+	AddonStatusUnchanged AddonStatus = "Unchanged"
+	// AddonStatusFailed means the addon could not be applied, or did not become healthy
+	AddonStatusFailed AddonStatus = "Failed"
+	// AddonStatusSkipped means the addon was not applied, because an earlier update failed
+	AddonStatusSkipped AddonStatus = "Skipped"
+	// AddonStatusPending means the addon was not applied, because we don't wait and an addon it depends on is not
+	// yet healthy, e.g. because it was applied in the same run; it is applied by a later run
+	AddonStatusPending AddonStatus = "Pending"
+)
+
+// AddonResult reports the outcome of the update of an addon
+type AddonResult struct {
+	Name    string
+	Status  AddonStatus
+	Update  *AddonUpdate
+	Message string
+}
+
+// Updater applies addon updates in dependency order, waiting for the addons to become healthy
+type Updater struct {
+	k8sClient kubernetes.Interface
+
+	// waitTimeout is how long we wait for an addon to become healthy.  With zero, we don't wait for updated
+	// addons, and we check the health of dependencies only once.
+	waitTimeout time.Duration
+
+	// apply applies an addon; it is replaced in tests
+	apply func(addon *Addon) (*AddonUpdate, error)
+}
+
+// NewUpdater builds an Updater
+func NewUpdater(k8sClient kubernetes.Interface, dynamicClient dynamic.ClientPool, waitTimeout time.Duration) *Updater {
+	return &Updater{
+		k8sClient:   k8sClient,
+		waitTimeout: waitTimeout,
+		apply: func(addon *Addon) (*AddonUpdate, error) {
+			return addon.EnsureUpdated(k8sClient, dynamicClient)
+		},
+	}
+}
+
+// Update applies the addons which need an update, in dependency order.  Before an addon is applied, the addons it
+// depends on must be healthy; after it is applied, we wait for it to become healthy.  We stop at the first failure,
+// and return a result for every addon which needed an update, along with the error.  Without a wait timeout, an addon
+// whose dependencies are not yet healthy is left pending, along with the addons which depend on it, and we continue.
+func (u *Updater) Update(menu *AddonMenu, needUpdates []*Addon) ([]*AddonResult, error) {
+	ordered, err := menu.SortByDependencies()
+	if err != nil {
+		return nil, err
+	}
+
+	needUpdate := make(map[string]bool)
+	for _, addon := range needUpdates {
+		needUpdate[addon.Name] = true
+	}
+
+	healthy := make(map[string]bool)
+	pending := make(map[string]bool)
+
+	var results []*AddonResult
+	var failure error
+	for _, addon := range ordered {
+		if !needUpdate[addon.Name] {
+			continue
+		}
+
+		result := &AddonResult{Name: addon.Name}
+		results = append(results, result)
+
+		if failure != nil {
+			result.Status = AddonStatusSkipped
+			result.Message = "not applied because of an earlier failure"
+			continue
+		}
+
+		if err := u.updateAddon(menu, addon, healthy, pending, result); err != nil {
+			result.Status = AddonStatusFailed
+			result.Message = err.Error()
+			failure = fmt.Errorf("error updating %q: %v", addon.Name, err)
+		}
+	}
+
+	if failure == nil && len(pending) != 0 {
+		var names []string
+		for _, result := range results {
+			if result.Status == AddonStatusPending {
+				names = append(names, result.Name)
+			}
+		}
+		failure = fmt.Errorf("addons not applied until their dependencies are healthy: %s", strings.Join(names, ", "))
+	}
+
+	return results, failure
+}
+
+// updateAddon waits for the dependencies of the addon, applies it, and waits for it to become healthy.
+// If the addon is left pending, it is added to pending.
+func (u *Updater) updateAddon(menu *AddonMenu, addon *Addon, healthy map[string]bool, pending map[string]bool, result *AddonResult) error {
+	for _, dependency := range sortedStrings(addon.Spec.DependsOn) {
+		if healthy[dependency] {
+			continue
+		}
+		if pending[dependency] {
+			result.Status = AddonStatusPending
+			result.Message = fmt.Sprintf("dependency %q has not been applied", dependency)
+			pending[addon.Name] = true
+			return nil
+		}
+		glog.V(2).Infof("checking health of %q, which %q depends on", dependency, addon.Name)
+		if err := menu.Addons[dependency].WaitForHealthy(u.k8sClient, u.waitTimeout); err != nil {
+			if u.waitTimeout == 0 {
+				glog.Infof("not applying %q yet: dependency %q is %v", addon.Name, dependency, err)
+				result.Status = AddonStatusPending
+				result.Message = fmt.Sprintf("dependency %q is %v", dependency, err)
+				pending[addon.Name] = true
+				return nil
+			}
+			return fmt.Errorf("dependency %q is %v", dependency, err)
+		}
+		healthy[dependency] = true
+	}
+
+	update, err := u.apply(addon)
+	if err != nil {
+		return err
+	}
+	result.Update = update
+	if update == nil {
+		result.Status = AddonStatusUnchanged
+		return nil
+	}
+
+	if u.waitTimeout != 0 {
+		if err := addon.WaitForHealthy(u.k8sClient, u.waitTimeout); err != nil {
+			return fmt.Errorf("applied, but %v", err)
+		}
+		healthy[addon.Name] = true
+	}
+
+	result.Status = AddonStatusUpdated
+	return nil
+}
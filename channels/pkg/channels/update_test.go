@@ -0,0 +1,302 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channels
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	extensions "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kops/channels/pkg/api"
+)
+
+func buildMenu(addons ...*Addon) *AddonMenu {
+	menu := NewAddonMenu()
+	for _, addon := range addons {
+		menu.Addons[addon.Name] = addon
+	}
+	return menu
+}
+
+func buildAddon(name string, dependsOn ...string) *Addon {
+	version := "1.0.0"
+	return &Addon{
+		Name:        name,
+		ChannelName: "test",
+		Spec: &api.AddonSpec{
+			Name:      &name,
+			Version:   &version,
+			Selector:  map[string]string{"k8s-addon": name},
+			DependsOn: dependsOn,
+		},
+	}
+}
+
+func addonNames(addons []*Addon) string {
+	var names []string
+	for _, addon := range addons {
+		names = append(names, addon.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func Test_SortByDependencies(t *testing.T) {
+	menu := buildMenu(
+		buildAddon("dashboard", "kube-dns"),
+		buildAddon("kube-dns", "networking"),
+		buildAddon("networking"),
+		buildAddon("autoscaler"),
+		buildAddon("monitoring", "networking", "dashboard"),
+	)
+
+	ordered, err := menu.SortByDependencies()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	actual := addonNames(ordered)
+	expected := "autoscaler,networking,kube-dns,dashboard,monitoring"
+	if actual != expected {
+		t.Fatalf("unexpected order: got %q, expected %q", actual, expected)
+	}
+}
+
+func Test_SortByDependencies_Errors(t *testing.T) {
+	grid := []struct {
+		Menu     *AddonMenu
+		Expected string
+	}{
+		{
+			Menu:     buildMenu(buildAddon("a", "b"), buildAddon("b", "c"), buildAddon("c", "a")),
+			Expected: "addons have a dependency cycle: a -> b -> c -> a",
+		},
+		{
+			Menu:     buildMenu(buildAddon("a", "a")),
+			Expected: "addons have a dependency cycle: a -> a",
+		},
+		{
+			Menu:     buildMenu(buildAddon("a", "missing")),
+			Expected: `addon "a" depends on "missing", which is not in any channel`,
+		},
+	}
+	for _, g := range grid {
+		_, err := g.Menu.SortByDependencies()
+		if err == nil || err.Error() != g.Expected {
+			t.Errorf("unexpected error: got %v, expected %q", err, g.Expected)
+		}
+	}
+}
+
+func daemonSet(name string, available int32) *extensions.DaemonSet {
+	return &extensions.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-system", Labels: map[string]string{"k8s-addon": name}},
+		Status:     extensions.DaemonSetStatus{DesiredNumberScheduled: 3, NumberAvailable: available},
+	}
+}
+
+// fakeApply records the addons which are applied
+type fakeApply struct {
+	applied []string
+	fail    map[string]bool
+}
+
+func (f *fakeApply) apply(addon *Addon) (*AddonUpdate, error) {
+	if f.fail[addon.Name] {
+		return nil, fmt.Errorf("apply failed")
+	}
+	f.applied = append(f.applied, addon.Name)
+	return &AddonUpdate{Name: addon.Name, NewVersion: addon.ChannelVersion()}, nil
+}
+
+func buildUpdater(k8sClient kubernetes.Interface, f *fakeApply, waitTimeout time.Duration) *Updater {
+	return &Updater{
+		k8sClient:   k8sClient,
+		waitTimeout: waitTimeout,
+		apply:       f.apply,
+	}
+}
+
+func resultsSummary(results []*AddonResult) string {
+	var s []string
+	for _, r := range results {
+		s = append(s, r.Name+"="+string(r.Status))
+	}
+	return strings.Join(s, ",")
+}
+
+func Test_Update_Ordered(t *testing.T) {
+	healthPollInterval = 10 * time.Millisecond
+	defer func() { healthPollInterval = 5 * time.Second }()
+
+	networking := buildAddon("networking")
+	kubeDNS := buildAddon("kube-dns", "networking")
+	dashboard := buildAddon("dashboard", "kube-dns")
+	menu := buildMenu(networking, kubeDNS, dashboard)
+
+	k8sClient := fake.NewSimpleClientset(daemonSet("networking", 3), daemonSet("kube-dns", 3), daemonSet("dashboard", 3))
+	f := &fakeApply{}
+	results, err := buildUpdater(k8sClient, f, time.Second).Update(menu, []*Addon{dashboard, kubeDNS, networking})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if actual := strings.Join(f.applied, ","); actual != "networking,kube-dns,dashboard" {
+		t.Fatalf("unexpected apply order: %q", actual)
+	}
+	if actual := resultsSummary(results); actual != "networking=Updated,kube-dns=Updated,dashboard=Updated" {
+		t.Fatalf("unexpected results: %q", actual)
+	}
+}
+
+func Test_Update_UnhealthyDependency(t *testing.T) {
+	healthPollInterval = 10 * time.Millisecond
+	defer func() { healthPollInterval = 5 * time.Second }()
+
+	networking := buildAddon("networking")
+	kubeDNS := buildAddon("kube-dns", "networking")
+	dashboard := buildAddon("dashboard", "kube-dns")
+	autoscaler := buildAddon("autoscaler")
+	menu := buildMenu(networking, kubeDNS, dashboard, autoscaler)
+
+	// networking is already installed (it doesn't need an update), but isn't healthy
+	k8sClient := fake.NewSimpleClientset(daemonSet("networking", 1), daemonSet("kube-dns", 3))
+	f := &fakeApply{}
+	results, err := buildUpdater(k8sClient, f, 50*time.Millisecond).Update(menu, []*Addon{dashboard, kubeDNS, autoscaler})
+	if err == nil {
+		t.Fatalf("expected error when dependency is unhealthy")
+	}
+	if !strings.Contains(err.Error(), `error updating "kube-dns": dependency "networking" is not healthy: daemonset kube-system/networking: 1 of 3 pods available`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// autoscaler has no dependencies so it sorts first; dashboard is skipped after the failure
+	if actual := strings.Join(f.applied, ","); actual != "autoscaler" {
+		t.Fatalf("unexpected applied addons: %q", actual)
+	}
+	if actual := resultsSummary(results); actual != "autoscaler=Updated,kube-dns=Failed,dashboard=Skipped" {
+		t.Fatalf("unexpected results: %q", actual)
+	}
+}
+
+func Test_Update_UnhealthyAfterApply(t *testing.T) {
+	healthPollInterval = 10 * time.Millisecond
+	defer func() { healthPollInterval = 5 * time.Second }()
+
+	networking := buildAddon("networking")
+	kubeDNS := buildAddon("kube-dns", "networking")
+	menu := buildMenu(networking, kubeDNS)
+
+	k8sClient := fake.NewSimpleClientset(daemonSet("networking", 2))
+
+	{
+		f := &fakeApply{}
+		results, err := buildUpdater(k8sClient, f, 50*time.Millisecond).Update(menu, []*Addon{networking, kubeDNS})
+		if err == nil || !strings.Contains(err.Error(), `error updating "networking": applied, but not healthy`) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if actual := resultsSummary(results); actual != "networking=Failed,kube-dns=Skipped" {
+			t.Fatalf("unexpected results: %q", actual)
+		}
+	}
+
+	{
+		// Without a timeout, we don't wait for updated addons, but still check dependencies
+		f := &fakeApply{}
+		results, err := buildUpdater(k8sClient, f, 0).Update(menu, []*Addon{networking, kubeDNS})
+		if err == nil || err.Error() != "addons not applied until their dependencies are healthy: kube-dns" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if actual := resultsSummary(results); actual != "networking=Updated,kube-dns=Pending" {
+			t.Fatalf("unexpected results: %q", actual)
+		}
+	}
+}
+
+func Test_Update_PendingWithoutWait(t *testing.T) {
+	networking := buildAddon("networking")
+	kubeDNS := buildAddon("kube-dns", "networking")
+	dashboard := buildAddon("dashboard", "kube-dns")
+	autoscaler := buildAddon("autoscaler")
+	storage := buildAddon("storage")
+	menu := buildMenu(networking, kubeDNS, dashboard, autoscaler, storage)
+
+	// The first run applies networking, which can't be healthy yet; the addons depending on it are left
+	// pending rather than failing, and the other addons are still applied
+	k8sClient := fake.NewSimpleClientset(daemonSet("networking", 0), daemonSet("kube-dns", 0))
+	f := &fakeApply{}
+	results, err := buildUpdater(k8sClient, f, 0).Update(menu, []*Addon{networking, kubeDNS, dashboard, autoscaler, storage})
+	if err == nil || err.Error() != "addons not applied until their dependencies are healthy: kube-dns, dashboard" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actual := strings.Join(f.applied, ","); actual != "autoscaler,networking,storage" {
+		t.Fatalf("unexpected applied addons: %q", actual)
+	}
+	if actual := resultsSummary(results); actual != "autoscaler=Updated,networking=Updated,kube-dns=Pending,dashboard=Pending,storage=Updated" {
+		t.Fatalf("unexpected results: %q", actual)
+	}
+	if results[3].Message != `dependency "kube-dns" has not been applied` {
+		t.Fatalf("unexpected message: %q", results[3].Message)
+	}
+
+	// Once networking is healthy, a later run applies the pending addons
+	if _, err := k8sClient.ExtensionsV1beta1().DaemonSets("kube-system").Update(daemonSet("networking", 3)); err != nil {
+		t.Fatalf("error updating daemonset: %v", err)
+	}
+	f = &fakeApply{}
+	results, err = buildUpdater(k8sClient, f, 0).Update(menu, []*Addon{kubeDNS, dashboard})
+	if err == nil || err.Error() != "addons not applied until their dependencies are healthy: dashboard" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actual := resultsSummary(results); actual != "kube-dns=Updated,dashboard=Pending" {
+		t.Fatalf("unexpected results: %q", actual)
+	}
+
+	if _, err := k8sClient.ExtensionsV1beta1().DaemonSets("kube-system").Update(daemonSet("kube-dns", 3)); err != nil {
+		t.Fatalf("error updating daemonset: %v", err)
+	}
+	f = &fakeApply{}
+	results, err = buildUpdater(k8sClient, f, 0).Update(menu, []*Addon{dashboard})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actual := resultsSummary(results); actual != "dashboard=Updated" {
+		t.Fatalf("unexpected results: %q", actual)
+	}
+}
+
+func Test_Update_ApplyFailure(t *testing.T) {
+	networking := buildAddon("networking")
+	kubeDNS := buildAddon("kube-dns", "networking")
+	menu := buildMenu(networking, kubeDNS)
+
+	k8sClient := fake.NewSimpleClientset()
+	f := &fakeApply{fail: map[string]bool{"networking": true}}
+	results, err := buildUpdater(k8sClient, f, 0).Update(menu, []*Addon{networking, kubeDNS})
+	if err == nil || err.Error() != `error updating "networking": apply failed` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actual := resultsSummary(results); actual != "networking=Failed,kube-dns=Skipped" {
+		t.Fatalf("unexpected results: %q", actual)
+	}
+	if results[0].Message != "apply failed" {
+		t.Fatalf("unexpected message: %q", results[0].Message)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/spf13/cobra"
@@ -32,10 +33,15 @@ import (
 type ApplyChannelOptions struct {
 	Yes   bool
 	Files []string
+
+	// WaitTimeout is how long we wait for each updated addon to roll out; zero disables waiting
+	WaitTimeout time.Duration
 }
 
 func NewCmdApplyChannel(f Factory, out io.Writer) *cobra.Command {
-	var options ApplyChannelOptions
+	options := ApplyChannelOptions{
+		WaitTimeout: 5 * time.Minute,
+	}
 
 	cmd := &cobra.Command{
 		Use:   "channel",
@@ -47,6 +53,7 @@ func NewCmdApplyChannel(f Factory, out io.Writer) *cobra.Command {
 
 	cmd.Flags().BoolVar(&options.Yes, "yes", false, "Apply update")
 	cmd.Flags().StringSliceVarP(&options.Files, "filename", "f", []string{}, "Apply from a local file")
+	cmd.Flags().DurationVar(&options.WaitTimeout, "wait-timeout", options.WaitTimeout, "Time to wait for each updated addon, and the addons it depends on, to become healthy; with 0 we don't wait for updated addons, and check dependencies once")
 
 	return cmd
 }
@@ -57,6 +64,11 @@ func RunApplyChannel(f Factory, out io.Writer, options *ApplyChannelOptions, arg
 		return err
 	}
 
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return err
+	}
+
 	kubernetesVersionInfo, err := k8sClient.Discovery().ServerVersion()
 	if err != nil {
 		return fmt.Errorf("error querying kubernetes version: %v", err)
@@ -134,9 +146,15 @@ func RunApplyChannel(f Factory, out io.Writer, options *ApplyChannelOptions, arg
 		menu.MergeAddons(current)
 	}
 
+	// We check (and apply) the addons in dependency order
+	ordered, err := menu.SortByDependencies()
+	if err != nil {
+		return err
+	}
+
 	var updates []*channels.AddonUpdate
 	var needUpdates []*channels.Addon
-	for _, addon := range menu.Addons {
+	for _, addon := range ordered {
 		// TODO: Cache lookups to prevent repeated lookups?
 		update, err := addon.GetRequiredUpdates(k8sClient)
 		if err != nil {
@@ -189,18 +207,37 @@ func RunApplyChannel(f Factory, out io.Writer, options *ApplyChannelOptions, arg
 		return nil
 	}
 
-	for _, needUpdate := range needUpdates {
-		update, err := needUpdate.EnsureUpdated(k8sClient)
-		if err != nil {
-			return fmt.Errorf("error updating %q: %v", needUpdate.Name, err)
+	updater := channels.NewUpdater(k8sClient, dynamicClient, options.WaitTimeout)
+	results, err := updater.Update(menu, needUpdates)
+	if err != nil {
+		fmt.Printf("\n")
+
+		t := &tables.Table{}
+		t.AddColumn("NAME", func(r *channels.AddonResult) string {
+			return r.Name
+		})
+		t.AddColumn("STATUS", func(r *channels.AddonResult) string {
+			return string(r.Status)
+		})
+		t.AddColumn("MESSAGE", func(r *channels.AddonResult) string {
+			return r.Message
+		})
+		if err := t.Render(results, os.Stdout, "NAME", "STATUS", "MESSAGE"); err != nil {
+			return err
 		}
+		return err
+	}
+
+	for _, result := range results {
 		// Could have been a concurrent request
-		if update != nil {
-			if update.NewVersion.Version != nil {
-				fmt.Printf("Updated %q to %s\n", update.Name, *update.NewVersion.Version)
-			} else {
-				fmt.Printf("Updated %q\n", update.Name)
-			}
+		if result.Status != channels.AddonStatusUpdated {
+			continue
+		}
+		update := result.Update
+		if update.NewVersion.Version != nil {
+			fmt.Printf("Updated %q to %s\n", update.Name, *update.NewVersion.Version)
+		} else {
+			fmt.Printf("Updated %q\n", update.Name)
 		}
 	}
 
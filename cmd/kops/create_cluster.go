@@ -312,7 +312,7 @@ func NewCmdCreateCluster(f *util.Factory, out io.Writer) *cobra.Command {
 	cmd.Flags().StringVar(&options.Authorization, "authorization", options.Authorization, "Authorization mode to use: "+AuthorizationFlagAlwaysAllow+" or "+AuthorizationFlagRBAC)
 
 	// DNS
-	cmd.Flags().StringVar(&options.DNSType, "dns", options.DNSType, "DNS hosted zone to use: public|private. Default is 'public'.")
+	cmd.Flags().StringVar(&options.DNSType, "dns", options.DNSType, "DNS hosted zone to use: public|private|splithorizon. Default is 'public'.")
 
 	// Bastion
 	cmd.Flags().BoolVar(&options.Bastion, "bastion", options.Bastion, "Pass the --bastion flag to enable a bastion instance group. Only applies to private topology.")
@@ -979,6 +979,8 @@ func RunCreateCluster(f *util.Factory, out io.Writer, c *CreateClusterOptions) e
 		cluster.Spec.Topology.DNS.Type = api.DNSTypePublic
 	case "private":
 		cluster.Spec.Topology.DNS.Type = api.DNSTypePrivate
+	case "splithorizon":
+		cluster.Spec.Topology.DNS.Type = api.DNSTypeSplitHorizon
 	default:
 		return fmt.Errorf("unknown DNSType: %q", c.DNSType)
 	}
@@ -123,6 +123,7 @@ func RunCreateSecretDockerConfig(f *util.Factory, out io.Writer, options *Create
 	}
 
 	secret.Data = data
+	secret.Type = fi.SecretTypeDockerConfig
 
 	if !options.Force {
 		_, created, err := secretStore.GetOrCreateSecret("dockerconfig", secret)
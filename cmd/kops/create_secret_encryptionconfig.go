@@ -124,6 +124,7 @@ func RunCreateSecretEncryptionConfig(f *util.Factory, out io.Writer, options *Cr
 	}
 
 	secret.Data = data
+	secret.Type = fi.SecretTypeEncryptionConfig
 
 	if !options.Force {
 		_, created, err := secretStore.GetOrCreateSecret("encryptionconfig", secret)
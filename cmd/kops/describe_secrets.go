@@ -132,7 +132,7 @@ func (c *DescribeSecretsCommand) Run(args []string) error {
 			}
 
 		case kops.SecretTypeSecret:
-			err = describeSecret(i, &b)
+			err = describeSecret(secretStore, i, &b)
 			if err != nil {
 				return err
 			}
@@ -198,7 +198,28 @@ func describeKeypair(keyStore fi.CAStore, item *fi.KeystoreItem, w *bytes.Buffer
 	return nil
 }
 
-func describeSecret(item *fi.KeystoreItem, w *bytes.Buffer) error {
+func describeSecret(secretStore fi.SecretStore, item *fi.KeystoreItem, w *bytes.Buffer) error {
+	secret, err := secretStore.FindSecret(item.Name)
+	if err != nil {
+		return fmt.Errorf("error retrieving secret %q: %v", item.Name, err)
+	}
+	if secret == nil {
+		return nil
+	}
+
+	fmt.Fprintf(w, "SecretType:\t%s\n", inferSecretType(item.Name, secret))
+	if secret.Created != nil {
+		fmt.Fprintf(w, "Created:\t%s\n", secret.Created)
+	}
+	if secret.Rotated != nil {
+		fmt.Fprintf(w, "Rotated:\t%s\n", secret.Rotated)
+	}
+	if secret.Expires != nil {
+		fmt.Fprintf(w, "Expires:\t%s\n", secret.Expires)
+	}
+	if secret.RotationPhase != "" {
+		fmt.Fprintf(w, "RotationPhase:\t%s\n", secret.RotationPhase)
+	}
 	return nil
 }
 
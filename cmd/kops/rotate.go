@@ -0,0 +1,53 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	rotateLong = templates.LongDesc(i18n.T(`
+	Rotate secrets.
+
+	kops rotate updates the state store; to apply the changes use "kops update cluster" and "kops rolling-update cluster".
+	`))
+
+	rotateExample = templates.Examples(i18n.T(`
+	# Rotate the kube static token
+	kops rotate secret kube --name k8s-cluster.example.com
+	`))
+)
+
+func NewCmdRotate(f *util.Factory, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rotate",
+		Short:   i18n.T("Rotate secrets."),
+		Long:    rotateLong,
+		Example: rotateExample,
+	}
+
+	// create subcommands
+	cmd.AddCommand(NewCmdRotateSecret(f, out))
+
+	return cmd
+}
@@ -0,0 +1,292 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kops/cmd/kops/util"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/encryptionconfig"
+	"k8s.io/kops/pkg/tokens"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	rotateSecretLong = templates.LongDesc(i18n.T(`
+	Rotate a secret in the state store.
+
+	Static tokens are replaced with a new random token.
+
+	The encryption config is rotated in three steps, running this command once for each step.
+	After each step the masters must be updated, so that every kube-apiserver is using the
+	new encryption config before the next step is started:
+
+	1. a new encryption key is added as a secondary key
+	2. the new key is promoted to be the primary key
+	3. all secrets are re-encrypted through the apiserver with the new key, and the old key is removed
+
+	Docker config secrets hold registry credentials, and must be replaced with
+	"kops create secret dockerconfig --force".`))
+
+	rotateSecretExample = templates.Examples(i18n.T(`
+	# Rotate the kube static token
+	kops rotate secret kube --name k8s-cluster.example.com --state s3://example.com
+
+	# Start (or continue) rotating the encryption key
+	kops rotate secret encryptionconfig --name k8s-cluster.example.com --state s3://example.com
+	kops update cluster --name k8s-cluster.example.com --yes
+	kops rolling-update cluster --name k8s-cluster.example.com --instance-group master-us-east-1a --force --yes
+	`))
+
+	rotateSecretShort = i18n.T(`Rotate a secret.`)
+)
+
+type RotateSecretOptions struct {
+	ClusterName string
+	SecretName  string
+}
+
+func NewCmdRotateSecret(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &RotateSecretOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "secret",
+		Short:   rotateSecretShort,
+		Long:    rotateSecretLong,
+		Example: rotateSecretExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				exitWithError(fmt.Errorf("Syntax: <name>"))
+			}
+
+			options.SecretName = args[0]
+			options.ClusterName = rootCommand.ClusterName()
+
+			err := RunRotateSecret(f, out, options)
+			if err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func RunRotateSecret(f *util.Factory, out io.Writer, options *RotateSecretOptions) error {
+	if options.ClusterName == "" {
+		return fmt.Errorf("ClusterName is required")
+	}
+	if options.SecretName == "" {
+		return fmt.Errorf("SecretName is required")
+	}
+
+	cluster, err := GetCluster(f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+
+	secretStore, err := clientset.SecretStore(cluster)
+	if err != nil {
+		return err
+	}
+
+	secret, err := secretStore.FindSecret(options.SecretName)
+	if err != nil {
+		return fmt.Errorf("error reading secret %q: %v", options.SecretName, err)
+	}
+	if secret == nil {
+		return fmt.Errorf("secret %q not found", options.SecretName)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	secretType := inferSecretType(options.SecretName, secret)
+	secret.Type = secretType
+
+	var next string
+	switch secretType {
+	case fi.SecretTypeStaticToken:
+		token, err := fi.CreateSecret()
+		if err != nil {
+			return err
+		}
+		secret.Data = token.Data
+		secret.Rotated = &now
+		secret.Expires = nil
+		fmt.Fprintf(out, "Rotated static token %q\n", options.SecretName)
+		next = "The masters must be updated to use the new token, and kubeconfigs using the token must be exported again with \"kops export kubecfg\"."
+
+	case fi.SecretTypeEncryptionConfig:
+		step, err := encryptionconfig.Rotate(secret.Data, secret.RotationPhase, now, func(resources []string) error {
+			return reencryptResources(cluster, resources, out)
+		})
+		if err != nil {
+			return fmt.Errorf("error rotating encryption config: %v", err)
+		}
+		secret.Data = step.Data
+		secret.RotationPhase = step.Phase
+		if step.Phase == encryptionconfig.PhaseNone {
+			secret.Rotated = &now
+			secret.Expires = nil
+		}
+		fmt.Fprintf(out, "Encryption config: %s\n", step.Description)
+		if step.Phase == encryptionconfig.PhaseNone {
+			next = "The masters must be updated to remove the old key; the rotation is then complete."
+		} else {
+			next = "The masters must be updated before running \"kops rotate secret " + options.SecretName + "\" again to continue the rotation."
+		}
+
+	case fi.SecretTypeDockerConfig:
+		return fmt.Errorf("secret %q holds registry credentials and cannot be generated; replace it with \"kops create secret dockerconfig --force\"", options.SecretName)
+
+	default:
+		return fmt.Errorf("secret %q is a %s secret, which kops does not know how to generate; replace it instead", options.SecretName, secretType)
+	}
+
+	if _, err := secretStore.ReplaceSecret(options.SecretName, secret); err != nil {
+		return fmt.Errorf("error updating secret %q: %v", options.SecretName, err)
+	}
+
+	masters, err := masterInstanceGroupNames(f, cluster)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\n%s\n", next)
+	fmt.Fprintf(out, "To update the masters run:\n")
+	fmt.Fprintf(out, "  kops update cluster --name %s --yes\n", cluster.ObjectMeta.Name)
+	fmt.Fprintf(out, "  kops rolling-update cluster --name %s --instance-group %s --force --yes\n", cluster.ObjectMeta.Name, strings.Join(masters, ","))
+
+	return nil
+}
+
+// inferSecretType returns the type of the secret, inferring it from the well-known names of secrets created before secrets were typed
+func inferSecretType(name string, secret *fi.Secret) fi.SecretType {
+	if secret.Type != "" {
+		return secret.Type
+	}
+
+	switch name {
+	case "encryptionconfig":
+		return fi.SecretTypeEncryptionConfig
+	case "dockerconfig":
+		return fi.SecretTypeDockerConfig
+	}
+	for _, token := range tokens.GetKubernetesAuthTokens_Deprecated() {
+		if token == name {
+			return fi.SecretTypeStaticToken
+		}
+	}
+	return fi.SecretTypeGeneric
+}
+
+// masterInstanceGroupNames returns the names of the master instance groups
+func masterInstanceGroupNames(f *util.Factory, cluster *api.Cluster) ([]string, error) {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := clientset.InstanceGroupsFor(cluster).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing InstanceGroups: %v", err)
+	}
+
+	var names []string
+	for _, ig := range list.Items {
+		if ig.IsMaster() {
+			names = append(names, ig.ObjectMeta.Name)
+		}
+	}
+	return names, nil
+}
+
+// reencryptResources rewrites every object of the specified resources through the apiserver, so they are encrypted with the primary key
+func reencryptResources(cluster *api.Cluster, resources []string, out io.Writer) error {
+	// TODO: Refactor into util.Factory
+	contextName := cluster.ObjectMeta.Name
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{CurrentContext: contextName}).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("cannot load kubecfg settings for %q: %v", contextName, err)
+	}
+
+	k8sClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("cannot build kubernetes api client for %q: %v", contextName, err)
+	}
+
+	for _, resource := range resources {
+		switch resource {
+		case "secrets":
+			list, err := k8sClient.CoreV1().Secrets("").List(metav1.ListOptions{})
+			if err != nil {
+				return fmt.Errorf("error listing secrets: %v", err)
+			}
+			for i := range list.Items {
+				s := &list.Items[i]
+				client := k8sClient.CoreV1().Secrets(s.Namespace)
+				if _, err := client.Update(s); err != nil {
+					if !errors.IsConflict(err) {
+						return fmt.Errorf("error re-encrypting secret %s/%s: %v", s.Namespace, s.Name, err)
+					}
+					// A concurrent write has already re-encrypted the secret
+					glog.V(2).Infof("secret %s/%s was concurrently updated", s.Namespace, s.Name)
+				}
+			}
+			fmt.Fprintf(out, "Re-encrypted %d secrets\n", len(list.Items))
+
+		case "configmaps":
+			list, err := k8sClient.CoreV1().ConfigMaps("").List(metav1.ListOptions{})
+			if err != nil {
+				return fmt.Errorf("error listing configmaps: %v", err)
+			}
+			for i := range list.Items {
+				cm := &list.Items[i]
+				client := k8sClient.CoreV1().ConfigMaps(cm.Namespace)
+				if _, err := client.Update(cm); err != nil {
+					if !errors.IsConflict(err) {
+						return fmt.Errorf("error re-encrypting configmap %s/%s: %v", cm.Namespace, cm.Name, err)
+					}
+					glog.V(2).Infof("configmap %s/%s was concurrently updated", cm.Namespace, cm.Name)
+				}
+			}
+			fmt.Fprintf(out, "Re-encrypted %d configmaps\n", len(list.Items))
+
+		default:
+			return fmt.Errorf("kops does not know how to re-encrypt resource %q; rewrite every object with \"kubectl get %s --all-namespaces -o json | kubectl replace -f -\" and then update the encryption config manually", resource, resource)
+		}
+	}
+
+	return nil
+}
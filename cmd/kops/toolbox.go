@@ -47,7 +47,9 @@ func NewCmdToolbox(f *util.Factory, out io.Writer) *cobra.Command {
 
 	cmd.AddCommand(NewCmdToolboxConvertImported(f, out))
 	cmd.AddCommand(NewCmdToolboxDump(f, out))
+	cmd.AddCommand(NewCmdToolboxGossipState(f, out))
 	cmd.AddCommand(NewCmdToolboxBundle(f, out))
+	cmd.AddCommand(NewCmdToolboxBuildNodeBundle(f, out))
 	cmd.AddCommand(NewCmdToolboxTemplate(f, out))
 
 	return cmd
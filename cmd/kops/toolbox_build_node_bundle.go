@@ -0,0 +1,237 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/nodeup/pkg/distros"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/model"
+	"k8s.io/kops/pkg/nodebundle"
+	"k8s.io/kops/pkg/nodebundle/bundlebuilder"
+	"k8s.io/kops/util/pkg/hashing"
+	"k8s.io/kops/util/pkg/vfs"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	toolboxBuildNodeBundleLong = templates.LongDesc(i18n.T(`
+	Builds an offline bundle of everything nodeup needs for an instance group.
+
+	The kubernetes binaries, CNI plugins, preloaded images and OS packages are downloaded
+	for the specified distribution and architecture, and written to a single tarball.
+	The instance group is updated to use the bundle, and nodeup then installs everything
+	from the bundle, without downloading anything from the internet.
+
+	OS packages are resolved by running the package manager in a docker container, so docker
+	must be available on the machine running this command.
+
+	The bundle must be rebuilt whenever the cluster is changed, for example when it is upgraded.`))
+
+	toolboxBuildNodeBundleExample = templates.Examples(i18n.T(`
+	# Build a bundle for the nodes, storing it in the state store
+	kops toolbox build-node-bundle --name k8s-cluster.example.com nodes --distribution xenial
+
+	# Build a bundle, storing it in a file repository
+	kops toolbox build-node-bundle --name k8s-cluster.example.com nodes --distribution centos7 \
+	  --target s3://my-file-repository/nodebundles --location https://my-file-repository.s3.amazonaws.com/nodebundles
+	`))
+
+	toolboxBuildNodeBundleShort = i18n.T(`Build an offline bundle for nodeup`)
+)
+
+type ToolboxBuildNodeBundleOptions struct {
+	// Distribution is the OS distribution of the instance group's image, e.g. xenial
+	Distribution string
+	// Architecture is the machine architecture of the instance group; it defaults to the architecture of its machine type
+	Architecture string
+	// PackagesImage is the docker image used to download OS packages
+	PackagesImage string
+
+	// Target is the directory to which the bundle is written; it defaults to the state store
+	Target string
+	// Location is the directory from which nodes download the bundle; it defaults to the target
+	Location string
+}
+
+func NewCmdToolboxBuildNodeBundle(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxBuildNodeBundleOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "build-node-bundle",
+		Short:   toolboxBuildNodeBundleShort,
+		Long:    toolboxBuildNodeBundleLong,
+		Example: toolboxBuildNodeBundleExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := RunToolboxBuildNodeBundle(f, out, options, args)
+			if err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Distribution, "distribution", options.Distribution, "OS distribution of the instance group: jessie, debian9, xenial, bionic, centos7, rhel7 or amazonlinux2")
+	cmd.Flags().StringVar(&options.Architecture, "architecture", options.Architecture, "Machine architecture of the instance group (defaults to the architecture of its machine type)")
+	cmd.Flags().StringVar(&options.PackagesImage, "packages-image", options.PackagesImage, "Docker image in which to download OS packages (required for rhel7)")
+	cmd.Flags().StringVar(&options.Target, "target", options.Target, "Directory to write the bundle to (defaults to the state store)")
+	cmd.Flags().StringVar(&options.Location, "location", options.Location, "Directory from which nodes download the bundle (defaults to the target)")
+
+	return cmd
+}
+
+func RunToolboxBuildNodeBundle(context Factory, out io.Writer, options *ToolboxBuildNodeBundleOptions, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("Specify name of instance group")
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("Can only specify one instance group")
+	}
+	groupName := args[0]
+
+	if options.Distribution == "" {
+		return fmt.Errorf("--distribution is required")
+	}
+	distribution, err := distros.ParseDistribution(options.Distribution)
+	if err != nil {
+		return err
+	}
+
+	cluster, err := rootCommand.Cluster()
+	if err != nil {
+		return err
+	}
+
+	clientset, err := context.Clientset()
+	if err != nil {
+		return err
+	}
+
+	ig, err := clientset.InstanceGroupsFor(cluster).Get(groupName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error reading InstanceGroup %q: %v", groupName, err)
+	}
+	if ig == nil {
+		return fmt.Errorf("InstanceGroup %q not found", groupName)
+	}
+
+	architecture := options.Architecture
+	if architecture == "" {
+		architecture = string(model.InstanceGroupArchitecture(&cluster.Spec, ig))
+	}
+
+	var target vfs.Path
+	if options.Target != "" {
+		target, err = vfs.Context.BuildVfsPath(options.Target)
+		if err != nil {
+			return fmt.Errorf("error parsing target %q: %v", options.Target, err)
+		}
+	} else {
+		configBase, err := clientset.ConfigBaseFor(cluster)
+		if err != nil {
+			return fmt.Errorf("error building ConfigBase for cluster: %v", err)
+		}
+		target = configBase.Join("nodebundles", ig.ObjectMeta.Name)
+	}
+
+	dir, err := ioutil.TempDir("", "nodebundle")
+	if err != nil {
+		return fmt.Errorf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	builder := &bundlebuilder.Builder{
+		Clientset:     clientset,
+		Distribution:  distribution,
+		Architecture:  architecture,
+		PackagesImage: options.PackagesImage,
+	}
+	manifest, err := builder.Build(cluster, ig, filepath.Join(dir, "contents"))
+	if err != nil {
+		return fmt.Errorf("error building node bundle: %v", err)
+	}
+
+	tarball := filepath.Join(dir, "bundle.tar.gz")
+	if err := writeNodeBundle(tarball, manifest, filepath.Join(dir, "contents")); err != nil {
+		return err
+	}
+
+	hash, err := hashing.HashAlgorithmSHA256.HashFile(tarball)
+	if err != nil {
+		return fmt.Errorf("error hashing node bundle: %v", err)
+	}
+
+	name := hash.Hex() + ".tar.gz"
+	p := target.Join(name)
+	if err := uploadNodeBundle(tarball, p); err != nil {
+		return err
+	}
+
+	location := p.Path()
+	if options.Location != "" {
+		location = strings.TrimSuffix(options.Location, "/") + "/" + name
+	}
+
+	ig.Spec.NodeBundle = &kops.NodeBundleSpec{
+		Location: location,
+		Hash:     hash.Hex(),
+	}
+	if _, err := clientset.InstanceGroupsFor(cluster).Update(ig); err != nil {
+		return fmt.Errorf("error updating InstanceGroup %q: %v", groupName, err)
+	}
+
+	fmt.Fprintf(out, "Wrote node bundle for %q to %s\n", groupName, p)
+	fmt.Fprintf(out, "Run kops update cluster and kops rolling-update cluster to use the bundle\n")
+	return nil
+}
+
+// writeNodeBundle writes the bundle tarball to a local file
+func writeNodeBundle(tarball string, manifest *nodebundle.Manifest, dir string) error {
+	f, err := os.Create(tarball)
+	if err != nil {
+		return fmt.Errorf("error creating %q: %v", tarball, err)
+	}
+	defer f.Close()
+
+	if err := nodebundle.Write(f, manifest, dir); err != nil {
+		return fmt.Errorf("error writing node bundle: %v", err)
+	}
+	return nil
+}
+
+// uploadNodeBundle copies the bundle tarball to the target
+func uploadNodeBundle(tarball string, p vfs.Path) error {
+	f, err := os.Open(tarball)
+	if err != nil {
+		return fmt.Errorf("error opening %q: %v", tarball, err)
+	}
+	defer f.Close()
+
+	if err := p.WriteFile(f, nil); err != nil {
+		return fmt.Errorf("error writing node bundle to %q: %v", p, err)
+	}
+	return nil
+}
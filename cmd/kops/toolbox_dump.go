@@ -21,20 +21,29 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 
+	"github.com/golang/glog"
 	"github.com/spf13/cobra"
 	"k8s.io/kops/cmd/kops/util"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/nodeup"
 	"k8s.io/kops/pkg/resources"
 	resourceops "k8s.io/kops/pkg/resources/ops"
+	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/util/pkg/vfs"
 	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
 	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
 )
 
 var (
 	toolboxDumpLong = templates.LongDesc(i18n.T(`
-	Displays cluster information.  Includes information about cloud and Kubernetes resources.`))
+	Displays cluster information.  Includes information about cloud and Kubernetes resources.
+
+	If uploadBootReports is enabled for the cluster, the boot reports uploaded by nodeup
+	on each node are also included.`))
 
 	toolboxDumpExample = templates.Examples(i18n.T(`
 	# Dump cluster information
@@ -118,6 +127,14 @@ func RunToolboxDump(f *util.Factory, out io.Writer, options *ToolboxDumpOptions)
 		return err
 	}
 
+	if fi.BoolValue(cluster.Spec.UploadBootReports) {
+		configBase, err := clientset.ConfigBaseFor(cluster)
+		if err != nil {
+			return fmt.Errorf("error building ConfigBase for cluster: %v", err)
+		}
+		dump.BootReports = readBootReports(configBase.Join("bootreports"))
+	}
+
 	switch options.Output {
 	case OutputYaml:
 		b, err := kops.ToRawYaml(dump)
@@ -145,3 +162,35 @@ func RunToolboxDump(f *util.Factory, out io.Writer, options *ToolboxDumpOptions)
 		return fmt.Errorf("Unsupported output format: %q", options.Output)
 	}
 }
+
+// readBootReports reads the boot reports uploaded by nodeup.  Errors are only logged, as the reports are for troubleshooting.
+func readBootReports(p vfs.Path) []*nodeup.BootReport {
+	files, err := p.ReadDir()
+	if err != nil {
+		glog.Warningf("error listing boot reports in %s: %v", p, err)
+		return nil
+	}
+
+	var reports []*nodeup.BootReport
+	for _, f := range files {
+		if !strings.HasSuffix(f.Base(), ".json") {
+			continue
+		}
+		data, err := f.ReadFile()
+		if err != nil {
+			glog.Warningf("error reading boot report %s: %v", f, err)
+			continue
+		}
+		report := &nodeup.BootReport{}
+		if err := json.Unmarshal(data, report); err != nil {
+			glog.Warningf("error parsing boot report %s: %v", f, err)
+			continue
+		}
+		reports = append(reports, report)
+	}
+	if len(reports) == 0 {
+		glog.Warningf("no boot reports found in %s", p)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Hostname < reports[j].Hostname })
+	return reports
+}
@@ -0,0 +1,372 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/dns"
+	"k8s.io/kops/protokube/pkg/gossip"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+	"k8s.io/kubernetes/pkg/kubectl/util/i18n"
+)
+
+var (
+	toolboxGossipStateLong = templates.LongDesc(i18n.T(`
+	Displays the gossip state of a node in a gossip-based (k8s.local) cluster: the peers
+	it knows about, its connections, and the gossiped values with their versions.
+
+	protokube serves its gossip state on a read-only endpoint bound to 127.0.0.1 (port 3997
+	by default).  The endpoint is reached by port-forwarding through the Kubernetes API to a pod
+	which uses the host network on the node; by default the node running dns-controller is
+	inspected.
+
+	The endpoint can also be read over SSH, with "curl http://127.0.0.1:3997/gossip/state".`))
+
+	toolboxGossipStateExample = templates.Examples(i18n.T(`
+	# Display the gossip state of the master running dns-controller
+	kops toolbox gossip-state --name k8s-cluster.k8s.local
+
+	# Display the gossip state of a node, as JSON
+	kops toolbox gossip-state --name k8s-cluster.k8s.local --node ip-172-20-40-77.ec2.internal -o json
+	`))
+
+	toolboxGossipStateShort = i18n.T(`Display the gossip state of a node`)
+)
+
+type ToolboxGossipStateOptions struct {
+	ClusterName string
+
+	// Node is the node to inspect; if empty we inspect the node running dns-controller
+	Node string
+	// Port is the port of the gossip status endpoint on the node
+	Port int
+
+	Output string
+}
+
+func (o *ToolboxGossipStateOptions) InitDefaults() {
+	o.Port = 3997
+	o.Output = OutputTable
+}
+
+func NewCmdToolboxGossipState(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxGossipStateOptions{}
+	options.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:     "gossip-state",
+		Short:   toolboxGossipStateShort,
+		Long:    toolboxGossipStateLong,
+		Example: toolboxGossipStateExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := rootCommand.ProcessArgs(args); err != nil {
+				exitWithError(err)
+			}
+
+			options.ClusterName = rootCommand.ClusterName()
+
+			err := RunToolboxGossipState(f, out, options)
+			if err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Node, "node", options.Node, "Name of the node to inspect; defaults to the node running dns-controller")
+	cmd.Flags().IntVar(&options.Port, "port", options.Port, "Port of the gossip status endpoint on the node")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output, "output format.  One of: table, yaml, json")
+
+	return cmd
+}
+
+func RunToolboxGossipState(f *util.Factory, out io.Writer, options *ToolboxGossipStateOptions) error {
+	if options.ClusterName == "" {
+		return fmt.Errorf("ClusterName is required")
+	}
+
+	cluster, err := GetCluster(f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+	if !dns.IsGossipHostname(cluster.ObjectMeta.Name) {
+		return fmt.Errorf("cluster %q does not use gossip DNS", cluster.ObjectMeta.Name)
+	}
+
+	// TODO: Refactor into util.Factory
+	contextName := cluster.ObjectMeta.Name
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{CurrentContext: contextName}).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("cannot load kubecfg settings for %q: %v", contextName, err)
+	}
+
+	k8sClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("cannot build kubernetes api client for %q: %v", contextName, err)
+	}
+
+	pod, err := findHostNetworkPod(k8sClient, options.Node)
+	if err != nil {
+		return err
+	}
+
+	data, err := getThroughPortForward(config, k8sClient, pod, options.Port, gossip.StatusPath)
+	if err != nil {
+		return fmt.Errorf("error reading gossip state from node %q: %v", pod.Spec.NodeName, err)
+	}
+
+	status := &gossip.GossipStatus{}
+	if err := json.Unmarshal(data, status); err != nil {
+		return fmt.Errorf("error parsing gossip state from node %q: %v", pod.Spec.NodeName, err)
+	}
+
+	switch options.Output {
+	case OutputTable:
+		return gossipStateOutputTable(pod.Spec.NodeName, status, out)
+
+	case OutputYaml:
+		b, err := kops.ToRawYaml(status)
+		if err != nil {
+			return fmt.Errorf("error marshaling yaml: %v", err)
+		}
+		if _, err := out.Write(b); err != nil {
+			return fmt.Errorf("error writing to stdout: %v", err)
+		}
+		return nil
+
+	case OutputJSON:
+		b, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling json: %v", err)
+		}
+		if _, err := out.Write(b); err != nil {
+			return fmt.Errorf("error writing to stdout: %v", err)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("Unsupported output format: %q", options.Output)
+	}
+}
+
+// findHostNetworkPod finds a running pod which uses the host network on node, so we can reach ports bound to
+// the node's loopback address.  If node is empty we use the dns-controller pod, which runs on a master.
+func findHostNetworkPod(k8sClient kubernetes.Interface, node string) (*v1.Pod, error) {
+	var pods *v1.PodList
+	var err error
+	if node == "" {
+		pods, err = k8sClient.CoreV1().Pods("kube-system").List(metav1.ListOptions{LabelSelector: "k8s-app=dns-controller"})
+	} else {
+		pods, err = k8sClient.CoreV1().Pods("").List(metav1.ListOptions{FieldSelector: "spec.nodeName=" + node})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing pods: %v", err)
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.HostNetwork && pod.Status.Phase == v1.PodRunning {
+			return pod, nil
+		}
+	}
+
+	if node == "" {
+		return nil, fmt.Errorf("could not find a running dns-controller pod; use --node to select a node")
+	}
+	return nil, fmt.Errorf("could not find a running pod using the host network on node %q; read the gossip state over SSH instead", node)
+}
+
+// getThroughPortForward makes an HTTP GET request for path to port of pod, through a port-forward
+func getThroughPortForward(config *rest.Config, k8sClient kubernetes.Interface, pod *v1.Pod, port int, path string) ([]byte, error) {
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return nil, fmt.Errorf("error building port-forward transport: %v", err)
+	}
+	u := k8sClient.CoreV1().RESTClient().Post().Resource("pods").Namespace(pod.Namespace).Name(pod.Name).SubResource("portforward").URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, u)
+
+	conn, _, err := dialer.Dial(portforward.PortForwardProtocolV1Name)
+	if err != nil {
+		return nil, fmt.Errorf("error starting port-forward to pod %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+	defer conn.Close()
+
+	headers := http.Header{}
+	headers.Set(v1.StreamType, v1.StreamTypeError)
+	headers.Set(v1.PortHeader, strconv.Itoa(port))
+	headers.Set(v1.PortForwardRequestIDHeader, "0")
+	errorStream, err := conn.CreateStream(headers)
+	if err != nil {
+		return nil, fmt.Errorf("error creating port-forward error stream: %v", err)
+	}
+	// we're not writing to this stream
+	errorStream.Close()
+
+	headers.Set(v1.StreamType, v1.StreamTypeData)
+	dataStream, err := conn.CreateStream(headers)
+	if err != nil {
+		return nil, fmt.Errorf("error creating port-forward data stream: %v", err)
+	}
+	defer dataStream.Close()
+
+	req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:"+strconv.Itoa(port)+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error building request: %v", err)
+	}
+	req.Close = true
+	if err := req.Write(dataStream); err != nil {
+		return nil, fmt.Errorf("error sending request: %v", err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(dataStream), req)
+	if err != nil {
+		// The error stream explains why the port could not be reached
+		if message, _ := ioutil.ReadAll(errorStream); len(message) != 0 {
+			return nil, fmt.Errorf("error forwarding to port %d: %s", port, string(message))
+		}
+		return nil, fmt.Errorf("error reading response: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response %s: %s", resp.Status, string(body))
+	}
+	return body, nil
+}
+
+// gossipValue is a row in the table of gossiped values
+type gossipValue struct {
+	Key     string
+	Value   string
+	Version uint64
+	Deleted bool
+}
+
+func gossipStateOutputTable(node string, status *gossip.GossipStatus, out io.Writer) error {
+	fmt.Fprintf(out, "Node:     %s\n", node)
+	fmt.Fprintf(out, "Member:   %s\n", status.Name)
+	fmt.Fprintf(out, "Version:  %d\n", status.Version)
+
+	for _, m := range status.Meshes {
+		encryption := "unencrypted"
+		if m.Encrypted {
+			encryption = "encrypted"
+		}
+		// Older members don't report the protocol, which was always mesh
+		protocol := m.Protocol
+		if protocol == "" {
+			protocol = "mesh"
+		}
+		fmt.Fprintf(out, "\nGossip using %s with the %s secret (%s, port %d)\n\n", protocol, m.Secret, encryption, m.Port)
+
+		{
+			t := &tables.Table{}
+			t.AddColumn("PEER", func(p *gossip.GossipPeerStatus) string {
+				return p.NickName
+			})
+			t.AddColumn("NAME", func(p *gossip.GossipPeerStatus) string {
+				return p.Name
+			})
+			t.AddColumn("VERSION", func(p *gossip.GossipPeerStatus) string {
+				return strconv.FormatUint(p.Version, 10)
+			})
+			t.AddColumn("CONNECTED TO", func(p *gossip.GossipPeerStatus) string {
+				return strings.Join(p.Connections, ",")
+			})
+			var peers []*gossip.GossipPeerStatus
+			for i := range m.Peers {
+				peers = append(peers, &m.Peers[i])
+			}
+			if err := t.Render(peers, out, "PEER", "NAME", "VERSION", "CONNECTED TO"); err != nil {
+				return err
+			}
+		}
+
+		if len(m.Connections) != 0 {
+			fmt.Fprintf(out, "\n")
+			t := &tables.Table{}
+			t.AddColumn("ADDRESS", func(c *gossip.GossipConnectionStatus) string {
+				return c.Address
+			})
+			t.AddColumn("DIRECTION", func(c *gossip.GossipConnectionStatus) string {
+				if c.Outbound {
+					return "outbound"
+				}
+				return "inbound"
+			})
+			t.AddColumn("STATE", func(c *gossip.GossipConnectionStatus) string {
+				return c.State
+			})
+			t.AddColumn("INFO", func(c *gossip.GossipConnectionStatus) string {
+				return c.Info
+			})
+			var connections []*gossip.GossipConnectionStatus
+			for i := range m.Connections {
+				connections = append(connections, &m.Connections[i])
+			}
+			if err := t.Render(connections, out, "ADDRESS", "DIRECTION", "STATE", "INFO"); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Fprintf(out, "\n")
+	var values []*gossipValue
+	for k, version := range status.ValueVersions {
+		v, found := status.Values[k]
+		values = append(values, &gossipValue{Key: k, Value: v, Version: version, Deleted: !found})
+	}
+
+	t := &tables.Table{}
+	t.AddColumn("KEY", func(v *gossipValue) string {
+		return v.Key
+	})
+	t.AddColumn("VALUE", func(v *gossipValue) string {
+		if v.Deleted {
+			return "<deleted>"
+		}
+		return v.Value
+	})
+	t.AddColumn("VERSION", func(v *gossipValue) string {
+		return strconv.FormatUint(v.Version, 10)
+	})
+	return t.Render(values, out, "KEY", "VALUE", "VERSION")
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"k8s.io/kops/protokube/pkg/gossip"
+)
+
+func TestGossipStateOutputTable(t *testing.T) {
+	status := &gossip.GossipStatus{
+		Name:    "i-0123456789abcdef0",
+		Version: 7,
+		Values: map[string]string{
+			"dns/local/A/api.internal.example.k8s.local": "172.20.40.77",
+		},
+		ValueVersions: map[string]uint64{
+			"dns/local/A/api.internal.example.k8s.local":    1520000000,
+			"dns/local/A/etcd-a.internal.example.k8s.local": 1510000000,
+		},
+		Meshes: []gossip.GossipMeshStatus{
+			{
+				Protocol:  "mesh",
+				Secret:    "primary",
+				Encrypted: true,
+				Port:      3999,
+				Peers: []gossip.GossipPeerStatus{
+					{Name: "aa:bb", NickName: "i-0123456789abcdef0", Version: 3, Connections: []string{"i-0fedcba9876543210"}},
+					{Name: "cc:dd", NickName: "i-0fedcba9876543210", Version: 2, Connections: []string{"i-0123456789abcdef0"}},
+				},
+				Connections: []gossip.GossipConnectionStatus{
+					{Address: "172.20.50.10:3999", Outbound: true, State: "established"},
+				},
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := gossipStateOutputTable("ip-172-20-40-77.ec2.internal", status, &out); err != nil {
+		t.Fatalf("error rendering table: %v", err)
+	}
+
+	s := out.String()
+	for _, expected := range []string{
+		"Node:     ip-172-20-40-77.ec2.internal",
+		"Version:  7",
+		"Gossip using mesh with the primary secret (encrypted, port 3999)",
+		"i-0fedcba9876543210",
+		"172.20.50.10:3999",
+		"outbound",
+		"172.20.40.77",
+		"1520000000",
+		"<deleted>",
+	} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("expected %q in output:\n%s", expected, s)
+		}
+	}
+
+	// Removed values are listed with their version, so a stale tombstone can be spotted
+	for _, line := range strings.Split(s, "\n") {
+		if strings.Contains(line, "etcd-a.internal") && !strings.Contains(line, "1510000000") {
+			t.Errorf("expected the version of the removed value in %q", line)
+		}
+	}
+}
@@ -29,6 +29,7 @@ import (
 	api "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/kops/util"
 	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/model"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup"
 	"k8s.io/kops/util/pkg/tables"
@@ -193,28 +194,29 @@ func (c *UpgradeClusterCmd) Run(args []string) error {
 
 	// Prompt to upgrade image
 	if proposedKubernetesVersion != nil {
-		image := channel.FindImage(cloud.ProviderID(), *proposedKubernetesVersion)
+		for _, ig := range instanceGroups {
+			arch := model.InstanceGroupArchitecture(&cluster.Spec, ig)
+			image := channel.FindImage(cloud.ProviderID(), *proposedKubernetesVersion, string(arch))
+			if image == nil {
+				glog.Warningf("No matching %s images specified in channel; cannot prompt for upgrade of Instance Group %q", arch, ig.GetName())
+				continue
+			}
 
-		if image == nil {
-			glog.Warningf("No matching images specified in channel; cannot prompt for upgrade")
-		} else {
-			for _, ig := range instanceGroups {
-				if strings.Contains(ig.Spec.Image, "kope.io") {
-					if ig.Spec.Image != image.Name {
-						target := ig
-						actions = append(actions, &upgradeAction{
-							Item:     "InstanceGroup/" + target.ObjectMeta.Name,
-							Property: "Image",
-							Old:      target.Spec.Image,
-							New:      image.Name,
-							apply: func() {
-								target.Spec.Image = image.Name
-							},
-						})
-					}
-				} else {
-					glog.Infof("Custom image (%s) has been provided for Instance Group %q; not updating image", ig.Spec.Image, ig.GetName())
+			if strings.Contains(ig.Spec.Image, "kope.io") {
+				if ig.Spec.Image != image.Name {
+					target := ig
+					actions = append(actions, &upgradeAction{
+						Item:     "InstanceGroup/" + target.ObjectMeta.Name,
+						Property: "Image",
+						Old:      target.Spec.Image,
+						New:      image.Name,
+						apply: func() {
+							target.Spec.Image = image.Name
+						},
+					})
 				}
+			} else {
+				glog.Infof("Custom image (%s) has been provided for Instance Group %q; not updating image", ig.Spec.Image, ig.GetName())
 			}
 		}
 	}
@@ -19,22 +19,30 @@ package util
 import (
 	"fmt"
 	"net/url"
+	"path/filepath"
 	"strings"
 
 	"github.com/golang/glog"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/homedir"
+	azureblobacls "k8s.io/kops/pkg/acls/azureblob"
 	gceacls "k8s.io/kops/pkg/acls/gce"
 	s3acls "k8s.io/kops/pkg/acls/s3"
 	kopsclient "k8s.io/kops/pkg/client/clientset_generated/clientset"
 	"k8s.io/kops/pkg/client/simple"
 	"k8s.io/kops/pkg/client/simple/api"
+	"k8s.io/kops/pkg/client/simple/crdclientset"
 	"k8s.io/kops/pkg/client/simple/vfsclientset"
+	"k8s.io/kops/pkg/featureflag"
 	"k8s.io/kops/util/pkg/vfs"
 )
 
 type FactoryOptions struct {
 	RegistryPath string
+
+	// NoCache disables the local cache of state store reads, even when the VFSCache feature flag is set
+	NoCache bool
 }
 
 type Factory struct {
@@ -43,6 +51,7 @@ type Factory struct {
 }
 
 func NewFactory(options *FactoryOptions) *Factory {
+	azureblobacls.Register()
 	gceacls.Register()
 	s3acls.Register()
 
@@ -69,14 +78,24 @@ func (f *Factory) Clientset() (simple.Clientset, error) {
 			return nil, field.Required(field.NewPath("State Store"), STATE_ERROR)
 		}
 
-		// We recognize a `k8s` scheme; this might change in future so we won't document it yet
-		// In practice nobody is going to hit this accidentally, so I don't think we need a feature flag.
+		// We recognize a `k8s` scheme:
+		//   k8s://?context=<context>&configBase=<vfs path> stores objects as CRDs in a management cluster, found via the kubeconfig
+		//   k8s://<host> talks to the (experimental) kops-server at host
 		if strings.HasPrefix(registryPath, "k8s://") {
 			u, err := url.Parse(registryPath)
 			if err != nil {
 				return nil, fmt.Errorf("Invalid kops server url: %q", registryPath)
 			}
 
+			if u.Host == "" {
+				clientset, err := crdclientset.NewCRDClientsetForURL(u)
+				if err != nil {
+					return nil, err
+				}
+				f.clientset = clientset
+				return f.clientset, nil
+			}
+
 			u.Scheme = "https"
 
 			config := &rest.Config{
@@ -99,6 +118,12 @@ func (f *Factory) Clientset() (simple.Clientset, error) {
 				KopsClient: kopsClient.Kops(),
 			}
 		} else {
+			if featureflag.VFSCache.Enabled() && !f.options.NoCache {
+				cacheDir := filepath.Join(homedir.HomeDir(), ".kops", "cache")
+				glog.V(2).Infof("Caching state store reads in %s", cacheDir)
+				vfs.Context.SetCache(vfs.NewVFSCache(cacheDir))
+			}
+
 			basePath, err := vfs.Context.BuildVfsPath(registryPath)
 			if err != nil {
 				return nil, fmt.Errorf("error building path for %q: %v", registryPath, err)
@@ -17,6 +17,7 @@ limitations under the License.
 package main // import "k8s.io/kops/cmd/nodeup"
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
@@ -39,7 +40,13 @@ func main() {
 	if kops.GitVersion != "" {
 		gitVersion = " (git-" + kops.GitVersion + ")"
 	}
-	fmt.Printf("nodeup version %s%s\n", kops.Version, gitVersion)
+
+	// nodeup agent applies configuration changes to a running node; it is run periodically by a systemd timer
+	agent := false
+	if len(os.Args) > 1 && os.Args[1] == "agent" {
+		agent = true
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
 
 	var flagConf string
 	flag.StringVar(&flagConf, "conf", "node.yaml", "configuration location")
@@ -55,6 +62,11 @@ func main() {
 	target := "direct"
 	flag.StringVar(&target, "target", target, "Target - direct, cloudinit")
 
+	diagnose := false
+	flag.BoolVar(&diagnose, "diagnose", diagnose, "If true, will check the node against the configuration and print a report, without changing anything")
+	output := "text"
+	flag.StringVar(&output, "output", output, "Output format for diagnose - text, json")
+
 	installSystemdUnit := false
 	flag.BoolVar(&installSystemdUnit, "install-systemd-unit", installSystemdUnit, "If true, will install a systemd unit instead of running directly")
 
@@ -65,10 +77,45 @@ func main() {
 	flag.Set("logtostderr", "true")
 	flag.Parse()
 
+	if diagnose {
+		// Keep stdout for the report
+		fmt.Fprintf(os.Stderr, "nodeup version %s%s\n", kops.Version, gitVersion)
+	} else {
+		fmt.Printf("nodeup version %s%s\n", kops.Version, gitVersion)
+	}
+
 	if flagConf == "" {
 		glog.Exitf("--conf is required")
 	}
 
+	if diagnose {
+		cmd := &nodeup.NodeUpCommand{
+			ConfigLocation: flagConf,
+			Target:         target,
+			CacheDir:       flagCacheDir,
+			FSRoot:         flagRootFS,
+			ModelDir:       models.NewAssetPath("nodeup"),
+		}
+		if err := runDiagnose(cmd, output); err != nil {
+			glog.Exitf("error running nodeup --diagnose: %v", err)
+		}
+		return
+	}
+
+	if agent {
+		cmd := &nodeup.NodeUpCommand{
+			ConfigLocation: flagConf,
+			Target:         target,
+			CacheDir:       flagCacheDir,
+			FSRoot:         flagRootFS,
+			ModelDir:       models.NewAssetPath("nodeup"),
+		}
+		if err := cmd.RunAgent(); err != nil {
+			glog.Exitf("error running nodeup agent: %v", err)
+		}
+		return
+	}
+
 	retries := flagRetries
 
 	for {
@@ -142,3 +189,31 @@ func main() {
 		time.Sleep(retryInterval)
 	}
 }
+
+// runDiagnose checks the node and prints the report, exiting non-zero if there are any problems
+func runDiagnose(cmd *nodeup.NodeUpCommand, output string) error {
+	report, err := cmd.Diagnose()
+	if err != nil {
+		return err
+	}
+
+	switch output {
+	case "text":
+		if err := nodeup.PrintBootReport(os.Stdout, report); err != nil {
+			return err
+		}
+	case "json":
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error serializing report: %v", err)
+		}
+		fmt.Printf("%s\n", data)
+	default:
+		return fmt.Errorf("unsupported output format %q", output)
+	}
+
+	if report.HasProblems() {
+		os.Exit(1)
+	}
+	return nil
+}
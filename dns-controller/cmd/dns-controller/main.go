@@ -30,21 +30,28 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/pflag"
 
+	"k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	_ "k8s.io/kubernetes/pkg/client/metrics/prometheus" // for client metric registration
 
 	"k8s.io/kops/dns-controller/pkg/dns"
 	"k8s.io/kops/dns-controller/pkg/watchers"
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/aws/route53"
+	_ "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/azure"
+	_ "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/cloudflare"
 	k8scoredns "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/coredns"
 	_ "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/google/clouddns"
+	_ "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/rfc2136"
 	_ "k8s.io/kops/pkg/resources/digitalocean/dns"
 	"k8s.io/kops/protokube/pkg/gossip"
 	gossipdns "k8s.io/kops/protokube/pkg/gossip/dns"
 	gossipdnsprovider "k8s.io/kops/protokube/pkg/gossip/dns/provider"
-	"k8s.io/kops/protokube/pkg/gossip/mesh"
+	"k8s.io/kops/protokube/pkg/gossip/protocols"
 )
 
 var (
@@ -54,9 +61,10 @@ var (
 
 func main() {
 	fmt.Printf("dns-controller version %s\n", BuildVersion)
-	var dnsServer, dnsProviderID, gossipListen, gossipSecret, watchNamespace, metricsListen string
-	var gossipSeeds, zones []string
-	var watchIngress bool
+	var dnsServer, dnsProviderID, gossipListen, gossipSecret, gossipSecretSecondary, gossipStatusListen, watchNamespace, metricsListen, ownerID, dnsProviderConfig string
+	var gossipProtocol, gossipProtocolSecondary, gossipListenSecondary string
+	var gossipSeeds, gossipSeedsSecondary, zones []string
+	var watchIngress, recordStatus bool
 
 	// Be sure to get the glog flags
 	glog.Flush()
@@ -65,12 +73,21 @@ func main() {
 	flags.BoolVar(&watchIngress, "watch-ingress", true, "Configure hostnames found in ingress resources")
 	flags.StringSliceVar(&gossipSeeds, "gossip-seed", gossipSeeds, "If set, will enable gossip zones and seed using the provided addresses")
 	flags.StringSliceVarP(&zones, "zone", "z", []string{}, "Configure permitted zones and their mappings")
-	flags.StringVar(&dnsProviderID, "dns", "aws-route53", "DNS provider we should use (aws-route53, google-clouddns, digitalocean, coredns, gossip)")
+	flags.StringVar(&dnsProviderID, "dns", "aws-route53", "DNS provider we should use (aws-route53, google-clouddns, digitalocean, coredns, rfc2136, cloudflare, azure-dns, gossip)")
+	flags.StringVar(&dnsProviderConfig, "dns-provider-config", "", "Path to the configuration file of the DNS provider")
 	flags.StringVar(&gossipListen, "gossip-listen", "0.0.0.0:3998", "The address on which to listen if gossip is enabled")
 	flags.StringVar(&gossipSecret, "gossip-secret", gossipSecret, "Secret to use to secure gossip")
+	flags.StringVar(&gossipSecretSecondary, "gossip-secret-secondary", gossipSecretSecondary, "If set, gossip with peers using this secret as well as --gossip-secret, while the secret is rotated")
+	flags.StringVar(&gossipProtocol, "gossip-protocol", "mesh", "Gossip protocol to use if gossip is enabled (mesh, memberlist)")
+	flags.StringVar(&gossipProtocolSecondary, "gossip-protocol-secondary", "", "If set, also gossip using this protocol, copying values between the two, while the protocol is changed")
+	flags.StringVar(&gossipListenSecondary, "gossip-listen-secondary", "0.0.0.0:3993", "The address on which to listen for gossip using the secondary protocol")
+	flags.StringSliceVar(&gossipSeedsSecondary, "gossip-seed-secondary", gossipSeedsSecondary, "The addresses through which to join the gossip using the secondary protocol")
+	flags.StringVar(&gossipStatusListen, "gossip-status-listen", "", "The address on which to serve the read-only gossip status, if gossip is enabled")
 	flags.StringVar(&watchNamespace, "watch-namespace", "", "Limits the functionality for pods, services and ingress to specific namespace, by default all")
 	flag.IntVar(&route53.MaxBatchSize, "route53-batch-size", route53.MaxBatchSize, "Maximum number of operations performed per changeset batch")
 	flag.StringVar(&metricsListen, "metrics-listen", "", "The address on which to listen for Prometheus metrics.")
+	flags.StringVar(&ownerID, "txt-owner-id", "", "If set, records ownership of names in TXT records, and only changes or deletes records with this owner id")
+	flags.BoolVar(&recordStatus, "record-status", true, "Record the published names in the "+watchers.AnnotationNameDNSStatus+" annotation of services, ingresses and pods")
 
 	// Trick to avoid 'logging before flag.Parse' warning
 	flag.CommandLine.Parse([]string{})
@@ -112,6 +129,14 @@ func main() {
 			lines = append(lines, "zones = "+zones[0])
 			config := "[global]\n" + strings.Join(lines, "\n") + "\n"
 			file = bytes.NewReader([]byte(config))
+		} else if dnsProviderConfig != "" {
+			f, err := os.Open(dnsProviderConfig)
+			if err != nil {
+				glog.Errorf("Error opening DNS provider config %q: %v", dnsProviderConfig, err)
+				os.Exit(1)
+			}
+			defer f.Close()
+			file = f
 		}
 		dnsProvider, err := dnsprovider.GetDnsProvider(dnsProviderID, file)
 		if err != nil {
@@ -134,13 +159,47 @@ func main() {
 		}
 		gossipName := "dns-controller." + id
 
+		// An empty secondary secret is valid: it is the secret of a cluster which has never set one
+		var secondarySecret []byte
+		if flags.Changed("gossip-secret-secondary") {
+			secondarySecret = []byte(gossipSecretSecondary)
+		}
+
+		gossipConfig := &protocols.Config{
+			Protocol:        gossipProtocol,
+			Listen:          gossipListen,
+			Secret:          []byte(gossipSecret),
+			SecondarySecret: secondarySecret,
+			Seeds:           gossipSeeds,
+		}
+		var gossipConfigSecondary *protocols.Config
+		if gossipProtocolSecondary != "" {
+			if len(gossipSeedsSecondary) == 0 {
+				glog.Errorf("--gossip-seed-secondary must be set with --gossip-protocol-secondary")
+				os.Exit(1)
+			}
+			gossipConfigSecondary = &protocols.Config{
+				Protocol:        gossipProtocolSecondary,
+				Listen:          gossipListenSecondary,
+				Secret:          []byte(gossipSecret),
+				SecondarySecret: secondarySecret,
+				Seeds:           gossip.NewStaticSeedProvider(gossipSeedsSecondary),
+			}
+		}
+
 		channelName := "dns"
-		gossipState, err := mesh.NewMeshGossiper(gossipListen, channelName, gossipName, []byte(gossipSecret), gossipSeeds)
+		gossipState, err := protocols.NewBridgedGossiper(gossipConfig, gossipConfigSecondary, channelName, gossipName)
 		if err != nil {
 			glog.Errorf("Error initializing gossip: %v", err)
 			os.Exit(1)
 		}
 
+		if gossipStatusListen != "" {
+			go func() {
+				log.Fatal(gossip.ListenAndServeStatus(gossipStatusListen, gossipState))
+			}()
+		}
+
 		go func() {
 			err := gossipState.Start()
 			if err != nil {
@@ -163,7 +222,17 @@ func main() {
 		dnsProviders = append(dnsProviders, dnsProvider)
 	}
 
-	dnsController, err := dns.NewDNSController(dnsProviders, zoneRules)
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartLogging(glog.V(2).Infof)
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
+	recorder := broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: "dns-controller"})
+
+	var statusWriter dns.StatusWriter
+	if recordStatus {
+		statusWriter = watchers.NewStatusWriter(client)
+	}
+
+	dnsController, err := dns.NewDNSController(dnsProviders, zoneRules, ownerID, recorder, statusWriter)
 	if err != nil {
 		glog.Errorf("Error building DNS controller: %v", err)
 		os.Exit(1)
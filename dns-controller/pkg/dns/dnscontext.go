@@ -16,6 +16,10 @@ limitations under the License.
 
 package dns
 
+import (
+	"k8s.io/api/core/v1"
+)
+
 // Context represents a state of the world for DNS.
 // It is grouped by scopes & named keys, and controllers will replace those groups
 // The DNS controller will then merge all those record sets, resolve aliases etc,
@@ -29,7 +33,12 @@ type Context interface {
 
 type Scope interface {
 	// Replace sets the records for recordName to the provided set of records.
-	Replace(recordName string, records []Record)
+	// source is the object which requested the records, or nil if there is none;
+	// events and status for the records are reported on the source.
+	Replace(recordName string, source *v1.ObjectReference, records []Record)
+
+	// RecordEvent posts an event on the source object, e.g. because its records are not valid
+	RecordEvent(source *v1.ObjectReference, eventType, reason, message string)
 
 	// MarkReady should be called when a controller has populated all the records for a particular scope
 	MarkReady()
@@ -27,6 +27,9 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
 	"k8s.io/kops/dns-controller/pkg/util"
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
 	k8scoredns "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/coredns"
@@ -45,6 +48,16 @@ type DNSController struct {
 
 	dnsCache *dnsCache
 
+	// ownerID is written in TXT records for the names we manage; if set, we only change or delete records we own
+	ownerID string
+
+	// recorder posts events on the objects which requested records, if set
+	recorder record.EventRecorder
+	// statusWriter records the published names on the objects which requested them, if set
+	statusWriter StatusWriter
+	// writtenStatus is the description of the records we last recorded in the status of each object
+	writtenStatus map[v1.ObjectReference]string
+
 	// mutex protects the following mutable state
 	mutex sync.Mutex
 	// scopes is a map for each top-level grouping
@@ -75,13 +88,18 @@ type DNSControllerScope struct {
 
 	// Records is the map of actual records for this scope
 	Records map[string][]Record
+
+	// Sources is the object which requested the records, for each record name which has one
+	Sources map[string]v1.ObjectReference
 }
 
 // DNSControllerScope is a Scope
 var _ Scope = &DNSControllerScope{}
 
-// NewDnsController creates a DnsController
-func NewDNSController(dnsProviders []dnsprovider.Interface, zoneRules *ZoneRules) (*DNSController, error) {
+// NewDnsController creates a DnsController.  If ownerID is set, ownership of the names is recorded in TXT records.
+// If recorder is set, events are posted on the objects which requested records; if statusWriter is set, the
+// status of their records is recorded on them.
+func NewDNSController(dnsProviders []dnsprovider.Interface, zoneRules *ZoneRules, ownerID string, recorder record.EventRecorder, statusWriter StatusWriter) (*DNSController, error) {
 	dnsCache, err := newDNSCache(dnsProviders)
 	if err != nil {
 		return nil, fmt.Errorf("error initializing DNS cache: %v", err)
@@ -91,6 +109,11 @@ func NewDNSController(dnsProviders []dnsprovider.Interface, zoneRules *ZoneRules
 		scopes:    make(map[string]*DNSControllerScope),
 		zoneRules: zoneRules,
 		dnsCache:  dnsCache,
+		ownerID:   ownerID,
+
+		recorder:      recorder,
+		statusWriter:  statusWriter,
+		writtenStatus: make(map[v1.ObjectReference]string),
 	}
 
 	return c, nil
@@ -127,10 +150,21 @@ func (c *DNSController) runWatcher(stopCh <-chan struct{}) {
 
 type snapshot struct {
 	changeCount  uint64
-	records      []Record
+	records      []sourcedRecord
 	aliasTargets map[string][]Record
 
-	recordValues map[recordKey][]string
+	recordValues    map[recordKey][]string
+	recordTTLs      map[recordKey]time.Duration
+	recordPolicies  map[recordKey]dnsprovider.RoutingPolicy
+	recordMaxValues map[recordKey]int
+	// recordSources are the objects which requested each record
+	recordSources map[recordKey][]v1.ObjectReference
+}
+
+// sourcedRecord is a Record with the object which requested it, if any
+type sourcedRecord struct {
+	Record
+	source *v1.ObjectReference
 }
 
 func (c *DNSController) snapshotIfChangedAndReady() *snapshot {
@@ -159,15 +193,19 @@ func (c *DNSController) snapshotIfChangedAndReady() *snapshot {
 		}
 	}
 
-	records := make([]Record, 0, recordCount)
+	records := make([]sourcedRecord, 0, recordCount)
 	for _, scope := range c.scopes {
-		for _, scopeRecords := range scope.Records {
+		for recordName, scopeRecords := range scope.Records {
+			var source *v1.ObjectReference
+			if ref, found := scope.Sources[recordName]; found {
+				source = &ref
+			}
 			for i := range scopeRecords {
 				r := &scopeRecords[i]
 				if r.AliasTarget {
 					aliasTargets[r.FQDN] = append(aliasTargets[r.FQDN], *r)
 				} else {
-					records = append(records, *r)
+					records = append(records, sourcedRecord{Record: *r, source: source})
 				}
 			}
 		}
@@ -182,6 +220,28 @@ func (c *DNSController) snapshotIfChangedAndReady() *snapshot {
 type recordKey struct {
 	RecordType RecordType
 	FQDN       string
+	// SetIdentifier distinguishes record sets with a routing policy; it is "" for a simple record set
+	SetIdentifier string
+	// Role distinguishes the internal and external record sets for a name, which are published in different zones
+	// with split-horizon DNS
+	Role string
+}
+
+func (k recordKey) String() string {
+	if k.Role == "" {
+		return fmt.Sprintf("{%s %s %s}", k.RecordType, k.FQDN, k.SetIdentifier)
+	}
+	return fmt.Sprintf("{%s %s %s %s}", k.RecordType, k.FQDN, k.SetIdentifier, k.Role)
+}
+
+// keyForRecord returns the recordKey for a record published with the FQDN, routing policy and type
+func keyForRecord(r *Record, recordType RecordType) recordKey {
+	return recordKey{
+		RecordType:    recordType,
+		FQDN:          r.FQDN,
+		SetIdentifier: r.Policy.SetIdentifier,
+		Role:          r.Role,
+	}
 }
 
 func (c *DNSController) runOnce() error {
@@ -192,29 +252,45 @@ func (c *DNSController) runOnce() error {
 	}
 
 	newValueMap := make(map[recordKey][]string)
+	newTTLMap := make(map[recordKey]time.Duration)
+	newPolicyMap := make(map[recordKey]dnsprovider.RoutingPolicy)
+	newMaxValuesMap := make(map[recordKey]int)
+	newSourceMap := make(map[recordKey][]v1.ObjectReference)
+	// conflicts are the keys for which records specify different routing policies; we don't publish these
+	conflicts := make(map[recordKey]bool)
 	{
+		addRecord := func(key recordKey, r *sourcedRecord, value string) {
+			if existing, found := newPolicyMap[key]; found && existing != r.Policy {
+				conflicts[key] = true
+			}
+			newPolicyMap[key] = r.Policy
+			newValueMap[key] = append(newValueMap[key], value)
+			newTTLMap[key] = mergeTTL(newTTLMap[key], r.TTL)
+			newMaxValuesMap[key] = mergeMaxValues(newMaxValuesMap[key], r.MaxValues)
+			if r.source != nil {
+				newSourceMap[key] = appendSource(newSourceMap[key], *r.source)
+			}
+		}
+
 		// Resolve and build map
-		for _, r := range snapshot.records {
+		for i := range snapshot.records {
+			r := &snapshot.records[i]
 			if r.RecordType == RecordTypeAlias {
 				aliasRecords := snapshot.aliasTargets[r.Value]
 				if len(aliasRecords) == 0 {
 					glog.Infof("Alias in record specified %q, but no records were found for that name", r.Value)
+					if r.source != nil {
+						c.recordEvent([]v1.ObjectReference{*r.source}, v1.EventTypeWarning, EventReasonRecordsFailed,
+							fmt.Sprintf("No addresses were found for %s, so no records were published for it", r.FQDN))
+					}
 				}
 				for _, aliasRecord := range aliasRecords {
-					key := recordKey{
-						RecordType: aliasRecord.RecordType,
-						FQDN:       r.FQDN,
-					}
 					// TODO: Support chains: alias of alias (etc)
-					newValueMap[key] = append(newValueMap[key], aliasRecord.Value)
+					addRecord(keyForRecord(&r.Record, aliasRecord.RecordType), r, aliasRecord.Value)
 				}
 				continue
 			} else {
-				key := recordKey{
-					RecordType: r.RecordType,
-					FQDN:       r.FQDN,
-				}
-				newValueMap[key] = append(newValueMap[key], r.Value)
+				addRecord(keyForRecord(&r.Record, r.RecordType), r, r.Value)
 				continue
 			}
 		}
@@ -225,20 +301,61 @@ func (c *DNSController) runOnce() error {
 			newValueMap[k] = values
 		}
 		snapshot.recordValues = newValueMap
+		snapshot.recordTTLs = newTTLMap
+		snapshot.recordPolicies = newPolicyMap
+		snapshot.recordMaxValues = newMaxValuesMap
+		snapshot.recordSources = newSourceMap
 	}
+	recordSets.Set(float64(len(newValueMap)))
 
 	var oldValueMap map[recordKey][]string
+	var oldTTLMap map[recordKey]time.Duration
+	var oldPolicyMap map[recordKey]dnsprovider.RoutingPolicy
+	var oldMaxValuesMap map[recordKey]int
+	var oldSourceMap map[recordKey][]v1.ObjectReference
 	if c.lastSuccessfulSnapshot != nil {
 		oldValueMap = c.lastSuccessfulSnapshot.recordValues
+		oldTTLMap = c.lastSuccessfulSnapshot.recordTTLs
+		oldPolicyMap = c.lastSuccessfulSnapshot.recordPolicies
+		oldMaxValuesMap = c.lastSuccessfulSnapshot.recordMaxValues
+		oldSourceMap = c.lastSuccessfulSnapshot.recordSources
 	}
 
-	op, err := newDNSOp(c.zoneRules, c.dnsCache)
+	op, err := newDNSOp(c.zoneRules, c.dnsCache, c.ownerID)
 	if err != nil {
 		return err
 	}
 
 	// Store a list of all the errors, so that one bad apple doesn't block every other request
 	var errors []error
+	// failed is the set of keys which we could not update
+	failed := make(map[recordKey]bool)
+
+	// zonesForKey returns the zones in which we publish the records for k, when valueMap holds all the records
+	zonesForKey := func(k recordKey, valueMap map[recordKey][]string) []dnsprovider.Zone {
+		internal := k
+		internal.Role = RoleTypeInternal
+		return op.findZones(EnsureDotSuffix(k.FQDN), k.Role, valueMap[internal] != nil)
+	}
+
+	// wantedNames are the names we publish records for, in each zone
+	wantedNames := make(map[string]bool)
+	// wantedRecords are the record sets (see zonedRecord) we publish
+	wantedRecords := make(map[string]bool)
+	for k := range newValueMap {
+		fqdn := EnsureDotSuffix(k.FQDN)
+		for _, zone := range zonesForKey(k, newValueMap) {
+			wantedNames[zonedName(zone, fqdn)] = true
+			wantedRecords[zonedRecord(zone, k)] = true
+		}
+	}
+
+	if c.ownerID != "" && c.lastSuccessfulSnapshot == nil {
+		if err := op.deleteOrphanedRecords(wantedNames); err != nil {
+			glog.Infof("error deleting orphaned records: %v", err)
+			errors = append(errors, err)
+		}
+	}
 
 	// Check each hostname for changes and apply them
 	for k, newValues := range newValueMap {
@@ -247,13 +364,28 @@ func (c *DNSController) runOnce() error {
 		}
 		oldValues := oldValueMap[k]
 
-		if util.StringSlicesEqual(newValues, oldValues) {
+		if conflicts[k] {
+			err := fmt.Errorf("not updating records for %s, which are specified with different routing policies", k)
+			c.recordFailure(newSourceMap[k], err)
+			errors = append(errors, err)
+			failed[k] = true
+			continue
+		}
+
+		ttl := newTTLMap[k]
+		policy := newPolicyMap[k]
+		maxValues := newMaxValuesMap[k]
+		zones := zonesForKey(k, newValueMap)
+		if util.StringSlicesEqual(newValues, oldValues) && ttl == oldTTLMap[k] && policy == oldPolicyMap[k] && maxValues == oldMaxValuesMap[k] &&
+			sameZones(zones, zonesForKey(k, oldValueMap)) {
 			glog.V(4).Infof("no change to records for %s", k)
 			continue
 		}
 
-		ttl := DefaultTTL
-		glog.Infof("Using default TTL of %v", ttl)
+		if ttl == 0 {
+			ttl = DefaultTTL
+			glog.Infof("Using default TTL of %v", ttl)
+		}
 
 		glog.V(4).Infof("updating records for %s: %v -> %v", k, oldValues, newValues)
 
@@ -274,10 +406,19 @@ func (c *DNSController) runOnce() error {
 			dedup = append(dedup, s)
 		}
 
-		err := op.updateRecords(k, dedup, int64(ttl.Seconds()))
+		// The values are sorted, so we consistently publish the same subset
+		if maxValues != 0 && len(dedup) > maxValues {
+			glog.V(2).Infof("publishing %d of the %d values for %s", maxValues, len(dedup), k)
+			dedup = dedup[:maxValues]
+		}
+
+		// Records are not deleted from a zone we stop publishing them in, because that only happens when
+		// internal records for the same name and type replace them in the private zone
+		err := op.updateRecords(k, zones, dedup, int64(ttl.Seconds()), policy)
 		if err != nil {
-			glog.Infof("error updating records for %s: %v", k, err)
+			c.recordFailure(newSourceMap[k], fmt.Errorf("error updating records for %s: %v", k, err))
 			errors = append(errors, err)
+			failed[k] = true
 		}
 	}
 
@@ -289,25 +430,81 @@ func (c *DNSController) runOnce() error {
 
 		newValues := newValueMap[k]
 		if newValues == nil {
-			err := op.deleteRecords(k)
-			if err != nil {
-				glog.Infof("error deleting records for %s: %v", k, err)
-				errors = append(errors, err)
+			// We leave the record sets which other records replace, such as external records in the private
+			// zone once the internal records for the same name are removed
+			oldZones := zonesForKey(k, oldValueMap)
+			var zones []dnsprovider.Zone
+			for _, zone := range oldZones {
+				if !wantedRecords[zonedRecord(zone, k)] {
+					zones = append(zones, zone)
+				}
+			}
+
+			if len(oldZones) == 0 || len(zones) != 0 {
+				err := op.deleteRecords(k, zones)
+				if err != nil {
+					c.recordFailure(oldSourceMap[k], fmt.Errorf("error deleting records for %s: %v", k, err))
+					errors = append(errors, err)
+					failed[k] = true
+				}
+			}
+
+			fqdn := EnsureDotSuffix(k.FQDN)
+			if c.ownerID != "" {
+				for _, zone := range oldZones {
+					name := zonedName(zone, fqdn)
+					if !wantedNames[name] && !op.released[name] {
+						if err := op.releaseOwnership(zone, fqdn); err != nil {
+							glog.Infof("error releasing ownership of %s: %v", fqdn, err)
+							errors = append(errors, err)
+						}
+						op.released[name] = true
+					}
+				}
 			}
 		}
 	}
 
 	for key, changeset := range op.changesets {
 		glog.V(2).Infof("applying DNS changeset for zone %s", key)
-		if err := changeset.Apply(); err != nil {
+		zoneName := op.changesetZones[key]
+		start := time.Now()
+		err := changeset.Apply()
+		changesetApplyDuration.WithLabelValues(zoneName).Observe(time.Since(start).Seconds())
+
+		result := resultSuccess
+		if err != nil {
+			result = resultError
 			glog.Warningf("error applying DNS changeset for zone %s: %v", key, err)
 			errors = append(errors, fmt.Errorf("error applying DNS changeset for zone %s: %v", key, err))
 		}
+
+		for _, change := range op.changes[key] {
+			recordOperationsTotal.WithLabelValues(zoneName, change.operation, result).Inc()
+
+			sources := newSourceMap[change.key]
+			if change.operation == operationDelete {
+				sources = oldSourceMap[change.key]
+			}
+			if err != nil {
+				failed[change.key] = true
+				c.recordEvent(sources, v1.EventTypeWarning, EventReasonRecordsFailed,
+					fmt.Sprintf("Error applying DNS changes for %s %s to zone %s: %v", change.key.RecordType, change.key.FQDN, zoneName, err))
+			} else if change.operation == operationUpsert {
+				c.recordEvent(sources, v1.EventTypeNormal, EventReasonRecordsPublished,
+					fmt.Sprintf("Published %s records for %s in zone %s: %s", change.key.RecordType, change.key.FQDN, zoneName, strings.Join(change.values, ",")))
+			}
+		}
 	}
 
+	c.writeStatus(snapshot, failed)
+
 	if len(errors) != 0 {
+		syncsTotal.WithLabelValues(resultError).Inc()
 		return errors[0]
 	}
+	syncsTotal.WithLabelValues(resultSuccess).Inc()
+	lastSuccessfulSync.SetToCurrentTime()
 
 	// Success!  Store the snapshot as our new baseline
 	c.mutex.Lock()
@@ -318,14 +515,29 @@ func (c *DNSController) runOnce() error {
 
 // dnsOp manages a single dns change; we cache results and state for the duration of the operation
 type dnsOp struct {
-	dnsCache     *dnsCache
-	zones        map[string]dnsprovider.Zone
+	dnsCache *dnsCache
+	// zones are the zones we manage, by name
+	zones map[string]dnsprovider.Zone
+	// roleZones are the zones we manage which are only used for records with a role, by role and name
+	roleZones map[string]map[string]dnsprovider.Zone
+
 	recordsCache map[string][]dnsprovider.ResourceRecordSet
 
 	changesets map[string]dnsprovider.ResourceRecordChangeset
+	// changesetZones is the name of the zone of each changeset
+	changesetZones map[string]string
+	// changes are the record sets changed by each changeset
+	changes map[string][]trackedChange
+
+	// ownerID is our owner id for ownership records, or "" if ownership is not recorded
+	ownerID string
+	// claimed is the set of names (see zonedName) whose ownership record we have written in this dnsOp
+	claimed map[string]bool
+	// released is the set of names (see zonedName) whose ownership record we have removed in this dnsOp
+	released map[string]bool
 }
 
-func newDNSOp(zoneRules *ZoneRules, dnsCache *dnsCache) (*dnsOp, error) {
+func newDNSOp(zoneRules *ZoneRules, dnsCache *dnsCache, ownerID string) (*dnsOp, error) {
 	zones, err := dnsCache.ListZones(zoneListCacheValidity)
 	if err != nil {
 		return nil, fmt.Errorf("error querying for zones: %v", err)
@@ -340,17 +552,27 @@ func newDNSOp(zoneRules *ZoneRules, dnsCache *dnsCache) (*dnsOp, error) {
 	}
 
 	zoneMap := make(map[string]dnsprovider.Zone)
+	roleZoneMap := map[string]map[string]dnsprovider.Zone{
+		RoleTypeInternal: make(map[string]dnsprovider.Zone),
+		RoleTypeExternal: make(map[string]dnsprovider.Zone),
+	}
 	for name, zones := range allZoneMap {
-		var matches []dnsprovider.Zone
+		var matches, unmatched []dnsprovider.Zone
+		roleMatches := make(map[string][]dnsprovider.Zone)
 		for _, zone := range zones {
-			if zoneRules.MatchesExplicitly(zone) {
+			zoneSpec := zoneRules.Match(zone)
+			if zoneSpec == nil {
+				unmatched = append(unmatched, zone)
+			} else if zoneSpec.Role != "" {
+				roleMatches[zoneSpec.Role] = append(roleMatches[zoneSpec.Role], zone)
+			} else {
 				matches = append(matches, zone)
 			}
 		}
 
 		if len(matches) == 0 && zoneRules.Wildcard {
-			// No explicit matches but wildcard; treat everything as matching
-			matches = append(matches, zones...)
+			// No explicit matches but wildcard; treat everything not matched for a role as matching
+			matches = append(matches, unmatched...)
 		}
 
 		if len(matches) == 1 {
@@ -358,13 +580,28 @@ func newDNSOp(zoneRules *ZoneRules, dnsCache *dnsCache) (*dnsOp, error) {
 		} else if len(matches) > 1 {
 			glog.Warningf("Found multiple zones for name %q, won't manage zone (To fix: provide zone mapping flag with ID of zone)", name)
 		}
+
+		for role, matches := range roleMatches {
+			if len(matches) == 1 {
+				roleZoneMap[role][name] = matches[0]
+			} else {
+				glog.Warningf("Found multiple %s zones for name %q, won't manage zone (To fix: provide zone mapping flag with ID of zone)", role, name)
+			}
+		}
 	}
 
 	o := &dnsOp{
 		dnsCache:     dnsCache,
 		zones:        zoneMap,
+		roleZones:    roleZoneMap,
 		changesets:   make(map[string]dnsprovider.ResourceRecordChangeset),
 		recordsCache: make(map[string][]dnsprovider.ResourceRecordSet),
+		ownerID:      ownerID,
+		claimed:      make(map[string]bool),
+		released:     make(map[string]bool),
+
+		changesetZones: make(map[string]string),
+		changes:        make(map[string][]trackedChange),
 	}
 
 	return o, nil
@@ -377,11 +614,37 @@ func EnsureDotSuffix(s string) string {
 	return s
 }
 
-func (o *dnsOp) findZone(fqdn string) dnsprovider.Zone {
+// findZones returns the zones in which to publish the records with the role for fqdn.
+// With split-horizon DNS the private zone hides the public zone from inside the VPC, so external records (and records
+// without a role) are also published in the private zone, unless hasInternal is set because there are internal
+// records for the same name and type, which take precedence there.
+func (o *dnsOp) findZones(fqdn string, role string, hasInternal bool) []dnsprovider.Zone {
+	var zones []dnsprovider.Zone
+	zone := o.findZone(fqdn, role)
+	if zone != nil {
+		zones = append(zones, zone)
+	}
+	if role != RoleTypeInternal && !hasInternal {
+		if private := o.findRoleZone(fqdn, RoleTypeInternal); private != nil && (zone == nil || zoneKey(private) != zoneKey(zone)) {
+			zones = append(zones, private)
+		}
+	}
+	return zones
+}
+
+// findZone returns the zone in which to publish the records with the role for fqdn: the zone with the longest
+// name which fqdn is in, preferring the zone for the role where there are zones for roles with that name
+func (o *dnsOp) findZone(fqdn string, role string) dnsprovider.Zone {
+	if role == "" {
+		role = RoleTypeExternal
+	}
+
 	zoneName := EnsureDotSuffix(fqdn)
 	for {
-		zone := o.zones[zoneName]
-		if zone != nil {
+		if zone := o.roleZones[role][zoneName]; zone != nil {
+			return zone
+		}
+		if zone := o.zones[zoneName]; zone != nil {
 			return zone
 		}
 		dot := strings.IndexByte(zoneName, '.')
@@ -392,8 +655,65 @@ func (o *dnsOp) findZone(fqdn string) dnsprovider.Zone {
 	}
 }
 
+// findRoleZone returns the zone with the longest name which fqdn is in, out of the zones only used for the role
+func (o *dnsOp) findRoleZone(fqdn string, role string) dnsprovider.Zone {
+	zoneName := EnsureDotSuffix(fqdn)
+	for {
+		if zone := o.roleZones[role][zoneName]; zone != nil {
+			return zone
+		}
+		dot := strings.IndexByte(zoneName, '.')
+		if dot == -1 {
+			return nil
+		}
+		zoneName = zoneName[dot+1:]
+	}
+}
+
+// zoneKey identifies a zone; there can be several zones with the same name
+func zoneKey(zone dnsprovider.Zone) string {
+	return zone.Name() + "::" + zone.ID()
+}
+
+// zonedName identifies the name fqdn in a zone
+func zonedName(zone dnsprovider.Zone, fqdn string) string {
+	return zoneKey(zone) + "::" + fqdn
+}
+
+// zonedRecord identifies the record set for k in a zone; records with different roles share the record set
+func zonedRecord(zone dnsprovider.Zone, k recordKey) string {
+	return zonedName(zone, EnsureDotSuffix(k.FQDN)) + "::" + string(k.RecordType) + "::" + k.SetIdentifier
+}
+
+// sameZones returns true if l and r are the same zones, in the same order
+func sameZones(l, r []dnsprovider.Zone) bool {
+	if len(l) != len(r) {
+		return false
+	}
+	for i := range l {
+		if zoneKey(l[i]) != zoneKey(r[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// allZones returns every zone we manage
+func (o *dnsOp) allZones() []dnsprovider.Zone {
+	var zones []dnsprovider.Zone
+	for _, zone := range o.zones {
+		zones = append(zones, zone)
+	}
+	for _, roleZones := range o.roleZones {
+		for _, zone := range roleZones {
+			zones = append(zones, zone)
+		}
+	}
+	return zones
+}
+
 func (o *dnsOp) getChangeset(zone dnsprovider.Zone) (dnsprovider.ResourceRecordChangeset, error) {
-	key := zone.Name() + "::" + zone.ID()
+	key := zoneKey(zone)
 	changeset := o.changesets[key]
 	if changeset == nil {
 		rrsProvider, ok := zone.ResourceRecordSets()
@@ -402,14 +722,28 @@ func (o *dnsOp) getChangeset(zone dnsprovider.Zone) (dnsprovider.ResourceRecordC
 		}
 		changeset = rrsProvider.StartChangeset()
 		o.changesets[key] = changeset
+		o.changesetZones[key] = zone.Name()
 	}
 
 	return changeset, nil
 }
 
+// trackedChange is a change to a record set, which we track so that we can report the result when the changeset is applied
+type trackedChange struct {
+	key       recordKey
+	operation string
+	values    []string
+}
+
+// trackChange records that the changeset for zone changes the record set for k
+func (o *dnsOp) trackChange(zone dnsprovider.Zone, k recordKey, operation string, values []string) {
+	key := zoneKey(zone)
+	o.changes[key] = append(o.changes[key], trackedChange{key: k, operation: operation, values: values})
+}
+
 // listRecords is a wrapper around listing records, but will cache the results for the duration of the dnsOp
 func (o *dnsOp) listRecords(zone dnsprovider.Zone) ([]dnsprovider.ResourceRecordSet, error) {
-	key := zone.Name() + "::" + zone.ID()
+	key := zoneKey(zone)
 
 	rrs := o.recordsCache[key]
 	if rrs == nil {
@@ -431,17 +765,37 @@ func (o *dnsOp) listRecords(zone dnsprovider.Zone) ([]dnsprovider.ResourceRecord
 	return rrs, nil
 }
 
-func (o *dnsOp) deleteRecords(k recordKey) error {
+// deleteRecords deletes the records for k from each of the zones
+func (o *dnsOp) deleteRecords(k recordKey, zones []dnsprovider.Zone) error {
 	glog.V(2).Infof("Deleting all records for %s", k)
 
 	fqdn := EnsureDotSuffix(k.FQDN)
 
-	zone := o.findZone(fqdn)
-	if zone == nil {
+	if len(zones) == 0 {
 		// TODO: Post event into service / pod
 		return fmt.Errorf("no suitable zone found for %q", fqdn)
 	}
 
+	for _, zone := range zones {
+		if err := o.deleteZoneRecords(zone, fqdn, k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *dnsOp) deleteZoneRecords(zone dnsprovider.Zone, fqdn string, k recordKey) error {
+	if o.ownerID != "" {
+		owner, err := o.getOwnership(zone, fqdn)
+		if err != nil {
+			return err
+		}
+		if owner != ownershipOwned {
+			glog.Warningf("Not deleting records for %s, which are not owned by this dns-controller", k)
+			return nil
+		}
+	}
+
 	// TODO: work-around before ResourceRecordSets.List() is implemented for CoreDNS
 	if isCoreDNSZone(zone) {
 		rrsProvider, ok := zone.ResourceRecordSets()
@@ -455,7 +809,7 @@ func (o *dnsOp) deleteRecords(k recordKey) error {
 		}
 
 		for _, dnsRecord := range dnsRecords {
-			if string(dnsRecord.Type()) == string(k.RecordType) {
+			if string(dnsRecord.Type()) == string(k.RecordType) && dnsprovider.GetRoutingPolicy(dnsRecord).SetIdentifier == k.SetIdentifier {
 				cs, err := o.getChangeset(zone)
 				if err != nil {
 					return err
@@ -463,6 +817,7 @@ func (o *dnsOp) deleteRecords(k recordKey) error {
 
 				glog.V(2).Infof("Deleting resource record %s %s", fqdn, k.RecordType)
 				cs.Remove(dnsRecord)
+				o.trackChange(zone, k, operationDelete, nil)
 			}
 		}
 
@@ -490,9 +845,14 @@ func (o *dnsOp) deleteRecords(k recordKey) error {
 			glog.V(8).Infof("Skipping delete of record %q (type %s != %s)", rrName, rr.Type(), k.RecordType)
 			continue
 		}
+		if setIdentifier := dnsprovider.GetRoutingPolicy(rr).SetIdentifier; setIdentifier != k.SetIdentifier {
+			glog.V(8).Infof("Skipping delete of record %q (set identifier %q != %q)", rrName, setIdentifier, k.SetIdentifier)
+			continue
+		}
 
 		glog.V(2).Infof("Deleting resource record %s %s", rrName, rr.Type())
 		cs.Remove(rr)
+		o.trackChange(zone, k, operationDelete, nil)
 	}
 
 	return nil
@@ -507,20 +867,35 @@ func FixWildcards(s string) string {
 	return strings.Replace(s, "\\052", "*", 1)
 }
 
-func (o *dnsOp) updateRecords(k recordKey, newRecords []string, ttl int64) error {
+// updateRecords publishes the records for k in each of the zones
+func (o *dnsOp) updateRecords(k recordKey, zones []dnsprovider.Zone, newRecords []string, ttl int64, policy dnsprovider.RoutingPolicy) error {
 	fqdn := EnsureDotSuffix(k.FQDN)
 
-	zone := o.findZone(fqdn)
-	if zone == nil {
+	if len(zones) == 0 {
 		// TODO: Post event into service / pod
 		return fmt.Errorf("no suitable zone found for %q", fqdn)
 	}
 
+	for _, zone := range zones {
+		if err := o.updateZoneRecords(zone, fqdn, k, newRecords, ttl, policy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *dnsOp) updateZoneRecords(zone dnsprovider.Zone, fqdn string, k recordKey, newRecords []string, ttl int64, policy dnsprovider.RoutingPolicy) error {
 	rrsProvider, ok := zone.ResourceRecordSets()
 	if !ok {
 		return fmt.Errorf("zone does not support resource records %q", zone.Name())
 	}
 
+	// We build the record set first, so that we reject routing policies the provider does not support before changing anything
+	rr, err := dnsprovider.NewWithRoutingPolicy(rrsProvider, fqdn, newRecords, ttl, rrstype.RrsType(k.RecordType), policy)
+	if err != nil {
+		return fmt.Errorf("cannot publish records for %s in zone %q: %v", k, zone.Name(), err)
+	}
+
 	var existing dnsprovider.ResourceRecordSet
 	// TODO: work-around before ResourceRecordSets.List() is implemented for CoreDNS
 	if isCoreDNSZone(zone) {
@@ -530,7 +905,7 @@ func (o *dnsOp) updateRecords(k recordKey, newRecords []string, ttl int64) error
 		}
 
 		for _, dnsRecord := range dnsRecords {
-			if string(dnsRecord.Type()) == string(k.RecordType) {
+			if string(dnsRecord.Type()) == string(k.RecordType) && dnsprovider.GetRoutingPolicy(dnsRecord).SetIdentifier == k.SetIdentifier {
 				glog.V(8).Infof("Found matching record: %s %s", k.RecordType, fqdn)
 				existing = dnsRecord
 			}
@@ -552,6 +927,10 @@ func (o *dnsOp) updateRecords(k recordKey, newRecords []string, ttl int64) error
 				glog.V(8).Infof("Skipping record %q (type %s != %s)", rrName, rr.Type(), k.RecordType)
 				continue
 			}
+			if setIdentifier := dnsprovider.GetRoutingPolicy(rr).SetIdentifier; setIdentifier != k.SetIdentifier {
+				glog.V(8).Infof("Skipping record %q (set identifier %q != %q)", rrName, setIdentifier, k.SetIdentifier)
+				continue
+			}
 
 			if existing != nil {
 				glog.Warningf("Found multiple matching records: %v and %v", existing, rr)
@@ -562,18 +941,57 @@ func (o *dnsOp) updateRecords(k recordKey, newRecords []string, ttl int64) error
 		}
 	}
 
+	if o.ownerID != "" {
+		owner, err := o.getOwnership(zone, fqdn)
+		if err != nil {
+			return err
+		}
+		if owner == ownershipForeign {
+			return fmt.Errorf("not updating records for %s, which are not owned by this dns-controller", k)
+		}
+		if err := o.claimOwnership(zone, fqdn); err != nil {
+			return err
+		}
+	}
+
 	cs, err := o.getChangeset(zone)
 	if err != nil {
 		return err
 	}
 
 	glog.V(2).Infof("Adding DNS changes to batch %s %s", k, newRecords)
-	rr := rrsProvider.New(fqdn, newRecords, ttl, rrstype.RrsType(k.RecordType))
 	cs.Upsert(rr)
+	o.trackChange(zone, k, operationUpsert, newRecords)
 
 	return nil
 }
 
+// mergeTTL combines the TTLs of records with the same name and type, choosing the smallest that is set
+func mergeTTL(l, r time.Duration) time.Duration {
+	if l == 0 || (r != 0 && r < l) {
+		return r
+	}
+	return l
+}
+
+// mergeMaxValues combines the MaxValues of records with the same name and type, choosing the smallest that is set
+func mergeMaxValues(l, r int) int {
+	if l == 0 || (r != 0 && r < l) {
+		return r
+	}
+	return l
+}
+
+// appendSource appends source to sources, if it is not already present
+func appendSource(sources []v1.ObjectReference, source v1.ObjectReference) []v1.ObjectReference {
+	for _, s := range sources {
+		if s == source {
+			return sources
+		}
+	}
+	return append(sources, source)
+}
+
 func (c *DNSController) recordChange() {
 	atomic.AddUint64(&c.changeCount, 1)
 }
@@ -585,11 +1003,12 @@ func (s *DNSControllerScope) MarkReady() {
 	s.Ready = true
 }
 
-func (s *DNSControllerScope) Replace(recordName string, records []Record) {
+func (s *DNSControllerScope) Replace(recordName string, source *v1.ObjectReference, records []Record) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	existing, exists := s.Records[recordName]
+	existingSource, hasSource := s.Sources[recordName]
 
 	if len(records) == 0 {
 		if !exists {
@@ -598,19 +1017,34 @@ func (s *DNSControllerScope) Replace(recordName string, records []Record) {
 		}
 
 		delete(s.Records, recordName)
+		delete(s.Sources, recordName)
 	} else {
-		if recordsSliceEquals(existing, records) {
+		sourceUnchanged := (source == nil && !hasSource) || (source != nil && hasSource && *source == existingSource)
+		if recordsSliceEquals(existing, records) && sourceUnchanged {
 			glog.V(6).Infof("skipping spurious update of record %s/%s=%s", s.ScopeName, recordName, records)
 			return
 		}
 
 		s.Records[recordName] = records
+		if source != nil {
+			s.Sources[recordName] = *source
+		} else {
+			delete(s.Sources, recordName)
+		}
 	}
 
 	glog.V(2).Infof("Update desired state: %s/%s: %v", s.ScopeName, recordName, records)
 	s.parent.recordChange()
 }
 
+// RecordEvent implements Scope::RecordEvent, posting an event on the source object
+func (s *DNSControllerScope) RecordEvent(source *v1.ObjectReference, eventType, reason, message string) {
+	if source == nil {
+		return
+	}
+	s.parent.recordEvent([]v1.ObjectReference{*source}, eventType, reason, message)
+}
+
 // AllKeys implements Scope::AllKeys, returns all the keys in the current scope
 func (s *DNSControllerScope) AllKeys() []string {
 	s.mutex.Lock()
@@ -651,6 +1085,7 @@ func (c *DNSController) CreateScope(scopeName string) (Scope, error) {
 	s = &DNSControllerScope{
 		ScopeName: scopeName,
 		Records:   make(map[string][]Record),
+		Sources:   make(map[string]v1.ObjectReference),
 		parent:    c,
 		Ready:     false,
 	}
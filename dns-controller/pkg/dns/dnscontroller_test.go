@@ -0,0 +1,390 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	awsroute53 "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/aws/route53"
+	route53stubs "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/aws/route53/stubs"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/google/clouddns"
+)
+
+func newRoute53TestProvider(t *testing.T) dnsprovider.Interface {
+	provider := awsroute53.New(route53stubs.NewRoute53APIStub())
+	zones, _ := provider.Zones()
+	zone, err := zones.New("example.com")
+	if err != nil {
+		t.Fatalf("error building zone: %v", err)
+	}
+	if _, err := zones.Add(zone); err != nil {
+		t.Fatalf("error adding zone: %v", err)
+	}
+	return provider
+}
+
+// newTestController builds a DNSController for provider, with a ready scope containing records
+func newTestController(t *testing.T, provider dnsprovider.Interface, records []Record) *DNSController {
+	return newTestControllerWithSource(t, provider, nil, nil, nil, records)
+}
+
+// newTestControllerWithSource builds a DNSController for provider which reports events and status, with a ready scope containing records from source
+func newTestControllerWithSource(t *testing.T, provider dnsprovider.Interface, recorder record.EventRecorder, statusWriter StatusWriter, source *v1.ObjectReference, records []Record) *DNSController {
+	c, err := NewDNSController([]dnsprovider.Interface{provider}, &ZoneRules{Wildcard: true}, "", recorder, statusWriter)
+	if err != nil {
+		t.Fatalf("error building controller: %v", err)
+	}
+	scope, err := c.CreateScope("test")
+	if err != nil {
+		t.Fatalf("error creating scope: %v", err)
+	}
+	scope.Replace("default/test", source, records)
+	scope.MarkReady()
+	return c
+}
+
+// listTestRecords returns the record sets in the first zone of provider, keyed by name, type and set identifier
+func listTestRecords(t *testing.T, provider dnsprovider.Interface) map[string]dnsprovider.ResourceRecordSet {
+	zones, _ := provider.Zones()
+	zoneList, err := zones.List()
+	if err != nil || len(zoneList) == 0 {
+		t.Fatalf("error listing zones: %v", err)
+	}
+	rrsets, _ := zoneList[0].ResourceRecordSets()
+	list, err := rrsets.List()
+	if err != nil {
+		t.Fatalf("error listing records: %v", err)
+	}
+	records := make(map[string]dnsprovider.ResourceRecordSet)
+	for _, rr := range list {
+		records[rr.Name()+" "+string(rr.Type())+" "+dnsprovider.GetRoutingPolicy(rr).SetIdentifier] = rr
+	}
+	return records
+}
+
+func TestRunOnceWeightedRecords(t *testing.T) {
+	provider := newRoute53TestProvider(t)
+
+	blue := dnsprovider.RoutingPolicy{SetIdentifier: "blue", Weighted: true, Weight: 90}
+	green := dnsprovider.RoutingPolicy{SetIdentifier: "green", Weighted: true, Weight: 10}
+	c := newTestController(t, provider, []Record{
+		{RecordType: RecordTypeA, FQDN: "www.example.com.", Value: "10.0.0.3", Policy: blue, MaxValues: 2},
+		{RecordType: RecordTypeA, FQDN: "www.example.com.", Value: "10.0.0.2", Policy: blue},
+		{RecordType: RecordTypeA, FQDN: "www.example.com.", Value: "10.0.0.1", Policy: blue},
+		{RecordType: RecordTypeA, FQDN: "www.example.com.", Value: "10.0.1.1", Policy: green},
+	})
+	if err := c.runOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	records := listTestRecords(t, provider)
+	if len(records) != 2 {
+		t.Fatalf("expected 2 record sets, got %v", records)
+	}
+	rr := records["www.example.com. A blue"]
+	if rr == nil || !reflect.DeepEqual(rr.Rrdatas(), []string{"10.0.0.1", "10.0.0.2"}) || dnsprovider.GetRoutingPolicy(rr) != blue {
+		t.Errorf("unexpected blue record set %v", rr)
+	}
+	rr = records["www.example.com. A green"]
+	if rr == nil || !reflect.DeepEqual(rr.Rrdatas(), []string{"10.0.1.1"}) || dnsprovider.GetRoutingPolicy(rr) != green {
+		t.Errorf("unexpected green record set %v", rr)
+	}
+}
+
+func TestRunOnceConflictingPolicies(t *testing.T) {
+	provider := newRoute53TestProvider(t)
+
+	c := newTestController(t, provider, []Record{
+		{RecordType: RecordTypeA, FQDN: "www.example.com.", Value: "10.0.0.1", Policy: dnsprovider.RoutingPolicy{SetIdentifier: "blue", Weighted: true, Weight: 1}},
+		{RecordType: RecordTypeA, FQDN: "www.example.com.", Value: "10.0.0.2", Policy: dnsprovider.RoutingPolicy{SetIdentifier: "blue", Weighted: true, Weight: 2}},
+	})
+	err := c.runOnce()
+	if err == nil || !strings.Contains(err.Error(), "different routing policies") {
+		t.Fatalf("expected error for conflicting routing policies, got %v", err)
+	}
+	if records := listTestRecords(t, provider); len(records) != 0 {
+		t.Errorf("expected no records to be published, got %v", records)
+	}
+}
+
+func TestRunOnceUnsupportedPolicy(t *testing.T) {
+	provider, err := clouddns.NewFakeInterface()
+	if err != nil {
+		t.Fatalf("error building provider: %v", err)
+	}
+
+	c := newTestController(t, provider, []Record{
+		{RecordType: RecordTypeA, FQDN: "www.example.com.", Value: "10.0.0.1", Policy: dnsprovider.RoutingPolicy{SetIdentifier: "blue", Weighted: true, Weight: 1}},
+		{RecordType: RecordTypeA, FQDN: "api.example.com.", Value: "10.0.0.2"},
+	})
+	err = c.runOnce()
+	if err == nil || !strings.Contains(err.Error(), "weighted routing is not supported") {
+		t.Fatalf("expected error for unsupported routing policy, got %v", err)
+	}
+
+	// The simple record is still published
+	records := listTestRecords(t, provider)
+	if len(records) != 1 || records["api.example.com. A "] == nil {
+		t.Errorf("expected only the simple record to be published, got %v", records)
+	}
+}
+
+func TestRunOnceSimpleToWeighted(t *testing.T) {
+	service := route53stubs.NewRoute53APIStub()
+	provider := awsroute53.New(service)
+	if _, err := service.CreateHostedZone(&route53.CreateHostedZoneInput{Name: aws.String("example.com")}); err != nil {
+		t.Fatalf("error adding zone: %v", err)
+	}
+
+	c := newTestController(t, provider, []Record{
+		{RecordType: RecordTypeA, FQDN: "www.example.com.", Value: "10.0.0.1"},
+	})
+	if err := c.runOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	weighted := dnsprovider.RoutingPolicy{SetIdentifier: "blue", Weighted: true, Weight: 1}
+	c.scopes["test"].Replace("default/test", nil, []Record{
+		{RecordType: RecordTypeA, FQDN: "www.example.com.", Value: "10.0.0.1", Policy: weighted},
+	})
+	if err := c.runOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	records := listTestRecords(t, provider)
+	if len(records) != 1 || records["www.example.com. A blue"] == nil {
+		t.Errorf("expected the simple record set to be replaced by the weighted record set, got %v", records)
+	}
+}
+
+func TestMergeMaxValues(t *testing.T) {
+	cases := []struct {
+		l, r, expected int
+	}{
+		{0, 0, 0},
+		{0, 3, 3},
+		{3, 0, 3},
+		{3, 2, 2},
+		{2, 3, 2},
+	}
+	for _, c := range cases {
+		if actual := mergeMaxValues(c.l, c.r); actual != c.expected {
+			t.Errorf("mergeMaxValues(%d, %d) expected %d, but got %d", c.l, c.r, c.expected, actual)
+		}
+	}
+}
+
+type fakeStatusWriter struct {
+	statuses map[v1.ObjectReference]*Status
+	writes   int
+}
+
+func (w *fakeStatusWriter) WriteStatus(source v1.ObjectReference, status *Status) error {
+	w.writes++
+	if status == nil {
+		delete(w.statuses, source)
+	} else {
+		w.statuses[source] = status
+	}
+	return nil
+}
+
+func TestRunOnceEventsAndStatus(t *testing.T) {
+	provider := newRoute53TestProvider(t)
+	recorder := record.NewFakeRecorder(10)
+	statusWriter := &fakeStatusWriter{statuses: make(map[v1.ObjectReference]*Status)}
+	source := &v1.ObjectReference{Kind: "Service", APIVersion: "v1", Namespace: "default", Name: "test", UID: "1234"}
+
+	c := newTestControllerWithSource(t, provider, recorder, statusWriter, source, []Record{
+		{RecordType: RecordTypeA, FQDN: "www.example.com.", Value: "10.0.0.1"},
+		{RecordType: RecordTypeA, FQDN: "api.example.com.", Value: "10.0.0.1"},
+		{RecordType: RecordTypeA, FQDN: "www.other.com.", Value: "10.0.0.1"},
+	})
+	if err := c.runOnce(); err == nil || !strings.Contains(err.Error(), "no suitable zone found") {
+		t.Fatalf("expected error for name without a zone, got %v", err)
+	}
+
+	events := map[string]bool{}
+	for len(recorder.Events) != 0 {
+		events[<-recorder.Events] = true
+	}
+	expected := []string{
+		"Normal DNSRecordsPublished Published A records for www.example.com. in zone example.com: 10.0.0.1",
+		"Normal DNSRecordsPublished Published A records for api.example.com. in zone example.com: 10.0.0.1",
+		`Warning DNSRecordsFailed error updating records for {A www.other.com. }: no suitable zone found for "www.other.com."`,
+	}
+	for _, e := range expected {
+		if !events[e] {
+			t.Errorf("expected event %q, got %v", e, events)
+		}
+	}
+	if len(events) != len(expected) {
+		t.Errorf("unexpected events %v", events)
+	}
+
+	// The status is not written while some of the records of the object fail
+	if statusWriter.writes != 0 {
+		t.Errorf("expected no status to be written, got %v", statusWriter.statuses)
+	}
+
+	c.scopes["test"].Replace("default/test", source, []Record{
+		{RecordType: RecordTypeA, FQDN: "www.example.com.", Value: "10.0.0.1"},
+		{RecordType: RecordTypeA, FQDN: "api.example.com.", Value: "10.0.0.1"},
+	})
+	if err := c.runOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	status := statusWriter.statuses[*source]
+	if status == nil || !reflect.DeepEqual(status.FQDNs, []string{"api.example.com.", "www.example.com."}) || status.LastSyncTime.IsZero() {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	c.scopes["test"].Replace("default/test", nil, nil)
+	if err := c.runOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(statusWriter.statuses) != 0 || statusWriter.writes != 2 {
+		t.Errorf("expected the status to be removed, got %v after %d writes", statusWriter.statuses, statusWriter.writes)
+	}
+}
+
+// listZoneRecords returns the values of the record sets in the zone of provider with the id, keyed by name and type
+func listZoneRecords(t *testing.T, provider dnsprovider.Interface, id string) map[string][]string {
+	zones, _ := provider.Zones()
+	zoneList, err := zones.List()
+	if err != nil {
+		t.Fatalf("error listing zones: %v", err)
+	}
+	records := make(map[string][]string)
+	for _, zone := range zoneList {
+		if zone.ID() != id {
+			continue
+		}
+		rrsets, _ := zone.ResourceRecordSets()
+		list, err := rrsets.List()
+		if err != nil {
+			t.Fatalf("error listing records: %v", err)
+		}
+		for _, rr := range list {
+			records[rr.Name()+" "+string(rr.Type())] = rr.Rrdatas()
+		}
+	}
+	return records
+}
+
+func TestRunOnceSplitHorizon(t *testing.T) {
+	service := route53stubs.NewRoute53APIStub()
+	provider := awsroute53.New(service)
+	if _, err := service.CreateHostedZone(&route53.CreateHostedZoneInput{Name: aws.String("example.com")}); err != nil {
+		t.Fatalf("error adding zone: %v", err)
+	}
+	if _, err := service.CreateHostedZone(&route53.CreateHostedZoneInput{Name: aws.String("example.com"), VPC: &route53.VPC{VPCId: aws.String("vpc-1234")}}); err != nil {
+		t.Fatalf("error adding private zone: %v", err)
+	}
+
+	zoneRules, err := ParseZoneRules([]string{"example.com:external", "example.com:internal"})
+	if err != nil {
+		t.Fatalf("error parsing zone rules: %v", err)
+	}
+	c, err := NewDNSController([]dnsprovider.Interface{provider}, zoneRules, "cluster1", nil, nil)
+	if err != nil {
+		t.Fatalf("error building controller: %v", err)
+	}
+	scope, err := c.CreateScope("test")
+	if err != nil {
+		t.Fatalf("error creating scope: %v", err)
+	}
+	scope.Replace("default/test", nil, []Record{
+		{RecordType: RecordTypeA, FQDN: "api.example.com.", Value: "10.0.0.1", Role: RoleTypeInternal},
+		{RecordType: RecordTypeA, FQDN: "api.example.com.", Value: "198.51.100.1", Role: RoleTypeExternal},
+		{RecordType: RecordTypeA, FQDN: "etcd.example.com.", Value: "10.0.0.2", Role: RoleTypeInternal},
+		{RecordType: RecordTypeA, FQDN: "www.example.com.", Value: "198.51.100.2"},
+	})
+	scope.MarkReady()
+	if err := c.runOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Internal records are published in the private zone, and external records (and records without a role) in both zones,
+	// so that they resolve from inside the VPC; in the private zone, internal records take precedence
+	ownership := []string{OwnershipRecordValue("cluster1")}
+	expectedPublic := map[string][]string{
+		"api.example.com. A":                   {"198.51.100.1"},
+		"www.example.com. A":                   {"198.51.100.2"},
+		"_dns-controller.api.example.com. TXT": ownership,
+		"_dns-controller.www.example.com. TXT": ownership,
+	}
+	if records := listZoneRecords(t, provider, "example.com"); !reflect.DeepEqual(records, expectedPublic) {
+		t.Errorf("unexpected records in the public zone: %v", records)
+	}
+	expectedPrivate := map[string][]string{
+		"api.example.com. A":                    {"10.0.0.1"},
+		"etcd.example.com. A":                   {"10.0.0.2"},
+		"www.example.com. A":                    {"198.51.100.2"},
+		"_dns-controller.api.example.com. TXT":  ownership,
+		"_dns-controller.etcd.example.com. TXT": ownership,
+		"_dns-controller.www.example.com. TXT":  ownership,
+	}
+	if records := listZoneRecords(t, provider, "private-example.com"); !reflect.DeepEqual(records, expectedPrivate) {
+		t.Errorf("unexpected records in the private zone: %v", records)
+	}
+
+	// Removing the external record removes it, and its ownership record, from the public zone only
+	scope.Replace("default/test", nil, []Record{
+		{RecordType: RecordTypeA, FQDN: "api.example.com.", Value: "10.0.0.1", Role: RoleTypeInternal},
+		{RecordType: RecordTypeA, FQDN: "etcd.example.com.", Value: "10.0.0.2", Role: RoleTypeInternal},
+		{RecordType: RecordTypeA, FQDN: "www.example.com.", Value: "198.51.100.2"},
+	})
+	if err := c.runOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	delete(expectedPublic, "api.example.com. A")
+	delete(expectedPublic, "_dns-controller.api.example.com. TXT")
+	if records := listZoneRecords(t, provider, "example.com"); !reflect.DeepEqual(records, expectedPublic) {
+		t.Errorf("unexpected records in the public zone: %v", records)
+	}
+	if records := listZoneRecords(t, provider, "private-example.com"); !reflect.DeepEqual(records, expectedPrivate) {
+		t.Errorf("unexpected records in the private zone: %v", records)
+	}
+
+	// Once the internal record is removed, the external record resolves from the private zone
+	scope.Replace("default/test", nil, []Record{
+		{RecordType: RecordTypeA, FQDN: "api.example.com.", Value: "198.51.100.1", Role: RoleTypeExternal},
+		{RecordType: RecordTypeA, FQDN: "etcd.example.com.", Value: "10.0.0.2", Role: RoleTypeInternal},
+		{RecordType: RecordTypeA, FQDN: "www.example.com.", Value: "198.51.100.2"},
+	})
+	if err := c.runOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedPublic["api.example.com. A"] = []string{"198.51.100.1"}
+	expectedPublic["_dns-controller.api.example.com. TXT"] = ownership
+	if records := listZoneRecords(t, provider, "example.com"); !reflect.DeepEqual(records, expectedPublic) {
+		t.Errorf("unexpected records in the public zone: %v", records)
+	}
+	expectedPrivate["api.example.com. A"] = []string{"198.51.100.1"}
+	if records := listZoneRecords(t, provider, "private-example.com"); !reflect.DeepEqual(records, expectedPrivate) {
+		t.Errorf("unexpected records in the private zone: %v", records)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "dns_controller"
+
+// The values of the operation label
+const (
+	operationUpsert = "upsert"
+	operationDelete = "delete"
+)
+
+// The values of the result label
+const (
+	resultSuccess = "success"
+	resultError   = "error"
+)
+
+var (
+	// syncsTotal counts the runs which applied changes to DNS
+	syncsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "syncs_total",
+			Help:      "Number of times changes were applied to DNS, by result.",
+		},
+		[]string{"result"},
+	)
+
+	// lastSuccessfulSync is the time of the last run which applied every change
+	lastSuccessfulSync = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "last_successful_sync_timestamp_seconds",
+			Help:      "Time at which all the desired records were last applied to DNS.",
+		},
+	)
+
+	// recordOperationsTotal counts the changes to record sets
+	recordOperationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "record_operations_total",
+			Help:      "Number of record set changes, by zone, operation and result.",
+		},
+		[]string{"zone", "operation", "result"},
+	)
+
+	// changesetApplyDuration is the time taken to apply the changes to each zone
+	changesetApplyDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "changeset_apply_duration_seconds",
+			Help:      "Time taken to apply a changeset to a zone.",
+		},
+		[]string{"zone"},
+	)
+
+	// recordSets is the number of record sets dns-controller wants to publish
+	recordSets = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "record_sets",
+			Help:      "Number of record sets which dns-controller is publishing.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(syncsTotal, lastSuccessfulSync, recordOperationsTotal, changesetApplyDuration, recordSets)
+}
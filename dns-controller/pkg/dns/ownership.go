@@ -0,0 +1,260 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+)
+
+// When an owner id is configured, the controller records which names it manages in TXT records,
+// and only changes or deletes records which it owns.  This lets several clusters (or other tools)
+// share a zone safely.
+
+// OwnershipRecordPrefix is prepended to a name to get the name of its ownership TXT record.
+// We use a separate name because a CNAME cannot coexist with other records.
+const OwnershipRecordPrefix = "_dns-controller."
+
+// ownershipHeritage identifies TXT records written by dns-controller
+const ownershipHeritage = "heritage=dns-controller"
+
+// placeholderIP is the address kops uses for the records it creates before dns-controller is running;
+// these records are taken over by dns-controller even though they have no ownership record.
+const placeholderIP = "203.0.113.123"
+
+// managedRecordTypes are the record types dns-controller creates for a name
+var managedRecordTypes = []RecordType{RecordTypeA, RecordTypeAAAA, RecordTypeCNAME}
+
+type ownership int
+
+const (
+	// ownershipFree means nothing exists for the name, or only the kops placeholder, so we can claim it
+	ownershipFree ownership = iota
+	// ownershipOwned means the ownership record for the name has our owner id
+	ownershipOwned
+	// ownershipForeign means the records for the name belong to another owner, or were not created by dns-controller
+	ownershipForeign
+)
+
+// wildcardOwnershipLabel replaces the leading * of a wildcard name in its ownership record name,
+// because a * is only a wildcard as the first label of a name.
+const wildcardOwnershipLabel = "_wildcard"
+
+// OwnershipRecordName returns the name of the TXT record which records the owner of fqdn
+func OwnershipRecordName(fqdn string) string {
+	fqdn = EnsureDotSuffix(fqdn)
+	if strings.HasPrefix(fqdn, "*.") {
+		fqdn = wildcardOwnershipLabel + strings.TrimPrefix(fqdn, "*")
+	}
+	return OwnershipRecordPrefix + fqdn
+}
+
+// ownedName returns the name whose owner is recorded by the TXT record with the specified name,
+// or false if it is not the name of an ownership record
+func ownedName(name string) (string, bool) {
+	name = EnsureDotSuffix(name)
+	if !strings.HasPrefix(name, OwnershipRecordPrefix) {
+		return "", false
+	}
+	fqdn := strings.TrimPrefix(name, OwnershipRecordPrefix)
+	if strings.HasPrefix(fqdn, wildcardOwnershipLabel+".") {
+		fqdn = "*" + strings.TrimPrefix(fqdn, wildcardOwnershipLabel)
+	}
+	return fqdn, true
+}
+
+// OwnershipRecordValue returns the value of the TXT record which records the owner
+func OwnershipRecordValue(ownerID string) string {
+	return "\"" + ownershipHeritage + ",dns-controller/owner=" + ownerID + "\""
+}
+
+// ParseOwnershipRecord returns the owner id from the values of an ownership TXT record, or "" if it is not an ownership record
+func ParseOwnershipRecord(rrdatas []string) string {
+	for _, rrdata := range rrdatas {
+		tokens := strings.Split(strings.Trim(rrdata, "\""), ",")
+		if len(tokens) != 2 || tokens[0] != ownershipHeritage {
+			continue
+		}
+		if strings.HasPrefix(tokens[1], "dns-controller/owner=") {
+			return strings.TrimPrefix(tokens[1], "dns-controller/owner=")
+		}
+	}
+	return ""
+}
+
+func isManagedRecordType(t rrstype.RrsType) bool {
+	for _, m := range managedRecordTypes {
+		if string(m) == string(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordsForName returns the records in the zone with the specified name
+func (o *dnsOp) recordsForName(zone dnsprovider.Zone, fqdn string) ([]dnsprovider.ResourceRecordSet, error) {
+	// TODO: work-around before ResourceRecordSets.List() is implemented for CoreDNS
+	if isCoreDNSZone(zone) {
+		rrsProvider, ok := zone.ResourceRecordSets()
+		if !ok {
+			return nil, fmt.Errorf("zone does not support resource records %q", zone.Name())
+		}
+		return rrsProvider.Get(fqdn)
+	}
+
+	rrs, err := o.listRecords(zone)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []dnsprovider.ResourceRecordSet
+	for _, rr := range rrs {
+		if EnsureDotSuffix(FixWildcards(rr.Name())) == fqdn {
+			matches = append(matches, rr)
+		}
+	}
+	return matches, nil
+}
+
+// getOwnership determines whether we own the records for fqdn
+func (o *dnsOp) getOwnership(zone dnsprovider.Zone, fqdn string) (ownership, error) {
+	txtRecords, err := o.recordsForName(zone, OwnershipRecordName(fqdn))
+	if err != nil {
+		return ownershipForeign, err
+	}
+	for _, rr := range txtRecords {
+		if string(rr.Type()) != RecordTypeTXT {
+			continue
+		}
+		owner := ParseOwnershipRecord(rr.Rrdatas())
+		if owner == "" {
+			continue
+		}
+		if owner == o.ownerID {
+			return ownershipOwned, nil
+		}
+		glog.V(2).Infof("records for %s are owned by %q", fqdn, owner)
+		return ownershipForeign, nil
+	}
+
+	records, err := o.recordsForName(zone, fqdn)
+	if err != nil {
+		return ownershipForeign, err
+	}
+	for _, rr := range records {
+		if !isManagedRecordType(rr.Type()) {
+			continue
+		}
+		for _, rrdata := range rr.Rrdatas() {
+			if rrdata != placeholderIP {
+				return ownershipForeign, nil
+			}
+		}
+	}
+	return ownershipFree, nil
+}
+
+// claimOwnership writes the ownership record for fqdn, if it has not already been written by this dnsOp
+func (o *dnsOp) claimOwnership(zone dnsprovider.Zone, fqdn string) error {
+	if o.claimed[zonedName(zone, fqdn)] {
+		return nil
+	}
+
+	rrsProvider, ok := zone.ResourceRecordSets()
+	if !ok {
+		return fmt.Errorf("zone does not support resource records %q", zone.Name())
+	}
+	cs, err := o.getChangeset(zone)
+	if err != nil {
+		return err
+	}
+
+	glog.V(2).Infof("Claiming ownership of %s", fqdn)
+	cs.Upsert(rrsProvider.New(OwnershipRecordName(fqdn), []string{OwnershipRecordValue(o.ownerID)}, int64(DefaultTTL.Seconds()), rrstype.TXT))
+	o.claimed[zonedName(zone, fqdn)] = true
+	return nil
+}
+
+// releaseOwnership removes our ownership record for fqdn in the zone, once we no longer manage any records for it there
+func (o *dnsOp) releaseOwnership(zone dnsprovider.Zone, fqdn string) error {
+	fqdn = EnsureDotSuffix(fqdn)
+
+	txtRecords, err := o.recordsForName(zone, OwnershipRecordName(fqdn))
+	if err != nil {
+		return err
+	}
+	for _, rr := range txtRecords {
+		if string(rr.Type()) != RecordTypeTXT || ParseOwnershipRecord(rr.Rrdatas()) != o.ownerID {
+			continue
+		}
+		cs, err := o.getChangeset(zone)
+		if err != nil {
+			return err
+		}
+		glog.V(2).Infof("Releasing ownership of %s", fqdn)
+		cs.Remove(rr)
+	}
+	return nil
+}
+
+// deleteOrphanedRecords deletes the records we own which are no longer wanted (wanted is keyed by zonedName).
+// We do this when we start, because records which were removed while we were not running are not in our last snapshot.
+func (o *dnsOp) deleteOrphanedRecords(wanted map[string]bool) error {
+	for _, zone := range o.allZones() {
+		// TODO: CoreDNS does not support listing records
+		if isCoreDNSZone(zone) {
+			continue
+		}
+
+		rrs, err := o.listRecords(zone)
+		if err != nil {
+			return err
+		}
+
+		for _, txt := range rrs {
+			if string(txt.Type()) != RecordTypeTXT {
+				continue
+			}
+			fqdn, ok := ownedName(FixWildcards(txt.Name()))
+			if !ok || ParseOwnershipRecord(txt.Rrdatas()) != o.ownerID {
+				continue
+			}
+			if wanted[zonedName(zone, fqdn)] {
+				continue
+			}
+
+			cs, err := o.getChangeset(zone)
+			if err != nil {
+				return err
+			}
+			for _, rr := range rrs {
+				if EnsureDotSuffix(FixWildcards(rr.Name())) == fqdn && isManagedRecordType(rr.Type()) {
+					glog.Infof("Deleting orphaned resource record %s %s", fqdn, rr.Type())
+					cs.Remove(rr)
+				}
+			}
+			glog.Infof("Releasing ownership of orphaned name %s", fqdn)
+			cs.Remove(txt)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOwnershipRecord(t *testing.T) {
+	names := []struct {
+		fqdn     string
+		expected string
+	}{
+		{"api.example.com", "_dns-controller.api.example.com."},
+		{"*.example.com.", "_dns-controller._wildcard.example.com."},
+		{"*.apps.example.com", "_dns-controller._wildcard.apps.example.com."},
+	}
+	for _, n := range names {
+		name := OwnershipRecordName(n.fqdn)
+		if name != n.expected {
+			t.Errorf("OwnershipRecordName(%q) expected %q, but got %q", n.fqdn, n.expected, name)
+		}
+		if fqdn, ok := ownedName(name); !ok || fqdn != EnsureDotSuffix(n.fqdn) {
+			t.Errorf("ownedName(%q) expected %q, but got %q (%v)", name, EnsureDotSuffix(n.fqdn), fqdn, ok)
+		}
+	}
+	if _, ok := ownedName("api.example.com."); ok {
+		t.Errorf("ownedName(%q) expected not to be an ownership record", "api.example.com.")
+	}
+
+	value := OwnershipRecordValue("cluster1.example.com")
+	if value != `"heritage=dns-controller,dns-controller/owner=cluster1.example.com"` {
+		t.Errorf("unexpected ownership record value %q", value)
+	}
+
+	cases := []struct {
+		rrdatas  []string
+		expected string
+	}{
+		{[]string{value}, "cluster1.example.com"},
+		{[]string{`"v=spf1 -all"`, value}, "cluster1.example.com"},
+		{[]string{`"heritage=external-dns,external-dns/owner=default"`}, ""},
+		{[]string{"heritage=dns-controller,dns-controller/owner=unquoted"}, "unquoted"},
+		{nil, ""},
+	}
+	for _, c := range cases {
+		if actual := ParseOwnershipRecord(c.rrdatas); actual != c.expected {
+			t.Errorf("ParseOwnershipRecord(%q) expected %q, but got %q", c.rrdatas, c.expected, actual)
+		}
+	}
+}
+
+func TestMergeTTL(t *testing.T) {
+	cases := []struct {
+		l, r, expected time.Duration
+	}{
+		{0, 0, 0},
+		{0, time.Minute, time.Minute},
+		{time.Minute, 0, time.Minute},
+		{time.Minute, 30 * time.Second, 30 * time.Second},
+		{30 * time.Second, time.Minute, 30 * time.Second},
+	}
+	for _, c := range cases {
+		if actual := mergeTTL(c.l, c.r); actual != c.expected {
+			t.Errorf("mergeTTL(%v, %v) expected %v, but got %v", c.l, c.r, c.expected, actual)
+		}
+	}
+}
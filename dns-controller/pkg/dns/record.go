@@ -16,6 +16,14 @@ limitations under the License.
 
 package dns
 
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+)
+
 type RecordType string
 
 const (
@@ -23,7 +31,9 @@ const (
 	RecordTypeAlias = "_alias"
 
 	RecordTypeA     = "A"
+	RecordTypeAAAA  = "AAAA"
 	RecordTypeCNAME = "CNAME"
+	RecordTypeTXT   = "TXT"
 
 	RoleTypeExternal = "external"
 	RoleTypeInternal = "internal"
@@ -34,6 +44,21 @@ type Record struct {
 	FQDN       string
 	Value      string
 
+	// Role is RoleTypeInternal for a record which should resolve inside the cluster's network, or RoleTypeExternal
+	// for one which should resolve from outside; with split-horizon DNS it chooses the zone the record is published in.
+	// Records without a role are published in the external zone.
+	Role string
+
+	// TTL is the time-to-live of the record; if zero the DefaultTTL is used
+	TTL time.Duration
+
+	// Policy is the routing policy of the record; records with the same FQDN and type but different
+	// Policy.SetIdentifier values are published as separate record sets
+	Policy dnsprovider.RoutingPolicy
+
+	// MaxValues limits the number of values published in the record set; if zero there is no limit
+	MaxValues int
+
 	// If AliasTarget is set, this entry will not actually be set in DNS,
 	// but will be used as an expansion for Records with type=RecordTypeAlias,
 	// where the referring record has Value = our FQDN
@@ -45,9 +70,30 @@ func AliasForNodesInRole(role, roleType string) string {
 	return "node/role=" + role + "/" + roleType
 }
 
+// RecordTypeForIP returns the type of the record for an address: AAAA for an IPv6 address, A otherwise
+func RecordTypeForIP(ip string) RecordType {
+	parsed := net.ParseIP(ip)
+	if parsed != nil && parsed.To4() == nil {
+		return RecordTypeAAAA
+	}
+	return RecordTypeA
+}
+
 func (r *Record) String() string {
 	s := "Record:[Type=" + string(r.RecordType) + ",FQDN=" + r.FQDN + ",Value=" + r.Value
 
+	if r.TTL != 0 {
+		s += ",TTL=" + r.TTL.String()
+	}
+
+	if !r.Policy.IsSimple() {
+		s += fmt.Sprintf(",Policy=%+v", r.Policy)
+	}
+
+	if r.MaxValues != 0 {
+		s += fmt.Sprintf(",MaxValues=%d", r.MaxValues)
+	}
+
 	if r.AliasTarget {
 		s += ",AliasTarget"
 	}
@@ -32,3 +32,18 @@ func TestAliasForNodesInRole(t *testing.T) {
 		}
 	}
 }
+
+func TestRecordTypeForIP(t *testing.T) {
+	cases := map[string]RecordType{
+		"10.0.0.1":        RecordTypeA,
+		"2001:db8::1":     RecordTypeAAAA,
+		"::ffff:10.0.0.1": RecordTypeA,
+		"not-an-ip":       RecordTypeA,
+	}
+
+	for ip, expected := range cases {
+		if actual := RecordTypeForIP(ip); actual != expected {
+			t.Errorf("RecordTypeForIP(%q) expected %q, but got %q", ip, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The reasons of the events we post on the objects which requested records
+const (
+	// EventReasonRecordsPublished is posted when the records for an object have been written to DNS
+	EventReasonRecordsPublished = "DNSRecordsPublished"
+	// EventReasonRecordsFailed is posted when the records for an object could not be written to DNS
+	EventReasonRecordsFailed = "DNSRecordsFailed"
+	// EventReasonInvalidRecords is posted when the annotations of an object do not describe valid records
+	EventReasonInvalidRecords = "InvalidDNSRecords"
+)
+
+// Status is the status of the records published for an object
+type Status struct {
+	// FQDNs are the names published for the object
+	FQDNs []string `json:"fqdns"`
+	// LastSyncTime is when the records for the object were last changed in DNS
+	LastSyncTime metav1.Time `json:"lastSyncTime"`
+}
+
+// StatusWriter records the status of the published records on the objects which requested them
+type StatusWriter interface {
+	// WriteStatus records the status on the source object; if status is nil the status is removed
+	WriteStatus(source v1.ObjectReference, status *Status) error
+}
+
+// recordEvent posts an event on each of the sources, if we have an event recorder
+func (c *DNSController) recordEvent(sources []v1.ObjectReference, eventType, reason, message string) {
+	if c.recorder == nil {
+		return
+	}
+	for i := range sources {
+		c.recorder.Event(&sources[i], eventType, reason, message)
+	}
+}
+
+// recordFailure logs err and posts it as a warning event on the sources of the record
+func (c *DNSController) recordFailure(sources []v1.ObjectReference, err error) {
+	glog.Infof("%v", err)
+	c.recordEvent(sources, v1.EventTypeWarning, EventReasonRecordsFailed, err.Error())
+}
+
+// writeStatus updates the status of every source whose published records have changed, skipping
+// those with records which failed to publish.  We remember what we last wrote, so that we only
+// write the status when it changes.
+func (c *DNSController) writeStatus(snapshot *snapshot, failed map[recordKey]bool) {
+	if c.statusWriter == nil {
+		return
+	}
+
+	published := make(map[v1.ObjectReference][]recordKey)
+	skip := make(map[v1.ObjectReference]bool)
+	for k, sources := range snapshot.recordSources {
+		for _, source := range sources {
+			published[source] = append(published[source], k)
+			if failed[k] {
+				skip[source] = true
+			}
+		}
+	}
+
+	now := metav1.NewTime(time.Now())
+	for source, keys := range published {
+		if skip[source] {
+			continue
+		}
+
+		var fqdns []string
+		var descriptions []string
+		for _, k := range keys {
+			fqdns = append(fqdns, k.FQDN)
+			descriptions = append(descriptions, fmt.Sprintf("%s %s %s %v", k.FQDN, k.RecordType, k.SetIdentifier, snapshot.recordValues[k]))
+		}
+		sort.Strings(descriptions)
+		description := strings.Join(descriptions, ";")
+		if c.writtenStatus[source] == description {
+			continue
+		}
+
+		status := &Status{FQDNs: uniqueSorted(fqdns), LastSyncTime: now}
+		if err := c.statusWriter.WriteStatus(source, status); err != nil {
+			glog.Warningf("error writing DNS status for %s %s/%s: %v", source.Kind, source.Namespace, source.Name, err)
+			continue
+		}
+		c.writtenStatus[source] = description
+	}
+
+	for source := range c.writtenStatus {
+		if _, found := published[source]; found {
+			continue
+		}
+		if err := c.statusWriter.WriteStatus(source, nil); err != nil {
+			glog.Warningf("error removing DNS status for %s %s/%s: %v", source.Kind, source.Namespace, source.Name, err)
+			continue
+		}
+		delete(c.writtenStatus, source)
+	}
+}
+
+// uniqueSorted returns the distinct values of s, in sorted order
+func uniqueSorted(s []string) []string {
+	sort.Strings(s)
+	var unique []string
+	for i, v := range s {
+		if i == 0 || v != s[i-1] {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
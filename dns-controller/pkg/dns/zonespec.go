@@ -27,24 +27,39 @@ import (
 type ZoneSpec struct {
 	Name string
 	ID   string
+
+	// Role, if set, means the zone is only used for records with that role (RoleTypeInternal or RoleTypeExternal),
+	// so that a private and a public zone with the same name can both be managed (split-horizon DNS).
+	// If the provider reports zone visibility, internal rules only match private zones and external rules public zones.
+	Role string
 }
 
 func ParseZoneSpec(s string) (*ZoneSpec, error) {
 	s = strings.TrimSpace(s)
 
+	// example.com/1234:internal: Only use the zone for records with the role
+	role := ""
+	if i := strings.LastIndex(s, ":"); i != -1 {
+		role = s[i+1:]
+		if role != RoleTypeInternal && role != RoleTypeExternal {
+			return nil, fmt.Errorf("unknown zone role %q (expected %s or %s)", role, RoleTypeInternal, RoleTypeExternal)
+		}
+		s = s[:i]
+	}
+
 	tokens := strings.SplitN(s, "/", 2)
 	if len(tokens) == 2 && tokens[0] == "*" {
 		// */1234: Match by ID
-		return &ZoneSpec{ID: tokens[1]}, nil
+		return &ZoneSpec{ID: tokens[1], Role: role}, nil
 	}
 	name := EnsureDotSuffix(tokens[0])
 	if len(tokens) == 1 {
 		// example.com: Match by name
-		return &ZoneSpec{Name: name}, nil
+		return &ZoneSpec{Name: name, Role: role}, nil
 	}
 
 	// example.com/1234: Match by name & id
-	return &ZoneSpec{Name: name, ID: tokens[1]}, nil
+	return &ZoneSpec{Name: name, ID: tokens[1], Role: role}, nil
 }
 
 type ZoneRules struct {
@@ -81,6 +96,11 @@ func ParseZoneRules(zones []string) (*ZoneRules, error) {
 
 // MatchesExplicitly returns true if this matches an explicit rule (not a wildcard)
 func (r *ZoneRules) MatchesExplicitly(zone dnsprovider.Zone) bool {
+	return r.Match(zone) != nil
+}
+
+// Match returns the first explicit rule (not a wildcard) which matches the zone, or nil if there is none
+func (r *ZoneRules) Match(zone dnsprovider.Zone) *ZoneSpec {
 	name := EnsureDotSuffix(zone.Name())
 	id := zone.ID()
 
@@ -90,11 +110,19 @@ func (r *ZoneRules) MatchesExplicitly(zone dnsprovider.Zone) bool {
 		}
 
 		if zoneSpec.ID != "" && zoneSpec.ID != id {
-			return false
+			continue
+		}
+
+		// When the provider knows which zones are private, a rule with a role only matches the zone
+		// with the corresponding visibility, so public and private zones can share a name
+		if privateZone, ok := zone.(dnsprovider.PrivateZone); ok && zoneSpec.Role != "" {
+			if privateZone.IsPrivate() != (zoneSpec.Role == RoleTypeInternal) {
+				continue
+			}
 		}
 
-		return true
+		return zoneSpec
 	}
 
-	return false
+	return nil
 }
@@ -62,6 +62,18 @@ func TestParseZoneSpec(t *testing.T) {
 			"*/1234",
 			ZoneSpec{Name: "", ID: "1234"},
 		},
+		{
+			"example.com/1234:internal",
+			ZoneSpec{Name: "example.com.", ID: "1234", Role: "internal"},
+		},
+		{
+			"example.com:external",
+			ZoneSpec{Name: "example.com.", ID: "", Role: "external"},
+		},
+		{
+			"*/1234:internal",
+			ZoneSpec{Name: "", ID: "1234", Role: "internal"},
+		},
 	}
 
 	for _, c := range cases {
@@ -69,6 +81,10 @@ func TestParseZoneSpec(t *testing.T) {
 			t.Errorf("ParseZoneSpec(%#v) expected %#v, but got %#v", c.s, c.expected, *actual)
 		}
 	}
+
+	if _, err := ParseZoneSpec("example.com/1234:private"); err == nil {
+		t.Errorf("expected error for unknown zone role")
+	}
 }
 
 func TestParseZoneRules(t *testing.T) {
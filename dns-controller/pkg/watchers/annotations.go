@@ -16,6 +16,17 @@ limitations under the License.
 
 package watchers
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+)
+
 // AnnotationNameDNSExternal is used to set up a DNS name for accessing the resource from outside the cluster
 // For a service of Type=LoadBalancer, it would map to the external LB hostname or IP
 const AnnotationNameDNSExternal = "dns.alpha.kubernetes.io/external"
@@ -23,3 +34,111 @@ const AnnotationNameDNSExternal = "dns.alpha.kubernetes.io/external"
 // AnnotationNameDNSInternal is used to set up a DNS name for accessing the resource from inside the cluster
 // This is only supported on Pods currently, and maps to the Internal address
 const AnnotationNameDNSInternal = "dns.alpha.kubernetes.io/internal"
+
+// AnnotationNameDNSTTL is used to set the TTL of the DNS records for the resource, either in seconds or as a duration such as 5m
+const AnnotationNameDNSTTL = "dns.alpha.kubernetes.io/ttl"
+
+// AnnotationNameDNSSetIdentifier distinguishes the records of the resource from other records with the same name,
+// for routing policies; it is required when a routing policy is set
+const AnnotationNameDNSSetIdentifier = "dns.alpha.kubernetes.io/set-identifier"
+
+// AnnotationNameDNSWeight requests weighted routing, with the relative weight (0-255) of the records of the resource
+const AnnotationNameDNSWeight = "dns.alpha.kubernetes.io/weight"
+
+// AnnotationNameDNSFailover requests failover routing; the value is primary or secondary
+const AnnotationNameDNSFailover = "dns.alpha.kubernetes.io/failover"
+
+// AnnotationNameDNSGeolocation requests geolocation routing; the value is continent=<code>, country=<code>
+// or country=<code>,subdivision=<code>.  country=* is the default location.
+const AnnotationNameDNSGeolocation = "dns.alpha.kubernetes.io/geolocation"
+
+// AnnotationNameDNSHealthCheckID is the id of the DNS provider health check which determines whether the records are healthy
+const AnnotationNameDNSHealthCheckID = "dns.alpha.kubernetes.io/health-check-id"
+
+// AnnotationNameDNSMaxValues limits the number of values in each record set
+const AnnotationNameDNSMaxValues = "dns.alpha.kubernetes.io/max-values"
+
+// parseTTLAnnotation returns the TTL set by the AnnotationNameDNSTTL annotation, or 0 if it is not set or not valid
+func parseTTLAnnotation(annotations map[string]string) time.Duration {
+	s := annotations[AnnotationNameDNSTTL]
+	if s == "" {
+		return 0
+	}
+
+	var ttl time.Duration
+	if seconds, err := strconv.ParseInt(s, 10, 64); err == nil {
+		ttl = time.Duration(seconds) * time.Second
+	} else if d, err := time.ParseDuration(s); err == nil {
+		ttl = d
+	} else {
+		glog.Warningf("ignoring invalid %s annotation %q", AnnotationNameDNSTTL, s)
+		return 0
+	}
+
+	if ttl < time.Second {
+		glog.Warningf("ignoring %s annotation %q, which is less than one second", AnnotationNameDNSTTL, s)
+		return 0
+	}
+	return ttl
+}
+
+// parseRoutingPolicyAnnotations returns the routing policy set by the annotations, or an error if it is not valid.
+// We don't ignore an invalid policy, because publishing simple records would conflict with the records of the policy.
+func parseRoutingPolicyAnnotations(annotations map[string]string) (dnsprovider.RoutingPolicy, error) {
+	policy := dnsprovider.RoutingPolicy{
+		SetIdentifier: strings.TrimSpace(annotations[AnnotationNameDNSSetIdentifier]),
+		HealthCheckID: strings.TrimSpace(annotations[AnnotationNameDNSHealthCheckID]),
+	}
+
+	if s, found := annotations[AnnotationNameDNSWeight]; found {
+		weight, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		if err != nil {
+			return policy, fmt.Errorf("invalid %s annotation %q", AnnotationNameDNSWeight, s)
+		}
+		policy.Weighted = true
+		policy.Weight = weight
+	}
+
+	if s := strings.TrimSpace(annotations[AnnotationNameDNSFailover]); s != "" {
+		policy.Failover = strings.ToUpper(s)
+	}
+
+	if s := strings.TrimSpace(annotations[AnnotationNameDNSGeolocation]); s != "" {
+		for _, token := range strings.Split(s, ",") {
+			kv := strings.SplitN(strings.TrimSpace(token), "=", 2)
+			if len(kv) != 2 || kv[1] == "" {
+				return policy, fmt.Errorf("invalid %s annotation %q", AnnotationNameDNSGeolocation, s)
+			}
+			switch kv[0] {
+			case "continent":
+				policy.Continent = strings.ToUpper(kv[1])
+			case "country":
+				policy.Country = strings.ToUpper(kv[1])
+			case "subdivision":
+				policy.Subdivision = strings.ToUpper(kv[1])
+			default:
+				return policy, fmt.Errorf("invalid %s annotation %q: unknown key %q", AnnotationNameDNSGeolocation, s, kv[0])
+			}
+		}
+	}
+
+	if err := policy.Validate(); err != nil {
+		return policy, fmt.Errorf("invalid DNS routing policy: %v", err)
+	}
+	return policy, nil
+}
+
+// parseMaxValuesAnnotation returns the limit set by the AnnotationNameDNSMaxValues annotation, or 0 if it is not set or not valid
+func parseMaxValuesAnnotation(annotations map[string]string) int {
+	s := annotations[AnnotationNameDNSMaxValues]
+	if s == "" {
+		return 0
+	}
+
+	maxValues, err := strconv.Atoi(s)
+	if err != nil || maxValues < 1 {
+		glog.Warningf("ignoring invalid %s annotation %q", AnnotationNameDNSMaxValues, s)
+		return 0
+	}
+	return maxValues
+}
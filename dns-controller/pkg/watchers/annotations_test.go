@@ -0,0 +1,120 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watchers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+)
+
+func TestParseTTLAnnotation(t *testing.T) {
+	cases := map[string]time.Duration{
+		"":      0,
+		"300":   5 * time.Minute,
+		"90s":   90 * time.Second,
+		"1h":    time.Hour,
+		"0":     0,
+		"500ms": 0,
+		"soon":  0,
+	}
+
+	for value, expected := range cases {
+		annotations := map[string]string{}
+		if value != "" {
+			annotations[AnnotationNameDNSTTL] = value
+		}
+		if actual := parseTTLAnnotation(annotations); actual != expected {
+			t.Errorf("parseTTLAnnotation(%q) expected %v, but got %v", value, expected, actual)
+		}
+	}
+}
+
+func TestParseRoutingPolicyAnnotations(t *testing.T) {
+	cases := []struct {
+		annotations map[string]string
+		expected    dnsprovider.RoutingPolicy
+		err         string
+	}{
+		{
+			annotations: map[string]string{},
+		},
+		{
+			annotations: map[string]string{AnnotationNameDNSSetIdentifier: "blue", AnnotationNameDNSWeight: "20"},
+			expected:    dnsprovider.RoutingPolicy{SetIdentifier: "blue", Weighted: true, Weight: 20},
+		},
+		{
+			annotations: map[string]string{AnnotationNameDNSSetIdentifier: "a", AnnotationNameDNSFailover: "primary", AnnotationNameDNSHealthCheckID: "hc-1"},
+			expected:    dnsprovider.RoutingPolicy{SetIdentifier: "a", Failover: dnsprovider.FailoverPrimary, HealthCheckID: "hc-1"},
+		},
+		{
+			annotations: map[string]string{AnnotationNameDNSSetIdentifier: "wa", AnnotationNameDNSGeolocation: "country=us, subdivision=wa"},
+			expected:    dnsprovider.RoutingPolicy{SetIdentifier: "wa", Country: "US", Subdivision: "WA"},
+		},
+		{
+			annotations: map[string]string{AnnotationNameDNSSetIdentifier: "blue", AnnotationNameDNSWeight: "heavy"},
+			err:         "invalid dns.alpha.kubernetes.io/weight annotation",
+		},
+		{
+			annotations: map[string]string{AnnotationNameDNSWeight: "20"},
+			err:         "weighted routing requires a set identifier",
+		},
+		{
+			annotations: map[string]string{AnnotationNameDNSSetIdentifier: "x", AnnotationNameDNSGeolocation: "planet=earth"},
+			err:         "unknown key \"planet\"",
+		},
+	}
+
+	for _, c := range cases {
+		actual, err := parseRoutingPolicyAnnotations(c.annotations)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("parseRoutingPolicyAnnotations(%v) expected error containing %q, got %v", c.annotations, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseRoutingPolicyAnnotations(%v) unexpected error: %v", c.annotations, err)
+			continue
+		}
+		if actual != c.expected {
+			t.Errorf("parseRoutingPolicyAnnotations(%v) expected %+v, but got %+v", c.annotations, c.expected, actual)
+		}
+	}
+}
+
+func TestParseMaxValuesAnnotation(t *testing.T) {
+	cases := map[string]int{
+		"":     0,
+		"3":    3,
+		"0":    0,
+		"-1":   0,
+		"many": 0,
+	}
+
+	for value, expected := range cases {
+		annotations := map[string]string{}
+		if value != "" {
+			annotations[AnnotationNameDNSMaxValues] = value
+		}
+		if actual := parseMaxValuesAnnotation(annotations); actual != expected {
+			t.Errorf("parseMaxValuesAnnotation(%q) expected %v, but got %v", value, expected, actual)
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
 	"k8s.io/api/extensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/watch"
@@ -85,7 +86,7 @@ func (c *IngressController) runWatcher(stopCh <-chan struct{}) {
 			if !foundKeys[key] {
 				// The ingress previously existed, but no longer exists; delete it from the scope
 				glog.V(2).Infof("removing ingress not found in list: %s", key)
-				c.scope.Replace(key, nil)
+				c.scope.Replace(key, nil, nil)
 			}
 		}
 		c.scope.MarkReady()
@@ -116,7 +117,7 @@ func (c *IngressController) runWatcher(stopCh <-chan struct{}) {
 					c.updateIngressRecords(ingress)
 
 				case watch.Deleted:
-					c.scope.Replace(ingress.Namespace+"/"+ingress.Name, nil)
+					c.scope.Replace(ingress.Namespace+"/"+ingress.Name, nil, nil)
 
 				default:
 					glog.Warningf("Unknown event type: %v", event.Type)
@@ -154,12 +155,25 @@ func (c *IngressController) updateIngressRecords(ingress *v1beta1.Ingress) strin
 		}
 		if ingress.IP != "" {
 			ingresses = append(ingresses, dns.Record{
-				RecordType: dns.RecordTypeA,
+				RecordType: dns.RecordTypeForIP(ingress.IP),
 				Value:      ingress.IP,
 			})
 		}
 	}
 
+	key := ingress.Namespace + "/" + ingress.Name
+	source := objectReference("Ingress", "extensions/v1beta1", &ingress.ObjectMeta)
+
+	ttl := parseTTLAnnotation(ingress.Annotations)
+	maxValues := parseMaxValuesAnnotation(ingress.Annotations)
+	policy, err := parseRoutingPolicyAnnotations(ingress.Annotations)
+	if err != nil {
+		glog.Warningf("not publishing DNS records for ingress %s: %v", key, err)
+		c.scope.RecordEvent(source, v1.EventTypeWarning, dns.EventReasonInvalidRecords, err.Error())
+		c.scope.Replace(key, nil, nil)
+		return key
+	}
+
 	for _, rule := range ingress.Spec.Rules {
 		if rule.Host == "" {
 			continue
@@ -170,11 +184,14 @@ func (c *IngressController) updateIngressRecords(ingress *v1beta1.Ingress) strin
 			var r dns.Record
 			r = ingress
 			r.FQDN = fqdn
+			r.Role = dns.RoleTypeExternal
+			r.TTL = ttl
+			r.Policy = policy
+			r.MaxValues = maxValues
 			records = append(records, r)
 		}
 	}
 
-	key := ingress.Namespace + "/" + ingress.Name
-	c.scope.Replace(key, records)
+	c.scope.Replace(key, source, records)
 	return key
 }
@@ -85,7 +85,7 @@ func (c *NodeController) runWatcher(stopCh <-chan struct{}) {
 			if !foundKeys[key] {
 				// The node previously existed, but no longer exists; delete it from the scope
 				glog.V(2).Infof("removing node not found in list: %s", key)
-				c.scope.Replace(key, nil)
+				c.scope.Replace(key, nil, nil)
 			}
 		}
 		c.scope.MarkReady()
@@ -116,7 +116,7 @@ func (c *NodeController) runWatcher(stopCh <-chan struct{}) {
 					c.updateNodeRecords(node)
 
 				case watch.Deleted:
-					c.scope.Replace( /* no namespace for nodes */ node.Name, nil)
+					c.scope.Replace( /* no namespace for nodes */ node.Name, nil, nil)
 				}
 			}
 		}
@@ -197,7 +197,7 @@ func (c *NodeController) updateNodeRecords(node *v1.Node) string {
 			continue
 		}
 		records = append(records, dns.Record{
-			RecordType:  dns.RecordTypeA,
+			RecordType:  dns.RecordTypeForIP(a.Address),
 			FQDN:        "node/" + node.Name + "/internal",
 			Value:       a.Address,
 			AliasTarget: true,
@@ -210,7 +210,7 @@ func (c *NodeController) updateNodeRecords(node *v1.Node) string {
 			continue
 		}
 		records = append(records, dns.Record{
-			RecordType:  dns.RecordTypeA,
+			RecordType:  dns.RecordTypeForIP(a.Address),
 			FQDN:        "node/" + node.Name + "/external",
 			Value:       a.Address,
 			AliasTarget: true,
@@ -234,7 +234,7 @@ func (c *NodeController) updateNodeRecords(node *v1.Node) string {
 				roleType = dns.RoleTypeExternal
 			}
 			records = append(records, dns.Record{
-				RecordType:  dns.RecordTypeA,
+				RecordType:  dns.RecordTypeForIP(a.Address),
 				FQDN:        dns.AliasForNodesInRole(role, roleType),
 				Value:       a.Address,
 				AliasTarget: true,
@@ -243,6 +243,6 @@ func (c *NodeController) updateNodeRecords(node *v1.Node) string {
 	}
 
 	key := /* no namespace for nodes */ node.Name
-	c.scope.Replace(key, records)
+	c.scope.Replace(key, objectReference("Node", "v1", &node.ObjectMeta), records)
 	return key
 }
@@ -88,7 +88,7 @@ func (c *PodController) runWatcher(stopCh <-chan struct{}) {
 			if !foundKeys[key] {
 				// The pod previous existed, but no longer exists; delete it from the scope
 				glog.V(2).Infof("removing pod not found in list: %s", key)
-				c.scope.Replace(key, nil)
+				c.scope.Replace(key, nil, nil)
 			}
 		}
 		c.scope.MarkReady()
@@ -119,7 +119,7 @@ func (c *PodController) runWatcher(stopCh <-chan struct{}) {
 					c.updatePodRecords(pod)
 
 				case watch.Deleted:
-					c.scope.Replace(pod.Namespace+"/"+pod.Name, nil)
+					c.scope.Replace(pod.Namespace+"/"+pod.Name, nil, nil)
 
 				default:
 					glog.Warningf("Unknown event type: %v", event.Type)
@@ -145,6 +145,19 @@ func (c *PodController) runWatcher(stopCh <-chan struct{}) {
 func (c *PodController) updatePodRecords(pod *v1.Pod) string {
 	var records []dns.Record
 
+	key := pod.Namespace + "/" + pod.Name
+	source := objectReference("Pod", "v1", &pod.ObjectMeta)
+
+	ttl := parseTTLAnnotation(pod.Annotations)
+	maxValues := parseMaxValuesAnnotation(pod.Annotations)
+	policy, err := parseRoutingPolicyAnnotations(pod.Annotations)
+	if err != nil {
+		glog.Warningf("not publishing DNS records for pod %s: %v", key, err)
+		c.scope.RecordEvent(source, v1.EventTypeWarning, dns.EventReasonInvalidRecords, err.Error())
+		c.scope.Replace(key, nil, nil)
+		return key
+	}
+
 	specExternal := pod.Annotations[AnnotationNameDNSExternal]
 	if specExternal != "" {
 		var aliases []string
@@ -166,6 +179,10 @@ func (c *PodController) updatePodRecords(pod *v1.Pod) string {
 					RecordType: dns.RecordTypeAlias,
 					FQDN:       fqdn,
 					Value:      alias,
+					Role:       dns.RoleTypeExternal,
+					TTL:        ttl,
+					Policy:     policy,
+					MaxValues:  maxValues,
 				})
 			}
 		}
@@ -191,9 +208,13 @@ func (c *PodController) updatePodRecords(pod *v1.Pod) string {
 			fqdn := dns.EnsureDotSuffix(token)
 			for _, ip := range ips {
 				records = append(records, dns.Record{
-					RecordType: dns.RecordTypeA,
+					RecordType: dns.RecordTypeForIP(ip),
 					FQDN:       fqdn,
 					Value:      ip,
+					Role:       dns.RoleTypeInternal,
+					TTL:        ttl,
+					Policy:     policy,
+					MaxValues:  maxValues,
 				})
 			}
 		}
@@ -201,7 +222,6 @@ func (c *PodController) updatePodRecords(pod *v1.Pod) string {
 		glog.V(4).Infof("Pod %q did not have %s label", pod.Name, AnnotationNameDNSInternal)
 	}
 
-	key := pod.Namespace + "/" + pod.Name
-	c.scope.Replace(key, records)
+	c.scope.Replace(key, source, records)
 	return key
 }
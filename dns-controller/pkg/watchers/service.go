@@ -86,7 +86,7 @@ func (c *ServiceController) runWatcher(stopCh <-chan struct{}) {
 			if !foundKeys[key] {
 				// The service previously existed, but no longer exists; delete it from the scope
 				glog.V(2).Infof("removing service not found in list: %s", key)
-				c.scope.Replace(key, nil)
+				c.scope.Replace(key, nil, nil)
 			}
 		}
 		c.scope.MarkReady()
@@ -117,7 +117,7 @@ func (c *ServiceController) runWatcher(stopCh <-chan struct{}) {
 					c.updateServiceRecords(service)
 
 				case watch.Deleted:
-					c.scope.Replace(service.Namespace+"/"+service.Name, nil)
+					c.scope.Replace(service.Namespace+"/"+service.Name, nil, nil)
 
 				default:
 					glog.Warningf("Unknown event type: %v", event.Type)
@@ -144,6 +144,8 @@ func (c *ServiceController) runWatcher(stopCh <-chan struct{}) {
 func (c *ServiceController) updateServiceRecords(service *v1.Service) string {
 	var records []dns.Record
 
+	source := objectReference("Service", "v1", &service.ObjectMeta)
+
 	specExternal := service.Annotations[AnnotationNameDNSExternal]
 	specInternal := service.Annotations[AnnotationNameDNSInternal]
 	if len(specExternal) != 0 || len(specInternal) != 0 {
@@ -161,11 +163,12 @@ func (c *ServiceController) updateServiceRecords(service *v1.Service) string {
 					glog.V(4).Infof("Found CNAME record for service %s/%s: %q", service.Namespace, service.Name, ingress.Hostname)
 				}
 				if ingress.IP != "" {
+					recordType := dns.RecordTypeForIP(ingress.IP)
 					ingresses = append(ingresses, dns.Record{
-						RecordType: dns.RecordTypeA,
+						RecordType: recordType,
 						Value:      ingress.IP,
 					})
-					glog.V(4).Infof("Found A record for service %s/%s: %q", service.Namespace, service.Name, ingress.IP)
+					glog.V(4).Infof("Found %s record for service %s/%s: %q", recordType, service.Namespace, service.Name, ingress.IP)
 				}
 			}
 		} else if service.Spec.Type == v1.ServiceTypeNodePort {
@@ -184,29 +187,46 @@ func (c *ServiceController) updateServiceRecords(service *v1.Service) string {
 			})
 			glog.V(4).Infof("Setting internal alias for NodePort service %s/%s", service.Namespace, service.Name)
 		} else {
-			// TODO: Emit event so that users are informed of this
 			glog.V(2).Infof("Cannot expose service %s/%s of type %q", service.Namespace, service.Name, service.Spec.Type)
+			c.scope.RecordEvent(source, v1.EventTypeWarning, dns.EventReasonInvalidRecords,
+				fmt.Sprintf("DNS records cannot be published for a service of type %s", service.Spec.Type))
+		}
+
+		ttl := parseTTLAnnotation(service.Annotations)
+		maxValues := parseMaxValuesAnnotation(service.Annotations)
+		policy, err := parseRoutingPolicyAnnotations(service.Annotations)
+		if err != nil {
+			glog.Warningf("not publishing DNS records for service %s/%s: %v", service.Namespace, service.Name, err)
+			c.scope.RecordEvent(source, v1.EventTypeWarning, dns.EventReasonInvalidRecords, err.Error())
+			ingresses = nil
 		}
 
-		var tokens []string
+		// The role of each name chooses the zone it is published in, with split-horizon DNS
+		tokens := make(map[string][]string)
 
 		if len(specExternal) != 0 {
-			tokens = append(tokens, strings.Split(specExternal, ",")...)
+			tokens[dns.RoleTypeExternal] = strings.Split(specExternal, ",")
 		}
 
 		if len(specInternal) != 0 {
-			tokens = append(tokens, strings.Split(specInternal, ",")...)
+			tokens[dns.RoleTypeInternal] = strings.Split(specInternal, ",")
 		}
 
-		for _, token := range tokens {
-			token = strings.TrimSpace(token)
-
-			fqdn := dns.EnsureDotSuffix(token)
-			for _, ingress := range ingresses {
-				var r dns.Record
-				r = ingress
-				r.FQDN = fqdn
-				records = append(records, r)
+		for _, role := range []string{dns.RoleTypeExternal, dns.RoleTypeInternal} {
+			for _, token := range tokens[role] {
+				token = strings.TrimSpace(token)
+
+				fqdn := dns.EnsureDotSuffix(token)
+				for _, ingress := range ingresses {
+					var r dns.Record
+					r = ingress
+					r.FQDN = fqdn
+					r.Role = role
+					r.TTL = ttl
+					r.Policy = policy
+					r.MaxValues = maxValues
+					records = append(records, r)
+				}
 			}
 		}
 	} else {
@@ -214,6 +234,6 @@ func (c *ServiceController) updateServiceRecords(service *v1.Service) string {
 	}
 
 	key := service.Namespace + "/" + service.Name
-	c.scope.Replace(key, records)
+	c.scope.Replace(key, source, records)
 	return key
 }
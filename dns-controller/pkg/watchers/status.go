@@ -0,0 +1,110 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watchers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"k8s.io/kops/dns-controller/pkg/dns"
+)
+
+// AnnotationNameDNSStatus is set by dns-controller to the status of the records published for the resource:
+// a JSON object with the published fqdns and the lastSyncTime at which they were last changed
+const AnnotationNameDNSStatus = "dns.alpha.kubernetes.io/status"
+
+// objectReference returns a reference to the object, which is used to post events and status for its records
+func objectReference(kind string, apiVersion string, meta *metav1.ObjectMeta) *v1.ObjectReference {
+	return &v1.ObjectReference{
+		Kind:       kind,
+		APIVersion: apiVersion,
+		Namespace:  meta.Namespace,
+		Name:       meta.Name,
+		UID:        meta.UID,
+	}
+}
+
+// StatusWriter records the status of the published records in the AnnotationNameDNSStatus annotation
+type StatusWriter struct {
+	client kubernetes.Interface
+}
+
+var _ dns.StatusWriter = &StatusWriter{}
+
+// NewStatusWriter builds a StatusWriter
+func NewStatusWriter(client kubernetes.Interface) *StatusWriter {
+	return &StatusWriter{client: client}
+}
+
+// WriteStatus implements dns.StatusWriter::WriteStatus, patching the annotation on the object
+func (w *StatusWriter) WriteStatus(source v1.ObjectReference, status *dns.Status) error {
+	patch, err := buildStatusPatch(status)
+	if err != nil {
+		return err
+	}
+
+	glog.V(4).Infof("updating DNS status of %s %s/%s", source.Kind, source.Namespace, source.Name)
+	switch source.Kind {
+	case "Service":
+		_, err = w.client.CoreV1().Services(source.Namespace).Patch(source.Name, types.MergePatchType, patch)
+	case "Ingress":
+		_, err = w.client.ExtensionsV1beta1().Ingresses(source.Namespace).Patch(source.Name, types.MergePatchType, patch)
+	case "Pod":
+		_, err = w.client.CoreV1().Pods(source.Namespace).Patch(source.Name, types.MergePatchType, patch)
+	default:
+		glog.V(4).Infof("not recording DNS status on %s %s", source.Kind, source.Name)
+		return nil
+	}
+
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// The object was deleted, along with its status
+			return nil
+		}
+		return fmt.Errorf("error patching %s %s/%s: %v", source.Kind, source.Namespace, source.Name, err)
+	}
+	return nil
+}
+
+// buildStatusPatch builds a JSON merge patch which sets the status annotation, or removes it if status is nil
+func buildStatusPatch(status *dns.Status) ([]byte, error) {
+	var value *string
+	if status != nil {
+		data, err := json.Marshal(status)
+		if err != nil {
+			return nil, fmt.Errorf("error serializing DNS status: %v", err)
+		}
+		s := string(data)
+		value = &s
+	}
+
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]*string{
+				AnnotationNameDNSStatus: value,
+			},
+		},
+	}
+	return json.Marshal(patch)
+}
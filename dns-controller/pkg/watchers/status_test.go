@@ -0,0 +1,85 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watchers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+
+	"k8s.io/kops/dns-controller/pkg/dns"
+)
+
+func TestStatusWriter(t *testing.T) {
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "web",
+			UID:         "1234",
+			Annotations: map[string]string{AnnotationNameDNSExternal: "www.example.com"},
+		},
+	}
+	client := fake.NewSimpleClientset(service)
+	w := NewStatusWriter(client)
+
+	source := objectReference("Service", "v1", &service.ObjectMeta)
+	if source.Kind != "Service" || source.Namespace != "default" || source.Name != "web" || source.UID != "1234" {
+		t.Fatalf("unexpected object reference %+v", source)
+	}
+
+	status := &dns.Status{
+		FQDNs:        []string{"www.example.com."},
+		LastSyncTime: metav1.NewTime(time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)),
+	}
+	if err := w.WriteStatus(*source, status); err != nil {
+		t.Fatalf("unexpected error writing status: %v", err)
+	}
+	if err := w.WriteStatus(*source, nil); err != nil {
+		t.Fatalf("unexpected error removing status: %v", err)
+	}
+
+	var patches []string
+	for _, action := range client.Actions() {
+		if patch, ok := action.(core.PatchAction); ok {
+			if action.GetResource().Resource != "services" || patch.GetNamespace() != "default" || patch.GetName() != "web" {
+				t.Errorf("unexpected patch of %v %s/%s", action.GetResource(), patch.GetNamespace(), patch.GetName())
+			}
+			patches = append(patches, string(patch.GetPatch()))
+		}
+	}
+	expected := []string{
+		`{"metadata":{"annotations":{"dns.alpha.kubernetes.io/status":"{\"fqdns\":[\"www.example.com.\"],\"lastSyncTime\":\"2018-01-02T03:04:05Z\"}"}}}`,
+		`{"metadata":{"annotations":{"dns.alpha.kubernetes.io/status":null}}}`,
+	}
+	if !reflect.DeepEqual(patches, expected) {
+		t.Errorf("expected patches %v, got %v", expected, patches)
+	}
+
+	// Nodes have no status
+	client.ClearActions()
+	if err := w.WriteStatus(v1.ObjectReference{Kind: "Node", Name: "node1"}, status); err != nil {
+		t.Errorf("unexpected error writing status of node: %v", err)
+	}
+	if len(client.Actions()) != 0 {
+		t.Errorf("unexpected actions for node: %v", client.Actions())
+	}
+}
@@ -49,6 +49,14 @@ type Zone interface {
 	ResourceRecordSets() (ResourceRecordSets, bool)
 }
 
+// PrivateZone is implemented by Zones of providers which support zones that are only visible from private networks
+type PrivateZone interface {
+	Zone
+
+	// IsPrivate returns true if the zone is only visible from private networks
+	IsPrivate() bool
+}
+
 type ResourceRecordSets interface {
 	// List returns the ResourceRecordSets of the Zone, or an error if the list operation failed.
 	List() ([]ResourceRecordSet, error)
@@ -107,7 +115,8 @@ type ResourceRecordSet interface {
    via their interfaces are equal.
 */
 func ResourceRecordSetsEquivalent(r1, r2 ResourceRecordSet) bool {
-	if r1.Name() == r2.Name() && reflect.DeepEqual(r1.Rrdatas(), r2.Rrdatas()) && r1.Ttl() == r2.Ttl() && r1.Type() == r2.Type() {
+	if r1.Name() == r2.Name() && reflect.DeepEqual(r1.Rrdatas(), r2.Rrdatas()) && r1.Ttl() == r2.Ttl() && r1.Type() == r2.Type() &&
+		GetRoutingPolicy(r1) == GetRoutingPolicy(r2) {
 		return true
 	}
 	return false
@@ -293,3 +293,47 @@ func TestResourceRecordSetsDifferentTypes(t *testing.T) {
 	zone := firstZone(t)
 	tests.CommonTestResourceRecordSetsDifferentTypes(t, zone)
 }
+
+/* TestResourceRecordSetsWeighted verifies that weighted record sets with the same name are separate record sets */
+func TestResourceRecordSetsWeighted(t *testing.T) {
+	zone := firstZone(t)
+	sets := rrs(t, zone)
+
+	name := "weighted." + zone.Name()
+	var added []dnsprovider.ResourceRecordSet
+	for i, id := range []string{"blue", "green"} {
+		policy := dnsprovider.RoutingPolicy{SetIdentifier: id, Weighted: true, Weight: int64(i * 10)}
+		rrset, err := dnsprovider.NewWithRoutingPolicy(sets, name, []string{fmt.Sprintf("10.0.0.%d", i+1)}, 180, rrstype.A, policy)
+		if err != nil {
+			t.Fatalf("error building weighted record set: %v", err)
+		}
+		addRrsetOrFail(t, sets, rrset)
+		added = append(added, rrset)
+	}
+
+	found := make(map[string]dnsprovider.RoutingPolicy)
+	for _, rrset := range listRrsOrFail(t, sets) {
+		if rrset.Name() == name {
+			policy := dnsprovider.GetRoutingPolicy(rrset)
+			found[policy.SetIdentifier] = policy
+		}
+	}
+	if len(found) != 2 || !found["green"].Weighted || found["green"].Weight != 10 || !found["blue"].Weighted || found["blue"].Weight != 0 {
+		t.Errorf("unexpected weighted record sets: %v", found)
+	}
+
+	if err := sets.StartChangeset().Remove(added[0]).Remove(added[1]).Apply(); err != nil {
+		t.Fatalf("error removing weighted record sets: %v", err)
+	}
+}
+
+/* TestResourceRecordSetsRoutingPolicyRejected verifies that invalid routing policies are rejected */
+func TestResourceRecordSetsRoutingPolicyRejected(t *testing.T) {
+	zone := firstZone(t)
+	sets := rrs(t, zone)
+
+	policy := dnsprovider.RoutingPolicy{Weighted: true, Weight: 1}
+	if _, err := dnsprovider.NewWithRoutingPolicy(sets, "rejected."+zone.Name(), []string{"10.0.0.1"}, 180, rrstype.A, policy); err == nil {
+		t.Errorf("expected weighted record set without a set identifier to be rejected")
+	}
+}
@@ -70,25 +70,31 @@ func buildChange(action string, rrs dnsprovider.ResourceRecordSet) *route53.Chan
 		}
 		change.ResourceRecordSet.ResourceRecords = append(change.ResourceRecordSet.ResourceRecords, rr)
 	}
+	setRoutingPolicy(change.ResourceRecordSet, dnsprovider.GetRoutingPolicy(rrs))
 	return change
 }
 
 func (c *ResourceRecordChangeset) Apply() error {
 	hostedZoneID := c.zone.impl.Id
 
-	removals := make(map[string]*route53.Change)
+	// Record sets with the same name and type but different set identifiers are separate record sets,
+	// but we still apply them in the same batch, so that e.g. a simple record set can be replaced by weighted record sets
+	removals := make(map[string][]*route53.Change)
 	for _, removal := range c.removals {
-		removals[string(removal.Type())+"::"+removal.Name()] = buildChange(route53.ChangeActionDelete, removal)
+		key := string(removal.Type()) + "::" + removal.Name()
+		removals[key] = append(removals[key], buildChange(route53.ChangeActionDelete, removal))
 	}
 
-	additions := make(map[string]*route53.Change)
+	additions := make(map[string][]*route53.Change)
 	for _, addition := range c.additions {
-		additions[string(addition.Type())+"::"+addition.Name()] = buildChange(route53.ChangeActionCreate, addition)
+		key := string(addition.Type()) + "::" + addition.Name()
+		additions[key] = append(additions[key], buildChange(route53.ChangeActionCreate, addition))
 	}
 
-	upserts := make(map[string]*route53.Change)
+	upserts := make(map[string][]*route53.Change)
 	for _, upsert := range c.upserts {
-		upserts[string(upsert.Type())+"::"+upsert.Name()] = buildChange(route53.ChangeActionUpsert, upsert)
+		key := string(upsert.Type()) + "::" + upsert.Name()
+		upserts[key] = append(upserts[key], buildChange(route53.ChangeActionUpsert, upsert))
 	}
 
 	doneKeys := make(map[string]bool)
@@ -112,19 +118,14 @@ func (c *ResourceRecordChangeset) Apply() error {
 				continue
 			}
 
-			if len(batch)+3 >= MaxBatchSize {
+			n := len(removals[k]) + len(additions[k]) + len(upserts[k])
+			if len(batch) != 0 && len(batch)+n > MaxBatchSize {
 				break
 			}
 
-			if change := removals[k]; change != nil {
-				batch = append(batch, change)
-			}
-			if change := additions[k]; change != nil {
-				batch = append(batch, change)
-			}
-			if change := upserts[k]; change != nil {
-				batch = append(batch, change)
-			}
+			batch = append(batch, removals[k]...)
+			batch = append(batch, additions[k]...)
+			batch = append(batch, upserts[k]...)
 			doneKeys[k] = true
 		}
 
@@ -25,7 +25,7 @@ import (
 )
 
 // Compile time check for interface adherence
-var _ dnsprovider.ResourceRecordSet = ResourceRecordSet{}
+var _ dnsprovider.RoutingPolicyResourceRecordSet = ResourceRecordSet{}
 
 type ResourceRecordSet struct {
 	impl   *route53.ResourceRecordSet
@@ -53,6 +53,25 @@ func (rrset ResourceRecordSet) Type() rrstype.RrsType {
 	return rrstype.RrsType(aws.StringValue(rrset.impl.Type))
 }
 
+// RoutingPolicy returns the routing policy of the record set, or the zero value for a simple record set
+func (rrset ResourceRecordSet) RoutingPolicy() dnsprovider.RoutingPolicy {
+	policy := dnsprovider.RoutingPolicy{
+		SetIdentifier: aws.StringValue(rrset.impl.SetIdentifier),
+		Failover:      aws.StringValue(rrset.impl.Failover),
+		HealthCheckID: aws.StringValue(rrset.impl.HealthCheckId),
+	}
+	if rrset.impl.Weight != nil {
+		policy.Weighted = true
+		policy.Weight = aws.Int64Value(rrset.impl.Weight)
+	}
+	if geo := rrset.impl.GeoLocation; geo != nil {
+		policy.Continent = aws.StringValue(geo.ContinentCode)
+		policy.Country = aws.StringValue(geo.CountryCode)
+		policy.Subdivision = aws.StringValue(geo.SubdivisionCode)
+	}
+	return policy
+}
+
 // Route53ResourceRecordSet returns the route53 ResourceRecordSet object for the ResourceRecordSet
 // This is a "back door" that allows for limited access to the ResourceRecordSet,
 // without having to requery it, so that we can expose AWS specific functionality.
@@ -24,7 +24,7 @@ import (
 )
 
 // Compile time check for interface adherence
-var _ dnsprovider.ResourceRecordSets = ResourceRecordSets{}
+var _ dnsprovider.RoutingPolicyResourceRecordSets = ResourceRecordSets{}
 
 type ResourceRecordSets struct {
 	zone *Zone
@@ -100,6 +100,51 @@ func (r ResourceRecordSets) New(name string, rrdatas []string, ttl int64, rrstyp
 	}
 }
 
+// RoutingCapabilities returns the routing policies supported by Route53
+func (r ResourceRecordSets) RoutingCapabilities() dnsprovider.RoutingCapabilities {
+	return dnsprovider.RoutingCapabilities{
+		Weighted:     true,
+		Failover:     true,
+		Geolocation:  true,
+		HealthChecks: true,
+	}
+}
+
+// NewWithRoutingPolicy allocates a new ResourceRecordSet with a routing policy
+func (r ResourceRecordSets) NewWithRoutingPolicy(name string, rrdatas []string, ttl int64, rrstype rrstype.RrsType, policy dnsprovider.RoutingPolicy) dnsprovider.ResourceRecordSet {
+	rrset := r.New(name, rrdatas, ttl, rrstype).(ResourceRecordSet)
+	setRoutingPolicy(rrset.impl, policy)
+	return rrset
+}
+
+// setRoutingPolicy sets the routing policy fields of a route53 ResourceRecordSet
+func setRoutingPolicy(rrs *route53.ResourceRecordSet, policy dnsprovider.RoutingPolicy) {
+	if policy.SetIdentifier != "" {
+		rrs.SetIdentifier = aws.String(policy.SetIdentifier)
+	}
+	if policy.Weighted {
+		rrs.Weight = aws.Int64(policy.Weight)
+	}
+	if policy.Failover != "" {
+		rrs.Failover = aws.String(policy.Failover)
+	}
+	if policy.IsGeolocation() {
+		rrs.GeoLocation = &route53.GeoLocation{}
+		if policy.Continent != "" {
+			rrs.GeoLocation.ContinentCode = aws.String(policy.Continent)
+		}
+		if policy.Country != "" {
+			rrs.GeoLocation.CountryCode = aws.String(policy.Country)
+		}
+		if policy.Subdivision != "" {
+			rrs.GeoLocation.SubdivisionCode = aws.String(policy.Subdivision)
+		}
+	}
+	if policy.HealthCheckID != "" {
+		rrs.HealthCheckId = aws.String(policy.HealthCheckID)
+	}
+}
+
 // Zone returns the parent zone
 func (rrset ResourceRecordSets) Zone() dnsprovider.Zone {
 	return rrset.zone
@@ -78,7 +78,7 @@ func (r *Route53APIStub) ChangeResourceRecordSets(input *route53.ChangeResourceR
 	}
 
 	for _, change := range input.ChangeBatch.Changes {
-		key := *change.ResourceRecordSet.Name + "::" + *change.ResourceRecordSet.Type
+		key := *change.ResourceRecordSet.Name + "::" + *change.ResourceRecordSet.Type + "::" + aws.StringValue(change.ResourceRecordSet.SetIdentifier)
 		switch *change.Action {
 		case route53.ChangeActionCreate:
 			if _, found := recordSets[key]; found {
@@ -91,7 +91,7 @@ func (r *Route53APIStub) ChangeResourceRecordSets(input *route53.ChangeResourceR
 			}
 			delete(recordSets, key)
 		case route53.ChangeActionUpsert:
-			// TODO - not used yet
+			recordSets[key] = []*route53.ResourceRecordSet{change.ResourceRecordSet}
 		}
 	}
 	r.recordSets[*input.HostedZoneId] = recordSets
@@ -111,12 +111,18 @@ func (r *Route53APIStub) ListHostedZonesPages(input *route53.ListHostedZonesInpu
 func (r *Route53APIStub) CreateHostedZone(input *route53.CreateHostedZoneInput) (*route53.CreateHostedZoneOutput, error) {
 	name := aws.StringValue(input.Name)
 	id := "/hostedzone/" + name
+	private := input.VPC != nil || (input.HostedZoneConfig != nil && aws.BoolValue(input.HostedZoneConfig.PrivateZone))
+	if private {
+		// A private zone can have the same name as a public zone
+		id = "/hostedzone/private-" + name
+	}
 	if _, ok := r.zones[id]; ok {
 		return nil, fmt.Errorf("Error creating hosted DNS zone: %s already exists", id)
 	}
 	r.zones[id] = &route53.HostedZone{
-		Id:   aws.String(id),
-		Name: aws.String(name),
+		Id:     aws.String(id),
+		Name:   aws.String(name),
+		Config: &route53.HostedZoneConfig{PrivateZone: aws.Bool(private)},
 	}
 	return &route53.CreateHostedZoneOutput{HostedZone: r.zones[id]}, nil
 }
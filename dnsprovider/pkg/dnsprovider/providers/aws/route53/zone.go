@@ -26,6 +26,7 @@ import (
 
 // Compile time check for interface adherence
 var _ dnsprovider.Zone = &Zone{}
+var _ dnsprovider.PrivateZone = &Zone{}
 
 type Zone struct {
 	impl  *route53.HostedZone
@@ -42,6 +43,11 @@ func (zone *Zone) ID() string {
 	return id
 }
 
+// IsPrivate returns true if this is a private hosted zone, associated with VPCs
+func (zone *Zone) IsPrivate() bool {
+	return zone.impl.Config != nil && aws.BoolValue(zone.impl.Config.PrivateZone)
+}
+
 func (zone *Zone) ResourceRecordSets() (dnsprovider.ResourceRecordSets, bool) {
 	return &ResourceRecordSets{zone}, true
 }
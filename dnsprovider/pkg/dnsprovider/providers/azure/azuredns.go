@@ -0,0 +1,252 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package azure is the implementation of pkg/dnsprovider interface for Azure DNS,
+// using the Azure Resource Manager REST API.
+package azure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/golang/glog"
+	"gopkg.in/gcfg.v1"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+)
+
+const (
+	// ProviderName is the name used to select this DNS provider
+	ProviderName = "azure-dns"
+
+	// apiVersion is the version of the Microsoft.Network DNS API that we use
+	apiVersion = "2017-09-01"
+
+	defaultResourceManagerEndpoint = "https://management.azure.com/"
+	defaultActiveDirectoryEndpoint = "https://login.microsoftonline.com/"
+)
+
+// Config is the configuration of the provider, for example:
+//
+//	[global]
+//	subscription-id = 00000000-0000-0000-0000-000000000000
+//	resource-group = dns
+//
+// The service principal credentials are normally passed in the AZURE_TENANT_ID, AZURE_CLIENT_ID
+// and AZURE_CLIENT_SECRET environment variables.
+type Config struct {
+	Global struct {
+		// SubscriptionID is the subscription which contains the DNS zones
+		SubscriptionID string `gcfg:"subscription-id"`
+		// ResourceGroup is the resource group which contains the DNS zones
+		ResourceGroup string `gcfg:"resource-group"`
+		// TenantID is the Azure Active Directory tenant of the service principal
+		TenantID string `gcfg:"tenant-id"`
+		// ClientID is the application id of the service principal
+		ClientID string `gcfg:"client-id"`
+		// ClientSecret is the secret of the service principal
+		ClientSecret string `gcfg:"client-secret"`
+		// ResourceManagerEndpoint overrides the Azure Resource Manager endpoint, for national clouds
+		ResourceManagerEndpoint string `gcfg:"resource-manager-endpoint"`
+		// ActiveDirectoryEndpoint overrides the Azure Active Directory endpoint, for national clouds
+		ActiveDirectoryEndpoint string `gcfg:"active-directory-endpoint"`
+	}
+}
+
+func init() {
+	dnsprovider.RegisterDnsProvider(ProviderName, func(config io.Reader) (dnsprovider.Interface, error) {
+		return newAzureDNSProviderInterface(config)
+	})
+}
+
+// newAzureDNSProviderInterface creates a new instance of an Azure DNS Interface
+func newAzureDNSProviderInterface(config io.Reader) (*Interface, error) {
+	var cfg Config
+	if config != nil {
+		if err := gcfg.ReadInto(&cfg, config); err != nil {
+			glog.Errorf("Couldn't read config: %v", err)
+			return nil, err
+		}
+	}
+
+	fromEnv := func(value *string, envVar string) {
+		if *value == "" {
+			*value = os.Getenv(envVar)
+		}
+	}
+	fromEnv(&cfg.Global.SubscriptionID, "AZURE_SUBSCRIPTION_ID")
+	fromEnv(&cfg.Global.TenantID, "AZURE_TENANT_ID")
+	fromEnv(&cfg.Global.ClientID, "AZURE_CLIENT_ID")
+	fromEnv(&cfg.Global.ClientSecret, "AZURE_CLIENT_SECRET")
+
+	if cfg.Global.ResourceManagerEndpoint == "" {
+		cfg.Global.ResourceManagerEndpoint = defaultResourceManagerEndpoint
+	}
+	if cfg.Global.ActiveDirectoryEndpoint == "" {
+		cfg.Global.ActiveDirectoryEndpoint = defaultActiveDirectoryEndpoint
+	}
+
+	if cfg.Global.TenantID == "" || cfg.Global.ClientID == "" || cfg.Global.ClientSecret == "" {
+		return nil, fmt.Errorf("the %s DNS provider requires a tenant-id, client-id and client-secret, in the config or in AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_CLIENT_SECRET", ProviderName)
+	}
+
+	oauthConfig, err := adal.NewOAuthConfig(cfg.Global.ActiveDirectoryEndpoint, cfg.Global.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error building azure oauth config: %v", err)
+	}
+	spt, err := adal.NewServicePrincipalToken(*oauthConfig, cfg.Global.ClientID, cfg.Global.ClientSecret, cfg.Global.ResourceManagerEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("error building azure service principal token: %v", err)
+	}
+
+	return newInterface(cfg, func() (string, error) {
+		if err := spt.EnsureFresh(); err != nil {
+			return "", fmt.Errorf("error refreshing azure token: %v", err)
+		}
+		return spt.OAuthToken(), nil
+	})
+}
+
+// newInterface creates an Azure DNS Interface, which authenticates with tokens from tokenSource
+func newInterface(cfg Config, tokenSource func() (string, error)) (*Interface, error) {
+	if cfg.Global.SubscriptionID == "" || cfg.Global.ResourceGroup == "" {
+		return nil, fmt.Errorf("the %s DNS provider must be configured with a subscription-id and resource-group", ProviderName)
+	}
+
+	c := &client{
+		baseURL: fmt.Sprintf("%s/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/dnsZones",
+			strings.TrimSuffix(cfg.Global.ResourceManagerEndpoint, "/"), cfg.Global.SubscriptionID, cfg.Global.ResourceGroup),
+		tokenSource: tokenSource,
+		httpClient:  &http.Client{Timeout: 60 * time.Second},
+	}
+	return &Interface{client: c}, nil
+}
+
+// client makes requests to the Azure Resource Manager API
+type client struct {
+	// baseURL is the URL of the dnsZones collection in the resource group
+	baseURL     string
+	tokenSource func() (string, error)
+	httpClient  *http.Client
+}
+
+// armError is the body of an Azure Resource Manager error response
+type armError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// errNotFound is returned when a resource does not exist
+type errNotFound struct {
+	url string
+}
+
+func (e *errNotFound) Error() string {
+	return fmt.Sprintf("%s was not found", e.url)
+}
+
+// do sends a request to url, which may be relative to the dnsZones collection, and decodes the response into result (if not nil)
+func (c *client) do(method string, url string, headers map[string]string, body interface{}, result interface{}) error {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		url = c.baseURL + url + "?api-version=" + apiVersion
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error serializing request: %v", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, url, reqBody)
+	if err != nil {
+		return fmt.Errorf("error building request: %v", err)
+	}
+	token, err := c.tokenSource()
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	glog.V(4).Infof("azure request: %s %s", method, url)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling azure API %s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response from azure API %s %s: %v", method, url, err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return &errNotFound{url: url}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		armErr := &armError{}
+		if err := json.Unmarshal(data, armErr); err != nil || armErr.Error.Code == "" {
+			return fmt.Errorf("azure API %s %s failed with status %d", method, url, resp.StatusCode)
+		}
+		return fmt.Errorf("azure API %s %s failed with status %d: %s: %s", method, url, resp.StatusCode, armErr.Error.Code, armErr.Error.Message)
+	}
+
+	if result != nil && len(data) != 0 {
+		if err := json.Unmarshal(data, result); err != nil {
+			return fmt.Errorf("error parsing response from azure API %s %s: %v", method, url, err)
+		}
+	}
+	return nil
+}
+
+// list fetches every page of a list, starting at path, calling appendPage with the items of each page
+func (c *client) list(path string, appendPage func(json.RawMessage) error) error {
+	url := c.baseURL + path + "?api-version=" + apiVersion
+	for url != "" {
+		page := &struct {
+			Value    json.RawMessage `json:"value"`
+			NextLink string          `json:"nextLink"`
+		}{}
+		if err := c.do(http.MethodGet, url, nil, nil, page); err != nil {
+			return err
+		}
+		if len(page.Value) != 0 {
+			if err := appendPage(page.Value); err != nil {
+				return fmt.Errorf("error parsing response from azure API GET %s: %v", url, err)
+			}
+		}
+		url = page.NextLink
+	}
+	return nil
+}
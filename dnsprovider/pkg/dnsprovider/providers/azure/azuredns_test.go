@@ -0,0 +1,356 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/tests"
+)
+
+const (
+	testToken          = "test-token"
+	testZonesPath      = "/subscriptions/sub/resourceGroups/dns/providers/Microsoft.Network/dnsZones"
+	testResourcePrefix = "/subscriptions/sub/resourceGroups/dns/providers/Microsoft.Network/dnszones/"
+)
+
+// fakeARM is a minimal in-memory implementation of the Azure DNS zones and record sets API
+type fakeARM struct {
+	mutex      sync.Mutex
+	serverURL  string
+	zones      []string
+	recordSets map[string]map[string]*recordSet
+}
+
+func (f *fakeARM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+testToken {
+		writeError(w, http.StatusUnauthorized, "InvalidAuthenticationToken")
+		return
+	}
+	if r.URL.Query().Get("api-version") != apiVersion {
+		writeError(w, http.StatusBadRequest, "MissingApiVersionParameter")
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, testZonesPath) {
+		writeError(w, http.StatusNotFound, "ResourceGroupNotFound")
+		return
+	}
+
+	var parts []string
+	if p := strings.Trim(strings.TrimPrefix(r.URL.Path, testZonesPath), "/"); p != "" {
+		parts = strings.Split(p, "/")
+	}
+
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		// Return one zone per page, to exercise nextLink
+		page := 0
+		fmt.Sscanf(r.URL.Query().Get("page"), "%d", &page)
+		response := map[string]interface{}{"value": []azureZone{}}
+		if page < len(f.zones) {
+			response["value"] = []azureZone{{ID: testResourcePrefix + f.zones[page], Name: f.zones[page], Location: "global"}}
+		}
+		if page+1 < len(f.zones) {
+			response["nextLink"] = fmt.Sprintf("%s%s?api-version=%s&page=%d", f.serverURL, testZonesPath, apiVersion, page+1)
+		}
+		writeJSON(w, http.StatusOK, response)
+
+	case len(parts) == 1 && r.Method == http.MethodPut:
+		f.addZone(parts[0])
+		writeJSON(w, http.StatusCreated, azureZone{ID: testResourcePrefix + parts[0], Name: parts[0], Location: "global"})
+
+	case len(parts) == 2 && parts[1] == "recordsets" && r.Method == http.MethodGet:
+		sets := f.recordSets[parts[0]]
+		if sets == nil {
+			writeError(w, http.StatusNotFound, "ParentResourceNotFound")
+			return
+		}
+		var keys []string
+		for k := range sets {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var value []*recordSet
+		for _, k := range keys {
+			value = append(value, sets[k])
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"value": value})
+
+	case len(parts) == 3:
+		sets := f.recordSets[parts[0]]
+		if sets == nil {
+			writeError(w, http.StatusNotFound, "ParentResourceNotFound")
+			return
+		}
+		k := parts[1] + "/" + parts[2]
+		switch r.Method {
+		case http.MethodGet:
+			if sets[k] == nil {
+				writeError(w, http.StatusNotFound, "NotFound")
+				return
+			}
+			writeJSON(w, http.StatusOK, sets[k])
+		case http.MethodPut:
+			if r.Header.Get("If-None-Match") == "*" && sets[k] != nil {
+				writeError(w, http.StatusPreconditionFailed, "PreconditionFailed")
+				return
+			}
+			rs := &recordSet{}
+			if err := json.NewDecoder(r.Body).Decode(rs); err != nil {
+				writeError(w, http.StatusBadRequest, "BadRequest")
+				return
+			}
+			rs.Name = parts[2]
+			rs.Type = "Microsoft.Network/dnszones/" + parts[1]
+			sets[k] = rs
+			writeJSON(w, http.StatusOK, rs)
+		case http.MethodDelete:
+			delete(sets, k)
+			w.WriteHeader(http.StatusOK)
+		}
+
+	default:
+		writeError(w, http.StatusNotFound, "NotFound")
+	}
+}
+
+func (f *fakeARM) addZone(name string) {
+	f.zones = append(f.zones, name)
+	f.recordSets[name] = map[string]*recordSet{
+		"SOA/@": {
+			Name: "@",
+			Type: "Microsoft.Network/dnszones/SOA",
+			Properties: recordSetProperties{TTL: 3600, SOARecord: &soaRecord{
+				Host: "ns1-01.azure-dns.com.", Email: "azuredns-hostmaster.microsoft.com", SerialNumber: 1,
+				RefreshTime: 3600, RetryTime: 300, ExpireTime: 2419200, MinimumTTL: 300,
+			}},
+		},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code string) {
+	e := &armError{}
+	e.Error.Code = code
+	e.Error.Message = code
+	writeJSON(w, status, e)
+}
+
+var intf dnsprovider.Interface
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	arm := &fakeARM{recordSets: make(map[string]map[string]*recordSet)}
+	arm.addZone("test.com")
+	arm.addZone("other.com")
+	server := httptest.NewServer(arm)
+	arm.serverURL = server.URL
+
+	cfg := Config{}
+	cfg.Global.SubscriptionID = "sub"
+	cfg.Global.ResourceGroup = "dns"
+	cfg.Global.ResourceManagerEndpoint = server.URL + "/"
+
+	var err error
+	intf, err = newInterface(cfg, func() (string, error) { return testToken, nil })
+	if err != nil {
+		fmt.Printf("Error creating interface: %v", err)
+		os.Exit(1)
+	}
+
+	rc := m.Run()
+	server.Close()
+	os.Exit(rc)
+}
+
+// firstZone returns the first zone for the configured dns provider account/project,
+// or fails if it can't be found
+func firstZone(t *testing.T) dnsprovider.Zone {
+	zonesInterface, supported := intf.Zones()
+	if !supported {
+		t.Fatalf("Zones interface not supported by interface %v", intf)
+	}
+	zones, err := zonesInterface.List()
+	if err != nil {
+		t.Fatalf("Failed to list zones: %v", err)
+	}
+	if len(zones) == 0 {
+		t.Fatalf("No zones were found")
+	}
+	return zones[0]
+}
+
+func rrs(t *testing.T, zone dnsprovider.Zone) dnsprovider.ResourceRecordSets {
+	rrsets, supported := zone.ResourceRecordSets()
+	if !supported {
+		t.Fatalf("ResourceRecordSets interface not supported by zone %v", zone)
+	}
+	return rrsets
+}
+
+func TestZonesList(t *testing.T) {
+	zonesInterface, _ := intf.Zones()
+	zones, err := zonesInterface.List()
+	if err != nil {
+		t.Fatalf("Failed to list zones: %v", err)
+	}
+	var names []string
+	for _, zone := range zones {
+		names = append(names, zone.Name())
+	}
+	if !reflect.DeepEqual(names, []string{"test.com", "other.com"}) {
+		t.Errorf("unexpected zones %v", names)
+	}
+	if zones[0].ID() != testResourcePrefix+"test.com" {
+		t.Errorf("unexpected zone id %q", zones[0].ID())
+	}
+}
+
+func TestZoneAddSuccess(t *testing.T) {
+	zones, _ := intf.Zones()
+	zone, err := zones.New("added.com.")
+	if err != nil {
+		t.Fatalf("error building zone: %v", err)
+	}
+	added, err := zones.Add(zone)
+	if err != nil {
+		t.Fatalf("error adding zone: %v", err)
+	}
+	if added.Name() != "added.com" {
+		t.Errorf("unexpected zone name %q", added.Name())
+	}
+}
+
+func TestResourceRecordSetsListApex(t *testing.T) {
+	list, err := rrs(t, firstZone(t)).List()
+	if err != nil {
+		t.Fatalf("Failed to list recordsets: %v", err)
+	}
+	if len(list) != 1 || list[0].Name() != "test.com" || list[0].Type() != rrstype.RrsType("SOA") {
+		t.Errorf("expected only the SOA record at the apex, got %v", list)
+	}
+}
+
+func TestResourceRecordSetsAddDuplicateFail(t *testing.T) {
+	sets := rrs(t, firstZone(t))
+	rrset := sets.New("dup.test.com", []string{"10.0.0.1"}, 60, rrstype.A)
+	if err := sets.StartChangeset().Add(rrset).Apply(); err != nil {
+		t.Fatalf("Failed to add recordset: %v", err)
+	}
+	defer sets.StartChangeset().Remove(rrset).Apply()
+
+	if err := sets.StartChangeset().Add(rrset).Apply(); err == nil {
+		t.Errorf("expected error adding a duplicate recordset")
+	}
+}
+
+func TestResourceRecordSetsRemovePartial(t *testing.T) {
+	sets := rrs(t, firstZone(t))
+	rrset := sets.New("multi.test.com", []string{"10.0.0.1", "10.0.0.2"}, 60, rrstype.A)
+	if err := sets.StartChangeset().Add(rrset).Apply(); err != nil {
+		t.Fatalf("Failed to add recordset: %v", err)
+	}
+
+	partial := sets.New("multi.test.com", []string{"10.0.0.1"}, 60, rrstype.A)
+	if err := sets.StartChangeset().Remove(partial).Apply(); err != nil {
+		t.Fatalf("Failed to remove value: %v", err)
+	}
+	remaining := sets.New("multi.test.com", []string{"10.0.0.2"}, 60, rrstype.A)
+	defer sets.StartChangeset().Remove(remaining).Apply()
+
+	found, err := sets.Get("multi.test.com")
+	if err != nil {
+		t.Fatalf("Failed to get recordset: %v", err)
+	}
+	if len(found) != 1 || !reflect.DeepEqual(found[0].Rrdatas(), []string{"10.0.0.2"}) {
+		t.Errorf("unexpected recordsets %v", found)
+	}
+}
+
+func TestResourceRecordSetsUpsertTXT(t *testing.T) {
+	sets := rrs(t, firstZone(t))
+	rrset := sets.New("txt.test.com", []string{"\"heritage=dns-controller,dns-controller/owner=a\""}, 60, rrstype.TXT)
+	if err := sets.StartChangeset().Upsert(rrset).Apply(); err != nil {
+		t.Fatalf("Failed to upsert recordset: %v", err)
+	}
+	defer sets.StartChangeset().Remove(rrset).Apply()
+
+	found, err := sets.Get("txt.test.com")
+	if err != nil {
+		t.Fatalf("Failed to get recordset: %v", err)
+	}
+	if len(found) != 1 || !reflect.DeepEqual(found[0].Rrdatas(), rrset.Rrdatas()) {
+		t.Errorf("unexpected recordsets %v", found)
+	}
+}
+
+func TestRecordOutsideZone(t *testing.T) {
+	sets := rrs(t, firstZone(t))
+	rrset := sets.New("www.example.org", []string{"10.0.0.1"}, 60, rrstype.A)
+	if err := sets.StartChangeset().Add(rrset).Apply(); err == nil {
+		t.Errorf("expected error adding a record outside the zone")
+	}
+}
+
+func TestParseTXT(t *testing.T) {
+	grid := map[string][]string{
+		`"v=spf1 -all"`:   {"v=spf1 -all"},
+		`"a" "b"`:         {"a", "b"},
+		`"say \"hello\""`: {`say "hello"`},
+		`unquoted`:        {"unquoted"},
+		`"unterminated`:   {"unterminated"},
+	}
+	for rrdata, expected := range grid {
+		actual := parseTXT(rrdata)
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("parseTXT(%q) = %q, expected %q", rrdata, actual, expected)
+		}
+	}
+}
+
+func TestResourceRecordSetsReplace(t *testing.T) {
+	tests.CommonTestResourceRecordSetsReplace(t, firstZone(t))
+}
+
+func TestResourceRecordSetsReplaceAll(t *testing.T) {
+	tests.CommonTestResourceRecordSetsReplaceAll(t, firstZone(t))
+}
+
+func TestResourceRecordSetsDifferentTypes(t *testing.T) {
+	tests.CommonTestResourceRecordSetsDifferentTypes(t, firstZone(t))
+}
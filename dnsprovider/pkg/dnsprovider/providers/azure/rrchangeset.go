@@ -0,0 +1,149 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+)
+
+// ResourceRecordChangeset is the Azure DNS implementation of dnsprovider.ResourceRecordChangeset.
+// Azure DNS updates one record set per request, so a changeset is applied as a sequence of requests:
+// removals first, then additions and upserts.  A failure part way through leaves the earlier changes applied.
+type ResourceRecordChangeset struct {
+	rrsets *ResourceRecordSets
+
+	additions []dnsprovider.ResourceRecordSet
+	removals  []dnsprovider.ResourceRecordSet
+	upserts   []dnsprovider.ResourceRecordSet
+}
+
+var _ dnsprovider.ResourceRecordChangeset = &ResourceRecordChangeset{}
+
+// Add adds the creation of a ResourceRecordSet in the Zone to the changeset
+func (c *ResourceRecordChangeset) Add(rrset dnsprovider.ResourceRecordSet) dnsprovider.ResourceRecordChangeset {
+	c.additions = append(c.additions, rrset)
+	return c
+}
+
+// Remove adds the removal of a ResourceRecordSet in the Zone to the changeset
+func (c *ResourceRecordChangeset) Remove(rrset dnsprovider.ResourceRecordSet) dnsprovider.ResourceRecordChangeset {
+	c.removals = append(c.removals, rrset)
+	return c
+}
+
+// Upsert adds an "create or update" operation for the ResourceRecordSet to the changeset
+func (c *ResourceRecordChangeset) Upsert(rrset dnsprovider.ResourceRecordSet) dnsprovider.ResourceRecordChangeset {
+	c.upserts = append(c.upserts, rrset)
+	return c
+}
+
+// Apply applies the changeset to the zone
+func (c *ResourceRecordChangeset) Apply() error {
+	for _, rrset := range c.removals {
+		glog.V(2).Infof("Removing %s %s", rrset.Name(), rrset.Type())
+		if err := c.remove(rrset); err != nil {
+			return err
+		}
+	}
+
+	for _, rrset := range c.additions {
+		glog.V(2).Infof("Adding %s %s", rrset.Name(), rrset.Type())
+		// If-None-Match fails the request if the record set already exists
+		if err := c.put(rrset, map[string]string{"If-None-Match": "*"}); err != nil {
+			return err
+		}
+	}
+
+	for _, rrset := range c.upserts {
+		glog.V(2).Infof("Upserting %s %s", rrset.Name(), rrset.Type())
+		if err := c.put(rrset, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// remove removes the values of rrset from the record set, deleting the record set if no values remain
+func (c *ResourceRecordChangeset) remove(rrset dnsprovider.ResourceRecordSet) error {
+	path, err := c.rrsets.recordSetPath(rrset.Name(), rrset.Type())
+	if err != nil {
+		return err
+	}
+
+	rs := &recordSet{}
+	if err := c.rrsets.zone.client().do(http.MethodGet, path, nil, nil, rs); err != nil {
+		return fmt.Errorf("error reading record set %s %s: %v", rrset.Name(), rrset.Type(), err)
+	}
+	current := c.rrsets.fromRecordSet(rs)
+	if current == nil {
+		return fmt.Errorf("record type %q is not supported by the %s DNS provider", rrset.Type(), ProviderName)
+	}
+
+	removed := make(map[string]bool)
+	for _, rrdata := range rrset.Rrdatas() {
+		removed[rrdata] = true
+	}
+	var remaining []string
+	for _, rrdata := range current.rrdatas {
+		if !removed[rrdata] {
+			remaining = append(remaining, rrdata)
+		}
+	}
+
+	if len(remaining) != 0 {
+		current.rrdatas = remaining
+		return c.put(current, nil)
+	}
+
+	if err := c.rrsets.zone.client().do(http.MethodDelete, path, nil, nil, nil); err != nil {
+		return fmt.Errorf("error deleting record set %s %s: %v", rrset.Name(), rrset.Type(), err)
+	}
+	return nil
+}
+
+// put creates or replaces the record set
+func (c *ResourceRecordChangeset) put(rrset dnsprovider.ResourceRecordSet, headers map[string]string) error {
+	path, err := c.rrsets.recordSetPath(rrset.Name(), rrset.Type())
+	if err != nil {
+		return err
+	}
+	properties, err := toProperties(rrset.Type(), rrset.Rrdatas(), rrset.Ttl())
+	if err != nil {
+		return err
+	}
+
+	if err := c.rrsets.zone.client().do(http.MethodPut, path, headers, &recordSet{Properties: *properties}, nil); err != nil {
+		return fmt.Errorf("error writing record set %s %s: %v", rrset.Name(), rrset.Type(), err)
+	}
+	return nil
+}
+
+// IsEmpty returns true if there are no changes in the changeset
+func (c *ResourceRecordChangeset) IsEmpty() bool {
+	return len(c.additions) == 0 && len(c.removals) == 0 && len(c.upserts) == 0
+}
+
+// ResourceRecordSets returns the parent ResourceRecordSets
+func (c *ResourceRecordChangeset) ResourceRecordSets() dnsprovider.ResourceRecordSets {
+	return c.rrsets
+}
@@ -0,0 +1,369 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+)
+
+// recordSet is a DNS record set resource
+type recordSet struct {
+	Name       string              `json:"name,omitempty"`
+	Type       string              `json:"type,omitempty"`
+	Properties recordSetProperties `json:"properties"`
+}
+
+// recordSetProperties holds the TTL and values of a record set; only the field for the type of the record set is set
+type recordSetProperties struct {
+	TTL         int64        `json:"TTL"`
+	ARecords    []aRecord    `json:"ARecords,omitempty"`
+	AAAARecords []aaaaRecord `json:"AAAARecords,omitempty"`
+	CNAMERecord *cnameRecord `json:"CNAMERecord,omitempty"`
+	TXTRecords  []txtRecord  `json:"TXTRecords,omitempty"`
+	NSRecords   []nsRecord   `json:"NSRecords,omitempty"`
+	MXRecords   []mxRecord   `json:"MXRecords,omitempty"`
+	PTRRecords  []ptrRecord  `json:"PTRRecords,omitempty"`
+	SRVRecords  []srvRecord  `json:"SRVRecords,omitempty"`
+	SOARecord   *soaRecord   `json:"SOARecord,omitempty"`
+}
+
+type aRecord struct {
+	IPv4Address string `json:"ipv4Address"`
+}
+
+type aaaaRecord struct {
+	IPv6Address string `json:"ipv6Address"`
+}
+
+type cnameRecord struct {
+	Cname string `json:"cname"`
+}
+
+type txtRecord struct {
+	Value []string `json:"value"`
+}
+
+type nsRecord struct {
+	Nsdname string `json:"nsdname"`
+}
+
+type mxRecord struct {
+	Preference int    `json:"preference"`
+	Exchange   string `json:"exchange"`
+}
+
+type ptrRecord struct {
+	Ptrdname string `json:"ptrdname"`
+}
+
+type srvRecord struct {
+	Priority int    `json:"priority"`
+	Weight   int    `json:"weight"`
+	Port     int    `json:"port"`
+	Target   string `json:"target"`
+}
+
+type soaRecord struct {
+	Host         string `json:"host"`
+	Email        string `json:"email"`
+	SerialNumber int64  `json:"serialNumber"`
+	RefreshTime  int64  `json:"refreshTime"`
+	RetryTime    int64  `json:"retryTime"`
+	ExpireTime   int64  `json:"expireTime"`
+	MinimumTTL   int64  `json:"minimumTTL"`
+}
+
+// ResourceRecordSets is the Azure DNS implementation of dnsprovider.ResourceRecordSets
+type ResourceRecordSets struct {
+	zone *Zone
+}
+
+var _ dnsprovider.ResourceRecordSets = &ResourceRecordSets{}
+
+// List returns all the record sets in the zone
+func (r *ResourceRecordSets) List() ([]dnsprovider.ResourceRecordSet, error) {
+	var list []dnsprovider.ResourceRecordSet
+	err := r.zone.client().list("/"+r.zone.name+"/recordsets", func(value json.RawMessage) error {
+		var page []recordSet
+		if err := json.Unmarshal(value, &page); err != nil {
+			return err
+		}
+		for i := range page {
+			rrset := r.fromRecordSet(&page[i])
+			if rrset != nil {
+				list = append(list, rrset)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing records in azure DNS zone %q: %v", r.zone.name, err)
+	}
+	return list, nil
+}
+
+// Get returns the record sets with the specified name
+func (r *ResourceRecordSets) Get(name string) ([]dnsprovider.ResourceRecordSet, error) {
+	records, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []dnsprovider.ResourceRecordSet
+	for _, record := range records {
+		if strings.EqualFold(record.Name(), strings.TrimSuffix(name, ".")) {
+			matches = append(matches, record)
+		}
+	}
+	return matches, nil
+}
+
+// StartChangeset starts a set of changes to the zone
+func (r *ResourceRecordSets) StartChangeset() dnsprovider.ResourceRecordChangeset {
+	return &ResourceRecordChangeset{rrsets: r}
+}
+
+// New builds a ResourceRecordSet; it is not created until it is added in a changeset
+func (r *ResourceRecordSets) New(name string, rrdatas []string, ttl int64, rrsType rrstype.RrsType) dnsprovider.ResourceRecordSet {
+	return &ResourceRecordSet{
+		name:    name,
+		rrdatas: rrdatas,
+		ttl:     ttl,
+		rrsType: rrsType,
+		rrsets:  r,
+	}
+}
+
+// Zone returns the parent zone
+func (r *ResourceRecordSets) Zone() dnsprovider.Zone {
+	return r.zone
+}
+
+// relativeName returns the name of a record set relative to the zone, as used in Azure DNS; "@" is the zone apex
+func (r *ResourceRecordSets) relativeName(name string) (string, error) {
+	name = strings.TrimSuffix(name, ".")
+	zone := r.zone.name
+	if strings.EqualFold(name, zone) {
+		return "@", nil
+	}
+	if !strings.HasSuffix(strings.ToLower(name), "."+strings.ToLower(zone)) {
+		return "", fmt.Errorf("record %q is not in zone %q", name, zone)
+	}
+	return name[:len(name)-len(zone)-1], nil
+}
+
+// recordSetPath returns the path of a record set, relative to the dnsZones collection
+func (r *ResourceRecordSets) recordSetPath(name string, rrsType rrstype.RrsType) (string, error) {
+	relativeName, err := r.relativeName(name)
+	if err != nil {
+		return "", err
+	}
+	return "/" + r.zone.name + "/" + string(rrsType) + "/" + relativeName, nil
+}
+
+// fromRecordSet converts an Azure record set; it returns nil for types we do not support
+func (r *ResourceRecordSets) fromRecordSet(rs *recordSet) *ResourceRecordSet {
+	// The resource type is e.g. Microsoft.Network/dnszones/A
+	t := rrstype.RrsType(rs.Type[strings.LastIndex(rs.Type, "/")+1:])
+
+	name := r.zone.name
+	if rs.Name != "@" {
+		name = rs.Name + "." + r.zone.name
+	}
+
+	rrdatas := toRrdatas(t, &rs.Properties)
+	if rrdatas == nil {
+		return nil
+	}
+	return &ResourceRecordSet{
+		name:    name,
+		rrdatas: rrdatas,
+		ttl:     rs.Properties.TTL,
+		rrsType: t,
+		rrsets:  r,
+	}
+}
+
+// ResourceRecordSet is the Azure DNS implementation of dnsprovider.ResourceRecordSet
+type ResourceRecordSet struct {
+	name    string
+	rrdatas []string
+	ttl     int64
+	rrsType rrstype.RrsType
+	rrsets  *ResourceRecordSets
+}
+
+var _ dnsprovider.ResourceRecordSet = &ResourceRecordSet{}
+
+// Name returns the name of the record set
+func (r *ResourceRecordSet) Name() string {
+	return r.name
+}
+
+// Rrdatas returns the values of the record set
+func (r *ResourceRecordSet) Rrdatas() []string {
+	return r.rrdatas
+}
+
+// Ttl returns the TTL of the record set, in seconds
+func (r *ResourceRecordSet) Ttl() int64 {
+	return r.ttl
+}
+
+// Type returns the type of the record set
+func (r *ResourceRecordSet) Type() rrstype.RrsType {
+	return r.rrsType
+}
+
+// toRrdatas returns the values of the record set in presentation format, or nil if the type is not supported
+func toRrdatas(t rrstype.RrsType, p *recordSetProperties) []string {
+	rrdatas := []string{}
+	switch string(t) {
+	case "A":
+		for _, r := range p.ARecords {
+			rrdatas = append(rrdatas, r.IPv4Address)
+		}
+	case "AAAA":
+		for _, r := range p.AAAARecords {
+			rrdatas = append(rrdatas, r.IPv6Address)
+		}
+	case "CNAME":
+		if p.CNAMERecord != nil {
+			rrdatas = append(rrdatas, p.CNAMERecord.Cname)
+		}
+	case "TXT":
+		for _, r := range p.TXTRecords {
+			var quoted []string
+			for _, v := range r.Value {
+				quoted = append(quoted, strconv.Quote(v))
+			}
+			rrdatas = append(rrdatas, strings.Join(quoted, " "))
+		}
+	case "NS":
+		for _, r := range p.NSRecords {
+			rrdatas = append(rrdatas, r.Nsdname)
+		}
+	case "MX":
+		for _, r := range p.MXRecords {
+			rrdatas = append(rrdatas, fmt.Sprintf("%d %s", r.Preference, r.Exchange))
+		}
+	case "PTR":
+		for _, r := range p.PTRRecords {
+			rrdatas = append(rrdatas, r.Ptrdname)
+		}
+	case "SRV":
+		for _, r := range p.SRVRecords {
+			rrdatas = append(rrdatas, fmt.Sprintf("%d %d %d %s", r.Priority, r.Weight, r.Port, r.Target))
+		}
+	case "SOA":
+		if s := p.SOARecord; s != nil {
+			rrdatas = append(rrdatas, fmt.Sprintf("%s %s %d %d %d %d %d", s.Host, s.Email, s.SerialNumber, s.RefreshTime, s.RetryTime, s.ExpireTime, s.MinimumTTL))
+		}
+	default:
+		return nil
+	}
+	return rrdatas
+}
+
+// toProperties builds the Azure properties for a record set
+func toProperties(t rrstype.RrsType, rrdatas []string, ttl int64) (*recordSetProperties, error) {
+	p := &recordSetProperties{TTL: ttl}
+	for _, rrdata := range rrdatas {
+		fields := strings.Fields(rrdata)
+		switch string(t) {
+		case "A":
+			p.ARecords = append(p.ARecords, aRecord{IPv4Address: rrdata})
+		case "AAAA":
+			p.AAAARecords = append(p.AAAARecords, aaaaRecord{IPv6Address: rrdata})
+		case "CNAME":
+			if p.CNAMERecord != nil {
+				return nil, fmt.Errorf("a CNAME record set can only have one value")
+			}
+			p.CNAMERecord = &cnameRecord{Cname: rrdata}
+		case "TXT":
+			p.TXTRecords = append(p.TXTRecords, txtRecord{Value: parseTXT(rrdata)})
+		case "NS":
+			p.NSRecords = append(p.NSRecords, nsRecord{Nsdname: rrdata})
+		case "PTR":
+			p.PTRRecords = append(p.PTRRecords, ptrRecord{Ptrdname: rrdata})
+		case "MX":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("invalid MX record %q", rrdata)
+			}
+			preference, err := strconv.Atoi(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid MX record %q", rrdata)
+			}
+			p.MXRecords = append(p.MXRecords, mxRecord{Preference: preference, Exchange: fields[1]})
+		case "SRV":
+			if len(fields) != 4 {
+				return nil, fmt.Errorf("invalid SRV record %q", rrdata)
+			}
+			var values [3]int
+			for i := range values {
+				v, err := strconv.Atoi(fields[i])
+				if err != nil {
+					return nil, fmt.Errorf("invalid SRV record %q", rrdata)
+				}
+				values[i] = v
+			}
+			p.SRVRecords = append(p.SRVRecords, srvRecord{Priority: values[0], Weight: values[1], Port: values[2], Target: fields[3]})
+		default:
+			return nil, fmt.Errorf("record type %q is not supported by the %s DNS provider", t, ProviderName)
+		}
+	}
+	return p, nil
+}
+
+// parseTXT splits a TXT rrdata, e.g. `"v=spf1" "-all"`, into its strings
+func parseTXT(rrdata string) []string {
+	var values []string
+	rest := strings.TrimSpace(rrdata)
+	for rest != "" {
+		if !strings.HasPrefix(rest, "\"") {
+			return append(values, rest)
+		}
+
+		// Find the closing quote, skipping escaped characters
+		end := -1
+		for i := 1; i < len(rest); i++ {
+			if rest[i] == '\\' {
+				i++
+			} else if rest[i] == '"' {
+				end = i
+				break
+			}
+		}
+		if end == -1 {
+			return append(values, strings.TrimPrefix(rest, "\""))
+		}
+
+		value, err := strconv.Unquote(rest[:end+1])
+		if err != nil {
+			value = rest[1:end]
+		}
+		values = append(values, value)
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+	return values
+}
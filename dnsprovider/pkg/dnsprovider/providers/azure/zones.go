@@ -0,0 +1,122 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+)
+
+// Interface is the Azure DNS implementation of dnsprovider.Interface
+type Interface struct {
+	client *client
+}
+
+var _ dnsprovider.Interface = &Interface{}
+
+// Zones returns the zones in the configured resource group
+func (i *Interface) Zones() (dnsprovider.Zones, bool) {
+	return &Zones{intf: i}, true
+}
+
+// Zones is the Azure DNS implementation of dnsprovider.Zones
+type Zones struct {
+	intf *Interface
+}
+
+var _ dnsprovider.Zones = &Zones{}
+
+// azureZone is a DNS zone resource
+type azureZone struct {
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Location string `json:"location"`
+}
+
+// List returns the zones in the resource group
+func (z *Zones) List() ([]dnsprovider.Zone, error) {
+	var zones []dnsprovider.Zone
+	err := z.intf.client.list("", func(value json.RawMessage) error {
+		var page []azureZone
+		if err := json.Unmarshal(value, &page); err != nil {
+			return err
+		}
+		for _, az := range page {
+			zones = append(zones, &Zone{id: az.ID, name: az.Name, zones: z})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing azure DNS zones: %v", err)
+	}
+	return zones, nil
+}
+
+// Add creates a zone in the resource group
+func (z *Zones) Add(zone dnsprovider.Zone) (dnsprovider.Zone, error) {
+	created := &azureZone{}
+	headers := map[string]string{"If-None-Match": "*"}
+	if err := z.intf.client.do(http.MethodPut, "/"+zone.Name(), headers, &azureZone{Location: "global"}, created); err != nil {
+		return nil, fmt.Errorf("error creating azure DNS zone %q: %v", zone.Name(), err)
+	}
+	return &Zone{id: created.ID, name: created.Name, zones: z}, nil
+}
+
+// Remove deletes a zone
+func (z *Zones) Remove(zone dnsprovider.Zone) error {
+	if err := z.intf.client.do(http.MethodDelete, "/"+zone.Name(), nil, nil, nil); err != nil {
+		return fmt.Errorf("error deleting azure DNS zone %q: %v", zone.Name(), err)
+	}
+	return nil
+}
+
+// New returns a Zone which can be passed to Add
+func (z *Zones) New(name string) (dnsprovider.Zone, error) {
+	return &Zone{name: strings.TrimSuffix(name, "."), zones: z}, nil
+}
+
+// Zone is the Azure DNS implementation of dnsprovider.Zone
+type Zone struct {
+	id    string
+	name  string
+	zones *Zones
+}
+
+var _ dnsprovider.Zone = &Zone{}
+
+// Name returns the name of the zone, e.g. "example.com"
+func (z *Zone) Name() string {
+	return z.name
+}
+
+// ID returns the Azure resource id of the zone
+func (z *Zone) ID() string {
+	return z.id
+}
+
+// ResourceRecordSets returns the records of the zone
+func (z *Zone) ResourceRecordSets() (dnsprovider.ResourceRecordSets, bool) {
+	return &ResourceRecordSets{zone: z}, true
+}
+
+func (z *Zone) client() *client {
+	return z.zones.intf.client
+}
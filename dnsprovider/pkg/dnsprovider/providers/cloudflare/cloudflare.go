@@ -0,0 +1,203 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cloudflare is the implementation of pkg/dnsprovider interface for Cloudflare DNS,
+// using the Cloudflare v4 REST API.
+package cloudflare
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"gopkg.in/gcfg.v1"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+)
+
+const (
+	// ProviderName is the name used to select this DNS provider
+	ProviderName = "cloudflare"
+
+	// APITokenEnvVar is the environment variable from which the API token is read, if it is not in the config
+	APITokenEnvVar = "CLOUDFLARE_API_TOKEN"
+
+	defaultAPIURL = "https://api.cloudflare.com/client/v4"
+
+	// pageSize is the number of items we request in each page of a list
+	pageSize = 100
+)
+
+// Config is the configuration of the provider, for example:
+//
+//	[global]
+//	api-token = <token with Zone.DNS edit permission>
+type Config struct {
+	Global struct {
+		// APIURL overrides the URL of the Cloudflare API
+		APIURL string `gcfg:"api-url"`
+		// APIToken is the API token; it is normally passed in the CLOUDFLARE_API_TOKEN environment variable
+		APIToken string `gcfg:"api-token"`
+	}
+}
+
+func init() {
+	dnsprovider.RegisterDnsProvider(ProviderName, func(config io.Reader) (dnsprovider.Interface, error) {
+		return newCloudflareProviderInterface(config)
+	})
+}
+
+// newCloudflareProviderInterface creates a new instance of a Cloudflare DNS Interface
+func newCloudflareProviderInterface(config io.Reader) (*Interface, error) {
+	var cfg Config
+	if config != nil {
+		if err := gcfg.ReadInto(&cfg, config); err != nil {
+			glog.Errorf("Couldn't read config: %v", err)
+			return nil, err
+		}
+	}
+
+	if cfg.Global.APIToken == "" {
+		cfg.Global.APIToken = os.Getenv(APITokenEnvVar)
+	}
+	if cfg.Global.APIToken == "" {
+		return nil, fmt.Errorf("the %s DNS provider requires an API token, in the config or in %s", ProviderName, APITokenEnvVar)
+	}
+	if cfg.Global.APIURL == "" {
+		cfg.Global.APIURL = defaultAPIURL
+	}
+
+	c := &client{
+		apiURL:     strings.TrimSuffix(cfg.Global.APIURL, "/"),
+		apiToken:   cfg.Global.APIToken,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	return &Interface{client: c}, nil
+}
+
+// client makes requests to the Cloudflare API
+type client struct {
+	apiURL     string
+	apiToken   string
+	httpClient *http.Client
+}
+
+// apiResponse is the envelope of every Cloudflare API response
+type apiResponse struct {
+	Success    bool            `json:"success"`
+	Errors     []apiError      `json:"errors"`
+	Result     json.RawMessage `json:"result"`
+	ResultInfo *apiResultInfo  `json:"result_info"`
+}
+
+type apiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type apiResultInfo struct {
+	Page       int `json:"page"`
+	TotalPages int `json:"total_pages"`
+}
+
+// do sends a request to the API, and decodes the result into result (if not nil)
+func (c *client) do(method string, path string, query url.Values, body interface{}, result interface{}) (*apiResultInfo, error) {
+	u := c.apiURL + path
+	if len(query) != 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("error serializing request: %v", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, u, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("error building request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiToken)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	glog.V(4).Infof("cloudflare request: %s %s", method, u)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling cloudflare API %s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response from cloudflare API %s %s: %v", method, path, err)
+	}
+
+	response := &apiResponse{}
+	if err := json.Unmarshal(data, response); err != nil {
+		return nil, fmt.Errorf("error parsing response from cloudflare API %s %s (status %d): %v", method, path, resp.StatusCode, err)
+	}
+	if !response.Success {
+		var messages []string
+		for _, e := range response.Errors {
+			messages = append(messages, fmt.Sprintf("%d: %s", e.Code, e.Message))
+		}
+		return nil, fmt.Errorf("cloudflare API %s %s failed (status %d): %s", method, path, resp.StatusCode, strings.Join(messages, "; "))
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(response.Result, result); err != nil {
+			return nil, fmt.Errorf("error parsing result from cloudflare API %s %s: %v", method, path, err)
+		}
+	}
+	return response.ResultInfo, nil
+}
+
+// list fetches every page of a list, calling appendPage with the raw result of each page
+func (c *client) list(path string, query url.Values, appendPage func(json.RawMessage) error) error {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("per_page", fmt.Sprintf("%d", pageSize))
+
+	for page := 1; ; page++ {
+		query.Set("page", fmt.Sprintf("%d", page))
+
+		var result json.RawMessage
+		info, err := c.do(http.MethodGet, path, query, nil, &result)
+		if err != nil {
+			return err
+		}
+		if err := appendPage(result); err != nil {
+			return fmt.Errorf("error parsing result from cloudflare API GET %s: %v", path, err)
+		}
+		if info == nil || page >= info.TotalPages {
+			return nil
+		}
+	}
+}
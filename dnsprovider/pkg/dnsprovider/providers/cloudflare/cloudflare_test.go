@@ -0,0 +1,288 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/tests"
+)
+
+const testAPIToken = "test-token"
+
+// fakePageSize is deliberately small, so that the tests exercise paging
+const fakePageSize = 2
+
+// fakeAPI is a minimal in-memory implementation of the Cloudflare zones and dns_records API
+type fakeAPI struct {
+	mutex   sync.Mutex
+	nextID  int
+	zones   []*cloudflareZone
+	records map[string][]*cloudflareRecord
+}
+
+func (f *fakeAPI) id() string {
+	f.nextID++
+	return fmt.Sprintf("%032x", f.nextID)
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+testAPIToken {
+		writeResponse(w, http.StatusForbidden, nil, nil, "Invalid access token")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "zones" && r.Method == http.MethodGet:
+		var items []interface{}
+		for _, z := range f.zones {
+			items = append(items, z)
+		}
+		writePage(w, r, items)
+
+	case len(parts) == 1 && parts[0] == "zones" && r.Method == http.MethodPost:
+		z := &cloudflareZone{}
+		json.NewDecoder(r.Body).Decode(z)
+		z.ID = f.id()
+		f.zones = append(f.zones, z)
+		writeResponse(w, http.StatusOK, z, nil, "")
+
+	case len(parts) == 3 && parts[2] == "dns_records" && r.Method == http.MethodGet:
+		var items []interface{}
+		for _, record := range f.records[parts[1]] {
+			if name := r.URL.Query().Get("name"); name != "" && name != record.Name {
+				continue
+			}
+			items = append(items, record)
+		}
+		writePage(w, r, items)
+
+	case len(parts) == 3 && parts[2] == "dns_records" && r.Method == http.MethodPost:
+		record := &cloudflareRecord{}
+		json.NewDecoder(r.Body).Decode(record)
+		for _, existing := range f.records[parts[1]] {
+			if existing.Name == record.Name && existing.Type == record.Type && existing.Content == record.Content {
+				writeResponse(w, http.StatusBadRequest, nil, nil, "The record already exists.")
+				return
+			}
+		}
+		record.ID = f.id()
+		f.records[parts[1]] = append(f.records[parts[1]], record)
+		writeResponse(w, http.StatusOK, record, nil, "")
+
+	case len(parts) == 4 && parts[2] == "dns_records" && r.Method == http.MethodDelete:
+		var kept []*cloudflareRecord
+		for _, record := range f.records[parts[1]] {
+			if record.ID != parts[3] {
+				kept = append(kept, record)
+			}
+		}
+		if len(kept) == len(f.records[parts[1]]) {
+			writeResponse(w, http.StatusNotFound, nil, nil, "Record not found")
+			return
+		}
+		f.records[parts[1]] = kept
+		writeResponse(w, http.StatusOK, map[string]string{"id": parts[3]}, nil, "")
+
+	default:
+		writeResponse(w, http.StatusNotFound, nil, nil, "Unknown route")
+	}
+}
+
+func writePage(w http.ResponseWriter, r *http.Request, items []interface{}) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	totalPages := (len(items) + fakePageSize - 1) / fakePageSize
+
+	result := []interface{}{}
+	for i := (page - 1) * fakePageSize; i < len(items) && i < page*fakePageSize; i++ {
+		result = append(result, items[i])
+	}
+	writeResponse(w, http.StatusOK, result, &apiResultInfo{Page: page, TotalPages: totalPages}, "")
+}
+
+func writeResponse(w http.ResponseWriter, status int, result interface{}, info *apiResultInfo, errorMessage string) {
+	response := map[string]interface{}{
+		"success":     errorMessage == "",
+		"errors":      []apiError{},
+		"result":      result,
+		"result_info": info,
+	}
+	if errorMessage != "" {
+		response["errors"] = []apiError{{Code: 1000, Message: errorMessage}}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+var intf dnsprovider.Interface
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	api := &fakeAPI{records: make(map[string][]*cloudflareRecord)}
+	api.zones = append(api.zones, &cloudflareZone{ID: api.id(), Name: "test.com"})
+	server := httptest.NewServer(api)
+
+	var err error
+	config := fmt.Sprintf("[global]\napi-url = %s\napi-token = %s\n", server.URL, testAPIToken)
+	intf, err = dnsprovider.GetDnsProvider(ProviderName, strings.NewReader(config))
+	if err != nil {
+		fmt.Printf("Error creating interface: %v", err)
+		os.Exit(1)
+	}
+
+	rc := m.Run()
+	server.Close()
+	os.Exit(rc)
+}
+
+// firstZone returns the first zone for the configured dns provider account/project,
+// or fails if it can't be found
+func firstZone(t *testing.T) dnsprovider.Zone {
+	zonesInterface, supported := intf.Zones()
+	if !supported {
+		t.Fatalf("Zones interface not supported by interface %v", intf)
+	}
+	zones, err := zonesInterface.List()
+	if err != nil {
+		t.Fatalf("Failed to list zones: %v", err)
+	}
+	if len(zones) == 0 {
+		t.Fatalf("No zones were found")
+	}
+	return zones[0]
+}
+
+func rrs(t *testing.T, zone dnsprovider.Zone) dnsprovider.ResourceRecordSets {
+	rrsets, supported := zone.ResourceRecordSets()
+	if !supported {
+		t.Fatalf("ResourceRecordSets interface not supported by zone %v", zone)
+	}
+	return rrsets
+}
+
+func TestZonesList(t *testing.T) {
+	zone := firstZone(t)
+	if zone.Name() != "test.com" || zone.ID() == "" {
+		t.Errorf("unexpected zone name=%q id=%q", zone.Name(), zone.ID())
+	}
+}
+
+func TestZoneAddSuccess(t *testing.T) {
+	zones, _ := intf.Zones()
+	zone, err := zones.New("added.com.")
+	if err != nil {
+		t.Fatalf("error building zone: %v", err)
+	}
+	added, err := zones.Add(zone)
+	if err != nil {
+		t.Fatalf("error adding zone: %v", err)
+	}
+	if added.Name() != "added.com" || added.ID() == "" {
+		t.Errorf("unexpected zone name=%q id=%q", added.Name(), added.ID())
+	}
+}
+
+func TestMissingToken(t *testing.T) {
+	os.Unsetenv(APITokenEnvVar)
+	if _, err := dnsprovider.GetDnsProvider(ProviderName, strings.NewReader("[global]\n")); err == nil {
+		t.Errorf("expected error when no API token is configured")
+	}
+}
+
+func TestResourceRecordSetsPaging(t *testing.T) {
+	sets := rrs(t, firstZone(t))
+	rrset := sets.New("www.test.com", []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, 120, rrstype.A)
+	if err := sets.StartChangeset().Add(rrset).Apply(); err != nil {
+		t.Fatalf("Failed to add recordset: %v", err)
+	}
+	defer sets.StartChangeset().Remove(rrset).Apply()
+
+	found, err := sets.Get("www.test.com")
+	if err != nil {
+		t.Fatalf("Failed to get recordset: %v", err)
+	}
+	if len(found) != 1 || len(found[0].Rrdatas()) != 3 {
+		t.Errorf("unexpected recordsets %v", found)
+	}
+}
+
+func TestResourceRecordSetsAddDuplicateFail(t *testing.T) {
+	sets := rrs(t, firstZone(t))
+	rrset := sets.New("dup.test.com", []string{"10.0.0.1"}, 120, rrstype.A)
+	if err := sets.StartChangeset().Add(rrset).Apply(); err != nil {
+		t.Fatalf("Failed to add recordset: %v", err)
+	}
+	defer sets.StartChangeset().Remove(rrset).Apply()
+
+	other := sets.New("dup.test.com", []string{"10.0.0.2"}, 120, rrstype.A)
+	if err := sets.StartChangeset().Add(other).Apply(); err == nil {
+		t.Errorf("expected error adding a duplicate recordset")
+	}
+}
+
+func TestResourceRecordSetsUpsertTXT(t *testing.T) {
+	sets := rrs(t, firstZone(t))
+	rrset := sets.New("txt.test.com", []string{"\"heritage=dns-controller\""}, 60, rrstype.TXT)
+	if err := sets.StartChangeset().Upsert(rrset).Apply(); err != nil {
+		t.Fatalf("Failed to upsert recordset: %v", err)
+	}
+	defer sets.StartChangeset().Remove(rrset).Apply()
+
+	if err := sets.StartChangeset().Upsert(rrset).Apply(); err != nil {
+		t.Fatalf("Failed to upsert recordset again: %v", err)
+	}
+
+	found, err := sets.Get("txt.test.com")
+	if err != nil {
+		t.Fatalf("Failed to get recordset: %v", err)
+	}
+	if len(found) != 1 || len(found[0].Rrdatas()) != 1 || found[0].Rrdatas()[0] != "\"heritage=dns-controller\"" {
+		t.Errorf("unexpected recordsets %v", found)
+	}
+}
+
+func TestResourceRecordSetsReplace(t *testing.T) {
+	tests.CommonTestResourceRecordSetsReplace(t, firstZone(t))
+}
+
+func TestResourceRecordSetsReplaceAll(t *testing.T) {
+	tests.CommonTestResourceRecordSetsReplaceAll(t, firstZone(t))
+}
+
+func TestResourceRecordSetsDifferentTypes(t *testing.T) {
+	tests.CommonTestResourceRecordSetsDifferentTypes(t, firstZone(t))
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang/glog"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+)
+
+// ResourceRecordChangeset is the Cloudflare implementation of dnsprovider.ResourceRecordChangeset.
+// The Cloudflare API has no batch operations, so a changeset is applied as a sequence of requests:
+// removals first, then additions and upserts.  A failure part way through leaves the earlier changes applied.
+type ResourceRecordChangeset struct {
+	rrsets *ResourceRecordSets
+
+	additions []dnsprovider.ResourceRecordSet
+	removals  []dnsprovider.ResourceRecordSet
+	upserts   []dnsprovider.ResourceRecordSet
+}
+
+var _ dnsprovider.ResourceRecordChangeset = &ResourceRecordChangeset{}
+
+// Add adds the creation of a ResourceRecordSet in the Zone to the changeset
+func (c *ResourceRecordChangeset) Add(rrset dnsprovider.ResourceRecordSet) dnsprovider.ResourceRecordChangeset {
+	c.additions = append(c.additions, rrset)
+	return c
+}
+
+// Remove adds the removal of a ResourceRecordSet in the Zone to the changeset
+func (c *ResourceRecordChangeset) Remove(rrset dnsprovider.ResourceRecordSet) dnsprovider.ResourceRecordChangeset {
+	c.removals = append(c.removals, rrset)
+	return c
+}
+
+// Upsert adds an "create or update" operation for the ResourceRecordSet to the changeset
+func (c *ResourceRecordChangeset) Upsert(rrset dnsprovider.ResourceRecordSet) dnsprovider.ResourceRecordChangeset {
+	c.upserts = append(c.upserts, rrset)
+	return c
+}
+
+// Apply applies the changeset to the zone
+func (c *ResourceRecordChangeset) Apply() error {
+	if c.IsEmpty() {
+		return nil
+	}
+
+	records, err := c.rrsets.listRecords("")
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]*ResourceRecordSet)
+	for _, rrset := range c.rrsets.groupRecords(records) {
+		existing[key(rrset.name, rrset.rrsType)] = rrset
+	}
+
+	// Check the additions before we change anything, so that we fail cleanly
+	removed := make(map[string]bool)
+	for _, rrset := range c.removals {
+		removed[key(rrset.Name(), rrset.Type())] = true
+	}
+	for _, rrset := range c.additions {
+		k := key(rrset.Name(), rrset.Type())
+		if existing[k] != nil && !removed[k] {
+			return fmt.Errorf("cannot add record set %s %s: it already exists", rrset.Name(), rrset.Type())
+		}
+	}
+
+	for _, rrset := range c.removals {
+		current := existing[key(rrset.Name(), rrset.Type())]
+		if current == nil {
+			return fmt.Errorf("cannot remove record set %s %s: it was not found", rrset.Name(), rrset.Type())
+		}
+
+		glog.V(2).Infof("Removing %s %s", rrset.Name(), rrset.Type())
+		for _, rrdata := range rrset.Rrdatas() {
+			for _, record := range current.records {
+				if record.Content != toContent(rrset.Type(), rrdata) {
+					continue
+				}
+				if err := c.deleteRecord(record); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, rrset := range c.additions {
+		glog.V(2).Infof("Adding %s %s", rrset.Name(), rrset.Type())
+		if err := c.createRecords(rrset); err != nil {
+			return err
+		}
+	}
+
+	for _, rrset := range c.upserts {
+		glog.V(2).Infof("Upserting %s %s", rrset.Name(), rrset.Type())
+		if current := existing[key(rrset.Name(), rrset.Type())]; current != nil {
+			for _, record := range current.records {
+				if err := c.deleteRecord(record); err != nil {
+					return err
+				}
+			}
+		}
+		if err := c.createRecords(rrset); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createRecords creates a Cloudflare record for each value of the record set
+func (c *ResourceRecordChangeset) createRecords(rrset dnsprovider.ResourceRecordSet) error {
+	zone := c.rrsets.zone
+	for _, rrdata := range rrset.Rrdatas() {
+		record := &cloudflareRecord{
+			Type:    string(rrset.Type()),
+			Name:    strings.TrimSuffix(rrset.Name(), "."),
+			Content: toContent(rrset.Type(), rrdata),
+			TTL:     rrset.Ttl(),
+		}
+		if _, err := zone.client().do(http.MethodPost, "/zones/"+zone.id+"/dns_records", nil, record, nil); err != nil {
+			return fmt.Errorf("error creating record %s %s in cloudflare zone %q: %v", rrset.Name(), rrset.Type(), zone.name, err)
+		}
+	}
+	return nil
+}
+
+func (c *ResourceRecordChangeset) deleteRecord(record *cloudflareRecord) error {
+	zone := c.rrsets.zone
+	if _, err := zone.client().do(http.MethodDelete, "/zones/"+zone.id+"/dns_records/"+record.ID, nil, nil, nil); err != nil {
+		return fmt.Errorf("error deleting record %s %s from cloudflare zone %q: %v", record.Name, record.Type, zone.name, err)
+	}
+	return nil
+}
+
+// IsEmpty returns true if there are no changes in the changeset
+func (c *ResourceRecordChangeset) IsEmpty() bool {
+	return len(c.additions) == 0 && len(c.removals) == 0 && len(c.upserts) == 0
+}
+
+// ResourceRecordSets returns the parent ResourceRecordSets
+func (c *ResourceRecordChangeset) ResourceRecordSets() dnsprovider.ResourceRecordSets {
+	return c.rrsets
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+)
+
+// cloudflareRecord is a DNS record in the Cloudflare API.  Cloudflare has no record sets;
+// each value of a record set is a separate record.
+type cloudflareRecord struct {
+	ID      string `json:"id,omitempty"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	TTL     int64  `json:"ttl"`
+}
+
+// ResourceRecordSets is the Cloudflare implementation of dnsprovider.ResourceRecordSets
+type ResourceRecordSets struct {
+	zone *Zone
+}
+
+var _ dnsprovider.ResourceRecordSets = &ResourceRecordSets{}
+
+// listRecords returns the records in the zone, optionally only those with the specified name
+func (r *ResourceRecordSets) listRecords(name string) ([]*cloudflareRecord, error) {
+	query := url.Values{}
+	if name != "" {
+		query.Set("name", name)
+	}
+
+	var records []*cloudflareRecord
+	err := r.zone.client().list("/zones/"+r.zone.id+"/dns_records", query, func(result json.RawMessage) error {
+		var page []*cloudflareRecord
+		if err := json.Unmarshal(result, &page); err != nil {
+			return err
+		}
+		records = append(records, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing records in cloudflare zone %q: %v", r.zone.name, err)
+	}
+	return records, nil
+}
+
+// groupRecords groups the Cloudflare records into record sets, by name and type
+func (r *ResourceRecordSets) groupRecords(records []*cloudflareRecord) []*ResourceRecordSet {
+	var rrsets []*ResourceRecordSet
+	byKey := make(map[string]*ResourceRecordSet)
+	for _, record := range records {
+		k := key(record.Name, rrstype.RrsType(record.Type))
+		rrset := byKey[k]
+		if rrset == nil {
+			rrset = &ResourceRecordSet{
+				name:    record.Name,
+				ttl:     record.TTL,
+				rrsType: rrstype.RrsType(record.Type),
+				rrsets:  r,
+			}
+			byKey[k] = rrset
+			rrsets = append(rrsets, rrset)
+		}
+		rrset.rrdatas = append(rrset.rrdatas, fromContent(rrset.rrsType, record.Content))
+		rrset.records = append(rrset.records, record)
+	}
+	return rrsets
+}
+
+// List returns all the record sets in the zone
+func (r *ResourceRecordSets) List() ([]dnsprovider.ResourceRecordSet, error) {
+	records, err := r.listRecords("")
+	if err != nil {
+		return nil, err
+	}
+
+	var list []dnsprovider.ResourceRecordSet
+	for _, rrset := range r.groupRecords(records) {
+		list = append(list, rrset)
+	}
+	return list, nil
+}
+
+// Get returns the record sets with the specified name
+func (r *ResourceRecordSets) Get(name string) ([]dnsprovider.ResourceRecordSet, error) {
+	records, err := r.listRecords(strings.TrimSuffix(name, "."))
+	if err != nil {
+		return nil, err
+	}
+
+	var list []dnsprovider.ResourceRecordSet
+	for _, rrset := range r.groupRecords(records) {
+		list = append(list, rrset)
+	}
+	return list, nil
+}
+
+// StartChangeset starts a set of changes to the zone
+func (r *ResourceRecordSets) StartChangeset() dnsprovider.ResourceRecordChangeset {
+	return &ResourceRecordChangeset{rrsets: r}
+}
+
+// New builds a ResourceRecordSet; it is not created until it is added in a changeset
+func (r *ResourceRecordSets) New(name string, rrdatas []string, ttl int64, rrsType rrstype.RrsType) dnsprovider.ResourceRecordSet {
+	return &ResourceRecordSet{
+		name:    name,
+		rrdatas: rrdatas,
+		ttl:     ttl,
+		rrsType: rrsType,
+		rrsets:  r,
+	}
+}
+
+// Zone returns the parent zone
+func (r *ResourceRecordSets) Zone() dnsprovider.Zone {
+	return r.zone
+}
+
+// ResourceRecordSet is the Cloudflare implementation of dnsprovider.ResourceRecordSet
+type ResourceRecordSet struct {
+	name    string
+	rrdatas []string
+	ttl     int64
+	rrsType rrstype.RrsType
+	rrsets  *ResourceRecordSets
+
+	// records are the Cloudflare records, if the record set was read from Cloudflare
+	records []*cloudflareRecord
+}
+
+var _ dnsprovider.ResourceRecordSet = &ResourceRecordSet{}
+
+// Name returns the name of the record set
+func (r *ResourceRecordSet) Name() string {
+	return r.name
+}
+
+// Rrdatas returns the values of the record set
+func (r *ResourceRecordSet) Rrdatas() []string {
+	return r.rrdatas
+}
+
+// Ttl returns the TTL of the record set, in seconds; 1 means that cloudflare chooses the TTL
+func (r *ResourceRecordSet) Ttl() int64 {
+	return r.ttl
+}
+
+// Type returns the type of the record set
+func (r *ResourceRecordSet) Type() rrstype.RrsType {
+	return r.rrsType
+}
+
+// key identifies a record set by name and type
+func key(name string, rrsType rrstype.RrsType) string {
+	return strings.ToLower(strings.TrimSuffix(name, ".")) + "/" + string(rrsType)
+}
+
+// toContent converts an rrdata to the content of a Cloudflare record; Cloudflare does not quote TXT records
+func toContent(rrsType rrstype.RrsType, rrdata string) string {
+	if rrsType == rrstype.TXT && len(rrdata) >= 2 && strings.HasPrefix(rrdata, "\"") && strings.HasSuffix(rrdata, "\"") {
+		return rrdata[1 : len(rrdata)-1]
+	}
+	return rrdata
+}
+
+// fromContent converts the content of a Cloudflare record to an rrdata, as returned by the other providers
+func fromContent(rrsType rrstype.RrsType, content string) string {
+	if rrsType == rrstype.TXT && !strings.HasPrefix(content, "\"") {
+		return "\"" + content + "\""
+	}
+	return content
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+)
+
+// Interface is the Cloudflare implementation of dnsprovider.Interface
+type Interface struct {
+	client *client
+}
+
+var _ dnsprovider.Interface = &Interface{}
+
+// Zones returns the zones accessible with the API token
+func (i *Interface) Zones() (dnsprovider.Zones, bool) {
+	return &Zones{intf: i}, true
+}
+
+// Zones is the Cloudflare implementation of dnsprovider.Zones
+type Zones struct {
+	intf *Interface
+}
+
+var _ dnsprovider.Zones = &Zones{}
+
+// cloudflareZone is a zone in the Cloudflare API
+type cloudflareZone struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+// List returns the zones accessible with the API token
+func (z *Zones) List() ([]dnsprovider.Zone, error) {
+	var zones []dnsprovider.Zone
+	err := z.intf.client.list("/zones", nil, func(result json.RawMessage) error {
+		var page []cloudflareZone
+		if err := json.Unmarshal(result, &page); err != nil {
+			return err
+		}
+		for _, cz := range page {
+			zones = append(zones, &Zone{id: cz.ID, name: cz.Name, zones: z})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing cloudflare zones: %v", err)
+	}
+	return zones, nil
+}
+
+// Add creates a zone
+func (z *Zones) Add(zone dnsprovider.Zone) (dnsprovider.Zone, error) {
+	created := &cloudflareZone{}
+	if _, err := z.intf.client.do(http.MethodPost, "/zones", nil, &cloudflareZone{Name: zone.Name()}, created); err != nil {
+		return nil, fmt.Errorf("error creating cloudflare zone %q: %v", zone.Name(), err)
+	}
+	return &Zone{id: created.ID, name: created.Name, zones: z}, nil
+}
+
+// Remove deletes a zone
+func (z *Zones) Remove(zone dnsprovider.Zone) error {
+	if _, err := z.intf.client.do(http.MethodDelete, "/zones/"+zone.ID(), nil, nil, nil); err != nil {
+		return fmt.Errorf("error deleting cloudflare zone %q: %v", zone.Name(), err)
+	}
+	return nil
+}
+
+// New returns a Zone which can be passed to Add
+func (z *Zones) New(name string) (dnsprovider.Zone, error) {
+	return &Zone{name: strings.TrimSuffix(name, "."), zones: z}, nil
+}
+
+// Zone is the Cloudflare implementation of dnsprovider.Zone
+type Zone struct {
+	id    string
+	name  string
+	zones *Zones
+}
+
+var _ dnsprovider.Zone = &Zone{}
+
+// Name returns the name of the zone, e.g. "example.com"
+func (z *Zone) Name() string {
+	return z.name
+}
+
+// ID returns the cloudflare identifier of the zone
+func (z *Zone) ID() string {
+	return z.id
+}
+
+// ResourceRecordSets returns the records of the zone
+func (z *Zone) ResourceRecordSets() (dnsprovider.ResourceRecordSets, bool) {
+	return &ResourceRecordSets{zone: z}, true
+}
+
+func (z *Zone) client() *client {
+	return z.zones.intf.client
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rfc2136 is the implementation of pkg/dnsprovider interface for DNS servers which support
+// RFC 2136 dynamic updates, such as BIND or Knot.  Records are listed with a zone transfer (AXFR),
+// and both updates and transfers can be signed with TSIG.
+package rfc2136
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/miekg/dns"
+	"gopkg.in/gcfg.v1"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+)
+
+const (
+	// ProviderName is the name used to select this DNS provider
+	ProviderName = "rfc2136"
+
+	// TSIGSecretEnvVar is the environment variable from which the TSIG secret is read, if it is not in the config
+	TSIGSecretEnvVar = "RFC2136_TSIG_SECRET"
+
+	// tsigFudge is the permitted clock skew of a TSIG signature, in seconds
+	tsigFudge = 300
+
+	defaultTimeout = 10 * time.Second
+)
+
+// Config is the configuration of the provider, for example:
+//
+//	[global]
+//	server = ns1.example.com:53
+//	zone = example.com
+//	tsig-key-name = kops
+//	tsig-algorithm = hmac-sha256
+type Config struct {
+	Global struct {
+		// Server is the host:port of the primary DNS server for the zones
+		Server string `gcfg:"server"`
+		// Zones are the zones to manage; the server does not tell us which zones it is authoritative for
+		Zones []string `gcfg:"zone"`
+		// TSIGKeyName is the name of the TSIG key; if not set, requests are not signed
+		TSIGKeyName string `gcfg:"tsig-key-name"`
+		// TSIGAlgorithm is the TSIG algorithm, defaulting to hmac-sha256
+		TSIGAlgorithm string `gcfg:"tsig-algorithm"`
+		// TSIGSecret is the base64 encoded TSIG secret; it is normally passed in the RFC2136_TSIG_SECRET environment variable
+		TSIGSecret string `gcfg:"tsig-secret"`
+	}
+}
+
+func init() {
+	dnsprovider.RegisterDnsProvider(ProviderName, func(config io.Reader) (dnsprovider.Interface, error) {
+		return newRFC2136ProviderInterface(config)
+	})
+}
+
+// newRFC2136ProviderInterface creates a new instance of an RFC 2136 DNS Interface
+func newRFC2136ProviderInterface(config io.Reader) (*Interface, error) {
+	if config == nil {
+		return nil, fmt.Errorf("the %s DNS provider must be configured with a server and zones", ProviderName)
+	}
+
+	var cfg Config
+	if err := gcfg.ReadInto(&cfg, config); err != nil {
+		glog.Errorf("Couldn't read config: %v", err)
+		return nil, err
+	}
+
+	if cfg.Global.TSIGSecret == "" {
+		cfg.Global.TSIGSecret = os.Getenv(TSIGSecretEnvVar)
+	}
+
+	return NewInterface(cfg)
+}
+
+// NewInterface creates an RFC 2136 DNS Interface from its configuration
+func NewInterface(cfg Config) (*Interface, error) {
+	if cfg.Global.Server == "" {
+		return nil, fmt.Errorf("the %s DNS provider must be configured with a server", ProviderName)
+	}
+	if len(cfg.Global.Zones) == 0 {
+		return nil, fmt.Errorf("the %s DNS provider must be configured with at least one zone", ProviderName)
+	}
+
+	c := &client{
+		server:  cfg.Global.Server,
+		timeout: defaultTimeout,
+	}
+
+	if cfg.Global.TSIGKeyName != "" {
+		if cfg.Global.TSIGSecret == "" {
+			return nil, fmt.Errorf("a TSIG secret must be set for TSIG key %q, in the config or in %s", cfg.Global.TSIGKeyName, TSIGSecretEnvVar)
+		}
+
+		algorithm, err := tsigAlgorithm(cfg.Global.TSIGAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+
+		c.tsigKeyName = dns.Fqdn(cfg.Global.TSIGKeyName)
+		c.tsigAlgorithm = algorithm
+		c.tsigSecret = map[string]string{c.tsigKeyName: cfg.Global.TSIGSecret}
+	}
+
+	glog.Infof("Using RFC 2136 DNS provider with server %s", c.server)
+
+	i := &Interface{client: c}
+	for _, zone := range cfg.Global.Zones {
+		i.zones = append(i.zones, &Zone{name: normalizeName(zone), zones: &Zones{intf: i}})
+	}
+	return i, nil
+}
+
+// tsigAlgorithm maps the name of a TSIG algorithm to its name in DNS
+func tsigAlgorithm(name string) (string, error) {
+	switch strings.TrimSuffix(strings.ToLower(name), ".") {
+	case "", "hmac-sha256":
+		return dns.HmacSHA256, nil
+	case "hmac-sha512":
+		return dns.HmacSHA512, nil
+	case "hmac-sha1":
+		return dns.HmacSHA1, nil
+	case "hmac-md5", "hmac-md5.sig-alg.reg.int":
+		return dns.HmacMD5, nil
+	default:
+		return "", fmt.Errorf("unsupported TSIG algorithm %q", name)
+	}
+}
+
+// normalizeName returns a name without the trailing dot, as the other providers do
+func normalizeName(name string) string {
+	return strings.TrimSuffix(name, ".")
+}
+
+// client sends dynamic updates and zone transfers to the DNS server
+type client struct {
+	server  string
+	timeout time.Duration
+
+	tsigKeyName   string
+	tsigAlgorithm string
+	tsigSecret    map[string]string
+}
+
+// sign adds a TSIG record to the message, if we are configured with a TSIG key
+func (c *client) sign(m *dns.Msg) {
+	if c.tsigKeyName != "" {
+		m.SetTsig(c.tsigKeyName, c.tsigAlgorithm, tsigFudge, time.Now().Unix())
+	}
+}
+
+// transfer returns all the records in the zone, using AXFR
+func (c *client) transfer(zone string) ([]dns.RR, error) {
+	m := new(dns.Msg)
+	m.SetAxfr(dns.Fqdn(zone))
+	c.sign(m)
+
+	t := &dns.Transfer{
+		DialTimeout:  c.timeout,
+		ReadTimeout:  c.timeout,
+		WriteTimeout: c.timeout,
+		TsigSecret:   c.tsigSecret,
+	}
+	env, err := t.In(m, c.server)
+	if err != nil {
+		return nil, fmt.Errorf("error starting zone transfer of %q from %s: %v", zone, c.server, err)
+	}
+
+	var records []dns.RR
+	for e := range env {
+		if e.Error != nil {
+			return nil, fmt.Errorf("error during zone transfer of %q from %s: %v", zone, c.server, e.Error)
+		}
+		records = append(records, e.RR...)
+	}
+	return records, nil
+}
+
+// update sends a dynamic update to the server
+func (c *client) update(m *dns.Msg) error {
+	c.sign(m)
+
+	dc := &dns.Client{
+		Net:        "tcp",
+		Timeout:    c.timeout,
+		TsigSecret: c.tsigSecret,
+	}
+	r, _, err := dc.Exchange(m, c.server)
+	if err != nil {
+		return fmt.Errorf("error sending DNS update to %s: %v", c.server, err)
+	}
+	if r.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("DNS update of zone %q was rejected by %s: %s", m.Question[0].Name, c.server, dns.RcodeToString[r.Rcode])
+	}
+	return nil
+}
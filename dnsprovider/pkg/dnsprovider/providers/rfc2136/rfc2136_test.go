@@ -0,0 +1,322 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rfc2136
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/miekg/dns"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/tests"
+)
+
+const (
+	testZone       = "test.com"
+	testTSIGKey    = "kops-test."
+	testTSIGSecret = "c2VjcmV0LXNlY3JldC1zZWNyZXQtc2VjcmV0LXNlY3I="
+)
+
+// fakeServer is a minimal authoritative DNS server, which supports TSIG signed AXFR and dynamic updates
+type fakeServer struct {
+	mutex   sync.Mutex
+	zone    string
+	soa     dns.RR
+	records []dns.RR
+}
+
+func newFakeServer(zone string) *fakeServer {
+	soa, _ := dns.NewRR(dns.Fqdn(zone) + " 3600 IN SOA ns1." + dns.Fqdn(zone) + " hostmaster." + dns.Fqdn(zone) + " 1 7200 3600 1209600 300")
+	return &fakeServer{zone: dns.Fqdn(zone), soa: soa}
+}
+
+func (s *fakeServer) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	m := new(dns.Msg)
+	m.SetReply(req)
+
+	if req.IsTsig() == nil || w.TsigStatus() != nil {
+		m.Rcode = dns.RcodeNotAuth
+	} else if len(req.Question) != 1 || !strings.EqualFold(req.Question[0].Name, s.zone) {
+		m.Rcode = dns.RcodeNotZone
+	} else if req.Opcode == dns.OpcodeUpdate {
+		m.Rcode = s.update(req)
+	} else if req.Question[0].Qtype == dns.TypeAXFR {
+		m.Answer = append(m.Answer, s.soa)
+		m.Answer = append(m.Answer, s.records...)
+		m.Answer = append(m.Answer, s.soa)
+	} else {
+		m.Rcode = dns.RcodeNotImplemented
+	}
+
+	if req.IsTsig() != nil {
+		m.SetTsig(testTSIGKey, dns.HmacSHA256, 300, int64(req.IsTsig().TimeSigned))
+	}
+	w.WriteMsg(m)
+}
+
+// update applies a dynamic update, following RFC 2136 section 3
+func (s *fakeServer) update(req *dns.Msg) int {
+	for _, prereq := range req.Answer {
+		hdr := prereq.Header()
+		if hdr.Class == dns.ClassNONE && s.rrsetExists(hdr.Name, hdr.Rrtype) {
+			return dns.RcodeYXRrset
+		}
+	}
+
+	for _, rr := range req.Ns {
+		hdr := rr.Header()
+		switch hdr.Class {
+		case dns.ClassANY:
+			s.removeMatching(func(r dns.RR) bool {
+				return sameRRset(r, hdr.Name, hdr.Rrtype)
+			})
+		case dns.ClassNONE:
+			value := rrdata(rr)
+			s.removeMatching(func(r dns.RR) bool {
+				return sameRRset(r, hdr.Name, hdr.Rrtype) && rrdata(r) == value
+			})
+		default:
+			// All the records in an RRset have the same TTL
+			for _, r := range s.records {
+				if sameRRset(r, hdr.Name, hdr.Rrtype) {
+					r.Header().Ttl = hdr.Ttl
+				}
+			}
+			s.records = append(s.records, rr)
+		}
+	}
+	return dns.RcodeSuccess
+}
+
+func (s *fakeServer) rrsetExists(name string, rrtype uint16) bool {
+	for _, r := range s.records {
+		if sameRRset(r, name, rrtype) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *fakeServer) removeMatching(match func(dns.RR) bool) {
+	var kept []dns.RR
+	for _, r := range s.records {
+		if !match(r) {
+			kept = append(kept, r)
+		}
+	}
+	s.records = kept
+}
+
+func sameRRset(rr dns.RR, name string, rrtype uint16) bool {
+	return strings.EqualFold(rr.Header().Name, name) && rr.Header().Rrtype == rrtype
+}
+
+// startFakeServer starts the fake server listening on localhost, and returns its address
+func startFakeServer(handler *fakeServer) (string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+
+	started := make(chan struct{})
+	server := &dns.Server{
+		Listener:          l,
+		Handler:           handler,
+		TsigSecret:        map[string]string{testTSIGKey: testTSIGSecret},
+		NotifyStartedFunc: func() { close(started) },
+	}
+	go server.ActivateAndServe()
+	<-started
+
+	return l.Addr().String(), nil
+}
+
+func newTestInterface() (dnsprovider.Interface, error) {
+	addr, err := startFakeServer(newFakeServer(testZone))
+	if err != nil {
+		return nil, err
+	}
+
+	config := fmt.Sprintf("[global]\nserver = %s\nzone = %s\ntsig-key-name = %s\ntsig-secret = %s\n", addr, testZone, testTSIGKey, testTSIGSecret)
+	return dnsprovider.GetDnsProvider(ProviderName, strings.NewReader(config))
+}
+
+var intf dnsprovider.Interface
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	var err error
+	intf, err = newTestInterface()
+	if err != nil {
+		fmt.Printf("Error creating interface: %v", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// firstZone returns the first zone for the configured dns provider account/project,
+// or fails if it can't be found
+func firstZone(t *testing.T) dnsprovider.Zone {
+	zonesInterface, supported := intf.Zones()
+	if !supported {
+		t.Fatalf("Zones interface not supported by interface %v", intf)
+	}
+	zones, err := zonesInterface.List()
+	if err != nil {
+		t.Fatalf("Failed to list zones: %v", err)
+	}
+	if len(zones) == 0 {
+		t.Fatalf("No zones were found")
+	}
+	return zones[0]
+}
+
+func rrs(t *testing.T, zone dnsprovider.Zone) dnsprovider.ResourceRecordSets {
+	rrsets, supported := zone.ResourceRecordSets()
+	if !supported {
+		t.Fatalf("ResourceRecordSets interface not supported by zone %v", zone)
+	}
+	return rrsets
+}
+
+func TestZonesList(t *testing.T) {
+	zone := firstZone(t)
+	if zone.Name() != testZone || zone.ID() != testZone {
+		t.Errorf("unexpected zone name=%q id=%q", zone.Name(), zone.ID())
+	}
+}
+
+func TestZoneAddNotSupported(t *testing.T) {
+	zones, _ := intf.Zones()
+	zone, err := zones.New("other.com")
+	if err != nil {
+		t.Fatalf("error building zone: %v", err)
+	}
+	if _, err := zones.Add(zone); err == nil {
+		t.Errorf("expected error adding zone")
+	}
+}
+
+func TestResourceRecordSetsList(t *testing.T) {
+	rrsets, err := rrs(t, firstZone(t)).List()
+	if err != nil {
+		t.Fatalf("Failed to list recordsets: %v", err)
+	}
+	if len(rrsets) != 1 || rrsets[0].Type() != rrstype.RrsType("SOA") || rrsets[0].Name() != testZone {
+		t.Errorf("expected only the SOA record, got %v", rrsets)
+	}
+}
+
+func TestResourceRecordSetsAddDuplicateFail(t *testing.T) {
+	sets := rrs(t, firstZone(t))
+	rrset := sets.New("www.test.com", []string{"10.0.0.1", "10.0.0.2"}, 60, rrstype.A)
+	if err := sets.StartChangeset().Add(rrset).Apply(); err != nil {
+		t.Fatalf("Failed to add recordset: %v", err)
+	}
+	defer sets.StartChangeset().Remove(rrset).Apply()
+
+	if err := sets.StartChangeset().Add(rrset).Apply(); err == nil {
+		t.Errorf("expected error adding a duplicate recordset")
+	}
+
+	found, err := sets.Get("www.test.com")
+	if err != nil {
+		t.Fatalf("Failed to get recordset: %v", err)
+	}
+	if len(found) != 1 || len(found[0].Rrdatas()) != 2 || found[0].Ttl() != 60 {
+		t.Errorf("unexpected recordsets %v", found)
+	}
+}
+
+func TestResourceRecordSetsUpsert(t *testing.T) {
+	sets := rrs(t, firstZone(t))
+	rrset := sets.New("txt.test.com", []string{"\"heritage=dns-controller\""}, 60, rrstype.TXT)
+	if err := sets.StartChangeset().Upsert(rrset).Apply(); err != nil {
+		t.Fatalf("Failed to upsert recordset: %v", err)
+	}
+	defer sets.StartChangeset().Remove(rrset).Apply()
+
+	rrset = sets.New("txt.test.com", []string{"\"heritage=dns-controller\""}, 120, rrstype.TXT)
+	if err := sets.StartChangeset().Upsert(rrset).Apply(); err != nil {
+		t.Fatalf("Failed to upsert recordset: %v", err)
+	}
+
+	found, err := sets.Get("txt.test.com")
+	if err != nil {
+		t.Fatalf("Failed to get recordset: %v", err)
+	}
+	if len(found) != 1 || found[0].Ttl() != 120 || found[0].Rrdatas()[0] != "\"heritage=dns-controller\"" {
+		t.Errorf("unexpected recordsets %v", found)
+	}
+}
+
+func TestUnsignedRequestRejected(t *testing.T) {
+	i := intf.(*Interface)
+	unsigned := &Interface{client: &client{server: i.client.server, timeout: i.client.timeout}}
+	unsigned.zones = []*Zone{{name: testZone, zones: &Zones{intf: unsigned}}}
+
+	zones, _ := unsigned.Zones()
+	list, _ := zones.List()
+	if _, err := rrs(t, list[0]).List(); err == nil {
+		t.Errorf("expected unsigned zone transfer to be rejected")
+	}
+}
+
+func TestResourceRecordSetsReplace(t *testing.T) {
+	tests.CommonTestResourceRecordSetsReplace(t, firstZone(t))
+}
+
+func TestResourceRecordSetsReplaceAll(t *testing.T) {
+	tests.CommonTestResourceRecordSetsReplaceAll(t, firstZone(t))
+}
+
+func TestResourceRecordSetsDifferentTypes(t *testing.T) {
+	tests.CommonTestResourceRecordSetsDifferentTypes(t, firstZone(t))
+}
+
+func TestTSIGAlgorithm(t *testing.T) {
+	grid := map[string]string{
+		"":                     dns.HmacSHA256,
+		"hmac-sha256":          dns.HmacSHA256,
+		"HMAC-SHA512.":         dns.HmacSHA512,
+		"hmac-md5":             dns.HmacMD5,
+		"hmac-sha1":            dns.HmacSHA1,
+		"hmac-md5.sig-alg.reg": "",
+	}
+	for name, expected := range grid {
+		actual, err := tsigAlgorithm(name)
+		if expected == "" {
+			if err == nil {
+				t.Errorf("expected error for %q", name)
+			}
+			continue
+		}
+		if err != nil || actual != expected {
+			t.Errorf("tsigAlgorithm(%q) = %q, %v; expected %q", name, actual, err, expected)
+		}
+	}
+}
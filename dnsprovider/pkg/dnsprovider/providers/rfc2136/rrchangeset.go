@@ -0,0 +1,131 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rfc2136
+
+import (
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/miekg/dns"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+)
+
+// ResourceRecordChangeset is the RFC 2136 implementation of dnsprovider.ResourceRecordChangeset.
+// The whole changeset is sent as a single dynamic update, so it is applied atomically by the server.
+type ResourceRecordChangeset struct {
+	rrsets *ResourceRecordSets
+
+	additions []dnsprovider.ResourceRecordSet
+	removals  []dnsprovider.ResourceRecordSet
+	upserts   []dnsprovider.ResourceRecordSet
+}
+
+var _ dnsprovider.ResourceRecordChangeset = &ResourceRecordChangeset{}
+
+// Add adds the creation of a ResourceRecordSet in the Zone to the changeset
+func (c *ResourceRecordChangeset) Add(rrset dnsprovider.ResourceRecordSet) dnsprovider.ResourceRecordChangeset {
+	c.additions = append(c.additions, rrset)
+	return c
+}
+
+// Remove adds the removal of a ResourceRecordSet in the Zone to the changeset
+func (c *ResourceRecordChangeset) Remove(rrset dnsprovider.ResourceRecordSet) dnsprovider.ResourceRecordChangeset {
+	c.removals = append(c.removals, rrset)
+	return c
+}
+
+// Upsert adds an "create or update" operation for the ResourceRecordSet to the changeset
+func (c *ResourceRecordChangeset) Upsert(rrset dnsprovider.ResourceRecordSet) dnsprovider.ResourceRecordChangeset {
+	c.upserts = append(c.upserts, rrset)
+	return c
+}
+
+// Apply sends the changeset to the DNS server
+func (c *ResourceRecordChangeset) Apply() error {
+	if c.IsEmpty() {
+		return nil
+	}
+
+	m := new(dns.Msg)
+	m.SetUpdate(dns.Fqdn(c.rrsets.zone.name))
+
+	removed := make(map[string]bool)
+	for _, rrset := range c.removals {
+		records, err := toRecords(rrset)
+		if err != nil {
+			return err
+		}
+		glog.V(2).Infof("Removing %s %s", rrset.Name(), rrset.Type())
+		m.Remove(records)
+		removed[key(rrset)] = true
+	}
+
+	for _, rrset := range c.additions {
+		records, err := toRecords(rrset)
+		if err != nil {
+			return err
+		}
+		// Adding a record set that already exists is an error, as for the other providers,
+		// unless it is being replaced in this changeset
+		if !removed[key(rrset)] {
+			m.RRsetNotUsed(records)
+		}
+		glog.V(2).Infof("Adding %s %s", rrset.Name(), rrset.Type())
+		m.Insert(records)
+	}
+
+	for _, rrset := range c.upserts {
+		records, err := toRecords(rrset)
+		if err != nil {
+			return err
+		}
+		glog.V(2).Infof("Upserting %s %s", rrset.Name(), rrset.Type())
+		m.RemoveRRset(records)
+		m.Insert(records)
+	}
+
+	return c.rrsets.zone.client().update(m)
+}
+
+// IsEmpty returns true if there are no changes in the changeset
+func (c *ResourceRecordChangeset) IsEmpty() bool {
+	return len(c.additions) == 0 && len(c.removals) == 0 && len(c.upserts) == 0
+}
+
+// ResourceRecordSets returns the parent ResourceRecordSets
+func (c *ResourceRecordChangeset) ResourceRecordSets() dnsprovider.ResourceRecordSets {
+	return c.rrsets
+}
+
+// toRecords returns the DNS resource records for a record set, which may have been built by another provider
+func toRecords(rrset dnsprovider.ResourceRecordSet) ([]dns.RR, error) {
+	r, ok := rrset.(*ResourceRecordSet)
+	if !ok {
+		r = &ResourceRecordSet{
+			name:    rrset.Name(),
+			rrdatas: rrset.Rrdatas(),
+			ttl:     rrset.Ttl(),
+			rrsType: rrset.Type(),
+		}
+	}
+	return r.records()
+}
+
+func key(rrset dnsprovider.ResourceRecordSet) string {
+	return strings.ToLower(normalizeName(rrset.Name())) + "/" + string(rrset.Type())
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rfc2136
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+)
+
+// ResourceRecordSets is the RFC 2136 implementation of dnsprovider.ResourceRecordSets
+type ResourceRecordSets struct {
+	zone *Zone
+}
+
+var _ dnsprovider.ResourceRecordSets = &ResourceRecordSets{}
+
+// List returns all the records in the zone, read with a zone transfer
+func (r *ResourceRecordSets) List() ([]dnsprovider.ResourceRecordSet, error) {
+	records, err := r.zone.client().transfer(r.zone.name)
+	if err != nil {
+		return nil, err
+	}
+
+	// An AXFR starts and ends with the SOA record
+	if len(records) > 1 && records[len(records)-1].Header().Rrtype == dns.TypeSOA {
+		records = records[:len(records)-1]
+	}
+
+	var list []dnsprovider.ResourceRecordSet
+	sets := make(map[string]*ResourceRecordSet)
+	for _, rr := range records {
+		hdr := rr.Header()
+		name := normalizeName(hdr.Name)
+		t := rrstype.RrsType(dns.TypeToString[hdr.Rrtype])
+
+		key := strings.ToLower(name) + "/" + string(t)
+		rrset := sets[key]
+		if rrset == nil {
+			rrset = &ResourceRecordSet{name: name, ttl: int64(hdr.Ttl), rrsType: t, rrsets: r}
+			sets[key] = rrset
+			list = append(list, rrset)
+		}
+		rrset.rrdatas = append(rrset.rrdatas, rrdata(rr))
+	}
+	return list, nil
+}
+
+// Get returns the records with the specified name
+func (r *ResourceRecordSets) Get(name string) ([]dnsprovider.ResourceRecordSet, error) {
+	records, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []dnsprovider.ResourceRecordSet
+	for _, record := range records {
+		if strings.EqualFold(record.Name(), normalizeName(name)) {
+			matches = append(matches, record)
+		}
+	}
+	return matches, nil
+}
+
+// StartChangeset starts a dynamic update of the zone
+func (r *ResourceRecordSets) StartChangeset() dnsprovider.ResourceRecordChangeset {
+	return &ResourceRecordChangeset{rrsets: r}
+}
+
+// New builds a ResourceRecordSet; it is not created until it is added in a changeset
+func (r *ResourceRecordSets) New(name string, rrdatas []string, ttl int64, rrsType rrstype.RrsType) dnsprovider.ResourceRecordSet {
+	return &ResourceRecordSet{
+		name:    name,
+		rrdatas: rrdatas,
+		ttl:     ttl,
+		rrsType: rrsType,
+		rrsets:  r,
+	}
+}
+
+// Zone returns the parent zone
+func (r *ResourceRecordSets) Zone() dnsprovider.Zone {
+	return r.zone
+}
+
+// ResourceRecordSet is the RFC 2136 implementation of dnsprovider.ResourceRecordSet
+type ResourceRecordSet struct {
+	name    string
+	rrdatas []string
+	ttl     int64
+	rrsType rrstype.RrsType
+	rrsets  *ResourceRecordSets
+}
+
+var _ dnsprovider.ResourceRecordSet = &ResourceRecordSet{}
+
+// Name returns the name of the record set
+func (r *ResourceRecordSet) Name() string {
+	return r.name
+}
+
+// Rrdatas returns the values of the record set
+func (r *ResourceRecordSet) Rrdatas() []string {
+	return r.rrdatas
+}
+
+// Ttl returns the TTL of the record set, in seconds
+func (r *ResourceRecordSet) Ttl() int64 {
+	return r.ttl
+}
+
+// Type returns the type of the record set
+func (r *ResourceRecordSet) Type() rrstype.RrsType {
+	return r.rrsType
+}
+
+// records returns the DNS resource records of the record set
+func (r *ResourceRecordSet) records() ([]dns.RR, error) {
+	var records []dns.RR
+	for _, value := range r.rrdatas {
+		s := fmt.Sprintf("%s %d IN %s %s", dns.Fqdn(r.name), r.ttl, r.rrsType, value)
+		rr, err := dns.NewRR(s)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing record %q: %v", s, err)
+		}
+		records = append(records, rr)
+	}
+	return records, nil
+}
+
+// rrdata returns the value of a resource record, in presentation format
+func rrdata(rr dns.RR) string {
+	return strings.TrimPrefix(rr.String(), rr.Header().String())
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rfc2136
+
+import (
+	"fmt"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+)
+
+// Interface is the RFC 2136 implementation of dnsprovider.Interface
+type Interface struct {
+	client *client
+	zones  []*Zone
+}
+
+var _ dnsprovider.Interface = &Interface{}
+
+// Zones returns the configured zones
+func (i *Interface) Zones() (dnsprovider.Zones, bool) {
+	return &Zones{intf: i}, true
+}
+
+// Zones is the RFC 2136 implementation of dnsprovider.Zones.  The zones are configured, not discovered,
+// because the DNS protocol has no way to list the zones of a server.
+type Zones struct {
+	intf *Interface
+}
+
+var _ dnsprovider.Zones = &Zones{}
+
+// List returns the configured zones
+func (z *Zones) List() ([]dnsprovider.Zone, error) {
+	var zones []dnsprovider.Zone
+	for _, zone := range z.intf.zones {
+		zones = append(zones, zone)
+	}
+	return zones, nil
+}
+
+// Add is not supported; zones must be created on the DNS server
+func (z *Zones) Add(zone dnsprovider.Zone) (dnsprovider.Zone, error) {
+	return nil, fmt.Errorf("zones cannot be created with the %s DNS provider", ProviderName)
+}
+
+// Remove is not supported; zones must be removed on the DNS server
+func (z *Zones) Remove(zone dnsprovider.Zone) error {
+	return fmt.Errorf("zones cannot be removed with the %s DNS provider", ProviderName)
+}
+
+// New returns a Zone for the name, which must exist on the DNS server
+func (z *Zones) New(name string) (dnsprovider.Zone, error) {
+	return &Zone{name: normalizeName(name), zones: z}, nil
+}
+
+// Zone is the RFC 2136 implementation of dnsprovider.Zone
+type Zone struct {
+	name  string
+	zones *Zones
+}
+
+var _ dnsprovider.Zone = &Zone{}
+
+// Name returns the name of the zone, e.g. "example.com"
+func (z *Zone) Name() string {
+	return z.name
+}
+
+// ID returns the name of the zone; zones have no other identifier
+func (z *Zone) ID() string {
+	return z.name
+}
+
+// ResourceRecordSets returns the records of the zone
+func (z *Zone) ResourceRecordSets() (dnsprovider.ResourceRecordSets, bool) {
+	return &ResourceRecordSets{zone: z}, true
+}
+
+func (z *Zone) client() *client {
+	return z.zones.intf.client
+}
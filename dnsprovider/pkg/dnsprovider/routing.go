@@ -0,0 +1,190 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dnsprovider
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
+)
+
+// Routing policies are an optional feature: a provider which supports them implements
+// RoutingPolicyResourceRecordSets on its ResourceRecordSets, and RoutingPolicyResourceRecordSet
+// on the record sets it returns.  Several record sets with the same name and type can then exist
+// in a zone, distinguished by their SetIdentifier.
+
+const (
+	// FailoverPrimary is the record set which is returned while it is healthy
+	FailoverPrimary = "PRIMARY"
+	// FailoverSecondary is the record set which is returned when the primary is unhealthy
+	FailoverSecondary = "SECONDARY"
+)
+
+// RoutingPolicy controls which of the record sets with the same name and type is returned to a query.
+// The zero value is a simple record set, with no routing policy.
+type RoutingPolicy struct {
+	// SetIdentifier distinguishes the record sets with the same name and type; it is required for every routing policy
+	SetIdentifier string
+
+	// Weighted is true for weighted routing, where record sets are returned in proportion to their Weight
+	Weighted bool
+	// Weight is the relative weight of the record set, if Weighted
+	Weight int64
+
+	// Failover is FailoverPrimary or FailoverSecondary for failover routing
+	Failover string
+
+	// Continent, Country and Subdivision select the location of the client for geolocation routing.
+	// Country "*" is the default location, used for clients which match no other record set.
+	Continent   string
+	Country     string
+	Subdivision string
+
+	// HealthCheckID is the provider id of a health check which determines whether the record set is healthy
+	HealthCheckID string
+}
+
+// RoutingCapabilities describes the routing policies supported by a provider
+type RoutingCapabilities struct {
+	Weighted     bool
+	Failover     bool
+	Geolocation  bool
+	HealthChecks bool
+}
+
+// RoutingPolicyResourceRecordSets is implemented by ResourceRecordSets which support routing policies
+type RoutingPolicyResourceRecordSets interface {
+	ResourceRecordSets
+
+	// RoutingCapabilities returns the routing policies supported by the provider
+	RoutingCapabilities() RoutingCapabilities
+	// NewWithRoutingPolicy allocates a new ResourceRecordSet with a routing policy, which can be passed to a ResourceRecordChangeset
+	NewWithRoutingPolicy(name string, rrdatas []string, ttl int64, rrstype rrstype.RrsType, policy RoutingPolicy) ResourceRecordSet
+}
+
+// RoutingPolicyResourceRecordSet is implemented by ResourceRecordSets which can have a routing policy
+type RoutingPolicyResourceRecordSet interface {
+	ResourceRecordSet
+
+	// RoutingPolicy returns the routing policy of the record set; the zero value if it is a simple record set
+	RoutingPolicy() RoutingPolicy
+}
+
+// IsSimple returns true if the policy is the zero value, i.e. a simple record set
+func (p *RoutingPolicy) IsSimple() bool {
+	return *p == RoutingPolicy{}
+}
+
+// IsGeolocation returns true if the policy is geolocation routing
+func (p *RoutingPolicy) IsGeolocation() bool {
+	return p.Continent != "" || p.Country != "" || p.Subdivision != ""
+}
+
+// Validate checks that the policy is well-formed: exactly one kind of routing, with a set identifier
+func (p *RoutingPolicy) Validate() error {
+	if p.IsSimple() {
+		return nil
+	}
+
+	var kinds []string
+	if p.Weighted {
+		kinds = append(kinds, "weighted")
+		if p.Weight < 0 || p.Weight > 255 {
+			return fmt.Errorf("weight %d must be between 0 and 255", p.Weight)
+		}
+	} else if p.Weight != 0 {
+		return fmt.Errorf("weight can only be set for weighted routing")
+	}
+	if p.Failover != "" {
+		kinds = append(kinds, "failover")
+		if p.Failover != FailoverPrimary && p.Failover != FailoverSecondary {
+			return fmt.Errorf("failover must be %s or %s, was %q", FailoverPrimary, FailoverSecondary, p.Failover)
+		}
+	}
+	if p.IsGeolocation() {
+		kinds = append(kinds, "geolocation")
+		if p.Continent != "" && (p.Country != "" || p.Subdivision != "") {
+			return fmt.Errorf("geolocation routing can select a continent or a country, not both")
+		}
+		if p.Subdivision != "" && p.Country == "" {
+			return fmt.Errorf("geolocation routing by subdivision requires a country")
+		}
+	}
+
+	if len(kinds) == 0 {
+		if p.HealthCheckID != "" {
+			return fmt.Errorf("a health check can only be used with a routing policy")
+		}
+		return fmt.Errorf("set identifier %q can only be used with a routing policy", p.SetIdentifier)
+	}
+	if len(kinds) > 1 {
+		return fmt.Errorf("only one routing policy can be used, found %s", strings.Join(kinds, " and "))
+	}
+	if p.SetIdentifier == "" {
+		return fmt.Errorf("%s routing requires a set identifier", kinds[0])
+	}
+	return nil
+}
+
+// Supports returns an error describing the first part of policy which is not supported, or nil if it is supported
+func (c RoutingCapabilities) Supports(policy RoutingPolicy) error {
+	if policy.Weighted && !c.Weighted {
+		return fmt.Errorf("weighted routing is not supported by the DNS provider")
+	}
+	if policy.Failover != "" && !c.Failover {
+		return fmt.Errorf("failover routing is not supported by the DNS provider")
+	}
+	if policy.IsGeolocation() && !c.Geolocation {
+		return fmt.Errorf("geolocation routing is not supported by the DNS provider")
+	}
+	if policy.HealthCheckID != "" && !c.HealthChecks {
+		return fmt.Errorf("health checks are not supported by the DNS provider")
+	}
+	return nil
+}
+
+// GetRoutingCapabilities returns the routing policies supported by rrsets; none if it does not implement RoutingPolicyResourceRecordSets
+func GetRoutingCapabilities(rrsets ResourceRecordSets) RoutingCapabilities {
+	if r, ok := rrsets.(RoutingPolicyResourceRecordSets); ok {
+		return r.RoutingCapabilities()
+	}
+	return RoutingCapabilities{}
+}
+
+// GetRoutingPolicy returns the routing policy of rrset, or the zero value if it is a simple record set
+func GetRoutingPolicy(rrset ResourceRecordSet) RoutingPolicy {
+	if r, ok := rrset.(RoutingPolicyResourceRecordSet); ok {
+		return r.RoutingPolicy()
+	}
+	return RoutingPolicy{}
+}
+
+// NewWithRoutingPolicy allocates a new ResourceRecordSet with a routing policy.  A simple record set is allocated with
+// ResourceRecordSets.New, so it works with every provider; for other policies rrsets must support the policy.
+func NewWithRoutingPolicy(rrsets ResourceRecordSets, name string, rrdatas []string, ttl int64, rrstype rrstype.RrsType, policy RoutingPolicy) (ResourceRecordSet, error) {
+	if policy.IsSimple() {
+		return rrsets.New(name, rrdatas, ttl, rrstype), nil
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	if err := GetRoutingCapabilities(rrsets).Supports(policy); err != nil {
+		return nil, err
+	}
+	return rrsets.(RoutingPolicyResourceRecordSets).NewWithRoutingPolicy(name, rrdatas, ttl, rrstype, policy), nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dnsprovider
+
+import (
+	"strings"
+	"testing"
+)
+
+type routedRecord struct {
+	record
+	policy RoutingPolicy
+}
+
+func (r routedRecord) RoutingPolicy() RoutingPolicy {
+	return r.policy
+}
+
+func TestRoutingPolicyValidate(t *testing.T) {
+	cases := []struct {
+		policy   RoutingPolicy
+		expected string
+	}{
+		{RoutingPolicy{}, ""},
+		{RoutingPolicy{SetIdentifier: "blue", Weighted: true, Weight: 10}, ""},
+		{RoutingPolicy{SetIdentifier: "blue", Weighted: true}, ""},
+		{RoutingPolicy{SetIdentifier: "primary", Failover: FailoverPrimary, HealthCheckID: "abc"}, ""},
+		{RoutingPolicy{SetIdentifier: "eu", Continent: "EU"}, ""},
+		{RoutingPolicy{SetIdentifier: "wa", Country: "US", Subdivision: "WA"}, ""},
+		{RoutingPolicy{SetIdentifier: "default", Country: "*"}, ""},
+		{RoutingPolicy{Weighted: true, Weight: 10}, "weighted routing requires a set identifier"},
+		{RoutingPolicy{SetIdentifier: "blue", Weighted: true, Weight: 256}, "weight 256 must be between 0 and 255"},
+		{RoutingPolicy{SetIdentifier: "blue", Weight: 10}, "weight can only be set for weighted routing"},
+		{RoutingPolicy{SetIdentifier: "blue"}, "set identifier \"blue\" can only be used with a routing policy"},
+		{RoutingPolicy{HealthCheckID: "abc"}, "a health check can only be used with a routing policy"},
+		{RoutingPolicy{SetIdentifier: "x", Failover: "TERTIARY"}, "failover must be PRIMARY or SECONDARY"},
+		{RoutingPolicy{SetIdentifier: "x", Weighted: true, Failover: FailoverPrimary}, "only one routing policy can be used, found weighted and failover"},
+		{RoutingPolicy{SetIdentifier: "x", Continent: "EU", Country: "FR"}, "a continent or a country, not both"},
+		{RoutingPolicy{SetIdentifier: "x", Subdivision: "WA"}, "by subdivision requires a country"},
+	}
+
+	for _, c := range cases {
+		err := c.policy.Validate()
+		if c.expected == "" {
+			if err != nil {
+				t.Errorf("unexpected error validating %+v: %v", c.policy, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("expected error containing %q validating %+v, got %v", c.expected, c.policy, err)
+		}
+	}
+}
+
+func TestRoutingCapabilitiesSupports(t *testing.T) {
+	weighted := RoutingPolicy{SetIdentifier: "blue", Weighted: true, Weight: 1}
+	failover := RoutingPolicy{SetIdentifier: "primary", Failover: FailoverPrimary, HealthCheckID: "abc"}
+
+	if err := (RoutingCapabilities{}).Supports(RoutingPolicy{}); err != nil {
+		t.Errorf("simple record sets should always be supported: %v", err)
+	}
+	if err := (RoutingCapabilities{}).Supports(weighted); err == nil {
+		t.Errorf("expected weighted routing to be rejected without the capability")
+	}
+	if err := (RoutingCapabilities{Weighted: true}).Supports(weighted); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := (RoutingCapabilities{Failover: true}).Supports(failover); err == nil || !strings.Contains(err.Error(), "health checks") {
+		t.Errorf("expected health checks to be rejected, got %v", err)
+	}
+	if err := (RoutingCapabilities{Failover: true, HealthChecks: true}).Supports(failover); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestEquivalentRoutingPolicy(t *testing.T) {
+	simple := record{"foo", []string{"1.2.3.4"}, 180, "A"}
+	blue := routedRecord{simple, RoutingPolicy{SetIdentifier: "blue", Weighted: true, Weight: 1}}
+	green := routedRecord{simple, RoutingPolicy{SetIdentifier: "green", Weighted: true, Weight: 1}}
+
+	if ResourceRecordSetsEquivalent(simple, blue) {
+		t.Errorf("simple and weighted record sets should not be equivalent")
+	}
+	if ResourceRecordSetsEquivalent(blue, green) {
+		t.Errorf("record sets with different set identifiers should not be equivalent")
+	}
+	if !ResourceRecordSetsEquivalent(blue, blue) {
+		t.Errorf("identical record sets should be equivalent")
+	}
+	if !ResourceRecordSetsEquivalent(simple, routedRecord{record: simple}) {
+		t.Errorf("a record set with the zero routing policy should be equivalent to a simple record set")
+	}
+}
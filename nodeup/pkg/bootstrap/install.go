@@ -111,6 +111,32 @@ func (i *Installation) buildSystemdJob() *nodetasks.Service {
 	manifest.Set("Unit", "Description", "Run kops bootstrap (nodeup)")
 	manifest.Set("Unit", "Documentation", "https://github.com/kubernetes/kops")
 
+	if env := ServiceEnvironment(); env != "" {
+		manifest.Set("Service", "Environment", env)
+	}
+
+	manifest.Set("Service", "EnvironmentFile", "/etc/environment")
+	manifest.Set("Service", "ExecStart", command)
+	manifest.Set("Service", "Type", "oneshot")
+
+	manifest.Set("Install", "WantedBy", "multi-user.target")
+
+	manifestString := manifest.Render()
+	glog.V(8).Infof("Built service manifest %q\n%s", serviceName, manifestString)
+
+	service := &nodetasks.Service{
+		Name:       serviceName,
+		Definition: fi.String(manifestString),
+	}
+
+	service.InitDefaults()
+
+	return service
+}
+
+// ServiceEnvironment returns the systemd Environment for services running nodeup, passing through the
+// cloud credentials and settings nodeup needs to read the state store
+func ServiceEnvironment() string {
 	var buffer bytes.Buffer
 
 	if os.Getenv("AWS_REGION") != "" {
@@ -141,25 +167,5 @@ func (i *Installation) buildSystemdJob() *nodetasks.Service {
 		buffer.WriteString("\" ")
 	}
 
-	if buffer.String() != "" {
-		manifest.Set("Service", "Environment", buffer.String())
-	}
-
-	manifest.Set("Service", "EnvironmentFile", "/etc/environment")
-	manifest.Set("Service", "ExecStart", command)
-	manifest.Set("Service", "Type", "oneshot")
-
-	manifest.Set("Install", "WantedBy", "multi-user.target")
-
-	manifestString := manifest.Render()
-	glog.V(8).Infof("Built service manifest %q\n%s", serviceName, manifestString)
-
-	service := &nodetasks.Service{
-		Name:       serviceName,
-		Definition: fi.String(manifestString),
-	}
-
-	service.InitDefaults()
-
-	return service
+	return buffer.String()
 }
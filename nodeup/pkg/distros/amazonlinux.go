@@ -0,0 +1,34 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package distros
+
+func init() {
+	Register(&Info{
+		Distribution:    DistributionAmazonLinux2,
+		Family:          FamilyRHEL,
+		PackageManager:  PackageManagerYum,
+		InitSystem:      InitSystemSystemd,
+		DefaultPackages: rhelPackages,
+		// Amazon Linux 2 is compatible with the CentOS 7 docker packages
+		DockerPackagesFrom: DistributionCentos7,
+		DockerPackage:      "docker",
+		PackagesImage:      "amazonlinux:2",
+		Identify: func(r *ReleaseFiles) bool {
+			return r.OSRelease("ID") == "amzn" && r.OSRelease("VERSION_ID") == "2"
+		},
+	})
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package distros
+
+func init() {
+	// Most of ContainerOS is read-only or noexec, so we install into /home/kubernetes and /etc/srv
+	Register(&Info{
+		Distribution: DistributionContainerOS,
+		Family:       FamilyContainerOS,
+		InitSystem:   InitSystemSystemd,
+		BuildTags:    []string{"_containeros"},
+		Paths: Paths{
+			BinDir:        "/home/kubernetes/bin",
+			KubeletPath:   "/home/kubernetes/bin/kubelet",
+			CNIBinDir:     "/home/kubernetes/bin/",
+			SrvKubernetes: "/etc/srv/kubernetes",
+			SrvSshproxy:   "/etc/srv/sshproxy",
+			SSLHostPaths:  []string{"/etc/ssl", "/etc/pki/tls", "/etc/pki/ca-trust", "/usr/share/ca-certificates"},
+		},
+		Identify: func(r *ReleaseFiles) bool {
+			return r.OSRelease("ID") == "cos"
+		},
+	})
+}
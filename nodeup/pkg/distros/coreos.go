@@ -0,0 +1,39 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package distros
+
+// coreOSPaths are the paths on CoreOS and its derivatives, where /usr is read-only
+var coreOSPaths = Paths{
+	BinDir:      "/opt/bin",
+	KubeletPath: "/opt/kubernetes/bin/kubelet",
+	// Because /usr is read-only on CoreOS, we can't have any new directories; docker will try (and fail) to create them
+	// TODO: Just check if the directories exist?
+	SSLHostPaths: []string{"/etc/ssl", "/etc/pki/tls", "/etc/pki/ca-trust", "/usr/share/ca-certificates"},
+}
+
+func init() {
+	Register(&Info{
+		Distribution: DistributionCoreOS,
+		Family:       FamilyCoreOS,
+		InitSystem:   InitSystemSystemd,
+		BuildTags:    []string{"_coreos"},
+		Paths:        coreOSPaths,
+		Identify: func(r *ReleaseFiles) bool {
+			return r.OSRelease("ID") == "coreos"
+		},
+	})
+}
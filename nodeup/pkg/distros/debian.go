@@ -0,0 +1,57 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package distros
+
+import "strings"
+
+// debianPackages are the packages nodeup installs on debian and ubuntu.
+// kubelet needs ebtables (kops #1711) and ethtool (kops #1830)
+var debianPackages = []string{"ebtables", "ethtool"}
+
+func init() {
+	Register(&Info{
+		Distribution:    DistributionJessie,
+		Family:          FamilyDebian,
+		PackageManager:  PackageManagerApt,
+		InitSystem:      InitSystemSystemd,
+		BuildTags:       []string{"_jessie"},
+		DefaultPackages: debianPackages,
+		PackagesImage:   "debian:jessie",
+		KubeconfigUsers: []string{"admin", "root"},
+		Identify:        isDebianVersion("8."),
+	})
+
+	Register(&Info{
+		Distribution:    DistributionDebian9,
+		Family:          FamilyDebian,
+		PackageManager:  PackageManagerApt,
+		InitSystem:      InitSystemSystemd,
+		DefaultPackages: debianPackages,
+		DockerPackage:   "docker.io",
+		PackagesImage:   "debian:stretch",
+		KubeconfigUsers: []string{"admin", "root"},
+		Identify:        isDebianVersion("9."),
+	})
+}
+
+// isDebianVersion matches debian releases by /etc/debian_version.
+// (ubuntu also has /etc/debian_version, but it holds the name of a debian release e.g. stretch/sid)
+func isDebianVersion(prefix string) func(r *ReleaseFiles) bool {
+	return func(r *ReleaseFiles) bool {
+		return strings.HasPrefix(strings.TrimSpace(r.Read("etc/debian_version")), prefix)
+	}
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package distros
 
 import (
+	"fmt"
+
 	"github.com/golang/glog"
 	"k8s.io/kops/upup/pkg/fi/nodeup/tags"
 )
@@ -24,43 +26,72 @@ import (
 type Distribution string
 
 var (
-	DistributionJessie      Distribution = "jessie"
-	DistributionDebian9     Distribution = "debian9"
-	DistributionXenial      Distribution = "xenial"
-	DistributionRhel7       Distribution = "rhel7"
-	DistributionCentos7     Distribution = "centos7"
-	DistributionCoreOS      Distribution = "coreos"
-	DistributionContainerOS Distribution = "containeros"
+	DistributionJessie       Distribution = "jessie"
+	DistributionDebian9      Distribution = "debian9"
+	DistributionXenial       Distribution = "xenial"
+	DistributionBionic       Distribution = "bionic"
+	DistributionRhel7        Distribution = "rhel7"
+	DistributionCentos7      Distribution = "centos7"
+	DistributionAmazonLinux2 Distribution = "amazonlinux2"
+	DistributionCoreOS       Distribution = "coreos"
+	DistributionFlatcar      Distribution = "flatcar"
+	DistributionContainerOS  Distribution = "containeros"
 )
 
-func (d Distribution) BuildTags() []string {
-	var t []string
-
-	switch d {
-	case DistributionJessie:
-		t = []string{"_jessie"}
-	case DistributionDebian9:
-		t = []string{} // trying to move away from tags
-	case DistributionXenial:
-		t = []string{"_xenial"}
-	case DistributionCentos7:
-		t = []string{"_centos7"}
-	case DistributionRhel7:
-		t = []string{"_rhel7"}
-	case DistributionCoreOS:
-		t = []string{"_coreos"}
-	case DistributionContainerOS:
-		t = []string{"_containeros"}
+// Family is a group of distributions which nodeup configures in the same way
+type Family string
+
+const (
+	FamilyDebian      Family = "debian"
+	FamilyRHEL        Family = "rhel"
+	FamilyCoreOS      Family = "coreos"
+	FamilyContainerOS Family = "containeros"
+)
+
+// tag returns the nodeup tag for the family
+func (f Family) tag() string {
+	switch f {
+	case FamilyDebian:
+		return tags.TagOSFamilyDebian
+	case FamilyRHEL:
+		return tags.TagOSFamilyRHEL
+	case FamilyCoreOS:
+		return tags.TagOSFamilyCoreOS
+	case FamilyContainerOS:
+		return tags.TagOSFamilyContainerOS
 	default:
+		return ""
+	}
+}
+
+// PackageManager is the tool used to install OS packages
+type PackageManager string
+
+const (
+	PackageManagerApt PackageManager = "apt"
+	PackageManagerYum PackageManager = "yum"
+	// PackageManagerNone is used for image-based distributions, where we cannot install packages
+	PackageManagerNone PackageManager = ""
+)
+
+// InitSystem is the init system of the distribution
+type InitSystem string
+
+const (
+	InitSystemSystemd InitSystem = "systemd"
+)
+
+// BuildTags returns the nodeup tags for the distribution
+func (d Distribution) BuildTags() []string {
+	info := registry[d]
+	if info == nil {
 		glog.Fatalf("unknown distribution: %s", d)
 		return nil
 	}
 
-	if d.IsDebianFamily() {
-		t = append(t, tags.TagOSFamilyDebian)
-	}
-	if d.IsRHELFamily() {
-		t = append(t, tags.TagOSFamilyRHEL)
+	t := append([]string{}, info.BuildTags...)
+	if tag := info.Family.tag(); tag != "" {
+		t = append(t, tag)
 	}
 	if d.IsSystemd() {
 		t = append(t, tags.TagSystemd)
@@ -69,46 +100,37 @@ func (d Distribution) BuildTags() []string {
 	return t
 }
 
+// Family returns the family of the distribution
+func (d Distribution) Family() Family {
+	return d.Info().Family
+}
+
+// PackageManager returns the tool used to install OS packages on the distribution
+func (d Distribution) PackageManager() PackageManager {
+	return d.Info().PackageManager
+}
+
+// Paths returns the locations where nodeup installs files on the distribution
+func (d Distribution) Paths() Paths {
+	return d.Info().Paths
+}
+
 func (d Distribution) IsDebianFamily() bool {
-	switch d {
-	case DistributionJessie, DistributionXenial, DistributionDebian9:
-		return true
-	case DistributionCentos7, DistributionRhel7:
-		return false
-	case DistributionCoreOS, DistributionContainerOS:
-		return false
-	default:
-		glog.Fatalf("unknown distribution: %s", d)
-		return false
-	}
+	return d.Family() == FamilyDebian
 }
 
 func (d Distribution) IsRHELFamily() bool {
-	switch d {
-	case DistributionCentos7, DistributionRhel7:
-		return true
-	case DistributionJessie, DistributionXenial, DistributionDebian9:
-		return false
-	case DistributionCoreOS, DistributionContainerOS:
-		return false
-	default:
-		glog.Fatalf("unknown distribution: %s", d)
-		return false
-	}
+	return d.Family() == FamilyRHEL
 }
 
 func (d Distribution) IsSystemd() bool {
-	switch d {
-	case DistributionJessie, DistributionXenial, DistributionDebian9:
-		return true
-	case DistributionCentos7, DistributionRhel7:
-		return true
-	case DistributionCoreOS:
-		return true
-	case DistributionContainerOS:
-		return true
-	default:
-		glog.Fatalf("unknown distribution: %s", d)
-		return false
+	return d.Info().InitSystem == InitSystemSystemd
+}
+
+// ParseDistribution returns the distribution with the specified name, e.g. xenial
+func ParseDistribution(s string) (Distribution, error) {
+	if _, found := registry[Distribution(s)]; found {
+		return Distribution(s), nil
 	}
+	return "", fmt.Errorf("unknown distribution %q", s)
 }
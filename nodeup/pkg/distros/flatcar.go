@@ -0,0 +1,30 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package distros
+
+func init() {
+	// Flatcar Linux is a fork of CoreOS Container Linux, so we configure it in the same way
+	Register(&Info{
+		Distribution: DistributionFlatcar,
+		Family:       FamilyCoreOS,
+		InitSystem:   InitSystemSystemd,
+		Paths:        coreOSPaths,
+		Identify: func(r *ReleaseFiles) bool {
+			return r.OSRelease("ID") == "flatcar"
+		},
+	})
+}
@@ -26,89 +26,114 @@ import (
 	"github.com/golang/glog"
 )
 
-// FindDistribution identifies the distribution on which we are running
-// We will likely remove this when everything is containerized
-func FindDistribution(rootfs string) (Distribution, error) {
-	// Ubuntu has /etc/lsb-release (and /etc/debian_version)
-	lsbRelease, err := ioutil.ReadFile(path.Join(rootfs, "etc/lsb-release"))
-	if err == nil {
-		for _, line := range strings.Split(string(lsbRelease), "\n") {
-			line = strings.TrimSpace(line)
-			if line == "DISTRIB_CODENAME=xenial" {
-				return DistributionXenial, nil
-			}
+// releaseFilePaths are the files which identify the distribution, relative to the root filesystem
+var releaseFilePaths = []string{
+	"etc/lsb-release",
+	"etc/debian_version",
+	"etc/redhat-release",
+	"etc/os-release",
+	"usr/lib/os-release",
+}
+
+// ReleaseFiles reads the files which identify the distribution from a root filesystem
+type ReleaseFiles struct {
+	rootfs   string
+	contents map[string]string
+}
+
+// NewReleaseFiles returns a ReleaseFiles for the root filesystem at rootfs
+func NewReleaseFiles(rootfs string) *ReleaseFiles {
+	return &ReleaseFiles{
+		rootfs:   rootfs,
+		contents: make(map[string]string),
+	}
+}
+
+// Read returns the contents of the file at p (relative to the root filesystem), or "" if it does not exist
+func (r *ReleaseFiles) Read(p string) string {
+	if s, found := r.contents[p]; found {
+		return s
+	}
+
+	b, err := ioutil.ReadFile(path.Join(r.rootfs, p))
+	if err != nil && !os.IsNotExist(err) {
+		glog.Warningf("error reading /%s: %v", p, err)
+	}
+	s := string(b)
+	r.contents[p] = s
+	return s
+}
+
+// Lines returns the lines of the file at p, with whitespace trimmed
+func (r *ReleaseFiles) Lines(p string) []string {
+	var lines []string
+	for _, line := range strings.Split(r.Read(p), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			lines = append(lines, line)
 		}
-	} else if !os.IsNotExist(err) {
-		glog.Warningf("error reading /etc/lsb-release: %v", err)
 	}
+	return lines
+}
 
-	// Debian has /etc/debian_version
-	debianVersionBytes, err := ioutil.ReadFile(path.Join(rootfs, "etc/debian_version"))
-	if err == nil {
-		debianVersion := strings.TrimSpace(string(debianVersionBytes))
-		if strings.HasPrefix(debianVersion, "8.") {
-			return DistributionJessie, nil
-		} else if strings.HasPrefix(debianVersion, "9.") {
-			return DistributionDebian9, nil
-		} else {
-			return "", fmt.Errorf("unhandled debian version %q", debianVersion)
+// HasLine returns true if the file at p has a line equal to s
+func (r *ReleaseFiles) HasLine(p string, s string) bool {
+	for _, line := range r.Lines(p) {
+		if line == s {
+			return true
 		}
-	} else if !os.IsNotExist(err) {
-		glog.Warningf("error reading /etc/debian_version: %v", err)
 	}
+	return false
+}
 
-	// Redhat has /etc/redhat-release
-	// Centos has /etc/centos-release
-	redhatRelease, err := ioutil.ReadFile(path.Join(rootfs, "etc/redhat-release"))
-	if err == nil {
-		for _, line := range strings.Split(string(redhatRelease), "\n") {
-			line = strings.TrimSpace(line)
-			if strings.HasPrefix(line, "Red Hat Enterprise Linux Server release 7.") {
-				return DistributionRhel7, nil
-			}
-			if strings.HasPrefix(line, "CentOS Linux release 7.") {
-				return DistributionCentos7, nil
-			}
+// HasLinePrefix returns true if the file at p has a line starting with prefix
+func (r *ReleaseFiles) HasLinePrefix(p string, prefix string) bool {
+	for _, line := range r.Lines(p) {
+		if strings.HasPrefix(line, prefix) {
+			return true
 		}
-		glog.Warningf("unhandled redhat-release info %q", string(lsbRelease))
-	} else if !os.IsNotExist(err) {
-		glog.Warningf("error reading /etc/redhat-release: %v", err)
 	}
+	return false
+}
 
-	// CoreOS uses /usr/lib/os-release
-	usrLibOsRelease, err := ioutil.ReadFile(path.Join(rootfs, "usr/lib/os-release"))
-	if err == nil {
-		for _, line := range strings.Split(string(usrLibOsRelease), "\n") {
-			line = strings.TrimSpace(line)
-			if line == "ID=coreos" {
-				return DistributionCoreOS, nil
-			}
+// OSRelease returns the (unquoted) value of key in os-release, or "" if it is not set.
+// As in the os-release specification, /etc/os-release takes precedence over /usr/lib/os-release.
+func (r *ReleaseFiles) OSRelease(key string) string {
+	p := "etc/os-release"
+	if r.Read(p) == "" {
+		p = "usr/lib/os-release"
+	}
+	for _, line := range r.Lines(p) {
+		if strings.HasPrefix(line, key+"=") {
+			return strings.Trim(strings.TrimPrefix(line, key+"="), "\"'")
 		}
-		glog.Warningf("unhandled os-release info %q", string(usrLibOsRelease))
-	} else if !os.IsNotExist(err) {
-		glog.Warningf("error reading /usr/lib/os-release: %v", err)
 	}
+	return ""
+}
+
+// FindDistribution identifies the distribution on which we are running
+// We will likely remove this when everything is containerized
+func FindDistribution(rootfs string) (Distribution, error) {
+	r := NewReleaseFiles(rootfs)
 
-	// ContainerOS uses /etc/os-release
-	osRelease, err := ioutil.ReadFile(path.Join(rootfs, "etc/os-release"))
-	if err == nil {
-		for _, line := range strings.Split(string(osRelease), "\n") {
-			line = strings.TrimSpace(line)
-			if line == "ID=cos" {
-				return DistributionContainerOS, nil
-			}
+	var matches []Distribution
+	for _, d := range Distributions() {
+		if registry[d].Identify(r) {
+			matches = append(matches, d)
 		}
-		glog.Warningf("unhandled /etc/os-release info %q", string(osRelease))
-	} else if !os.IsNotExist(err) {
-		glog.Warningf("error reading /etc/os-release: %v", err)
+	}
+
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	if len(matches) > 1 {
+		return "", fmt.Errorf("cannot identify distro: matches all of %v", matches)
 	}
 
 	glog.Warningf("could not determine distro")
-	glog.Warningf("  /etc/lsb-release: %q", string(lsbRelease))
-	glog.Warningf("  /etc/debian_version: %q", string(debianVersionBytes))
-	glog.Warningf("  /etc/redhat-release: %q", string(redhatRelease))
-	glog.Warningf("  /usr/lib/os-release: %q", string(usrLibOsRelease))
-	glog.Warningf("  /etc/os-release: %q", string(osRelease))
+	for _, p := range releaseFilePaths {
+		glog.Warningf("  /%s: %q", p, r.Read(p))
+	}
 
 	return "", fmt.Errorf("cannot identify distro")
 }
@@ -0,0 +1,89 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package distros
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestFindDistribution identifies each distribution from the release files in tests/<distribution>
+func TestFindDistribution(t *testing.T) {
+	for _, d := range Distributions() {
+		rootfs := filepath.Join("tests", string(d))
+		actual, err := FindDistribution(rootfs)
+		if err != nil {
+			t.Errorf("error identifying %s: %v", rootfs, err)
+			continue
+		}
+		if actual != d {
+			t.Errorf("%s was identified as %q, expected %q", rootfs, actual, d)
+		}
+	}
+}
+
+func TestFindDistribution_Unknown(t *testing.T) {
+	for _, rootfs := range []string{filepath.Join("tests", "unknown"), filepath.Join("tests", "does-not-exist")} {
+		if d, err := FindDistribution(rootfs); err == nil {
+			t.Errorf("expected error identifying %s, was identified as %q", rootfs, d)
+		}
+	}
+}
+
+func TestOSRelease(t *testing.T) {
+	grid := []struct {
+		Rootfs   string
+		Key      string
+		Expected string
+	}{
+		{Rootfs: "amazonlinux2", Key: "ID", Expected: "amzn"},
+		{Rootfs: "amazonlinux2", Key: "VERSION_ID", Expected: "2"},
+		// CoreOS only has /usr/lib/os-release
+		{Rootfs: "coreos", Key: "ID", Expected: "coreos"},
+		{Rootfs: "flatcar", Key: "ID_LIKE", Expected: "coreos"},
+		{Rootfs: "jessie", Key: "ID", Expected: ""},
+	}
+	for _, g := range grid {
+		r := NewReleaseFiles(filepath.Join("tests", g.Rootfs))
+		if actual := r.OSRelease(g.Key); actual != g.Expected {
+			t.Errorf("%s: expected %s=%q, got %q", g.Rootfs, g.Key, g.Expected, actual)
+		}
+	}
+}
+
+func TestBuildTags(t *testing.T) {
+	grid := map[Distribution][]string{
+		DistributionXenial:       {"_xenial", "_debian_family", "_systemd"},
+		DistributionBionic:       {"_debian_family", "_systemd"},
+		DistributionAmazonLinux2: {"_rhel_family", "_systemd"},
+		DistributionCoreOS:       {"_coreos", "_coreos_family", "_systemd"},
+		DistributionFlatcar:      {"_coreos_family", "_systemd"},
+	}
+	for d, expected := range grid {
+		actual := d.BuildTags()
+		if len(actual) != len(expected) {
+			t.Errorf("%s: expected tags %v, got %v", d, expected, actual)
+			continue
+		}
+		for i := range expected {
+			if actual[i] != expected[i] {
+				t.Errorf("%s: expected tags %v, got %v", d, expected, actual)
+				break
+			}
+		}
+	}
+}
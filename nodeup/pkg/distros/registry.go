@@ -0,0 +1,138 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package distros
+
+import (
+	"sort"
+
+	"github.com/golang/glog"
+)
+
+// Info describes a distribution, and how nodeup configures it.
+// Each distribution registers its Info (see Register), so adding a distribution does not require changes elsewhere.
+type Info struct {
+	// Distribution is the name of the distribution
+	Distribution Distribution
+	// Family is the group of distributions which nodeup configures in the same way
+	Family Family
+	// PackageManager is the tool used to install OS packages
+	PackageManager PackageManager
+	// InitSystem is the init system of the distribution
+	InitSystem InitSystem
+	// BuildTags are the (legacy) nodeup tags for the distribution; the family tags are added automatically
+	BuildTags []string
+
+	// DefaultPackages are the OS packages nodeup installs on every node
+	DefaultPackages []string
+	// DockerPackagesFrom is the distribution whose docker packages we install, if the distribution has none of its own
+	DockerPackagesFrom Distribution
+	// DockerPackage is the docker package from the distribution's own repositories,
+	// which we install on architectures for which we have no pinned docker packages
+	DockerPackage string
+	// PackagesImage is the docker image used to download OS packages for node bundles
+	PackagesImage string
+	// KubeconfigUsers are the users for whom we write a kubeconfig on masters, in order of preference
+	KubeconfigUsers []string
+
+	// Paths are the locations where nodeup installs files; unset fields take the default values
+	Paths Paths
+
+	// Identify returns true if the root filesystem is running this distribution
+	Identify func(r *ReleaseFiles) bool
+}
+
+// Paths are the locations where nodeup installs files, which differ on distributions with a read-only /usr
+type Paths struct {
+	// BinDir is the directory for binaries such as kubectl
+	BinDir string
+	// KubeletPath is the path of the kubelet binary
+	KubeletPath string
+	// CNIBinDir is the directory for the CNI plugins
+	CNIBinDir string
+	// SrvKubernetes is the directory for the kubernetes service files
+	SrvKubernetes string
+	// SrvSshproxy is the directory for the SSH proxy files
+	SrvSshproxy string
+	// SSLHostPaths are the directories with TLS certificates, which we mount into the control plane pods
+	SSLHostPaths []string
+}
+
+// defaultPaths are the paths used on distributions which do not override them
+var defaultPaths = Paths{
+	BinDir:        "/usr/local/bin",
+	KubeletPath:   "/usr/local/bin/kubelet",
+	CNIBinDir:     "/opt/cni/bin/",
+	SrvKubernetes: "/srv/kubernetes",
+	SrvSshproxy:   "/srv/sshproxy",
+	SSLHostPaths:  []string{"/etc/ssl", "/etc/pki/tls", "/etc/pki/ca-trust", "/usr/share/ssl", "/usr/ssl", "/usr/lib/ssl", "/usr/local/openssl", "/var/ssl", "/etc/openssl"},
+}
+
+// registry holds the known distributions
+var registry = make(map[Distribution]*Info)
+
+// Register adds a distribution; it is expected to be called from init
+func Register(info *Info) {
+	if info.Distribution == "" || info.Family == "" || info.Identify == nil {
+		glog.Fatalf("distribution %q must have a name, family and identify function", info.Distribution)
+	}
+	if _, found := registry[info.Distribution]; found {
+		glog.Fatalf("distribution %q registered twice", info.Distribution)
+	}
+
+	p := &info.Paths
+	if p.BinDir == "" {
+		p.BinDir = defaultPaths.BinDir
+	}
+	if p.KubeletPath == "" {
+		p.KubeletPath = defaultPaths.KubeletPath
+	}
+	if p.CNIBinDir == "" {
+		p.CNIBinDir = defaultPaths.CNIBinDir
+	}
+	if p.SrvKubernetes == "" {
+		p.SrvKubernetes = defaultPaths.SrvKubernetes
+	}
+	if p.SrvSshproxy == "" {
+		p.SrvSshproxy = defaultPaths.SrvSshproxy
+	}
+	if len(p.SSLHostPaths) == 0 {
+		p.SSLHostPaths = defaultPaths.SSLHostPaths
+	}
+
+	registry[info.Distribution] = info
+}
+
+// Info returns the description of the distribution.
+// An unknown distribution has no family or package manager, and the default paths.
+func (d Distribution) Info() *Info {
+	if info := registry[d]; info != nil {
+		return info
+	}
+	return &Info{Distribution: d, Paths: defaultPaths}
+}
+
+// Distributions returns the names of the known distributions, sorted by name
+func Distributions() []Distribution {
+	var names []Distribution
+	for d := range registry {
+		names = append(names, d)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i] < names[j]
+	})
+	return names
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package distros
+
+// rhelPackages are the packages nodeup installs on the RHEL family
+var rhelPackages = []string{"ebtables", "ethtool", "socat"}
+
+func init() {
+	Register(&Info{
+		Distribution:    DistributionRhel7,
+		Family:          FamilyRHEL,
+		PackageManager:  PackageManagerYum,
+		InitSystem:      InitSystemSystemd,
+		BuildTags:       []string{"_rhel7"},
+		DefaultPackages: rhelPackages,
+		DockerPackage:   "docker",
+		Identify:        isRedhatRelease("Red Hat Enterprise Linux Server release 7."),
+	})
+
+	Register(&Info{
+		Distribution:    DistributionCentos7,
+		Family:          FamilyRHEL,
+		PackageManager:  PackageManagerYum,
+		InitSystem:      InitSystemSystemd,
+		BuildTags:       []string{"_centos7"},
+		DefaultPackages: rhelPackages,
+		DockerPackage:   "docker",
+		PackagesImage:   "centos:7",
+		Identify:        isRedhatRelease("CentOS Linux release 7."),
+	})
+}
+
+// isRedhatRelease matches RHEL and CentOS releases by /etc/redhat-release
+func isRedhatRelease(prefix string) func(r *ReleaseFiles) bool {
+	return func(r *ReleaseFiles) bool {
+		return r.HasLinePrefix("etc/redhat-release", prefix)
+	}
+}
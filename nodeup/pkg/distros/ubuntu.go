@@ -0,0 +1,51 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package distros
+
+func init() {
+	Register(&Info{
+		Distribution:    DistributionXenial,
+		Family:          FamilyDebian,
+		PackageManager:  PackageManagerApt,
+		InitSystem:      InitSystemSystemd,
+		BuildTags:       []string{"_xenial"},
+		DefaultPackages: debianPackages,
+		DockerPackage:   "docker.io",
+		PackagesImage:   "ubuntu:16.04",
+		Identify:        isUbuntuRelease("xenial"),
+	})
+
+	Register(&Info{
+		Distribution:    DistributionBionic,
+		Family:          FamilyDebian,
+		PackageManager:  PackageManagerApt,
+		InitSystem:      InitSystemSystemd,
+		DefaultPackages: debianPackages,
+		// The xenial docker packages install cleanly on bionic
+		DockerPackagesFrom: DistributionXenial,
+		DockerPackage:      "docker.io",
+		PackagesImage:      "ubuntu:18.04",
+		Identify:           isUbuntuRelease("bionic"),
+	})
+}
+
+// isUbuntuRelease matches ubuntu releases by the codename in /etc/lsb-release
+func isUbuntuRelease(codename string) func(r *ReleaseFiles) bool {
+	return func(r *ReleaseFiles) bool {
+		return r.HasLine("etc/lsb-release", "DISTRIB_CODENAME="+codename)
+	}
+}
@@ -16,9 +16,39 @@ limitations under the License.
 
 package model
 
+import "strings"
+
 type Architecture string
 
 var (
 	ArchitectureAmd64 Architecture = "amd64"
 	ArchitectureArm   Architecture = "arm"
+	ArchitectureArm64 Architecture = "arm64"
 )
+
+// unsuffixedAmd64Images are the images which are published for amd64 without an architecture suffix,
+// but with a suffix (e.g. kube-proxy-arm64) for other architectures
+var unsuffixedAmd64Images = []string{"kube-proxy", "kube-apiserver", "kube-controller-manager", "kube-scheduler", "etcd"}
+
+// Image returns the name of the image for the architecture, e.g. k8s.gcr.io/pause-arm64:3.0 for k8s.gcr.io/pause-amd64:3.0 on arm64
+func (a Architecture) Image(image string) string {
+	if a == "" || a == ArchitectureAmd64 || image == "" {
+		return image
+	}
+
+	name, tag := image, ""
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		name, tag = image[:i], image[i:]
+	}
+
+	if strings.HasSuffix(name, "-amd64") {
+		return strings.TrimSuffix(name, "-amd64") + "-" + string(a) + tag
+	}
+	base := name[strings.LastIndex(name, "/")+1:]
+	for _, s := range unsuffixedAmd64Images {
+		if base == s {
+			return name + "-" + string(a) + tag
+		}
+	}
+	return image
+}
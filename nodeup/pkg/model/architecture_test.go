@@ -0,0 +1,86 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func TestArchitectureImage(t *testing.T) {
+	grid := []struct {
+		Architecture Architecture
+		Image        string
+		Expected     string
+	}{
+		{ArchitectureAmd64, "k8s.gcr.io/pause-amd64:3.0", "k8s.gcr.io/pause-amd64:3.0"},
+		{"", "k8s.gcr.io/kube-proxy:v1.10.3", "k8s.gcr.io/kube-proxy:v1.10.3"},
+		{ArchitectureArm64, "k8s.gcr.io/pause-amd64:3.0", "k8s.gcr.io/pause-arm64:3.0"},
+		{ArchitectureArm64, "k8s.gcr.io/kube-proxy:v1.10.3", "k8s.gcr.io/kube-proxy-arm64:v1.10.3"},
+		{ArchitectureArm64, "localhost:5000/kube-proxy", "localhost:5000/kube-proxy-arm64"},
+		{ArchitectureArm64, "k8s.gcr.io/kube-apiserver:v1.10.3", "k8s.gcr.io/kube-apiserver-arm64:v1.10.3"},
+		{ArchitectureArm64, "k8s.gcr.io/etcd:3.2.18", "k8s.gcr.io/etcd-arm64:3.2.18"},
+		{ArchitectureArm64, "k8s.gcr.io/etcd-amd64:3.2.18", "k8s.gcr.io/etcd-arm64:3.2.18"},
+		{ArchitectureArm64, "example.com/custom/pause:3.0", "example.com/custom/pause:3.0"},
+		{ArchitectureArm64, "", ""},
+	}
+	for _, g := range grid {
+		if actual := g.Architecture.Image(g.Image); actual != g.Expected {
+			t.Errorf("%s: expected image %q for %q, got %q", g.Architecture, g.Expected, g.Image, actual)
+		}
+	}
+}
+
+func TestArchitectureControlPlaneImages(t *testing.T) {
+	cluster := &kops.Cluster{}
+	cluster.Spec.KubernetesVersion = "1.10.3"
+	cluster.Spec.KubeControllerManager = &kops.KubeControllerManagerConfig{Image: "k8s.gcr.io/kube-controller-manager:v1.10.3"}
+	cluster.Spec.KubeScheduler = &kops.KubeSchedulerConfig{Image: "k8s.gcr.io/kube-scheduler:v1.10.3"}
+
+	context := &NodeupModelContext{
+		Cluster:       cluster,
+		InstanceGroup: &kops.InstanceGroup{},
+		Architecture:  ArchitectureArm64,
+		IsMaster:      true,
+	}
+	if err := context.Init(); err != nil {
+		t.Fatalf("error initializing context: %v", err)
+	}
+
+	var images []string
+	for _, build := range []func() (*v1.Pod, error){
+		(&KubeControllerManagerBuilder{NodeupModelContext: context}).buildPod,
+		(&KubeSchedulerBuilder{NodeupModelContext: context}).buildPod,
+	} {
+		pod, err := build()
+		if err != nil {
+			t.Fatalf("error building pod: %v", err)
+		}
+		images = append(images, pod.Spec.Containers[0].Image)
+	}
+
+	expected := []string{
+		"k8s.gcr.io/kube-controller-manager-arm64:v1.10.3",
+		"k8s.gcr.io/kube-scheduler-arm64:v1.10.3",
+	}
+	if !reflect.DeepEqual(images, expected) {
+		t.Errorf("unexpected control plane images %v, expected %v", images, expected)
+	}
+}
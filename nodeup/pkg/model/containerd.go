@@ -0,0 +1,192 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/nodeup/pkg/distros"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/flagbuilder"
+	"k8s.io/kops/pkg/systemd"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+// containerdSocket is the socket on which containerd serves the CRI
+const containerdSocket = "/run/containerd/containerd.sock"
+
+// defaultSandboxImage is the pause image, if one is not set in the kubelet config.  This is the amd64 image:
+// SandboxImage uses Architecture.Image to find the image for the architecture of the node.
+const defaultSandboxImage = "k8s.gcr.io/pause-amd64:3.0"
+
+// ContainerdBuilder installs containerd, when it is the container runtime
+type ContainerdBuilder struct {
+	*NodeupModelContext
+}
+
+var _ fi.ModelBuilder = &ContainerdBuilder{}
+
+// containerdBinaries are the binaries we install from the containerd release, and the directory they are installed into
+var containerdBinaries = []struct {
+	Name string
+	Dir  string
+}{
+	{Name: "containerd", Dir: "/usr/local/bin"},
+	{Name: "containerd-shim", Dir: "/usr/local/bin"},
+	{Name: "ctr", Dir: "/usr/local/bin"},
+	{Name: "crictl", Dir: "/usr/local/bin"},
+	{Name: "runc", Dir: "/usr/local/sbin"},
+}
+
+// Build is responsible for installing and configuring containerd
+func (b *ContainerdBuilder) Build(c *fi.ModelBuilderContext) error {
+	if b.ContainerRuntime() != kops.ContainerRuntimeContainerd {
+		return nil
+	}
+
+	switch b.Distribution.Family() {
+	case distros.FamilyCoreOS, distros.FamilyContainerOS:
+		return fmt.Errorf("containerd is not supported on %s", b.Distribution)
+	}
+
+	for _, binary := range containerdBinaries {
+		// The release tarball has the same layout as the target filesystem
+		target := filepath.Join(binary.Dir, binary.Name)
+		asset, err := b.Assets.Find(binary.Name, strings.TrimPrefix(target, "/"))
+		if err != nil {
+			return fmt.Errorf("error trying to locate asset %q: %v", binary.Name, err)
+		}
+		if asset == nil {
+			return fmt.Errorf("unable to locate asset %q", binary.Name)
+		}
+
+		c.AddTask(&nodetasks.File{
+			Path:     target,
+			Contents: asset,
+			Type:     nodetasks.FileType_File,
+			Mode:     s("0755"),
+		})
+	}
+
+	return b.buildConfiguration(c)
+}
+
+// buildConfiguration adds the configuration files and the systemd service for containerd
+func (b *ContainerdBuilder) buildConfiguration(c *fi.ModelBuilderContext) error {
+	config := b.Cluster.Spec.Containerd
+	if config == nil {
+		config = &kops.ContainerdConfig{}
+	}
+
+	c.AddTask(&nodetasks.File{
+		Path:     "/etc/containerd/config.toml",
+		Contents: fi.NewStringResource(b.buildConfigFile(config)),
+		Type:     nodetasks.FileType_File,
+	})
+
+	// crictl talks to the docker shim by default
+	c.AddTask(&nodetasks.File{
+		Path:     "/etc/crictl.yaml",
+		Contents: fi.NewStringResource("runtime-endpoint: " + b.ContainerRuntimeEndpoint() + "\n"),
+		Type:     nodetasks.FileType_File,
+	})
+
+	flagsString, err := flagbuilder.BuildFlags(config)
+	if err != nil {
+		return fmt.Errorf("error building containerd flags: %v", err)
+	}
+	c.AddTask(&nodetasks.File{
+		Path:     "/etc/sysconfig/containerd",
+		Contents: fi.NewStringResource("CONTAINERD_OPTS=" + flagsString + "\n"),
+		Type:     nodetasks.FileType_File,
+	})
+
+	c.AddTask(b.buildSystemdService())
+
+	return nil
+}
+
+// buildConfigFile renders the containerd config file, which configures the CRI plugin
+func (b *ContainerdBuilder) buildConfigFile(config *kops.ContainerdConfig) string {
+	var buf bytes.Buffer
+	buf.WriteString("[plugins.cri]\n")
+	buf.WriteString("  sandbox_image = " + strconv.Quote(b.SandboxImage()) + "\n")
+	buf.WriteString("  [plugins.cri.cni]\n")
+	buf.WriteString("    bin_dir = " + strconv.Quote(b.CNIBinDir()) + "\n")
+	buf.WriteString("    conf_dir = " + strconv.Quote(b.CNIConfDir()) + "\n")
+
+	var registries []string
+	for registry := range config.RegistryMirrors {
+		registries = append(registries, registry)
+	}
+	sort.Strings(registries)
+	for _, registry := range registries {
+		var endpoints []string
+		for _, endpoint := range config.RegistryMirrors[registry] {
+			endpoints = append(endpoints, strconv.Quote(endpoint))
+		}
+		buf.WriteString("  [plugins.cri.registry.mirrors." + strconv.Quote(registry) + "]\n")
+		buf.WriteString("    endpoint = [" + strings.Join(endpoints, ", ") + "]\n")
+	}
+
+	return buf.String()
+}
+
+// buildSystemdService generates the systemd unit for containerd
+func (b *ContainerdBuilder) buildSystemdService() *nodetasks.Service {
+	manifest := &systemd.Manifest{}
+	manifest.Set("Unit", "Description", "containerd container runtime")
+	manifest.Set("Unit", "Documentation", "https://containerd.io")
+	manifest.Set("Unit", "After", "network.target")
+
+	manifest.Set("Service", "EnvironmentFile", "/etc/sysconfig/containerd")
+	manifest.Set("Service", "EnvironmentFile", "/etc/environment")
+	manifest.Set("Service", "ExecStartPre", "/sbin/modprobe overlay")
+	manifest.Set("Service", "ExecStart", "/usr/local/bin/containerd \"$CONTAINERD_OPTS\"")
+	manifest.Set("Service", "Restart", "always")
+	manifest.Set("Service", "RestartSec", "5")
+
+	// set delegate yes so that systemd does not reset the cgroups of containers
+	manifest.Set("Service", "Delegate", "yes")
+	// kill only the containerd process, not all processes in the cgroup
+	manifest.Set("Service", "KillMode", "process")
+	manifest.Set("Service", "OOMScoreAdjust", "-999")
+	manifest.Set("Service", "LimitNOFILE", "1048576")
+	manifest.Set("Service", "LimitNPROC", "infinity")
+	manifest.Set("Service", "LimitCORE", "infinity")
+
+	manifest.Set("Install", "WantedBy", "multi-user.target")
+
+	manifestString := manifest.Render()
+	glog.V(8).Infof("Built service manifest %q\n%s", "containerd", manifestString)
+
+	service := &nodetasks.Service{
+		Name:       "containerd.service",
+		Definition: s(manifestString),
+	}
+
+	service.InitDefaults()
+
+	return service
+}
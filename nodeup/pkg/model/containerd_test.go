@@ -0,0 +1,75 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"path"
+	"strings"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func TestContainerdBuilder_Simple(t *testing.T) {
+	runContainerdBuilderTest(t, "simple")
+}
+
+func TestContainerdBuilder_Mirrors(t *testing.T) {
+	runContainerdBuilderTest(t, "mirrors")
+}
+
+func TestContainerdBuilder_Arm64(t *testing.T) {
+	basedir := "tests/containerdbuilder/simple"
+
+	nodeUpModelContext, err := LoadModel(basedir)
+	if err != nil {
+		t.Fatalf("error parsing cluster yaml %q: %v", basedir, err)
+	}
+	nodeUpModelContext.Architecture = ArchitectureArm64
+
+	builder := ContainerdBuilder{NodeupModelContext: nodeUpModelContext}
+	config := builder.buildConfigFile(&kops.ContainerdConfig{})
+	if !strings.Contains(config, `sandbox_image = "k8s.gcr.io/pause-arm64:3.0"`) {
+		t.Errorf("expected the arm64 pause image, got:\n%s", config)
+	}
+}
+
+func runContainerdBuilderTest(t *testing.T, key string) {
+	basedir := path.Join("tests/containerdbuilder/", key)
+
+	nodeUpModelContext, err := LoadModel(basedir)
+	if err != nil {
+		t.Fatalf("error parsing cluster yaml %q: %v", basedir, err)
+		return
+	}
+
+	context := &fi.ModelBuilderContext{
+		Tasks: make(map[string]fi.Task),
+	}
+
+	builder := ContainerdBuilder{NodeupModelContext: nodeUpModelContext}
+
+	// The binaries come from the asset store, which is not available in tests, so we only build the configuration
+	err = builder.buildConfiguration(context)
+	if err != nil {
+		t.Fatalf("error from ContainerdBuilder buildConfiguration: %v", err)
+		return
+	}
+
+	ValidateTasks(t, basedir, context)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/golang/glog"
 	"k8s.io/kops/nodeup/pkg/distros"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/model"
 	"k8s.io/kops/pkg/apis/kops/util"
 	"k8s.io/kops/pkg/apis/nodeup"
 	"k8s.io/kops/pkg/kubeconfig"
@@ -59,30 +60,12 @@ func (c *NodeupModelContext) Init() error {
 
 // SSLHostPaths returns the TLS paths for the distribution
 func (c *NodeupModelContext) SSLHostPaths() []string {
-	paths := []string{"/etc/ssl", "/etc/pki/tls", "/etc/pki/ca-trust"}
-
-	switch c.Distribution {
-	case distros.DistributionCoreOS:
-		// Because /usr is read-only on CoreOS, we can't have any new directories; docker will try (and fail) to create them
-		// TODO: Just check if the directories exist?
-		paths = append(paths, "/usr/share/ca-certificates")
-	case distros.DistributionContainerOS:
-		paths = append(paths, "/usr/share/ca-certificates")
-	default:
-		paths = append(paths, "/usr/share/ssl", "/usr/ssl", "/usr/lib/ssl", "/usr/local/openssl", "/var/ssl", "/etc/openssl")
-	}
-
-	return paths
+	return c.Distribution.Paths().SSLHostPaths
 }
 
 // PathSrvKubernetes returns the path for the kubernetes service files
 func (c *NodeupModelContext) PathSrvKubernetes() string {
-	switch c.Distribution {
-	case distros.DistributionContainerOS:
-		return "/etc/srv/kubernetes"
-	default:
-		return "/srv/kubernetes"
-	}
+	return c.Distribution.Paths().SrvKubernetes
 }
 
 // FileAssetsDefaultPath is the default location for assets which have no path
@@ -92,22 +75,12 @@ func (c *NodeupModelContext) FileAssetsDefaultPath() string {
 
 // PathSrvSshproxy returns the path for the SSL proxy
 func (c *NodeupModelContext) PathSrvSshproxy() string {
-	switch c.Distribution {
-	case distros.DistributionContainerOS:
-		return "/etc/srv/sshproxy"
-	default:
-		return "/srv/sshproxy"
-	}
+	return c.Distribution.Paths().SrvSshproxy
 }
 
 // CNIBinDir returns the path for the CNI binaries
 func (c *NodeupModelContext) CNIBinDir() string {
-	switch c.Distribution {
-	case distros.DistributionContainerOS:
-		return "/home/kubernetes/bin/"
-	default:
-		return "/opt/cni/bin/"
-	}
+	return c.Distribution.Paths().CNIBinDir
 }
 
 // CNIConfDir returns the CNI directory
@@ -248,6 +221,47 @@ func (c *NodeupModelContext) UsesCNI() bool {
 	return true
 }
 
+// ContainerRuntime returns the container runtime of the instance group: docker, containerd or cri-o
+func (c *NodeupModelContext) ContainerRuntime() string {
+	return model.ContainerRuntime(c.Cluster, c.InstanceGroup)
+}
+
+// ContainerRuntimeEndpoint returns the CRI endpoint of the container runtime, or an empty string for docker
+func (c *NodeupModelContext) ContainerRuntimeEndpoint() string {
+	switch c.ContainerRuntime() {
+	case kops.ContainerRuntimeContainerd:
+		return "unix://" + containerdSocket
+	case kops.ContainerRuntimeCRIO:
+		return "unix://" + crioSocket
+	default:
+		return ""
+	}
+}
+
+// ContainerRuntimeService returns the name of the systemd service of the container runtime
+func (c *NodeupModelContext) ContainerRuntimeService() string {
+	switch c.ContainerRuntime() {
+	case kops.ContainerRuntimeContainerd:
+		return "containerd.service"
+	case kops.ContainerRuntimeCRIO:
+		return "crio.service"
+	default:
+		return "docker.service"
+	}
+}
+
+// SandboxImage returns the image for the pod sandbox (pause) containers, which the CRI runtimes run themselves.
+// This is the kubelet pod infra container image, which cloudup has already remapped with the AssetBuilder.
+func (c *NodeupModelContext) SandboxImage() string {
+	if c.InstanceGroup != nil && c.InstanceGroup.Spec.Kubelet != nil && c.InstanceGroup.Spec.Kubelet.PodInfraContainerImage != "" {
+		return c.Architecture.Image(c.InstanceGroup.Spec.Kubelet.PodInfraContainerImage)
+	}
+	if c.Cluster.Spec.Kubelet != nil && c.Cluster.Spec.Kubelet.PodInfraContainerImage != "" {
+		return c.Architecture.Image(c.Cluster.Spec.Kubelet.PodInfraContainerImage)
+	}
+	return c.Architecture.Image(defaultSandboxImage)
+}
+
 // UseSecureKubelet checks if the kubelet api should be protected by a client certificate. Note: the settings are be
 // in one of three section, master specific kubelet, cluster wide kubelet or the InstanceGroup. Though arguably is
 // doesn't make much sense to unset this on a per InstanceGroup level, but hey :)
@@ -277,14 +291,7 @@ func (c *NodeupModelContext) UseSecureKubelet() bool {
 
 // KubectlPath returns distro based path for kubectl
 func (c *NodeupModelContext) KubectlPath() string {
-	kubeletCommand := "/usr/local/bin"
-	if c.Distribution == distros.DistributionCoreOS {
-		kubeletCommand = "/opt/bin"
-	}
-	if c.Distribution == distros.DistributionContainerOS {
-		kubeletCommand = "/home/kubernetes/bin"
-	}
-	return kubeletCommand
+	return c.Distribution.Paths().BinDir
 }
 
 // BuildCertificateTask is responsible for build a certificate request task
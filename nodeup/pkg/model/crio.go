@@ -0,0 +1,131 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/flagbuilder"
+	"k8s.io/kops/pkg/systemd"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+// crioSocket is the socket on which CRI-O serves the CRI
+const crioSocket = "/var/run/crio/crio.sock"
+
+// DefaultCRIOVersion is the CRI-O version we use if one is not specified in the manifest
+const DefaultCRIOVersion = "1.10"
+
+// CRIOBuilder installs CRI-O, when it is the container runtime
+type CRIOBuilder struct {
+	*NodeupModelContext
+}
+
+var _ fi.ModelBuilder = &CRIOBuilder{}
+
+// Build is responsible for installing and configuring CRI-O
+func (b *CRIOBuilder) Build(c *fi.ModelBuilderContext) error {
+	if b.ContainerRuntime() != kops.ContainerRuntimeCRIO {
+		return nil
+	}
+
+	config := b.Cluster.Spec.CRIO
+	if config == nil {
+		config = &kops.CRIOConfig{}
+	}
+
+	version := fi.StringValue(config.Version)
+	if version == "" {
+		version = DefaultCRIOVersion
+		glog.Warningf("CRI-O version not specified; using default %q", version)
+	}
+
+	if !b.Distribution.IsDebianFamily() && !b.Distribution.IsRHELFamily() {
+		return fmt.Errorf("CRI-O is not supported on %s", b.Distribution)
+	}
+	c.AddTask(b.buildPackage(version))
+
+	flagsString, err := flagbuilder.BuildFlags(config)
+	if err != nil {
+		return fmt.Errorf("error building CRI-O flags: %v", err)
+	}
+	// The CNI directories and the pause image are not exposed, as they must match the kubelet
+	flagsString += " --cni-config-dir=" + b.CNIConfDir()
+	flagsString += " --cni-plugin-dir=" + b.CNIBinDir()
+	flagsString += " --pause-image=" + b.SandboxImage()
+
+	c.AddTask(&nodetasks.File{
+		Path:     "/etc/sysconfig/crio",
+		Contents: fi.NewStringResource("CRIO_OPTS=" + flagsString + "\n"),
+		Type:     nodetasks.FileType_File,
+	})
+
+	c.AddTask(b.buildSystemdService())
+
+	return nil
+}
+
+// buildPackage returns the package for the CRI-O version.
+// CRI-O is not in the standard repositories; the package repository must be configured in the image
+// (for example the projectatomic PPA on ubuntu, or the virt SIG on centos).
+func (b *CRIOBuilder) buildPackage(version string) *nodetasks.Package {
+	if b.Distribution.IsRHELFamily() {
+		// The rpm is named cri-o for every version, so we ask yum for the version
+		return &nodetasks.Package{Name: "cri-o", Version: s(version)}
+	}
+	// The PPA has a package for each minor version
+	return &nodetasks.Package{Name: "cri-o-" + version}
+}
+
+// buildSystemdService generates the systemd unit for CRI-O
+func (b *CRIOBuilder) buildSystemdService() *nodetasks.Service {
+	manifest := &systemd.Manifest{}
+	manifest.Set("Unit", "Description", "Container Runtime Interface for OCI (CRI-O)")
+	manifest.Set("Unit", "Documentation", "https://github.com/kubernetes-incubator/cri-o")
+	manifest.Set("Unit", "Wants", "network-online.target")
+	manifest.Set("Unit", "After", "network-online.target")
+
+	manifest.Set("Service", "Type", "notify")
+	manifest.Set("Service", "EnvironmentFile", "/etc/sysconfig/crio")
+	manifest.Set("Service", "EnvironmentFile", "/etc/environment")
+	manifest.Set("Service", "ExecStart", "/usr/bin/crio \"$CRIO_OPTS\"")
+	manifest.Set("Service", "ExecReload", "/bin/kill -s HUP $MAINPID")
+	manifest.Set("Service", "TimeoutStartSec", "0")
+	manifest.Set("Service", "OOMScoreAdjust", "-999")
+	manifest.Set("Service", "LimitNOFILE", "1048576")
+	manifest.Set("Service", "LimitNPROC", "1048576")
+	manifest.Set("Service", "LimitCORE", "infinity")
+	manifest.Set("Service", "Restart", "always")
+	manifest.Set("Service", "RestartSec", "2s")
+
+	manifest.Set("Install", "WantedBy", "multi-user.target")
+
+	manifestString := manifest.Render()
+	glog.V(8).Infof("Built service manifest %q\n%s", "crio", manifestString)
+
+	service := &nodetasks.Service{
+		Name:       "crio.service",
+		Definition: s(manifestString),
+	}
+
+	service.InitDefaults()
+
+	return service
+}
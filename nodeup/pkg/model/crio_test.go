@@ -0,0 +1,72 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"path"
+	"testing"
+
+	"k8s.io/kops/nodeup/pkg/distros"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func TestCRIOBuilder_Simple(t *testing.T) {
+	runCRIOBuilderTest(t, "simple")
+}
+
+func TestCRIOBuilder_Package(t *testing.T) {
+	grid := []struct {
+		Distribution    distros.Distribution
+		ExpectedName    string
+		ExpectedVersion string
+	}{
+		{Distribution: distros.DistributionXenial, ExpectedName: "cri-o-1.11"},
+		{Distribution: distros.DistributionCentos7, ExpectedName: "cri-o", ExpectedVersion: "1.11"},
+		{Distribution: distros.DistributionRhel7, ExpectedName: "cri-o", ExpectedVersion: "1.11"},
+	}
+	for _, g := range grid {
+		builder := &CRIOBuilder{NodeupModelContext: &NodeupModelContext{Distribution: g.Distribution}}
+		pkg := builder.buildPackage("1.11")
+		if pkg.Name != g.ExpectedName || fi.StringValue(pkg.Version) != g.ExpectedVersion {
+			t.Errorf("unexpected package for %s: %s %s", g.Distribution, pkg.Name, fi.StringValue(pkg.Version))
+		}
+	}
+}
+
+func runCRIOBuilderTest(t *testing.T, key string) {
+	basedir := path.Join("tests/criobuilder/", key)
+
+	nodeUpModelContext, err := LoadModel(basedir)
+	if err != nil {
+		t.Fatalf("error parsing cluster yaml %q: %v", basedir, err)
+		return
+	}
+
+	context := &fi.ModelBuilderContext{
+		Tasks: make(map[string]fi.Task),
+	}
+
+	builder := CRIOBuilder{NodeupModelContext: nodeUpModelContext}
+
+	err = builder.Build(context)
+	if err != nil {
+		t.Fatalf("error from CRIOBuilder Build: %v", err)
+		return
+	}
+
+	ValidateTasks(t, basedir, context)
+}
@@ -30,7 +30,7 @@ type DirectoryBuilder struct {
 var _ fi.ModelBuilder = &DirectoryBuilder{}
 
 func (b *DirectoryBuilder) Build(c *fi.ModelBuilderContext) error {
-	if b.Distribution == distros.DistributionContainerOS {
+	if b.Distribution.Family() == distros.FamilyContainerOS {
 		dir := "/home/kubernetes/bin"
 
 		t := &nodetasks.File{
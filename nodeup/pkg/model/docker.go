@@ -23,6 +23,7 @@ import (
 
 	"k8s.io/kops/nodeup/pkg/distros"
 	"k8s.io/kops/nodeup/pkg/model/resources"
+	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/flagbuilder"
 	"k8s.io/kops/pkg/systemd"
 	"k8s.io/kops/upup/pkg/fi"
@@ -511,19 +512,36 @@ func (d *dockerVersion) matches(arch Architecture, dockerVersion string, distro
 	return true
 }
 
+// findDockerPackages returns the docker packages to install for the distribution.
+// Note that centos/rhel comprises multiple packages.
+func findDockerPackages(arch Architecture, version string, distro distros.Distribution) []*dockerVersion {
+	var packages []*dockerVersion
+	for i := range dockerVersions {
+		dv := &dockerVersions[i]
+		if dv.matches(arch, version, distro) {
+			packages = append(packages, dv)
+		}
+	}
+	return packages
+}
+
 // Build is responsible for configuring the docker daemon
 func (b *DockerBuilder) Build(c *fi.ModelBuilderContext) error {
+	if runtime := b.ContainerRuntime(); runtime != kops.ContainerRuntimeDocker {
+		glog.Infof("Container runtime is %q; won't install Docker", runtime)
+		return nil
+	}
 
 	// @check: neither coreos or containeros need provision docker.service, just the docker daemon options
-	switch b.Distribution {
-	case distros.DistributionCoreOS:
+	switch b.Distribution.Family() {
+	case distros.FamilyCoreOS:
 		glog.Infof("Detected CoreOS; won't install Docker")
 		if err := b.buildContainerOSConfigurationDropIn(c); err != nil {
 			return err
 		}
 		return nil
 
-	case distros.DistributionContainerOS:
+	case distros.FamilyContainerOS:
 		glog.Infof("Detected ContainerOS; won't install Docker")
 		if err := b.buildContainerOSConfigurationDropIn(c); err != nil {
 			return err
@@ -552,15 +570,15 @@ func (b *DockerBuilder) Build(c *fi.ModelBuilderContext) error {
 
 	// Add packages
 	{
-		count := 0
-		for i := range dockerVersions {
-			dv := &dockerVersions[i]
-			if !dv.matches(b.Architecture, dockerVersion, b.Distribution) {
-				continue
+		packages := findDockerPackages(b.Architecture, dockerVersion, b.Distribution)
+		if len(packages) == 0 {
+			if from := b.Distribution.Info().DockerPackagesFrom; from != "" {
+				glog.Infof("Using docker packages for %s on %s", from, b.Distribution)
+				packages = findDockerPackages(b.Architecture, dockerVersion, from)
 			}
+		}
 
-			count++
-
+		for _, dv := range packages {
 			c.AddTask(&nodetasks.Package{
 				Name:    dv.Name,
 				Version: s(dv.Version),
@@ -574,12 +592,16 @@ func (b *DockerBuilder) Build(c *fi.ModelBuilderContext) error {
 			for _, dep := range dv.Dependencies {
 				c.AddTask(&nodetasks.Package{Name: dep})
 			}
-
-			// Note we do _not_ stop looping... centos/rhel comprises multiple packages
 		}
 
-		if count == 0 {
-			glog.Warningf("Did not find docker package for %s %s %s", b.Distribution, b.Architecture, dockerVersion)
+		if len(packages) == 0 {
+			// We only pin docker packages for amd64 (and arm on some distros); elsewhere we use the distro package
+			if name := b.Distribution.Info().DockerPackage; name != "" && b.Architecture != ArchitectureAmd64 {
+				glog.Warningf("No docker %s package for %s on %s; installing %q from the distribution", dockerVersion, b.Architecture, b.Distribution, name)
+				c.AddTask(&nodetasks.Package{Name: name, PreventStart: fi.Bool(true)})
+			} else {
+				glog.Warningf("Did not find docker package for %s %s %s", b.Distribution, b.Architecture, dockerVersion)
+			}
 		}
 	}
 
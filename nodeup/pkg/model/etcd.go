@@ -37,12 +37,12 @@ func (b *EtcdBuilder) Build(c *fi.ModelBuilderContext) error {
 		return nil
 	}
 
-	switch b.Distribution {
-	case distros.DistributionCoreOS:
+	switch b.Distribution.Family() {
+	case distros.FamilyCoreOS:
 		glog.Infof("Detected CoreOS; skipping etcd user installation")
 		return nil
 
-	case distros.DistributionContainerOS:
+	case distros.FamilyContainerOS:
 		glog.Infof("Detected ContainerOS; skipping etcd user installation")
 		return nil
 	}
@@ -23,7 +23,7 @@ import (
 	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
 )
 
-// FirewallBuilder configures the firewall (iptables)
+// FirewallBuilder configures the firewall (iptables), and the host firewall if it is enabled
 type FirewallBuilder struct {
 	*NodeupModelContext
 }
@@ -33,16 +33,32 @@ var _ fi.ModelBuilder = &FirewallBuilder{}
 // Build is responsible for generating any node firewall rules
 func (b *FirewallBuilder) Build(c *fi.ModelBuilderContext) error {
 	// We need forwarding enabled (https://github.com/kubernetes/kubernetes/issues/40182)
-	c.AddTask(b.buildFirewallScript())
-	c.AddTask(b.buildSystemdService())
+	hostFirewall, allow := b.hostFirewallRules()
+
+	c.AddTask(b.buildFirewallScript(hostFirewall != nil))
+	c.AddTask(b.buildSystemdService(hostFirewall != nil))
+
+	if hostFirewall != nil {
+		b.buildHostFirewall(c, hostFirewall, allow)
+	} else {
+		// The host firewall may have been enabled before; its rules are removed by the iptables-setup script
+		c.AddTask(&nodetasks.RemovedService{
+			Name:  hostFirewallServiceName,
+			Files: []string{hostFirewallScriptPath},
+		})
+	}
 
 	return nil
 }
 
-func (b *FirewallBuilder) buildSystemdService() *nodetasks.Service {
+func (b *FirewallBuilder) buildSystemdService(hostFirewall bool) *nodetasks.Service {
 	manifest := &systemd.Manifest{}
 	manifest.Set("Unit", "Description", "Configure iptables for kubernetes")
 	manifest.Set("Unit", "Documentation", "https://github.com/kubernetes/kops")
+	if hostFirewall {
+		// The host firewall chain must come before any ACCEPT rules we add to INPUT
+		manifest.Set("Unit", "After", hostFirewallServiceName)
+	}
 	manifest.Set("Unit", "Before", "network.target")
 	manifest.Set("Service", "Type", "oneshot")
 	manifest.Set("Service", "RemainAfterExit", "yes")
@@ -62,7 +78,7 @@ func (b *FirewallBuilder) buildSystemdService() *nodetasks.Service {
 	return service
 }
 
-func (b *FirewallBuilder) buildFirewallScript() *nodetasks.File {
+func (b *FirewallBuilder) buildFirewallScript(hostFirewall bool) *nodetasks.File {
 	// TODO: Do we want to rely on running nodeup on every boot, or do we want to install systemd units?
 
 	// TODO: The if statement in the script doesn't make it idempotent
@@ -86,6 +102,10 @@ iptables -A FORWARD -w -p UDP -j ACCEPT
 iptables -A FORWARD -w -p ICMP -j ACCEPT
 fi
 `
+	if !hostFirewall {
+		script += removeHostFirewallScript()
+	}
+
 	t := &nodetasks.File{
 		Path:     "/home/kubernetes/bin/iptables-setup",
 		Contents: fi.NewStringResource(script),
@@ -17,8 +17,12 @@ limitations under the License.
 package model
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"k8s.io/kops/pkg/apis/kops"
@@ -36,9 +40,22 @@ type HookBuilder struct {
 
 var _ fi.ModelBuilder = &HookBuilder{}
 
+const (
+	// hooksDir holds the scripts, environment files and completion markers of the hooks
+	hooksDir = "/var/lib/kops/hooks"
+
+	// kubeletHealthzURL is polled by hooks which run after the kubelet is healthy
+	kubeletHealthzURL = "http://127.0.0.1:10248/healthz"
+
+	// hookRetryDelaySeconds is the delay between retries of a failed hook
+	hookRetryDelaySeconds = 10
+)
+
 // Build is responsible for implementing the cluster hook
 func (h *HookBuilder) Build(c *fi.ModelBuilderContext) error {
-	// we keep a list of hooks name so we can allow local instanceGroup hooks override the cluster ones
+	// we keep a list of hooks name so we can allow local instanceGroup hooks override the cluster ones:
+	// the instance group hooks are processed first, so a cluster hook is skipped (and so replaced, or
+	// disabled by a disabled instance group hook) if an instance group hook has the same name
 	hookNames := make(map[string]bool, 0)
 	for i, spec := range []*[]kops.HookSpec{&h.InstanceGroup.Spec.Hooks, &h.Cluster.Spec.Hooks} {
 		for j, hook := range *spec {
@@ -80,7 +97,7 @@ func (h *HookBuilder) Build(c *fi.ModelBuilderContext) error {
 				continue
 			}
 
-			service, err := h.buildSystemdService(name, &hook)
+			service, err := h.buildSystemdService(c, name, &hook)
 			if err != nil {
 				return err
 			}
@@ -103,7 +120,7 @@ func ensureSystemdSuffix(name string) string {
 }
 
 // buildSystemdService is responsible for generating the service
-func (h *HookBuilder) buildSystemdService(name string, hook *kops.HookSpec) (*nodetasks.Service, error) {
+func (h *HookBuilder) buildSystemdService(c *fi.ModelBuilderContext, name string, hook *kops.HookSpec) (*nodetasks.Service, error) {
 	// perform some basic validation
 	if hook.ExecContainer == nil && hook.Manifest == "" {
 		glog.Warningf("hook: %s has neither a raw unit or exec image configured", name)
@@ -126,17 +143,48 @@ func (h *HookBuilder) buildSystemdService(name string, hook *kops.HookSpec) (*no
 	for _, x := range hook.Before {
 		unit.Set("Unit", "Before", x)
 	}
+	if hook.Phase == kops.HookPhaseBeforeShutdown {
+		// units are stopped in the reverse order to which they are started, so the hook runs before its requirements are stopped
+		for _, x := range hook.Requires {
+			unit.Set("Unit", "After", x)
+		}
+	}
+	if hook.RunPolicy == kops.HookRunPolicyOnce {
+		unit.Set("Unit", "ConditionPathExists", "!"+hookPath(name, ".done"))
+	}
+
+	environmentFile, err := h.buildEnvironmentFile(c, name, hook)
+	if err != nil {
+		return nil, err
+	}
 
 	// are we a raw unit file or a docker exec?
 	switch hook.ExecContainer {
 	case nil:
 		unit.SetSection("Service", hook.Manifest)
+		if environmentFile != "" {
+			unit.Set("Service", "EnvironmentFile", environmentFile)
+		}
+		if hook.RunPolicy == kops.HookRunPolicyOnce {
+			unit.Set("Service", "ExecStartPost", systemd.EscapeCommand([]string{"/bin/touch", hookPath(name, ".done")}))
+		}
 	default:
-		if err := h.buildDockerService(unit, hook); err != nil {
+		if err := h.buildDockerService(c, name, unit, hook, environmentFile); err != nil {
 			return nil, err
 		}
 	}
 
+	if hook.RunPolicy == kops.HookRunPolicyOncePerBoot {
+		// the unit stays active once the hook has run, so nodeup does not start it again until the node reboots
+		unit.Set("Service", "RemainAfterExit", "yes")
+	}
+	if hook.Timeout != nil {
+		unit.Set("Service", hookTimeoutKey(hook), strconv.Itoa(int(hook.Timeout.Duration.Seconds())))
+	}
+	if len(hook.SuccessExitCodes) != 0 {
+		unit.Set("Service", "SuccessExitStatus", joinExitCodes(hook.SuccessExitCodes, " "))
+	}
+
 	service := &nodetasks.Service{
 		Name:       ensureSystemdSuffix(name),
 		Definition: s(unit.Render()),
@@ -148,7 +196,7 @@ func (h *HookBuilder) buildSystemdService(name string, hook *kops.HookSpec) (*no
 }
 
 // buildDockerService is responsible for generating a docker exec unit file
-func (h *HookBuilder) buildDockerService(unit *systemd.Manifest, hook *kops.HookSpec) error {
+func (h *HookBuilder) buildDockerService(c *fi.ModelBuilderContext, name string, unit *systemd.Manifest, hook *kops.HookSpec, environmentFile string) error {
 	dockerArgs := []string{
 		"/usr/bin/docker", "run",
 		"-v", "/:/rootfs/",
@@ -158,21 +206,207 @@ func (h *HookBuilder) buildDockerService(unit *systemd.Manifest, hook *kops.Hook
 		"--privileged",
 	}
 	dockerArgs = append(dockerArgs, buildDockerEnvironmentVars(hook.ExecContainer.Environment)...)
+	if environmentFile != "" {
+		dockerArgs = append(dockerArgs, "--env-file", environmentFile)
+	}
 	dockerArgs = append(dockerArgs, hook.ExecContainer.Image)
 	dockerArgs = append(dockerArgs, hook.ExecContainer.Command...)
-
-	dockerRunCommand := systemd.EscapeCommand(dockerArgs)
-	dockerPullCommand := systemd.EscapeCommand([]string{"/usr/bin/docker", "pull", hook.ExecContainer.Image})
+	dockerPullArgs := []string{"/usr/bin/docker", "pull", hook.ExecContainer.Image}
 
 	unit.Set("Unit", "Requires", "docker.service")
-	unit.Set("Service", "ExecStartPre", dockerPullCommand)
-	unit.Set("Service", "ExecStart", dockerRunCommand)
-	unit.Set("Service", "Type", "oneshot")
+
+	if !needsHookScript(hook) {
+		unit.Set("Service", "ExecStartPre", systemd.EscapeCommand(dockerPullArgs))
+		unit.Set("Service", "ExecStart", systemd.EscapeCommand(dockerArgs))
+		unit.Set("Service", "Type", "oneshot")
+		if hook.RunPolicy == kops.HookRunPolicyOnce {
+			unit.Set("Service", "ExecStartPost", systemd.EscapeCommand([]string{"/bin/touch", hookPath(name, ".done")}))
+		}
+		unit.Set("Install", "WantedBy", "multi-user.target")
+		return nil
+	}
+
+	// the script is run with bash, as /var may be mounted noexec
+	scriptPath := hookPath(name, ".sh")
+	c.AddTask(&nodetasks.File{
+		Path:     scriptPath,
+		Contents: fi.NewStringResource(buildHookScript(name, hook, dockerPullArgs, dockerArgs)),
+		Type:     nodetasks.FileType_File,
+		Mode:     s("0755"),
+	})
+	runScript := systemd.EscapeCommand([]string{"/bin/bash", scriptPath})
+
+	switch hook.Phase {
+	case kops.HookPhaseAfterKubeletHealthy:
+		unit.Set("Unit", "Wants", "kubelet.service")
+		unit.Set("Unit", "After", "kubelet.service")
+		// a simple service does not block the start, so nodeup does not wait on a kubelet it may still be configuring
+		unit.Set("Service", "Type", "simple")
+		unit.Set("Service", "ExecStart", runScript)
+	case kops.HookPhaseBeforeShutdown:
+		unit.Set("Unit", "After", "docker.service")
+		unit.Set("Unit", "After", "kubelet.service")
+		unit.Set("Service", "Type", "oneshot")
+		unit.Set("Service", "RemainAfterExit", "yes")
+		unit.Set("Service", "ExecStart", "/bin/true")
+		unit.Set("Service", "ExecStop", runScript)
+	default:
+		unit.Set("Service", "Type", "oneshot")
+		unit.Set("Service", "ExecStart", runScript)
+	}
 	unit.Set("Install", "WantedBy", "multi-user.target")
 
 	return nil
 }
 
+// needsHookScript returns true if the hook is run by a script, which waits for the kubelet, retries or runs only on shutdown
+func needsHookScript(hook *kops.HookSpec) bool {
+	return hook.Retries > 0 || (hook.Phase != "" && hook.Phase != kops.HookPhaseBoot)
+}
+
+// buildHookScript returns the script which runs an execContainer hook
+func buildHookScript(name string, hook *kops.HookSpec, dockerPullArgs, dockerArgs []string) string {
+	var buf bytes.Buffer
+
+	buf.WriteString(`#!/bin/bash
+# Built by kops - do not edit
+
+set -o nounset
+set -o pipefail
+
+`)
+
+	switch hook.Phase {
+	case kops.HookPhaseBeforeShutdown:
+		buf.WriteString(`# systemd also stops the unit when nodeup restarts it, so we only run the hook when the node is shutting down
+if [[ "$(systemctl is-system-running)" != "stopping" ]]; then
+  exit 0
+fi
+
+`)
+	case kops.HookPhaseAfterKubeletHealthy:
+		buf.WriteString(`until curl -sf ` + kubeletHealthzURL + ` > /dev/null; do
+  echo "waiting for the kubelet to be healthy"
+  sleep 5
+done
+
+`)
+	}
+
+	retries := strconv.Itoa(int(hook.Retries))
+	successCodes := append([]int32{0}, hook.SuccessExitCodes...)
+
+	buf.WriteString(`rc=0
+for attempt in $(seq 0 ` + retries + `); do
+  if [[ ${attempt} -gt 0 ]]; then
+    echo "hook ` + name + ` failed with exit code ${rc}; retrying (${attempt} of ` + retries + `)"
+    sleep ` + strconv.Itoa(hookRetryDelaySeconds) + `
+  fi
+  rc=0
+  ` + shellQuoteCommand(dockerPullArgs) + ` && ` + shellQuoteCommand(dockerArgs) + ` || rc=$?
+  case ${rc} in
+  ` + joinExitCodes(successCodes, "|") + `)
+`)
+	if hook.RunPolicy == kops.HookRunPolicyOnce {
+		buf.WriteString(`    touch ` + hookPath(name, ".done") + "\n")
+	}
+	buf.WriteString(`    exit 0
+    ;;
+  esac
+done
+exit ${rc}
+`)
+
+	return buf.String()
+}
+
+// buildEnvironmentFile writes the values of the secrets referenced by the hook to an environment file, returning its path.
+// It returns an empty path if the hook does not reference any secrets.
+func (h *HookBuilder) buildEnvironmentFile(c *fi.ModelBuilderContext, name string, hook *kops.HookSpec) (string, error) {
+	if len(hook.EnvironmentFromSecrets) == 0 {
+		return "", nil
+	}
+	if h.SecretStore == nil {
+		return "", fmt.Errorf("hook %s uses secrets, but the secret store is not configured", name)
+	}
+
+	var keys []string
+	for k := range hook.EnvironmentFromSecrets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var lines []string
+	for _, k := range keys {
+		id := hook.EnvironmentFromSecrets[k]
+		secret, err := h.SecretStore.FindSecret(id)
+		if err != nil {
+			return "", fmt.Errorf("error reading secret %q for hook %s: %v", id, name, err)
+		}
+		if secret == nil {
+			return "", fmt.Errorf("secret %q for hook %s not found", id, name)
+		}
+		value := string(secret.Data)
+		// neither systemd nor docker environment files support multi-line values
+		if strings.ContainsAny(value, "\r\n") {
+			return "", fmt.Errorf("secret %q for hook %s cannot be used in the environment, as it spans multiple lines", id, name)
+		}
+		lines = append(lines, k+"="+value)
+	}
+
+	p := hookPath(name, ".env")
+	c.AddTask(&nodetasks.File{
+		Path:     p,
+		Contents: fi.NewStringResource(strings.Join(lines, "\n") + "\n"),
+		Type:     nodetasks.FileType_File,
+		Mode:     s("0600"),
+	})
+
+	return p, nil
+}
+
+// hookPath returns the path of a file belonging to the hook
+func hookPath(name string, extension string) string {
+	return hooksDir + "/" + strings.TrimSuffix(strings.TrimSuffix(name, ".service"), ".timer") + extension
+}
+
+// hookTimeoutKey returns the systemd setting which limits the time the hook runs
+func hookTimeoutKey(hook *kops.HookSpec) string {
+	switch hook.Phase {
+	case kops.HookPhaseAfterKubeletHealthy:
+		return "RuntimeMaxSec"
+	case kops.HookPhaseBeforeShutdown:
+		return "TimeoutStopSec"
+	default:
+		return "TimeoutStartSec"
+	}
+}
+
+// joinExitCodes returns the exit codes joined by the separator
+func joinExitCodes(codes []int32, sep string) string {
+	var s []string
+	for _, code := range codes {
+		s = append(s, strconv.Itoa(int(code)))
+	}
+	return strings.Join(s, sep)
+}
+
+// shellSafeRegex matches arguments which do not need quoting in a shell script
+var shellSafeRegex = regexp.MustCompile(`^[a-zA-Z0-9_./:=@%+,-]+$`)
+
+// shellQuoteCommand returns the command with the arguments quoted for bash
+func shellQuoteCommand(argv []string) string {
+	var quoted []string
+	for _, arg := range argv {
+		if shellSafeRegex.MatchString(arg) {
+			quoted = append(quoted, arg)
+		} else {
+			quoted = append(quoted, "'"+strings.Replace(arg, "'", `'\''`, -1)+"'")
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
 // isValidExecContainerAction checks the validatity of the execContainer - personally i think this validation
 // should be done high up the chain, but
 func isValidExecContainerAction(action *kops.ExecContainerAction) error {
@@ -0,0 +1,70 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"path"
+	"testing"
+
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/secrets"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestHookBuilder(t *testing.T) {
+	basedir := path.Join("tests/hooks/", "simple")
+
+	nodeUpModelContext, err := LoadModel(basedir)
+	if err != nil {
+		t.Fatalf("error parsing cluster yaml %q: %v", basedir, err)
+		return
+	}
+
+	secretStore := secrets.NewVFSSecretStore(nodeUpModelContext.Cluster, vfs.NewMemFSPath(vfs.NewMemFSContext(), "secrets"))
+	if _, _, err := secretStore.GetOrCreateSecret("hook-token", &fi.Secret{Data: []byte("s3cr3t")}); err != nil {
+		t.Fatalf("error creating secret: %v", err)
+	}
+	nodeUpModelContext.SecretStore = secretStore
+
+	context := &fi.ModelBuilderContext{
+		Tasks: make(map[string]fi.Task),
+	}
+
+	builder := HookBuilder{NodeupModelContext: nodeUpModelContext}
+
+	err = builder.Build(context)
+	if err != nil {
+		t.Fatalf("error from HookBuilder Build: %v", err)
+		return
+	}
+
+	ValidateTasks(t, basedir, context)
+}
+
+func TestShellQuoteCommand(t *testing.T) {
+	grid := map[string][]string{
+		"/usr/bin/docker run busybox":        {"/usr/bin/docker", "run", "busybox"},
+		"sh -c 'echo hello world'":           {"sh", "-c", "echo hello world"},
+		`echo 'it'\''s' '$HOME'`:             {"echo", "it's", "$HOME"},
+		"docker run -e KEY=value --net=host": {"docker", "run", "-e", "KEY=value", "--net=host"},
+	}
+	for expected, argv := range grid {
+		if actual := shellQuoteCommand(argv); actual != expected {
+			t.Errorf("expected %v to be quoted as %q, got %q", argv, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"bytes"
+	"net"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/systemd"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+const (
+	// hostFirewallChain is the iptables chain holding the host firewall rules; it is jumped to from INPUT
+	hostFirewallChain = "KOPS-HOST-FIREWALL"
+	// hostFirewallTable is the nftables table holding the host firewall rules
+	hostFirewallTable = "kops-host-firewall"
+
+	hostFirewallScriptPath  = "/home/kubernetes/bin/host-firewall-setup"
+	hostFirewallServiceName = "kubernetes-host-firewall.service"
+
+	// nodePortRange is the default range of NodePort services
+	nodePortRange = "30000-32767"
+)
+
+// gceHealthCheckCIDRs are the sources of the GCE load balancer health checks, which reach the instances directly
+var gceHealthCheckCIDRs = []string{"130.211.0.0/22", "35.191.0.0/16"}
+
+// hostFirewallAllow is a rule allowing ingress through the host firewall
+type hostFirewallAllow struct {
+	// Protocol is tcp, udp or icmp; all protocols if empty
+	Protocol string
+	// Ports is a port or range of ports, e.g. 30000-32767; all ports if empty
+	Ports string
+	// CIDR is the source; any source if empty
+	CIDR string
+}
+
+// hostFirewallRules returns the host firewall for the node, merging the HostFirewall of the cluster and instance group.
+// It returns nil if the host firewall is not enabled.
+func (b *FirewallBuilder) hostFirewallRules() (*kops.HostFirewallSpec, []hostFirewallAllow) {
+	var specs []*kops.HostFirewallSpec
+	specs = append(specs, b.Cluster.Spec.HostFirewall)
+	if b.InstanceGroup != nil {
+		specs = append(specs, b.InstanceGroup.Spec.HostFirewall)
+	}
+	firewall := mergeHostFirewall(specs...)
+	if !fi.BoolValue(firewall.Enabled) {
+		return nil, nil
+	}
+
+	var role kops.InstanceGroupRole
+	if b.InstanceGroup != nil {
+		role = b.InstanceGroup.Spec.Role
+	}
+
+	var allow []hostFirewallAllow
+
+	// Traffic from within the cluster: the network, the pods and the services
+	clusterCIDRs := []string{b.Cluster.Spec.NetworkCIDR}
+	clusterCIDRs = append(clusterCIDRs, b.Cluster.Spec.AdditionalNetworkCIDRs...)
+	for _, subnet := range b.Cluster.Spec.Subnets {
+		clusterCIDRs = append(clusterCIDRs, subnet.CIDR)
+	}
+	clusterCIDRs = append(clusterCIDRs, b.Cluster.Spec.NonMasqueradeCIDR, b.Cluster.Spec.ServiceClusterIPRange)
+	if b.Cluster.Spec.KubeControllerManager != nil {
+		clusterCIDRs = append(clusterCIDRs, b.Cluster.Spec.KubeControllerManager.ClusterCIDR)
+	}
+	for _, cidr := range clusterCIDRs {
+		allow = appendHostFirewallAllow(allow, hostFirewallAllow{CIDR: cidr})
+	}
+
+	// Load balancer health checks; on AWS these come from within the VPC
+	if kops.CloudProviderID(b.Cluster.Spec.CloudProvider) == kops.CloudProviderGCE {
+		for _, cidr := range gceHealthCheckCIDRs {
+			allow = appendHostFirewallAllow(allow, hostFirewallAllow{Protocol: "tcp", CIDR: cidr})
+		}
+	}
+
+	// The same access that kops grants in the cloud security groups
+	for _, cidr := range b.Cluster.Spec.SSHAccess {
+		allow = appendHostFirewallAllow(allow, hostFirewallAllow{Protocol: "tcp", Ports: "22", CIDR: cidr})
+	}
+	if role == kops.InstanceGroupRoleMaster {
+		for _, cidr := range b.Cluster.Spec.KubernetesAPIAccess {
+			allow = appendHostFirewallAllow(allow, hostFirewallAllow{Protocol: "tcp", Ports: "443", CIDR: cidr})
+		}
+	}
+	if role == kops.InstanceGroupRoleNode {
+		for _, cidr := range b.Cluster.Spec.NodePortAccess {
+			allow = appendHostFirewallAllow(allow, hostFirewallAllow{Protocol: "tcp", Ports: nodePortRange, CIDR: cidr})
+			allow = appendHostFirewallAllow(allow, hostFirewallAllow{Protocol: "udp", Ports: nodePortRange, CIDR: cidr})
+		}
+	}
+
+	for _, rule := range firewall.Rules {
+		if !hostFirewallRuleAppliesToRole(&rule, role) {
+			continue
+		}
+
+		protocol := rule.Protocol
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		ports := rule.Ports
+		if len(ports) == 0 {
+			ports = []string{""}
+		}
+		cidrs := rule.CIDRs
+		if len(cidrs) == 0 {
+			cidrs = []string{""}
+		}
+		for _, port := range ports {
+			for _, cidr := range cidrs {
+				allow = appendHostFirewallAllow(allow, hostFirewallAllow{Protocol: protocol, Ports: port, CIDR: cidr})
+			}
+		}
+	}
+
+	return firewall, allow
+}
+
+// appendHostFirewallAllow adds a rule, skipping duplicates, unset CIDRs (e.g. no AdditionalNetworkCIDRs) and IPv6 CIDRs
+func appendHostFirewallAllow(allow []hostFirewallAllow, rule hostFirewallAllow) []hostFirewallAllow {
+	if rule.CIDR == "" && rule.Protocol == "" {
+		return allow
+	}
+	if rule.CIDR != "" {
+		ip, _, err := net.ParseCIDR(rule.CIDR)
+		if err != nil || ip.To4() == nil {
+			glog.Warningf("ignoring CIDR %q in host firewall; only IPv4 CIDRs are supported", rule.CIDR)
+			return allow
+		}
+	}
+	for _, a := range allow {
+		if a == rule {
+			return allow
+		}
+	}
+	return append(allow, rule)
+}
+
+// hostFirewallRuleAppliesToRole returns true if the rule applies to instance groups with the role
+func hostFirewallRuleAppliesToRole(rule *kops.HostFirewallRule, role kops.InstanceGroupRole) bool {
+	if len(rule.Roles) == 0 {
+		return true
+	}
+	for _, r := range rule.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeHostFirewall combines the HostFirewall specs, with later specs overriding enabled and backend, and adding rules
+func mergeHostFirewall(specs ...*kops.HostFirewallSpec) *kops.HostFirewallSpec {
+	merged := &kops.HostFirewallSpec{}
+	for _, spec := range specs {
+		if spec == nil {
+			continue
+		}
+		if spec.Enabled != nil {
+			merged.Enabled = spec.Enabled
+		}
+		if spec.Backend != "" {
+			merged.Backend = spec.Backend
+		}
+		merged.Rules = append(merged.Rules, spec.Rules...)
+	}
+	return merged
+}
+
+// buildHostFirewall adds the script and systemd service which apply the host firewall.
+// The service runs the script on boot, and (because the script is a dependency) whenever nodeup changes the rules.
+func (b *FirewallBuilder) buildHostFirewall(c *fi.ModelBuilderContext, firewall *kops.HostFirewallSpec, allow []hostFirewallAllow) {
+	c.AddTask(&nodetasks.File{
+		Path:     hostFirewallScriptPath,
+		Contents: fi.NewStringResource(buildHostFirewallScript(firewall.Backend, allow)),
+		Type:     nodetasks.FileType_File,
+		Mode:     s("0755"),
+	})
+
+	manifest := &systemd.Manifest{}
+	manifest.Set("Unit", "Description", "Configure the host firewall for kubernetes")
+	manifest.Set("Unit", "Documentation", "https://github.com/kubernetes/kops")
+	manifest.Set("Unit", "Before", "network.target")
+	manifest.Set("Service", "Type", "oneshot")
+	manifest.Set("Service", "RemainAfterExit", "yes")
+	manifest.Set("Service", "ExecStart", hostFirewallScriptPath)
+	manifest.Set("Install", "WantedBy", "basic.target")
+
+	manifestString := manifest.Render()
+	glog.V(8).Infof("Built service manifest %q\n%s", hostFirewallServiceName, manifestString)
+
+	service := &nodetasks.Service{
+		Name:       hostFirewallServiceName,
+		Definition: s(manifestString),
+	}
+	service.InitDefaults()
+	c.AddTask(service)
+}
+
+// buildHostFirewallScript returns the script which applies the rules with the backend, or with nftables if it is installed and no backend is set
+func buildHostFirewallScript(backend string, allow []hostFirewallAllow) string {
+	var buf bytes.Buffer
+
+	buf.WriteString(`#!/bin/bash
+# Built by kops - do not edit
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+`)
+	buf.WriteString("BACKEND=\"" + backend + "\"\n")
+	buf.WriteString(`if [[ -z "${BACKEND}" ]]; then
+  if command -v nft > /dev/null; then
+    BACKEND=nftables
+  else
+    BACKEND=iptables
+  fi
+fi
+
+if [[ "${BACKEND}" == "nftables" ]]; then
+# The table is replaced atomically; it runs after the iptables filter table, so the kube-proxy and CNI rules are unaffected
+nft -f - <<'EOF'
+`)
+	buf.WriteString(buildHostFirewallNftables(allow))
+	buf.WriteString(`EOF
+else
+# Only our chain is replaced, so the chains of kube-proxy and the CNI provider are preserved
+iptables-restore --noflush <<'EOF'
+`)
+	buf.WriteString(buildHostFirewallIptables(allow))
+	buf.WriteString(`EOF
+# Our chain is at the end of INPUT, after the chains that kube-proxy and the CNI provider insert at the start
+iptables -w -C INPUT -j ` + hostFirewallChain + ` 2> /dev/null || iptables -w -A INPUT -j ` + hostFirewallChain + `
+fi
+`)
+
+	return buf.String()
+}
+
+// removeHostFirewallScript returns the commands which remove the rules of the host firewall, with either backend.
+// Nothing is changed if the host firewall was never enabled.
+func removeHostFirewallScript() string {
+	return `
+# The host firewall is not enabled; remove its rules, in case it was enabled before
+if iptables -w -n -L ` + hostFirewallChain + ` > /dev/null 2>&1; then
+echo "Removing the host firewall chain ` + hostFirewallChain + `"
+while iptables -w -D INPUT -j ` + hostFirewallChain + ` 2> /dev/null; do :; done
+iptables -w -F ` + hostFirewallChain + `
+iptables -w -X ` + hostFirewallChain + `
+fi
+if command -v nft > /dev/null && nft list table ip ` + hostFirewallTable + ` > /dev/null 2>&1; then
+echo "Removing the host firewall table ` + hostFirewallTable + `"
+nft delete table ip ` + hostFirewallTable + `
+fi
+`
+}
+
+// buildHostFirewallIptables returns the rules in iptables-restore format.
+// Allowed packets RETURN to INPUT, so that any later rules still apply.
+func buildHostFirewallIptables(allow []hostFirewallAllow) string {
+	lines := []string{
+		"*filter",
+		":" + hostFirewallChain + " - [0:0]",
+		"-A " + hostFirewallChain + " -i lo -j RETURN",
+		"-A " + hostFirewallChain + " -m conntrack --ctstate RELATED,ESTABLISHED -j RETURN",
+	}
+	for _, a := range allow {
+		rule := []string{"-A", hostFirewallChain}
+		if a.CIDR != "" {
+			rule = append(rule, "-s", a.CIDR)
+		}
+		if a.Protocol != "" {
+			rule = append(rule, "-p", a.Protocol)
+		}
+		if a.Ports != "" {
+			rule = append(rule, "-m", a.Protocol, "--dport", strings.Replace(a.Ports, "-", ":", 1))
+		}
+		rule = append(rule, "-j", "RETURN")
+		lines = append(lines, strings.Join(rule, " "))
+	}
+	lines = append(lines, "-A "+hostFirewallChain+" -j DROP", "COMMIT", "")
+	return strings.Join(lines, "\n")
+}
+
+// buildHostFirewallNftables returns the rules in nft format
+func buildHostFirewallNftables(allow []hostFirewallAllow) string {
+	lines := []string{
+		// Declaring the table before deleting it means the delete succeeds even if the table does not exist yet
+		"table ip " + hostFirewallTable + " {",
+		"}",
+		"delete table ip " + hostFirewallTable,
+		"table ip " + hostFirewallTable + " {",
+		"  chain input {",
+		"    type filter hook input priority 10; policy accept;",
+		"    iifname \"lo\" accept",
+		"    ct state established,related accept",
+	}
+	for _, a := range allow {
+		var rule []string
+		if a.CIDR != "" {
+			rule = append(rule, "ip saddr "+a.CIDR)
+		}
+		if a.Ports != "" {
+			rule = append(rule, a.Protocol+" dport "+a.Ports)
+		} else if a.Protocol != "" {
+			rule = append(rule, "ip protocol "+a.Protocol)
+		}
+		rule = append(rule, "accept")
+		lines = append(lines, "    "+strings.Join(rule, " "))
+	}
+	lines = append(lines, "    drop", "  }", "}", "")
+	return strings.Join(lines, "\n")
+}
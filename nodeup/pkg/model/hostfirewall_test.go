@@ -0,0 +1,60 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"path"
+	"testing"
+
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func TestFirewallBuilder_HostFirewall(t *testing.T) {
+	runHostFirewallTest(t, "simple")
+}
+
+func TestFirewallBuilder_HostFirewallDisabled(t *testing.T) {
+	runHostFirewallTest(t, "disabled")
+}
+
+func TestFirewallBuilder_HostFirewallGCE(t *testing.T) {
+	runHostFirewallTest(t, "gce")
+}
+
+func runHostFirewallTest(t *testing.T, key string) {
+	basedir := path.Join("tests/hostfirewall/", key)
+
+	nodeUpModelContext, err := LoadModel(basedir)
+	if err != nil {
+		t.Fatalf("error parsing cluster yaml %q: %v", basedir, err)
+		return
+	}
+
+	context := &fi.ModelBuilderContext{
+		Tasks: make(map[string]fi.Task),
+	}
+
+	builder := FirewallBuilder{NodeupModelContext: nodeUpModelContext}
+
+	err = builder.Build(context)
+	if err != nil {
+		t.Fatalf("error from FirewallBuilder Build: %v", err)
+		return
+	}
+
+	ValidateTasks(t, basedir, context)
+}
@@ -27,6 +27,7 @@ import (
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
 	"k8s.io/kops/util/pkg/exec"
+	"k8s.io/kops/util/pkg/hashing"
 
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -38,6 +39,9 @@ import (
 
 const PathAuthnConfig = "/etc/kubernetes/authn.config"
 
+// annotationEncryptionConfigHash is set on the kube-apiserver pod to the hash of the encryption config
+const annotationEncryptionConfigHash = "kops.k8s.io/encryptionconfig-hash"
+
 // KubeAPIServerBuilder install kube-apiserver (just the manifest at the moment)
 type KubeAPIServerBuilder struct {
 	*NodeupModelContext
@@ -55,6 +59,7 @@ func (b *KubeAPIServerBuilder) Build(c *fi.ModelBuilderContext) error {
 		return err
 	}
 
+	var encryptionConfigHash *hashing.Hash
 	if b.Cluster.Spec.EncryptionConfig != nil {
 		if *b.Cluster.Spec.EncryptionConfig && b.IsKubernetesGTE("1.7") {
 			b.Cluster.Spec.KubeAPIServer.ExperimentalEncryptionProviderConfig = fi.String(filepath.Join(b.PathSrvKubernetes(), "encryptionconfig.yaml"))
@@ -62,6 +67,11 @@ func (b *KubeAPIServerBuilder) Build(c *fi.ModelBuilderContext) error {
 			encryptioncfg, _ := b.SecretStore.Secret(key)
 			if encryptioncfg != nil {
 				contents := string(encryptioncfg.Data)
+				h, err := hashing.HashAlgorithmSHA256.Hash(strings.NewReader(contents))
+				if err != nil {
+					return fmt.Errorf("error hashing encryption config: %v", err)
+				}
+				encryptionConfigHash = h
 				t := &nodetasks.File{
 					Path:     *b.Cluster.Spec.KubeAPIServer.ExperimentalEncryptionProviderConfig,
 					Contents: fi.NewStringResource(contents),
@@ -78,6 +88,11 @@ func (b *KubeAPIServerBuilder) Build(c *fi.ModelBuilderContext) error {
 			return fmt.Errorf("error building kube-apiserver manifest: %v", err)
 		}
 
+		// Changing the encryption config (e.g. during key rotation) changes the manifest, so that the kubelet restarts the apiserver
+		if encryptionConfigHash != nil {
+			pod.ObjectMeta.Annotations[annotationEncryptionConfigHash] = encryptionConfigHash.Hex()
+		}
+
 		manifest, err := k8scodecs.ToVersionedYaml(pod)
 		if err != nil {
 			return fmt.Errorf("error marshalling manifest to yaml: %v", err)
@@ -247,7 +262,7 @@ func (b *KubeAPIServerBuilder) buildPod() (*v1.Pod, error) {
 
 	container := &v1.Container{
 		Name:  "kube-apiserver",
-		Image: b.Cluster.Spec.KubeAPIServer.Image,
+		Image: b.Architecture.Image(b.Cluster.Spec.KubeAPIServer.Image),
 		Command: exec.WithTee(
 			"/usr/local/bin/kube-apiserver",
 			sortedStrings(flags),
@@ -167,7 +167,7 @@ func (b *KubeControllerManagerBuilder) buildPod() (*v1.Pod, error) {
 
 	container := &v1.Container{
 		Name:  "kube-controller-manager",
-		Image: b.Cluster.Spec.KubeControllerManager.Image,
+		Image: b.Architecture.Image(b.Cluster.Spec.KubeControllerManager.Image),
 		Command: exec.WithTee(
 			"/usr/local/bin/kube-controller-manager",
 			sortedStrings(flags),
@@ -162,7 +162,7 @@ func (b *KubeProxyBuilder) buildPod() (*v1.Pod, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error building kubeproxy flags: %v", err)
 	}
-	image := c.Image
+	image := b.Architecture.Image(c.Image)
 
 	flags = append(flags, []string{
 		"--conntrack-max-per-core=131072",
@@ -124,7 +124,7 @@ func (b *KubeSchedulerBuilder) buildPod() (*v1.Pod, error) {
 
 	container := &v1.Container{
 		Name:  "kube-scheduler",
-		Image: c.Image,
+		Image: b.Architecture.Image(c.Image),
 		Command: exec.WithTee(
 			"/usr/local/bin/kube-scheduler",
 			sortedStrings(flags),
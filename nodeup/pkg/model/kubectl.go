@@ -19,7 +19,6 @@ package model
 import (
 	"fmt"
 
-	"k8s.io/kops/nodeup/pkg/distros"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
 
@@ -104,11 +103,8 @@ func (b *KubectlBuilder) Build(c *fi.ModelBuilderContext) error {
 
 // findKubeconfigUser finds the default user for whom we should create a kubeconfig
 func (b *KubectlBuilder) findKubeconfigUser() (*fi.User, *fi.Group, error) {
-	var users []string
-	switch b.Distribution {
-	case distros.DistributionJessie, distros.DistributionDebian9:
-		users = []string{"admin", "root"}
-	default:
+	users := b.Distribution.Info().KubeconfigUsers
+	if len(users) == 0 {
 		glog.Warningf("Unknown distro; won't write kubeconfig to homedir %s", b.Distribution)
 		return nil, nil, nil
 	}
@@ -20,11 +20,13 @@ import (
 	"fmt"
 	"path"
 	"path/filepath"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/golang/glog"
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kops/nodeup/pkg/distros"
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/flagbuilder"
@@ -119,14 +121,7 @@ func (b *KubeletBuilder) Build(c *fi.ModelBuilderContext) error {
 
 // kubeletPath returns the path of the kubelet based on distro
 func (b *KubeletBuilder) kubeletPath() string {
-	kubeletCommand := "/usr/local/bin/kubelet"
-	if b.Distribution == distros.DistributionCoreOS {
-		kubeletCommand = "/opt/kubernetes/bin/kubelet"
-	}
-	if b.Distribution == distros.DistributionContainerOS {
-		kubeletCommand = "/home/kubernetes/bin/kubelet"
-	}
-	return kubeletCommand
+	return b.Distribution.Paths().KubeletPath
 }
 
 // buildSystemdEnvironmentFile renders the environment file for the kubelet
@@ -194,9 +189,9 @@ func (b *KubeletBuilder) buildSystemdService() *nodetasks.Service {
 	manifest := &systemd.Manifest{}
 	manifest.Set("Unit", "Description", "Kubernetes Kubelet Server")
 	manifest.Set("Unit", "Documentation", "https://github.com/kubernetes/kubernetes")
-	manifest.Set("Unit", "After", "docker.service")
+	manifest.Set("Unit", "After", b.ContainerRuntimeService())
 
-	if b.Distribution == distros.DistributionCoreOS {
+	if b.Distribution.Family() == distros.FamilyCoreOS {
 		// We add /opt/kubernetes/bin for our utilities (socat)
 		manifest.Set("Service", "Environment", "PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin:/opt/kubernetes/bin")
 	}
@@ -241,7 +236,7 @@ func (b *KubeletBuilder) buildKubeletConfig() (*kops.KubeletConfigSpec, error) {
 }
 
 func (b *KubeletBuilder) addStaticUtils(c *fi.ModelBuilderContext) error {
-	if b.Distribution == distros.DistributionCoreOS {
+	if b.Distribution.Family() == distros.FamilyCoreOS {
 		// CoreOS does not ship with socat.  Install our own (statically linked) version
 		// TODO: Extract to common function?
 		assetName := "socat"
@@ -268,12 +263,7 @@ func (b *KubeletBuilder) addStaticUtils(c *fi.ModelBuilderContext) error {
 
 // usesContainerizedMounter returns true if we use the containerized mounter
 func (b *KubeletBuilder) usesContainerizedMounter() bool {
-	switch b.Distribution {
-	case distros.DistributionContainerOS:
-		return true
-	default:
-		return false
-	}
+	return b.Distribution.Family() == distros.FamilyContainerOS
 }
 
 // addContainerizedMounter downloads and installs the containerized mounter, that we need on ContainerOS
@@ -405,6 +395,9 @@ func (b *KubeletBuilder) buildKubeletConfigSpec() (*kops.KubeletConfigSpec, erro
 		utils.JsonMergeStruct(c, b.InstanceGroup.Spec.Kubelet)
 	}
 
+	// The pause image is published per architecture
+	c.PodInfraContainerImage = b.Architecture.Image(c.PodInfraContainerImage)
+
 	if b.InstanceGroup.Spec.Role == kops.InstanceGroupRoleMaster {
 		if c.NodeLabels == nil {
 			c.NodeLabels = make(map[string]string)
@@ -444,5 +437,19 @@ func (b *KubeletBuilder) buildKubeletConfigSpec() (*kops.KubeletConfigSpec, erro
 		// For 1.5 and earlier, protokube will taint the master
 	}
 
+	// CRI runtimes are reached over their socket, rather than through the built-in docker shim
+	if runtime := b.ContainerRuntime(); runtime != kops.ContainerRuntimeDocker {
+		if c.ContainerRuntime == "" {
+			c.ContainerRuntime = "remote"
+		}
+		if c.ContainerRuntimeEndpoint == "" {
+			c.ContainerRuntimeEndpoint = b.ContainerRuntimeEndpoint()
+		}
+		if c.RuntimeRequestTimeout == nil {
+			// Image pulls are not streamed, so can take longer than the default 2 minutes
+			c.RuntimeRequestTimeout = &metav1.Duration{Duration: 15 * time.Minute}
+		}
+	}
+
 	return c, nil
 }
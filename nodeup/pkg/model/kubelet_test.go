@@ -165,8 +165,14 @@ func stringSlicesEqual(exp, other []string) bool {
 }
 
 func Test_RunKubeletBuilder(t *testing.T) {
-	basedir := "tests/kubelet/featuregates"
+	runKubeletBuilderTest(t, "tests/kubelet/featuregates")
+}
+
+func Test_RunKubeletBuilder_Containerd(t *testing.T) {
+	runKubeletBuilderTest(t, "tests/kubelet/containerd")
+}
 
+func runKubeletBuilderTest(t *testing.T, basedir string) {
 	context := &fi.ModelBuilderContext{
 		Tasks: make(map[string]fi.Task),
 	}
@@ -36,10 +36,10 @@ type LogrotateBuilder struct {
 var _ fi.ModelBuilder = &LogrotateBuilder{}
 
 func (b *LogrotateBuilder) Build(c *fi.ModelBuilderContext) error {
-	if b.Distribution == distros.DistributionContainerOS {
+	if b.Distribution.Family() == distros.FamilyContainerOS {
 		glog.Infof("Detected ContainerOS; won't install logrotate")
 		return nil
-	} else if b.Distribution == distros.DistributionCoreOS {
+	} else if b.Distribution.Family() == distros.FamilyCoreOS {
 		glog.Infof("Detected CoreOS; won't install logrotate")
 	} else {
 		c.AddTask(&nodetasks.Package{Name: "logrotate"})
@@ -88,8 +88,8 @@ func (b *LogrotateBuilder) Build(c *fi.ModelBuilderContext) error {
 
 // addLogrotateService creates a logrotate systemd task to act as target for the timer, if one is needed
 func (b *LogrotateBuilder) addLogrotateService(c *fi.ModelBuilderContext) error {
-	switch b.Distribution {
-	case distros.DistributionCoreOS, distros.DistributionContainerOS:
+	switch b.Distribution.Family() {
+	case distros.FamilyCoreOS, distros.FamilyContainerOS:
 		// logrotate service already exists
 		return nil
 	}
@@ -0,0 +1,194 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/systemd"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+const (
+	// devicePluginDir is where the kubelet listens for device plugins to register
+	devicePluginDir = "/var/lib/kubelet/device-plugins"
+	// kubeletDir is the kubelet root directory, which holds the plugin and pod volume directories used by CSI drivers
+	kubeletDir = "/var/lib/kubelet"
+	// defaultFlexVolumeDir is the default directory in which the kubelet looks for flexvolume drivers
+	defaultFlexVolumeDir = "/usr/libexec/kubernetes/kubelet-plugins/volume/exec/"
+	// defaultNodeAddonHealthCheckTimeout is how long we wait for a node add-on to become healthy, if not configured
+	defaultNodeAddonHealthCheckTimeout = 5 * time.Minute
+)
+
+// NodeAddonsBuilder installs the node add-ons of the instance group.  Binaries are installed where the kubelet finds them,
+// so they are in place before the kubelet starts; containers are run as systemd services once the kubelet has started.
+type NodeAddonsBuilder struct {
+	*NodeupModelContext
+}
+
+var _ fi.ModelBuilder = &NodeAddonsBuilder{}
+
+// Build is responsible for installing the node add-ons
+func (b *NodeAddonsBuilder) Build(c *fi.ModelBuilderContext) error {
+	if b.InstanceGroup == nil {
+		return nil
+	}
+
+	for i := range b.InstanceGroup.Spec.NodeAddons {
+		addon := &b.InstanceGroup.Spec.NodeAddons[i]
+
+		if addon.Source != "" {
+			if err := b.addBinary(c, addon); err != nil {
+				return err
+			}
+		}
+		if addon.Image != "" {
+			c.AddTask(b.buildService(addon))
+		}
+		if addon.HealthCheck != nil {
+			c.AddTask(buildNodeAddonHealthCheck(addon))
+		}
+	}
+
+	return nil
+}
+
+// addBinary installs the binary of the add-on, which nodeup has downloaded and verified with the other assets
+func (b *NodeAddonsBuilder) addBinary(c *fi.ModelBuilderContext, addon *kops.NodeAddonSpec) error {
+	u, err := url.Parse(addon.Source)
+	if err != nil {
+		return fmt.Errorf("unable to parse source %q of node add-on %q: %v", addon.Source, addon.Name, err)
+	}
+
+	// The source may have been remapped to a file repository, but the path is the same
+	assetName := path.Base(u.Path)
+	asset, err := b.Assets.Find(assetName, u.Path)
+	if err != nil {
+		return fmt.Errorf("error trying to locate asset %q for node add-on %q: %v", assetName, addon.Name, err)
+	}
+	if asset == nil {
+		return fmt.Errorf("unable to locate asset %q for node add-on %q", assetName, addon.Name)
+	}
+
+	var p string
+	switch addon.Type {
+	case kops.NodeAddonTypeCNIPlugin:
+		p = path.Join(b.CNIBinDir(), addon.Name)
+	case kops.NodeAddonTypeKubeletPlugin:
+		// flexvolume drivers are found at <vendor~driver>/<driver>
+		driver := addon.Name[strings.Index(addon.Name, "~")+1:]
+		p = path.Join(b.flexVolumeDir(), addon.Name, driver)
+	default:
+		return fmt.Errorf("node add-on %q of type %q cannot be installed from a binary", addon.Name, addon.Type)
+	}
+
+	c.AddTask(&nodetasks.File{
+		Path:     p,
+		Contents: asset,
+		Type:     nodetasks.FileType_File,
+		Mode:     s("0755"),
+	})
+	return nil
+}
+
+// flexVolumeDir returns the directory in which the kubelet looks for flexvolume drivers
+func (b *NodeAddonsBuilder) flexVolumeDir() string {
+	kubelets := []*kops.KubeletConfigSpec{b.InstanceGroup.Spec.Kubelet, b.Cluster.Spec.Kubelet}
+	if b.IsMaster {
+		kubelets = []*kops.KubeletConfigSpec{b.InstanceGroup.Spec.Kubelet, b.Cluster.Spec.MasterKubelet}
+	}
+	for _, kubelet := range kubelets {
+		if kubelet != nil && kubelet.VolumePluginDirectory != "" {
+			return kubelet.VolumePluginDirectory
+		}
+	}
+	return defaultFlexVolumeDir
+}
+
+// nodeAddonUnitName returns the name of the systemd unit for an add-on; '~' is not allowed in unit names
+func nodeAddonUnitName(addon *kops.NodeAddonSpec) string {
+	return "node-addon-" + strings.Replace(addon.Name, "~", "-", -1)
+}
+
+// buildService builds the systemd service which runs the container of the add-on
+func (b *NodeAddonsBuilder) buildService(addon *kops.NodeAddonSpec) *nodetasks.Service {
+	name := nodeAddonUnitName(addon)
+
+	dockerArgs := []string{
+		"/usr/bin/docker", "run",
+		"--rm",
+		"--name", name,
+		"--net=host",
+		"--privileged",
+	}
+	switch addon.Type {
+	case kops.NodeAddonTypeDevicePlugin:
+		dockerArgs = append(dockerArgs, "-v", devicePluginDir+":"+devicePluginDir)
+	case kops.NodeAddonTypeKubeletPlugin:
+		// the mounts made by the driver must propagate to the kubelet
+		dockerArgs = append(dockerArgs, "-v", kubeletDir+":"+kubeletDir+":rshared", "-v", "/dev:/dev")
+	}
+	dockerArgs = append(dockerArgs, addon.Image)
+	dockerArgs = append(dockerArgs, addon.Args...)
+
+	manifest := &systemd.Manifest{}
+	manifest.Set("Unit", "Description", fmt.Sprintf("Kops node add-on %s %s", addon.Name, addon.Version))
+	manifest.Set("Unit", "Documentation", "https://github.com/kubernetes/kops")
+	manifest.Set("Unit", "Requires", "docker.service")
+	manifest.Set("Unit", "After", "docker.service")
+	// the add-on registers with the kubelet, so is started once the kubelet has started
+	manifest.Set("Unit", "Wants", "kubelet.service")
+	manifest.Set("Unit", "After", "kubelet.service")
+
+	manifest.Set("Service", "ExecStartPre", "-"+systemd.EscapeCommand([]string{"/usr/bin/docker", "rm", "-f", name}))
+	manifest.Set("Service", "ExecStartPre", systemd.EscapeCommand([]string{"/usr/bin/docker", "pull", addon.Image}))
+	manifest.Set("Service", "ExecStart", systemd.EscapeCommand(dockerArgs))
+	manifest.Set("Service", "ExecStop", systemd.EscapeCommand([]string{"/usr/bin/docker", "stop", name}))
+	manifest.Set("Service", "Restart", "always")
+	manifest.Set("Service", "RestartSec", "10s")
+
+	manifest.Set("Install", "WantedBy", "multi-user.target")
+
+	service := &nodetasks.Service{
+		Name:       name + ".service",
+		Definition: s(manifest.Render()),
+	}
+	service.InitDefaults()
+
+	return service
+}
+
+// buildNodeAddonHealthCheck builds the task which waits for the add-on to become healthy
+func buildNodeAddonHealthCheck(addon *kops.NodeAddonSpec) *nodetasks.HealthCheck {
+	timeout := defaultNodeAddonHealthCheckTimeout
+	if addon.HealthCheck.Timeout != nil {
+		timeout = addon.HealthCheck.Timeout.Duration
+	}
+
+	return &nodetasks.HealthCheck{
+		Name:    nodeAddonUnitName(addon),
+		Command: addon.HealthCheck.Exec,
+		Socket:  addon.HealthCheck.Socket,
+		Timeout: timeout.String(),
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+func TestNodeAddonsBuilder(t *testing.T) {
+	basedir := path.Join("tests/nodeaddons/", "simple")
+
+	nodeUpModelContext, err := LoadModel(basedir)
+	if err != nil {
+		t.Fatalf("error parsing cluster yaml %q: %v", basedir, err)
+		return
+	}
+
+	tmpdir, err := ioutil.TempDir("", "nodeaddons")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	// nodeup downloads the add-on binaries with the other assets
+	nodeUpModelContext.Assets = fi.NewAssetStore(path.Join(tmpdir, "cache"))
+	for _, asset := range []string{"cni/v1.0.0/example-cni", "flex/v2.1.0/flex"} {
+		p := filepath.Join(tmpdir, "assets", asset)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("error creating asset dir: %v", err)
+		}
+		if err := ioutil.WriteFile(p, []byte(asset), 0755); err != nil {
+			t.Fatalf("error writing asset: %v", err)
+		}
+		hash, err := hashing.HashAlgorithmSHA256.Hash(bytes.NewReader([]byte(asset)))
+		if err != nil {
+			t.Fatalf("error hashing asset: %v", err)
+		}
+		if err := nodeUpModelContext.Assets.Add(hash.Hex() + "@file://" + p); err != nil {
+			t.Fatalf("error adding asset: %v", err)
+		}
+	}
+
+	context := &fi.ModelBuilderContext{
+		Tasks: make(map[string]fi.Task),
+	}
+
+	builder := NodeAddonsBuilder{NodeupModelContext: nodeUpModelContext}
+
+	err = builder.Build(context)
+	if err != nil {
+		t.Fatalf("error from NodeAddonsBuilder Build: %v", err)
+		return
+	}
+
+	ValidateTasks(t, basedir, context)
+}
+
+func TestNodeAddonsBuilderMissingAsset(t *testing.T) {
+	basedir := path.Join("tests/nodeaddons/", "simple")
+
+	nodeUpModelContext, err := LoadModel(basedir)
+	if err != nil {
+		t.Fatalf("error parsing cluster yaml %q: %v", basedir, err)
+		return
+	}
+	nodeUpModelContext.Assets = fi.NewAssetStore("")
+
+	context := &fi.ModelBuilderContext{
+		Tasks: make(map[string]fi.Task),
+	}
+
+	builder := NodeAddonsBuilder{NodeupModelContext: nodeUpModelContext}
+	if err := builder.Build(context); err == nil {
+		t.Fatalf("expected error when the asset of a node add-on was not downloaded")
+	}
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+const (
+	// nodeTuningSysctlPath sorts after 99-k8s-general.conf, so the user's sysctls take precedence over ours on boot
+	nodeTuningSysctlPath        = "/etc/sysctl.d/99-kops-nodetuning.conf"
+	nodeTuningKernelModulesPath = "/etc/modules-load.d/kops-nodetuning.conf"
+	nodeTuningUlimitsPath       = "/etc/systemd/system.conf.d/kops-nodetuning.conf"
+)
+
+// hugepageSizes maps the supported hugepage sizes to the size in kB, as used in /sys/kernel/mm/hugepages
+var hugepageSizes = map[string]int{
+	"2Mi": 2048,
+	"1Gi": 1048576,
+}
+
+// NodeTuningBuilder configures the sysctls, kernel modules, ulimits and hugepages from the NodeTuning
+// of the cluster and the instance group, along with those the container runtime needs.
+// Each setting is applied to the running node, and persisted for boot.
+type NodeTuningBuilder struct {
+	*NodeupModelContext
+}
+
+var _ fi.ModelBuilder = &NodeTuningBuilder{}
+
+// Build is responsible for building the node tuning tasks
+func (b *NodeTuningBuilder) Build(c *fi.ModelBuilderContext) error {
+	var specs []*kops.NodeTuningSpec
+	specs = append(specs, b.containerRuntimeTuning())
+	specs = append(specs, b.Cluster.Spec.NodeTuning)
+	if b.InstanceGroup != nil {
+		specs = append(specs, b.InstanceGroup.Spec.NodeTuning)
+	}
+	tuning := mergeNodeTuning(specs...)
+
+	if len(tuning.KernelModules) != 0 {
+		c.AddTask(&nodetasks.File{
+			Path:     nodeTuningKernelModulesPath,
+			Contents: fi.NewStringResource(strings.Join(tuning.KernelModules, "\n") + "\n"),
+			Type:     nodetasks.FileType_File,
+		})
+		for _, module := range tuning.KernelModules {
+			c.AddTask(&nodetasks.KernelModule{Name: module})
+		}
+	}
+
+	if len(tuning.SysctlParameters) != 0 {
+		keys, values, err := parseSysctlParameters(tuning.SysctlParameters)
+		if err != nil {
+			return err
+		}
+
+		lines := []string{"# Sysctls from the nodeTuning of the cluster and instance group", ""}
+		for _, key := range keys {
+			lines = append(lines, key+" = "+values[key])
+			sysctl, err := nodetasks.NewSysctl(key, values[key])
+			if err != nil {
+				return err
+			}
+			c.AddTask(sysctl)
+		}
+		lines = append(lines, "")
+
+		// The KernelParameter tasks set the sysctls now, once the kernel modules are loaded
+		c.AddTask(&nodetasks.File{
+			Path:     nodeTuningSysctlPath,
+			Contents: fi.NewStringResource(strings.Join(lines, "\n")),
+			Type:     nodetasks.FileType_File,
+		})
+	}
+
+	if tuning.Ulimits != nil {
+		lines := []string{"[Manager]"}
+		limits := []struct {
+			name  string
+			value string
+		}{
+			{"DefaultLimitNOFILE", tuning.Ulimits.NoFile},
+			{"DefaultLimitNPROC", tuning.Ulimits.NProc},
+			{"DefaultLimitMEMLOCK", tuning.Ulimits.MemLock},
+			{"DefaultLimitCORE", tuning.Ulimits.Core},
+		}
+		for _, limit := range limits {
+			if limit.value != "" {
+				lines = append(lines, limit.name+"="+limit.value)
+			}
+		}
+		lines = append(lines, "")
+
+		// systemd only reads the default limits when it is (re)executed; services pick them up when they are (re)started
+		c.AddTask(&nodetasks.File{
+			Path:            nodeTuningUlimitsPath,
+			Contents:        fi.NewStringResource(strings.Join(lines, "\n")),
+			Type:            nodetasks.FileType_File,
+			OnChangeExecute: [][]string{{"systemctl", "daemon-reexec"}},
+		})
+	}
+
+	if tuning.Hugepages != nil {
+		pageSize := tuning.Hugepages.PageSize
+		if pageSize == "" {
+			pageSize = "2Mi"
+		}
+		kb, found := hugepageSizes[pageSize]
+		if !found {
+			return fmt.Errorf("unsupported hugepage size %q", pageSize)
+		}
+
+		// nodeup runs on every boot, so this also reserves the hugepages on boot
+		c.AddTask(&nodetasks.KernelParameter{
+			Path:  fmt.Sprintf("/sys/kernel/mm/hugepages/hugepages-%dkB/nr_hugepages", kb),
+			Value: strconv.Itoa(int(tuning.Hugepages.Count)),
+		})
+	}
+
+	return nil
+}
+
+// containerRuntimeTuning returns the settings the container runtime needs, which the user can override.
+// Docker loads br_netfilter itself, but the CRI runtimes don't, and without it bridged pod traffic bypasses iptables
+// (and so the kube-proxy service rules).
+func (b *NodeTuningBuilder) containerRuntimeTuning() *kops.NodeTuningSpec {
+	if b.ContainerRuntime() == kops.ContainerRuntimeDocker {
+		return nil
+	}
+	return &kops.NodeTuningSpec{
+		KernelModules: []string{"br_netfilter"},
+		SysctlParameters: []string{
+			"net.bridge.bridge-nf-call-iptables=1",
+			"net.bridge.bridge-nf-call-ip6tables=1",
+		},
+	}
+}
+
+// mergeNodeTuning combines the NodeTuning specs, with later specs adding to or overriding earlier ones:
+// sysctls and kernel modules are added, ulimits override individually, and hugepages are replaced
+func mergeNodeTuning(specs ...*kops.NodeTuningSpec) *kops.NodeTuningSpec {
+	merged := &kops.NodeTuningSpec{}
+	modules := make(map[string]bool)
+
+	for _, spec := range specs {
+		if spec == nil {
+			continue
+		}
+
+		merged.SysctlParameters = append(merged.SysctlParameters, spec.SysctlParameters...)
+
+		for _, module := range spec.KernelModules {
+			if !modules[module] {
+				modules[module] = true
+				merged.KernelModules = append(merged.KernelModules, module)
+			}
+		}
+
+		if spec.Ulimits != nil {
+			if merged.Ulimits == nil {
+				merged.Ulimits = &kops.UlimitsSpec{}
+			}
+			if spec.Ulimits.NoFile != "" {
+				merged.Ulimits.NoFile = spec.Ulimits.NoFile
+			}
+			if spec.Ulimits.NProc != "" {
+				merged.Ulimits.NProc = spec.Ulimits.NProc
+			}
+			if spec.Ulimits.MemLock != "" {
+				merged.Ulimits.MemLock = spec.Ulimits.MemLock
+			}
+			if spec.Ulimits.Core != "" {
+				merged.Ulimits.Core = spec.Ulimits.Core
+			}
+		}
+
+		if spec.Hugepages != nil {
+			merged.Hugepages = spec.Hugepages
+		}
+	}
+
+	return merged
+}
+
+// parseSysctlParameters parses key=value sysctls, returning the keys in order and the values; a later value for a key overrides an earlier one
+func parseSysctlParameters(parameters []string) ([]string, map[string]string, error) {
+	var keys []string
+	values := make(map[string]string)
+	for _, parameter := range parameters {
+		tokens := strings.SplitN(parameter, "=", 2)
+		if len(tokens) != 2 {
+			return nil, nil, fmt.Errorf("invalid sysctl parameter %q; must be key=value", parameter)
+		}
+		key := strings.TrimSpace(tokens[0])
+		value := strings.TrimSpace(tokens[1])
+		if _, found := values[key]; !found {
+			keys = append(keys, key)
+		}
+		values[key] = value
+	}
+	return keys, values, nil
+}
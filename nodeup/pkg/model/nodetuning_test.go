@@ -0,0 +1,56 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"path"
+	"testing"
+
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func TestNodeTuningBuilder_Simple(t *testing.T) {
+	runNodeTuningBuilderTest(t, "simple")
+}
+
+func TestNodeTuningBuilder_Containerd(t *testing.T) {
+	runNodeTuningBuilderTest(t, "containerd")
+}
+
+func runNodeTuningBuilderTest(t *testing.T, key string) {
+	basedir := path.Join("tests/nodetuning/", key)
+
+	nodeUpModelContext, err := LoadModel(basedir)
+	if err != nil {
+		t.Fatalf("error parsing cluster yaml %q: %v", basedir, err)
+		return
+	}
+
+	context := &fi.ModelBuilderContext{
+		Tasks: make(map[string]fi.Task),
+	}
+
+	builder := NodeTuningBuilder{NodeupModelContext: nodeUpModelContext}
+
+	err = builder.Build(context)
+	if err != nil {
+		t.Fatalf("error from NodeTuningBuilder Build: %v", err)
+		return
+	}
+
+	ValidateTasks(t, basedir, context)
+}
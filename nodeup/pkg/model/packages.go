@@ -30,20 +30,15 @@ type PackagesBuilder struct {
 var _ fi.ModelBuilder = &DockerBuilder{}
 
 func (b *PackagesBuilder) Build(c *fi.ModelBuilderContext) error {
-	// kubelet needs:
-	//   ebtables - kops #1711
-	//   ethtool - kops #1830
-	if b.Distribution.IsDebianFamily() {
-		c.AddTask(&nodetasks.Package{Name: "ebtables"})
-		c.AddTask(&nodetasks.Package{Name: "ethtool"})
-	} else if b.Distribution.IsRHELFamily() {
-		c.AddTask(&nodetasks.Package{Name: "ebtables"})
-		c.AddTask(&nodetasks.Package{Name: "ethtool"})
-		c.AddTask(&nodetasks.Package{Name: "socat"})
-	} else {
-		// Hopefully it's already installed
+	// kubelet needs ebtables (kops #1711) and ethtool (kops #1830); the package names are defined by the distribution
+	packages := b.Distribution.Info().DefaultPackages
+	if len(packages) == 0 {
+		// Hopefully they're already installed
 		glog.Infof("ebtables package not known for distro %q", b.Distribution)
 	}
+	for _, name := range packages {
+		c.AddTask(&nodetasks.Package{Name: name})
+	}
 
 	return nil
 }
@@ -114,16 +114,6 @@ func (t *ProtokubeBuilder) buildSystemdService() (*nodetasks.Service, error) {
 		"-v", "/run/systemd:/run/systemd",
 	}
 
-	// add kubectl only if a master
-	// path changes depending on distro, and always mount it on /opt/kops/bin
-	// kubectl is downloaded and installed by other tasks
-	if t.IsMaster {
-		dockerArgs = append(dockerArgs, []string{
-			"-v", t.KubectlPath() + ":/opt/kops/bin:ro",
-			"--env", "PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin:/opt/kops/bin",
-		}...)
-	}
-
 	dockerArgs = append(dockerArgs, []string{
 		"--net=host",
 		"--pid=host",   // Needed for mounting in a container (when using systemd mounting?)
@@ -195,7 +185,7 @@ type ProtokubeFlags struct {
 	ApplyTaints *bool    `json:"applyTaints,omitempty" flag:"apply-taints"`
 	Channels    []string `json:"channels,omitempty" flag:"channels"`
 	Cloud       *string  `json:"cloud,omitempty" flag:"cloud"`
-	// ClusterID flag is required only for vSphere cloud type, to pass cluster id information to protokube. AWS and GCE workflows ignore this flag.
+	// ClusterID flag is required for vSphere, and for gossip on clouds other than AWS and GCE, to pass cluster id information to protokube. AWS and GCE workflows ignore this flag.
 	ClusterID                 *string  `json:"cluster-id,omitempty" flag:"cluster-id"`
 	Containerized             *bool    `json:"containerized,omitempty" flag:"containerized"`
 	DNSInternalSuffix         *string  `json:"dnsInternalSuffix,omitempty" flag:"dns-internal-suffix"`
@@ -206,6 +196,11 @@ type ProtokubeFlags struct {
 	EtcdImage                 *string  `json:"etcd-image,omitempty" flag:"etcd-image"`
 	EtcdLeaderElectionTimeout *string  `json:"etcd-election-timeout,omitempty" flag:"etcd-election-timeout"`
 	EtcdHearbeatInterval      *string  `json:"etcd-heartbeat-interval,omitempty" flag:"etcd-heartbeat-interval"`
+	GossipListen              *string  `json:"gossip-listen,omitempty" flag:"gossip-listen"`
+	GossipListenSecondary     *string  `json:"gossip-listen-secondary,omitempty" flag:"gossip-listen-secondary"`
+	GossipProtocol            *string  `json:"gossip-protocol,omitempty" flag:"gossip-protocol"`
+	GossipProtocolSecondary   *string  `json:"gossip-protocol-secondary,omitempty" flag:"gossip-protocol-secondary"`
+	GossipSeedSRV             *string  `json:"gossip-seed-srv,omitempty" flag:"gossip-seed-srv"`
 	InitializeRBAC            *bool    `json:"initializeRBAC,omitempty" flag:"initialize-rbac"`
 	LogLevel                  *int32   `json:"logLevel,omitempty" flag:"v"`
 	Master                    *bool    `json:"master,omitempty" flag:"master"`
@@ -271,7 +266,7 @@ func (t *ProtokubeBuilder) ProtokubeFlags(k8sVersion semver.Version) (*Protokube
 		image = remapped
 	}
 
-	f.EtcdImage = s(image)
+	f.EtcdImage = s(t.Architecture.Image(image))
 
 	// initialize rbac on Kubernetes >= 1.6 and master
 	if k8sVersion.Major == 1 && k8sVersion.Minor >= 6 {
@@ -315,6 +310,23 @@ func (t *ProtokubeBuilder) ProtokubeFlags(k8sVersion semver.Version) (*Protokube
 		internalSuffix := t.Cluster.Spec.MasterInternalName
 		internalSuffix = strings.TrimPrefix(internalSuffix, "api.")
 		f.DNSInternalSuffix = fi.String(internalSuffix)
+
+		if g := t.Cluster.Spec.GossipConfig; g != nil {
+			f.GossipProtocol = g.Protocol
+			f.GossipListen = g.Listen
+			f.GossipSeedSRV = g.SeedSRV
+			if g.Secondary != nil {
+				f.GossipProtocolSecondary = g.Secondary.Protocol
+				f.GossipListenSecondary = g.Secondary.Listen
+			}
+		}
+
+		// Only AWS and GCE determine the cluster id from the cloud; the other clouds also need it to discover the seeds
+		switch kops.CloudProviderID(t.Cluster.Spec.CloudProvider) {
+		case kops.CloudProviderAWS, kops.CloudProviderGCE:
+		default:
+			f.ClusterID = fi.String(t.Cluster.ObjectMeta.Name)
+		}
 	}
 
 	if t.Cluster.Spec.CloudProvider != "" {
@@ -0,0 +1,94 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azureblob
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/acls"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/values"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// azureBlobPublicAclStrategy is the AclStrategy for objects that are written with public read access.
+// This strategy is used by custom file assets, which nodes download anonymously.
+type azureBlobPublicAclStrategy struct {
+}
+
+var _ acls.ACLStrategy = &azureBlobPublicAclStrategy{}
+
+// GetACL returns public blob read access for paths in the container of the file repository.
+// Azure Blob Storage only supports access levels on containers, so we never return it for the container of the state store.
+func (s *azureBlobPublicAclStrategy) GetACL(p vfs.Path, cluster *kops.Cluster) (vfs.ACL, error) {
+	if cluster.Spec.Assets == nil || cluster.Spec.Assets.FileRepository == nil {
+		return nil, nil
+	}
+
+	azurePath, ok := p.(*vfs.AzureBlobPath)
+	if !ok {
+		return nil, nil
+	}
+
+	fileRepository := values.StringValue(cluster.Spec.Assets.FileRepository)
+	repositoryContainer, err := containerForURL(fileRepository)
+	if err != nil {
+		return nil, err
+	}
+	if repositoryContainer != azurePath.Container() {
+		glog.V(8).Infof("path %q is not inside the file repository %q, not setting public access", p, fileRepository)
+		return nil, nil
+	}
+
+	if strings.HasPrefix(cluster.Spec.ConfigStore, "azureblob://") {
+		stateContainer, err := containerForURL(cluster.Spec.ConfigStore)
+		if err != nil {
+			return nil, err
+		}
+		if stateContainer == azurePath.Container() {
+			glog.V(8).Infof("path %q is inside the container of the config store %q, not setting public access", p, cluster.Spec.ConfigStore)
+			return nil, nil
+		}
+	}
+
+	return &vfs.AzureBlobACL{
+		PublicAccess: "blob",
+	}, nil
+}
+
+// containerForURL returns the container of an azureblob://<container>/<path> URL,
+// or of an https://<account>.blob.core.windows.net/<container>/<path> URL
+func containerForURL(s string) (string, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse: %q", s)
+	}
+
+	if u.Scheme == "azureblob" {
+		return u.Host, nil
+	}
+
+	tokens := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 2)
+	return tokens[0], nil
+}
+
+func Register() {
+	acls.RegisterPlugin("k8s.io/kops/acl/azureblob", &azureBlobPublicAclStrategy{})
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azureblob
+
+import (
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/values"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func Test_Strategy(t *testing.T) {
+	context := &vfs.VFSContext{}
+	s3Path, err := context.BuildVfsPath("s3://assets/nodeup")
+	if err != nil {
+		t.Fatalf("unable to create path: %v", err)
+	}
+
+	grid := []struct {
+		Path           vfs.Path
+		FileRepository string
+		ConfigStore    string
+		Public         bool
+	}{
+		{
+			Path:           vfs.NewAzureBlobPath(nil, "assets", "kubernetes/nodeup"),
+			FileRepository: "https://myaccount.blob.core.windows.net/assets/kubernetes",
+			ConfigStore:    "azureblob://state/cluster",
+			Public:         true,
+		},
+		{
+			Path:           vfs.NewAzureBlobPath(nil, "assets", "kubernetes/nodeup"),
+			FileRepository: "azureblob://assets/kubernetes",
+			ConfigStore:    "s3://my_state_store/cluster",
+			Public:         true,
+		},
+		{
+			// Never make the container of the state store public
+			Path:           vfs.NewAzureBlobPath(nil, "state", "assets/nodeup"),
+			FileRepository: "https://myaccount.blob.core.windows.net/state/assets",
+			ConfigStore:    "azureblob://state/cluster",
+			Public:         false,
+		},
+		{
+			Path:           vfs.NewAzureBlobPath(nil, "other", "nodeup"),
+			FileRepository: "https://myaccount.blob.core.windows.net/assets",
+			ConfigStore:    "azureblob://state/cluster",
+			Public:         false,
+		},
+		{
+			Path:           s3Path,
+			FileRepository: "https://myaccount.blob.core.windows.net/assets",
+			ConfigStore:    "azureblob://state/cluster",
+			Public:         false,
+		},
+	}
+
+	for _, g := range grid {
+		cluster := &kops.Cluster{
+			Spec: kops.ClusterSpec{
+				ConfigStore: g.ConfigStore,
+				Assets: &kops.Assets{
+					FileRepository: values.String(g.FileRepository),
+				},
+			},
+		}
+
+		s := &azureBlobPublicAclStrategy{}
+		acl, err := s.GetACL(g.Path, cluster)
+		if err != nil {
+			t.Errorf("error getting ACL for %s: %v", g.Path, err)
+			continue
+		}
+
+		if !g.Public {
+			if acl != nil {
+				t.Errorf("unexpected ACL for %s with file repository %q: %v", g.Path, g.FileRepository, acl)
+			}
+			continue
+		}
+
+		azureACL, ok := acl.(*vfs.AzureBlobACL)
+		if !ok || azureACL.PublicAccess != "blob" {
+			t.Errorf("expected public blob ACL for %s with file repository %q, got %v", g.Path, g.FileRepository, acl)
+		}
+	}
+}
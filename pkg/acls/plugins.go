@@ -32,6 +32,9 @@ func GetACL(p vfs.Path, cluster *kops.Cluster) (vfs.ACL, error) {
 	strategiesMutex.Lock()
 	defer strategiesMutex.Unlock()
 
+	// Strategies inspect the concrete path type, so look through any caching layer
+	p = vfs.Unwrap(p)
+
 	for k, strategy := range strategies {
 		acl, err := strategy.GetACL(p, cluster)
 		if err != nil {
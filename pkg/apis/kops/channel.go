@@ -63,6 +63,9 @@ type KopsVersionSpec struct {
 
 	// KubernetesVersion is the default version of kubernetes to use with this kops version e.g. for new clusters
 	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+
+	// Architecture is the machine architecture of the image; images without an architecture are amd64
+	Architecture string `json:"architecture,omitempty"`
 }
 
 type KubernetesVersionSpec struct {
@@ -80,6 +83,9 @@ type ChannelImageSpec struct {
 	Name string `json:"name,omitempty"`
 
 	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+
+	// Architecture is the machine architecture of the image; images without an architecture are amd64
+	Architecture string `json:"architecture,omitempty"`
 }
 
 // LoadChannel loads a Channel object from the specified VFS location
@@ -256,13 +262,20 @@ const CloudProviderVSphere CloudProviderID = "vsphere"
 const CloudProviderOpenstack CloudProviderID = "openstack"
 
 // FindImage returns the image for the cloudprovider, or nil if none found
-func (c *Channel) FindImage(provider CloudProviderID, kubernetesVersion semver.Version) *ChannelImageSpec {
+func (c *Channel) FindImage(provider CloudProviderID, kubernetesVersion semver.Version, architecture string) *ChannelImageSpec {
 	var matches []*ChannelImageSpec
 
 	for _, image := range c.Spec.Images {
 		if image.ProviderID != string(provider) {
 			continue
 		}
+		imageArchitecture := image.Architecture
+		if imageArchitecture == "" {
+			imageArchitecture = "amd64"
+		}
+		if imageArchitecture != architecture {
+			continue
+		}
 		if image.KubernetesVersion != "" {
 			versionRange, err := semver.ParseRange(image.KubernetesVersion)
 			if err != nil {
@@ -279,7 +292,7 @@ func (c *Channel) FindImage(provider CloudProviderID, kubernetesVersion semver.V
 	}
 
 	if len(matches) == 0 {
-		glog.V(2).Infof("No matching images in channel for cloudprovider %q", provider)
+		glog.V(2).Infof("No matching images in channel for cloudprovider %q and architecture %q", provider, architecture)
 		return nil
 	}
 
@@ -152,6 +152,10 @@ type KubeletConfigSpec struct {
 	EnforceNodeAllocatable string `json:"enforceNodeAllocatable,omitempty" flag:"enforce-node-allocatable"`
 	// RuntimeRequestTimeout is timeout for runtime requests on - pull, logs, exec and attach
 	RuntimeRequestTimeout *metav1.Duration `json:"runtimeRequestTimeout,omitempty" flag:"runtime-request-timeout"`
+	// ContainerRuntime is the container runtime the kubelet uses: docker, or remote for a CRI runtime such as containerd or CRI-O
+	ContainerRuntime string `json:"containerRuntime,omitempty" flag:"container-runtime"`
+	// ContainerRuntimeEndpoint is the endpoint of the remote runtime service, e.g. unix:///run/containerd/containerd.sock
+	ContainerRuntimeEndpoint string `json:"containerRuntimeEndpoint,omitempty" flag:"container-runtime-endpoint"`
 	// VolumeStatsAggPeriod is the interval for kubelet to calculate and cache the volume disk usage for all pods and volumes
 	VolumeStatsAggPeriod *metav1.Duration `json:"volumeStatsAggPeriod,omitempty" flag:"volume-stats-agg-period"`
 	// Tells the Kubelet to fail to start if swap is enabled on the node.
@@ -78,6 +78,9 @@ type InstanceGroupSpec struct {
 	MaxSize *int32 `json:"maxSize,omitempty"`
 	// MachineType is the instance class
 	MachineType string `json:"machineType,omitempty"`
+	// Architecture is the machine architecture of the instances, e.g. amd64 or arm64.
+	// If not set it is determined from the machine type, defaulting to amd64.
+	Architecture string `json:"architecture,omitempty"`
 	// RootVolumeSize is the size of the EBS root volume to use, in GB
 	RootVolumeSize *int32 `json:"rootVolumeSize,omitempty"`
 	// RootVolumeType is the type of the EBS root volume to use (e.g. gp2)
@@ -110,6 +113,19 @@ type InstanceGroupSpec struct {
 	Tenancy string `json:"tenancy,omitempty"`
 	// Kubelet overrides kubelet config from the ClusterSpec
 	Kubelet *KubeletConfigSpec `json:"kubelet,omitempty"`
+	// ContainerRuntime overrides the container runtime from the ClusterSpec: docker, containerd or cri-o
+	ContainerRuntime string `json:"containerRuntime,omitempty"`
+	// NodeBundle is an offline bundle of the files and packages nodeup installs, built with kops toolbox build-node-bundle.
+	// When set, nodeup installs everything from the bundle, and does not download from the internet.
+	NodeBundle *NodeBundleSpec `json:"nodeBundle,omitempty"`
+	// NodeTuning configures the kernel and systemd on the nodes, adding to or overriding the NodeTuning from the ClusterSpec
+	NodeTuning *NodeTuningSpec `json:"nodeTuning,omitempty"`
+	// HostFirewall configures the firewall on the hosts, adding rules to or overriding the HostFirewall from the ClusterSpec
+	HostFirewall *HostFirewallSpec `json:"hostFirewall,omitempty"`
+	// NodeAgent configures the nodeup agent on the instances, overriding the NodeAgent from the ClusterSpec
+	NodeAgent *NodeAgentSpec `json:"nodeAgent,omitempty"`
+	// NodeAddons are plugins for the kubelet installed on the instances, such as CNI, volume and device plugins
+	NodeAddons []NodeAddonSpec `json:"nodeAddons,omitempty"`
 	// Taints indicates the kubernetes taints for nodes in this group
 	Taints []string `json:"taints,omitempty"`
 	// AdditionalUserData is any aditional user-data to be passed to the host
@@ -120,6 +136,14 @@ type InstanceGroupSpec struct {
 	DetailedInstanceMonitoring *bool `json:"detailedInstanceMonitoring,omitempty"`
 }
 
+// NodeBundleSpec is the location of an offline node bundle
+type NodeBundleSpec struct {
+	// Location is the path of the bundle, in the state store or the file repository
+	Location string `json:"location,omitempty"`
+	// Hash is the sha256 hash of the bundle
+	Hash string `json:"hash,omitempty"`
+}
+
 // UserData defines a user-data section
 type UserData struct {
 	// Name is the name of the user-data
@@ -48,3 +48,27 @@ func FindZonesForInstanceGroup(c *kops.Cluster, ig *kops.InstanceGroup) ([]strin
 	}
 	return zones.List(), nil
 }
+
+// ContainerRuntime returns the container runtime of the instance group, which defaults to the runtime of the cluster, and then to docker
+func ContainerRuntime(c *kops.Cluster, ig *kops.InstanceGroup) string {
+	if ig != nil && ig.Spec.ContainerRuntime != "" {
+		return ig.Spec.ContainerRuntime
+	}
+	if c.Spec.ContainerRuntime != "" {
+		return c.Spec.ContainerRuntime
+	}
+	return kops.ContainerRuntimeDocker
+}
+
+// UsesContainerRuntime returns true if any of the instance groups run the container runtime
+func UsesContainerRuntime(c *kops.Cluster, groups []*kops.InstanceGroup, runtime string) bool {
+	if len(groups) == 0 {
+		return ContainerRuntime(c, nil) == runtime
+	}
+	for _, ig := range groups {
+		if ContainerRuntime(c, ig) == runtime {
+			return true
+		}
+	}
+	return false
+}
@@ -169,3 +169,65 @@ func Test_FindZonesForInstanceGroup(t *testing.T) {
 		}
 	}
 }
+
+// Test_ContainerRuntime tests ContainerRuntime and UsesContainerRuntime
+func Test_ContainerRuntime(t *testing.T) {
+	nodes := &kops.InstanceGroup{}
+	containerdNodes := &kops.InstanceGroup{
+		Spec: kops.InstanceGroupSpec{
+			ContainerRuntime: kops.ContainerRuntimeContainerd,
+		},
+	}
+
+	grid := []struct {
+		cluster  *kops.Cluster
+		groups   []*kops.InstanceGroup
+		expected string
+		uses     map[string]bool
+	}{
+		{
+			cluster:  &kops.Cluster{},
+			groups:   []*kops.InstanceGroup{nodes},
+			expected: kops.ContainerRuntimeDocker,
+			uses:     map[string]bool{kops.ContainerRuntimeDocker: true, kops.ContainerRuntimeContainerd: false},
+		},
+		{
+			cluster:  &kops.Cluster{Spec: kops.ClusterSpec{ContainerRuntime: kops.ContainerRuntimeCRIO}},
+			groups:   []*kops.InstanceGroup{nodes},
+			expected: kops.ContainerRuntimeCRIO,
+			uses:     map[string]bool{kops.ContainerRuntimeDocker: false, kops.ContainerRuntimeCRIO: true},
+		},
+		{
+			cluster:  &kops.Cluster{Spec: kops.ClusterSpec{ContainerRuntime: kops.ContainerRuntimeCRIO}},
+			groups:   []*kops.InstanceGroup{containerdNodes},
+			expected: kops.ContainerRuntimeContainerd,
+			uses:     map[string]bool{kops.ContainerRuntimeCRIO: false, kops.ContainerRuntimeContainerd: true},
+		},
+		{
+			cluster:  &kops.Cluster{},
+			groups:   []*kops.InstanceGroup{nodes, containerdNodes},
+			expected: kops.ContainerRuntimeDocker,
+			uses:     map[string]bool{kops.ContainerRuntimeDocker: true, kops.ContainerRuntimeContainerd: true},
+		},
+		{
+			cluster:  &kops.Cluster{Spec: kops.ClusterSpec{ContainerRuntime: kops.ContainerRuntimeContainerd}},
+			expected: kops.ContainerRuntimeContainerd,
+			uses:     map[string]bool{kops.ContainerRuntimeDocker: false, kops.ContainerRuntimeContainerd: true},
+		},
+	}
+	for i, g := range grid {
+		var ig *kops.InstanceGroup
+		if len(g.groups) != 0 {
+			ig = g.groups[0]
+		}
+		actual := ContainerRuntime(g.cluster, ig)
+		if actual != g.expected {
+			t.Errorf("unexpected runtime for %d: actual=%q, expected=%q", i, actual, g.expected)
+		}
+		for runtime, expected := range g.uses {
+			if actual := UsesContainerRuntime(g.cluster, g.groups, runtime); actual != expected {
+				t.Errorf("unexpected UsesContainerRuntime(%q) for %d: actual=%v, expected=%v", runtime, i, actual, expected)
+			}
+		}
+	}
+}
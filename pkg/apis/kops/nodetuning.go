@@ -0,0 +1,50 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kops
+
+// NodeTuningSpec configures the kernel and systemd on the nodes
+type NodeTuningSpec struct {
+	// SysctlParameters are sysctls to set, in the form key=value, e.g. net.ipv4.tcp_keepalive_time=600
+	SysctlParameters []string `json:"sysctlParameters,omitempty"`
+	// KernelModules are kernel modules to load, now and on every boot
+	KernelModules []string `json:"kernelModules,omitempty"`
+	// Ulimits are the default resource limits for systemd services
+	Ulimits *UlimitsSpec `json:"ulimits,omitempty"`
+	// Hugepages reserves hugepages
+	Hugepages *HugepagesSpec `json:"hugepages,omitempty"`
+}
+
+// UlimitsSpec sets the systemd default resource limits (DefaultLimitNOFILE etc.)
+// Each value is a number, infinity, or soft:hard
+type UlimitsSpec struct {
+	// NoFile is the maximum number of open files
+	NoFile string `json:"nofile,omitempty"`
+	// NProc is the maximum number of processes
+	NProc string `json:"nproc,omitempty"`
+	// MemLock is the maximum locked-in-memory address space, in bytes
+	MemLock string `json:"memlock,omitempty"`
+	// Core is the maximum size of core files, in bytes
+	Core string `json:"core,omitempty"`
+}
+
+// HugepagesSpec reserves hugepages
+type HugepagesSpec struct {
+	// PageSize is the size of the hugepages: 2Mi (the default) or 1Gi
+	PageSize string `json:"pageSize,omitempty"`
+	// Count is the number of hugepages to reserve
+	Count int32 `json:"count"`
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kops
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Operation records an action (such as an update or a rolling-update) that the kops server runs against a cluster
+type Operation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OperationSpec   `json:"spec,omitempty"`
+	Status OperationStatus `json:"status,omitempty"`
+}
+
+// OperationAction is the action performed by an operation
+type OperationAction string
+
+const (
+	// OperationActionPlan previews the changes that an apply would make, like `kops update cluster`
+	OperationActionPlan OperationAction = "Plan"
+	// OperationActionApply applies the cluster configuration to the cloud, like `kops update cluster --yes`
+	OperationActionApply OperationAction = "Apply"
+	// OperationActionRollingUpdate replaces instances that are out of date, like `kops rolling-update cluster --yes`
+	OperationActionRollingUpdate OperationAction = "RollingUpdate"
+	// OperationActionValidate validates the cluster, like `kops validate cluster`
+	OperationActionValidate OperationAction = "Validate"
+	// OperationActionKubeconfig builds a kubeconfig for the cluster, like `kops export kubecfg`
+	OperationActionKubeconfig OperationAction = "Kubeconfig"
+)
+
+// OperationActions is the list of all the operation actions
+var OperationActions = []OperationAction{
+	OperationActionPlan,
+	OperationActionApply,
+	OperationActionRollingUpdate,
+	OperationActionValidate,
+	OperationActionKubeconfig,
+}
+
+// OperationSpec describes the action requested
+type OperationSpec struct {
+	// ClusterName is the name of the cluster the operation acts on
+	ClusterName string `json:"clusterName,omitempty"`
+	// Action is the action performed
+	Action OperationAction `json:"action,omitempty"`
+	// User is the name of the user that requested the operation
+	User string `json:"user,omitempty"`
+
+	// InstanceGroups restricts a rolling-update to the named instance groups
+	InstanceGroups []string `json:"instanceGroups,omitempty"`
+	// Force replaces instances in a rolling-update even if they are up to date
+	Force bool `json:"force,omitempty"`
+	// CloudOnly performs a rolling-update without draining nodes or validating the cluster
+	CloudOnly bool `json:"cloudOnly,omitempty"`
+}
+
+// OperationPhase is the lifecycle phase of an operation
+type OperationPhase string
+
+const (
+	// OperationPending is the phase of an operation that has been accepted but not yet started
+	OperationPending OperationPhase = "Pending"
+	// OperationRunning is the phase of an operation that is running
+	OperationRunning OperationPhase = "Running"
+	// OperationSucceeded is the phase of an operation that completed successfully
+	OperationSucceeded OperationPhase = "Succeeded"
+	// OperationFailed is the phase of an operation that completed with an error
+	OperationFailed OperationPhase = "Failed"
+)
+
+// OperationStatus reports the progress and the result of an operation
+type OperationStatus struct {
+	// Phase is the lifecycle phase of the operation
+	Phase OperationPhase `json:"phase,omitempty"`
+	// StartTime is the time the operation started running
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the operation succeeded or failed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Message is a human readable description of the result, set when the operation completes
+	Message string `json:"message,omitempty"`
+	// Output is the output of the action, as the equivalent kops command would print it
+	Output string `json:"output,omitempty"`
+	// Owner identifies the kops-server instance running the operation
+	Owner string `json:"owner,omitempty"`
+	// RenewTime is the last time the owner confirmed it is still running the operation.
+	// An unfinished operation whose owner stops renewing it is marked as failed by the other instances.
+	RenewTime *metav1.Time `json:"renewTime,omitempty"`
+}
+
+// IsFinished returns true if the operation has succeeded or failed
+func (s *OperationStatus) IsFinished() bool {
+	return s.Phase == OperationSucceeded || s.Phase == OperationFailed
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type OperationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Operation `json:"items"`
+}
@@ -63,6 +63,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&KeysetList{},
 		&SSHCredential{},
 		&SSHCredentialList{},
+		&Operation{},
+		&OperationList{},
 	)
 	//metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -80,3 +82,6 @@ func (obj *Keyset) GetObjectKind() schema.ObjectKind {
 func (obj *SSHCredential) GetObjectKind() schema.ObjectKind {
 	return &obj.TypeMeta
 }
+func (obj *Operation) GetObjectKind() schema.ObjectKind {
+	return &obj.TypeMeta
+}
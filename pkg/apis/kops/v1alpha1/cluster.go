@@ -130,8 +130,12 @@ type ClusterSpec struct {
 	SSHKeyName string `json:"sshKeyName,omitempty"`
 	// EtcdClusters stores the configuration for each cluster
 	EtcdClusters []*EtcdClusterSpec `json:"etcdClusters,omitempty"`
+	// ContainerRuntime is the container runtime used by the kubelet: docker (the default), containerd or cri-o
+	ContainerRuntime string `json:"containerRuntime,omitempty"`
 	// Component configurations
 	Docker                         *DockerConfig                 `json:"docker,omitempty"`
+	Containerd                     *ContainerdConfig             `json:"containerd,omitempty"`
+	CRIO                           *CRIOConfig                   `json:"crio,omitempty"`
 	KubeDNS                        *KubeDNSConfig                `json:"kubeDNS,omitempty"`
 	KubeAPIServer                  *KubeAPIServerConfig          `json:"kubeAPIServer,omitempty"`
 	KubeControllerManager          *KubeControllerManagerConfig  `json:"kubeControllerManager,omitempty"`
@@ -142,6 +146,7 @@ type ClusterSpec struct {
 	MasterKubelet                  *KubeletConfigSpec            `json:"masterKubelet,omitempty"`
 	CloudConfig                    *CloudConfiguration           `json:"cloudConfig,omitempty"`
 	ExternalDNS                    *ExternalDNSConfig            `json:"externalDns,omitempty"`
+	GossipConfig                   *GossipConfig                 `json:"gossipConfig,omitempty"`
 
 	// Networking configuration
 	Networking *NetworkingSpec `json:"networking,omitempty"`
@@ -155,12 +160,20 @@ type ClusterSpec struct {
 	CloudLabels map[string]string `json:"cloudLabels,omitempty"`
 	// Hooks for custom actions e.g. on first installation
 	Hooks []HookSpec `json:"hooks,omitempty"`
+	// NodeTuning configures the kernel and systemd on all nodes: sysctls, kernel modules, ulimits and hugepages
+	NodeTuning *NodeTuningSpec `json:"nodeTuning,omitempty"`
+	// HostFirewall configures a firewall on all hosts, independent of the cloud security groups
+	HostFirewall *HostFirewallSpec `json:"hostFirewall,omitempty"`
+	// NodeAgent periodically re-runs nodeup on the nodes, applying the changes which are safe to make without replacing the instances
+	NodeAgent *NodeAgentSpec `json:"nodeAgent,omitempty"`
 	// Alternative locations for files and containers
 	Assets *Assets `json:"assets,omitempty"`
 	// IAM field adds control over the IAM security policies applied to resources
 	IAM *IAMSpec `json:"iam,omitempty"`
 	// EncryptionConfig holds the encryption config
 	EncryptionConfig *bool `json:"encryptionConfig,omitempty"`
+	// UploadBootReports controls if nodes upload their nodeup boot reports to the state store, where kops toolbox dump collects them
+	UploadBootReports *bool `json:"uploadBootReports,omitempty"`
 	// Target allows for us to nest extra config for targets such as terraform
 	Target *TargetSpec `json:"target,omitempty"`
 }
@@ -215,6 +228,18 @@ type HookSpec struct {
 	ExecContainer *ExecContainerAction `json:"execContainer,omitempty"`
 	// Manifest is a raw systemd unit file
 	Manifest string `json:"manifest,omitempty"`
+	// Phase is when the hook runs: Boot (the default), AfterKubeletHealthy or BeforeShutdown
+	Phase string `json:"phase,omitempty"`
+	// RunPolicy is how often the hook runs: EveryNodeupRun (the default), OncePerBoot or Once
+	RunPolicy string `json:"runPolicy,omitempty"`
+	// Timeout is the maximum time the hook may run before it is stopped and considered to have failed
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Retries is the number of times a failed execContainer hook is retried
+	Retries int32 `json:"retries,omitempty"`
+	// SuccessExitCodes are the exit codes, other than 0, which indicate the hook succeeded
+	SuccessExitCodes []int32 `json:"successExitCodes,omitempty"`
+	// EnvironmentFromSecrets maps environment variables of the hook to the names of the kops secrets holding their values
+	EnvironmentFromSecrets map[string]string `json:"environmentFromSecrets,omitempty"`
 }
 
 // ExecContainerAction defines an hood action
@@ -297,6 +322,28 @@ type KubeDNSConfig struct {
 	ServerIP string `json:"serverIP,omitempty"`
 }
 
+// GossipConfig configures the gossip used by protokube and the dns-controller in gossip (k8s.local) clusters
+type GossipConfig struct {
+	// Protocol is the gossip protocol: mesh (the default) or memberlist
+	Protocol *string `json:"protocol,omitempty"`
+	// Listen is the address:port on which protokube gossips, 0.0.0.0:3999 by default
+	Listen *string `json:"listen,omitempty"`
+	// Secondary, if set, is a second gossip network which protokube and the dns-controller also join, copying values
+	// between the two; it is used while moving a cluster to another protocol
+	Secondary *GossipConfigSecondary `json:"secondary,omitempty"`
+	// SeedSRV is a DNS SRV record whose targets are the gossip seeds, e.g. _gossip._tcp.example.com.  By default
+	// protokube discovers the seeds from the cloud, or from _gossip._tcp.<cluster name> on vSphere and bare-metal
+	SeedSRV *string `json:"seedSRV,omitempty"`
+}
+
+// GossipConfigSecondary is the gossip network a cluster is moving from or to
+type GossipConfigSecondary struct {
+	// Protocol is the gossip protocol: mesh or memberlist
+	Protocol *string `json:"protocol,omitempty"`
+	// Listen is the address:port on which protokube gossips, 0.0.0.0:4000 by default
+	Listen *string `json:"listen,omitempty"`
+}
+
 // ExternalDNSConfig are options of the dns-controller
 type ExternalDNSConfig struct {
 	// Disable indicates we do not wish to run the dns-controller addon
@@ -305,6 +352,15 @@ type ExternalDNSConfig struct {
 	WatchIngress *bool `json:"watchIngress,omitempty"`
 	// WatchNamespace is namespace to watch, detaults to all (use to control whom can creates dns entries)
 	WatchNamespace string `json:"watchNamespace,omitempty"`
+	// TXTOwnerID, if set, makes the dns-controller record ownership of the names it manages in TXT records with this owner id,
+	// and only change or delete records it owns; use a different id for each cluster sharing a zone
+	TXTOwnerID string `json:"txtOwnerId,omitempty"`
+	// Provider, if set, is the DNS provider the dns-controller (and kops) use instead of the DNS service of the cloud:
+	// rfc2136, cloudflare or azure-dns
+	Provider string `json:"provider,omitempty"`
+	// ProviderConfig is the configuration file of the DNS provider, in gcfg format.  Credentials should not be put here;
+	// they are read from the environment, which is populated from the dns-controller-provider secret in kube-system
+	ProviderConfig string `json:"providerConfig,omitempty"`
 }
 
 // EtcdClusterSpec is the etcd cluster specification
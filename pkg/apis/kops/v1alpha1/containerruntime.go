@@ -0,0 +1,41 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// ContainerdConfig is the configuration for containerd
+type ContainerdConfig struct {
+	// LogLevel is the logging level ("debug", "info", "warn", "error", "fatal", "panic") (default "info")
+	LogLevel *string `json:"logLevel,omitempty" flag:"log-level"`
+	// RegistryMirrors maps a registry (for example docker.io) to the endpoints of its mirrors, which are tried in order
+	RegistryMirrors map[string][]string `json:"registryMirrors,omitempty"`
+	// Version is consumed by the nodeup and used to pick the containerd release
+	Version *string `json:"version,omitempty"`
+}
+
+// CRIOConfig is the configuration for CRI-O
+type CRIOConfig struct {
+	// InsecureRegistries is a list of registries that are accessed without TLS verification
+	InsecureRegistries []string `json:"insecureRegistries,omitempty" flag:"insecure-registry,repeat"`
+	// LogLevel is the logging level ("debug", "info", "warn", "error", "fatal", "panic") (default "error")
+	LogLevel *string `json:"logLevel,omitempty" flag:"log-level"`
+	// Registries is the list of registries searched for images that are not fully qualified
+	Registries []string `json:"registries,omitempty" flag:"registry,repeat"`
+	// StorageDriver is the storage driver to use
+	StorageDriver *string `json:"storageDriver,omitempty" flag:"storage-driver"`
+	// Version is consumed by the nodeup and used to pick the CRI-O package
+	Version *string `json:"version,omitempty"`
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// HostFirewallSpec configures a firewall on the hosts, independent of the cloud security groups.
+// When it is enabled, ingress from outside the cluster is dropped unless a rule allows it.
+type HostFirewallSpec struct {
+	// Enabled turns on the host firewall
+	Enabled *bool `json:"enabled,omitempty"`
+	// Backend is the tool used to program the firewall: iptables or nftables.  If not set, nftables is used where it is installed.
+	Backend string `json:"backend,omitempty"`
+	// Rules allow ingress, in addition to traffic from within the cluster and from the sshAccess, kubernetesApiAccess and nodePortAccess CIDRs
+	Rules []HostFirewallRule `json:"rules,omitempty"`
+}
+
+// HostFirewallRule allows ingress to the hosts
+type HostFirewallRule struct {
+	// Roles are the instance group roles to which the rule applies (Master, Node or Bastion); all roles if empty
+	Roles []InstanceGroupRole `json:"roles,omitempty"`
+	// Protocol is tcp (the default), udp or icmp
+	Protocol string `json:"protocol,omitempty"`
+	// Ports are the destination ports or port ranges, e.g. 9100 or 30000-32767; all ports if empty
+	Ports []string `json:"ports,omitempty"`
+	// CIDRs are the source CIDRs; any source if empty
+	CIDRs []string `json:"cidrs,omitempty"`
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// NodeAddonSpec is a plugin for the kubelet which kops installs on the instances: a CNI plugin, a kubelet (volume) plugin
+// or a device plugin.  Binaries are downloaded and verified like the other assets nodeup installs; containers are run as systemd services.
+type NodeAddonSpec struct {
+	// Name identifies the add-on; it names the installed binary, or the systemd service running the container.
+	// A binary KubeletPlugin is installed as a flexvolume driver, so its name must be of the form vendor~driver.
+	Name string `json:"name,omitempty"`
+	// Type is how the kubelet uses the add-on: CNIPlugin, KubeletPlugin or DevicePlugin
+	Type string `json:"type,omitempty"`
+	// Version is the version of the add-on
+	Version string `json:"version,omitempty"`
+	// Source is the URL of a binary to install; it is used for CNIPlugin and KubeletPlugin add-ons
+	Source string `json:"source,omitempty"`
+	// Hash is the hash of the binary, e.g. sha256:<hex>.  If not set, it is read from the .sha256 or .sha1 file alongside the source.
+	Hash string `json:"hash,omitempty"`
+	// Image is a container image to run on the node; it is used for DevicePlugin and KubeletPlugin add-ons
+	Image string `json:"image,omitempty"`
+	// Args are passed to the container of the add-on; only used with image
+	Args []string `json:"args,omitempty"`
+	// HealthCheck checks the add-on is working once it is installed; nodeup retries until the check passes
+	HealthCheck *NodeAddonHealthCheckSpec `json:"healthCheck,omitempty"`
+}
+
+// NodeAddonHealthCheckSpec checks a node add-on is working.  Exactly one of Exec or Socket must be set.
+type NodeAddonHealthCheckSpec struct {
+	// Exec is a command run on the node, which exits zero when the add-on is healthy
+	Exec []string `json:"exec,omitempty"`
+	// Socket is the path of a unix socket the add-on creates when it is ready, such as a device plugin socket
+	Socket string `json:"socket,omitempty"`
+	// Timeout is how long to wait for the add-on to become healthy; the default is 5 minutes
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
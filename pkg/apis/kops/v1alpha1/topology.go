@@ -42,6 +42,18 @@ type TopologySpec struct {
 
 type DNSSpec struct {
 	Type DNSType `json:"type,omitempty"`
+
+	// AdditionalVPCs are VPCs, other than the cluster VPC, which are associated with the private hosted zone,
+	// so that the private records can be resolved from them (e.g. from a peered VPC)
+	AdditionalVPCs []DNSVPCSpec `json:"additionalVPCs,omitempty"`
+}
+
+// DNSVPCSpec identifies a VPC to associate with a private hosted zone
+type DNSVPCSpec struct {
+	// ID is the id of the VPC
+	ID string `json:"id,omitempty"`
+	// Region is the region of the VPC; defaults to the region of the cluster
+	Region string `json:"region,omitempty"`
 }
 
 type DNSType string
@@ -49,4 +61,7 @@ type DNSType string
 const (
 	DNSTypePublic  DNSType = "Public"
 	DNSTypePrivate DNSType = "Private"
+	// DNSTypeSplitHorizon manages both a public and a private hosted zone with the name of the DNSZone:
+	// internal records are published in the private zone, and external records in the public zone
+	DNSTypeSplitHorizon DNSType = "SplitHorizon"
 )
@@ -51,6 +51,8 @@ func RegisterConversions(scheme *runtime.Scheme) error {
 		Convert_kops_AuthorizationSpec_To_v1alpha1_AuthorizationSpec,
 		Convert_v1alpha1_CNINetworkingSpec_To_kops_CNINetworkingSpec,
 		Convert_kops_CNINetworkingSpec_To_v1alpha1_CNINetworkingSpec,
+		Convert_v1alpha1_CRIOConfig_To_kops_CRIOConfig,
+		Convert_kops_CRIOConfig_To_v1alpha1_CRIOConfig,
 		Convert_v1alpha1_CalicoNetworkingSpec_To_kops_CalicoNetworkingSpec,
 		Convert_kops_CalicoNetworkingSpec_To_v1alpha1_CalicoNetworkingSpec,
 		Convert_v1alpha1_CanalNetworkingSpec_To_kops_CanalNetworkingSpec,
@@ -69,10 +71,14 @@ func RegisterConversions(scheme *runtime.Scheme) error {
 		Convert_kops_ClusterList_To_v1alpha1_ClusterList,
 		Convert_v1alpha1_ClusterSpec_To_kops_ClusterSpec,
 		Convert_kops_ClusterSpec_To_v1alpha1_ClusterSpec,
+		Convert_v1alpha1_ContainerdConfig_To_kops_ContainerdConfig,
+		Convert_kops_ContainerdConfig_To_v1alpha1_ContainerdConfig,
 		Convert_v1alpha1_DNSAccessSpec_To_kops_DNSAccessSpec,
 		Convert_kops_DNSAccessSpec_To_v1alpha1_DNSAccessSpec,
 		Convert_v1alpha1_DNSSpec_To_kops_DNSSpec,
 		Convert_kops_DNSSpec_To_v1alpha1_DNSSpec,
+		Convert_v1alpha1_DNSVPCSpec_To_kops_DNSVPCSpec,
+		Convert_kops_DNSVPCSpec_To_v1alpha1_DNSVPCSpec,
 		Convert_v1alpha1_DockerConfig_To_kops_DockerConfig,
 		Convert_kops_DockerConfig_To_v1alpha1_DockerConfig,
 		Convert_v1alpha1_EgressProxySpec_To_kops_EgressProxySpec,
@@ -93,10 +99,20 @@ func RegisterConversions(scheme *runtime.Scheme) error {
 		Convert_kops_FileAssetSpec_To_v1alpha1_FileAssetSpec,
 		Convert_v1alpha1_FlannelNetworkingSpec_To_kops_FlannelNetworkingSpec,
 		Convert_kops_FlannelNetworkingSpec_To_v1alpha1_FlannelNetworkingSpec,
+		Convert_v1alpha1_GossipConfig_To_kops_GossipConfig,
+		Convert_kops_GossipConfig_To_v1alpha1_GossipConfig,
+		Convert_v1alpha1_GossipConfigSecondary_To_kops_GossipConfigSecondary,
+		Convert_kops_GossipConfigSecondary_To_v1alpha1_GossipConfigSecondary,
 		Convert_v1alpha1_HTTPProxy_To_kops_HTTPProxy,
 		Convert_kops_HTTPProxy_To_v1alpha1_HTTPProxy,
 		Convert_v1alpha1_HookSpec_To_kops_HookSpec,
 		Convert_kops_HookSpec_To_v1alpha1_HookSpec,
+		Convert_v1alpha1_HostFirewallRule_To_kops_HostFirewallRule,
+		Convert_kops_HostFirewallRule_To_v1alpha1_HostFirewallRule,
+		Convert_v1alpha1_HostFirewallSpec_To_kops_HostFirewallSpec,
+		Convert_kops_HostFirewallSpec_To_v1alpha1_HostFirewallSpec,
+		Convert_v1alpha1_HugepagesSpec_To_kops_HugepagesSpec,
+		Convert_kops_HugepagesSpec_To_v1alpha1_HugepagesSpec,
 		Convert_v1alpha1_IAMSpec_To_kops_IAMSpec,
 		Convert_kops_IAMSpec_To_v1alpha1_IAMSpec,
 		Convert_v1alpha1_InstanceGroup_To_kops_InstanceGroup,
@@ -131,6 +147,16 @@ func RegisterConversions(scheme *runtime.Scheme) error {
 		Convert_kops_LoadBalancerAccessSpec_To_v1alpha1_LoadBalancerAccessSpec,
 		Convert_v1alpha1_NetworkingSpec_To_kops_NetworkingSpec,
 		Convert_kops_NetworkingSpec_To_v1alpha1_NetworkingSpec,
+		Convert_v1alpha1_NodeAddonHealthCheckSpec_To_kops_NodeAddonHealthCheckSpec,
+		Convert_kops_NodeAddonHealthCheckSpec_To_v1alpha1_NodeAddonHealthCheckSpec,
+		Convert_v1alpha1_NodeAddonSpec_To_kops_NodeAddonSpec,
+		Convert_kops_NodeAddonSpec_To_v1alpha1_NodeAddonSpec,
+		Convert_v1alpha1_NodeAgentSpec_To_kops_NodeAgentSpec,
+		Convert_kops_NodeAgentSpec_To_v1alpha1_NodeAgentSpec,
+		Convert_v1alpha1_NodeBundleSpec_To_kops_NodeBundleSpec,
+		Convert_kops_NodeBundleSpec_To_v1alpha1_NodeBundleSpec,
+		Convert_v1alpha1_NodeTuningSpec_To_kops_NodeTuningSpec,
+		Convert_kops_NodeTuningSpec_To_v1alpha1_NodeTuningSpec,
 		Convert_v1alpha1_RBACAuthorizationSpec_To_kops_RBACAuthorizationSpec,
 		Convert_kops_RBACAuthorizationSpec_To_v1alpha1_RBACAuthorizationSpec,
 		Convert_v1alpha1_RomanaNetworkingSpec_To_kops_RomanaNetworkingSpec,
@@ -145,6 +171,8 @@ func RegisterConversions(scheme *runtime.Scheme) error {
 		Convert_kops_TargetSpec_To_v1alpha1_TargetSpec,
 		Convert_v1alpha1_TerraformSpec_To_kops_TerraformSpec,
 		Convert_kops_TerraformSpec_To_v1alpha1_TerraformSpec,
+		Convert_v1alpha1_UlimitsSpec_To_kops_UlimitsSpec,
+		Convert_kops_UlimitsSpec_To_v1alpha1_UlimitsSpec,
 		Convert_v1alpha1_UserData_To_kops_UserData,
 		Convert_kops_UserData_To_v1alpha1_UserData,
 		Convert_v1alpha1_WeaveNetworkingSpec_To_kops_WeaveNetworkingSpec,
@@ -392,6 +420,34 @@ func Convert_kops_CNINetworkingSpec_To_v1alpha1_CNINetworkingSpec(in *kops.CNINe
 	return autoConvert_kops_CNINetworkingSpec_To_v1alpha1_CNINetworkingSpec(in, out, s)
 }
 
+func autoConvert_v1alpha1_CRIOConfig_To_kops_CRIOConfig(in *CRIOConfig, out *kops.CRIOConfig, s conversion.Scope) error {
+	out.InsecureRegistries = in.InsecureRegistries
+	out.LogLevel = in.LogLevel
+	out.Registries = in.Registries
+	out.StorageDriver = in.StorageDriver
+	out.Version = in.Version
+	return nil
+}
+
+// Convert_v1alpha1_CRIOConfig_To_kops_CRIOConfig is an autogenerated conversion function.
+func Convert_v1alpha1_CRIOConfig_To_kops_CRIOConfig(in *CRIOConfig, out *kops.CRIOConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha1_CRIOConfig_To_kops_CRIOConfig(in, out, s)
+}
+
+func autoConvert_kops_CRIOConfig_To_v1alpha1_CRIOConfig(in *kops.CRIOConfig, out *CRIOConfig, s conversion.Scope) error {
+	out.InsecureRegistries = in.InsecureRegistries
+	out.LogLevel = in.LogLevel
+	out.Registries = in.Registries
+	out.StorageDriver = in.StorageDriver
+	out.Version = in.Version
+	return nil
+}
+
+// Convert_kops_CRIOConfig_To_v1alpha1_CRIOConfig is an autogenerated conversion function.
+func Convert_kops_CRIOConfig_To_v1alpha1_CRIOConfig(in *kops.CRIOConfig, out *CRIOConfig, s conversion.Scope) error {
+	return autoConvert_kops_CRIOConfig_To_v1alpha1_CRIOConfig(in, out, s)
+}
+
 func autoConvert_v1alpha1_CalicoNetworkingSpec_To_kops_CalicoNetworkingSpec(in *CalicoNetworkingSpec, out *kops.CalicoNetworkingSpec, s conversion.Scope) error {
 	out.CrossSubnet = in.CrossSubnet
 	out.PrometheusMetricsEnabled = in.PrometheusMetricsEnabled
@@ -734,6 +790,7 @@ func autoConvert_v1alpha1_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	} else {
 		out.EtcdClusters = nil
 	}
+	out.ContainerRuntime = in.ContainerRuntime
 	if in.Docker != nil {
 		in, out := &in.Docker, &out.Docker
 		*out = new(kops.DockerConfig)
@@ -743,6 +800,24 @@ func autoConvert_v1alpha1_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	} else {
 		out.Docker = nil
 	}
+	if in.Containerd != nil {
+		in, out := &in.Containerd, &out.Containerd
+		*out = new(kops.ContainerdConfig)
+		if err := Convert_v1alpha1_ContainerdConfig_To_kops_ContainerdConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Containerd = nil
+	}
+	if in.CRIO != nil {
+		in, out := &in.CRIO, &out.CRIO
+		*out = new(kops.CRIOConfig)
+		if err := Convert_v1alpha1_CRIOConfig_To_kops_CRIOConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.CRIO = nil
+	}
 	if in.KubeDNS != nil {
 		in, out := &in.KubeDNS, &out.KubeDNS
 		*out = new(kops.KubeDNSConfig)
@@ -833,6 +908,15 @@ func autoConvert_v1alpha1_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	} else {
 		out.ExternalDNS = nil
 	}
+	if in.GossipConfig != nil {
+		in, out := &in.GossipConfig, &out.GossipConfig
+		*out = new(kops.GossipConfig)
+		if err := Convert_v1alpha1_GossipConfig_To_kops_GossipConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.GossipConfig = nil
+	}
 	if in.Networking != nil {
 		in, out := &in.Networking, &out.Networking
 		*out = new(kops.NetworkingSpec)
@@ -881,6 +965,33 @@ func autoConvert_v1alpha1_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	} else {
 		out.Hooks = nil
 	}
+	if in.NodeTuning != nil {
+		in, out := &in.NodeTuning, &out.NodeTuning
+		*out = new(kops.NodeTuningSpec)
+		if err := Convert_v1alpha1_NodeTuningSpec_To_kops_NodeTuningSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeTuning = nil
+	}
+	if in.HostFirewall != nil {
+		in, out := &in.HostFirewall, &out.HostFirewall
+		*out = new(kops.HostFirewallSpec)
+		if err := Convert_v1alpha1_HostFirewallSpec_To_kops_HostFirewallSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.HostFirewall = nil
+	}
+	if in.NodeAgent != nil {
+		in, out := &in.NodeAgent, &out.NodeAgent
+		*out = new(kops.NodeAgentSpec)
+		if err := Convert_v1alpha1_NodeAgentSpec_To_kops_NodeAgentSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeAgent = nil
+	}
 	if in.Assets != nil {
 		in, out := &in.Assets, &out.Assets
 		*out = new(kops.Assets)
@@ -900,6 +1011,7 @@ func autoConvert_v1alpha1_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 		out.IAM = nil
 	}
 	out.EncryptionConfig = in.EncryptionConfig
+	out.UploadBootReports = in.UploadBootReports
 	if in.Target != nil {
 		in, out := &in.Target, &out.Target
 		*out = new(kops.TargetSpec)
@@ -991,6 +1103,7 @@ func autoConvert_kops_ClusterSpec_To_v1alpha1_ClusterSpec(in *kops.ClusterSpec,
 	} else {
 		out.EtcdClusters = nil
 	}
+	out.ContainerRuntime = in.ContainerRuntime
 	if in.Docker != nil {
 		in, out := &in.Docker, &out.Docker
 		*out = new(DockerConfig)
@@ -1000,6 +1113,24 @@ func autoConvert_kops_ClusterSpec_To_v1alpha1_ClusterSpec(in *kops.ClusterSpec,
 	} else {
 		out.Docker = nil
 	}
+	if in.Containerd != nil {
+		in, out := &in.Containerd, &out.Containerd
+		*out = new(ContainerdConfig)
+		if err := Convert_kops_ContainerdConfig_To_v1alpha1_ContainerdConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Containerd = nil
+	}
+	if in.CRIO != nil {
+		in, out := &in.CRIO, &out.CRIO
+		*out = new(CRIOConfig)
+		if err := Convert_kops_CRIOConfig_To_v1alpha1_CRIOConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.CRIO = nil
+	}
 	if in.KubeDNS != nil {
 		in, out := &in.KubeDNS, &out.KubeDNS
 		*out = new(KubeDNSConfig)
@@ -1090,6 +1221,15 @@ func autoConvert_kops_ClusterSpec_To_v1alpha1_ClusterSpec(in *kops.ClusterSpec,
 	} else {
 		out.ExternalDNS = nil
 	}
+	if in.GossipConfig != nil {
+		in, out := &in.GossipConfig, &out.GossipConfig
+		*out = new(GossipConfig)
+		if err := Convert_kops_GossipConfig_To_v1alpha1_GossipConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.GossipConfig = nil
+	}
 	if in.Networking != nil {
 		in, out := &in.Networking, &out.Networking
 		*out = new(NetworkingSpec)
@@ -1138,6 +1278,33 @@ func autoConvert_kops_ClusterSpec_To_v1alpha1_ClusterSpec(in *kops.ClusterSpec,
 	} else {
 		out.Hooks = nil
 	}
+	if in.NodeTuning != nil {
+		in, out := &in.NodeTuning, &out.NodeTuning
+		*out = new(NodeTuningSpec)
+		if err := Convert_kops_NodeTuningSpec_To_v1alpha1_NodeTuningSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeTuning = nil
+	}
+	if in.HostFirewall != nil {
+		in, out := &in.HostFirewall, &out.HostFirewall
+		*out = new(HostFirewallSpec)
+		if err := Convert_kops_HostFirewallSpec_To_v1alpha1_HostFirewallSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.HostFirewall = nil
+	}
+	if in.NodeAgent != nil {
+		in, out := &in.NodeAgent, &out.NodeAgent
+		*out = new(NodeAgentSpec)
+		if err := Convert_kops_NodeAgentSpec_To_v1alpha1_NodeAgentSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeAgent = nil
+	}
 	if in.Assets != nil {
 		in, out := &in.Assets, &out.Assets
 		*out = new(Assets)
@@ -1157,6 +1324,7 @@ func autoConvert_kops_ClusterSpec_To_v1alpha1_ClusterSpec(in *kops.ClusterSpec,
 		out.IAM = nil
 	}
 	out.EncryptionConfig = in.EncryptionConfig
+	out.UploadBootReports = in.UploadBootReports
 	if in.Target != nil {
 		in, out := &in.Target, &out.Target
 		*out = new(TargetSpec)
@@ -1169,6 +1337,30 @@ func autoConvert_kops_ClusterSpec_To_v1alpha1_ClusterSpec(in *kops.ClusterSpec,
 	return nil
 }
 
+func autoConvert_v1alpha1_ContainerdConfig_To_kops_ContainerdConfig(in *ContainerdConfig, out *kops.ContainerdConfig, s conversion.Scope) error {
+	out.LogLevel = in.LogLevel
+	out.RegistryMirrors = in.RegistryMirrors
+	out.Version = in.Version
+	return nil
+}
+
+// Convert_v1alpha1_ContainerdConfig_To_kops_ContainerdConfig is an autogenerated conversion function.
+func Convert_v1alpha1_ContainerdConfig_To_kops_ContainerdConfig(in *ContainerdConfig, out *kops.ContainerdConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ContainerdConfig_To_kops_ContainerdConfig(in, out, s)
+}
+
+func autoConvert_kops_ContainerdConfig_To_v1alpha1_ContainerdConfig(in *kops.ContainerdConfig, out *ContainerdConfig, s conversion.Scope) error {
+	out.LogLevel = in.LogLevel
+	out.RegistryMirrors = in.RegistryMirrors
+	out.Version = in.Version
+	return nil
+}
+
+// Convert_kops_ContainerdConfig_To_v1alpha1_ContainerdConfig is an autogenerated conversion function.
+func Convert_kops_ContainerdConfig_To_v1alpha1_ContainerdConfig(in *kops.ContainerdConfig, out *ContainerdConfig, s conversion.Scope) error {
+	return autoConvert_kops_ContainerdConfig_To_v1alpha1_ContainerdConfig(in, out, s)
+}
+
 func autoConvert_v1alpha1_DNSAccessSpec_To_kops_DNSAccessSpec(in *DNSAccessSpec, out *kops.DNSAccessSpec, s conversion.Scope) error {
 	return nil
 }
@@ -1189,6 +1381,17 @@ func Convert_kops_DNSAccessSpec_To_v1alpha1_DNSAccessSpec(in *kops.DNSAccessSpec
 
 func autoConvert_v1alpha1_DNSSpec_To_kops_DNSSpec(in *DNSSpec, out *kops.DNSSpec, s conversion.Scope) error {
 	out.Type = kops.DNSType(in.Type)
+	if in.AdditionalVPCs != nil {
+		in, out := &in.AdditionalVPCs, &out.AdditionalVPCs
+		*out = make([]kops.DNSVPCSpec, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_DNSVPCSpec_To_kops_DNSVPCSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.AdditionalVPCs = nil
+	}
 	return nil
 }
 
@@ -1199,6 +1402,17 @@ func Convert_v1alpha1_DNSSpec_To_kops_DNSSpec(in *DNSSpec, out *kops.DNSSpec, s
 
 func autoConvert_kops_DNSSpec_To_v1alpha1_DNSSpec(in *kops.DNSSpec, out *DNSSpec, s conversion.Scope) error {
 	out.Type = DNSType(in.Type)
+	if in.AdditionalVPCs != nil {
+		in, out := &in.AdditionalVPCs, &out.AdditionalVPCs
+		*out = make([]DNSVPCSpec, len(*in))
+		for i := range *in {
+			if err := Convert_kops_DNSVPCSpec_To_v1alpha1_DNSVPCSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.AdditionalVPCs = nil
+	}
 	return nil
 }
 
@@ -1207,6 +1421,28 @@ func Convert_kops_DNSSpec_To_v1alpha1_DNSSpec(in *kops.DNSSpec, out *DNSSpec, s
 	return autoConvert_kops_DNSSpec_To_v1alpha1_DNSSpec(in, out, s)
 }
 
+func autoConvert_v1alpha1_DNSVPCSpec_To_kops_DNSVPCSpec(in *DNSVPCSpec, out *kops.DNSVPCSpec, s conversion.Scope) error {
+	out.ID = in.ID
+	out.Region = in.Region
+	return nil
+}
+
+// Convert_v1alpha1_DNSVPCSpec_To_kops_DNSVPCSpec is an autogenerated conversion function.
+func Convert_v1alpha1_DNSVPCSpec_To_kops_DNSVPCSpec(in *DNSVPCSpec, out *kops.DNSVPCSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_DNSVPCSpec_To_kops_DNSVPCSpec(in, out, s)
+}
+
+func autoConvert_kops_DNSVPCSpec_To_v1alpha1_DNSVPCSpec(in *kops.DNSVPCSpec, out *DNSVPCSpec, s conversion.Scope) error {
+	out.ID = in.ID
+	out.Region = in.Region
+	return nil
+}
+
+// Convert_kops_DNSVPCSpec_To_v1alpha1_DNSVPCSpec is an autogenerated conversion function.
+func Convert_kops_DNSVPCSpec_To_v1alpha1_DNSVPCSpec(in *kops.DNSVPCSpec, out *DNSVPCSpec, s conversion.Scope) error {
+	return autoConvert_kops_DNSVPCSpec_To_v1alpha1_DNSVPCSpec(in, out, s)
+}
+
 func autoConvert_v1alpha1_DockerConfig_To_kops_DockerConfig(in *DockerConfig, out *kops.DockerConfig, s conversion.Scope) error {
 	out.AuthorizationPlugins = in.AuthorizationPlugins
 	out.Bridge = in.Bridge
@@ -1435,6 +1671,9 @@ func autoConvert_v1alpha1_ExternalDNSConfig_To_kops_ExternalDNSConfig(in *Extern
 	out.Disable = in.Disable
 	out.WatchIngress = in.WatchIngress
 	out.WatchNamespace = in.WatchNamespace
+	out.TXTOwnerID = in.TXTOwnerID
+	out.Provider = in.Provider
+	out.ProviderConfig = in.ProviderConfig
 	return nil
 }
 
@@ -1447,6 +1686,9 @@ func autoConvert_kops_ExternalDNSConfig_To_v1alpha1_ExternalDNSConfig(in *kops.E
 	out.Disable = in.Disable
 	out.WatchIngress = in.WatchIngress
 	out.WatchNamespace = in.WatchNamespace
+	out.TXTOwnerID = in.TXTOwnerID
+	out.Provider = in.Provider
+	out.ProviderConfig = in.ProviderConfig
 	return nil
 }
 
@@ -1537,6 +1779,70 @@ func Convert_kops_FlannelNetworkingSpec_To_v1alpha1_FlannelNetworkingSpec(in *ko
 	return autoConvert_kops_FlannelNetworkingSpec_To_v1alpha1_FlannelNetworkingSpec(in, out, s)
 }
 
+func autoConvert_v1alpha1_GossipConfig_To_kops_GossipConfig(in *GossipConfig, out *kops.GossipConfig, s conversion.Scope) error {
+	out.Protocol = in.Protocol
+	out.Listen = in.Listen
+	if in.Secondary != nil {
+		in, out := &in.Secondary, &out.Secondary
+		*out = new(kops.GossipConfigSecondary)
+		if err := Convert_v1alpha1_GossipConfigSecondary_To_kops_GossipConfigSecondary(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Secondary = nil
+	}
+	out.SeedSRV = in.SeedSRV
+	return nil
+}
+
+// Convert_v1alpha1_GossipConfig_To_kops_GossipConfig is an autogenerated conversion function.
+func Convert_v1alpha1_GossipConfig_To_kops_GossipConfig(in *GossipConfig, out *kops.GossipConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha1_GossipConfig_To_kops_GossipConfig(in, out, s)
+}
+
+func autoConvert_kops_GossipConfig_To_v1alpha1_GossipConfig(in *kops.GossipConfig, out *GossipConfig, s conversion.Scope) error {
+	out.Protocol = in.Protocol
+	out.Listen = in.Listen
+	if in.Secondary != nil {
+		in, out := &in.Secondary, &out.Secondary
+		*out = new(GossipConfigSecondary)
+		if err := Convert_kops_GossipConfigSecondary_To_v1alpha1_GossipConfigSecondary(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Secondary = nil
+	}
+	out.SeedSRV = in.SeedSRV
+	return nil
+}
+
+// Convert_kops_GossipConfig_To_v1alpha1_GossipConfig is an autogenerated conversion function.
+func Convert_kops_GossipConfig_To_v1alpha1_GossipConfig(in *kops.GossipConfig, out *GossipConfig, s conversion.Scope) error {
+	return autoConvert_kops_GossipConfig_To_v1alpha1_GossipConfig(in, out, s)
+}
+
+func autoConvert_v1alpha1_GossipConfigSecondary_To_kops_GossipConfigSecondary(in *GossipConfigSecondary, out *kops.GossipConfigSecondary, s conversion.Scope) error {
+	out.Protocol = in.Protocol
+	out.Listen = in.Listen
+	return nil
+}
+
+// Convert_v1alpha1_GossipConfigSecondary_To_kops_GossipConfigSecondary is an autogenerated conversion function.
+func Convert_v1alpha1_GossipConfigSecondary_To_kops_GossipConfigSecondary(in *GossipConfigSecondary, out *kops.GossipConfigSecondary, s conversion.Scope) error {
+	return autoConvert_v1alpha1_GossipConfigSecondary_To_kops_GossipConfigSecondary(in, out, s)
+}
+
+func autoConvert_kops_GossipConfigSecondary_To_v1alpha1_GossipConfigSecondary(in *kops.GossipConfigSecondary, out *GossipConfigSecondary, s conversion.Scope) error {
+	out.Protocol = in.Protocol
+	out.Listen = in.Listen
+	return nil
+}
+
+// Convert_kops_GossipConfigSecondary_To_v1alpha1_GossipConfigSecondary is an autogenerated conversion function.
+func Convert_kops_GossipConfigSecondary_To_v1alpha1_GossipConfigSecondary(in *kops.GossipConfigSecondary, out *GossipConfigSecondary, s conversion.Scope) error {
+	return autoConvert_kops_GossipConfigSecondary_To_v1alpha1_GossipConfigSecondary(in, out, s)
+}
+
 func autoConvert_v1alpha1_HTTPProxy_To_kops_HTTPProxy(in *HTTPProxy, out *kops.HTTPProxy, s conversion.Scope) error {
 	out.Host = in.Host
 	out.Port = in.Port
@@ -1583,6 +1889,12 @@ func autoConvert_v1alpha1_HookSpec_To_kops_HookSpec(in *HookSpec, out *kops.Hook
 		out.ExecContainer = nil
 	}
 	out.Manifest = in.Manifest
+	out.Phase = in.Phase
+	out.RunPolicy = in.RunPolicy
+	out.Timeout = in.Timeout
+	out.Retries = in.Retries
+	out.SuccessExitCodes = in.SuccessExitCodes
+	out.EnvironmentFromSecrets = in.EnvironmentFromSecrets
 	return nil
 }
 
@@ -1615,6 +1927,12 @@ func autoConvert_kops_HookSpec_To_v1alpha1_HookSpec(in *kops.HookSpec, out *Hook
 		out.ExecContainer = nil
 	}
 	out.Manifest = in.Manifest
+	out.Phase = in.Phase
+	out.RunPolicy = in.RunPolicy
+	out.Timeout = in.Timeout
+	out.Retries = in.Retries
+	out.SuccessExitCodes = in.SuccessExitCodes
+	out.EnvironmentFromSecrets = in.EnvironmentFromSecrets
 	return nil
 }
 
@@ -1623,6 +1941,114 @@ func Convert_kops_HookSpec_To_v1alpha1_HookSpec(in *kops.HookSpec, out *HookSpec
 	return autoConvert_kops_HookSpec_To_v1alpha1_HookSpec(in, out, s)
 }
 
+func autoConvert_v1alpha1_HostFirewallRule_To_kops_HostFirewallRule(in *HostFirewallRule, out *kops.HostFirewallRule, s conversion.Scope) error {
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]kops.InstanceGroupRole, len(*in))
+		for i := range *in {
+			(*out)[i] = kops.InstanceGroupRole((*in)[i])
+		}
+	} else {
+		out.Roles = nil
+	}
+	out.Protocol = in.Protocol
+	out.Ports = in.Ports
+	out.CIDRs = in.CIDRs
+	return nil
+}
+
+// Convert_v1alpha1_HostFirewallRule_To_kops_HostFirewallRule is an autogenerated conversion function.
+func Convert_v1alpha1_HostFirewallRule_To_kops_HostFirewallRule(in *HostFirewallRule, out *kops.HostFirewallRule, s conversion.Scope) error {
+	return autoConvert_v1alpha1_HostFirewallRule_To_kops_HostFirewallRule(in, out, s)
+}
+
+func autoConvert_kops_HostFirewallRule_To_v1alpha1_HostFirewallRule(in *kops.HostFirewallRule, out *HostFirewallRule, s conversion.Scope) error {
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]InstanceGroupRole, len(*in))
+		for i := range *in {
+			(*out)[i] = InstanceGroupRole((*in)[i])
+		}
+	} else {
+		out.Roles = nil
+	}
+	out.Protocol = in.Protocol
+	out.Ports = in.Ports
+	out.CIDRs = in.CIDRs
+	return nil
+}
+
+// Convert_kops_HostFirewallRule_To_v1alpha1_HostFirewallRule is an autogenerated conversion function.
+func Convert_kops_HostFirewallRule_To_v1alpha1_HostFirewallRule(in *kops.HostFirewallRule, out *HostFirewallRule, s conversion.Scope) error {
+	return autoConvert_kops_HostFirewallRule_To_v1alpha1_HostFirewallRule(in, out, s)
+}
+
+func autoConvert_v1alpha1_HostFirewallSpec_To_kops_HostFirewallSpec(in *HostFirewallSpec, out *kops.HostFirewallSpec, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.Backend = in.Backend
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]kops.HostFirewallRule, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_HostFirewallRule_To_kops_HostFirewallRule(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Rules = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha1_HostFirewallSpec_To_kops_HostFirewallSpec is an autogenerated conversion function.
+func Convert_v1alpha1_HostFirewallSpec_To_kops_HostFirewallSpec(in *HostFirewallSpec, out *kops.HostFirewallSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_HostFirewallSpec_To_kops_HostFirewallSpec(in, out, s)
+}
+
+func autoConvert_kops_HostFirewallSpec_To_v1alpha1_HostFirewallSpec(in *kops.HostFirewallSpec, out *HostFirewallSpec, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.Backend = in.Backend
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]HostFirewallRule, len(*in))
+		for i := range *in {
+			if err := Convert_kops_HostFirewallRule_To_v1alpha1_HostFirewallRule(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Rules = nil
+	}
+	return nil
+}
+
+// Convert_kops_HostFirewallSpec_To_v1alpha1_HostFirewallSpec is an autogenerated conversion function.
+func Convert_kops_HostFirewallSpec_To_v1alpha1_HostFirewallSpec(in *kops.HostFirewallSpec, out *HostFirewallSpec, s conversion.Scope) error {
+	return autoConvert_kops_HostFirewallSpec_To_v1alpha1_HostFirewallSpec(in, out, s)
+}
+
+func autoConvert_v1alpha1_HugepagesSpec_To_kops_HugepagesSpec(in *HugepagesSpec, out *kops.HugepagesSpec, s conversion.Scope) error {
+	out.PageSize = in.PageSize
+	out.Count = in.Count
+	return nil
+}
+
+// Convert_v1alpha1_HugepagesSpec_To_kops_HugepagesSpec is an autogenerated conversion function.
+func Convert_v1alpha1_HugepagesSpec_To_kops_HugepagesSpec(in *HugepagesSpec, out *kops.HugepagesSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_HugepagesSpec_To_kops_HugepagesSpec(in, out, s)
+}
+
+func autoConvert_kops_HugepagesSpec_To_v1alpha1_HugepagesSpec(in *kops.HugepagesSpec, out *HugepagesSpec, s conversion.Scope) error {
+	out.PageSize = in.PageSize
+	out.Count = in.Count
+	return nil
+}
+
+// Convert_kops_HugepagesSpec_To_v1alpha1_HugepagesSpec is an autogenerated conversion function.
+func Convert_kops_HugepagesSpec_To_v1alpha1_HugepagesSpec(in *kops.HugepagesSpec, out *HugepagesSpec, s conversion.Scope) error {
+	return autoConvert_kops_HugepagesSpec_To_v1alpha1_HugepagesSpec(in, out, s)
+}
+
 func autoConvert_v1alpha1_IAMSpec_To_kops_IAMSpec(in *IAMSpec, out *kops.IAMSpec, s conversion.Scope) error {
 	out.Legacy = in.Legacy
 	out.AllowContainerRegistry = in.AllowContainerRegistry
@@ -1719,6 +2145,7 @@ func autoConvert_v1alpha1_InstanceGroupSpec_To_kops_InstanceGroupSpec(in *Instan
 	out.MinSize = in.MinSize
 	out.MaxSize = in.MaxSize
 	out.MachineType = in.MachineType
+	out.Architecture = in.Architecture
 	out.RootVolumeSize = in.RootVolumeSize
 	out.RootVolumeType = in.RootVolumeType
 	out.RootVolumeIops = in.RootVolumeIops
@@ -1760,6 +2187,54 @@ func autoConvert_v1alpha1_InstanceGroupSpec_To_kops_InstanceGroupSpec(in *Instan
 	} else {
 		out.Kubelet = nil
 	}
+	out.ContainerRuntime = in.ContainerRuntime
+	if in.NodeBundle != nil {
+		in, out := &in.NodeBundle, &out.NodeBundle
+		*out = new(kops.NodeBundleSpec)
+		if err := Convert_v1alpha1_NodeBundleSpec_To_kops_NodeBundleSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeBundle = nil
+	}
+	if in.NodeTuning != nil {
+		in, out := &in.NodeTuning, &out.NodeTuning
+		*out = new(kops.NodeTuningSpec)
+		if err := Convert_v1alpha1_NodeTuningSpec_To_kops_NodeTuningSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeTuning = nil
+	}
+	if in.HostFirewall != nil {
+		in, out := &in.HostFirewall, &out.HostFirewall
+		*out = new(kops.HostFirewallSpec)
+		if err := Convert_v1alpha1_HostFirewallSpec_To_kops_HostFirewallSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.HostFirewall = nil
+	}
+	if in.NodeAgent != nil {
+		in, out := &in.NodeAgent, &out.NodeAgent
+		*out = new(kops.NodeAgentSpec)
+		if err := Convert_v1alpha1_NodeAgentSpec_To_kops_NodeAgentSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeAgent = nil
+	}
+	if in.NodeAddons != nil {
+		in, out := &in.NodeAddons, &out.NodeAddons
+		*out = make([]kops.NodeAddonSpec, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_NodeAddonSpec_To_kops_NodeAddonSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.NodeAddons = nil
+	}
 	out.Taints = in.Taints
 	if in.AdditionalUserData != nil {
 		in, out := &in.AdditionalUserData, &out.AdditionalUserData
@@ -1784,6 +2259,7 @@ func autoConvert_kops_InstanceGroupSpec_To_v1alpha1_InstanceGroupSpec(in *kops.I
 	out.MinSize = in.MinSize
 	out.MaxSize = in.MaxSize
 	out.MachineType = in.MachineType
+	out.Architecture = in.Architecture
 	out.RootVolumeSize = in.RootVolumeSize
 	out.RootVolumeType = in.RootVolumeType
 	out.RootVolumeIops = in.RootVolumeIops
@@ -1827,6 +2303,54 @@ func autoConvert_kops_InstanceGroupSpec_To_v1alpha1_InstanceGroupSpec(in *kops.I
 	} else {
 		out.Kubelet = nil
 	}
+	out.ContainerRuntime = in.ContainerRuntime
+	if in.NodeBundle != nil {
+		in, out := &in.NodeBundle, &out.NodeBundle
+		*out = new(NodeBundleSpec)
+		if err := Convert_kops_NodeBundleSpec_To_v1alpha1_NodeBundleSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeBundle = nil
+	}
+	if in.NodeTuning != nil {
+		in, out := &in.NodeTuning, &out.NodeTuning
+		*out = new(NodeTuningSpec)
+		if err := Convert_kops_NodeTuningSpec_To_v1alpha1_NodeTuningSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeTuning = nil
+	}
+	if in.HostFirewall != nil {
+		in, out := &in.HostFirewall, &out.HostFirewall
+		*out = new(HostFirewallSpec)
+		if err := Convert_kops_HostFirewallSpec_To_v1alpha1_HostFirewallSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.HostFirewall = nil
+	}
+	if in.NodeAgent != nil {
+		in, out := &in.NodeAgent, &out.NodeAgent
+		*out = new(NodeAgentSpec)
+		if err := Convert_kops_NodeAgentSpec_To_v1alpha1_NodeAgentSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeAgent = nil
+	}
+	if in.NodeAddons != nil {
+		in, out := &in.NodeAddons, &out.NodeAddons
+		*out = make([]NodeAddonSpec, len(*in))
+		for i := range *in {
+			if err := Convert_kops_NodeAddonSpec_To_v1alpha1_NodeAddonSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.NodeAddons = nil
+	}
 	out.Taints = in.Taints
 	if in.AdditionalUserData != nil {
 		in, out := &in.AdditionalUserData, &out.AdditionalUserData
@@ -2258,6 +2782,8 @@ func autoConvert_v1alpha1_KubeletConfigSpec_To_kops_KubeletConfigSpec(in *Kubele
 	out.SystemReservedCgroup = in.SystemReservedCgroup
 	out.EnforceNodeAllocatable = in.EnforceNodeAllocatable
 	out.RuntimeRequestTimeout = in.RuntimeRequestTimeout
+	out.ContainerRuntime = in.ContainerRuntime
+	out.ContainerRuntimeEndpoint = in.ContainerRuntimeEndpoint
 	out.VolumeStatsAggPeriod = in.VolumeStatsAggPeriod
 	out.FailSwapOn = in.FailSwapOn
 	return nil
@@ -2324,6 +2850,8 @@ func autoConvert_kops_KubeletConfigSpec_To_v1alpha1_KubeletConfigSpec(in *kops.K
 	out.SystemReservedCgroup = in.SystemReservedCgroup
 	out.EnforceNodeAllocatable = in.EnforceNodeAllocatable
 	out.RuntimeRequestTimeout = in.RuntimeRequestTimeout
+	out.ContainerRuntime = in.ContainerRuntime
+	out.ContainerRuntimeEndpoint = in.ContainerRuntimeEndpoint
 	out.VolumeStatsAggPeriod = in.VolumeStatsAggPeriod
 	out.FailSwapOn = in.FailSwapOn
 	return nil
@@ -2666,6 +3194,182 @@ func Convert_kops_NetworkingSpec_To_v1alpha1_NetworkingSpec(in *kops.NetworkingS
 	return autoConvert_kops_NetworkingSpec_To_v1alpha1_NetworkingSpec(in, out, s)
 }
 
+func autoConvert_v1alpha1_NodeAddonHealthCheckSpec_To_kops_NodeAddonHealthCheckSpec(in *NodeAddonHealthCheckSpec, out *kops.NodeAddonHealthCheckSpec, s conversion.Scope) error {
+	out.Exec = in.Exec
+	out.Socket = in.Socket
+	out.Timeout = in.Timeout
+	return nil
+}
+
+// Convert_v1alpha1_NodeAddonHealthCheckSpec_To_kops_NodeAddonHealthCheckSpec is an autogenerated conversion function.
+func Convert_v1alpha1_NodeAddonHealthCheckSpec_To_kops_NodeAddonHealthCheckSpec(in *NodeAddonHealthCheckSpec, out *kops.NodeAddonHealthCheckSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_NodeAddonHealthCheckSpec_To_kops_NodeAddonHealthCheckSpec(in, out, s)
+}
+
+func autoConvert_kops_NodeAddonHealthCheckSpec_To_v1alpha1_NodeAddonHealthCheckSpec(in *kops.NodeAddonHealthCheckSpec, out *NodeAddonHealthCheckSpec, s conversion.Scope) error {
+	out.Exec = in.Exec
+	out.Socket = in.Socket
+	out.Timeout = in.Timeout
+	return nil
+}
+
+// Convert_kops_NodeAddonHealthCheckSpec_To_v1alpha1_NodeAddonHealthCheckSpec is an autogenerated conversion function.
+func Convert_kops_NodeAddonHealthCheckSpec_To_v1alpha1_NodeAddonHealthCheckSpec(in *kops.NodeAddonHealthCheckSpec, out *NodeAddonHealthCheckSpec, s conversion.Scope) error {
+	return autoConvert_kops_NodeAddonHealthCheckSpec_To_v1alpha1_NodeAddonHealthCheckSpec(in, out, s)
+}
+
+func autoConvert_v1alpha1_NodeAddonSpec_To_kops_NodeAddonSpec(in *NodeAddonSpec, out *kops.NodeAddonSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Type = in.Type
+	out.Version = in.Version
+	out.Source = in.Source
+	out.Hash = in.Hash
+	out.Image = in.Image
+	out.Args = in.Args
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(kops.NodeAddonHealthCheckSpec)
+		if err := Convert_v1alpha1_NodeAddonHealthCheckSpec_To_kops_NodeAddonHealthCheckSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.HealthCheck = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha1_NodeAddonSpec_To_kops_NodeAddonSpec is an autogenerated conversion function.
+func Convert_v1alpha1_NodeAddonSpec_To_kops_NodeAddonSpec(in *NodeAddonSpec, out *kops.NodeAddonSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_NodeAddonSpec_To_kops_NodeAddonSpec(in, out, s)
+}
+
+func autoConvert_kops_NodeAddonSpec_To_v1alpha1_NodeAddonSpec(in *kops.NodeAddonSpec, out *NodeAddonSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Type = in.Type
+	out.Version = in.Version
+	out.Source = in.Source
+	out.Hash = in.Hash
+	out.Image = in.Image
+	out.Args = in.Args
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(NodeAddonHealthCheckSpec)
+		if err := Convert_kops_NodeAddonHealthCheckSpec_To_v1alpha1_NodeAddonHealthCheckSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.HealthCheck = nil
+	}
+	return nil
+}
+
+// Convert_kops_NodeAddonSpec_To_v1alpha1_NodeAddonSpec is an autogenerated conversion function.
+func Convert_kops_NodeAddonSpec_To_v1alpha1_NodeAddonSpec(in *kops.NodeAddonSpec, out *NodeAddonSpec, s conversion.Scope) error {
+	return autoConvert_kops_NodeAddonSpec_To_v1alpha1_NodeAddonSpec(in, out, s)
+}
+
+func autoConvert_v1alpha1_NodeAgentSpec_To_kops_NodeAgentSpec(in *NodeAgentSpec, out *kops.NodeAgentSpec, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.Interval = in.Interval
+	return nil
+}
+
+// Convert_v1alpha1_NodeAgentSpec_To_kops_NodeAgentSpec is an autogenerated conversion function.
+func Convert_v1alpha1_NodeAgentSpec_To_kops_NodeAgentSpec(in *NodeAgentSpec, out *kops.NodeAgentSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_NodeAgentSpec_To_kops_NodeAgentSpec(in, out, s)
+}
+
+func autoConvert_kops_NodeAgentSpec_To_v1alpha1_NodeAgentSpec(in *kops.NodeAgentSpec, out *NodeAgentSpec, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.Interval = in.Interval
+	return nil
+}
+
+// Convert_kops_NodeAgentSpec_To_v1alpha1_NodeAgentSpec is an autogenerated conversion function.
+func Convert_kops_NodeAgentSpec_To_v1alpha1_NodeAgentSpec(in *kops.NodeAgentSpec, out *NodeAgentSpec, s conversion.Scope) error {
+	return autoConvert_kops_NodeAgentSpec_To_v1alpha1_NodeAgentSpec(in, out, s)
+}
+
+func autoConvert_v1alpha1_NodeBundleSpec_To_kops_NodeBundleSpec(in *NodeBundleSpec, out *kops.NodeBundleSpec, s conversion.Scope) error {
+	out.Location = in.Location
+	out.Hash = in.Hash
+	return nil
+}
+
+// Convert_v1alpha1_NodeBundleSpec_To_kops_NodeBundleSpec is an autogenerated conversion function.
+func Convert_v1alpha1_NodeBundleSpec_To_kops_NodeBundleSpec(in *NodeBundleSpec, out *kops.NodeBundleSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_NodeBundleSpec_To_kops_NodeBundleSpec(in, out, s)
+}
+
+func autoConvert_kops_NodeBundleSpec_To_v1alpha1_NodeBundleSpec(in *kops.NodeBundleSpec, out *NodeBundleSpec, s conversion.Scope) error {
+	out.Location = in.Location
+	out.Hash = in.Hash
+	return nil
+}
+
+// Convert_kops_NodeBundleSpec_To_v1alpha1_NodeBundleSpec is an autogenerated conversion function.
+func Convert_kops_NodeBundleSpec_To_v1alpha1_NodeBundleSpec(in *kops.NodeBundleSpec, out *NodeBundleSpec, s conversion.Scope) error {
+	return autoConvert_kops_NodeBundleSpec_To_v1alpha1_NodeBundleSpec(in, out, s)
+}
+
+func autoConvert_v1alpha1_NodeTuningSpec_To_kops_NodeTuningSpec(in *NodeTuningSpec, out *kops.NodeTuningSpec, s conversion.Scope) error {
+	out.SysctlParameters = in.SysctlParameters
+	out.KernelModules = in.KernelModules
+	if in.Ulimits != nil {
+		in, out := &in.Ulimits, &out.Ulimits
+		*out = new(kops.UlimitsSpec)
+		if err := Convert_v1alpha1_UlimitsSpec_To_kops_UlimitsSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Ulimits = nil
+	}
+	if in.Hugepages != nil {
+		in, out := &in.Hugepages, &out.Hugepages
+		*out = new(kops.HugepagesSpec)
+		if err := Convert_v1alpha1_HugepagesSpec_To_kops_HugepagesSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Hugepages = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha1_NodeTuningSpec_To_kops_NodeTuningSpec is an autogenerated conversion function.
+func Convert_v1alpha1_NodeTuningSpec_To_kops_NodeTuningSpec(in *NodeTuningSpec, out *kops.NodeTuningSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_NodeTuningSpec_To_kops_NodeTuningSpec(in, out, s)
+}
+
+func autoConvert_kops_NodeTuningSpec_To_v1alpha1_NodeTuningSpec(in *kops.NodeTuningSpec, out *NodeTuningSpec, s conversion.Scope) error {
+	out.SysctlParameters = in.SysctlParameters
+	out.KernelModules = in.KernelModules
+	if in.Ulimits != nil {
+		in, out := &in.Ulimits, &out.Ulimits
+		*out = new(UlimitsSpec)
+		if err := Convert_kops_UlimitsSpec_To_v1alpha1_UlimitsSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Ulimits = nil
+	}
+	if in.Hugepages != nil {
+		in, out := &in.Hugepages, &out.Hugepages
+		*out = new(HugepagesSpec)
+		if err := Convert_kops_HugepagesSpec_To_v1alpha1_HugepagesSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Hugepages = nil
+	}
+	return nil
+}
+
+// Convert_kops_NodeTuningSpec_To_v1alpha1_NodeTuningSpec is an autogenerated conversion function.
+func Convert_kops_NodeTuningSpec_To_v1alpha1_NodeTuningSpec(in *kops.NodeTuningSpec, out *NodeTuningSpec, s conversion.Scope) error {
+	return autoConvert_kops_NodeTuningSpec_To_v1alpha1_NodeTuningSpec(in, out, s)
+}
+
 func autoConvert_v1alpha1_RBACAuthorizationSpec_To_kops_RBACAuthorizationSpec(in *RBACAuthorizationSpec, out *kops.RBACAuthorizationSpec, s conversion.Scope) error {
 	return nil
 }
@@ -2850,6 +3554,32 @@ func Convert_kops_TerraformSpec_To_v1alpha1_TerraformSpec(in *kops.TerraformSpec
 	return autoConvert_kops_TerraformSpec_To_v1alpha1_TerraformSpec(in, out, s)
 }
 
+func autoConvert_v1alpha1_UlimitsSpec_To_kops_UlimitsSpec(in *UlimitsSpec, out *kops.UlimitsSpec, s conversion.Scope) error {
+	out.NoFile = in.NoFile
+	out.NProc = in.NProc
+	out.MemLock = in.MemLock
+	out.Core = in.Core
+	return nil
+}
+
+// Convert_v1alpha1_UlimitsSpec_To_kops_UlimitsSpec is an autogenerated conversion function.
+func Convert_v1alpha1_UlimitsSpec_To_kops_UlimitsSpec(in *UlimitsSpec, out *kops.UlimitsSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_UlimitsSpec_To_kops_UlimitsSpec(in, out, s)
+}
+
+func autoConvert_kops_UlimitsSpec_To_v1alpha1_UlimitsSpec(in *kops.UlimitsSpec, out *UlimitsSpec, s conversion.Scope) error {
+	out.NoFile = in.NoFile
+	out.NProc = in.NProc
+	out.MemLock = in.MemLock
+	out.Core = in.Core
+	return nil
+}
+
+// Convert_kops_UlimitsSpec_To_v1alpha1_UlimitsSpec is an autogenerated conversion function.
+func Convert_kops_UlimitsSpec_To_v1alpha1_UlimitsSpec(in *kops.UlimitsSpec, out *UlimitsSpec, s conversion.Scope) error {
+	return autoConvert_kops_UlimitsSpec_To_v1alpha1_UlimitsSpec(in, out, s)
+}
+
 func autoConvert_v1alpha1_UserData_To_kops_UserData(in *UserData, out *kops.UserData, s conversion.Scope) error {
 	out.Name = in.Name
 	out.Type = in.Type
@@ -0,0 +1,29 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// NodeAgentSpec configures the nodeup agent, which periodically re-runs nodeup on the nodes.
+// The agent applies the changes which are safe to make in place (the kubelet, fileAssets and nodeTuning),
+// so that changing them does not replace the instances; other changes are reported on the node, and still require a rolling update.
+type NodeAgentSpec struct {
+	// Enabled runs the nodeup agent
+	Enabled *bool `json:"enabled,omitempty"`
+	// Interval is the time between runs of the agent; the default is 10 minutes
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
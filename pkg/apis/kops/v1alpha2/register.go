@@ -61,6 +61,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&KeysetList{},
 		&SSHCredential{},
 		&SSHCredentialList{},
+		&Operation{},
+		&OperationList{},
 	)
 
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
@@ -80,6 +82,9 @@ func (obj *Keyset) GetObjectKind() schema.ObjectKind {
 func (obj *SSHCredential) GetObjectKind() schema.ObjectKind {
 	return &obj.TypeMeta
 }
+func (obj *Operation) GetObjectKind() schema.ObjectKind {
+	return &obj.TypeMeta
+}
 
 func addConversionFuncs(scheme *runtime.Scheme) error {
 	return nil
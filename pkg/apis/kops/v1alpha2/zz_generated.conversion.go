@@ -53,6 +53,8 @@ func RegisterConversions(scheme *runtime.Scheme) error {
 		Convert_kops_BastionSpec_To_v1alpha2_BastionSpec,
 		Convert_v1alpha2_CNINetworkingSpec_To_kops_CNINetworkingSpec,
 		Convert_kops_CNINetworkingSpec_To_v1alpha2_CNINetworkingSpec,
+		Convert_v1alpha2_CRIOConfig_To_kops_CRIOConfig,
+		Convert_kops_CRIOConfig_To_v1alpha2_CRIOConfig,
 		Convert_v1alpha2_CalicoNetworkingSpec_To_kops_CalicoNetworkingSpec,
 		Convert_kops_CalicoNetworkingSpec_To_v1alpha2_CalicoNetworkingSpec,
 		Convert_v1alpha2_CanalNetworkingSpec_To_kops_CanalNetworkingSpec,
@@ -73,10 +75,14 @@ func RegisterConversions(scheme *runtime.Scheme) error {
 		Convert_kops_ClusterSpec_To_v1alpha2_ClusterSpec,
 		Convert_v1alpha2_ClusterSubnetSpec_To_kops_ClusterSubnetSpec,
 		Convert_kops_ClusterSubnetSpec_To_v1alpha2_ClusterSubnetSpec,
+		Convert_v1alpha2_ContainerdConfig_To_kops_ContainerdConfig,
+		Convert_kops_ContainerdConfig_To_v1alpha2_ContainerdConfig,
 		Convert_v1alpha2_DNSAccessSpec_To_kops_DNSAccessSpec,
 		Convert_kops_DNSAccessSpec_To_v1alpha2_DNSAccessSpec,
 		Convert_v1alpha2_DNSSpec_To_kops_DNSSpec,
 		Convert_kops_DNSSpec_To_v1alpha2_DNSSpec,
+		Convert_v1alpha2_DNSVPCSpec_To_kops_DNSVPCSpec,
+		Convert_kops_DNSVPCSpec_To_v1alpha2_DNSVPCSpec,
 		Convert_v1alpha2_DockerConfig_To_kops_DockerConfig,
 		Convert_kops_DockerConfig_To_v1alpha2_DockerConfig,
 		Convert_v1alpha2_EgressProxySpec_To_kops_EgressProxySpec,
@@ -97,10 +103,20 @@ func RegisterConversions(scheme *runtime.Scheme) error {
 		Convert_kops_FileAssetSpec_To_v1alpha2_FileAssetSpec,
 		Convert_v1alpha2_FlannelNetworkingSpec_To_kops_FlannelNetworkingSpec,
 		Convert_kops_FlannelNetworkingSpec_To_v1alpha2_FlannelNetworkingSpec,
+		Convert_v1alpha2_GossipConfig_To_kops_GossipConfig,
+		Convert_kops_GossipConfig_To_v1alpha2_GossipConfig,
+		Convert_v1alpha2_GossipConfigSecondary_To_kops_GossipConfigSecondary,
+		Convert_kops_GossipConfigSecondary_To_v1alpha2_GossipConfigSecondary,
 		Convert_v1alpha2_HTTPProxy_To_kops_HTTPProxy,
 		Convert_kops_HTTPProxy_To_v1alpha2_HTTPProxy,
 		Convert_v1alpha2_HookSpec_To_kops_HookSpec,
 		Convert_kops_HookSpec_To_v1alpha2_HookSpec,
+		Convert_v1alpha2_HostFirewallRule_To_kops_HostFirewallRule,
+		Convert_kops_HostFirewallRule_To_v1alpha2_HostFirewallRule,
+		Convert_v1alpha2_HostFirewallSpec_To_kops_HostFirewallSpec,
+		Convert_kops_HostFirewallSpec_To_v1alpha2_HostFirewallSpec,
+		Convert_v1alpha2_HugepagesSpec_To_kops_HugepagesSpec,
+		Convert_kops_HugepagesSpec_To_v1alpha2_HugepagesSpec,
 		Convert_v1alpha2_IAMSpec_To_kops_IAMSpec,
 		Convert_kops_IAMSpec_To_v1alpha2_IAMSpec,
 		Convert_v1alpha2_InstanceGroup_To_kops_InstanceGroup,
@@ -143,6 +159,24 @@ func RegisterConversions(scheme *runtime.Scheme) error {
 		Convert_kops_LoadBalancerAccessSpec_To_v1alpha2_LoadBalancerAccessSpec,
 		Convert_v1alpha2_NetworkingSpec_To_kops_NetworkingSpec,
 		Convert_kops_NetworkingSpec_To_v1alpha2_NetworkingSpec,
+		Convert_v1alpha2_NodeAddonHealthCheckSpec_To_kops_NodeAddonHealthCheckSpec,
+		Convert_kops_NodeAddonHealthCheckSpec_To_v1alpha2_NodeAddonHealthCheckSpec,
+		Convert_v1alpha2_NodeAddonSpec_To_kops_NodeAddonSpec,
+		Convert_kops_NodeAddonSpec_To_v1alpha2_NodeAddonSpec,
+		Convert_v1alpha2_NodeAgentSpec_To_kops_NodeAgentSpec,
+		Convert_kops_NodeAgentSpec_To_v1alpha2_NodeAgentSpec,
+		Convert_v1alpha2_NodeBundleSpec_To_kops_NodeBundleSpec,
+		Convert_kops_NodeBundleSpec_To_v1alpha2_NodeBundleSpec,
+		Convert_v1alpha2_NodeTuningSpec_To_kops_NodeTuningSpec,
+		Convert_kops_NodeTuningSpec_To_v1alpha2_NodeTuningSpec,
+		Convert_v1alpha2_Operation_To_kops_Operation,
+		Convert_kops_Operation_To_v1alpha2_Operation,
+		Convert_v1alpha2_OperationList_To_kops_OperationList,
+		Convert_kops_OperationList_To_v1alpha2_OperationList,
+		Convert_v1alpha2_OperationSpec_To_kops_OperationSpec,
+		Convert_kops_OperationSpec_To_v1alpha2_OperationSpec,
+		Convert_v1alpha2_OperationStatus_To_kops_OperationStatus,
+		Convert_kops_OperationStatus_To_v1alpha2_OperationStatus,
 		Convert_v1alpha2_RBACAuthorizationSpec_To_kops_RBACAuthorizationSpec,
 		Convert_kops_RBACAuthorizationSpec_To_v1alpha2_RBACAuthorizationSpec,
 		Convert_v1alpha2_RomanaNetworkingSpec_To_kops_RomanaNetworkingSpec,
@@ -159,6 +193,8 @@ func RegisterConversions(scheme *runtime.Scheme) error {
 		Convert_kops_TerraformSpec_To_v1alpha2_TerraformSpec,
 		Convert_v1alpha2_TopologySpec_To_kops_TopologySpec,
 		Convert_kops_TopologySpec_To_v1alpha2_TopologySpec,
+		Convert_v1alpha2_UlimitsSpec_To_kops_UlimitsSpec,
+		Convert_kops_UlimitsSpec_To_v1alpha2_UlimitsSpec,
 		Convert_v1alpha2_UserData_To_kops_UserData,
 		Convert_kops_UserData_To_v1alpha2_UserData,
 		Convert_v1alpha2_WeaveNetworkingSpec_To_kops_WeaveNetworkingSpec,
@@ -428,6 +464,34 @@ func Convert_kops_CNINetworkingSpec_To_v1alpha2_CNINetworkingSpec(in *kops.CNINe
 	return autoConvert_kops_CNINetworkingSpec_To_v1alpha2_CNINetworkingSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_CRIOConfig_To_kops_CRIOConfig(in *CRIOConfig, out *kops.CRIOConfig, s conversion.Scope) error {
+	out.InsecureRegistries = in.InsecureRegistries
+	out.LogLevel = in.LogLevel
+	out.Registries = in.Registries
+	out.StorageDriver = in.StorageDriver
+	out.Version = in.Version
+	return nil
+}
+
+// Convert_v1alpha2_CRIOConfig_To_kops_CRIOConfig is an autogenerated conversion function.
+func Convert_v1alpha2_CRIOConfig_To_kops_CRIOConfig(in *CRIOConfig, out *kops.CRIOConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha2_CRIOConfig_To_kops_CRIOConfig(in, out, s)
+}
+
+func autoConvert_kops_CRIOConfig_To_v1alpha2_CRIOConfig(in *kops.CRIOConfig, out *CRIOConfig, s conversion.Scope) error {
+	out.InsecureRegistries = in.InsecureRegistries
+	out.LogLevel = in.LogLevel
+	out.Registries = in.Registries
+	out.StorageDriver = in.StorageDriver
+	out.Version = in.Version
+	return nil
+}
+
+// Convert_kops_CRIOConfig_To_v1alpha2_CRIOConfig is an autogenerated conversion function.
+func Convert_kops_CRIOConfig_To_v1alpha2_CRIOConfig(in *kops.CRIOConfig, out *CRIOConfig, s conversion.Scope) error {
+	return autoConvert_kops_CRIOConfig_To_v1alpha2_CRIOConfig(in, out, s)
+}
+
 func autoConvert_v1alpha2_CalicoNetworkingSpec_To_kops_CalicoNetworkingSpec(in *CalicoNetworkingSpec, out *kops.CalicoNetworkingSpec, s conversion.Scope) error {
 	out.CrossSubnet = in.CrossSubnet
 	out.PrometheusMetricsEnabled = in.PrometheusMetricsEnabled
@@ -781,6 +845,7 @@ func autoConvert_v1alpha2_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	} else {
 		out.EtcdClusters = nil
 	}
+	out.ContainerRuntime = in.ContainerRuntime
 	if in.Docker != nil {
 		in, out := &in.Docker, &out.Docker
 		*out = new(kops.DockerConfig)
@@ -790,6 +855,24 @@ func autoConvert_v1alpha2_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	} else {
 		out.Docker = nil
 	}
+	if in.Containerd != nil {
+		in, out := &in.Containerd, &out.Containerd
+		*out = new(kops.ContainerdConfig)
+		if err := Convert_v1alpha2_ContainerdConfig_To_kops_ContainerdConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Containerd = nil
+	}
+	if in.CRIO != nil {
+		in, out := &in.CRIO, &out.CRIO
+		*out = new(kops.CRIOConfig)
+		if err := Convert_v1alpha2_CRIOConfig_To_kops_CRIOConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.CRIO = nil
+	}
 	if in.KubeDNS != nil {
 		in, out := &in.KubeDNS, &out.KubeDNS
 		*out = new(kops.KubeDNSConfig)
@@ -880,6 +963,15 @@ func autoConvert_v1alpha2_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	} else {
 		out.ExternalDNS = nil
 	}
+	if in.GossipConfig != nil {
+		in, out := &in.GossipConfig, &out.GossipConfig
+		*out = new(kops.GossipConfig)
+		if err := Convert_v1alpha2_GossipConfig_To_kops_GossipConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.GossipConfig = nil
+	}
 	if in.Networking != nil {
 		in, out := &in.Networking, &out.Networking
 		*out = new(kops.NetworkingSpec)
@@ -928,6 +1020,33 @@ func autoConvert_v1alpha2_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	} else {
 		out.Hooks = nil
 	}
+	if in.NodeTuning != nil {
+		in, out := &in.NodeTuning, &out.NodeTuning
+		*out = new(kops.NodeTuningSpec)
+		if err := Convert_v1alpha2_NodeTuningSpec_To_kops_NodeTuningSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeTuning = nil
+	}
+	if in.HostFirewall != nil {
+		in, out := &in.HostFirewall, &out.HostFirewall
+		*out = new(kops.HostFirewallSpec)
+		if err := Convert_v1alpha2_HostFirewallSpec_To_kops_HostFirewallSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.HostFirewall = nil
+	}
+	if in.NodeAgent != nil {
+		in, out := &in.NodeAgent, &out.NodeAgent
+		*out = new(kops.NodeAgentSpec)
+		if err := Convert_v1alpha2_NodeAgentSpec_To_kops_NodeAgentSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeAgent = nil
+	}
 	if in.Assets != nil {
 		in, out := &in.Assets, &out.Assets
 		*out = new(kops.Assets)
@@ -947,6 +1066,7 @@ func autoConvert_v1alpha2_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 		out.IAM = nil
 	}
 	out.EncryptionConfig = in.EncryptionConfig
+	out.UploadBootReports = in.UploadBootReports
 	if in.Target != nil {
 		in, out := &in.Target, &out.Target
 		*out = new(kops.TargetSpec)
@@ -1053,6 +1173,7 @@ func autoConvert_kops_ClusterSpec_To_v1alpha2_ClusterSpec(in *kops.ClusterSpec,
 	} else {
 		out.EtcdClusters = nil
 	}
+	out.ContainerRuntime = in.ContainerRuntime
 	if in.Docker != nil {
 		in, out := &in.Docker, &out.Docker
 		*out = new(DockerConfig)
@@ -1062,6 +1183,24 @@ func autoConvert_kops_ClusterSpec_To_v1alpha2_ClusterSpec(in *kops.ClusterSpec,
 	} else {
 		out.Docker = nil
 	}
+	if in.Containerd != nil {
+		in, out := &in.Containerd, &out.Containerd
+		*out = new(ContainerdConfig)
+		if err := Convert_kops_ContainerdConfig_To_v1alpha2_ContainerdConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Containerd = nil
+	}
+	if in.CRIO != nil {
+		in, out := &in.CRIO, &out.CRIO
+		*out = new(CRIOConfig)
+		if err := Convert_kops_CRIOConfig_To_v1alpha2_CRIOConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.CRIO = nil
+	}
 	if in.KubeDNS != nil {
 		in, out := &in.KubeDNS, &out.KubeDNS
 		*out = new(KubeDNSConfig)
@@ -1152,6 +1291,15 @@ func autoConvert_kops_ClusterSpec_To_v1alpha2_ClusterSpec(in *kops.ClusterSpec,
 	} else {
 		out.ExternalDNS = nil
 	}
+	if in.GossipConfig != nil {
+		in, out := &in.GossipConfig, &out.GossipConfig
+		*out = new(GossipConfig)
+		if err := Convert_kops_GossipConfig_To_v1alpha2_GossipConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.GossipConfig = nil
+	}
 	if in.Networking != nil {
 		in, out := &in.Networking, &out.Networking
 		*out = new(NetworkingSpec)
@@ -1200,6 +1348,33 @@ func autoConvert_kops_ClusterSpec_To_v1alpha2_ClusterSpec(in *kops.ClusterSpec,
 	} else {
 		out.Hooks = nil
 	}
+	if in.NodeTuning != nil {
+		in, out := &in.NodeTuning, &out.NodeTuning
+		*out = new(NodeTuningSpec)
+		if err := Convert_kops_NodeTuningSpec_To_v1alpha2_NodeTuningSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeTuning = nil
+	}
+	if in.HostFirewall != nil {
+		in, out := &in.HostFirewall, &out.HostFirewall
+		*out = new(HostFirewallSpec)
+		if err := Convert_kops_HostFirewallSpec_To_v1alpha2_HostFirewallSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.HostFirewall = nil
+	}
+	if in.NodeAgent != nil {
+		in, out := &in.NodeAgent, &out.NodeAgent
+		*out = new(NodeAgentSpec)
+		if err := Convert_kops_NodeAgentSpec_To_v1alpha2_NodeAgentSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeAgent = nil
+	}
 	if in.Assets != nil {
 		in, out := &in.Assets, &out.Assets
 		*out = new(Assets)
@@ -1219,6 +1394,7 @@ func autoConvert_kops_ClusterSpec_To_v1alpha2_ClusterSpec(in *kops.ClusterSpec,
 		out.IAM = nil
 	}
 	out.EncryptionConfig = in.EncryptionConfig
+	out.UploadBootReports = in.UploadBootReports
 	if in.Target != nil {
 		in, out := &in.Target, &out.Target
 		*out = new(TargetSpec)
@@ -1270,6 +1446,30 @@ func Convert_kops_ClusterSubnetSpec_To_v1alpha2_ClusterSubnetSpec(in *kops.Clust
 	return autoConvert_kops_ClusterSubnetSpec_To_v1alpha2_ClusterSubnetSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_ContainerdConfig_To_kops_ContainerdConfig(in *ContainerdConfig, out *kops.ContainerdConfig, s conversion.Scope) error {
+	out.LogLevel = in.LogLevel
+	out.RegistryMirrors = in.RegistryMirrors
+	out.Version = in.Version
+	return nil
+}
+
+// Convert_v1alpha2_ContainerdConfig_To_kops_ContainerdConfig is an autogenerated conversion function.
+func Convert_v1alpha2_ContainerdConfig_To_kops_ContainerdConfig(in *ContainerdConfig, out *kops.ContainerdConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha2_ContainerdConfig_To_kops_ContainerdConfig(in, out, s)
+}
+
+func autoConvert_kops_ContainerdConfig_To_v1alpha2_ContainerdConfig(in *kops.ContainerdConfig, out *ContainerdConfig, s conversion.Scope) error {
+	out.LogLevel = in.LogLevel
+	out.RegistryMirrors = in.RegistryMirrors
+	out.Version = in.Version
+	return nil
+}
+
+// Convert_kops_ContainerdConfig_To_v1alpha2_ContainerdConfig is an autogenerated conversion function.
+func Convert_kops_ContainerdConfig_To_v1alpha2_ContainerdConfig(in *kops.ContainerdConfig, out *ContainerdConfig, s conversion.Scope) error {
+	return autoConvert_kops_ContainerdConfig_To_v1alpha2_ContainerdConfig(in, out, s)
+}
+
 func autoConvert_v1alpha2_DNSAccessSpec_To_kops_DNSAccessSpec(in *DNSAccessSpec, out *kops.DNSAccessSpec, s conversion.Scope) error {
 	return nil
 }
@@ -1290,6 +1490,17 @@ func Convert_kops_DNSAccessSpec_To_v1alpha2_DNSAccessSpec(in *kops.DNSAccessSpec
 
 func autoConvert_v1alpha2_DNSSpec_To_kops_DNSSpec(in *DNSSpec, out *kops.DNSSpec, s conversion.Scope) error {
 	out.Type = kops.DNSType(in.Type)
+	if in.AdditionalVPCs != nil {
+		in, out := &in.AdditionalVPCs, &out.AdditionalVPCs
+		*out = make([]kops.DNSVPCSpec, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_DNSVPCSpec_To_kops_DNSVPCSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.AdditionalVPCs = nil
+	}
 	return nil
 }
 
@@ -1300,6 +1511,17 @@ func Convert_v1alpha2_DNSSpec_To_kops_DNSSpec(in *DNSSpec, out *kops.DNSSpec, s
 
 func autoConvert_kops_DNSSpec_To_v1alpha2_DNSSpec(in *kops.DNSSpec, out *DNSSpec, s conversion.Scope) error {
 	out.Type = DNSType(in.Type)
+	if in.AdditionalVPCs != nil {
+		in, out := &in.AdditionalVPCs, &out.AdditionalVPCs
+		*out = make([]DNSVPCSpec, len(*in))
+		for i := range *in {
+			if err := Convert_kops_DNSVPCSpec_To_v1alpha2_DNSVPCSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.AdditionalVPCs = nil
+	}
 	return nil
 }
 
@@ -1308,6 +1530,28 @@ func Convert_kops_DNSSpec_To_v1alpha2_DNSSpec(in *kops.DNSSpec, out *DNSSpec, s
 	return autoConvert_kops_DNSSpec_To_v1alpha2_DNSSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_DNSVPCSpec_To_kops_DNSVPCSpec(in *DNSVPCSpec, out *kops.DNSVPCSpec, s conversion.Scope) error {
+	out.ID = in.ID
+	out.Region = in.Region
+	return nil
+}
+
+// Convert_v1alpha2_DNSVPCSpec_To_kops_DNSVPCSpec is an autogenerated conversion function.
+func Convert_v1alpha2_DNSVPCSpec_To_kops_DNSVPCSpec(in *DNSVPCSpec, out *kops.DNSVPCSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_DNSVPCSpec_To_kops_DNSVPCSpec(in, out, s)
+}
+
+func autoConvert_kops_DNSVPCSpec_To_v1alpha2_DNSVPCSpec(in *kops.DNSVPCSpec, out *DNSVPCSpec, s conversion.Scope) error {
+	out.ID = in.ID
+	out.Region = in.Region
+	return nil
+}
+
+// Convert_kops_DNSVPCSpec_To_v1alpha2_DNSVPCSpec is an autogenerated conversion function.
+func Convert_kops_DNSVPCSpec_To_v1alpha2_DNSVPCSpec(in *kops.DNSVPCSpec, out *DNSVPCSpec, s conversion.Scope) error {
+	return autoConvert_kops_DNSVPCSpec_To_v1alpha2_DNSVPCSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_DockerConfig_To_kops_DockerConfig(in *DockerConfig, out *kops.DockerConfig, s conversion.Scope) error {
 	out.AuthorizationPlugins = in.AuthorizationPlugins
 	out.Bridge = in.Bridge
@@ -1546,6 +1790,9 @@ func autoConvert_v1alpha2_ExternalDNSConfig_To_kops_ExternalDNSConfig(in *Extern
 	out.Disable = in.Disable
 	out.WatchIngress = in.WatchIngress
 	out.WatchNamespace = in.WatchNamespace
+	out.TXTOwnerID = in.TXTOwnerID
+	out.Provider = in.Provider
+	out.ProviderConfig = in.ProviderConfig
 	return nil
 }
 
@@ -1558,6 +1805,9 @@ func autoConvert_kops_ExternalDNSConfig_To_v1alpha2_ExternalDNSConfig(in *kops.E
 	out.Disable = in.Disable
 	out.WatchIngress = in.WatchIngress
 	out.WatchNamespace = in.WatchNamespace
+	out.TXTOwnerID = in.TXTOwnerID
+	out.Provider = in.Provider
+	out.ProviderConfig = in.ProviderConfig
 	return nil
 }
 
@@ -1648,6 +1898,70 @@ func Convert_kops_FlannelNetworkingSpec_To_v1alpha2_FlannelNetworkingSpec(in *ko
 	return autoConvert_kops_FlannelNetworkingSpec_To_v1alpha2_FlannelNetworkingSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_GossipConfig_To_kops_GossipConfig(in *GossipConfig, out *kops.GossipConfig, s conversion.Scope) error {
+	out.Protocol = in.Protocol
+	out.Listen = in.Listen
+	if in.Secondary != nil {
+		in, out := &in.Secondary, &out.Secondary
+		*out = new(kops.GossipConfigSecondary)
+		if err := Convert_v1alpha2_GossipConfigSecondary_To_kops_GossipConfigSecondary(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Secondary = nil
+	}
+	out.SeedSRV = in.SeedSRV
+	return nil
+}
+
+// Convert_v1alpha2_GossipConfig_To_kops_GossipConfig is an autogenerated conversion function.
+func Convert_v1alpha2_GossipConfig_To_kops_GossipConfig(in *GossipConfig, out *kops.GossipConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha2_GossipConfig_To_kops_GossipConfig(in, out, s)
+}
+
+func autoConvert_kops_GossipConfig_To_v1alpha2_GossipConfig(in *kops.GossipConfig, out *GossipConfig, s conversion.Scope) error {
+	out.Protocol = in.Protocol
+	out.Listen = in.Listen
+	if in.Secondary != nil {
+		in, out := &in.Secondary, &out.Secondary
+		*out = new(GossipConfigSecondary)
+		if err := Convert_kops_GossipConfigSecondary_To_v1alpha2_GossipConfigSecondary(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Secondary = nil
+	}
+	out.SeedSRV = in.SeedSRV
+	return nil
+}
+
+// Convert_kops_GossipConfig_To_v1alpha2_GossipConfig is an autogenerated conversion function.
+func Convert_kops_GossipConfig_To_v1alpha2_GossipConfig(in *kops.GossipConfig, out *GossipConfig, s conversion.Scope) error {
+	return autoConvert_kops_GossipConfig_To_v1alpha2_GossipConfig(in, out, s)
+}
+
+func autoConvert_v1alpha2_GossipConfigSecondary_To_kops_GossipConfigSecondary(in *GossipConfigSecondary, out *kops.GossipConfigSecondary, s conversion.Scope) error {
+	out.Protocol = in.Protocol
+	out.Listen = in.Listen
+	return nil
+}
+
+// Convert_v1alpha2_GossipConfigSecondary_To_kops_GossipConfigSecondary is an autogenerated conversion function.
+func Convert_v1alpha2_GossipConfigSecondary_To_kops_GossipConfigSecondary(in *GossipConfigSecondary, out *kops.GossipConfigSecondary, s conversion.Scope) error {
+	return autoConvert_v1alpha2_GossipConfigSecondary_To_kops_GossipConfigSecondary(in, out, s)
+}
+
+func autoConvert_kops_GossipConfigSecondary_To_v1alpha2_GossipConfigSecondary(in *kops.GossipConfigSecondary, out *GossipConfigSecondary, s conversion.Scope) error {
+	out.Protocol = in.Protocol
+	out.Listen = in.Listen
+	return nil
+}
+
+// Convert_kops_GossipConfigSecondary_To_v1alpha2_GossipConfigSecondary is an autogenerated conversion function.
+func Convert_kops_GossipConfigSecondary_To_v1alpha2_GossipConfigSecondary(in *kops.GossipConfigSecondary, out *GossipConfigSecondary, s conversion.Scope) error {
+	return autoConvert_kops_GossipConfigSecondary_To_v1alpha2_GossipConfigSecondary(in, out, s)
+}
+
 func autoConvert_v1alpha2_HTTPProxy_To_kops_HTTPProxy(in *HTTPProxy, out *kops.HTTPProxy, s conversion.Scope) error {
 	out.Host = in.Host
 	out.Port = in.Port
@@ -1694,6 +2008,12 @@ func autoConvert_v1alpha2_HookSpec_To_kops_HookSpec(in *HookSpec, out *kops.Hook
 		out.ExecContainer = nil
 	}
 	out.Manifest = in.Manifest
+	out.Phase = in.Phase
+	out.RunPolicy = in.RunPolicy
+	out.Timeout = in.Timeout
+	out.Retries = in.Retries
+	out.SuccessExitCodes = in.SuccessExitCodes
+	out.EnvironmentFromSecrets = in.EnvironmentFromSecrets
 	return nil
 }
 
@@ -1726,6 +2046,12 @@ func autoConvert_kops_HookSpec_To_v1alpha2_HookSpec(in *kops.HookSpec, out *Hook
 		out.ExecContainer = nil
 	}
 	out.Manifest = in.Manifest
+	out.Phase = in.Phase
+	out.RunPolicy = in.RunPolicy
+	out.Timeout = in.Timeout
+	out.Retries = in.Retries
+	out.SuccessExitCodes = in.SuccessExitCodes
+	out.EnvironmentFromSecrets = in.EnvironmentFromSecrets
 	return nil
 }
 
@@ -1734,6 +2060,114 @@ func Convert_kops_HookSpec_To_v1alpha2_HookSpec(in *kops.HookSpec, out *HookSpec
 	return autoConvert_kops_HookSpec_To_v1alpha2_HookSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_HostFirewallRule_To_kops_HostFirewallRule(in *HostFirewallRule, out *kops.HostFirewallRule, s conversion.Scope) error {
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]kops.InstanceGroupRole, len(*in))
+		for i := range *in {
+			(*out)[i] = kops.InstanceGroupRole((*in)[i])
+		}
+	} else {
+		out.Roles = nil
+	}
+	out.Protocol = in.Protocol
+	out.Ports = in.Ports
+	out.CIDRs = in.CIDRs
+	return nil
+}
+
+// Convert_v1alpha2_HostFirewallRule_To_kops_HostFirewallRule is an autogenerated conversion function.
+func Convert_v1alpha2_HostFirewallRule_To_kops_HostFirewallRule(in *HostFirewallRule, out *kops.HostFirewallRule, s conversion.Scope) error {
+	return autoConvert_v1alpha2_HostFirewallRule_To_kops_HostFirewallRule(in, out, s)
+}
+
+func autoConvert_kops_HostFirewallRule_To_v1alpha2_HostFirewallRule(in *kops.HostFirewallRule, out *HostFirewallRule, s conversion.Scope) error {
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]InstanceGroupRole, len(*in))
+		for i := range *in {
+			(*out)[i] = InstanceGroupRole((*in)[i])
+		}
+	} else {
+		out.Roles = nil
+	}
+	out.Protocol = in.Protocol
+	out.Ports = in.Ports
+	out.CIDRs = in.CIDRs
+	return nil
+}
+
+// Convert_kops_HostFirewallRule_To_v1alpha2_HostFirewallRule is an autogenerated conversion function.
+func Convert_kops_HostFirewallRule_To_v1alpha2_HostFirewallRule(in *kops.HostFirewallRule, out *HostFirewallRule, s conversion.Scope) error {
+	return autoConvert_kops_HostFirewallRule_To_v1alpha2_HostFirewallRule(in, out, s)
+}
+
+func autoConvert_v1alpha2_HostFirewallSpec_To_kops_HostFirewallSpec(in *HostFirewallSpec, out *kops.HostFirewallSpec, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.Backend = in.Backend
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]kops.HostFirewallRule, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_HostFirewallRule_To_kops_HostFirewallRule(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Rules = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha2_HostFirewallSpec_To_kops_HostFirewallSpec is an autogenerated conversion function.
+func Convert_v1alpha2_HostFirewallSpec_To_kops_HostFirewallSpec(in *HostFirewallSpec, out *kops.HostFirewallSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_HostFirewallSpec_To_kops_HostFirewallSpec(in, out, s)
+}
+
+func autoConvert_kops_HostFirewallSpec_To_v1alpha2_HostFirewallSpec(in *kops.HostFirewallSpec, out *HostFirewallSpec, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.Backend = in.Backend
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]HostFirewallRule, len(*in))
+		for i := range *in {
+			if err := Convert_kops_HostFirewallRule_To_v1alpha2_HostFirewallRule(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Rules = nil
+	}
+	return nil
+}
+
+// Convert_kops_HostFirewallSpec_To_v1alpha2_HostFirewallSpec is an autogenerated conversion function.
+func Convert_kops_HostFirewallSpec_To_v1alpha2_HostFirewallSpec(in *kops.HostFirewallSpec, out *HostFirewallSpec, s conversion.Scope) error {
+	return autoConvert_kops_HostFirewallSpec_To_v1alpha2_HostFirewallSpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_HugepagesSpec_To_kops_HugepagesSpec(in *HugepagesSpec, out *kops.HugepagesSpec, s conversion.Scope) error {
+	out.PageSize = in.PageSize
+	out.Count = in.Count
+	return nil
+}
+
+// Convert_v1alpha2_HugepagesSpec_To_kops_HugepagesSpec is an autogenerated conversion function.
+func Convert_v1alpha2_HugepagesSpec_To_kops_HugepagesSpec(in *HugepagesSpec, out *kops.HugepagesSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_HugepagesSpec_To_kops_HugepagesSpec(in, out, s)
+}
+
+func autoConvert_kops_HugepagesSpec_To_v1alpha2_HugepagesSpec(in *kops.HugepagesSpec, out *HugepagesSpec, s conversion.Scope) error {
+	out.PageSize = in.PageSize
+	out.Count = in.Count
+	return nil
+}
+
+// Convert_kops_HugepagesSpec_To_v1alpha2_HugepagesSpec is an autogenerated conversion function.
+func Convert_kops_HugepagesSpec_To_v1alpha2_HugepagesSpec(in *kops.HugepagesSpec, out *HugepagesSpec, s conversion.Scope) error {
+	return autoConvert_kops_HugepagesSpec_To_v1alpha2_HugepagesSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_IAMSpec_To_kops_IAMSpec(in *IAMSpec, out *kops.IAMSpec, s conversion.Scope) error {
 	out.Legacy = in.Legacy
 	out.AllowContainerRegistry = in.AllowContainerRegistry
@@ -1830,6 +2264,7 @@ func autoConvert_v1alpha2_InstanceGroupSpec_To_kops_InstanceGroupSpec(in *Instan
 	out.MinSize = in.MinSize
 	out.MaxSize = in.MaxSize
 	out.MachineType = in.MachineType
+	out.Architecture = in.Architecture
 	out.RootVolumeSize = in.RootVolumeSize
 	out.RootVolumeType = in.RootVolumeType
 	out.RootVolumeIops = in.RootVolumeIops
@@ -1873,6 +2308,54 @@ func autoConvert_v1alpha2_InstanceGroupSpec_To_kops_InstanceGroupSpec(in *Instan
 	} else {
 		out.Kubelet = nil
 	}
+	out.ContainerRuntime = in.ContainerRuntime
+	if in.NodeBundle != nil {
+		in, out := &in.NodeBundle, &out.NodeBundle
+		*out = new(kops.NodeBundleSpec)
+		if err := Convert_v1alpha2_NodeBundleSpec_To_kops_NodeBundleSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeBundle = nil
+	}
+	if in.NodeTuning != nil {
+		in, out := &in.NodeTuning, &out.NodeTuning
+		*out = new(kops.NodeTuningSpec)
+		if err := Convert_v1alpha2_NodeTuningSpec_To_kops_NodeTuningSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeTuning = nil
+	}
+	if in.HostFirewall != nil {
+		in, out := &in.HostFirewall, &out.HostFirewall
+		*out = new(kops.HostFirewallSpec)
+		if err := Convert_v1alpha2_HostFirewallSpec_To_kops_HostFirewallSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.HostFirewall = nil
+	}
+	if in.NodeAgent != nil {
+		in, out := &in.NodeAgent, &out.NodeAgent
+		*out = new(kops.NodeAgentSpec)
+		if err := Convert_v1alpha2_NodeAgentSpec_To_kops_NodeAgentSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeAgent = nil
+	}
+	if in.NodeAddons != nil {
+		in, out := &in.NodeAddons, &out.NodeAddons
+		*out = make([]kops.NodeAddonSpec, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_NodeAddonSpec_To_kops_NodeAddonSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.NodeAddons = nil
+	}
 	out.Taints = in.Taints
 	if in.AdditionalUserData != nil {
 		in, out := &in.AdditionalUserData, &out.AdditionalUserData
@@ -1901,6 +2384,7 @@ func autoConvert_kops_InstanceGroupSpec_To_v1alpha2_InstanceGroupSpec(in *kops.I
 	out.MinSize = in.MinSize
 	out.MaxSize = in.MaxSize
 	out.MachineType = in.MachineType
+	out.Architecture = in.Architecture
 	out.RootVolumeSize = in.RootVolumeSize
 	out.RootVolumeType = in.RootVolumeType
 	out.RootVolumeIops = in.RootVolumeIops
@@ -1944,6 +2428,54 @@ func autoConvert_kops_InstanceGroupSpec_To_v1alpha2_InstanceGroupSpec(in *kops.I
 	} else {
 		out.Kubelet = nil
 	}
+	out.ContainerRuntime = in.ContainerRuntime
+	if in.NodeBundle != nil {
+		in, out := &in.NodeBundle, &out.NodeBundle
+		*out = new(NodeBundleSpec)
+		if err := Convert_kops_NodeBundleSpec_To_v1alpha2_NodeBundleSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeBundle = nil
+	}
+	if in.NodeTuning != nil {
+		in, out := &in.NodeTuning, &out.NodeTuning
+		*out = new(NodeTuningSpec)
+		if err := Convert_kops_NodeTuningSpec_To_v1alpha2_NodeTuningSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeTuning = nil
+	}
+	if in.HostFirewall != nil {
+		in, out := &in.HostFirewall, &out.HostFirewall
+		*out = new(HostFirewallSpec)
+		if err := Convert_kops_HostFirewallSpec_To_v1alpha2_HostFirewallSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.HostFirewall = nil
+	}
+	if in.NodeAgent != nil {
+		in, out := &in.NodeAgent, &out.NodeAgent
+		*out = new(NodeAgentSpec)
+		if err := Convert_kops_NodeAgentSpec_To_v1alpha2_NodeAgentSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeAgent = nil
+	}
+	if in.NodeAddons != nil {
+		in, out := &in.NodeAddons, &out.NodeAddons
+		*out = make([]NodeAddonSpec, len(*in))
+		for i := range *in {
+			if err := Convert_kops_NodeAddonSpec_To_v1alpha2_NodeAddonSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.NodeAddons = nil
+	}
 	out.Taints = in.Taints
 	if in.AdditionalUserData != nil {
 		in, out := &in.AdditionalUserData, &out.AdditionalUserData
@@ -2522,6 +3054,8 @@ func autoConvert_v1alpha2_KubeletConfigSpec_To_kops_KubeletConfigSpec(in *Kubele
 	out.SystemReservedCgroup = in.SystemReservedCgroup
 	out.EnforceNodeAllocatable = in.EnforceNodeAllocatable
 	out.RuntimeRequestTimeout = in.RuntimeRequestTimeout
+	out.ContainerRuntime = in.ContainerRuntime
+	out.ContainerRuntimeEndpoint = in.ContainerRuntimeEndpoint
 	out.VolumeStatsAggPeriod = in.VolumeStatsAggPeriod
 	out.FailSwapOn = in.FailSwapOn
 	return nil
@@ -2588,6 +3122,8 @@ func autoConvert_kops_KubeletConfigSpec_To_v1alpha2_KubeletConfigSpec(in *kops.K
 	out.SystemReservedCgroup = in.SystemReservedCgroup
 	out.EnforceNodeAllocatable = in.EnforceNodeAllocatable
 	out.RuntimeRequestTimeout = in.RuntimeRequestTimeout
+	out.ContainerRuntime = in.ContainerRuntime
+	out.ContainerRuntimeEndpoint = in.ContainerRuntimeEndpoint
 	out.VolumeStatsAggPeriod = in.VolumeStatsAggPeriod
 	out.FailSwapOn = in.FailSwapOn
 	return nil
@@ -2930,6 +3466,318 @@ func Convert_kops_NetworkingSpec_To_v1alpha2_NetworkingSpec(in *kops.NetworkingS
 	return autoConvert_kops_NetworkingSpec_To_v1alpha2_NetworkingSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_NodeAddonHealthCheckSpec_To_kops_NodeAddonHealthCheckSpec(in *NodeAddonHealthCheckSpec, out *kops.NodeAddonHealthCheckSpec, s conversion.Scope) error {
+	out.Exec = in.Exec
+	out.Socket = in.Socket
+	out.Timeout = in.Timeout
+	return nil
+}
+
+// Convert_v1alpha2_NodeAddonHealthCheckSpec_To_kops_NodeAddonHealthCheckSpec is an autogenerated conversion function.
+func Convert_v1alpha2_NodeAddonHealthCheckSpec_To_kops_NodeAddonHealthCheckSpec(in *NodeAddonHealthCheckSpec, out *kops.NodeAddonHealthCheckSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_NodeAddonHealthCheckSpec_To_kops_NodeAddonHealthCheckSpec(in, out, s)
+}
+
+func autoConvert_kops_NodeAddonHealthCheckSpec_To_v1alpha2_NodeAddonHealthCheckSpec(in *kops.NodeAddonHealthCheckSpec, out *NodeAddonHealthCheckSpec, s conversion.Scope) error {
+	out.Exec = in.Exec
+	out.Socket = in.Socket
+	out.Timeout = in.Timeout
+	return nil
+}
+
+// Convert_kops_NodeAddonHealthCheckSpec_To_v1alpha2_NodeAddonHealthCheckSpec is an autogenerated conversion function.
+func Convert_kops_NodeAddonHealthCheckSpec_To_v1alpha2_NodeAddonHealthCheckSpec(in *kops.NodeAddonHealthCheckSpec, out *NodeAddonHealthCheckSpec, s conversion.Scope) error {
+	return autoConvert_kops_NodeAddonHealthCheckSpec_To_v1alpha2_NodeAddonHealthCheckSpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_NodeAddonSpec_To_kops_NodeAddonSpec(in *NodeAddonSpec, out *kops.NodeAddonSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Type = in.Type
+	out.Version = in.Version
+	out.Source = in.Source
+	out.Hash = in.Hash
+	out.Image = in.Image
+	out.Args = in.Args
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(kops.NodeAddonHealthCheckSpec)
+		if err := Convert_v1alpha2_NodeAddonHealthCheckSpec_To_kops_NodeAddonHealthCheckSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.HealthCheck = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha2_NodeAddonSpec_To_kops_NodeAddonSpec is an autogenerated conversion function.
+func Convert_v1alpha2_NodeAddonSpec_To_kops_NodeAddonSpec(in *NodeAddonSpec, out *kops.NodeAddonSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_NodeAddonSpec_To_kops_NodeAddonSpec(in, out, s)
+}
+
+func autoConvert_kops_NodeAddonSpec_To_v1alpha2_NodeAddonSpec(in *kops.NodeAddonSpec, out *NodeAddonSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Type = in.Type
+	out.Version = in.Version
+	out.Source = in.Source
+	out.Hash = in.Hash
+	out.Image = in.Image
+	out.Args = in.Args
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(NodeAddonHealthCheckSpec)
+		if err := Convert_kops_NodeAddonHealthCheckSpec_To_v1alpha2_NodeAddonHealthCheckSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.HealthCheck = nil
+	}
+	return nil
+}
+
+// Convert_kops_NodeAddonSpec_To_v1alpha2_NodeAddonSpec is an autogenerated conversion function.
+func Convert_kops_NodeAddonSpec_To_v1alpha2_NodeAddonSpec(in *kops.NodeAddonSpec, out *NodeAddonSpec, s conversion.Scope) error {
+	return autoConvert_kops_NodeAddonSpec_To_v1alpha2_NodeAddonSpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_NodeAgentSpec_To_kops_NodeAgentSpec(in *NodeAgentSpec, out *kops.NodeAgentSpec, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.Interval = in.Interval
+	return nil
+}
+
+// Convert_v1alpha2_NodeAgentSpec_To_kops_NodeAgentSpec is an autogenerated conversion function.
+func Convert_v1alpha2_NodeAgentSpec_To_kops_NodeAgentSpec(in *NodeAgentSpec, out *kops.NodeAgentSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_NodeAgentSpec_To_kops_NodeAgentSpec(in, out, s)
+}
+
+func autoConvert_kops_NodeAgentSpec_To_v1alpha2_NodeAgentSpec(in *kops.NodeAgentSpec, out *NodeAgentSpec, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.Interval = in.Interval
+	return nil
+}
+
+// Convert_kops_NodeAgentSpec_To_v1alpha2_NodeAgentSpec is an autogenerated conversion function.
+func Convert_kops_NodeAgentSpec_To_v1alpha2_NodeAgentSpec(in *kops.NodeAgentSpec, out *NodeAgentSpec, s conversion.Scope) error {
+	return autoConvert_kops_NodeAgentSpec_To_v1alpha2_NodeAgentSpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_NodeBundleSpec_To_kops_NodeBundleSpec(in *NodeBundleSpec, out *kops.NodeBundleSpec, s conversion.Scope) error {
+	out.Location = in.Location
+	out.Hash = in.Hash
+	return nil
+}
+
+// Convert_v1alpha2_NodeBundleSpec_To_kops_NodeBundleSpec is an autogenerated conversion function.
+func Convert_v1alpha2_NodeBundleSpec_To_kops_NodeBundleSpec(in *NodeBundleSpec, out *kops.NodeBundleSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_NodeBundleSpec_To_kops_NodeBundleSpec(in, out, s)
+}
+
+func autoConvert_kops_NodeBundleSpec_To_v1alpha2_NodeBundleSpec(in *kops.NodeBundleSpec, out *NodeBundleSpec, s conversion.Scope) error {
+	out.Location = in.Location
+	out.Hash = in.Hash
+	return nil
+}
+
+// Convert_kops_NodeBundleSpec_To_v1alpha2_NodeBundleSpec is an autogenerated conversion function.
+func Convert_kops_NodeBundleSpec_To_v1alpha2_NodeBundleSpec(in *kops.NodeBundleSpec, out *NodeBundleSpec, s conversion.Scope) error {
+	return autoConvert_kops_NodeBundleSpec_To_v1alpha2_NodeBundleSpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_NodeTuningSpec_To_kops_NodeTuningSpec(in *NodeTuningSpec, out *kops.NodeTuningSpec, s conversion.Scope) error {
+	out.SysctlParameters = in.SysctlParameters
+	out.KernelModules = in.KernelModules
+	if in.Ulimits != nil {
+		in, out := &in.Ulimits, &out.Ulimits
+		*out = new(kops.UlimitsSpec)
+		if err := Convert_v1alpha2_UlimitsSpec_To_kops_UlimitsSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Ulimits = nil
+	}
+	if in.Hugepages != nil {
+		in, out := &in.Hugepages, &out.Hugepages
+		*out = new(kops.HugepagesSpec)
+		if err := Convert_v1alpha2_HugepagesSpec_To_kops_HugepagesSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Hugepages = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha2_NodeTuningSpec_To_kops_NodeTuningSpec is an autogenerated conversion function.
+func Convert_v1alpha2_NodeTuningSpec_To_kops_NodeTuningSpec(in *NodeTuningSpec, out *kops.NodeTuningSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_NodeTuningSpec_To_kops_NodeTuningSpec(in, out, s)
+}
+
+func autoConvert_kops_NodeTuningSpec_To_v1alpha2_NodeTuningSpec(in *kops.NodeTuningSpec, out *NodeTuningSpec, s conversion.Scope) error {
+	out.SysctlParameters = in.SysctlParameters
+	out.KernelModules = in.KernelModules
+	if in.Ulimits != nil {
+		in, out := &in.Ulimits, &out.Ulimits
+		*out = new(UlimitsSpec)
+		if err := Convert_kops_UlimitsSpec_To_v1alpha2_UlimitsSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Ulimits = nil
+	}
+	if in.Hugepages != nil {
+		in, out := &in.Hugepages, &out.Hugepages
+		*out = new(HugepagesSpec)
+		if err := Convert_kops_HugepagesSpec_To_v1alpha2_HugepagesSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Hugepages = nil
+	}
+	return nil
+}
+
+// Convert_kops_NodeTuningSpec_To_v1alpha2_NodeTuningSpec is an autogenerated conversion function.
+func Convert_kops_NodeTuningSpec_To_v1alpha2_NodeTuningSpec(in *kops.NodeTuningSpec, out *NodeTuningSpec, s conversion.Scope) error {
+	return autoConvert_kops_NodeTuningSpec_To_v1alpha2_NodeTuningSpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_Operation_To_kops_Operation(in *Operation, out *kops.Operation, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha2_OperationSpec_To_kops_OperationSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_v1alpha2_OperationStatus_To_kops_OperationStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha2_Operation_To_kops_Operation is an autogenerated conversion function.
+func Convert_v1alpha2_Operation_To_kops_Operation(in *Operation, out *kops.Operation, s conversion.Scope) error {
+	return autoConvert_v1alpha2_Operation_To_kops_Operation(in, out, s)
+}
+
+func autoConvert_kops_Operation_To_v1alpha2_Operation(in *kops.Operation, out *Operation, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_kops_OperationSpec_To_v1alpha2_OperationSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_kops_OperationStatus_To_v1alpha2_OperationStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_kops_Operation_To_v1alpha2_Operation is an autogenerated conversion function.
+func Convert_kops_Operation_To_v1alpha2_Operation(in *kops.Operation, out *Operation, s conversion.Scope) error {
+	return autoConvert_kops_Operation_To_v1alpha2_Operation(in, out, s)
+}
+
+func autoConvert_v1alpha2_OperationList_To_kops_OperationList(in *OperationList, out *kops.OperationList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]kops.Operation, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_Operation_To_kops_Operation(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha2_OperationList_To_kops_OperationList is an autogenerated conversion function.
+func Convert_v1alpha2_OperationList_To_kops_OperationList(in *OperationList, out *kops.OperationList, s conversion.Scope) error {
+	return autoConvert_v1alpha2_OperationList_To_kops_OperationList(in, out, s)
+}
+
+func autoConvert_kops_OperationList_To_v1alpha2_OperationList(in *kops.OperationList, out *OperationList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Operation, len(*in))
+		for i := range *in {
+			if err := Convert_kops_Operation_To_v1alpha2_Operation(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	return nil
+}
+
+// Convert_kops_OperationList_To_v1alpha2_OperationList is an autogenerated conversion function.
+func Convert_kops_OperationList_To_v1alpha2_OperationList(in *kops.OperationList, out *OperationList, s conversion.Scope) error {
+	return autoConvert_kops_OperationList_To_v1alpha2_OperationList(in, out, s)
+}
+
+func autoConvert_v1alpha2_OperationSpec_To_kops_OperationSpec(in *OperationSpec, out *kops.OperationSpec, s conversion.Scope) error {
+	out.ClusterName = in.ClusterName
+	out.Action = kops.OperationAction(in.Action)
+	out.User = in.User
+	out.InstanceGroups = in.InstanceGroups
+	out.Force = in.Force
+	out.CloudOnly = in.CloudOnly
+	return nil
+}
+
+// Convert_v1alpha2_OperationSpec_To_kops_OperationSpec is an autogenerated conversion function.
+func Convert_v1alpha2_OperationSpec_To_kops_OperationSpec(in *OperationSpec, out *kops.OperationSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_OperationSpec_To_kops_OperationSpec(in, out, s)
+}
+
+func autoConvert_kops_OperationSpec_To_v1alpha2_OperationSpec(in *kops.OperationSpec, out *OperationSpec, s conversion.Scope) error {
+	out.ClusterName = in.ClusterName
+	out.Action = OperationAction(in.Action)
+	out.User = in.User
+	out.InstanceGroups = in.InstanceGroups
+	out.Force = in.Force
+	out.CloudOnly = in.CloudOnly
+	return nil
+}
+
+// Convert_kops_OperationSpec_To_v1alpha2_OperationSpec is an autogenerated conversion function.
+func Convert_kops_OperationSpec_To_v1alpha2_OperationSpec(in *kops.OperationSpec, out *OperationSpec, s conversion.Scope) error {
+	return autoConvert_kops_OperationSpec_To_v1alpha2_OperationSpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_OperationStatus_To_kops_OperationStatus(in *OperationStatus, out *kops.OperationStatus, s conversion.Scope) error {
+	out.Phase = kops.OperationPhase(in.Phase)
+	out.StartTime = in.StartTime
+	out.CompletionTime = in.CompletionTime
+	out.Message = in.Message
+	out.Output = in.Output
+	out.Owner = in.Owner
+	out.RenewTime = in.RenewTime
+	return nil
+}
+
+// Convert_v1alpha2_OperationStatus_To_kops_OperationStatus is an autogenerated conversion function.
+func Convert_v1alpha2_OperationStatus_To_kops_OperationStatus(in *OperationStatus, out *kops.OperationStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha2_OperationStatus_To_kops_OperationStatus(in, out, s)
+}
+
+func autoConvert_kops_OperationStatus_To_v1alpha2_OperationStatus(in *kops.OperationStatus, out *OperationStatus, s conversion.Scope) error {
+	out.Phase = OperationPhase(in.Phase)
+	out.StartTime = in.StartTime
+	out.CompletionTime = in.CompletionTime
+	out.Message = in.Message
+	out.Output = in.Output
+	out.Owner = in.Owner
+	out.RenewTime = in.RenewTime
+	return nil
+}
+
+// Convert_kops_OperationStatus_To_v1alpha2_OperationStatus is an autogenerated conversion function.
+func Convert_kops_OperationStatus_To_v1alpha2_OperationStatus(in *kops.OperationStatus, out *OperationStatus, s conversion.Scope) error {
+	return autoConvert_kops_OperationStatus_To_v1alpha2_OperationStatus(in, out, s)
+}
+
 func autoConvert_v1alpha2_RBACAuthorizationSpec_To_kops_RBACAuthorizationSpec(in *RBACAuthorizationSpec, out *kops.RBACAuthorizationSpec, s conversion.Scope) error {
 	return nil
 }
@@ -3172,6 +4020,32 @@ func Convert_kops_TopologySpec_To_v1alpha2_TopologySpec(in *kops.TopologySpec, o
 	return autoConvert_kops_TopologySpec_To_v1alpha2_TopologySpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_UlimitsSpec_To_kops_UlimitsSpec(in *UlimitsSpec, out *kops.UlimitsSpec, s conversion.Scope) error {
+	out.NoFile = in.NoFile
+	out.NProc = in.NProc
+	out.MemLock = in.MemLock
+	out.Core = in.Core
+	return nil
+}
+
+// Convert_v1alpha2_UlimitsSpec_To_kops_UlimitsSpec is an autogenerated conversion function.
+func Convert_v1alpha2_UlimitsSpec_To_kops_UlimitsSpec(in *UlimitsSpec, out *kops.UlimitsSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_UlimitsSpec_To_kops_UlimitsSpec(in, out, s)
+}
+
+func autoConvert_kops_UlimitsSpec_To_v1alpha2_UlimitsSpec(in *kops.UlimitsSpec, out *UlimitsSpec, s conversion.Scope) error {
+	out.NoFile = in.NoFile
+	out.NProc = in.NProc
+	out.MemLock = in.MemLock
+	out.Core = in.Core
+	return nil
+}
+
+// Convert_kops_UlimitsSpec_To_v1alpha2_UlimitsSpec is an autogenerated conversion function.
+func Convert_kops_UlimitsSpec_To_v1alpha2_UlimitsSpec(in *kops.UlimitsSpec, out *UlimitsSpec, s conversion.Scope) error {
+	return autoConvert_kops_UlimitsSpec_To_v1alpha2_UlimitsSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_UserData_To_kops_UserData(in *UserData, out *kops.UserData, s conversion.Scope) error {
 	out.Name = in.Name
 	out.Type = in.Type
@@ -19,8 +19,11 @@ package validation
 import (
 	"net"
 	"net/url"
+	"strconv"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kops/pkg/apis/kops"
 )
 
 // isSubnet checks if child is a subnet of parent
@@ -70,3 +73,26 @@ func IsValidValue(fldPath *field.Path, v *string, validValues []string) field.Er
 	}
 	return allErrs
 }
+
+// instanceGroupRoleNames returns the names of the instance group roles
+func instanceGroupRoleNames() []string {
+	var names []string
+	for _, role := range kops.AllInstanceGroupRoles {
+		names = append(names, string(role))
+	}
+	return names
+}
+
+// isValidPortRange checks if s is a port (e.g. 9100) or a range of ports (e.g. 30000-32767)
+func isValidPortRange(s string) bool {
+	tokens := strings.SplitN(s, "-", 2)
+	var ports []int
+	for _, token := range tokens {
+		port, err := strconv.Atoi(token)
+		if err != nil || port < 1 || port > 65535 {
+			return false
+		}
+		ports = append(ports, port)
+	}
+	return len(ports) == 1 || ports[0] <= ports[1]
+}
@@ -18,11 +18,18 @@ package validation
 
 import (
 	"fmt"
+	"net/url"
+	"path"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/model"
 	"k8s.io/kops/pkg/apis/kops/util"
+	"k8s.io/kops/pkg/dns"
 	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/architectures"
+	"k8s.io/kops/util/pkg/hashing"
 )
 
 func ValidateInstanceGroup(g *kops.InstanceGroup) error {
@@ -46,6 +53,12 @@ func ValidateInstanceGroup(g *kops.InstanceGroup) error {
 		}
 	}
 
+	if g.Spec.Architecture != "" {
+		if _, err := architectures.Parse(g.Spec.Architecture); err != nil {
+			return field.NotSupported(field.NewPath("Architecture"), g.Spec.Architecture, architectureNames())
+		}
+	}
+
 	if fi.Int32Value(g.Spec.RootVolumeIops) < 0 {
 		return field.Invalid(field.NewPath("RootVolumeIops"), g.Spec.RootVolumeIops, "RootVolumeIops must be greater than 0")
 	}
@@ -74,9 +87,155 @@ func ValidateInstanceGroup(g *kops.InstanceGroup) error {
 		}
 	}
 
+	if g.Spec.NodeBundle != nil {
+		if err := validateNodeBundle(g.Spec.NodeBundle, field.NewPath("NodeBundle")); err != nil {
+			return err
+		}
+	}
+
+	if g.Spec.NodeTuning != nil {
+		if errs := validateNodeTuning(g.Spec.NodeTuning, field.NewPath("NodeTuning")); len(errs) != 0 {
+			return errs.ToAggregate()
+		}
+	}
+
+	for i := range g.Spec.Hooks {
+		if errs := validateHookSpec(&g.Spec.Hooks[i], field.NewPath("Hooks").Index(i)); len(errs) != 0 {
+			return errs.ToAggregate()
+		}
+	}
+
+	if g.Spec.HostFirewall != nil {
+		if errs := validateHostFirewall(g.Spec.HostFirewall, field.NewPath("HostFirewall")); len(errs) != 0 {
+			return errs.ToAggregate()
+		}
+	}
+
+	if g.Spec.NodeAgent != nil {
+		if errs := validateNodeAgent(g.Spec.NodeAgent, field.NewPath("NodeAgent")); len(errs) != 0 {
+			return errs.ToAggregate()
+		}
+	}
+
+	if errs := validateNodeAddons(g.Spec.NodeAddons, field.NewPath("NodeAddons")); len(errs) != 0 {
+		return errs.ToAggregate()
+	}
+
 	return nil
 }
 
+// validateNodeBundle checks that the location and the sha256 hash of a node bundle are set
+func validateNodeBundle(spec *kops.NodeBundleSpec, fieldPath *field.Path) error {
+	if spec.Location == "" {
+		return field.Required(fieldPath.Child("Location"), "the location of the node bundle must be set")
+	}
+
+	if spec.Hash == "" {
+		return field.Required(fieldPath.Child("Hash"), "the hash of the node bundle must be set")
+	}
+	if _, err := hashing.HashAlgorithmSHA256.FromString(spec.Hash); err != nil {
+		return field.Invalid(fieldPath.Child("Hash"), spec.Hash, "the hash of the node bundle must be a sha256 hash")
+	}
+
+	return nil
+}
+
+// validateNodeAddons checks the node add-ons can be installed: binaries are installed as files named for the add-on,
+// and containers are run as systemd services named for the add-on
+func validateNodeAddons(addons []kops.NodeAddonSpec, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	names := make(map[string]bool)
+	for i := range addons {
+		addon := &addons[i]
+		addonPath := fieldPath.Index(i)
+
+		if addon.Name == "" {
+			allErrs = append(allErrs, field.Required(addonPath.Child("name"), "the name of the node add-on must be set"))
+		} else if !nodeAddonNameRegex.MatchString(addon.Name) {
+			allErrs = append(allErrs, field.Invalid(addonPath.Child("name"), addon.Name, "the name may only contain letters, numbers, '.', '_', '-' and '~'"))
+		} else if names[addon.Name] {
+			allErrs = append(allErrs, field.Duplicate(addonPath.Child("name"), addon.Name))
+		}
+		names[addon.Name] = true
+
+		if addon.Type == "" {
+			allErrs = append(allErrs, field.Required(addonPath.Child("type"), "the type of the node add-on must be set"))
+		} else {
+			allErrs = append(allErrs, IsValidValue(addonPath.Child("type"), &addon.Type, validNodeAddonTypes)...)
+		}
+
+		if addon.Version == "" {
+			allErrs = append(allErrs, field.Required(addonPath.Child("version"), "the version of the node add-on must be set"))
+		}
+
+		if addon.Source == "" && addon.Image == "" {
+			allErrs = append(allErrs, field.Required(addonPath.Child("source"), "one of source or image must be set"))
+		}
+		if addon.Source != "" && addon.Image != "" {
+			allErrs = append(allErrs, field.Forbidden(addonPath.Child("image"), "only one of source or image may be set"))
+		}
+		if len(addon.Args) != 0 && addon.Image == "" {
+			allErrs = append(allErrs, field.Forbidden(addonPath.Child("args"), "args can only be passed to a container image"))
+		}
+
+		switch addon.Type {
+		case kops.NodeAddonTypeCNIPlugin:
+			if addon.Image != "" {
+				allErrs = append(allErrs, field.Forbidden(addonPath.Child("image"), "CNI plugins are installed from a binary source"))
+			}
+		case kops.NodeAddonTypeDevicePlugin:
+			if addon.Source != "" {
+				allErrs = append(allErrs, field.Forbidden(addonPath.Child("source"), "device plugins are run from a container image"))
+			}
+		case kops.NodeAddonTypeKubeletPlugin:
+			// binary kubelet plugins are flexvolume drivers, which the kubelet finds at <vendor~driver>/<driver>
+			if addon.Source != "" && !flexVolumeDriverRegex.MatchString(addon.Name) {
+				allErrs = append(allErrs, field.Invalid(addonPath.Child("name"), addon.Name, "the name of a kubelet plugin binary must be of the form vendor~driver"))
+			}
+		}
+
+		if addon.Source != "" {
+			u, err := url.Parse(addon.Source)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || path.Base(u.Path) == "/" || path.Base(u.Path) == "." {
+				allErrs = append(allErrs, field.Invalid(addonPath.Child("source"), addon.Source, "the source must be an http or https URL of a file"))
+			}
+		}
+		if addon.Hash != "" {
+			if addon.Source == "" {
+				allErrs = append(allErrs, field.Forbidden(addonPath.Child("hash"), "the hash can only be set for a binary source"))
+			} else if _, err := hashing.FromString(addon.Hash); err != nil {
+				allErrs = append(allErrs, field.Invalid(addonPath.Child("hash"), addon.Hash, "the hash must be a sha1 or sha256 hash"))
+			}
+		}
+
+		if addon.HealthCheck != nil {
+			allErrs = append(allErrs, validateNodeAddonHealthCheck(addon.HealthCheck, addonPath.Child("healthCheck"))...)
+		}
+	}
+
+	return allErrs
+}
+
+func validateNodeAddonHealthCheck(check *kops.NodeAddonHealthCheckSpec, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if len(check.Exec) == 0 && check.Socket == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("exec"), "one of exec or socket must be set"))
+	}
+	if len(check.Exec) != 0 && check.Socket != "" {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("socket"), "only one of exec or socket may be set"))
+	}
+	if check.Socket != "" && !path.IsAbs(check.Socket) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("socket"), check.Socket, "the socket must be an absolute path"))
+	}
+	if check.Timeout != nil && check.Timeout.Duration < time.Second {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("timeout"), check.Timeout.Duration.String(), "the timeout must be at least one second"))
+	}
+
+	return allErrs
+}
+
 // CrossValidateInstanceGroup performs validation of the instance group, including that it is consistent with the Cluster
 // It calls ValidateInstanceGroup, so all that validation is included.
 func CrossValidateInstanceGroup(g *kops.InstanceGroup, cluster *kops.Cluster, strict bool) error {
@@ -119,6 +278,8 @@ func CrossValidateInstanceGroup(g *kops.InstanceGroup, cluster *kops.Cluster, st
 		}
 	}
 
+	allErrs = append(allErrs, validateInstanceGroupContainerRuntime(g, cluster, fieldPath.Child("Spec").Child("ContainerRuntime"))...)
+
 	if len(allErrs) != 0 {
 		return allErrs[0]
 	}
@@ -126,6 +287,63 @@ func CrossValidateInstanceGroup(g *kops.InstanceGroup, cluster *kops.Cluster, st
 	return nil
 }
 
+// validateInstanceGroupContainerRuntime checks that the container runtime of the instance group can run everything
+// that kops runs on the instances; protokube and hooks with execContainer are run with docker
+func validateInstanceGroupContainerRuntime(g *kops.InstanceGroup, cluster *kops.Cluster, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if g.Spec.ContainerRuntime != "" {
+		allErrs = append(allErrs, IsValidValue(fieldPath, &g.Spec.ContainerRuntime, kops.ContainerRuntimes)...)
+	}
+
+	runtime := model.ContainerRuntime(cluster, g)
+	if runtime == kops.ContainerRuntimeDocker {
+		return allErrs
+	}
+
+	if g.IsMaster() {
+		allErrs = append(allErrs, field.Invalid(fieldPath, runtime, "masters must use docker, because protokube runs in docker"))
+	}
+	if dns.IsGossipHostname(cluster.ObjectMeta.Name) {
+		allErrs = append(allErrs, field.Invalid(fieldPath, runtime, "gossip clusters must use docker, because protokube runs in docker on every instance"))
+	}
+	if cluster.Spec.Networking != nil && cluster.Spec.Networking.Kubenet != nil {
+		allErrs = append(allErrs, field.Invalid(fieldPath, runtime, "kubenet networking is only supported with docker; use a CNI networking provider"))
+	}
+
+	hooks := append([]kops.HookSpec{}, cluster.Spec.Hooks...)
+	hooks = append(hooks, g.Spec.Hooks...)
+	for _, hook := range hooks {
+		if hook.ExecContainer == nil {
+			continue
+		}
+		if len(hook.Roles) != 0 && !hookAppliesToRole(hook, g.Spec.Role) {
+			continue
+		}
+		allErrs = append(allErrs, field.Invalid(fieldPath, runtime, "instance groups with execContainer hooks must use docker, because the hooks run in docker"))
+		break
+	}
+
+	for _, addon := range g.Spec.NodeAddons {
+		if addon.Image != "" {
+			allErrs = append(allErrs, field.Invalid(fieldPath, runtime, "instance groups with node add-on images must use docker, because the add-ons run in docker"))
+			break
+		}
+	}
+
+	return allErrs
+}
+
+// hookAppliesToRole returns true if the hook lists the role
+func hookAppliesToRole(hook kops.HookSpec, role kops.InstanceGroupRole) bool {
+	for _, r := range hook.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
 func validateExtraUserData(userData *kops.UserData) error {
 	fieldPath := field.NewPath("AdditionalUserData")
 
@@ -153,3 +371,12 @@ func validateExtraUserData(userData *kops.UserData) error {
 
 	return nil
 }
+
+// architectureNames returns the names of the supported architectures
+func architectureNames() []string {
+	var names []string
+	for _, a := range architectures.All() {
+		names = append(names, string(a))
+	}
+	return names
+}
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kops/pkg/apis/kops"
 )
 
@@ -64,3 +65,172 @@ func TestDefaultTaintsEnforcedBefore160(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateInstanceGroupContainerRuntime(t *testing.T) {
+	grid := []struct {
+		clusterName string
+		runtime     string
+		role        kops.InstanceGroupRole
+		networking  *kops.NetworkingSpec
+		hooks       []kops.HookSpec
+		expected    []string
+	}{
+		{
+			clusterName: "example.com",
+			runtime:     kops.ContainerRuntimeContainerd,
+			role:        kops.InstanceGroupRoleNode,
+			networking:  &kops.NetworkingSpec{Weave: &kops.WeaveNetworkingSpec{}},
+		},
+		{
+			clusterName: "example.com",
+			runtime:     kops.ContainerRuntimeDocker,
+			role:        kops.InstanceGroupRoleMaster,
+			networking:  &kops.NetworkingSpec{Kubenet: &kops.KubenetNetworkingSpec{}},
+		},
+		{
+			clusterName: "example.com",
+			runtime:     "rkt",
+			role:        kops.InstanceGroupRoleNode,
+			networking:  &kops.NetworkingSpec{Weave: &kops.WeaveNetworkingSpec{}},
+			expected:    []string{"Unsupported value::InstanceGroup.Spec.ContainerRuntime"},
+		},
+		{
+			clusterName: "example.com",
+			runtime:     kops.ContainerRuntimeCRIO,
+			role:        kops.InstanceGroupRoleMaster,
+			networking:  &kops.NetworkingSpec{Weave: &kops.WeaveNetworkingSpec{}},
+			expected:    []string{"Invalid value::InstanceGroup.Spec.ContainerRuntime"},
+		},
+		{
+			clusterName: "example.k8s.local",
+			runtime:     kops.ContainerRuntimeContainerd,
+			role:        kops.InstanceGroupRoleNode,
+			networking:  &kops.NetworkingSpec{Weave: &kops.WeaveNetworkingSpec{}},
+			expected:    []string{"Invalid value::InstanceGroup.Spec.ContainerRuntime"},
+		},
+		{
+			clusterName: "example.com",
+			runtime:     kops.ContainerRuntimeContainerd,
+			role:        kops.InstanceGroupRoleNode,
+			networking:  &kops.NetworkingSpec{Kubenet: &kops.KubenetNetworkingSpec{}},
+			expected:    []string{"Invalid value::InstanceGroup.Spec.ContainerRuntime"},
+		},
+		{
+			clusterName: "example.com",
+			runtime:     kops.ContainerRuntimeContainerd,
+			role:        kops.InstanceGroupRoleNode,
+			networking:  &kops.NetworkingSpec{Weave: &kops.WeaveNetworkingSpec{}},
+			hooks:       []kops.HookSpec{{ExecContainer: &kops.ExecContainerAction{Image: "busybox"}}},
+			expected:    []string{"Invalid value::InstanceGroup.Spec.ContainerRuntime"},
+		},
+		{
+			clusterName: "example.com",
+			runtime:     kops.ContainerRuntimeContainerd,
+			role:        kops.InstanceGroupRoleNode,
+			networking:  &kops.NetworkingSpec{Weave: &kops.WeaveNetworkingSpec{}},
+			hooks:       []kops.HookSpec{{Roles: []kops.InstanceGroupRole{kops.InstanceGroupRoleMaster}, ExecContainer: &kops.ExecContainerAction{Image: "busybox"}}},
+		},
+	}
+
+	for _, g := range grid {
+		cluster := &kops.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: g.clusterName},
+			Spec: kops.ClusterSpec{
+				Networking: g.networking,
+				Hooks:      g.hooks,
+			},
+		}
+		ig := &kops.InstanceGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec: kops.InstanceGroupSpec{
+				Role:             g.role,
+				ContainerRuntime: g.runtime,
+			},
+		}
+
+		errs := validateInstanceGroupContainerRuntime(ig, cluster, field.NewPath("InstanceGroup").Child("Spec").Child("ContainerRuntime"))
+		testErrors(t, g, errs, g.expected)
+	}
+}
+
+func TestValidateNodeBundle(t *testing.T) {
+	grid := []struct {
+		spec     kops.NodeBundleSpec
+		expected string
+	}{
+		{
+			spec: kops.NodeBundleSpec{
+				Location: "s3://bucket/example.com/nodebundles/nodes/bundle.tar.gz",
+				Hash:     "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			},
+		},
+		{
+			spec: kops.NodeBundleSpec{
+				Hash: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			},
+			expected: "Required value",
+		},
+		{
+			spec: kops.NodeBundleSpec{
+				Location: "s3://bucket/example.com/nodebundles/nodes/bundle.tar.gz",
+			},
+			expected: "Required value",
+		},
+		{
+			spec: kops.NodeBundleSpec{
+				Location: "s3://bucket/example.com/nodebundles/nodes/bundle.tar.gz",
+				Hash:     "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+			},
+			expected: "Invalid value",
+		},
+	}
+
+	for _, g := range grid {
+		err := validateNodeBundle(&g.spec, field.NewPath("NodeBundle"))
+		if g.expected == "" {
+			if err != nil {
+				t.Errorf("unexpected error validating %v: %v", g.spec, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("expected error %q validating %v", g.expected, g.spec)
+		} else if !strings.Contains(err.Error(), g.expected) {
+			t.Errorf("expected error %q validating %v, got %v", g.expected, g.spec, err)
+		}
+	}
+}
+
+func TestValidateInstanceGroupArchitecture(t *testing.T) {
+	grid := []struct {
+		architecture string
+		expected     string
+	}{
+		{architecture: ""},
+		{architecture: "amd64"},
+		{architecture: "arm64"},
+		{architecture: "arm", expected: "Unsupported value"},
+	}
+
+	for _, g := range grid {
+		ig := &kops.InstanceGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "nodes"},
+			Spec: kops.InstanceGroupSpec{
+				Role:         kops.InstanceGroupRoleNode,
+				Architecture: g.architecture,
+			},
+		}
+		err := ValidateInstanceGroup(ig)
+		if g.expected == "" {
+			if err != nil {
+				t.Errorf("unexpected error validating architecture %q: %v", g.architecture, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("expected error %q validating architecture %q", g.expected, g.architecture)
+		} else if !strings.Contains(err.Error(), g.expected) {
+			t.Errorf("expected error %q validating architecture %q, got %v", g.expected, g.architecture, err)
+		}
+	}
+}
@@ -19,7 +19,9 @@ package validation
 import (
 	"fmt"
 	"net"
+	"regexp"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/validation"
 	utilnet "k8s.io/apimachinery/pkg/util/net"
@@ -30,6 +32,55 @@ import (
 
 var validDockerConfigStorageValues = []string{"aufs", "btrfs", "devicemapper", "overlay", "overlay2", "zfs"}
 
+var validHugepageSizes = []string{"2Mi", "1Gi"}
+
+var validHostFirewallBackends = []string{"iptables", "nftables"}
+
+var validHostFirewallProtocols = []string{"tcp", "udp", "icmp"}
+
+var validHookPhases = []string{kops.HookPhaseBoot, kops.HookPhaseAfterKubeletHealthy, kops.HookPhaseBeforeShutdown}
+
+var validHookRunPolicies = []string{kops.HookRunPolicyEveryNodeupRun, kops.HookRunPolicyOncePerBoot, kops.HookRunPolicyOnce}
+
+var validExternalDNSProviders = []string{kops.ExternalDNSProviderRFC2136, kops.ExternalDNSProviderCloudflare, kops.ExternalDNSProviderAzure}
+
+var validGossipProtocols = []string{kops.GossipProtocolMesh, kops.GossipProtocolMemberlist}
+
+var validDNSTypes = []string{string(kops.DNSTypePublic), string(kops.DNSTypePrivate), string(kops.DNSTypeSplitHorizon)}
+
+var validNodeAddonTypes = []string{kops.NodeAddonTypeCNIPlugin, kops.NodeAddonTypeKubeletPlugin, kops.NodeAddonTypeDevicePlugin}
+
+var (
+	sysctlKeyRegex    = regexp.MustCompile(`^[a-zA-Z0-9_.\-/]+$`)
+	kernelModuleRegex = regexp.MustCompile(`^[a-zA-Z0-9_\-]+$`)
+	ulimitRegex       = regexp.MustCompile(`^(infinity|[0-9]+)(:(infinity|[0-9]+))?$`)
+	envVarNameRegex   = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+var (
+	nodeAddonNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.\-~]*$`)
+	// flexVolumeDriverRegex matches the vendor~driver names of flexvolume drivers
+	flexVolumeDriverRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.\-]*~[a-zA-Z0-9][a-zA-Z0-9_.\-]*$`)
+	// txtOwnerIDRegex matches the owner ids the dns-controller can write in a TXT record
+	txtOwnerIDRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.\-]*$`)
+	// srvRecordRegex matches the names of SRV records, _service._proto.name
+	srvRecordRegex = regexp.MustCompile(`^_[a-zA-Z0-9\-]+\._(tcp|udp)\.[a-zA-Z0-9][a-zA-Z0-9.\-]*$`)
+)
+
+// isValidSysctlKey checks a sysctl key names a parameter in /proc/sys: no component between the dots
+// and slashes may be empty, so the key cannot refer to a parent directory with ".."
+func isValidSysctlKey(key string) bool {
+	if !sysctlKeyRegex.MatchString(key) {
+		return false
+	}
+	for _, component := range strings.Split(strings.Replace(key, "/", ".", -1), ".") {
+		if component == "" {
+			return false
+		}
+	}
+	return true
+}
+
 func ValidateDockerConfig(config *kops.DockerConfig, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	allErrs = append(allErrs, IsValidValue(fldPath.Child("storage"), config.Storage, validDockerConfigStorageValues)...)
@@ -86,6 +137,40 @@ func validateClusterSpec(spec *kops.ClusterSpec, fieldPath *field.Path) field.Er
 		}
 	}
 
+	if spec.NodeTuning != nil {
+		allErrs = append(allErrs, validateNodeTuning(spec.NodeTuning, fieldPath.Child("nodeTuning"))...)
+	}
+
+	if spec.HostFirewall != nil {
+		allErrs = append(allErrs, validateHostFirewall(spec.HostFirewall, fieldPath.Child("hostFirewall"))...)
+	}
+
+	if spec.NodeAgent != nil {
+		allErrs = append(allErrs, validateNodeAgent(spec.NodeAgent, fieldPath.Child("nodeAgent"))...)
+	}
+
+	if spec.GossipConfig != nil {
+		allErrs = append(allErrs, validateGossipConfig(spec.GossipConfig, fieldPath.Child("gossipConfig"))...)
+	}
+
+	if spec.Topology != nil && spec.Topology.DNS != nil {
+		allErrs = append(allErrs, validateTopologyDNS(spec, fieldPath.Child("topology", "dns"))...)
+	}
+
+	if spec.ExternalDNS != nil {
+		allErrs = append(allErrs, validateExternalDNS(spec.ExternalDNS, fieldPath.Child("externalDns"))...)
+
+		// kops creates these records in the DNS of the cloud, so they cannot be used with an external DNS provider
+		if spec.ExternalDNS.Provider != "" {
+			if spec.API != nil && spec.API.LoadBalancer != nil {
+				allErrs = append(allErrs, field.Forbidden(fieldPath.Child("api", "loadBalancer"), "an API load balancer is not supported with an external DNS provider"))
+			}
+			if spec.Topology != nil && spec.Topology.Bastion != nil && spec.Topology.Bastion.BastionPublicName != "" {
+				allErrs = append(allErrs, field.Forbidden(fieldPath.Child("topology", "bastion", "bastionPublicName"), "a bastion DNS name is not supported with an external DNS provider"))
+			}
+		}
+	}
+
 	if spec.KubeAPIServer != nil {
 		allErrs = append(allErrs, validateKubeAPIServer(spec.KubeAPIServer, fieldPath.Child("kubeAPIServer"))...)
 	}
@@ -94,6 +179,13 @@ func validateClusterSpec(spec *kops.ClusterSpec, fieldPath *field.Path) field.Er
 		allErrs = append(allErrs, validateNetworking(spec.Networking, fieldPath.Child("networking"))...)
 	}
 
+	if spec.ContainerRuntime != "" {
+		allErrs = append(allErrs, IsValidValue(fieldPath.Child("containerRuntime"), &spec.ContainerRuntime, kops.ContainerRuntimes)...)
+		if spec.ContainerRuntime != kops.ContainerRuntimeDocker && spec.Networking != nil && spec.Networking.Kubenet != nil {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("containerRuntime"), spec.ContainerRuntime, "kubenet networking is only supported with docker; use a CNI networking provider"))
+		}
+	}
+
 	return allErrs
 }
 
@@ -191,6 +283,242 @@ func validateHookSpec(v *kops.HookSpec, fieldPath *field.Path) field.ErrorList {
 		allErrs = append(allErrs, validateExecContainerAction(v.ExecContainer, fieldPath.Child("ExecContainer"))...)
 	}
 
+	if v.Phase != "" {
+		allErrs = append(allErrs, IsValidValue(fieldPath.Child("phase"), &v.Phase, validHookPhases)...)
+	}
+	if v.RunPolicy != "" {
+		allErrs = append(allErrs, IsValidValue(fieldPath.Child("runPolicy"), &v.RunPolicy, validHookRunPolicies)...)
+	}
+
+	// a raw unit controls how it runs, so only execContainer hooks can be wrapped to wait for the kubelet, retry, or run on shutdown
+	if v.ExecContainer == nil {
+		if v.Phase != "" && v.Phase != kops.HookPhaseBoot {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("phase"), "only execContainer hooks can set the phase"))
+		}
+		if v.Retries != 0 {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("retries"), "only execContainer hooks can be retried"))
+		}
+	}
+	if v.Phase == kops.HookPhaseBeforeShutdown && v.RunPolicy != "" && v.RunPolicy != kops.HookRunPolicyOnce {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("runPolicy"), v.RunPolicy, "hooks run before shutdown can only set the Once run policy"))
+	}
+
+	if v.Timeout != nil && v.Timeout.Duration < time.Second {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("timeout"), v.Timeout.Duration.String(), "the timeout must be at least one second"))
+	}
+	if v.Retries < 0 {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("retries"), v.Retries, "retries cannot be negative"))
+	}
+	for i, code := range v.SuccessExitCodes {
+		if code < 0 || code > 255 {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("successExitCodes").Index(i), code, "exit codes must be between 0 and 255"))
+		}
+	}
+
+	for k, secret := range v.EnvironmentFromSecrets {
+		if !envVarNameRegex.MatchString(k) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("environmentFromSecrets"), k, "invalid environment variable name"))
+		}
+		if secret == "" {
+			allErrs = append(allErrs, field.Required(fieldPath.Child("environmentFromSecrets").Key(k), "the name of the secret is required"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateNodeTuning(v *kops.NodeTuningSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, s := range v.SysctlParameters {
+		tokens := strings.SplitN(s, "=", 2)
+		if len(tokens) != 2 || strings.TrimSpace(tokens[1]) == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("sysctlParameters").Index(i), s, "sysctl parameters must be in the form key=value"))
+		} else if !isValidSysctlKey(strings.TrimSpace(tokens[0])) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("sysctlParameters").Index(i), s, "invalid sysctl key"))
+		}
+	}
+
+	for i, m := range v.KernelModules {
+		if !kernelModuleRegex.MatchString(m) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("kernelModules").Index(i), m, "invalid kernel module name"))
+		}
+	}
+
+	if v.Ulimits != nil {
+		limits := map[string]string{
+			"nofile":  v.Ulimits.NoFile,
+			"nproc":   v.Ulimits.NProc,
+			"memlock": v.Ulimits.MemLock,
+			"core":    v.Ulimits.Core,
+		}
+		for k, limit := range limits {
+			if limit != "" && !ulimitRegex.MatchString(limit) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("ulimits", k), limit, "limits must be a number, infinity, or soft:hard"))
+			}
+		}
+	}
+
+	if v.Hugepages != nil {
+		if v.Hugepages.PageSize != "" {
+			allErrs = append(allErrs, IsValidValue(fldPath.Child("hugepages", "pageSize"), &v.Hugepages.PageSize, validHugepageSizes)...)
+		}
+		if v.Hugepages.Count < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("hugepages", "count"), v.Hugepages.Count, "count must not be negative"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateNodeAgent(v *kops.NodeAgentSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	// the agent reads the state store on every run, so we do not allow it to run too often
+	if v.Interval != nil && v.Interval.Duration < time.Minute {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("interval"), v.Interval.Duration.String(), "the interval must be at least one minute"))
+	}
+
+	return allErrs
+}
+
+func validateExternalDNS(v *kops.ExternalDNSConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if v.TXTOwnerID != "" && !txtOwnerIDRegex.MatchString(v.TXTOwnerID) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("txtOwnerId"), v.TXTOwnerID, "the owner id may only contain letters, digits, '.', '_' and '-'"))
+	}
+
+	if v.Provider != "" {
+		allErrs = append(allErrs, IsValidValue(fldPath.Child("provider"), &v.Provider, validExternalDNSProviders)...)
+		if v.Disable {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("provider"), "an external DNS provider requires the dns-controller"))
+		}
+	} else if v.ProviderConfig != "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("providerConfig"), "providerConfig can only be set with a provider"))
+	}
+
+	return allErrs
+}
+
+func validateTopologyDNS(spec *kops.ClusterSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	v := spec.Topology.DNS
+	if v.Type != "" {
+		dnsType := string(v.Type)
+		allErrs = append(allErrs, IsValidValue(fldPath.Child("type"), &dnsType, validDNSTypes)...)
+	}
+
+	if v.Type == kops.DNSTypeSplitHorizon {
+		if spec.CloudProvider != string(kops.CloudProviderAWS) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("type"), "split-horizon DNS is only supported on AWS"))
+		}
+		// The private zone is found or created by the name of the public zone
+		if spec.DNSZone != "" && !strings.Contains(spec.DNSZone, ".") {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "dnsZone"), spec.DNSZone, "split-horizon DNS requires the name of the zone, not its ID"))
+		}
+		if spec.ExternalDNS != nil && spec.ExternalDNS.Provider != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("type"), "split-horizon DNS is not supported with an external DNS provider"))
+		}
+	}
+
+	if len(v.AdditionalVPCs) != 0 && v.Type != kops.DNSTypePrivate && v.Type != kops.DNSTypeSplitHorizon {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("additionalVPCs"), "additional VPCs can only be associated with a private zone"))
+	}
+	for i, vpc := range v.AdditionalVPCs {
+		if vpc.ID == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("additionalVPCs").Index(i).Child("id"), "the id of the VPC must be set"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateGossipConfig(v *kops.GossipConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, IsValidValue(fldPath.Child("protocol"), v.Protocol, validGossipProtocols)...)
+	allErrs = append(allErrs, validateGossipListen(fldPath.Child("listen"), v.Listen)...)
+
+	if v.SeedSRV != nil && !srvRecordRegex.MatchString(*v.SeedSRV) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("seedSRV"), *v.SeedSRV, "must be the name of an SRV record, e.g. _gossip._tcp.example.com"))
+	}
+
+	if v.Secondary != nil {
+		allErrs = append(allErrs, IsValidValue(fldPath.Child("secondary", "protocol"), v.Secondary.Protocol, validGossipProtocols)...)
+		allErrs = append(allErrs, validateGossipListen(fldPath.Child("secondary", "listen"), v.Secondary.Listen)...)
+
+		protocol := kops.GossipProtocolMesh
+		if v.Protocol != nil {
+			protocol = *v.Protocol
+		}
+		if v.Secondary.Protocol == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("secondary", "protocol"), "the secondary gossip protocol must be set"))
+		} else if *v.Secondary.Protocol == protocol {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("secondary", "protocol"), *v.Secondary.Protocol, "the secondary gossip protocol must differ from the protocol"))
+		}
+		if v.Listen != nil && v.Secondary.Listen != nil && *v.Listen == *v.Secondary.Listen {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("secondary", "listen"), *v.Secondary.Listen, "the secondary gossip must listen on a different address"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateGossipListen(fldPath *field.Path, listen *string) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if listen != nil {
+		if _, port, err := net.SplitHostPort(*listen); err != nil || port == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath, *listen, "must be an address:port"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateHostFirewall(v *kops.HostFirewallSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if v.Backend != "" {
+		allErrs = append(allErrs, IsValidValue(fldPath.Child("backend"), &v.Backend, validHostFirewallBackends)...)
+	}
+
+	for i := range v.Rules {
+		rule := &v.Rules[i]
+		rulePath := fldPath.Child("rules").Index(i)
+
+		for j, role := range rule.Roles {
+			if _, ok := kops.ParseInstanceGroupRole(string(role), false); !ok {
+				allErrs = append(allErrs, field.NotSupported(rulePath.Child("roles").Index(j), role, instanceGroupRoleNames()))
+			}
+		}
+
+		if rule.Protocol != "" {
+			allErrs = append(allErrs, IsValidValue(rulePath.Child("protocol"), &rule.Protocol, validHostFirewallProtocols)...)
+		}
+		if rule.Protocol == "icmp" && len(rule.Ports) != 0 {
+			allErrs = append(allErrs, field.Invalid(rulePath.Child("ports"), rule.Ports, "ports cannot be specified for icmp"))
+		}
+		for j, port := range rule.Ports {
+			if !isValidPortRange(port) {
+				allErrs = append(allErrs, field.Invalid(rulePath.Child("ports").Index(j), port, "ports must be a port (e.g. 9100) or a range of ports (e.g. 30000-32767)"))
+			}
+		}
+
+		for j, cidr := range rule.CIDRs {
+			cidrPath := rulePath.Child("cidrs").Index(j)
+			errs := validateCIDR(cidr, cidrPath)
+			if len(errs) == 0 {
+				if ip, _, _ := net.ParseCIDR(cidr); ip.To4() == nil {
+					errs = append(errs, field.Invalid(cidrPath, cidr, "only IPv4 CIDRs are supported"))
+				}
+			}
+			allErrs = append(allErrs, errs...)
+		}
+	}
+
 	return allErrs
 }
 
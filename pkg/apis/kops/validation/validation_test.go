@@ -18,11 +18,14 @@ package validation
 
 import (
 	"testing"
+	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
 )
 
 func Test_Validate_DNS(t *testing.T) {
@@ -241,3 +244,457 @@ func Test_Validate_Networking_Flannel(t *testing.T) {
 		testErrors(t, g.Input, errs, g.ExpectedErrors)
 	}
 }
+
+func Test_Validate_NodeTuning(t *testing.T) {
+	grid := []struct {
+		Input          kops.NodeTuningSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.NodeTuningSpec{
+				SysctlParameters: []string{"net.ipv4.tcp_keepalive_time=600", "kernel.pid_max = 4194304"},
+				KernelModules:    []string{"br_netfilter", "ip_vs"},
+				Ulimits:          &kops.UlimitsSpec{NoFile: "1048576", MemLock: "infinity", Core: "0:infinity"},
+				Hugepages:        &kops.HugepagesSpec{PageSize: "1Gi", Count: 4},
+			},
+		},
+		{
+			Input: kops.NodeTuningSpec{
+				SysctlParameters: []string{"net.ipv4.tcp_keepalive_time"},
+			},
+			ExpectedErrors: []string{"Invalid value::NodeTuning.sysctlParameters[0]"},
+		},
+		{
+			Input: kops.NodeTuningSpec{
+				SysctlParameters: []string{"net.ipv4.conf.eth0/100.rp_filter=0"},
+			},
+		},
+		{
+			Input: kops.NodeTuningSpec{
+				SysctlParameters: []string{"../../etc/passwd=x", "net..ipv4.ip_forward=1", "net/../../etc.shadow=x", ".net.ipv4.ip_forward=1"},
+			},
+			ExpectedErrors: []string{
+				"Invalid value::NodeTuning.sysctlParameters[0]",
+				"Invalid value::NodeTuning.sysctlParameters[1]",
+				"Invalid value::NodeTuning.sysctlParameters[2]",
+				"Invalid value::NodeTuning.sysctlParameters[3]",
+			},
+		},
+		{
+			Input: kops.NodeTuningSpec{
+				KernelModules: []string{"br_netfilter; rm -rf /"},
+			},
+			ExpectedErrors: []string{"Invalid value::NodeTuning.kernelModules[0]"},
+		},
+		{
+			Input: kops.NodeTuningSpec{
+				Ulimits: &kops.UlimitsSpec{NProc: "lots"},
+			},
+			ExpectedErrors: []string{"Invalid value::NodeTuning.ulimits.nproc"},
+		},
+		{
+			Input: kops.NodeTuningSpec{
+				Hugepages: &kops.HugepagesSpec{PageSize: "4Ki", Count: -1},
+			},
+			ExpectedErrors: []string{
+				"Unsupported value::NodeTuning.hugepages.pageSize",
+				"Invalid value::NodeTuning.hugepages.count",
+			},
+		},
+	}
+	for _, g := range grid {
+		errs := validateNodeTuning(&g.Input, field.NewPath("NodeTuning"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_HostFirewall(t *testing.T) {
+	grid := []struct {
+		Input          kops.HostFirewallSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.HostFirewallSpec{
+				Enabled: fi.Bool(true),
+				Backend: "nftables",
+				Rules: []kops.HostFirewallRule{
+					{Roles: []kops.InstanceGroupRole{kops.InstanceGroupRoleNode}, Ports: []string{"9100", "30000-32767"}, CIDRs: []string{"10.0.0.0/8"}},
+					{Protocol: "icmp"},
+				},
+			},
+		},
+		{
+			Input: kops.HostFirewallSpec{
+				Backend: "pf",
+			},
+			ExpectedErrors: []string{"Unsupported value::HostFirewall.backend"},
+		},
+		{
+			Input: kops.HostFirewallSpec{
+				Rules: []kops.HostFirewallRule{
+					{Roles: []kops.InstanceGroupRole{"Worker"}, Protocol: "sctp"},
+				},
+			},
+			ExpectedErrors: []string{
+				"Unsupported value::HostFirewall.rules[0].roles[0]",
+				"Unsupported value::HostFirewall.rules[0].protocol",
+			},
+		},
+		{
+			Input: kops.HostFirewallSpec{
+				Rules: []kops.HostFirewallRule{
+					{Ports: []string{"0", "22-21", "http"}},
+					{Protocol: "icmp", Ports: []string{"8"}},
+				},
+			},
+			ExpectedErrors: []string{
+				"Invalid value::HostFirewall.rules[0].ports[0]",
+				"Invalid value::HostFirewall.rules[0].ports[1]",
+				"Invalid value::HostFirewall.rules[0].ports[2]",
+				"Invalid value::HostFirewall.rules[1].ports",
+			},
+		},
+		{
+			Input: kops.HostFirewallSpec{
+				Rules: []kops.HostFirewallRule{
+					{CIDRs: []string{"10.0.0.0", "2001:db8::/32"}},
+				},
+			},
+			ExpectedErrors: []string{
+				"Invalid value::HostFirewall.rules[0].cidrs[0]",
+				"Invalid value::HostFirewall.rules[0].cidrs[1]",
+			},
+		},
+	}
+	for _, g := range grid {
+		errs := validateHostFirewall(&g.Input, field.NewPath("HostFirewall"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_Hook(t *testing.T) {
+	grid := []struct {
+		Input          kops.HookSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.HookSpec{
+				ExecContainer:          &kops.ExecContainerAction{Image: "busybox"},
+				Phase:                  kops.HookPhaseAfterKubeletHealthy,
+				RunPolicy:              kops.HookRunPolicyOncePerBoot,
+				Timeout:                &metav1.Duration{Duration: 5 * time.Minute},
+				Retries:                3,
+				SuccessExitCodes:       []int32{2},
+				EnvironmentFromSecrets: map[string]string{"API_TOKEN": "hook-token"},
+			},
+		},
+		{
+			Input: kops.HookSpec{
+				ExecContainer: &kops.ExecContainerAction{Image: "busybox"},
+				Phase:         "Reboot",
+				RunPolicy:     "Always",
+			},
+			ExpectedErrors: []string{
+				"Unsupported value::Hook.phase",
+				"Unsupported value::Hook.runPolicy",
+			},
+		},
+		{
+			Input: kops.HookSpec{
+				Manifest: "Type=oneshot\nExecStart=/bin/true",
+				Phase:    kops.HookPhaseBeforeShutdown,
+				Retries:  1,
+			},
+			ExpectedErrors: []string{
+				"Forbidden::Hook.phase",
+				"Forbidden::Hook.retries",
+			},
+		},
+		{
+			Input: kops.HookSpec{
+				ExecContainer: &kops.ExecContainerAction{Image: "busybox"},
+				Phase:         kops.HookPhaseBeforeShutdown,
+				RunPolicy:     kops.HookRunPolicyOncePerBoot,
+			},
+			ExpectedErrors: []string{"Invalid value::Hook.runPolicy"},
+		},
+		{
+			Input: kops.HookSpec{
+				ExecContainer:          &kops.ExecContainerAction{Image: "busybox"},
+				Timeout:                &metav1.Duration{Duration: time.Millisecond},
+				Retries:                -1,
+				SuccessExitCodes:       []int32{256},
+				EnvironmentFromSecrets: map[string]string{"API-TOKEN": "hook-token"},
+			},
+			ExpectedErrors: []string{
+				"Invalid value::Hook.timeout",
+				"Invalid value::Hook.retries",
+				"Invalid value::Hook.successExitCodes[0]",
+				"Invalid value::Hook.environmentFromSecrets",
+			},
+		},
+		{
+			Input: kops.HookSpec{
+				ExecContainer:          &kops.ExecContainerAction{Image: "busybox"},
+				EnvironmentFromSecrets: map[string]string{"API_TOKEN": ""},
+			},
+			ExpectedErrors: []string{"Required value::Hook.environmentFromSecrets[API_TOKEN]"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateHookSpec(&g.Input, field.NewPath("Hook"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_NodeAgent(t *testing.T) {
+	grid := []struct {
+		Input          kops.NodeAgentSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.NodeAgentSpec{
+				Enabled:  fi.Bool(true),
+				Interval: &metav1.Duration{Duration: 15 * time.Minute},
+			},
+		},
+		{
+			Input: kops.NodeAgentSpec{
+				Enabled:  fi.Bool(true),
+				Interval: &metav1.Duration{Duration: 10 * time.Second},
+			},
+			ExpectedErrors: []string{"Invalid value::NodeAgent.interval"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateNodeAgent(&g.Input, field.NewPath("NodeAgent"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_ExternalDNS(t *testing.T) {
+	grid := []struct {
+		Input          kops.ExternalDNSConfig
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.ExternalDNSConfig{TXTOwnerID: "cluster1.example.com"},
+		},
+		{
+			Input:          kops.ExternalDNSConfig{TXTOwnerID: "cluster1,\"other\""},
+			ExpectedErrors: []string{"Invalid value::ExternalDNS.txtOwnerId"},
+		},
+		{
+			Input: kops.ExternalDNSConfig{Provider: "cloudflare"},
+		},
+		{
+			Input:          kops.ExternalDNSConfig{Provider: "bind"},
+			ExpectedErrors: []string{"Unsupported value::ExternalDNS.provider"},
+		},
+		{
+			Input:          kops.ExternalDNSConfig{Provider: "rfc2136", Disable: true},
+			ExpectedErrors: []string{"Forbidden::ExternalDNS.provider"},
+		},
+		{
+			Input:          kops.ExternalDNSConfig{ProviderConfig: "[global]\n"},
+			ExpectedErrors: []string{"Forbidden::ExternalDNS.providerConfig"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateExternalDNS(&g.Input, field.NewPath("ExternalDNS"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_TopologyDNS(t *testing.T) {
+	grid := []struct {
+		Input          kops.ClusterSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider: "aws",
+				DNSZone:       "example.com",
+				Topology:      &kops.TopologySpec{DNS: &kops.DNSSpec{Type: kops.DNSTypeSplitHorizon, AdditionalVPCs: []kops.DNSVPCSpec{{ID: "vpc-1234", Region: "us-west-2"}}}},
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider: "aws",
+				Topology:      &kops.TopologySpec{DNS: &kops.DNSSpec{Type: "Secret"}},
+			},
+			ExpectedErrors: []string{"Unsupported value::TopologyDNS.type"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider: "gce",
+				Topology:      &kops.TopologySpec{DNS: &kops.DNSSpec{Type: kops.DNSTypeSplitHorizon}},
+			},
+			ExpectedErrors: []string{"Forbidden::TopologyDNS.type"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider: "aws",
+				DNSZone:       "Z1234",
+				Topology:      &kops.TopologySpec{DNS: &kops.DNSSpec{Type: kops.DNSTypeSplitHorizon}},
+			},
+			ExpectedErrors: []string{"Invalid value::spec.dnsZone"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider: "aws",
+				Topology:      &kops.TopologySpec{DNS: &kops.DNSSpec{Type: kops.DNSTypePublic, AdditionalVPCs: []kops.DNSVPCSpec{{ID: "vpc-1234"}}}},
+			},
+			ExpectedErrors: []string{"Forbidden::TopologyDNS.additionalVPCs"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				CloudProvider: "aws",
+				Topology:      &kops.TopologySpec{DNS: &kops.DNSSpec{Type: kops.DNSTypePrivate, AdditionalVPCs: []kops.DNSVPCSpec{{Region: "us-west-2"}}}},
+			},
+			ExpectedErrors: []string{"Required value::TopologyDNS.additionalVPCs[0].id"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateTopologyDNS(&g.Input, field.NewPath("TopologyDNS"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_GossipConfig(t *testing.T) {
+	grid := []struct {
+		Input          kops.GossipConfig
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.GossipConfig{Protocol: fi.String("memberlist"), Listen: fi.String("0.0.0.0:3999")},
+		},
+		{
+			Input:          kops.GossipConfig{Protocol: fi.String("serf")},
+			ExpectedErrors: []string{"Unsupported value::GossipConfig.protocol"},
+		},
+		{
+			Input:          kops.GossipConfig{Listen: fi.String("0.0.0.0")},
+			ExpectedErrors: []string{"Invalid value::GossipConfig.listen"},
+		},
+		{
+			Input: kops.GossipConfig{SeedSRV: fi.String("_gossip._tcp.example.com")},
+		},
+		{
+			Input:          kops.GossipConfig{SeedSRV: fi.String("example.com")},
+			ExpectedErrors: []string{"Invalid value::GossipConfig.seedSRV"},
+		},
+		{
+			Input: kops.GossipConfig{
+				Protocol:  fi.String("memberlist"),
+				Secondary: &kops.GossipConfigSecondary{Protocol: fi.String("mesh"), Listen: fi.String("0.0.0.0:4000")},
+			},
+		},
+		{
+			Input:          kops.GossipConfig{Secondary: &kops.GossipConfigSecondary{Protocol: fi.String("mesh")}},
+			ExpectedErrors: []string{"Invalid value::GossipConfig.secondary.protocol"},
+		},
+		{
+			Input:          kops.GossipConfig{Secondary: &kops.GossipConfigSecondary{}},
+			ExpectedErrors: []string{"Required value::GossipConfig.secondary.protocol"},
+		},
+		{
+			Input: kops.GossipConfig{
+				Listen:    fi.String("0.0.0.0:3999"),
+				Secondary: &kops.GossipConfigSecondary{Protocol: fi.String("memberlist"), Listen: fi.String("0.0.0.0:3999")},
+			},
+			ExpectedErrors: []string{"Invalid value::GossipConfig.secondary.listen"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateGossipConfig(&g.Input, field.NewPath("GossipConfig"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_NodeAddons(t *testing.T) {
+	grid := []struct {
+		Input          []kops.NodeAddonSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: []kops.NodeAddonSpec{
+				{
+					Name:    "bandwidth",
+					Type:    kops.NodeAddonTypeCNIPlugin,
+					Version: "0.7.1",
+					Source:  "https://example.com/cni/bandwidth",
+					Hash:    "sha256:0f4eb0ef2b1c1c3c1fb2b4d5ce9a0e7d1dbeba3fbe3d7e9e1a1fa6b3a3b1a2c3",
+				},
+				{
+					Name:    "example.com~nfs",
+					Type:    kops.NodeAddonTypeKubeletPlugin,
+					Version: "1.0.0",
+					Source:  "https://example.com/flex/nfs",
+				},
+				{
+					Name:    "nvidia-device-plugin",
+					Type:    kops.NodeAddonTypeDevicePlugin,
+					Version: "1.10",
+					Image:   "nvidia/k8s-device-plugin:1.10",
+					HealthCheck: &kops.NodeAddonHealthCheckSpec{
+						Socket:  "/var/lib/kubelet/device-plugins/nvidia.sock",
+						Timeout: &metav1.Duration{Duration: time.Minute},
+					},
+				},
+			},
+		},
+		{
+			Input: []kops.NodeAddonSpec{
+				{Name: "bandwidth", Type: "Unknown", Source: "https://example.com/cni/bandwidth", Image: "busybox"},
+				{Name: "bandwidth", Type: kops.NodeAddonTypeCNIPlugin, Version: "0.7.1", Source: "s3://bucket/bandwidth", Hash: "nothex"},
+			},
+			ExpectedErrors: []string{
+				"Unsupported value::NodeAddons[0].type",
+				"Required value::NodeAddons[0].version",
+				"Forbidden::NodeAddons[0].image",
+				"Duplicate value::NodeAddons[1].name",
+				"Invalid value::NodeAddons[1].source",
+				"Invalid value::NodeAddons[1].hash",
+			},
+		},
+		{
+			Input: []kops.NodeAddonSpec{
+				{Name: "nfs", Type: kops.NodeAddonTypeKubeletPlugin, Version: "1.0.0", Source: "https://example.com/flex/nfs"},
+				{Name: "cni", Type: kops.NodeAddonTypeCNIPlugin, Version: "0.7.1", Image: "busybox"},
+				{Name: "gpu", Type: kops.NodeAddonTypeDevicePlugin, Version: "1.10", Source: "https://example.com/gpu"},
+				{Name: "loopback", Type: kops.NodeAddonTypeCNIPlugin, Version: "0.7.1", Source: "https://example.com/cni/loopback", Args: []string{"--debug"}},
+			},
+			ExpectedErrors: []string{
+				"Invalid value::NodeAddons[0].name",
+				"Forbidden::NodeAddons[1].image",
+				"Forbidden::NodeAddons[2].source",
+				"Forbidden::NodeAddons[3].args",
+			},
+		},
+		{
+			Input: []kops.NodeAddonSpec{
+				{
+					Name:    "gpu",
+					Type:    kops.NodeAddonTypeDevicePlugin,
+					Version: "1.10",
+					Image:   "nvidia/k8s-device-plugin:1.10",
+					HealthCheck: &kops.NodeAddonHealthCheckSpec{
+						Exec:    []string{"/bin/true"},
+						Socket:  "nvidia.sock",
+						Timeout: &metav1.Duration{Duration: time.Millisecond},
+					},
+				},
+			},
+			ExpectedErrors: []string{
+				"Forbidden::NodeAddons[0].healthCheck.socket",
+				"Invalid value::NodeAddons[0].healthCheck.socket",
+				"Invalid value::NodeAddons[0].healthCheck.timeout",
+			},
+		},
+	}
+	for _, g := range grid {
+		errs := validateNodeAddons(g.Input, field.NewPath("NodeAddons"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
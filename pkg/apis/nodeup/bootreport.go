@@ -0,0 +1,139 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import "time"
+
+// BootReportPath is the location on the node where nodeup writes its boot report
+const BootReportPath = "/var/log/nodeup-report.json"
+
+// BootReportMode is how nodeup was run when it produced the report
+type BootReportMode string
+
+const (
+	// BootReportModeRun is a report from a normal nodeup run, which configures the node
+	BootReportModeRun BootReportMode = "run"
+	// BootReportModeDiagnose is a report from nodeup --diagnose, which only checks the node
+	BootReportModeDiagnose BootReportMode = "diagnose"
+	// BootReportModeAgent is a run of the nodeup agent, which applies configuration changes to a running node
+	BootReportModeAgent BootReportMode = "agent"
+)
+
+// TaskStatus is the state of the node for a task, as found by nodeup --diagnose
+type TaskStatus string
+
+const (
+	// TaskStatusInSync means the node matches the task
+	TaskStatusInSync TaskStatus = "InSync"
+	// TaskStatusMissing means nothing was found on the node for the task
+	TaskStatusMissing TaskStatus = "Missing"
+	// TaskStatusOutOfSync means the task was found, but differs from what nodeup would configure
+	TaskStatusOutOfSync TaskStatus = "OutOfSync"
+	// TaskStatusError means the task could not be checked
+	TaskStatusError TaskStatus = "Error"
+	// TaskStatusUnchecked means the task is an action (such as loading an image) which has no state we can check
+	TaskStatusUnchecked TaskStatus = "Unchecked"
+)
+
+// BootReport is a structured summary of a nodeup run, for troubleshooting nodes that fail to join the cluster
+type BootReport struct {
+	// Hostname is the hostname of the node
+	Hostname string `json:"hostname,omitempty"`
+	// InstanceGroup is the name of the instance group of the node
+	InstanceGroup string `json:"instanceGroup,omitempty"`
+	// NodeupVersion is the version of nodeup which produced the report
+	NodeupVersion string `json:"nodeupVersion,omitempty"`
+	// Mode is how nodeup was run
+	Mode BootReportMode `json:"mode,omitempty"`
+
+	// StartTime is when nodeup started
+	StartTime time.Time `json:"startTime"`
+	// EndTime is when nodeup finished
+	EndTime time.Time `json:"endTime"`
+
+	// Success is true if nodeup completed without errors
+	Success bool `json:"success"`
+	// Error is the error that stopped nodeup, if it failed
+	Error string `json:"error,omitempty"`
+
+	// MissingAssets are the assets which could not be found or downloaded
+	MissingAssets []*AssetReport `json:"missingAssets,omitempty"`
+	// Tasks is the state of each task; it is only populated by nodeup --diagnose
+	Tasks []*TaskReport `json:"tasks,omitempty"`
+	// Services is the state of the systemd services nodeup manages
+	Services []*ServiceReport `json:"services,omitempty"`
+	// Certificates are the certificates nodeup installs that are missing or invalid
+	Certificates []*CertificateReport `json:"certificates,omitempty"`
+}
+
+// AssetReport describes an asset nodeup could not obtain
+type AssetReport struct {
+	// Asset is the asset, as specified in the nodeup config
+	Asset string `json:"asset,omitempty"`
+	// Error is the error from finding or downloading the asset
+	Error string `json:"error,omitempty"`
+}
+
+// TaskReport is the state of the node for a single task
+type TaskReport struct {
+	// Name is the key of the task, e.g. File//etc/sysconfig/kubelet
+	Name string `json:"name,omitempty"`
+	// Status is the state of the node for the task
+	Status TaskStatus `json:"status,omitempty"`
+	// Changes are the fields which differ from what nodeup would configure
+	Changes []string `json:"changes,omitempty"`
+	// Error is the error from checking the task
+	Error string `json:"error,omitempty"`
+}
+
+// ServiceReport is the state of a systemd service
+type ServiceReport struct {
+	// Name is the name of the systemd unit
+	Name string `json:"name,omitempty"`
+	// ActiveState is the systemd ActiveState, e.g. active or failed
+	ActiveState string `json:"activeState,omitempty"`
+	// SubState is the systemd SubState, e.g. running or exited
+	SubState string `json:"subState,omitempty"`
+	// Failed is true if the service has failed
+	Failed bool `json:"failed,omitempty"`
+}
+
+// CertificateReport describes a problem with a certificate on the node
+type CertificateReport struct {
+	// Path is the location of the certificate on the node
+	Path string `json:"path,omitempty"`
+	// Problem describes what is wrong with the certificate
+	Problem string `json:"problem,omitempty"`
+}
+
+// HasProblems returns true if the report shows a failure, or anything out of order on the node
+func (r *BootReport) HasProblems() bool {
+	if !r.Success || len(r.MissingAssets) != 0 || len(r.Certificates) != 0 {
+		return true
+	}
+	for _, t := range r.Tasks {
+		if t.Status != TaskStatusInSync && t.Status != TaskStatusUnchecked {
+			return true
+		}
+	}
+	for _, s := range r.Services {
+		if s.Failed {
+			return true
+		}
+	}
+	return false
+}
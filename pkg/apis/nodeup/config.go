@@ -37,6 +37,8 @@ type Config struct {
 	ProtokubeImage *Image `json:"protokubeImage,omitempty"`
 	// Channels is a list of channels that we should apply
 	Channels []string `json:"channels,omitempty"`
+	// NodeBundle is an offline bundle, from which we install all files and packages, instead of downloading them
+	NodeBundle *NodeBundle `json:"nodeBundle,omitempty"`
 }
 
 // Image is a docker image we should pre-load
@@ -48,3 +50,11 @@ type Image struct {
 	// Hash is the hash of the file, to verify image integrity (even over http)
 	Hash string `json:"hash,omitempty"`
 }
+
+// NodeBundle is an offline bundle of the files and packages nodeup needs
+type NodeBundle struct {
+	// Source is the location of the bundle
+	Source string `json:"source,omitempty"`
+	// Hash is the sha256 hash of the bundle
+	Hash string `json:"hash,omitempty"`
+}
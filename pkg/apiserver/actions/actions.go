@@ -0,0 +1,338 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package actions
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apiserver/operations"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/instancegroups"
+	"k8s.io/kops/pkg/kubeconfig"
+	"k8s.io/kops/pkg/validation"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/upup/pkg/kutil"
+	"k8s.io/kops/util/pkg/tables"
+)
+
+// DefaultActions returns the actions supported by the kops server.
+// They are built from the same code, with the same defaults, as the equivalent kops commands.
+func DefaultActions() map[kops.OperationAction]*operations.Action {
+	return map[kops.OperationAction]*operations.Action{
+		kops.OperationActionPlan: {
+			Run: func(c *operations.ActionContext, out io.Writer) error {
+				return runUpdate(c, out, true)
+			},
+		},
+		kops.OperationActionApply: {
+			Run: func(c *operations.ActionContext, out io.Writer) error {
+				return runUpdate(c, out, false)
+			},
+			Exclusive: true,
+		},
+		kops.OperationActionRollingUpdate: {
+			Run:       runRollingUpdate,
+			Exclusive: true,
+		},
+		kops.OperationActionValidate: {
+			Run: runValidate,
+		},
+		kops.OperationActionKubeconfig: {
+			Run:         runKubeconfig,
+			Synchronous: true,
+		},
+	}
+}
+
+// runUpdate previews or applies the cluster configuration, as `kops update cluster` does
+func runUpdate(c *operations.ActionContext, out io.Writer, dryRun bool) error {
+	list, err := c.Clientset.InstanceGroupsFor(c.Cluster).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing InstanceGroups: %v", err)
+	}
+	var instanceGroups []*kops.InstanceGroup
+	for i := range list.Items {
+		instanceGroups = append(instanceGroups, &list.Items[i])
+	}
+
+	// The apply caches assets and writes any local output to OutDir
+	outDir, err := ioutil.TempDir("", "kops-operation")
+	if err != nil {
+		return fmt.Errorf("error creating temporary directory: %v", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(outDir); err != nil {
+			glog.Warningf("error removing temporary directory %q: %v", outDir, err)
+		}
+	}()
+
+	targetName := cloudup.TargetDirect
+	if dryRun {
+		targetName = cloudup.TargetDryRun
+	}
+
+	applyCmd := &cloudup.ApplyClusterCmd{
+		Clientset:       c.Clientset,
+		Cluster:         c.Cluster,
+		DryRun:          dryRun,
+		InstanceGroups:  instanceGroups,
+		MaxTaskDuration: cloudup.DefaultMaxTaskDuration,
+		Models:          cloudup.CloudupModels,
+		OutDir:          outDir,
+		TargetName:      targetName,
+		Out:             out,
+	}
+	if err := applyCmd.Run(); err != nil {
+		return err
+	}
+
+	if dryRun {
+		target := applyCmd.Target.(*fi.DryRunTarget)
+		if target.HasChanges() {
+			fmt.Fprintf(out, "\nApply the cluster to make these changes\n")
+		} else {
+			fmt.Fprintf(out, "\nNo changes need to be applied\n")
+		}
+		return nil
+	}
+
+	fmt.Fprintf(out, "\nCluster changes have been applied to the cloud.\n")
+	fmt.Fprintf(out, "\nChanges may require instances to restart: rolling-update the cluster\n")
+	return nil
+}
+
+// runRollingUpdate replaces the instances that need updating, as `kops rolling-update cluster --yes` does
+func runRollingUpdate(c *operations.ActionContext, out io.Writer) error {
+	options := &c.Operation.Spec
+
+	list, err := c.Clientset.InstanceGroupsFor(c.Cluster).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing InstanceGroups: %v", err)
+	}
+
+	var instanceGroups []*kops.InstanceGroup
+	for i := range list.Items {
+		instanceGroups = append(instanceGroups, &list.Items[i])
+	}
+
+	warnUnmatched := true
+	if len(options.InstanceGroups) != 0 {
+		var filtered []*kops.InstanceGroup
+		for _, name := range options.InstanceGroups {
+			var found *kops.InstanceGroup
+			for _, ig := range instanceGroups {
+				if ig.ObjectMeta.Name == name {
+					found = ig
+					break
+				}
+			}
+			if found == nil {
+				return fmt.Errorf("InstanceGroup %q not found", name)
+			}
+			filtered = append(filtered, found)
+		}
+		instanceGroups = filtered
+
+		// Don't warn if we find more ASGs than IGs
+		warnUnmatched = false
+	}
+
+	var config *rest.Config
+	var k8sClient kubernetes.Interface
+	var nodes []v1.Node
+	if !options.CloudOnly {
+		config, err = buildRestConfig(c)
+		if err != nil {
+			return err
+		}
+		k8sClient, err = kubernetes.NewForConfig(config)
+		if err != nil {
+			return fmt.Errorf("cannot build kube client for %q: %v", c.Cluster.ObjectMeta.Name, err)
+		}
+
+		nodeList, err := k8sClient.CoreV1().Nodes().List(metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("error listing nodes in cluster (use cloudOnly to rolling-update without the kubernetes API): %v", err)
+		}
+		nodes = nodeList.Items
+	}
+
+	cloud, err := cloudup.BuildCloud(c.Cluster)
+	if err != nil {
+		return err
+	}
+
+	groups, err := cloud.GetCloudGroups(c.Cluster, instanceGroups, warnUnmatched, nodes)
+	if err != nil {
+		return err
+	}
+
+	{
+		t := &tables.Table{}
+		t.AddColumn("NAME", func(r *cloudinstances.CloudInstanceGroup) string {
+			return r.InstanceGroup.ObjectMeta.Name
+		})
+		t.AddColumn("STATUS", func(r *cloudinstances.CloudInstanceGroup) string {
+			return r.Status()
+		})
+		t.AddColumn("NEEDUPDATE", func(r *cloudinstances.CloudInstanceGroup) string {
+			return strconv.Itoa(len(r.NeedUpdate))
+		})
+		t.AddColumn("READY", func(r *cloudinstances.CloudInstanceGroup) string {
+			return strconv.Itoa(len(r.Ready))
+		})
+		t.AddColumn("MIN", func(r *cloudinstances.CloudInstanceGroup) string {
+			return strconv.Itoa(r.MinSize)
+		})
+		t.AddColumn("MAX", func(r *cloudinstances.CloudInstanceGroup) string {
+			return strconv.Itoa(r.MaxSize)
+		})
+		var l []*cloudinstances.CloudInstanceGroup
+		for _, v := range groups {
+			l = append(l, v)
+		}
+		if err := t.Render(l, out, "NAME", "STATUS", "NEEDUPDATE", "READY", "MIN", "MAX"); err != nil {
+			return err
+		}
+	}
+
+	needUpdate := false
+	for _, group := range groups {
+		if len(group.NeedUpdate) != 0 {
+			needUpdate = true
+		}
+	}
+
+	if !needUpdate && !options.Force {
+		fmt.Fprintf(out, "\nNo rolling-update required.\n")
+		return nil
+	}
+
+	d := &instancegroups.RollingUpdateCluster{
+		MasterInterval:    5 * time.Minute,
+		NodeInterval:      4 * time.Minute,
+		BastionInterval:   5 * time.Minute,
+		Force:             options.Force,
+		Cloud:             cloud,
+		K8sClient:         k8sClient,
+		FailOnDrainError:  false,
+		FailOnValidate:    true,
+		CloudOnly:         options.CloudOnly,
+		ClusterName:       c.Cluster.ObjectMeta.Name,
+		PostDrainDelay:    90 * time.Second,
+		ValidationTimeout: 5 * time.Minute,
+	}
+	if config != nil {
+		d.ClientConfig = kutil.NewClientConfig(config, "kube-system")
+	}
+	if err := d.RollingUpdate(groups, c.Cluster, list); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nRolling-update completed for cluster %q\n", c.Cluster.ObjectMeta.Name)
+	return nil
+}
+
+// runValidate validates the cluster, as `kops validate cluster -o yaml` does; the operation fails if validation fails
+func runValidate(c *operations.ActionContext, out io.Writer) error {
+	list, err := c.Clientset.InstanceGroupsFor(c.Cluster).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("cannot get InstanceGroups for %q: %v", c.Cluster.ObjectMeta.Name, err)
+	}
+	if len(list.Items) == 0 {
+		return fmt.Errorf("no InstanceGroup objects found")
+	}
+
+	config, err := buildRestConfig(c)
+	if err != nil {
+		return err
+	}
+	k8sClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("cannot build kubernetes api client for %q: %v", c.Cluster.ObjectMeta.Name, err)
+	}
+
+	result, err := validation.ValidateCluster(c.Cluster, list, k8sClient)
+	if err != nil {
+		return fmt.Errorf("unexpected error during validation: %v", err)
+	}
+
+	y, err := yaml.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("unable to marshal YAML: %v", err)
+	}
+	if _, err := out.Write(y); err != nil {
+		return fmt.Errorf("error writing output: %v", err)
+	}
+
+	if len(result.Failures) != 0 {
+		return fmt.Errorf("cluster %q failed validation with %d failures", c.Cluster.ObjectMeta.Name, len(result.Failures))
+	}
+	return nil
+}
+
+// runKubeconfig writes an admin kubeconfig for the cluster, as `kops export kubecfg` does
+func runKubeconfig(c *operations.ActionContext, out io.Writer) error {
+	conf, err := buildKubeconfig(c)
+	if err != nil {
+		return err
+	}
+
+	data, err := conf.BuildKubeconfig()
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+// buildRestConfig returns the configuration for an admin client of the cluster
+func buildRestConfig(c *operations.ActionContext) (*rest.Config, error) {
+	conf, err := buildKubeconfig(c)
+	if err != nil {
+		return nil, err
+	}
+	return conf.BuildRestConfig()
+}
+
+func buildKubeconfig(c *operations.ActionContext) (*kubeconfig.KubeconfigBuilder, error) {
+	keyStore, err := c.Clientset.KeyStore(c.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	secretStore, err := c.Clientset.SecretStore(c.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	return kubeconfig.BuildKubecfg(c.Cluster, keyStore, secretStore, &commands.CloudDiscoveryStatusStore{})
+}
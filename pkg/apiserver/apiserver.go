@@ -18,6 +18,7 @@ package apiserver
 
 import (
 	"fmt"
+	"net/url"
 
 	"k8s.io/apimachinery/pkg/apimachinery/announced"
 	"k8s.io/apimachinery/pkg/apimachinery/registered"
@@ -32,8 +33,12 @@ import (
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/kops/install"
 	"k8s.io/kops/pkg/apis/kops/v1alpha2"
+	"k8s.io/kops/pkg/apiserver/operations"
 	registrycluster "k8s.io/kops/pkg/apiserver/registry/cluster"
 	registryinstancegroup "k8s.io/kops/pkg/apiserver/registry/instancegroup"
+	registryoperation "k8s.io/kops/pkg/apiserver/registry/operation"
+	kopsclient "k8s.io/kops/pkg/client/clientset_generated/clientset"
+	"k8s.io/kops/pkg/client/simple/api"
 )
 
 var (
@@ -61,8 +66,18 @@ func init() {
 	)
 }
 
+// actionSubresources maps the subresources of clusters to the actions they start
+var actionSubresources = map[string]kops.OperationAction{
+	"plan":          kops.OperationActionPlan,
+	"apply":         kops.OperationActionApply,
+	"rollingupdate": kops.OperationActionRollingUpdate,
+	"validate":      kops.OperationActionValidate,
+	"kubeconfig":    kops.OperationActionKubeconfig,
+}
+
 type ExtraConfig struct {
-	// Place you custom config here.
+	// Actions are the actions that can be run against clusters, through the action subresources
+	Actions map[kops.OperationAction]*operations.Action
 }
 
 type Config struct {
@@ -124,11 +139,42 @@ func (c completedConfig) New() (*KopsServer, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error initializing instancegroups: %v", err)
 	}
+
+	// Operations are created by the action subresources of clusters, which run the same code as the kops commands
+	operationStore, err := registryoperation.NewStore(Scheme, c.GenericConfig.RESTOptionsGetter)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing operations: %v", err)
+	}
+	v1alpha2storage["operations"] = registryoperation.NewREST(operationStore)
+
+	kopsClient, err := kopsclient.NewForConfig(c.GenericConfig.LoopbackClientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error building loopback kops client: %v", err)
+	}
+	clientset := &api.RESTClientset{
+		BaseURL:    &url.URL{Scheme: "k8s", Host: c.GenericConfig.LoopbackClientConfig.Host},
+		KopsClient: kopsClient.Kops(),
+	}
+	runner := operations.NewRunner(operationStore, clientset, c.ExtraConfig.Actions)
+	clusters := v1alpha2storage["clusters"].(rest.Getter)
+	for subresource, action := range actionSubresources {
+		v1alpha2storage["clusters/"+subresource] = operations.NewActionREST(runner, clusters, action)
+	}
+
 	apiGroupInfo.VersionedResourcesStorageMap["v1alpha2"] = v1alpha2storage
 
 	if err := s.GenericAPIServer.InstallAPIGroup(&apiGroupInfo); err != nil {
 		return nil, err
 	}
 
+	s.GenericAPIServer.AddPostStartHookOrDie("kops-fail-interrupted-operations", func(context genericapiserver.PostStartHookContext) error {
+		if err := runner.FailInterrupted(); err != nil {
+			return err
+		}
+		// Other instances of the server can stop while running operations, so keep checking for expired leases
+		go runner.FailInterruptedUntil(context.StopCh)
+		return nil
+	})
+
 	return s, nil
 }
@@ -35,6 +35,7 @@ import (
 	"k8s.io/apiserver/pkg/storage/storagebackend"
 	"k8s.io/kops/pkg/apis/kops/v1alpha2"
 	"k8s.io/kops/pkg/apiserver"
+	"k8s.io/kops/pkg/apiserver/actions"
 	"k8s.io/kops/pkg/openapi"
 )
 
@@ -51,6 +52,9 @@ type KopsServerOptions struct {
 	StdErr io.Writer
 
 	PrintOpenapi bool
+
+	// DisableAuth turns off authentication and authorization, allowing every request
+	DisableAuth bool
 }
 
 // NewCommandStartKopsServer provides a CLI handler for 'start master' command
@@ -98,12 +102,18 @@ func NewCommandStartKopsServer(out, err io.Writer) *cobra.Command {
 
 	flags.BoolVar(&o.PrintOpenapi, "print-openapi", false,
 		"Print the openapi json and exit")
+	flags.BoolVar(&o.DisableAuth, "disable-auth", false,
+		"Disable authentication and authorization, allowing every request; for development only")
 
 	return cmd
 }
 
 func (o KopsServerOptions) Validate(args []string) error {
 	errors := []error{}
+	if !o.DisableAuth {
+		errors = append(errors, o.Authentication.Validate()...)
+		errors = append(errors, o.Authorization.Validate()...)
+	}
 	//errors = append(errors, o.RecommendedOptions.Validate()...)
 	//errors = append(errors, o.Admission.Validate()...)
 	return utilerrors.NewAggregate(errors)
@@ -165,7 +175,18 @@ func (o KopsServerOptions) Config() (*apiserver.Config, error) {
 	//      return err
 	//}
 
-	glog.Warningf("Authentication/Authorization disabled")
+	// Authentication and authorization are delegated to the kubernetes apiserver (with TokenReview and SubjectAccessReview),
+	// so the action subresources (e.g. clusters/apply) and operations can be granted with RBAC
+	if o.DisableAuth {
+		glog.Warningf("Authentication/Authorization disabled")
+	} else {
+		if err := o.Authentication.ApplyTo(&serverConfig.Config); err != nil {
+			return nil, fmt.Errorf("error configuring authentication: %v", err)
+		}
+		if err := o.Authorization.ApplyTo(&serverConfig.Config); err != nil {
+			return nil, fmt.Errorf("error configuring authorization: %v", err)
+		}
+	}
 
 	//var err error
 	//privilegedLoopbackToken := uuid.NewRandom().String()
@@ -175,7 +196,9 @@ func (o KopsServerOptions) Config() (*apiserver.Config, error) {
 
 	config := &apiserver.Config{
 		GenericConfig: serverConfig,
-		ExtraConfig:   apiserver.ExtraConfig{},
+		ExtraConfig: apiserver.ExtraConfig{
+			Actions: actions.DefaultActions(),
+		},
 	}
 	return config, nil
 }
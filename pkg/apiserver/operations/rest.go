@@ -0,0 +1,98 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operations
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// ActionREST implements a subresource of clusters (for example clusters/apply), which starts an action
+// against the cluster when an Operation is POSTed to it, and returns the recorded Operation.
+// Only the options in the spec of the posted Operation are used.
+type ActionREST struct {
+	runner   *Runner
+	clusters rest.Getter
+	action   kops.OperationAction
+}
+
+var _ rest.NamedCreater = &ActionREST{}
+
+// NewActionREST returns the subresource storage for an action, reading clusters from clusters
+func NewActionREST(runner *Runner, clusters rest.Getter, action kops.OperationAction) *ActionREST {
+	return &ActionREST{
+		runner:   runner,
+		clusters: clusters,
+		action:   action,
+	}
+}
+
+// New implements rest.NamedCreater
+func (r *ActionREST) New() runtime.Object {
+	return &kops.Operation{}
+}
+
+// Create implements rest.NamedCreater
+func (r *ActionREST) Create(ctx genericapirequest.Context, name string, obj runtime.Object, createValidation rest.ValidateObjectFunc, includeUninitialized bool) (runtime.Object, error) {
+	request, ok := obj.(*kops.Operation)
+	if !ok {
+		return nil, errors.NewBadRequest(fmt.Sprintf("expected an Operation, got %T", obj))
+	}
+
+	// The cluster stores (keys, secrets, instance groups) are found by the namespace derived from the cluster name,
+	// so a cluster must be in that namespace to be acted on; otherwise access to one namespace would grant access to another.
+	namespace := genericapirequest.NamespaceValue(ctx)
+	if expected := namespaceForClusterName(name); namespace != expected {
+		return nil, errors.NewBadRequest(fmt.Sprintf("cluster %q must be in namespace %q", name, expected))
+	}
+
+	clusterObj, err := r.clusters.Get(ctx, name, &metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	cluster := clusterObj.(*kops.Cluster)
+
+	op := &kops.Operation{}
+	op.Namespace = namespace
+	op.GenerateName = operationGenerateName(name, r.action)
+	op.Labels = map[string]string{kops.LabelClusterName: name}
+	op.Spec.ClusterName = name
+	op.Spec.Action = r.action
+	if r.action == kops.OperationActionRollingUpdate {
+		op.Spec.InstanceGroups = request.Spec.InstanceGroups
+		op.Spec.Force = request.Spec.Force
+		op.Spec.CloudOnly = request.Spec.CloudOnly
+	}
+	if user, ok := genericapirequest.UserFrom(ctx); ok {
+		op.Spec.User = user.GetName()
+	}
+
+	return r.runner.Start(ctx, cluster, op, createValidation)
+}
+
+// namespaceForClusterName returns the namespace in which the kops server stores the cluster
+func namespaceForClusterName(clusterName string) string {
+	// We are not allowed dots, so we map them to dashes, matching the REST clientset
+	return strings.Replace(clusterName, ".", "-", -1)
+}
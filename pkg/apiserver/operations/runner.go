@@ -0,0 +1,445 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operations
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/wait"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/client/simple"
+)
+
+// defaultProgressInterval is how often the output of a running operation is saved, so clients can follow progress.
+// The lease on the operation is renewed at the same time.
+const defaultProgressInterval = 10 * time.Second
+
+// defaultLeaseDuration is how long an operation is considered to be running after its owner last renewed it
+const defaultLeaseDuration = time.Minute
+
+// Store is the subset of the operation storage used by the Runner
+type Store interface {
+	Create(ctx genericapirequest.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, includeUninitialized bool) (runtime.Object, error)
+	Get(ctx genericapirequest.Context, name string, options *metav1.GetOptions) (runtime.Object, error)
+	List(ctx genericapirequest.Context, options *metainternalversion.ListOptions) (runtime.Object, error)
+	Update(ctx genericapirequest.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc) (runtime.Object, bool, error)
+}
+
+// Action is an action that can be run against a cluster
+type Action struct {
+	// Run performs the action, writing the output that the equivalent kops command would print to out
+	Run func(c *ActionContext, out io.Writer) error
+
+	// Exclusive actions change the cluster, so only one exclusive action runs against a cluster at a time
+	Exclusive bool
+
+	// Synchronous actions complete before the request returns.
+	// Their output is returned to the caller, but is not stored, so it can contain credentials.
+	Synchronous bool
+}
+
+// ActionContext holds the state passed to an Action
+type ActionContext struct {
+	Clientset simple.Clientset
+	Cluster   *kops.Cluster
+	Operation *kops.Operation
+}
+
+// Runner runs actions against clusters, recording their progress and results in Operation objects.
+// Several instances of the server can share the store: each operation records the instance which owns it,
+// and the owner renews a lease on the operation while it is running.
+type Runner struct {
+	store     Store
+	clientset simple.Clientset
+	actions   map[kops.OperationAction]*Action
+
+	// identity is recorded as the owner of the operations started by this runner
+	identity string
+
+	// progressInterval is how often the output of a running operation is saved, and its lease renewed
+	progressInterval time.Duration
+	// leaseDuration is how long an operation is considered to be running after its lease was last renewed
+	leaseDuration time.Duration
+
+	mutex sync.Mutex
+	// exclusive holds the name of the exclusive operation running against each cluster, keyed by namespace/cluster
+	exclusive map[string]string
+	// running holds the operations this runner is running, keyed by namespace/name
+	running map[string]bool
+}
+
+// NewRunner builds a Runner which records operations in store, and runs actions with clientset
+func NewRunner(store Store, clientset simple.Clientset, actions map[kops.OperationAction]*Action) *Runner {
+	return &Runner{
+		store:            store,
+		clientset:        clientset,
+		actions:          actions,
+		identity:         newIdentity(),
+		progressInterval: defaultProgressInterval,
+		leaseDuration:    defaultLeaseDuration,
+		exclusive:        make(map[string]string),
+		running:          make(map[string]bool),
+	}
+}
+
+// newIdentity builds a name for this instance of the server, which is unique even if the hostname is not
+func newIdentity() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		glog.Warningf("error getting hostname: %v", err)
+		hostname = "kops-server"
+	}
+	return hostname + "_" + string(uuid.NewUUID())
+}
+
+// Start records the operation and starts running it against the cluster.
+// The operation is returned once it is recorded, unless the action is synchronous.
+func (r *Runner) Start(ctx genericapirequest.Context, cluster *kops.Cluster, op *kops.Operation, createValidation rest.ValidateObjectFunc) (*kops.Operation, error) {
+	action := r.actions[op.Spec.Action]
+	if action == nil {
+		return nil, errors.NewBadRequest(fmt.Sprintf("action %q is not supported", op.Spec.Action))
+	}
+
+	key := op.Namespace + "/" + op.Spec.ClusterName
+	if action.Exclusive {
+		if err := r.reserve(key, op.Spec.ClusterName); err != nil {
+			return nil, err
+		}
+		// Another instance of the server may be running an exclusive operation against the cluster
+		if err := r.checkExclusive(ctx, op); err != nil {
+			r.release(key)
+			return nil, err
+		}
+	}
+
+	now := metav1.Now()
+	op.Status = kops.OperationStatus{
+		Phase:     kops.OperationPending,
+		Owner:     r.identity,
+		RenewTime: &now,
+	}
+	obj, err := r.store.Create(ctx, op, createValidation, false)
+	if err != nil {
+		if action.Exclusive {
+			r.release(key)
+		}
+		return nil, err
+	}
+	created := obj.(*kops.Operation)
+
+	r.mutex.Lock()
+	if action.Exclusive {
+		r.exclusive[key] = created.Name
+	}
+	r.running[created.Namespace+"/"+created.Name] = true
+	r.mutex.Unlock()
+
+	// The operation outlives the request, so it runs with a context of its own
+	runCtx := genericapirequest.WithNamespace(genericapirequest.NewContext(), created.Namespace)
+	if action.Synchronous {
+		return r.run(runCtx, key, action, cluster, created), nil
+	}
+
+	go r.run(runCtx, key, action, cluster, created)
+	return created, nil
+}
+
+// reserve marks the cluster as running an exclusive operation, failing if one is already running
+func (r *Runner) reserve(key string, clusterName string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if running, found := r.exclusive[key]; found {
+		return errors.NewConflict(kops.Resource("clusters"), clusterName, fmt.Errorf("operation %q is already running against the cluster", running))
+	}
+	// The operation name is not known until it is created
+	r.exclusive[key] = "(pending)"
+	return nil
+}
+
+// release marks the cluster as no longer running an exclusive operation
+func (r *Runner) release(key string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.exclusive, key)
+}
+
+// checkExclusive fails if an exclusive operation against the cluster is recorded in the store, and its lease is current
+func (r *Runner) checkExclusive(ctx genericapirequest.Context, op *kops.Operation) error {
+	obj, err := r.store.List(ctx, &metainternalversion.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing operations: %v", err)
+	}
+
+	now := time.Now()
+	for i := range obj.(*kops.OperationList).Items {
+		existing := &obj.(*kops.OperationList).Items[i]
+		if existing.Namespace != op.Namespace || existing.Spec.ClusterName != op.Spec.ClusterName {
+			continue
+		}
+		if existing.Status.IsFinished() || r.leaseExpired(existing, now) {
+			continue
+		}
+		if action := r.actions[existing.Spec.Action]; action == nil || !action.Exclusive {
+			continue
+		}
+		return errors.NewConflict(kops.Resource("clusters"), op.Spec.ClusterName, fmt.Errorf("operation %q is already running against the cluster", existing.Name))
+	}
+	return nil
+}
+
+// isRunning returns true if this runner is running the operation
+func (r *Runner) isRunning(op *kops.Operation) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.running[op.Namespace+"/"+op.Name]
+}
+
+// leaseExpired returns true if the owner of the operation has not renewed it within the lease duration.
+// Operations recorded before leases were introduced fall back to their start or creation time.
+func (r *Runner) leaseExpired(op *kops.Operation, now time.Time) bool {
+	renewed := op.CreationTimestamp.Time
+	if op.Status.RenewTime != nil {
+		renewed = op.Status.RenewTime.Time
+	} else if op.Status.StartTime != nil {
+		renewed = op.Status.StartTime.Time
+	}
+	return now.Sub(renewed) > r.leaseDuration
+}
+
+// run runs the action, recording its progress, and returns the finished operation.
+// The output of synchronous actions is returned but not stored.
+func (r *Runner) run(ctx genericapirequest.Context, key string, action *Action, cluster *kops.Cluster, op *kops.Operation) *kops.Operation {
+	if action.Exclusive {
+		defer r.release(key)
+	}
+	defer func() {
+		r.mutex.Lock()
+		delete(r.running, op.Namespace+"/"+op.Name)
+		r.mutex.Unlock()
+	}()
+
+	glog.Infof("starting operation %s/%s: %s of cluster %q", op.Namespace, op.Name, op.Spec.Action, op.Spec.ClusterName)
+
+	now := metav1.Now()
+	if _, err := r.updateStatus(ctx, op.Name, func(status *kops.OperationStatus) {
+		status.Phase = kops.OperationRunning
+		status.StartTime = &now
+		status.RenewTime = &now
+	}); err != nil {
+		glog.Warningf("error recording start of operation %s/%s: %v", op.Namespace, op.Name, err)
+	}
+
+	out := &syncBuffer{}
+	done := make(chan struct{})
+	go r.heartbeat(ctx, op.Name, out, !action.Synchronous, done)
+
+	err := runAction(action, &ActionContext{
+		Clientset: r.clientset,
+		Cluster:   cluster,
+		Operation: op,
+	}, out)
+	close(done)
+
+	output := out.String()
+	completed := metav1.Now()
+	finished, updateErr := r.updateStatus(ctx, op.Name, func(status *kops.OperationStatus) {
+		status.CompletionTime = &completed
+		if err != nil {
+			status.Phase = kops.OperationFailed
+			status.Message = err.Error()
+		} else {
+			status.Phase = kops.OperationSucceeded
+			status.Message = fmt.Sprintf("%s of cluster %q completed", op.Spec.Action, op.Spec.ClusterName)
+		}
+		if !action.Synchronous {
+			status.Output = output
+		}
+	})
+	if updateErr != nil {
+		glog.Warningf("error recording result of operation %s/%s: %v", op.Namespace, op.Name, updateErr)
+		finished = op.DeepCopy()
+		finished.Status.Phase = kops.OperationFailed
+		finished.Status.Message = fmt.Sprintf("error recording result: %v", updateErr)
+	}
+
+	if err != nil {
+		glog.Warningf("operation %s/%s failed: %v", op.Namespace, op.Name, err)
+	} else {
+		glog.Infof("operation %s/%s succeeded", op.Namespace, op.Name)
+	}
+
+	if action.Synchronous {
+		finished.Status.Output = output
+	}
+	return finished
+}
+
+// runAction runs the action, converting a panic into an error so that a bad action can't take down the server
+func runAction(action *Action, c *ActionContext, out io.Writer) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			glog.Errorf("panic running %s of cluster %q: %v", c.Operation.Spec.Action, c.Operation.Spec.ClusterName, r)
+			err = fmt.Errorf("internal error running %s: %v", c.Operation.Spec.Action, r)
+		}
+	}()
+	return action.Run(c, out)
+}
+
+// heartbeat periodically renews the lease on a running operation, until done is closed.
+// If saveOutput is set, the output so far is saved at the same time, so clients can follow progress.
+func (r *Runner) heartbeat(ctx genericapirequest.Context, name string, out *syncBuffer, saveOutput bool, done chan struct{}) {
+	ticker := time.NewTicker(r.progressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			output := out.String()
+			now := metav1.Now()
+			if _, err := r.updateStatus(ctx, name, func(status *kops.OperationStatus) {
+				if status.IsFinished() {
+					return
+				}
+				status.RenewTime = &now
+				if saveOutput {
+					status.Output = output
+				}
+			}); err != nil {
+				glog.Warningf("error renewing operation %q: %v", name, err)
+			}
+		}
+	}
+}
+
+// updateStatus applies mutator to the stored status of the operation, retrying on conflicts
+func (r *Runner) updateStatus(ctx genericapirequest.Context, name string, mutator func(status *kops.OperationStatus)) (*kops.Operation, error) {
+	var updated *kops.Operation
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		obj, err := r.store.Get(ctx, name, &metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		op := obj.(*kops.Operation).DeepCopy()
+		mutator(&op.Status)
+
+		obj, _, err = r.store.Update(ctx, name, rest.DefaultUpdatedObjectInfo(op), rest.ValidateAllObjectFunc, rest.ValidateAllObjectUpdateFunc)
+		if err != nil {
+			return err
+		}
+		updated = obj.(*kops.Operation)
+		return nil
+	})
+	return updated, err
+}
+
+// FailInterrupted marks operations that were left pending or running as failed, once their lease has expired.
+// The lease on an operation expires when the instance of the server which owns it stops or restarts.
+func (r *Runner) FailInterrupted() error {
+	ctx := genericapirequest.WithNamespace(genericapirequest.NewContext(), metav1.NamespaceAll)
+	obj, err := r.store.List(ctx, &metainternalversion.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing operations: %v", err)
+	}
+
+	for i := range obj.(*kops.OperationList).Items {
+		op := &obj.(*kops.OperationList).Items[i]
+		if op.Status.IsFinished() || r.isRunning(op) {
+			continue
+		}
+		if !r.leaseExpired(op, time.Now()) {
+			glog.V(2).Infof("operation %s/%s is owned by %q, which renewed it recently", op.Namespace, op.Name, op.Status.Owner)
+			continue
+		}
+
+		glog.Warningf("marking interrupted operation %s/%s (owned by %q) as failed", op.Namespace, op.Name, op.Status.Owner)
+		opCtx := genericapirequest.WithNamespace(genericapirequest.NewContext(), op.Namespace)
+		renewed := op.Status.RenewTime
+		now := metav1.Now()
+		if _, err := r.updateStatus(opCtx, op.Name, func(status *kops.OperationStatus) {
+			if status.IsFinished() {
+				return
+			}
+			if renewed != nil && status.RenewTime != nil && !status.RenewTime.Equal(renewed) {
+				// The owner renewed the lease concurrently
+				return
+			}
+			status.Phase = kops.OperationFailed
+			status.CompletionTime = &now
+			status.Message = "operation was interrupted, because the kops server running it stopped"
+		}); err != nil {
+			return fmt.Errorf("error updating operation %s/%s: %v", op.Namespace, op.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// FailInterruptedUntil periodically marks the operations of stopped instances of the server as failed, until stopCh is closed
+func (r *Runner) FailInterruptedUntil(stopCh <-chan struct{}) {
+	wait.Until(func() {
+		if err := r.FailInterrupted(); err != nil {
+			glog.Warningf("error failing interrupted operations: %v", err)
+		}
+	}, r.leaseDuration, stopCh)
+}
+
+// operationGenerateName returns the prefix for the names of operations of an action against a cluster
+func operationGenerateName(clusterName string, action kops.OperationAction) string {
+	return strings.Replace(clusterName, ".", "-", -1) + "-" + strings.ToLower(string(action)) + "-"
+}
+
+// syncBuffer is a bytes.Buffer that can be written and read concurrently
+type syncBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+var _ io.Writer = &syncBuffer{}
+
+// Write implements io.Writer
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(p)
+}
+
+// String returns everything written so far
+func (b *syncBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.String()
+}
@@ -0,0 +1,429 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operations
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/user"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// memoryStore is an in-memory Store
+type memoryStore struct {
+	mutex   sync.Mutex
+	version int
+	objects map[string]*kops.Operation
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{objects: make(map[string]*kops.Operation)}
+}
+
+func (s *memoryStore) Create(ctx genericapirequest.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, includeUninitialized bool) (runtime.Object, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	op := obj.(*kops.Operation).DeepCopy()
+	s.version++
+	if op.Name == "" {
+		op.Name = op.GenerateName + strconv.Itoa(s.version)
+	}
+	op.ResourceVersion = strconv.Itoa(s.version)
+	s.objects[op.Name] = op
+	return op.DeepCopy(), nil
+}
+
+func (s *memoryStore) Get(ctx genericapirequest.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	op := s.objects[name]
+	if op == nil {
+		return nil, errors.NewNotFound(kops.Resource("operations"), name)
+	}
+	return op.DeepCopy(), nil
+}
+
+func (s *memoryStore) List(ctx genericapirequest.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	list := &kops.OperationList{}
+	for _, op := range s.objects {
+		list.Items = append(list.Items, *op.DeepCopy())
+	}
+	return list, nil
+}
+
+func (s *memoryStore) Update(ctx genericapirequest.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc) (runtime.Object, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	old := s.objects[name]
+	if old == nil {
+		return nil, false, errors.NewNotFound(kops.Resource("operations"), name)
+	}
+	obj, err := objInfo.UpdatedObject(ctx, old.DeepCopy())
+	if err != nil {
+		return nil, false, err
+	}
+	op := obj.(*kops.Operation).DeepCopy()
+	if op.ResourceVersion != old.ResourceVersion {
+		return nil, false, errors.NewConflict(kops.Resource("operations"), name, fmt.Errorf("the object has been modified"))
+	}
+	s.version++
+	op.ResourceVersion = strconv.Itoa(s.version)
+	s.objects[name] = op
+	return op.DeepCopy(), false, nil
+}
+
+func (s *memoryStore) get(name string) *kops.Operation {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.objects[name].DeepCopy()
+}
+
+// clusterGetter is a rest.Getter returning a fixed set of clusters
+type clusterGetter map[string]*kops.Cluster
+
+func (g clusterGetter) Get(ctx genericapirequest.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	cluster := g[genericapirequest.NamespaceValue(ctx)+"/"+name]
+	if cluster == nil {
+		return nil, errors.NewNotFound(kops.Resource("clusters"), name)
+	}
+	return cluster, nil
+}
+
+func newCluster(name string) *kops.Cluster {
+	cluster := &kops.Cluster{}
+	cluster.Name = name
+	cluster.Namespace = namespaceForClusterName(name)
+	return cluster
+}
+
+func requestContext(namespace string) genericapirequest.Context {
+	ctx := genericapirequest.WithNamespace(genericapirequest.NewContext(), namespace)
+	return genericapirequest.WithUser(ctx, &user.DefaultInfo{Name: "alice"})
+}
+
+// waitForFinished polls the store until the operation has finished
+func waitForFinished(t *testing.T, store *memoryStore, name string) *kops.Operation {
+	for i := 0; i < 500; i++ {
+		op := store.get(name)
+		if op.Status.IsFinished() {
+			return op
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("operation %q did not finish", name)
+	return nil
+}
+
+func TestActionREST(t *testing.T) {
+	store := newMemoryStore()
+	clusters := clusterGetter{"example-com/example.com": newCluster("example.com")}
+
+	var ranAgainst string
+	actions := map[kops.OperationAction]*Action{
+		kops.OperationActionValidate: {
+			Run: func(c *ActionContext, out io.Writer) error {
+				ranAgainst = c.Cluster.Name
+				fmt.Fprintf(out, "Your cluster %s is ready\n", c.Cluster.Name)
+				return nil
+			},
+		},
+		kops.OperationActionApply: {
+			Run: func(c *ActionContext, out io.Writer) error {
+				fmt.Fprintf(out, "partial output\n")
+				return fmt.Errorf("apply failed")
+			},
+		},
+		kops.OperationActionPlan: {
+			Run: func(c *ActionContext, out io.Writer) error {
+				panic("bad action")
+			},
+		},
+		kops.OperationActionKubeconfig: {
+			Run: func(c *ActionContext, out io.Writer) error {
+				fmt.Fprintf(out, "secret-kubeconfig")
+				return nil
+			},
+			Synchronous: true,
+		},
+	}
+	runner := NewRunner(store, nil, actions)
+
+	{
+		obj, err := NewActionREST(runner, clusters, kops.OperationActionValidate).Create(requestContext("example-com"), "example.com", &kops.Operation{}, rest.ValidateAllObjectFunc, false)
+		if err != nil {
+			t.Fatalf("error starting validate: %v", err)
+		}
+		op := obj.(*kops.Operation)
+		if op.Spec.Action != kops.OperationActionValidate || op.Spec.ClusterName != "example.com" || op.Spec.User != "alice" {
+			t.Fatalf("unexpected operation spec %+v", op.Spec)
+		}
+		if !strings.HasPrefix(op.Name, "example-com-validate-") {
+			t.Fatalf("unexpected operation name %q", op.Name)
+		}
+		if op.Labels[kops.LabelClusterName] != "example.com" {
+			t.Fatalf("operation was not labeled with the cluster: %v", op.Labels)
+		}
+
+		finished := waitForFinished(t, store, op.Name)
+		if finished.Status.Phase != kops.OperationSucceeded {
+			t.Fatalf("expected validate to succeed, got %+v", finished.Status)
+		}
+		if finished.Status.Output != "Your cluster example.com is ready\n" {
+			t.Fatalf("unexpected output %q", finished.Status.Output)
+		}
+		if finished.Status.StartTime == nil || finished.Status.CompletionTime == nil {
+			t.Fatalf("start and completion times were not recorded: %+v", finished.Status)
+		}
+		if ranAgainst != "example.com" {
+			t.Fatalf("action ran against %q", ranAgainst)
+		}
+	}
+
+	{
+		obj, err := NewActionREST(runner, clusters, kops.OperationActionApply).Create(requestContext("example-com"), "example.com", &kops.Operation{}, rest.ValidateAllObjectFunc, false)
+		if err != nil {
+			t.Fatalf("error starting apply: %v", err)
+		}
+		finished := waitForFinished(t, store, obj.(*kops.Operation).Name)
+		if finished.Status.Phase != kops.OperationFailed || finished.Status.Message != "apply failed" {
+			t.Fatalf("expected apply to fail, got %+v", finished.Status)
+		}
+		if finished.Status.Output != "partial output\n" {
+			t.Fatalf("unexpected output %q", finished.Status.Output)
+		}
+	}
+
+	{
+		obj, err := NewActionREST(runner, clusters, kops.OperationActionPlan).Create(requestContext("example-com"), "example.com", &kops.Operation{}, rest.ValidateAllObjectFunc, false)
+		if err != nil {
+			t.Fatalf("error starting plan: %v", err)
+		}
+		finished := waitForFinished(t, store, obj.(*kops.Operation).Name)
+		if finished.Status.Phase != kops.OperationFailed || !strings.Contains(finished.Status.Message, "bad action") {
+			t.Fatalf("expected panicking plan to fail, got %+v", finished.Status)
+		}
+	}
+
+	{
+		obj, err := NewActionREST(runner, clusters, kops.OperationActionKubeconfig).Create(requestContext("example-com"), "example.com", &kops.Operation{}, rest.ValidateAllObjectFunc, false)
+		if err != nil {
+			t.Fatalf("error running kubeconfig: %v", err)
+		}
+		op := obj.(*kops.Operation)
+		if op.Status.Phase != kops.OperationSucceeded || op.Status.Output != "secret-kubeconfig" {
+			t.Fatalf("expected kubeconfig to be returned, got %+v", op.Status)
+		}
+		if stored := store.get(op.Name); stored.Status.Output != "" {
+			t.Fatalf("kubeconfig output was stored: %q", stored.Status.Output)
+		}
+	}
+
+	// The cluster must be in the namespace derived from its name
+	if _, err := NewActionREST(runner, clusters, kops.OperationActionValidate).Create(requestContext("other"), "example.com", &kops.Operation{}, rest.ValidateAllObjectFunc, false); !errors.IsBadRequest(err) {
+		t.Fatalf("expected BadRequest acting on a cluster in another namespace, got %v", err)
+	}
+
+	if _, err := NewActionREST(runner, clusters, kops.OperationActionValidate).Create(requestContext("missing-example-com"), "missing.example.com", &kops.Operation{}, rest.ValidateAllObjectFunc, false); !errors.IsNotFound(err) {
+		t.Fatalf("expected NotFound acting on a missing cluster, got %v", err)
+	}
+
+	if _, err := NewActionREST(runner, clusters, kops.OperationActionRollingUpdate).Create(requestContext("example-com"), "example.com", &kops.Operation{}, rest.ValidateAllObjectFunc, false); !errors.IsBadRequest(err) {
+		t.Fatalf("expected BadRequest for an unsupported action, got %v", err)
+	}
+}
+
+func TestExclusiveActions(t *testing.T) {
+	store := newMemoryStore()
+	clusters := clusterGetter{
+		"a-example-com/a.example.com": newCluster("a.example.com"),
+		"b-example-com/b.example.com": newCluster("b.example.com"),
+	}
+
+	release := make(chan struct{})
+	var igs []string
+	actions := map[kops.OperationAction]*Action{
+		kops.OperationActionRollingUpdate: {
+			Run: func(c *ActionContext, out io.Writer) error {
+				igs = c.Operation.Spec.InstanceGroups
+				<-release
+				return nil
+			},
+			Exclusive: true,
+		},
+		kops.OperationActionApply: {
+			Run: func(c *ActionContext, out io.Writer) error {
+				return nil
+			},
+			Exclusive: true,
+		},
+	}
+	runner := NewRunner(store, nil, actions)
+
+	request := &kops.Operation{}
+	request.Spec.InstanceGroups = []string{"nodes"}
+	request.Spec.Action = kops.OperationActionApply
+	obj, err := NewActionREST(runner, clusters, kops.OperationActionRollingUpdate).Create(requestContext("a-example-com"), "a.example.com", request, rest.ValidateAllObjectFunc, false)
+	if err != nil {
+		t.Fatalf("error starting rolling-update: %v", err)
+	}
+	rollingUpdate := obj.(*kops.Operation)
+	if rollingUpdate.Spec.Action != kops.OperationActionRollingUpdate {
+		t.Fatalf("the action was taken from the request: %v", rollingUpdate.Spec.Action)
+	}
+
+	// A second exclusive action against the same cluster is rejected while the first is running
+	if _, err := NewActionREST(runner, clusters, kops.OperationActionApply).Create(requestContext("a-example-com"), "a.example.com", &kops.Operation{}, rest.ValidateAllObjectFunc, false); !errors.IsConflict(err) {
+		t.Fatalf("expected Conflict applying during a rolling-update, got %v", err)
+	}
+
+	// ... but not against another cluster
+	obj, err = NewActionREST(runner, clusters, kops.OperationActionApply).Create(requestContext("b-example-com"), "b.example.com", &kops.Operation{}, rest.ValidateAllObjectFunc, false)
+	if err != nil {
+		t.Fatalf("error applying another cluster: %v", err)
+	}
+	waitForFinished(t, store, obj.(*kops.Operation).Name)
+
+	close(release)
+	finished := waitForFinished(t, store, rollingUpdate.Name)
+	if finished.Status.Phase != kops.OperationSucceeded {
+		t.Fatalf("expected rolling-update to succeed, got %+v", finished.Status)
+	}
+	if len(igs) != 1 || igs[0] != "nodes" {
+		t.Fatalf("rolling-update options were not passed: %v", igs)
+	}
+
+	// The cluster is released once the operation finishes
+	var lastErr error
+	for i := 0; i < 500; i++ {
+		if _, lastErr = NewActionREST(runner, clusters, kops.OperationActionApply).Create(requestContext("a-example-com"), "a.example.com", &kops.Operation{}, rest.ValidateAllObjectFunc, false); lastErr == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if lastErr != nil {
+		t.Fatalf("error applying after the rolling-update finished: %v", lastErr)
+	}
+}
+
+func TestFailInterrupted(t *testing.T) {
+	store := newMemoryStore()
+	recent := metav1.NewTime(time.Now().Add(-10 * time.Second))
+	stale := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+	for name, status := range map[string]kops.OperationStatus{
+		// recorded before operations had leases
+		"pending":   {Phase: kops.OperationPending},
+		"running":   {Phase: kops.OperationRunning},
+		"succeeded": {Phase: kops.OperationSucceeded},
+		// running on another instance of the server
+		"renewed": {Phase: kops.OperationRunning, Owner: "other", RenewTime: &recent},
+		// the instance of the server running it stopped
+		"expired": {Phase: kops.OperationRunning, Owner: "other", RenewTime: &stale},
+	} {
+		op := &kops.Operation{}
+		op.Name = name
+		op.Namespace = "example-com"
+		op.Spec.ClusterName = "example.com"
+		op.Spec.Action = kops.OperationActionApply
+		op.Status = status
+		if _, err := store.Create(nil, op, nil, false); err != nil {
+			t.Fatalf("error creating operation: %v", err)
+		}
+	}
+
+	runner := NewRunner(store, nil, nil)
+	if err := runner.FailInterrupted(); err != nil {
+		t.Fatalf("error failing interrupted operations: %v", err)
+	}
+
+	for name, expected := range map[string]kops.OperationPhase{
+		"pending":   kops.OperationFailed,
+		"running":   kops.OperationFailed,
+		"succeeded": kops.OperationSucceeded,
+		"renewed":   kops.OperationRunning,
+		"expired":   kops.OperationFailed,
+	} {
+		op := store.get(name)
+		if op.Status.Phase != expected {
+			t.Errorf("operation %q: expected phase %s, got %s", name, expected, op.Status.Phase)
+		}
+		if expected == kops.OperationFailed && op.Status.CompletionTime == nil {
+			t.Errorf("operation %q: completion time was not set", name)
+		}
+	}
+}
+
+func TestExclusiveAcrossServers(t *testing.T) {
+	store := newMemoryStore()
+	clusters := clusterGetter{"example-com/example.com": newCluster("example.com")}
+	actions := map[kops.OperationAction]*Action{
+		kops.OperationActionApply: {
+			Run: func(c *ActionContext, out io.Writer) error {
+				return nil
+			},
+			Exclusive: true,
+		},
+	}
+
+	// Another instance of the server is applying the cluster
+	other := &kops.Operation{}
+	other.Name = "other-apply"
+	other.Namespace = "example-com"
+	other.Spec.ClusterName = "example.com"
+	other.Spec.Action = kops.OperationActionApply
+	renewed := metav1.Now()
+	other.Status = kops.OperationStatus{Phase: kops.OperationRunning, Owner: "other", RenewTime: &renewed}
+	if _, err := store.Create(nil, other, nil, false); err != nil {
+		t.Fatalf("error creating operation: %v", err)
+	}
+
+	runner := NewRunner(store, nil, actions)
+	apply := NewActionREST(runner, clusters, kops.OperationActionApply)
+	if _, err := apply.Create(requestContext("example-com"), "example.com", &kops.Operation{}, rest.ValidateAllObjectFunc, false); !errors.IsConflict(err) {
+		t.Fatalf("expected Conflict applying while another server is applying, got %v", err)
+	}
+
+	// Once the lease of the other server expires, the cluster can be applied again
+	runner.leaseDuration = time.Millisecond
+	time.Sleep(10 * time.Millisecond)
+	obj, err := apply.Create(requestContext("example-com"), "example.com", &kops.Operation{}, rest.ValidateAllObjectFunc, false)
+	if err != nil {
+		t.Fatalf("error applying after the lease expired: %v", err)
+	}
+	created := obj.(*kops.Operation)
+	if created.Status.Owner != runner.identity || created.Status.RenewTime == nil {
+		t.Fatalf("operation does not record its owner and lease: %+v", created.Status)
+	}
+	waitForFinished(t, store, created.Name)
+}
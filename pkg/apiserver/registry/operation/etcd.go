@@ -0,0 +1,110 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operation
+
+import (
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1alpha1 "k8s.io/apimachinery/pkg/apis/meta/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/generic"
+	genericregistry "k8s.io/apiserver/pkg/registry/generic/registry"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// NewStore returns the storage for kops Operations.
+// The store is used directly by the operation runner, and is exposed through the API by REST.
+func NewStore(scheme *runtime.Scheme, optsGetter generic.RESTOptionsGetter) (*genericregistry.Store, error) {
+	strategy := NewStrategy(scheme)
+
+	store := &genericregistry.Store{
+		NewFunc: func() runtime.Object {
+			return &kops.Operation{}
+		},
+		NewListFunc: func() runtime.Object {
+			return &kops.OperationList{}
+		},
+		ObjectNameFunc: func(obj runtime.Object) (string, error) {
+			return obj.(*kops.Operation).Name, nil
+		},
+		PredicateFunc:            MatchOperation,
+		DefaultQualifiedResource: kops.Resource("operations"),
+
+		CreateStrategy: strategy,
+		UpdateStrategy: strategy,
+		DeleteStrategy: strategy,
+	}
+	options := &generic.StoreOptions{RESTOptions: optsGetter, AttrFunc: GetAttrs}
+	if err := store.CompleteWithOptions(options); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// REST exposes kops Operations through the API.
+// Operations are created and updated only by the cluster action subresources, so REST does not allow create or update.
+type REST struct {
+	store *genericregistry.Store
+}
+
+var _ rest.Getter = &REST{}
+var _ rest.Lister = &REST{}
+var _ rest.Watcher = &REST{}
+var _ rest.GracefulDeleter = &REST{}
+
+// NewREST returns a read-only RESTStorage object for the operations in store
+func NewREST(store *genericregistry.Store) *REST {
+	return &REST{store: store}
+}
+
+// New implements rest.Storage
+func (r *REST) New() runtime.Object {
+	return r.store.New()
+}
+
+// NewList implements rest.Lister
+func (r *REST) NewList() runtime.Object {
+	return r.store.NewList()
+}
+
+// Get implements rest.Getter
+func (r *REST) Get(ctx genericapirequest.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	return r.store.Get(ctx, name, options)
+}
+
+// List implements rest.Lister
+func (r *REST) List(ctx genericapirequest.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+	return r.store.List(ctx, options)
+}
+
+// Watch implements rest.Watcher
+func (r *REST) Watch(ctx genericapirequest.Context, options *metainternalversion.ListOptions) (watch.Interface, error) {
+	return r.store.Watch(ctx, options)
+}
+
+// Delete implements rest.GracefulDeleter, so that the history of finished operations can be cleaned up
+func (r *REST) Delete(ctx genericapirequest.Context, name string, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	return r.store.Delete(ctx, name, options)
+}
+
+// ConvertToTable implements rest.TableConvertor
+func (r *REST) ConvertToTable(ctx genericapirequest.Context, object runtime.Object, tableOptions runtime.Object) (*metav1alpha1.Table, error) {
+	return r.store.ConvertToTable(ctx, object, tableOptions)
+}
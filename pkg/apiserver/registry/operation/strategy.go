@@ -0,0 +1,127 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operation
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/generic"
+	"k8s.io/apiserver/pkg/storage"
+	"k8s.io/apiserver/pkg/storage/names"
+
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+type operationStrategy struct {
+	runtime.ObjectTyper
+	names.NameGenerator
+}
+
+func NewStrategy(typer runtime.ObjectTyper) operationStrategy {
+	return operationStrategy{typer, names.SimpleNameGenerator}
+}
+
+func (operationStrategy) NamespaceScoped() bool {
+	return true
+}
+
+func (operationStrategy) PrepareForCreate(ctx genericapirequest.Context, obj runtime.Object) {
+}
+
+func (operationStrategy) PrepareForUpdate(ctx genericapirequest.Context, obj, old runtime.Object) {
+}
+
+func (operationStrategy) Validate(ctx genericapirequest.Context, obj runtime.Object) field.ErrorList {
+	return validateOperationSpec(obj.(*kops.Operation))
+}
+
+func (operationStrategy) AllowCreateOnUpdate() bool {
+	return false
+}
+
+func (operationStrategy) AllowUnconditionalUpdate() bool {
+	return false
+}
+
+func (operationStrategy) Canonicalize(obj runtime.Object) {
+}
+
+func (operationStrategy) ValidateUpdate(ctx genericapirequest.Context, obj, old runtime.Object) field.ErrorList {
+	allErrs := validateOperationSpec(obj.(*kops.Operation))
+
+	// Only the status of an operation changes as it runs
+	oldSpec := old.(*kops.Operation).Spec
+	newSpec := obj.(*kops.Operation).Spec
+	if newSpec.ClusterName != oldSpec.ClusterName {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "clusterName"), "clusterName cannot be changed"))
+	}
+	if newSpec.Action != oldSpec.Action {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "action"), "action cannot be changed"))
+	}
+
+	return allErrs
+}
+
+func validateOperationSpec(op *kops.Operation) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if op.Spec.ClusterName == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "clusterName"), ""))
+	}
+
+	known := false
+	var actions []string
+	for _, action := range kops.OperationActions {
+		if op.Spec.Action == action {
+			known = true
+		}
+		actions = append(actions, string(action))
+	}
+	if !known {
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("spec", "action"), op.Spec.Action, actions))
+	}
+
+	return allErrs
+}
+
+func GetAttrs(obj runtime.Object) (labels.Set, fields.Set, bool, error) {
+	operation, ok := obj.(*kops.Operation)
+	if !ok {
+		return nil, nil, false, fmt.Errorf("given object is not an Operation.")
+	}
+	return labels.Set(operation.Labels), OperationToSelectableFields(operation), operation.Initializers != nil, nil
+}
+
+// MatchOperation is the filter used by the generic etcd backend to watch events
+// from etcd to clients of the apiserver only interested in specific labels/fields.
+func MatchOperation(label labels.Selector, field fields.Selector) storage.SelectionPredicate {
+	return storage.SelectionPredicate{
+		Label:    label,
+		Field:    field,
+		GetAttrs: GetAttrs,
+	}
+}
+
+// OperationToSelectableFields returns a field set that represents the object.
+func OperationToSelectableFields(obj *kops.Operation) fields.Set {
+	return generic.ObjectMetaFieldsSet(&obj.ObjectMeta, true)
+}
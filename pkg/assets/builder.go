@@ -258,14 +258,19 @@ func (a *AssetBuilder) findHash(file *FileAsset) (*hashing.Hash, error) {
 		return nil, fmt.Errorf("file url is not defined")
 	}
 
-	for _, ext := range []string{".sha1"} {
+	for _, ext := range []string{".sha1", ".sha256"} {
 		hashURL := u.String() + ext
 		b, err := vfs.Context.ReadFile(hashURL)
 		if err != nil {
 			glog.Infof("error reading hash file %q: %v", hashURL, err)
 			continue
 		}
-		hashString := strings.TrimSpace(string(b))
+		// Hash files written by sha256sum also name the file, after the hash
+		fields := strings.Fields(string(b))
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("hash file %q was empty", hashURL)
+		}
+		hashString := fields[0]
 		glog.V(2).Infof("Found hash %q for %q", hashString, u)
 
 		return hashing.FromString(hashString)
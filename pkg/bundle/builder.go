@@ -32,6 +32,7 @@ import (
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup"
 	"k8s.io/kops/upup/pkg/fi/utils"
+	"k8s.io/kops/util/pkg/architectures"
 	"k8s.io/kops/util/pkg/vfs"
 )
 
@@ -170,12 +171,13 @@ func (b *Builder) Build(cluster *kops.Cluster, ig *kops.InstanceGroup) (*Data, e
 
 		bootstrapScript := model.BootstrapScript{}
 
-		nodeupLocation, nodeupHash, err := cloudup.NodeUpLocation(assetBuilder)
+		arch := model.InstanceGroupArchitecture(&cluster.Spec, ig)
+		nodeupLocation, nodeupHash, err := cloudup.NodeUpLocation(assetBuilder, arch)
 		if err != nil {
 			return nil, err
 		}
-		bootstrapScript.NodeUpSource = nodeupLocation.String()
-		bootstrapScript.NodeUpSourceHash = nodeupHash.Hex()
+		bootstrapScript.NodeUpSource = map[architectures.Architecture]string{arch: nodeupLocation.String()}
+		bootstrapScript.NodeUpSourceHash = map[architectures.Architecture]string{arch: nodeupHash.Hex()}
 		bootstrapScript.NodeUpConfigBuilder = func(ig *kops.InstanceGroup) (*nodeup.Config, error) {
 			return nodeupConfig, err
 		}
@@ -0,0 +1,328 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crdclientset
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/validation"
+	kopsinternalversion "k8s.io/kops/pkg/client/clientset_generated/clientset/typed/kops/internalversion"
+	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/pkg/client/simple/vfsclientset"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/secrets"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// CRDClientset is a simple.Clientset that stores kops objects as custom resources in a (management) kubernetes cluster.
+// Each cluster is stored in its own namespace, so access can be granted per-cluster with RBAC.
+type CRDClientset struct {
+	// KopsClient is the client for the kops CRDs
+	KopsClient kopsinternalversion.KopsInterface
+
+	// ConfigBase is the VFS path under which the configuration for nodes is written, when a cluster does not set spec.configBase
+	ConfigBase vfs.Path
+
+	// KubernetesClient is used to create the namespace for a cluster; if nil the namespace must already exist
+	KubernetesClient kubernetes.Interface
+}
+
+var _ simple.Clientset = &CRDClientset{}
+
+// NewKopsClient builds a client for the kops CRDs from the rest config of the management cluster
+func NewKopsClient(config *rest.Config) (kopsinternalversion.KopsInterface, error) {
+	config = rest.CopyConfig(config)
+	config.APIPath = "/apis"
+	config.GroupVersion = &CRDGroupVersion
+	config.ContentType = "application/json"
+	config.NegotiatedSerializer = crdSerializer{}
+	if config.UserAgent == "" {
+		config.UserAgent = rest.DefaultKubernetesUserAgent()
+	}
+
+	restClient, err := rest.RESTClientFor(config)
+	if err != nil {
+		return nil, fmt.Errorf("error building kops CRD client: %v", err)
+	}
+	return kopsinternalversion.New(restClient), nil
+}
+
+// NewCRDClientsetForURL builds a CRDClientset from a state store url of the form k8s://?context=<context>&configBase=<vfs path>.
+// The management cluster is found using the kubeconfig; the kubeconfig option can be used to specify an alternate kubeconfig file.
+func NewCRDClientsetForURL(u *url.URL) (*CRDClientset, error) {
+	if u.Host != "" {
+		return nil, fmt.Errorf("kubernetes state store %q should not specify a host; use the context option to select a kubeconfig context", u)
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	overrides := &clientcmd.ConfigOverrides{}
+	var configBase vfs.Path
+	for k, values := range u.Query() {
+		v := values[len(values)-1]
+		switch k {
+		case "context":
+			overrides.CurrentContext = v
+		case "kubeconfig":
+			loadingRules.ExplicitPath = v
+		case "configBase":
+			p, err := vfs.Context.BuildVfsPath(v)
+			if err != nil {
+				return nil, fmt.Errorf("error building path for configBase %q: %v", v, err)
+			}
+			configBase = p
+		default:
+			return nil, fmt.Errorf("unknown option %q in kubernetes state store %q", k, u)
+		}
+	}
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("cannot load kubecfg settings for management cluster: %v", err)
+	}
+	glog.V(2).Infof("Using kops CRDs on management cluster %s", config.Host)
+
+	kopsClient, err := NewKopsClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	k8sClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot build kubernetes api client for management cluster: %v", err)
+	}
+
+	return &CRDClientset{
+		KopsClient:       kopsClient,
+		ConfigBase:       configBase,
+		KubernetesClient: k8sClient,
+	}, nil
+}
+
+// GetCluster implements the GetCluster method of simple.Clientset for a CRD-backed state store
+func (c *CRDClientset) GetCluster(name string) (*kops.Cluster, error) {
+	namespace := NamespaceForClusterName(name)
+	return c.KopsClient.Clusters(namespace).Get(name, metav1.GetOptions{})
+}
+
+// CreateCluster implements the CreateCluster method of simple.Clientset for a CRD-backed state store
+func (c *CRDClientset) CreateCluster(cluster *kops.Cluster) (*kops.Cluster, error) {
+	if err := validation.ValidateCluster(cluster, false); err != nil {
+		return nil, err
+	}
+
+	namespace := NamespaceForClusterName(cluster.Name)
+	if err := c.ensureNamespace(namespace, cluster.Name); err != nil {
+		return nil, err
+	}
+	return c.KopsClient.Clusters(namespace).Create(cluster)
+}
+
+// ensureNamespace creates the namespace for the cluster, if it does not already exist
+func (c *CRDClientset) ensureNamespace(namespace string, clusterName string) error {
+	if c.KubernetesClient == nil {
+		return nil
+	}
+
+	_, err := c.KubernetesClient.CoreV1().Namespaces().Get(namespace, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		if errors.IsForbidden(err) {
+			// The user may be allowed to manage clusters in a pre-created namespace, without being allowed to read namespaces
+			glog.V(2).Infof("not permitted to read namespace %q; assuming it exists", namespace)
+			return nil
+		}
+		return fmt.Errorf("error reading namespace %q: %v", namespace, err)
+	}
+
+	ns := &v1.Namespace{}
+	ns.Name = namespace
+	ns.Labels = map[string]string{kops.LabelClusterName: clusterName}
+	glog.Infof("Creating namespace %q for cluster %q", namespace, clusterName)
+	if _, err := c.KubernetesClient.CoreV1().Namespaces().Create(ns); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("error creating namespace %q: %v", namespace, err)
+	}
+	return nil
+}
+
+// UpdateCluster implements the UpdateCluster method of simple.Clientset for a CRD-backed state store.
+// If the cluster has a resourceVersion, the update is rejected with a Conflict error if the cluster has been changed since it was read;
+// otherwise (e.g. kops replace) the cluster is unconditionally replaced.
+func (c *CRDClientset) UpdateCluster(cluster *kops.Cluster, status *kops.ClusterStatus) (*kops.Cluster, error) {
+	old, err := c.GetCluster(cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := validation.ValidateClusterUpdate(cluster, status, old).ToAggregate(); err != nil {
+		return nil, err
+	}
+	if cluster.ResourceVersion == "" {
+		cluster.ResourceVersion = old.ResourceVersion
+	}
+
+	namespace := NamespaceForClusterName(cluster.Name)
+	return c.KopsClient.Clusters(namespace).Update(cluster)
+}
+
+// ConfigBaseFor implements the ConfigBaseFor method of simple.Clientset for a CRD-backed state store.
+// Nodes can't read the CRDs, so the configuration for nodes must still be written to a VFS path.
+func (c *CRDClientset) ConfigBaseFor(cluster *kops.Cluster) (vfs.Path, error) {
+	if cluster.Spec.ConfigBase != "" {
+		return vfs.Context.BuildVfsPath(cluster.Spec.ConfigBase)
+	}
+	if c.ConfigBase == nil {
+		return nil, fmt.Errorf("cluster %q does not set spec.configBase, and the kubernetes state store does not set the configBase option", cluster.Name)
+	}
+	return c.ConfigBase.Join(cluster.Name), nil
+}
+
+// ListClusters implements the ListClusters method of simple.Clientset for a CRD-backed state store
+func (c *CRDClientset) ListClusters(options metav1.ListOptions) (*kops.ClusterList, error) {
+	return c.KopsClient.Clusters(metav1.NamespaceAll).List(options)
+}
+
+// InstanceGroupsFor implements the InstanceGroupsFor method of simple.Clientset for a CRD-backed state store
+func (c *CRDClientset) InstanceGroupsFor(cluster *kops.Cluster) kopsinternalversion.InstanceGroupInterface {
+	namespace := NamespaceForClusterName(cluster.Name)
+	return &instanceGroups{c.KopsClient.InstanceGroups(namespace)}
+}
+
+// instanceGroups wraps the generated InstanceGroupInterface, to allow unconditional updates
+type instanceGroups struct {
+	kopsinternalversion.InstanceGroupInterface
+}
+
+// Update replaces the instance group.  If the instance group has a resourceVersion the update
+// is rejected with a Conflict error if it has been changed since it was read; otherwise it is unconditionally replaced.
+func (c *instanceGroups) Update(ig *kops.InstanceGroup) (*kops.InstanceGroup, error) {
+	if ig.ResourceVersion == "" {
+		old, err := c.Get(ig.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		ig.ResourceVersion = old.ResourceVersion
+	}
+	return c.InstanceGroupInterface.Update(ig)
+}
+
+// SecretStore implements the SecretStore method of simple.Clientset for a CRD-backed state store
+func (c *CRDClientset) SecretStore(cluster *kops.Cluster) (fi.SecretStore, error) {
+	namespace := NamespaceForClusterName(cluster.Name)
+	return secrets.NewClientsetSecretStore(cluster, c.KopsClient, namespace), nil
+}
+
+// KeyStore implements the KeyStore method of simple.Clientset for a CRD-backed state store
+func (c *CRDClientset) KeyStore(cluster *kops.Cluster) (fi.CAStore, error) {
+	namespace := NamespaceForClusterName(cluster.Name)
+	return fi.NewClientsetCAStore(cluster, c.KopsClient, namespace), nil
+}
+
+// SSHCredentialStore implements the SSHCredentialStore method of simple.Clientset for a CRD-backed state store
+func (c *CRDClientset) SSHCredentialStore(cluster *kops.Cluster) (fi.SSHCredentialStore, error) {
+	namespace := NamespaceForClusterName(cluster.Name)
+	return fi.NewClientsetSSHCredentialStore(cluster, c.KopsClient, namespace), nil
+}
+
+// DeleteCluster implements the DeleteCluster method of simple.Clientset for a CRD-backed state store
+func (c *CRDClientset) DeleteCluster(cluster *kops.Cluster) error {
+	configBase, err := c.ConfigBaseFor(cluster)
+	if err != nil {
+		return err
+	}
+
+	err = vfsclientset.DeleteAllClusterState(configBase)
+	if err != nil {
+		return err
+	}
+
+	name := cluster.Name
+	namespace := NamespaceForClusterName(name)
+
+	{
+		keysets, err := c.KopsClient.Keysets(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("error listing Keysets: %v", err)
+		}
+
+		for i := range keysets.Items {
+			keyset := &keysets.Items[i]
+			err = c.KopsClient.Keysets(namespace).Delete(keyset.Name, &metav1.DeleteOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("error deleting Keyset %q: %v", keyset.Name, err)
+			}
+		}
+	}
+
+	{
+		sshCredentials, err := c.KopsClient.SSHCredentials(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("error listing SSHCredentials: %v", err)
+		}
+
+		for i := range sshCredentials.Items {
+			sshCredential := &sshCredentials.Items[i]
+			err = c.KopsClient.SSHCredentials(namespace).Delete(sshCredential.Name, &metav1.DeleteOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("error deleting SSHCredential %q: %v", sshCredential.Name, err)
+			}
+		}
+	}
+
+	{
+		igs, err := c.KopsClient.InstanceGroups(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("error listing instance groups: %v", err)
+		}
+
+		for i := range igs.Items {
+			ig := &igs.Items[i]
+			err = c.KopsClient.InstanceGroups(namespace).Delete(ig.Name, &metav1.DeleteOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("error deleting instance group %q: %v", ig.Name, err)
+			}
+		}
+	}
+
+	err = c.KopsClient.Clusters(namespace).Delete(name, &metav1.DeleteOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			glog.Warningf("cluster %q was concurrently deleted", name)
+		} else {
+			return fmt.Errorf("error deleting cluster %q: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+// NamespaceForClusterName returns the namespace in which the objects for a cluster are stored
+func NamespaceForClusterName(clusterName string) string {
+	// Namespaces can't contain dots, so we map them to dashes, as the kops-server does.
+	// This can conflict, i.e. it will not be possible to manage both a.b.example.com and a-b.example.com
+	return strings.Replace(clusterName, ".", "-", -1)
+}
@@ -0,0 +1,325 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crdclientset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// fakeCRDServer is a minimal implementation of the CRD REST API for the kops resources in a single namespace,
+// enforcing the group on the wire and resourceVersion concurrency
+type fakeCRDServer struct {
+	t       *testing.T
+	mutex   sync.Mutex
+	version int
+	// objects holds the objects by resource and name
+	objects map[string]map[string]map[string]interface{}
+}
+
+// fakeCRDListKinds maps the kops resources to the kinds of their lists
+var fakeCRDListKinds = map[string]string{
+	"clusters":       "ClusterList",
+	"instancegroups": "InstanceGroupList",
+	"keysets":        "KeysetList",
+	"sshcredentials": "SSHCredentialList",
+}
+
+func (s *fakeCRDServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if strings.HasPrefix(r.URL.Path, "/apis/kops.k8s.io/v1alpha2/namespaces/missing-example-com/") {
+		s.writeStatus(w, errors.NewNotFound(kops.Resource("clusters"), "missing.example.com"))
+		return
+	}
+
+	prefix := "/apis/kops.k8s.io/v1alpha2/namespaces/example-com/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		s.t.Errorf("unexpected request path %q", r.URL.Path)
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	tokens := strings.SplitN(strings.TrimPrefix(r.URL.Path, prefix), "/", 2)
+	resource := tokens[0]
+	name := ""
+	if len(tokens) == 2 {
+		name = tokens[1]
+	}
+	objects := s.objects[resource]
+	if objects == nil {
+		objects = make(map[string]map[string]interface{})
+		s.objects[resource] = objects
+	}
+
+	var body map[string]interface{}
+	if r.Method == "POST" || r.Method == "PUT" {
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			s.t.Fatalf("error reading body: %v", err)
+		}
+		if err := json.Unmarshal(data, &body); err != nil {
+			s.t.Fatalf("error parsing body: %v", err)
+		}
+		if body["apiVersion"] != "kops.k8s.io/v1alpha2" {
+			s.writeStatus(w, errors.NewBadRequest(fmt.Sprintf("unexpected apiVersion %v", body["apiVersion"])))
+			return
+		}
+	}
+
+	switch {
+	case r.Method == "POST" && name == "":
+		metadata := body["metadata"].(map[string]interface{})
+		name = metadata["name"].(string)
+		if objects[name] != nil {
+			s.writeStatus(w, errors.NewAlreadyExists(kops.Resource(resource), name))
+			return
+		}
+		s.store(objects, name, body)
+		s.writeJSON(w, body)
+
+	case r.Method == "GET" && name == "" && r.URL.Query().Get("watch") == "true":
+		for _, obj := range objects {
+			data, _ := json.Marshal(map[string]interface{}{"type": "ADDED", "object": obj})
+			w.Write(append(data, '\n'))
+		}
+
+	case r.Method == "GET" && name == "":
+		items := []interface{}{}
+		for _, obj := range objects {
+			items = append(items, obj)
+		}
+		s.writeJSON(w, map[string]interface{}{"apiVersion": "kops.k8s.io/v1alpha2", "kind": fakeCRDListKinds[resource], "items": items})
+
+	case r.Method == "GET" && name != "":
+		obj := objects[name]
+		if obj == nil {
+			s.writeStatus(w, errors.NewNotFound(kops.Resource(resource), name))
+			return
+		}
+		s.writeJSON(w, obj)
+
+	case r.Method == "PUT" && name != "":
+		obj := objects[name]
+		if obj == nil {
+			s.writeStatus(w, errors.NewNotFound(kops.Resource(resource), name))
+			return
+		}
+		resourceVersion := body["metadata"].(map[string]interface{})["resourceVersion"]
+		if resourceVersion != obj["metadata"].(map[string]interface{})["resourceVersion"] {
+			s.writeStatus(w, errors.NewConflict(kops.Resource(resource), name, fmt.Errorf("the object has been modified")))
+			return
+		}
+		s.store(objects, name, body)
+		s.writeJSON(w, body)
+
+	case r.Method == "DELETE" && name != "":
+		obj := objects[name]
+		if obj == nil {
+			s.writeStatus(w, errors.NewNotFound(kops.Resource(resource), name))
+			return
+		}
+		delete(objects, name)
+		s.writeJSON(w, map[string]interface{}{"apiVersion": "v1", "kind": "Status", "status": "Success"})
+
+	default:
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		http.Error(w, "not supported", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *fakeCRDServer) store(objects map[string]map[string]interface{}, name string, obj map[string]interface{}) {
+	s.version++
+	obj["metadata"].(map[string]interface{})["resourceVersion"] = strconv.Itoa(s.version)
+	objects[name] = obj
+}
+
+func (s *fakeCRDServer) writeJSON(w http.ResponseWriter, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(obj)
+}
+
+func (s *fakeCRDServer) writeStatus(w http.ResponseWriter, err *errors.StatusError) {
+	status := err.Status()
+	status.APIVersion = "v1"
+	status.Kind = "Status"
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(int(status.Code))
+	json.NewEncoder(w).Encode(status)
+}
+
+func TestCRDClientset(t *testing.T) {
+	server := httptest.NewServer(&fakeCRDServer{t: t, objects: make(map[string]map[string]map[string]interface{})})
+	defer server.Close()
+
+	kopsClient, err := NewKopsClient(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("error building client: %v", err)
+	}
+	clientset := &CRDClientset{KopsClient: kopsClient}
+
+	cluster := &kops.Cluster{}
+	cluster.Name = "example.com"
+	cluster.Spec.KubernetesVersion = "1.9.3"
+	clusters := clientset.KopsClient.Clusters(NamespaceForClusterName(cluster.Name))
+	if _, err := clusters.Create(cluster); err != nil {
+		t.Fatalf("error creating cluster: %v", err)
+	}
+
+	if _, err := clusters.Create(cluster); !errors.IsAlreadyExists(err) {
+		t.Fatalf("expected AlreadyExists creating cluster twice, got %v", err)
+	}
+
+	read, err := clientset.GetCluster("example.com")
+	if err != nil {
+		t.Fatalf("error reading cluster: %v", err)
+	}
+	if read.Spec.KubernetesVersion != "1.9.3" {
+		t.Fatalf("unexpected kubernetesVersion %q", read.Spec.KubernetesVersion)
+	}
+	if read.ResourceVersion == "" {
+		t.Fatalf("resourceVersion was not set")
+	}
+
+	// An update from the current version succeeds
+	stale := read.DeepCopy()
+	read.Spec.KubernetesVersion = "1.9.4"
+	if _, err := clusters.Update(read); err != nil {
+		t.Fatalf("error updating cluster: %v", err)
+	}
+
+	// An update from a stale version is rejected
+	stale.Spec.KubernetesVersion = "1.9.5"
+	if _, err := clusters.Update(stale); !errors.IsConflict(err) {
+		t.Fatalf("expected Conflict updating stale cluster, got %v", err)
+	}
+
+	if _, err := clientset.GetCluster("missing.example.com"); !errors.IsNotFound(err) {
+		t.Fatalf("expected NotFound reading missing cluster, got %v", err)
+	}
+
+	w, err := clusters.Watch(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("error watching clusters: %v", err)
+	}
+	defer w.Stop()
+	event := <-w.ResultChan()
+	if event.Type != watch.Added {
+		t.Fatalf("unexpected watch event %v: %v", event.Type, event.Object)
+	}
+	watched, ok := event.Object.(*kops.Cluster)
+	if !ok {
+		t.Fatalf("unexpected watch object type %T", event.Object)
+	}
+	if watched.Spec.KubernetesVersion != "1.9.4" {
+		t.Fatalf("unexpected watched kubernetesVersion %q", watched.Spec.KubernetesVersion)
+	}
+}
+
+func TestDeleteCluster(t *testing.T) {
+	server := httptest.NewServer(&fakeCRDServer{t: t, objects: make(map[string]map[string]map[string]interface{})})
+	defer server.Close()
+
+	kopsClient, err := NewKopsClient(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("error building client: %v", err)
+	}
+	configBase := vfs.NewMemFSPath(vfs.NewMemFSContext(), "nodes")
+	clientset := &CRDClientset{KopsClient: kopsClient, ConfigBase: configBase}
+
+	// The cluster does not set spec.configBase, so the node configuration is under the configBase of the clientset
+	cluster := &kops.Cluster{}
+	cluster.Name = "example.com"
+	namespace := NamespaceForClusterName(cluster.Name)
+	if _, err := clientset.KopsClient.Clusters(namespace).Create(cluster); err != nil {
+		t.Fatalf("error creating cluster: %v", err)
+	}
+	ig := &kops.InstanceGroup{}
+	ig.Name = "nodes"
+	if _, err := clientset.KopsClient.InstanceGroups(namespace).Create(ig); err != nil {
+		t.Fatalf("error creating instance group: %v", err)
+	}
+
+	nodeConfig := configBase.Join("example.com", "instancegroup", "nodes")
+	if err := nodeConfig.WriteFile(bytes.NewReader([]byte("spec: {}")), nil); err != nil {
+		t.Fatalf("error writing node configuration: %v", err)
+	}
+
+	if err := clientset.DeleteCluster(cluster); err != nil {
+		t.Fatalf("error deleting cluster: %v", err)
+	}
+
+	if _, err := nodeConfig.ReadFile(); !os.IsNotExist(err) {
+		t.Fatalf("expected node configuration to be deleted, got %v", err)
+	}
+	if _, err := clientset.GetCluster("example.com"); !errors.IsNotFound(err) {
+		t.Fatalf("expected NotFound reading deleted cluster, got %v", err)
+	}
+	igs, err := clientset.KopsClient.InstanceGroups(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("error listing instance groups: %v", err)
+	}
+	if len(igs.Items) != 0 {
+		t.Fatalf("expected instance groups to be deleted, got %v", igs.Items)
+	}
+}
+
+func TestRewriteAPIVersion(t *testing.T) {
+	grid := []struct {
+		Input    string
+		Expected string
+	}{
+		{
+			Input:    `{"apiVersion":"kops/v1alpha2","kind":"Cluster"}`,
+			Expected: `{"apiVersion":"kops.k8s.io/v1alpha2","kind":"Cluster"}`,
+		},
+		{
+			Input:    `{"apiVersion":"v1","kind":"Status"}`,
+			Expected: `{"apiVersion":"v1","kind":"Status"}`,
+		},
+		{
+			Input:    `{"kind":"Cluster"}`,
+			Expected: `{"kind":"Cluster"}`,
+		},
+	}
+	for _, g := range grid {
+		actual, err := rewriteAPIVersion([]byte(g.Input), kops.GroupName, CRDGroupName)
+		if err != nil {
+			t.Errorf("error rewriting %s: %v", g.Input, err)
+			continue
+		}
+		if string(actual) != g.Expected {
+			t.Errorf("unexpected result rewriting %s: expected %s, got %s", g.Input, g.Expected, string(actual))
+		}
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crdclientset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/v1alpha2"
+	"k8s.io/kops/pkg/client/clientset_generated/clientset/scheme"
+)
+
+// CRDGroupName is the API group under which the kops CRDs are registered.
+// CRD groups must be a domain, so we can't use the kops API group name directly;
+// objects are converted through the kops/v1alpha2 scheme and only the group is rewritten on the wire.
+const CRDGroupName = "kops.k8s.io"
+
+// CRDGroupVersion is the group version of the kops CRDs
+var CRDGroupVersion = schema.GroupVersion{Group: CRDGroupName, Version: v1alpha2.SchemeGroupVersion.Version}
+
+func init() {
+	// The generated clients encode query parameters (ListOptions, GetOptions etc) for the group version of the rest client
+	metav1.AddToGroupVersion(scheme.Scheme, CRDGroupVersion)
+}
+
+// internalGroupVersions are the versions we decode to; the legacy group is included to decode Status objects
+var internalGroupVersions = schema.GroupVersions{
+	{Group: kops.GroupName, Version: runtime.APIVersionInternal},
+	{Group: "", Version: runtime.APIVersionInternal},
+}
+
+// crdSerializer is a runtime.NegotiatedSerializer that speaks the CRD group on the wire,
+// but converts through the kops scheme, so objects are stored as v1alpha2.
+type crdSerializer struct{}
+
+var _ runtime.NegotiatedSerializer = crdSerializer{}
+
+// SupportedMediaTypes implements runtime.NegotiatedSerializer; CRDs only support JSON
+func (crdSerializer) SupportedMediaTypes() []runtime.SerializerInfo {
+	for _, info := range scheme.Codecs.SupportedMediaTypes() {
+		if info.MediaType == runtime.ContentTypeJSON {
+			return []runtime.SerializerInfo{info}
+		}
+	}
+	return nil
+}
+
+// EncoderForVersion implements runtime.NegotiatedSerializer, always encoding as v1alpha2
+func (crdSerializer) EncoderForVersion(encoder runtime.Encoder, gv runtime.GroupVersioner) runtime.Encoder {
+	return &crdEncoder{
+		encoder: scheme.Codecs.EncoderForVersion(encoder, v1alpha2.SchemeGroupVersion),
+	}
+}
+
+// DecoderToVersion implements runtime.NegotiatedSerializer, always decoding to the internal version
+func (crdSerializer) DecoderToVersion(decoder runtime.Decoder, gv runtime.GroupVersioner) runtime.Decoder {
+	return &crdDecoder{
+		decoder: scheme.Codecs.DecoderToVersion(decoder, internalGroupVersions),
+	}
+}
+
+// crdEncoder encodes objects with the kops scheme, and then rewrites the group to the CRD group
+type crdEncoder struct {
+	encoder runtime.Encoder
+}
+
+// Encode implements runtime.Encoder
+func (e *crdEncoder) Encode(obj runtime.Object, w io.Writer) error {
+	var b bytes.Buffer
+	if err := e.encoder.Encode(obj, &b); err != nil {
+		return err
+	}
+	data, err := rewriteAPIVersion(b.Bytes(), kops.GroupName, CRDGroupName)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// crdDecoder rewrites the CRD group to the kops group, and then decodes with the kops scheme
+type crdDecoder struct {
+	decoder runtime.Decoder
+}
+
+// Decode implements runtime.Decoder
+func (d *crdDecoder) Decode(data []byte, defaults *schema.GroupVersionKind, into runtime.Object) (runtime.Object, *schema.GroupVersionKind, error) {
+	data, err := rewriteAPIVersion(data, CRDGroupName, kops.GroupName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d.decoder.Decode(data, defaults, into)
+}
+
+// rewriteAPIVersion replaces the group in the top-level apiVersion field of a JSON object, if it matches fromGroup
+func rewriteAPIVersion(data []byte, fromGroup string, toGroup string) ([]byte, error) {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		// Not a JSON object; let the underlying codec report the problem
+		return data, nil
+	}
+
+	raw, found := fields["apiVersion"]
+	if !found {
+		return data, nil
+	}
+	var apiVersion string
+	if err := json.Unmarshal(raw, &apiVersion); err != nil {
+		return nil, fmt.Errorf("error parsing apiVersion %s: %v", string(raw), err)
+	}
+	if !strings.HasPrefix(apiVersion, fromGroup+"/") {
+		return data, nil
+	}
+
+	raw, err := json.Marshal(toGroup + "/" + strings.TrimPrefix(apiVersion, fromGroup+"/"))
+	if err != nil {
+		return nil, err
+	}
+	fields["apiVersion"] = raw
+	return json.Marshal(fields)
+}
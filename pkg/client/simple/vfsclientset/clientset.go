@@ -38,7 +38,7 @@ type VFSClientset struct {
 var _ simple.Clientset = &VFSClientset{}
 
 func (c *VFSClientset) clusters() *ClusterVFS {
-	return newClusterVFS(c.basePath)
+	return newClusterVFS(vfs.Context.Cached(c.basePath))
 }
 
 // GetCluster implements the GetCluster method of simple.Clientset for a VFS-backed state store
@@ -79,7 +79,7 @@ func (c *VFSClientset) SecretStore(cluster *kops.Cluster) (fi.SecretStore, error
 	if err != nil {
 		return nil, err
 	}
-	basedir := configBase.Join("secrets")
+	basedir := vfs.Context.Cached(configBase.Join("secrets"))
 	return secrets.NewVFSSecretStore(cluster, basedir), nil
 }
 
@@ -88,7 +88,7 @@ func (c *VFSClientset) KeyStore(cluster *kops.Cluster) (fi.CAStore, error) {
 	if err != nil {
 		return nil, err
 	}
-	basedir := configBase.Join("pki")
+	basedir := vfs.Context.Cached(configBase.Join("pki"))
 	return fi.NewVFSCAStore(cluster, basedir, c.allowList), nil
 }
 
@@ -97,7 +97,7 @@ func (c *VFSClientset) SSHCredentialStore(cluster *kops.Cluster) (fi.SSHCredenti
 	if err != nil {
 		return nil, err
 	}
-	basedir := configBase.Join("pki")
+	basedir := vfs.Context.Cached(configBase.Join("pki"))
 	return fi.NewVFSSSHCredentialStore(cluster, basedir), nil
 }
 
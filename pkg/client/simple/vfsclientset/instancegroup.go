@@ -80,7 +80,7 @@ func newInstanceGroupVFS(c *VFSClientset, cluster *kops.Cluster) *InstanceGroupV
 		cluster:     cluster,
 		clusterName: clusterName,
 	}
-	r.init(kind, c.basePath.Join(clusterName, "instancegroup"), StoreVersion)
+	r.init(kind, vfs.Context.Cached(c.basePath.Join(clusterName, "instancegroup")), StoreVersion)
 	defaultReadVersion := v1alpha1.SchemeGroupVersion.WithKind(kind)
 	r.defaultReadVersion = &defaultReadVersion
 	r.validate = func(o runtime.Object) error {
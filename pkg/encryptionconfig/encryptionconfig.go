@@ -0,0 +1,200 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryptionconfig
+
+import (
+	crypto_rand "crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// EncryptionConfig is the kube-apiserver encryption-at-rest configuration
+// See https://kubernetes.io/docs/tasks/administer-cluster/encrypt-data/
+type EncryptionConfig struct {
+	Kind       string           `json:"kind"`
+	APIVersion string           `json:"apiVersion"`
+	Resources  []ResourceConfig `json:"resources"`
+}
+
+// ResourceConfig configures the providers used for a set of resources
+type ResourceConfig struct {
+	Resources []string         `json:"resources"`
+	Providers []ProviderConfig `json:"providers"`
+}
+
+// ProviderConfig is a single encryption provider; only one field should be set
+type ProviderConfig struct {
+	AESGCM    *KeysConfig            `json:"aesgcm,omitempty"`
+	AESCBC    *KeysConfig            `json:"aescbc,omitempty"`
+	Secretbox *KeysConfig            `json:"secretbox,omitempty"`
+	Identity  *IdentityConfig        `json:"identity,omitempty"`
+	KMS       map[string]interface{} `json:"kms,omitempty"`
+}
+
+// KeysConfig is the list of keys for a key-based provider; the first key is used for encryption
+type KeysConfig struct {
+	Keys []Key `json:"keys"`
+}
+
+// Key is a named encryption key
+type Key struct {
+	Name   string `json:"name"`
+	Secret string `json:"secret"`
+}
+
+// IdentityConfig is the (empty) configuration of the identity provider
+type IdentityConfig struct{}
+
+// Parse parses an encryption config from yaml
+func Parse(data []byte) (*EncryptionConfig, error) {
+	c := &EncryptionConfig{}
+	if err := kops.ParseRawYaml(data, c); err != nil {
+		return nil, fmt.Errorf("error parsing encryption config: %v", err)
+	}
+	if len(c.Resources) == 0 {
+		return nil, fmt.Errorf("encryption config does not configure any resources")
+	}
+	return c, nil
+}
+
+// ToYAML serializes the encryption config to yaml
+func (c *EncryptionConfig) ToYAML() ([]byte, error) {
+	return kops.ToRawYaml(c)
+}
+
+// EncryptedResources returns the resources that are encrypted by a key-based provider
+func (c *EncryptionConfig) EncryptedResources() []string {
+	var resources []string
+	for i := range c.Resources {
+		for _, p := range c.Resources[i].Providers {
+			if p.AESGCM != nil || p.AESCBC != nil || p.Secretbox != nil {
+				resources = append(resources, c.Resources[i].Resources...)
+				break
+			}
+		}
+	}
+	return resources
+}
+
+// keys returns the key-based provider configurations, in order
+func (c *EncryptionConfig) keys() []*KeysConfig {
+	var keys []*KeysConfig
+	for i := range c.Resources {
+		for j := range c.Resources[i].Providers {
+			p := &c.Resources[i].Providers[j]
+			for _, k := range []*KeysConfig{p.AESGCM, p.AESCBC, p.Secretbox} {
+				if k != nil {
+					keys = append(keys, k)
+				}
+			}
+		}
+	}
+	return keys
+}
+
+// GenerateKeySecret generates a random 32 byte key, valid for the aescbc, aesgcm and secretbox providers
+func GenerateKeySecret() (string, error) {
+	data := make([]byte, 32)
+	if _, err := crypto_rand.Read(data); err != nil {
+		return "", fmt.Errorf("error reading crypto_rand: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// AddKey appends the key to every key-based provider.  The key is added last,
+// so it can be used for decryption but is not yet used for encryption.
+func (c *EncryptionConfig) AddKey(key Key) error {
+	providers := c.keys()
+	if len(providers) == 0 {
+		return fmt.Errorf("encryption config has no aescbc, aesgcm or secretbox provider with keys to rotate")
+	}
+	for _, p := range providers {
+		for _, k := range p.Keys {
+			if k.Name == key.Name {
+				return fmt.Errorf("encryption config already has a key named %q", key.Name)
+			}
+		}
+		p.Keys = append(p.Keys, key)
+	}
+	return nil
+}
+
+// PromoteKey moves the named key to be the first key of every key-based provider, so it is used for encryption
+func (c *EncryptionConfig) PromoteKey(name string) error {
+	providers := c.keys()
+	if len(providers) == 0 {
+		return fmt.Errorf("encryption config has no aescbc, aesgcm or secretbox provider with keys to rotate")
+	}
+	for _, p := range providers {
+		found := -1
+		for i, k := range p.Keys {
+			if k.Name == name {
+				found = i
+			}
+		}
+		if found == -1 {
+			return fmt.Errorf("encryption config key %q not found", name)
+		}
+		key := p.Keys[found]
+		keys := []Key{key}
+		keys = append(keys, p.Keys[:found]...)
+		keys = append(keys, p.Keys[found+1:]...)
+		p.Keys = keys
+	}
+	return nil
+}
+
+// RemoveKeysExcept removes all keys other than the named key, returning the number of keys removed
+func (c *EncryptionConfig) RemoveKeysExcept(name string) (int, error) {
+	removed := 0
+	for _, p := range c.keys() {
+		var keys []Key
+		for _, k := range p.Keys {
+			if k.Name == name {
+				keys = append(keys, k)
+			} else {
+				removed++
+			}
+		}
+		if len(keys) == 0 {
+			return 0, fmt.Errorf("encryption config key %q not found", name)
+		}
+		p.Keys = keys
+	}
+	return removed, nil
+}
+
+// LastKeyName returns the name of the most recently added key of the first key-based provider
+func (c *EncryptionConfig) LastKeyName() (string, error) {
+	providers := c.keys()
+	if len(providers) == 0 || len(providers[0].Keys) == 0 {
+		return "", fmt.Errorf("encryption config has no aescbc, aesgcm or secretbox provider with keys to rotate")
+	}
+	keys := providers[0].Keys
+	return keys[len(keys)-1].Name, nil
+}
+
+// PrimaryKeyName returns the name of the key used for encryption by the first key-based provider
+func (c *EncryptionConfig) PrimaryKeyName() (string, error) {
+	providers := c.keys()
+	if len(providers) == 0 || len(providers[0].Keys) == 0 {
+		return "", fmt.Errorf("encryption config has no aescbc, aesgcm or secretbox provider with keys to rotate")
+	}
+	return providers[0].Keys[0].Name, nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryptionconfig
+
+import (
+	"fmt"
+	"time"
+)
+
+// Key rotation follows the procedure recommended for the apiserver; every step must
+// be rolled out to all masters (by nodeup) before the next step is started:
+//   1. a new key is added as a secondary key, so that every apiserver can decrypt with it
+//   2. the new key is promoted to be the primary key, so new writes are encrypted with it
+//   3. every resource is rewritten through the apiserver, re-encrypting it with the new key,
+//      and then the old keys are removed
+const (
+	// PhaseNone means that no rotation is in progress
+	PhaseNone = ""
+	// PhaseKeyAdded means a new key has been added as a secondary key
+	PhaseKeyAdded = "KeyAdded"
+	// PhaseKeyPromoted means the new key has been promoted to be the primary key
+	PhaseKeyPromoted = "KeyPromoted"
+)
+
+// RotationStep is the result of a single step of a key rotation
+type RotationStep struct {
+	// Data is the updated encryption config
+	Data []byte
+	// Phase is the rotation phase after the step
+	Phase string
+	// Description describes what the step did
+	Description string
+}
+
+// Rotate performs the next step of the key rotation of the encryption config, given the current phase.
+// reencrypt is called before the old keys are removed, and must rewrite all objects of the encrypted resources.
+func Rotate(data []byte, phase string, now time.Time, reencrypt func(resources []string) error) (*RotationStep, error) {
+	config, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	step := &RotationStep{}
+	switch phase {
+	case PhaseNone:
+		secret, err := GenerateKeySecret()
+		if err != nil {
+			return nil, err
+		}
+		key := Key{
+			Name:   fmt.Sprintf("key%d", now.Unix()),
+			Secret: secret,
+		}
+		if err := config.AddKey(key); err != nil {
+			return nil, err
+		}
+		step.Phase = PhaseKeyAdded
+		step.Description = fmt.Sprintf("added new encryption key %q as a secondary key", key.Name)
+
+	case PhaseKeyAdded:
+		name, err := config.LastKeyName()
+		if err != nil {
+			return nil, err
+		}
+		if err := config.PromoteKey(name); err != nil {
+			return nil, err
+		}
+		step.Phase = PhaseKeyPromoted
+		step.Description = fmt.Sprintf("promoted encryption key %q to be the primary key", name)
+
+	case PhaseKeyPromoted:
+		name, err := config.PrimaryKeyName()
+		if err != nil {
+			return nil, err
+		}
+		if err := reencrypt(config.EncryptedResources()); err != nil {
+			return nil, fmt.Errorf("error re-encrypting resources: %v", err)
+		}
+		removed, err := config.RemoveKeysExcept(name)
+		if err != nil {
+			return nil, err
+		}
+		step.Phase = PhaseNone
+		step.Description = fmt.Sprintf("re-encrypted resources with encryption key %q and removed %d old key(s)", name, removed)
+
+	default:
+		return nil, fmt.Errorf("unknown encryption config rotation phase %q", phase)
+	}
+
+	step.Data, err = config.ToYAML()
+	if err != nil {
+		return nil, err
+	}
+	return step, nil
+}
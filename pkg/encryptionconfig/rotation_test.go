@@ -0,0 +1,135 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryptionconfig
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+const testConfig = `
+kind: EncryptionConfig
+apiVersion: v1
+resources:
+  - resources:
+    - secrets
+    providers:
+    - aescbc:
+        keys:
+        - name: key1
+          secret: c2VjcmV0IGlzIHNlY3VyZQ==
+    - identity: {}
+`
+
+func keyNames(t *testing.T, data []byte) []string {
+	config, err := Parse(data)
+	if err != nil {
+		t.Fatalf("error parsing rotated config: %v", err)
+	}
+	var names []string
+	for _, k := range config.Resources[0].Providers[0].AESCBC.Keys {
+		names = append(names, k.Name)
+	}
+	if config.Resources[0].Providers[1].Identity == nil {
+		t.Fatalf("identity provider was not preserved")
+	}
+	return names
+}
+
+func TestRotate(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	reencrypted := 0
+	reencrypt := func(resources []string) error {
+		if fmt.Sprintf("%v", resources) != "[secrets]" {
+			t.Fatalf("unexpected resources to re-encrypt: %v", resources)
+		}
+		reencrypted++
+		return nil
+	}
+
+	step, err := Rotate([]byte(testConfig), PhaseNone, now, reencrypt)
+	if err != nil {
+		t.Fatalf("error adding key: %v", err)
+	}
+	if step.Phase != PhaseKeyAdded {
+		t.Fatalf("unexpected phase %q", step.Phase)
+	}
+	if names := fmt.Sprintf("%v", keyNames(t, step.Data)); names != "[key1 key1500000000]" {
+		t.Fatalf("unexpected keys after add: %s", names)
+	}
+
+	step, err = Rotate(step.Data, step.Phase, now, reencrypt)
+	if err != nil {
+		t.Fatalf("error promoting key: %v", err)
+	}
+	if step.Phase != PhaseKeyPromoted {
+		t.Fatalf("unexpected phase %q", step.Phase)
+	}
+	if names := fmt.Sprintf("%v", keyNames(t, step.Data)); names != "[key1500000000 key1]" {
+		t.Fatalf("unexpected keys after promote: %s", names)
+	}
+	if reencrypted != 0 {
+		t.Fatalf("re-encrypted before the new key was primary")
+	}
+
+	step, err = Rotate(step.Data, step.Phase, now, reencrypt)
+	if err != nil {
+		t.Fatalf("error removing old key: %v", err)
+	}
+	if step.Phase != PhaseNone {
+		t.Fatalf("unexpected phase %q", step.Phase)
+	}
+	if names := fmt.Sprintf("%v", keyNames(t, step.Data)); names != "[key1500000000]" {
+		t.Fatalf("unexpected keys after remove: %s", names)
+	}
+	if reencrypted != 1 {
+		t.Fatalf("expected one re-encryption, got %d", reencrypted)
+	}
+}
+
+func TestRotate_ReencryptFailureKeepsOldKey(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	data := []byte(testConfig)
+	for _, phase := range []string{PhaseNone, PhaseKeyAdded} {
+		step, err := Rotate(data, phase, now, nil)
+		if err != nil {
+			t.Fatalf("error rotating: %v", err)
+		}
+		data = step.Data
+	}
+
+	_, err := Rotate(data, PhaseKeyPromoted, now, func([]string) error { return fmt.Errorf("apiserver unavailable") })
+	if err == nil {
+		t.Fatalf("expected error when re-encryption fails")
+	}
+}
+
+func TestRotate_NoKeyedProvider(t *testing.T) {
+	config := `
+kind: EncryptionConfig
+apiVersion: v1
+resources:
+  - resources:
+    - secrets
+    providers:
+    - identity: {}
+`
+	if _, err := Rotate([]byte(config), PhaseNone, time.Now(), nil); err == nil {
+		t.Fatalf("expected error rotating config without keys")
+	}
+}
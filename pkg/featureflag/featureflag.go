@@ -66,6 +66,9 @@ var SpecOverrideFlag = New("SpecOverrideFlag", Bool(false))
 // However we should no longer need it, with the keyset.yaml fix
 var GoogleCloudBucketAcl = New("GoogleCloudBucketAcl", Bool(false))
 
+// VFSCache enables a local cache of files read from the state store, validated by their hashes
+var VFSCache = New("VFSCache", Bool(false))
+
 var flags = make(map[string]*FeatureFlag)
 var flagsMutex sync.Mutex
 
@@ -110,6 +110,30 @@ func (b *KubeconfigBuilder) WriteKubecfg() error {
 		config = &clientcmdapi.Config{}
 	}
 
+	b.mergeInto(config)
+
+	if err := clientcmd.ModifyConfig(b.configAccess, *config, true); err != nil {
+		return err
+	}
+
+	fmt.Printf("kops has set your kubectl context to %s\n", b.Context)
+	return nil
+}
+
+// BuildKubeconfig returns a standalone kubeconfig file for the cluster, without reading or writing the local kubeconfig
+func (b *KubeconfigBuilder) BuildKubeconfig() ([]byte, error) {
+	config := clientcmdapi.NewConfig()
+	b.mergeInto(config)
+
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing kubeconfig: %v", err)
+	}
+	return data, nil
+}
+
+// mergeInto adds (or replaces) the cluster, credentials and context for the cluster in config, and selects the context
+func (b *KubeconfigBuilder) mergeInto(config *clientcmdapi.Config) {
 	{
 		cluster := config.Clusters[b.Context]
 		if cluster == nil {
@@ -199,11 +223,4 @@ func (b *KubeconfigBuilder) WriteKubecfg() error {
 	}
 
 	config.CurrentContext = b.Context
-
-	if err := clientcmd.ModifyConfig(b.configAccess, *config, true); err != nil {
-		return err
-	}
-
-	fmt.Printf("kops has set your kubectl context to %s\n", b.Context)
-	return nil
 }
@@ -0,0 +1,65 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"sort"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/util/pkg/architectures"
+)
+
+// InstanceGroupArchitecture returns the machine architecture of the instance group.
+// This is the architecture in the spec if set, otherwise the architecture of the machine type (on AWS), defaulting to amd64.
+func InstanceGroupArchitecture(cluster *kops.ClusterSpec, ig *kops.InstanceGroup) architectures.Architecture {
+	if ig.Spec.Architecture != "" {
+		return architectures.Architecture(ig.Spec.Architecture)
+	}
+
+	if kops.CloudProviderID(cluster.CloudProvider) == kops.CloudProviderAWS && ig.Spec.MachineType != "" {
+		machineInfo, err := awsup.GetMachineTypeInfo(ig.Spec.MachineType)
+		if err != nil {
+			glog.V(2).Infof("cannot determine architecture of machine type %q: %v", ig.Spec.MachineType, err)
+		} else if machineInfo.Architecture != "" {
+			return architectures.Architecture(machineInfo.Architecture)
+		}
+	}
+
+	return architectures.Default
+}
+
+// ClusterArchitectures returns the architectures of the instance groups, in a stable order
+func ClusterArchitectures(cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup) []architectures.Architecture {
+	found := make(map[architectures.Architecture]bool)
+	for _, ig := range instanceGroups {
+		found[InstanceGroupArchitecture(&cluster.Spec, ig)] = true
+	}
+	if len(found) == 0 {
+		found[architectures.Default] = true
+	}
+
+	var archs []architectures.Architecture
+	for a := range found {
+		archs = append(archs, a)
+	}
+	sort.Slice(archs, func(i, j int) bool {
+		return archs[i] < archs[j]
+	})
+	return archs
+}
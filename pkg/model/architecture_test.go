@@ -0,0 +1,66 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/util/pkg/architectures"
+)
+
+func TestInstanceGroupArchitecture(t *testing.T) {
+	grid := []struct {
+		CloudProvider string
+		MachineType   string
+		Architecture  string
+		Expected      architectures.Architecture
+	}{
+		{CloudProvider: "aws", MachineType: "m4.large", Expected: architectures.ArchitectureAmd64},
+		{CloudProvider: "aws", MachineType: "a1.xlarge", Expected: architectures.ArchitectureArm64},
+		{CloudProvider: "aws", MachineType: "a1.xlarge", Architecture: "amd64", Expected: architectures.ArchitectureAmd64},
+		{CloudProvider: "aws", MachineType: "unknown.large", Expected: architectures.ArchitectureAmd64},
+		{CloudProvider: "gce", MachineType: "n1-standard-2", Expected: architectures.ArchitectureAmd64},
+		{CloudProvider: "gce", MachineType: "n1-standard-2", Architecture: "arm64", Expected: architectures.ArchitectureArm64},
+	}
+	for _, g := range grid {
+		cluster := &kops.ClusterSpec{CloudProvider: g.CloudProvider}
+		ig := &kops.InstanceGroup{Spec: kops.InstanceGroupSpec{MachineType: g.MachineType, Architecture: g.Architecture}}
+		if actual := InstanceGroupArchitecture(cluster, ig); actual != g.Expected {
+			t.Errorf("%s %s (architecture %q): expected %q, got %q", g.CloudProvider, g.MachineType, g.Architecture, g.Expected, actual)
+		}
+	}
+}
+
+func TestClusterArchitectures(t *testing.T) {
+	cluster := &kops.Cluster{Spec: kops.ClusterSpec{CloudProvider: "aws"}}
+
+	if actual := ClusterArchitectures(cluster, nil); !reflect.DeepEqual(actual, []architectures.Architecture{architectures.ArchitectureAmd64}) {
+		t.Errorf("expected amd64 for a cluster without instance groups, got %v", actual)
+	}
+
+	igs := []*kops.InstanceGroup{
+		{Spec: kops.InstanceGroupSpec{MachineType: "a1.large"}},
+		{Spec: kops.InstanceGroupSpec{MachineType: "m4.large"}},
+		{Spec: kops.InstanceGroupSpec{MachineType: "a1.xlarge"}},
+	}
+	expected := []architectures.Architecture{architectures.ArchitectureAmd64, architectures.ArchitectureArm64}
+	if actual := ClusterArchitectures(cluster, igs); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
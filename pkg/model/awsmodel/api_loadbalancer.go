@@ -61,84 +61,31 @@ func (b *APILoadBalancerBuilder) Build(c *fi.ModelBuilderContext) error {
 		return fmt.Errorf("unhandled LoadBalancer type %q", lbSpec.Type)
 	}
 
-	// Compute the subnets - only one per zone, and then break ties based on chooseBestSubnetForELB
-	var elbSubnets []*awstasks.Subnet
-	{
-		subnetsByZone := make(map[string][]*kops.ClusterSubnetSpec)
-		for i := range b.Cluster.Spec.Subnets {
-			subnet := &b.Cluster.Spec.Subnets[i]
-
-			switch subnet.Type {
-			case kops.SubnetTypePublic, kops.SubnetTypeUtility:
-				if lbSpec.Type != kops.LoadBalancerTypePublic {
-					continue
-				}
-
-			case kops.SubnetTypePrivate:
-				if lbSpec.Type != kops.LoadBalancerTypeInternal {
-					continue
-				}
-
-			default:
-				return fmt.Errorf("subnet %q had unknown type %q", subnet.Name, subnet.Type)
-			}
-
-			subnetsByZone[subnet.Zone] = append(subnetsByZone[subnet.Zone], subnet)
-		}
-
-		for zone, subnets := range subnetsByZone {
-			subnet := b.chooseBestSubnetForELB(zone, subnets)
-
-			elbSubnets = append(elbSubnets, b.LinkToSubnet(subnet))
-		}
+	elbSubnets, err := b.subnetsForELB(lbSpec.Type)
+	if err != nil {
+		return err
 	}
 
-	var elb *awstasks.LoadBalancer
-	{
-		loadBalancerName := b.GetELBName32("api")
-
-		idleTimeout := LoadBalancerDefaultIdleTimeout
-		if lbSpec.IdleTimeoutSeconds != nil {
-			idleTimeout = time.Second * time.Duration(*lbSpec.IdleTimeoutSeconds)
-		}
-
-		elb = &awstasks.LoadBalancer{
-			Name:      s("api." + b.ClusterName()),
-			Lifecycle: b.Lifecycle,
-
-			LoadBalancerName: s(loadBalancerName),
-			SecurityGroups: []*awstasks.SecurityGroup{
-				b.LinkToELBSecurityGroup("api"),
-			},
-			Subnets: elbSubnets,
-			Listeners: map[string]*awstasks.LoadBalancerListener{
-				"443": {InstancePort: 443},
-			},
-
-			// Configure fast-recovery health-checks
-			HealthCheck: &awstasks.LoadBalancerHealthCheck{
-				Target:             s("SSL:443"),
-				Timeout:            i64(5),
-				Interval:           i64(10),
-				HealthyThreshold:   i64(2),
-				UnhealthyThreshold: i64(2),
-			},
-
-			ConnectionSettings: &awstasks.LoadBalancerConnectionSettings{
-				IdleTimeout: i64(int64(idleTimeout.Seconds())),
-			},
+	elb, err := b.buildLoadBalancer("api", lbSpec, lbSpec.Type, elbSubnets)
+	if err != nil {
+		return err
+	}
+	c.AddTask(elb)
+
+	// With split-horizon DNS, clients inside the VPC reach the API through an internal ELB,
+	// which the private zone points to, while the public zone points to the public ELB
+	var internalELB *awstasks.LoadBalancer
+	if b.UseInternalLoadBalancerForAPI() {
+		internalSubnets, err := b.subnetsForELB(kops.LoadBalancerTypeInternal)
+		if err != nil {
+			return err
 		}
 
-		switch lbSpec.Type {
-		case kops.LoadBalancerTypeInternal:
-			elb.Scheme = s("internal")
-		case kops.LoadBalancerTypePublic:
-			elb.Scheme = nil
-		default:
-			return fmt.Errorf("unknown elb Type: %q", lbSpec.Type)
+		internalELB, err = b.buildLoadBalancer("api-internal", lbSpec, kops.LoadBalancerTypeInternal, internalSubnets)
+		if err != nil {
+			return err
 		}
-
-		c.AddTask(elb)
+		c.AddTask(internalELB)
 	}
 
 	// Create security group for API ELB
@@ -199,6 +146,9 @@ func (b *APILoadBalancerBuilder) Build(c *fi.ModelBuilderContext) error {
 				return err
 			}
 			elb.SecurityGroups = append(elb.SecurityGroups, t)
+			if internalELB != nil {
+				internalELB.SecurityGroups = append(internalELB.SecurityGroups, t)
+			}
 		}
 	}
 
@@ -239,12 +189,105 @@ func (b *APILoadBalancerBuilder) Build(c *fi.ModelBuilderContext) error {
 		}
 
 		c.AddTask(t)
+
+		if internalELB != nil {
+			c.AddTask(&awstasks.LoadBalancerAttachment{
+				Name:      s("api-internal-" + ig.ObjectMeta.Name),
+				Lifecycle: b.Lifecycle,
+
+				LoadBalancer:     b.LinkToELB("api-internal"),
+				AutoscalingGroup: b.LinkToAutoscalingGroup(ig),
+			})
+		}
 	}
 
 	return nil
 
 }
 
+// subnetsForELB computes the subnets for an ELB of the given type - only one per zone, and then break ties based on chooseBestSubnetForELB
+func (b *APILoadBalancerBuilder) subnetsForELB(lbType kops.LoadBalancerType) ([]*awstasks.Subnet, error) {
+	var elbSubnets []*awstasks.Subnet
+
+	subnetsByZone := make(map[string][]*kops.ClusterSubnetSpec)
+	for i := range b.Cluster.Spec.Subnets {
+		subnet := &b.Cluster.Spec.Subnets[i]
+
+		switch subnet.Type {
+		case kops.SubnetTypePublic, kops.SubnetTypeUtility:
+			if lbType != kops.LoadBalancerTypePublic {
+				continue
+			}
+
+		case kops.SubnetTypePrivate:
+			if lbType != kops.LoadBalancerTypeInternal {
+				continue
+			}
+
+		default:
+			return nil, fmt.Errorf("subnet %q had unknown type %q", subnet.Name, subnet.Type)
+		}
+
+		subnetsByZone[subnet.Zone] = append(subnetsByZone[subnet.Zone], subnet)
+	}
+
+	for zone, subnets := range subnetsByZone {
+		subnet := b.chooseBestSubnetForELB(zone, subnets)
+
+		elbSubnets = append(elbSubnets, b.LinkToSubnet(subnet))
+	}
+
+	return elbSubnets, nil
+}
+
+// buildLoadBalancer builds the ELB task for the API, named using prefix
+func (b *APILoadBalancerBuilder) buildLoadBalancer(prefix string, lbSpec *kops.LoadBalancerAccessSpec, lbType kops.LoadBalancerType, subnets []*awstasks.Subnet) (*awstasks.LoadBalancer, error) {
+	loadBalancerName := b.GetELBName32(prefix)
+
+	idleTimeout := LoadBalancerDefaultIdleTimeout
+	if lbSpec.IdleTimeoutSeconds != nil {
+		idleTimeout = time.Second * time.Duration(*lbSpec.IdleTimeoutSeconds)
+	}
+
+	elb := &awstasks.LoadBalancer{
+		Name:      s(b.ELBName(prefix)),
+		Lifecycle: b.Lifecycle,
+
+		LoadBalancerName: s(loadBalancerName),
+		SecurityGroups: []*awstasks.SecurityGroup{
+			b.LinkToELBSecurityGroup("api"),
+		},
+		Subnets: subnets,
+		Listeners: map[string]*awstasks.LoadBalancerListener{
+			"443": {InstancePort: 443},
+		},
+
+		// Configure fast-recovery health-checks
+		HealthCheck: &awstasks.LoadBalancerHealthCheck{
+			Target:             s("SSL:443"),
+			Timeout:            i64(5),
+			Interval:           i64(10),
+			HealthyThreshold:   i64(2),
+			UnhealthyThreshold: i64(2),
+		},
+
+		ConnectionSettings: &awstasks.LoadBalancerConnectionSettings{
+			IdleTimeout: i64(int64(idleTimeout.Seconds())),
+		},
+	}
+
+	switch lbType {
+	case kops.LoadBalancerTypeInternal:
+		elb.Scheme = s("internal")
+	case kops.LoadBalancerTypePublic:
+		elb.Scheme = nil
+	default:
+		return nil, fmt.Errorf("unknown elb Type: %q", lbType)
+	}
+
+	return elb, nil
+}
+
 type scoredSubnet struct {
 	score  int
 	subnet *kops.ClusterSubnetSpec
@@ -31,13 +31,17 @@ import (
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/nodeup"
 	"k8s.io/kops/pkg/model/resources"
+	"k8s.io/kops/pkg/nodeagent"
 	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/architectures"
 )
 
 // BootstrapScript creates the bootstrap script
 type BootstrapScript struct {
-	NodeUpSource        string
-	NodeUpSourceHash    string
+	// NodeUpSource is the location of nodeup, for each architecture
+	NodeUpSource map[architectures.Architecture]string
+	// NodeUpSourceHash is the hash of nodeup, for each architecture
+	NodeUpSourceHash    map[architectures.Architecture]string
 	NodeUpConfigBuilder func(ig *kops.InstanceGroup) (*nodeup.Config, error)
 }
 
@@ -64,12 +68,23 @@ func (b *BootstrapScript) ResourceNodeUp(ig *kops.InstanceGroup, cs *kops.Cluste
 		return nil, nil
 	}
 
+	arch := InstanceGroupArchitecture(cs, ig)
+
+	// The nodeup agent applies changes to these settings in place, so we leave them out of the bootstrap script
+	// and changing them does not replace the instances (see nodeagent.ApplyInPlaceChanges).
+	// nodeagent.ReplacementChanges compares the other settings included here, so keep the two in step.
+	inPlace := nodeagent.Enabled(cs, ig)
+
 	functions := template.FuncMap{
-		"NodeUpSource": func() string {
-			return b.NodeUpSource
+		"NodeUpSource": func() (string, error) {
+			source := b.NodeUpSource[arch]
+			if source == "" {
+				return "", fmt.Errorf("nodeup is not available for architecture %q", arch)
+			}
+			return source, nil
 		},
 		"NodeUpSourceHash": func() string {
-			return b.NodeUpSourceHash
+			return b.NodeUpSourceHash[arch]
 		},
 		"KubeEnv": func() (string, error) {
 			return b.KubeEnv(ig)
@@ -116,15 +131,30 @@ func (b *BootstrapScript) ResourceNodeUp(ig *kops.InstanceGroup, cs *kops.Cluste
 			spec := make(map[string]interface{})
 			spec["cloudConfig"] = cs.CloudConfig
 			spec["docker"] = cs.Docker
-			spec["kubelet"] = cs.Kubelet
+			if !inPlace {
+				spec["kubelet"] = cs.Kubelet
+			}
 			spec["kubeProxy"] = cs.KubeProxy
 
+			// The container runtime settings are only included when set, so that existing nodes are not replaced
+			if cs.ContainerRuntime != "" {
+				spec["containerRuntime"] = cs.ContainerRuntime
+			}
+			if cs.Containerd != nil {
+				spec["containerd"] = cs.Containerd
+			}
+			if cs.CRIO != nil {
+				spec["crio"] = cs.CRIO
+			}
+
 			if ig.IsMaster() {
 				spec["encryptionConfig"] = cs.EncryptionConfig
 				spec["kubeAPIServer"] = cs.KubeAPIServer
 				spec["kubeControllerManager"] = cs.KubeControllerManager
 				spec["kubeScheduler"] = cs.KubeScheduler
-				spec["masterKubelet"] = cs.MasterKubelet
+				if !inPlace {
+					spec["masterKubelet"] = cs.MasterKubelet
+				}
 				spec["etcdClusters"] = make(map[string]kops.EtcdClusterSpec, 0)
 
 				for _, etcdCluster := range cs.EtcdClusters {
@@ -143,12 +173,14 @@ func (b *BootstrapScript) ResourceNodeUp(ig *kops.InstanceGroup, cs *kops.Cluste
 				spec["hooks"] = hooks
 			}
 
-			fileAssets, err := b.getRelevantFileAssets(cs.FileAssets, ig.Spec.Role)
-			if err != nil {
-				return "", err
-			}
-			if len(fileAssets) > 0 {
-				spec["fileAssets"] = fileAssets
+			if !inPlace {
+				fileAssets, err := b.getRelevantFileAssets(cs.FileAssets, ig.Spec.Role)
+				if err != nil {
+					return "", err
+				}
+				if len(fileAssets) > 0 {
+					spec["fileAssets"] = fileAssets
+				}
 			}
 
 			content, err := yaml.Marshal(spec)
@@ -160,10 +192,21 @@ func (b *BootstrapScript) ResourceNodeUp(ig *kops.InstanceGroup, cs *kops.Cluste
 
 		"IGSpec": func() (string, error) {
 			spec := make(map[string]interface{})
-			spec["kubelet"] = ig.Spec.Kubelet
+			if !inPlace {
+				spec["kubelet"] = ig.Spec.Kubelet
+			}
 			spec["nodeLabels"] = ig.Spec.NodeLabels
 			spec["taints"] = ig.Spec.Taints
 			spec["suspendProcesses"] = ig.Spec.SuspendProcesses
+			if ig.Spec.ContainerRuntime != "" {
+				spec["containerRuntime"] = ig.Spec.ContainerRuntime
+			}
+			if ig.Spec.NodeBundle != nil {
+				spec["nodeBundle"] = ig.Spec.NodeBundle
+			}
+			if len(ig.Spec.NodeAddons) > 0 {
+				spec["nodeAddons"] = ig.Spec.NodeAddons
+			}
 
 			hooks, err := b.getRelevantHooks(ig.Spec.Hooks, ig.Spec.Role)
 			if err != nil {
@@ -173,12 +216,14 @@ func (b *BootstrapScript) ResourceNodeUp(ig *kops.InstanceGroup, cs *kops.Cluste
 				spec["hooks"] = hooks
 			}
 
-			fileAssets, err := b.getRelevantFileAssets(ig.Spec.FileAssets, ig.Spec.Role)
-			if err != nil {
-				return "", err
-			}
-			if len(fileAssets) > 0 {
-				spec["fileAssets"] = fileAssets
+			if !inPlace {
+				fileAssets, err := b.getRelevantFileAssets(ig.Spec.FileAssets, ig.Spec.Role)
+				if err != nil {
+					return "", err
+				}
+				if len(fileAssets) > 0 {
+					spec["fileAssets"] = fileAssets
+				}
 			}
 
 			content, err := yaml.Marshal(spec)
@@ -24,6 +24,8 @@ import (
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/nodeup"
 	"k8s.io/kops/pkg/diff"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/architectures"
 )
 
 func Test_ProxyFunc(t *testing.T) {
@@ -59,6 +61,7 @@ func TestBootstrapUserData(t *testing.T) {
 		ExpectedFilePath   string
 		HookSpecRoles      []kops.InstanceGroupRole
 		FileAssetSpecRoles []kops.InstanceGroupRole
+		NodeAgent          bool
 	}{
 		{
 			Role:               "Master",
@@ -108,19 +111,37 @@ func TestBootstrapUserData(t *testing.T) {
 			HookSpecRoles:      []kops.InstanceGroupRole{"Master", "Node"},
 			FileAssetSpecRoles: []kops.InstanceGroupRole{"Master", "Node"},
 		},
+		{
+			Role:               "Master",
+			ExpectedFilePath:   "tests/data/bootstrapscript_6.txt",
+			HookSpecRoles:      []kops.InstanceGroupRole{"Master", "Node"},
+			FileAssetSpecRoles: []kops.InstanceGroupRole{"Master", "Node"},
+			NodeAgent:          true,
+		},
+		{
+			Role:               "Node",
+			ExpectedFilePath:   "tests/data/bootstrapscript_7.txt",
+			HookSpecRoles:      []kops.InstanceGroupRole{"Master", "Node"},
+			FileAssetSpecRoles: []kops.InstanceGroupRole{"Master", "Node"},
+			NodeAgent:          true,
+		},
 	}
 
 	for i, x := range cs {
 		spec := makeTestCluster(x.HookSpecRoles, x.FileAssetSpecRoles).Spec
 		group := makeTestInstanceGroup(x.Role, x.HookSpecRoles, x.FileAssetSpecRoles)
+		if x.NodeAgent {
+			// The kubelet and file assets are applied in place by the agent, so they are left out
+			spec.NodeAgent = &kops.NodeAgentSpec{Enabled: fi.Bool(true)}
+		}
 
 		renderNodeUpConfig := func(ig *kops.InstanceGroup) (*nodeup.Config, error) {
 			return &nodeup.Config{}, nil
 		}
 
 		bs := &BootstrapScript{
-			NodeUpSource:        "NUSource",
-			NodeUpSourceHash:    "NUSHash",
+			NodeUpSource:        map[architectures.Architecture]string{architectures.ArchitectureAmd64: "NUSource"},
+			NodeUpSourceHash:    map[architectures.Architecture]string{architectures.ArchitectureAmd64: "NUSHash"},
 			NodeUpConfigBuilder: renderNodeUpConfig,
 		}
 
@@ -272,6 +272,11 @@ func (m *KopsModelContext) UseLoadBalancerForAPI() bool {
 	return m.Cluster.Spec.API.LoadBalancer != nil
 }
 
+// UsesExternalDNSProvider returns true if the cluster's DNS records are managed in an external DNS provider, not the DNS of the cloud
+func (m *KopsModelContext) UsesExternalDNSProvider() bool {
+	return m.Cluster.Spec.ExternalDNS != nil && m.Cluster.Spec.ExternalDNS.Provider != ""
+}
+
 func (m *KopsModelContext) UsePrivateDNS() bool {
 	topology := m.Cluster.Spec.Topology
 	if topology != nil && topology.DNS != nil {
@@ -280,6 +285,9 @@ func (m *KopsModelContext) UsePrivateDNS() bool {
 			return false
 		case kops.DNSTypePrivate:
 			return true
+		case kops.DNSTypeSplitHorizon:
+			// The DNSZone is the public zone; the private zone is additional
+			return false
 
 		default:
 			glog.Warningf("Unknown DNS type %q", topology.DNS.Type)
@@ -290,6 +298,21 @@ func (m *KopsModelContext) UsePrivateDNS() bool {
 	return false
 }
 
+// UsesSplitHorizonDNS returns true if we manage a private zone alongside the public DNSZone, with the same name
+func (m *KopsModelContext) UsesSplitHorizonDNS() bool {
+	topology := m.Cluster.Spec.Topology
+	return topology != nil && topology.DNS != nil && topology.DNS.Type == kops.DNSTypeSplitHorizon
+}
+
+// UseInternalLoadBalancerForAPI returns true if we build an internal ELB for the API alongside the public ELB,
+// which is the case for split-horizon DNS, where the private zone points to the internal ELB
+func (m *KopsModelContext) UseInternalLoadBalancerForAPI() bool {
+	if !m.UseLoadBalancerForAPI() || !m.UsesSplitHorizonDNS() {
+		return false
+	}
+	return m.Cluster.Spec.API.LoadBalancer.Type == kops.LoadBalancerTypePublic
+}
+
 // UseEtcdTLS checks to see if etcd tls is enabled
 func (c *KopsModelContext) UseEtcdTLS() bool {
 	for _, x := range c.Cluster.Spec.EtcdClusters {
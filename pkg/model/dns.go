@@ -48,12 +48,13 @@ func (b *DNSModelBuilder) ensureDNSZone(c *fi.ModelBuilderContext) error {
 	topology := b.Cluster.Spec.Topology
 	if topology != nil && topology.DNS != nil {
 		switch topology.DNS.Type {
-		case kops.DNSTypePublic:
-		// Ignore
+		case kops.DNSTypePublic, kops.DNSTypeSplitHorizon:
+		// Ignore; with split-horizon DNS this is the public zone
 
 		case kops.DNSTypePrivate:
 			dnsZone.Private = fi.Bool(true)
 			dnsZone.PrivateVPC = b.LinkToVPC()
+			dnsZone.AdditionalVPCs = b.additionalDNSZoneVPCs()
 
 		default:
 			return fmt.Errorf("Unknown DNS type %q", topology.DNS.Type)
@@ -68,10 +69,51 @@ func (b *DNSModelBuilder) ensureDNSZone(c *fi.ModelBuilderContext) error {
 		dnsZone.DNSName = s(b.Cluster.Spec.DNSZone)
 	}
 
-	return c.EnsureTask(dnsZone)
+	if err := c.EnsureTask(dnsZone); err != nil {
+		return err
+	}
+
+	if b.UsesSplitHorizonDNS() {
+		// The private zone has the same name as the public zone, and is associated with our VPC
+		privateDNSZone := &awstasks.DNSZone{
+			Name:      s(b.NameForPrivateDNSZone()),
+			Lifecycle: b.Lifecycle,
+
+			DNSName:        s(b.Cluster.Spec.DNSZone),
+			Private:        fi.Bool(true),
+			PrivateVPC:     b.LinkToVPC(),
+			AdditionalVPCs: b.additionalDNSZoneVPCs(),
+		}
+		if err := c.EnsureTask(privateDNSZone); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// additionalDNSZoneVPCs returns the VPCs, other than the cluster VPC, to associate with the private zone
+func (b *DNSModelBuilder) additionalDNSZoneVPCs() []*awstasks.DNSZoneVPC {
+	var vpcs []*awstasks.DNSZoneVPC
+	for _, vpc := range b.Cluster.Spec.Topology.DNS.AdditionalVPCs {
+		region := vpc.Region
+		if region == "" {
+			region = b.Region
+		}
+		vpcs = append(vpcs, &awstasks.DNSZoneVPC{
+			ID:     s(vpc.ID),
+			Region: s(region),
+		})
+	}
+	return vpcs
 }
 
 func (b *DNSModelBuilder) Build(c *fi.ModelBuilderContext) error {
+	// The zone is not in the cloud's DNS, so there is nothing for us to create; the dns-controller manages the records
+	if b.UsesExternalDNSProvider() {
+		return nil
+	}
+
 	// Add a HostedZone if we are going to publish a dns record that depends on it
 	if b.UsePrivateDNS() {
 		// Check to see if we are using a bastion DNS record that points to the hosted zone
@@ -108,6 +150,22 @@ func (b *DNSModelBuilder) Build(c *fi.ModelBuilderContext) error {
 				TargetLoadBalancer: b.LinkToELB("api"),
 			}
 			c.AddTask(apiDnsName)
+
+			if b.UsesSplitHorizonDNS() {
+				// Inside the VPC, the API name resolves to the internal ELB (if we have one)
+				target := b.LinkToELB("api")
+				if b.UseInternalLoadBalancerForAPI() {
+					target = b.LinkToELB("api-internal")
+				}
+				c.AddTask(&awstasks.DNSName{
+					Name:               s("private-" + b.Cluster.Spec.MasterPublicName),
+					Lifecycle:          b.Lifecycle,
+					Zone:               b.LinkToPrivateDNSZone(),
+					DNSName:            s(b.Cluster.Spec.MasterPublicName),
+					ResourceType:       s("A"),
+					TargetLoadBalancer: target,
+				})
+			}
 		}
 	}
 
@@ -24,6 +24,7 @@ import (
 	"text/template"
 
 	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/model/iam"
 	"k8s.io/kops/upup/pkg/fi"
@@ -90,9 +91,10 @@ func (b *IAMModelBuilder) Build(c *fi.ModelBuilderContext) error {
 		{
 			iamPolicy := &iam.PolicyResource{
 				Builder: &iam.PolicyBuilder{
-					Cluster: b.Cluster,
-					Role:    role,
-					Region:  b.Region,
+					Cluster:     b.Cluster,
+					Role:        role,
+					Region:      b.Region,
+					HookSecrets: b.hookSecrets(role),
 				},
 			}
 
@@ -106,6 +108,11 @@ func (b *IAMModelBuilder) Build(c *fi.ModelBuilderContext) error {
 			} else {
 				glog.V(2).Infof("Task %q not found; won't set route53 permissions in IAM", "DNSZone/"+b.NameForDNSZone())
 			}
+			if b.UsesSplitHorizonDNS() {
+				if privateDNSZoneTask, found := c.Tasks["DNSZone/"+b.NameForPrivateDNSZone()]; found {
+					iamPolicy.PrivateDNSZone = privateDNSZoneTask.(*awstasks.DNSZone)
+				}
+			}
 
 			t := &awstasks.IAMRolePolicy{
 				Name:      s(name),
@@ -204,3 +211,38 @@ func (b *IAMModelBuilder) buildAWSIAMRolePolicy() (fi.Resource, error) {
 	}
 	return templateResource, nil
 }
+
+// hookSecrets returns the names of the secrets referenced by the hooks of the cluster and instance groups
+// which run on instances with the role, so nodeup can read them
+func (b *IAMModelBuilder) hookSecrets(role kops.InstanceGroupRole) []string {
+	hooks := append([]kops.HookSpec{}, b.Cluster.Spec.Hooks...)
+	for _, ig := range b.InstanceGroups {
+		if ig.Spec.Role == role {
+			hooks = append(hooks, ig.Spec.Hooks...)
+		}
+	}
+
+	names := sets.NewString()
+	for _, hook := range hooks {
+		if hook.Disabled || !hookAppliesToRole(&hook, role) {
+			continue
+		}
+		for _, name := range hook.EnvironmentFromSecrets {
+			names.Insert(name)
+		}
+	}
+	return names.List()
+}
+
+// hookAppliesToRole returns true if the hook runs on instances with the role
+func hookAppliesToRole(hook *kops.HookSpec, role kops.InstanceGroupRole) bool {
+	if len(hook.Roles) == 0 {
+		return true
+	}
+	for _, r := range hook.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -109,6 +109,12 @@ type PolicyBuilder struct {
 	Region       string
 	ResourceARN  *string
 	Role         kops.InstanceGroupRole
+
+	// PrivateHostedZoneID is the private zone of split-horizon DNS, which is managed alongside HostedZoneID
+	PrivateHostedZoneID string
+
+	// HookSecrets are the names of the secrets referenced by the hooks which run on instances with the role
+	HookSecrets []string
 }
 
 // BuildAWSPolicy builds a set of IAM policy statements based on the
@@ -172,7 +178,7 @@ func (b *PolicyBuilder) BuildAWSPolicyMaster() (*Policy, error) {
 	}
 
 	if b.HostedZoneID != "" {
-		addRoute53Permissions(p, b.HostedZoneID)
+		addRoute53Permissions(p, b.hostedZoneIDs()...)
 	}
 
 	if b.Cluster.Spec.IAM.Legacy {
@@ -211,7 +217,7 @@ func (b *PolicyBuilder) BuildAWSPolicyNode() (*Policy, error) {
 
 	if b.Cluster.Spec.IAM.Legacy {
 		if b.HostedZoneID != "" {
-			addRoute53Permissions(p, b.HostedZoneID)
+			addRoute53Permissions(p, b.hostedZoneIDs()...)
 		}
 		addRoute53ListHostedZonesPermission(p)
 	}
@@ -352,6 +358,7 @@ func (b *PolicyBuilder) AddS3Permissions(p *Policy) (*Policy, error) {
 							strings.Join([]string{b.IAMPrefix(), ":s3:::", iamS3Path, "/cluster.spec"}, ""),
 							strings.Join([]string{b.IAMPrefix(), ":s3:::", iamS3Path, "/config"}, ""),
 							strings.Join([]string{b.IAMPrefix(), ":s3:::", iamS3Path, "/instancegroup/*"}, ""),
+							strings.Join([]string{b.IAMPrefix(), ":s3:::", iamS3Path, "/nodebundles/*"}, ""),
 							strings.Join([]string{b.IAMPrefix(), ":s3:::", iamS3Path, "/pki/issued/*"}, ""),
 							strings.Join([]string{b.IAMPrefix(), ":s3:::", iamS3Path, "/pki/private/kube-proxy/*"}, ""),
 							strings.Join([]string{b.IAMPrefix(), ":s3:::", iamS3Path, "/pki/private/kubelet/*"}, ""),
@@ -360,6 +367,20 @@ func (b *PolicyBuilder) AddS3Permissions(p *Policy) (*Policy, error) {
 						),
 					})
 
+					// nodeup reads the secrets used in the environment of the hooks
+					if len(b.HookSecrets) != 0 {
+						var resources []string
+						for _, name := range b.HookSecrets {
+							resources = append(resources, strings.Join([]string{b.IAMPrefix(), ":s3:::", iamS3Path, "/secrets/", name}, ""))
+						}
+						p.Statement = append(p.Statement, &Statement{
+							Sid:      "kopsK8sS3NodeBucketGetHookSecrets",
+							Effect:   StatementEffectAllow,
+							Action:   stringorslice.Slice([]string{"s3:Get*"}),
+							Resource: stringorslice.Slice(resources),
+						})
+					}
+
 					if b.Cluster.Spec.Networking != nil {
 						// @check if kuberoute is enabled and permit access to the private key
 						if b.Cluster.Spec.Networking.Kuberouter != nil {
@@ -386,6 +407,18 @@ func (b *PolicyBuilder) AddS3Permissions(p *Policy) (*Policy, error) {
 						}
 					}
 				}
+
+				// nodeup uploads its boot report, for kops toolbox dump
+				if fi.BoolValue(b.Cluster.Spec.UploadBootReports) && (b.Role == kops.InstanceGroupRoleMaster || b.Role == kops.InstanceGroupRoleNode) {
+					p.Statement = append(p.Statement, &Statement{
+						Sid:    "kopsK8sS3BootReportsPut",
+						Effect: StatementEffectAllow,
+						Action: stringorslice.Slice([]string{"s3:PutObject"}),
+						Resource: stringorslice.Of(
+							strings.Join([]string{b.IAMPrefix(), ":s3:::", iamS3Path, "/bootreports/*"}, ""),
+						),
+					})
+				}
 			}
 		} else if _, ok := vfsPath.(*vfs.MemFSPath); ok {
 			// Tests -ignore - nothing we can do in terms of IAM policy
@@ -437,8 +470,9 @@ func (b *PolicyBuilder) AddS3Permissions(p *Policy) (*Policy, error) {
 // PolicyResource defines the PolicyBuilder and DNSZone to use when building the
 // IAM policy document for a given instance group role
 type PolicyResource struct {
-	Builder *PolicyBuilder
-	DNSZone *awstasks.DNSZone
+	Builder        *PolicyBuilder
+	DNSZone        *awstasks.DNSZone
+	PrivateDNSZone *awstasks.DNSZone
 }
 
 var _ fi.Resource = &PolicyResource{}
@@ -450,6 +484,9 @@ func (b *PolicyResource) GetDependencies(tasks map[string]fi.Task) []fi.Task {
 	if b.DNSZone != nil {
 		deps = append(deps, b.DNSZone)
 	}
+	if b.PrivateDNSZone != nil {
+		deps = append(deps, b.PrivateDNSZone)
+	}
 	return deps
 }
 
@@ -467,6 +504,14 @@ func (b *PolicyResource) Open() (io.Reader, error) {
 		pb.HostedZoneID = hostedZoneID
 	}
 
+	if b.PrivateDNSZone != nil {
+		privateHostedZoneID := fi.StringValue(b.PrivateDNSZone.ZoneID)
+		if privateHostedZoneID == "" {
+			return nil, fmt.Errorf("private DNS ZoneID not set")
+		}
+		pb.PrivateHostedZoneID = privateHostedZoneID
+	}
+
 	policy, err := pb.BuildAWSPolicy()
 	if err != nil {
 		return nil, fmt.Errorf("error building IAM policy: %v", err)
@@ -502,13 +547,27 @@ func addECRPermissions(p *Policy) {
 	})
 }
 
-func addRoute53Permissions(p *Policy, hostedZoneID string) {
+// hostedZoneIDs returns the ids of the hosted zones in which we manage records
+func (b *PolicyBuilder) hostedZoneIDs() []string {
+	ids := []string{b.HostedZoneID}
+	if b.PrivateHostedZoneID != "" {
+		ids = append(ids, b.PrivateHostedZoneID)
+	}
+	return ids
+}
+
+func addRoute53Permissions(p *Policy, hostedZoneIDs ...string) {
 
 	// TODO: Route53 currently not supported in China, need to check and fail/return
 
-	// Remove /hostedzone/ prefix (if present)
-	hostedZoneID = strings.TrimPrefix(hostedZoneID, "/")
-	hostedZoneID = strings.TrimPrefix(hostedZoneID, "hostedzone/")
+	var resources []string
+	for _, hostedZoneID := range hostedZoneIDs {
+		// Remove /hostedzone/ prefix (if present)
+		hostedZoneID = strings.TrimPrefix(hostedZoneID, "/")
+		hostedZoneID = strings.TrimPrefix(hostedZoneID, "hostedzone/")
+
+		resources = append(resources, "arn:aws:route53:::hostedzone/"+hostedZoneID)
+	}
 
 	p.Statement = append(p.Statement, &Statement{
 		Sid:    "kopsK8sRoute53Change",
@@ -516,7 +575,7 @@ func addRoute53Permissions(p *Policy, hostedZoneID string) {
 		Action: stringorslice.Of("route53:ChangeResourceRecordSets",
 			"route53:ListResourceRecordSets",
 			"route53:GetHostedZone"),
-		Resource: stringorslice.Slice([]string{"arn:aws:route53:::hostedzone/" + hostedZoneID}),
+		Resource: stringorslice.Slice(resources),
 	})
 
 	p.Statement = append(p.Statement, &Statement{
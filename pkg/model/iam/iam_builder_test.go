@@ -81,6 +81,8 @@ func TestPolicyGeneration(t *testing.T) {
 		Role                   kops.InstanceGroupRole
 		LegacyIAM              bool
 		AllowContainerRegistry bool
+		UploadBootReports      bool
+		HookSecrets            []string
 		Policy                 string
 	}{
 		{
@@ -119,6 +121,20 @@ func TestPolicyGeneration(t *testing.T) {
 			AllowContainerRegistry: true,
 			Policy:                 "tests/iam_builder_node_strict_ecr.json",
 		},
+		{
+			Role:                   "Node",
+			LegacyIAM:              false,
+			AllowContainerRegistry: false,
+			UploadBootReports:      true,
+			Policy:                 "tests/iam_builder_node_strict_bootreports.json",
+		},
+		{
+			Role:                   "Node",
+			LegacyIAM:              false,
+			AllowContainerRegistry: false,
+			HookSecrets:            []string{"hook-token", "registry-password"},
+			Policy:                 "tests/iam_builder_node_strict_hooksecrets.json",
+		},
 		{
 			Role:                   "Bastion",
 			LegacyIAM:              true,
@@ -148,6 +164,7 @@ func TestPolicyGeneration(t *testing.T) {
 						Legacy:                 x.LegacyIAM,
 						AllowContainerRegistry: x.AllowContainerRegistry,
 					},
+					UploadBootReports: &x.UploadBootReports,
 					EtcdClusters: []*kops.EtcdClusterSpec{
 						{
 							Members: []*kops.EtcdMemberSpec{
@@ -172,7 +189,8 @@ func TestPolicyGeneration(t *testing.T) {
 					},
 				},
 			},
-			Role: x.Role,
+			HookSecrets: x.HookSecrets,
+			Role:        x.Role,
 		}
 		b.Cluster.SetName("iam-builder-test.k8s.local")
 
@@ -99,6 +99,17 @@ func (b *KopsModelContext) NameForDNSZone() string {
 	return name
 }
 
+// LinkToPrivateDNSZone returns a link to the private zone of split-horizon DNS
+func (b *KopsModelContext) LinkToPrivateDNSZone() *awstasks.DNSZone {
+	name := b.NameForPrivateDNSZone()
+	return &awstasks.DNSZone{Name: &name}
+}
+
+// NameForPrivateDNSZone is the task name of the private zone of split-horizon DNS, which has the same DNS name as the DNSZone
+func (b *KopsModelContext) NameForPrivateDNSZone() string {
+	return "private-" + b.Cluster.Spec.DNSZone
+}
+
 func (b *KopsModelContext) IAMName(role kops.InstanceGroupRole) string {
 	switch role {
 	case kops.InstanceGroupRoleMaster:
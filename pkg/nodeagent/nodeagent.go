@@ -0,0 +1,215 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeagent
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+// DefaultInterval is the time between runs of the nodeup agent, if not configured
+const DefaultInterval = 10 * time.Minute
+
+// Annotations set on the node by the nodeup agent
+const (
+	// AnnotationLastRun is the time the agent last ran
+	AnnotationLastRun = "kops.k8s.io/nodeup-agent-last-run"
+	// AnnotationStatus is the outcome of the last run: Applied or Failed
+	AnnotationStatus = "kops.k8s.io/nodeup-agent-status"
+	// AnnotationError is the error from the last run, if it failed
+	AnnotationError = "kops.k8s.io/nodeup-agent-error"
+	// AnnotationReplacementRequired lists the settings which changed, but which the agent does not apply in place
+	AnnotationReplacementRequired = "kops.k8s.io/nodeup-agent-replacement-required"
+)
+
+const (
+	// StatusApplied means the agent applied the configuration
+	StatusApplied = "Applied"
+	// StatusFailed means the agent failed to apply the configuration
+	StatusFailed = "Failed"
+)
+
+// Enabled returns true if the nodeup agent runs on the instances of the instance group
+func Enabled(cluster *kops.ClusterSpec, ig *kops.InstanceGroup) bool {
+	spec := mergeNodeAgent(cluster, ig)
+	return fi.BoolValue(spec.Enabled)
+}
+
+// Interval returns the time between runs of the nodeup agent on the instances of the instance group
+func Interval(cluster *kops.ClusterSpec, ig *kops.InstanceGroup) time.Duration {
+	spec := mergeNodeAgent(cluster, ig)
+	if spec.Interval == nil || spec.Interval.Duration == 0 {
+		return DefaultInterval
+	}
+	return spec.Interval.Duration
+}
+
+// mergeNodeAgent returns the NodeAgent of the cluster, with the settings of the instance group taking precedence
+func mergeNodeAgent(cluster *kops.ClusterSpec, ig *kops.InstanceGroup) *kops.NodeAgentSpec {
+	merged := &kops.NodeAgentSpec{}
+	specs := []*kops.NodeAgentSpec{cluster.NodeAgent}
+	if ig != nil {
+		specs = append(specs, ig.Spec.NodeAgent)
+	}
+	for _, spec := range specs {
+		if spec == nil {
+			continue
+		}
+		if spec.Enabled != nil {
+			merged.Enabled = spec.Enabled
+		}
+		if spec.Interval != nil {
+			merged.Interval = spec.Interval
+		}
+	}
+	return merged
+}
+
+// inPlaceClusterSettings are the fields of the ClusterSpec which the agent changes in place
+var inPlaceClusterSettings = []string{"Kubelet", "MasterKubelet", "FileAssets", "NodeTuning", "NodeAgent"}
+
+// inPlaceInstanceGroupSettings are the fields of the InstanceGroupSpec which the agent changes in place
+var inPlaceInstanceGroupSettings = []string{"Kubelet", "FileAssets", "NodeTuning", "NodeAgent"}
+
+// The settings which need replacement are the settings which the bootstrap script fingerprints (see BootstrapScript.ResourceNodeUp),
+// other than the in-place settings: other settings do not change the instance, or are not used by nodeup.
+
+// replacementClusterSettings are the fields of the ClusterSpec which need replacement
+var replacementClusterSettings = []string{"CloudProvider", "ConfigBase", "KubernetesVersion", "EgressProxy", "CloudConfig", "Docker", "ContainerRuntime", "Containerd", "CRIO", "KubeProxy", "Hooks"}
+
+// replacementMasterSettings are the fields of the ClusterSpec which need replacement of masters, but which nodes do not use
+var replacementMasterSettings = []string{"Addons", "EncryptionConfig", "EtcdClusters", "KubeAPIServer", "KubeControllerManager", "KubeScheduler"}
+
+// replacementInstanceGroupSettings are the fields of the InstanceGroupSpec which need replacement
+var replacementInstanceGroupSettings = []string{"Architecture", "AdditionalUserData", "ContainerRuntime", "NodeBundle", "NodeAddons", "NodeLabels", "Taints", "SuspendProcesses", "Hooks"}
+
+// ApplyInPlaceChanges copies the settings which the agent changes in place from the current configuration to the applied configuration.
+// The bootstrap script leaves out these settings when the agent is enabled, so changing them does not replace the instances.
+func ApplyInPlaceChanges(applied *kops.Cluster, appliedIG *kops.InstanceGroup, current *kops.Cluster, currentIG *kops.InstanceGroup) {
+	copyFields(&applied.Spec, &current.Spec, inPlaceClusterSettings)
+
+	if appliedIG != nil && currentIG != nil {
+		copyFields(&appliedIG.Spec, &currentIG.Spec, inPlaceInstanceGroupSettings)
+	}
+}
+
+// ReplacementChanges returns the names of the settings which differ between the applied and current configuration,
+// and which the agent does not change in place: the instance must be replaced to apply them.
+func ReplacementChanges(applied *kops.Cluster, appliedIG *kops.InstanceGroup, current *kops.Cluster, currentIG *kops.InstanceGroup) []string {
+	role := kops.InstanceGroupRoleNode
+	if currentIG != nil {
+		role = currentIG.Spec.Role
+	}
+
+	settings := replacementClusterSettings
+	if role == kops.InstanceGroupRoleMaster {
+		settings = append(append([]string{}, settings...), replacementMasterSettings...)
+	}
+	changes := changedFields("spec", fingerprintedClusterSpec(&applied.Spec, role), fingerprintedClusterSpec(&current.Spec, role), settings)
+
+	if appliedIG != nil && currentIG != nil {
+		changes = append(changes, changedFields("instanceGroup.spec", fingerprintedInstanceGroupSpec(&appliedIG.Spec), fingerprintedInstanceGroupSpec(&currentIG.Spec), replacementInstanceGroupSettings)...)
+	}
+	return changes
+}
+
+// fingerprintedClusterSpec returns a copy of the ClusterSpec reduced as in the bootstrap script:
+// only the hooks for the role, and only the image and version of the etcd clusters
+func fingerprintedClusterSpec(spec *kops.ClusterSpec, role kops.InstanceGroupRole) *kops.ClusterSpec {
+	reduced := *spec
+	reduced.Hooks = relevantHooks(spec.Hooks, role)
+	reduced.EtcdClusters = nil
+	for _, etcdCluster := range spec.EtcdClusters {
+		reduced.EtcdClusters = append(reduced.EtcdClusters, &kops.EtcdClusterSpec{
+			Name:    etcdCluster.Name,
+			Image:   etcdCluster.Image,
+			Version: etcdCluster.Version,
+		})
+	}
+	return &reduced
+}
+
+// fingerprintedInstanceGroupSpec returns a copy of the InstanceGroupSpec reduced as in the bootstrap script: only the hooks for the role
+func fingerprintedInstanceGroupSpec(spec *kops.InstanceGroupSpec) *kops.InstanceGroupSpec {
+	reduced := *spec
+	reduced.Hooks = relevantHooks(spec.Hooks, spec.Role)
+	return &reduced
+}
+
+// relevantHooks returns the hooks which apply to the role
+func relevantHooks(hooks []kops.HookSpec, role kops.InstanceGroupRole) []kops.HookSpec {
+	var relevant []kops.HookSpec
+	for _, hook := range hooks {
+		if len(hook.Roles) == 0 {
+			relevant = append(relevant, hook)
+			continue
+		}
+		for _, hookRole := range hook.Roles {
+			if hookRole == role {
+				relevant = append(relevant, hook)
+				break
+			}
+		}
+	}
+	return relevant
+}
+
+// copyFields sets the named fields of the struct pointed to by dest to their values in src
+func copyFields(dest, src interface{}, fields []string) {
+	d := reflect.ValueOf(dest).Elem()
+	s := reflect.ValueOf(src).Elem()
+	for _, field := range fields {
+		d.FieldByName(field).Set(s.FieldByName(field))
+	}
+}
+
+// changedFields returns the names of the listed fields which differ between the structs pointed to by a and b.
+// The names are the json names of the fields, as they appear in kops edit, with the prefix.
+func changedFields(prefix string, a, b interface{}, fields []string) []string {
+	va := reflect.ValueOf(a).Elem()
+	vb := reflect.ValueOf(b).Elem()
+
+	var changes []string
+	for i := 0; i < va.NumField(); i++ {
+		field := va.Type().Field(i)
+		if !containsString(fields, field.Name) {
+			continue
+		}
+		if reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		changes = append(changes, prefix+"."+name)
+	}
+	return changes
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeagent
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func TestEnabledAndInterval(t *testing.T) {
+	grid := []struct {
+		Cluster          *kops.NodeAgentSpec
+		InstanceGroup    *kops.NodeAgentSpec
+		ExpectedEnabled  bool
+		ExpectedInterval time.Duration
+	}{
+		{
+			ExpectedEnabled:  false,
+			ExpectedInterval: DefaultInterval,
+		},
+		{
+			Cluster:          &kops.NodeAgentSpec{Enabled: fi.Bool(true), Interval: &metav1.Duration{Duration: time.Hour}},
+			ExpectedEnabled:  true,
+			ExpectedInterval: time.Hour,
+		},
+		{
+			Cluster:          &kops.NodeAgentSpec{Enabled: fi.Bool(true), Interval: &metav1.Duration{Duration: time.Hour}},
+			InstanceGroup:    &kops.NodeAgentSpec{Enabled: fi.Bool(false)},
+			ExpectedEnabled:  false,
+			ExpectedInterval: time.Hour,
+		},
+		{
+			InstanceGroup:    &kops.NodeAgentSpec{Enabled: fi.Bool(true), Interval: &metav1.Duration{Duration: 5 * time.Minute}},
+			ExpectedEnabled:  true,
+			ExpectedInterval: 5 * time.Minute,
+		},
+	}
+	for i, g := range grid {
+		cluster := &kops.ClusterSpec{NodeAgent: g.Cluster}
+		ig := &kops.InstanceGroup{Spec: kops.InstanceGroupSpec{NodeAgent: g.InstanceGroup}}
+		if actual := Enabled(cluster, ig); actual != g.ExpectedEnabled {
+			t.Errorf("case %d: expected enabled %v, got %v", i, g.ExpectedEnabled, actual)
+		}
+		if actual := Interval(cluster, ig); actual != g.ExpectedInterval {
+			t.Errorf("case %d: expected interval %v, got %v", i, g.ExpectedInterval, actual)
+		}
+	}
+}
+
+func TestReplacementChanges(t *testing.T) {
+	newConfig := func(role kops.InstanceGroupRole) (*kops.Cluster, *kops.InstanceGroup) {
+		cluster := &kops.Cluster{
+			Spec: kops.ClusterSpec{
+				KubernetesVersion: "1.10.0",
+				Kubelet:           &kops.KubeletConfigSpec{MaxPods: fi.Int32(110)},
+				KubeAPIServer:     &kops.KubeAPIServerConfig{LogLevel: 2},
+				FileAssets:        []kops.FileAssetSpec{{Name: "motd", Path: "/etc/motd", Content: "hello"}},
+			},
+		}
+		ig := &kops.InstanceGroup{
+			Spec: kops.InstanceGroupSpec{
+				Role:       role,
+				Image:      "kope.io/k8s-1.10-debian-stretch-amd64-hvm-ebs-2018-08-17",
+				NodeLabels: map[string]string{"team": "a"},
+			},
+		}
+		return cluster, ig
+	}
+
+	grid := []struct {
+		Role     kops.InstanceGroupRole
+		Change   func(c *kops.Cluster, ig *kops.InstanceGroup)
+		Expected []string
+	}{
+		{
+			Role: kops.InstanceGroupRoleNode,
+			Change: func(c *kops.Cluster, ig *kops.InstanceGroup) {
+				c.Spec.Kubelet.MaxPods = fi.Int32(200)
+				c.Spec.FileAssets[0].Content = "goodbye"
+				ig.Spec.NodeTuning = &kops.NodeTuningSpec{SysctlParameters: []string{"vm.swappiness=10"}}
+			},
+		},
+		{
+			Role: kops.InstanceGroupRoleNode,
+			Change: func(c *kops.Cluster, ig *kops.InstanceGroup) {
+				c.Spec.KubernetesVersion = "1.10.5"
+				c.Spec.KubeAPIServer.LogLevel = 4
+				ig.Spec.NodeLabels["team"] = "b"
+			},
+			Expected: []string{"spec.kubernetesVersion", "instanceGroup.spec.nodeLabels"},
+		},
+		{
+			Role: kops.InstanceGroupRoleMaster,
+			Change: func(c *kops.Cluster, ig *kops.InstanceGroup) {
+				c.Spec.KubeAPIServer.LogLevel = 4
+			},
+			Expected: []string{"spec.kubeAPIServer"},
+		},
+		{
+			// Settings which the bootstrap script does not include do not need the agent to replace the node
+			Role: kops.InstanceGroupRoleNode,
+			Change: func(c *kops.Cluster, ig *kops.InstanceGroup) {
+				c.Spec.SSHAccess = []string{"10.0.0.0/8"}
+				c.Spec.KubernetesAPIAccess = []string{"10.0.0.0/8"}
+				c.Spec.Subnets = []kops.ClusterSubnetSpec{{Name: "us-test-1a", CIDR: "172.20.32.0/19"}}
+				c.Spec.Topology = &kops.TopologySpec{Masters: kops.TopologyPrivate, Nodes: kops.TopologyPrivate}
+				c.Spec.API = &kops.AccessSpec{DNS: &kops.DNSAccessSpec{}}
+				c.Spec.Addons = []kops.AddonSpec{{Manifest: "s3://bucket/addons.yaml"}}
+				ig.Spec.RootVolumeSize = fi.Int32(64)
+			},
+		},
+		{
+			// Hooks are only compared for the role of the instance group
+			Role: kops.InstanceGroupRoleNode,
+			Change: func(c *kops.Cluster, ig *kops.InstanceGroup) {
+				c.Spec.Hooks = []kops.HookSpec{{Name: "masters.service", Roles: []kops.InstanceGroupRole{kops.InstanceGroupRoleMaster}}}
+			},
+		},
+		{
+			Role: kops.InstanceGroupRoleNode,
+			Change: func(c *kops.Cluster, ig *kops.InstanceGroup) {
+				c.Spec.Hooks = []kops.HookSpec{{Name: "all.service"}}
+				ig.Spec.Taints = []string{"dedicated=gpu:NoSchedule"}
+			},
+			Expected: []string{"spec.hooks", "instanceGroup.spec.taints"},
+		},
+		{
+			Role: kops.InstanceGroupRoleMaster,
+			Change: func(c *kops.Cluster, ig *kops.InstanceGroup) {
+				c.Spec.Addons = []kops.AddonSpec{{Manifest: "s3://bucket/addons.yaml"}}
+				c.Spec.EtcdClusters = []*kops.EtcdClusterSpec{{Name: "main", Version: "3.2.18"}}
+			},
+			Expected: []string{"spec.addons", "spec.etcdClusters"},
+		},
+		{
+			Role: kops.InstanceGroupRoleNode,
+			Change: func(c *kops.Cluster, ig *kops.InstanceGroup) {
+				ig.Spec.MaxSize = fi.Int32(10)
+			},
+		},
+	}
+	for i, g := range grid {
+		applied, appliedIG := newConfig(g.Role)
+		current, currentIG := newConfig(g.Role)
+		g.Change(current, currentIG)
+
+		actual := ReplacementChanges(applied, appliedIG, current, currentIG)
+		if !reflect.DeepEqual(actual, g.Expected) {
+			t.Errorf("case %d: expected changes %v, got %v", i, g.Expected, actual)
+		}
+
+		// Once the in-place changes are applied, only the changes which need replacement remain
+		ApplyInPlaceChanges(applied, appliedIG, current, currentIG)
+		if !reflect.DeepEqual(applied.Spec.Kubelet, current.Spec.Kubelet) || !reflect.DeepEqual(appliedIG.Spec.NodeTuning, currentIG.Spec.NodeTuning) {
+			t.Errorf("case %d: in-place changes were not applied", i)
+		}
+		if actual := ReplacementChanges(applied, appliedIG, current, currentIG); !reflect.DeepEqual(actual, g.Expected) {
+			t.Errorf("case %d: expected changes %v after applying in place, got %v", i, g.Expected, actual)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodebundle
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+const (
+	testKubeletSource = "https://storage.googleapis.com/kubernetes-release/release/v1.10.0/bin/linux/amd64/kubelet"
+	testKubeletData   = "kubelet binary"
+	testPackageData   = "socat package"
+)
+
+// buildTestBundle writes a bundle containing a kubelet and a socat package, returning the path of the tarball
+func buildTestBundle(t *testing.T, tmp string, kubeletHash string) string {
+	src := filepath.Join(tmp, "src")
+	files := map[string]string{
+		"files/kubelet/kubelet":        testKubeletData,
+		"packages/socat/socat_1.7.deb": testPackageData,
+	}
+	for p, data := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(src, p)), 0755); err != nil {
+			t.Fatalf("error creating directories: %v", err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(src, p), []byte(data), 0644); err != nil {
+			t.Fatalf("error writing file: %v", err)
+		}
+	}
+
+	packageHash, err := hashing.HashAlgorithmSHA256.Hash(strings.NewReader(testPackageData))
+	if err != nil {
+		t.Fatalf("error hashing: %v", err)
+	}
+
+	manifest := &Manifest{
+		ClusterName:  "minimal.example.com",
+		Distribution: "xenial",
+		Architecture: "amd64",
+		Files: []*File{
+			{Path: "files/kubelet/kubelet", Source: testKubeletSource, Hash: kubeletHash},
+			{Path: "packages/socat/socat_1.7.deb", Hash: packageHash.Hex()},
+		},
+		Packages: []*Package{
+			{Name: "socat", Files: []string{"packages/socat/socat_1.7.deb"}},
+		},
+	}
+
+	tarball := filepath.Join(tmp, "bundle.tar.gz")
+	f, err := os.Create(tarball)
+	if err != nil {
+		t.Fatalf("error creating tarball: %v", err)
+	}
+	defer f.Close()
+	if err := Write(f, manifest, src); err != nil {
+		t.Fatalf("error writing bundle: %v", err)
+	}
+	return tarball
+}
+
+func kubeletHash(t *testing.T) string {
+	h, err := hashing.HashAlgorithmSHA1.Hash(strings.NewReader(testKubeletData))
+	if err != nil {
+		t.Fatalf("error hashing: %v", err)
+	}
+	return h.Hex()
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "nodebundle")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	tarball := buildTestBundle(t, tmp, kubeletHash(t))
+	dir := filepath.Join(tmp, "extracted")
+
+	b, err := Extract(tarball, dir)
+	if err != nil {
+		t.Fatalf("error extracting bundle: %v", err)
+	}
+	if b.Manifest.ClusterName != "minimal.example.com" {
+		t.Errorf("unexpected cluster name %q", b.Manifest.ClusterName)
+	}
+
+	p, err := b.FindSource(testKubeletSource, kubeletHash(t))
+	if err != nil {
+		t.Fatalf("error finding kubelet: %v", err)
+	}
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		t.Fatalf("error reading kubelet: %v", err)
+	}
+	if string(data) != testKubeletData {
+		t.Errorf("unexpected kubelet contents %q", string(data))
+	}
+
+	files, err := b.PackageFiles("socat")
+	if err != nil {
+		t.Fatalf("error finding socat: %v", err)
+	}
+	if len(files) != 1 || files[0] != filepath.Join(dir, "packages/socat/socat_1.7.deb") {
+		t.Errorf("unexpected package files %v", files)
+	}
+
+	// A second extract reuses the extracted bundle
+	if _, err := Extract(tarball, dir); err != nil {
+		t.Fatalf("error re-extracting bundle: %v", err)
+	}
+}
+
+func TestBundleMissingEntries(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "nodebundle")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	b, err := Extract(buildTestBundle(t, tmp, kubeletHash(t)), filepath.Join(tmp, "extracted"))
+	if err != nil {
+		t.Fatalf("error extracting bundle: %v", err)
+	}
+
+	if _, err := b.FindSource("https://example.com/kubectl", ""); err == nil || !strings.Contains(err.Error(), "must be rebuilt") {
+		t.Errorf("expected error for missing source, got %v", err)
+	}
+	if _, err := b.FindSource(testKubeletSource, "0123456789012345678901234567890123456789"); err == nil || !strings.Contains(err.Error(), "must be rebuilt") {
+		t.Errorf("expected error for changed hash, got %v", err)
+	}
+	if _, err := b.PackageFiles("ntp"); err == nil || !strings.Contains(err.Error(), "must be rebuilt") {
+		t.Errorf("expected error for missing package, got %v", err)
+	}
+}
+
+func TestBundleHashMismatch(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "nodebundle")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	// The manifest records a different hash than the contents of the kubelet
+	tarball := buildTestBundle(t, tmp, "0123456789012345678901234567890123456789")
+	dir := filepath.Join(tmp, "extracted")
+
+	if _, err := Extract(tarball, dir); err == nil || !strings.Contains(err.Error(), "expected") {
+		t.Fatalf("expected hash mismatch error, got %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("bundle was extracted despite hash mismatch")
+	}
+}
@@ -0,0 +1,276 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundlebuilder
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/nodeup/pkg/distros"
+	"k8s.io/kops/nodeup/pkg/model"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/registry"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/pkg/nodebundle"
+	"k8s.io/kops/upup/models"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+	nodeupcommand "k8s.io/kops/upup/pkg/fi/nodeup"
+	"k8s.io/kops/upup/pkg/fi/utils"
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+// Builder builds node bundles
+type Builder struct {
+	Clientset simple.Clientset
+
+	// Distribution is the OS distribution the instance group runs
+	Distribution distros.Distribution
+	// Architecture is the machine architecture of the instance group
+	Architecture string
+	// PackagesImage is the docker image in which we download OS packages; it must have the same
+	// package repositories as the machine image. If not set we use the official image for the distribution.
+	PackagesImage string
+}
+
+// Build downloads everything nodeup needs for the instance group into dir, returning the manifest of the bundle
+func (b *Builder) Build(cluster *kops.Cluster, ig *kops.InstanceGroup, dir string) (*nodebundle.Manifest, error) {
+	glog.Infof("building node bundle for %q", ig.Name)
+
+	if b.Architecture != "amd64" {
+		return nil, fmt.Errorf("architecture %q is not supported", b.Architecture)
+	}
+	if b.Distribution.PackageManager() == distros.PackageManagerNone {
+		return nil, fmt.Errorf("node bundles are not supported on %s", b.Distribution)
+	}
+
+	// nodeup runs against the completed cluster
+	fullCluster := &kops.Cluster{}
+	{
+		configBase, err := b.Clientset.ConfigBaseFor(cluster)
+		if err != nil {
+			return nil, fmt.Errorf("error building ConfigBase for cluster: %v", err)
+		}
+
+		p := configBase.Join(registry.PathClusterCompleted)
+
+		data, err := p.ReadFile()
+		if err != nil {
+			return nil, fmt.Errorf("error loading Cluster %q: %v", p, err)
+		}
+
+		err = utils.YamlUnmarshal(data, fullCluster)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing Cluster %q: %v", p, err)
+		}
+	}
+
+	// The images are only preloaded when the instance group has a node bundle
+	ig = ig.DeepCopy()
+	if ig.Spec.NodeBundle == nil {
+		ig.Spec.NodeBundle = &kops.NodeBundleSpec{}
+	}
+
+	phase := cloudup.PhaseCluster
+	assetBuilder := assets.NewAssetBuilder(fullCluster, string(phase))
+
+	applyCmd := &cloudup.ApplyClusterCmd{
+		Cluster:        fullCluster,
+		Clientset:      b.Clientset,
+		InstanceGroups: []*kops.InstanceGroup{ig},
+		Phase:          phase,
+	}
+
+	if err := applyCmd.AddFileAssets(assetBuilder); err != nil {
+		return nil, fmt.Errorf("error adding assets: %v", err)
+	}
+
+	nodeupConfig, err := applyCmd.BuildNodeUpConfig(assetBuilder, ig)
+	if err != nil {
+		return nil, fmt.Errorf("error building nodeup config: %v", err)
+	}
+
+	manifest := &nodebundle.Manifest{
+		ClusterName:       cluster.ObjectMeta.Name,
+		InstanceGroup:     ig.ObjectMeta.Name,
+		KubernetesVersion: fullCluster.Spec.KubernetesVersion,
+		Distribution:      string(b.Distribution),
+		Architecture:      b.Architecture,
+	}
+
+	for _, asset := range nodeupConfig.Assets {
+		i := strings.Index(asset, "@")
+		if i == -1 {
+			return nil, fmt.Errorf("asset %q does not have a hash", asset)
+		}
+		if err := b.addSource(manifest, dir, asset[i+1:], asset[:i]); err != nil {
+			return nil, err
+		}
+	}
+
+	images := nodeupConfig.Images
+	if nodeupConfig.ProtokubeImage != nil {
+		images = append(images, nodeupConfig.ProtokubeImage)
+	}
+	for _, image := range images {
+		if err := b.addSource(manifest, dir, image.Source, image.Hash); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := b.addPackages(manifest, dir, fullCluster, ig, nodeupConfig); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// addSource downloads a file nodeup would download, and adds it to the manifest
+func (b *Builder) addSource(manifest *nodebundle.Manifest, dir string, source string, hashString string) error {
+	if manifest.FindSource(source) != nil {
+		return nil
+	}
+
+	hash, err := hashing.FromString(hashString)
+	if err != nil {
+		return fmt.Errorf("error parsing hash %q for %q: %v", hashString, source, err)
+	}
+
+	// We keep the name of the file, because that is how nodeup finds assets
+	p := path.Join("files", hash.Hex(), path.Base(source))
+	if _, err := fi.DownloadURL(source, filepath.Join(dir, p), hash); err != nil {
+		return fmt.Errorf("error downloading %q: %v", source, err)
+	}
+
+	manifest.Files = append(manifest.Files, &nodebundle.File{
+		Path:   p,
+		Source: source,
+		Hash:   hashString,
+	})
+	return nil
+}
+
+// addPackages downloads the packages nodeup installs, and adds them to the manifest
+func (b *Builder) addPackages(manifest *nodebundle.Manifest, dir string, cluster *kops.Cluster, ig *kops.InstanceGroup, nodeupConfig *nodeup.Config) error {
+	packages, err := nodeupcommand.RequiredPackages(nodeupConfig, cluster, ig, b.Distribution, model.Architecture(b.Architecture), models.NewAssetPath("nodeup"))
+	if err != nil {
+		return fmt.Errorf("error determining packages: %v", err)
+	}
+
+	for _, p := range packages {
+		if p.Source != nil {
+			// A package file nodeup downloads directly, rather than from the package repositories
+			if err := b.addSource(manifest, dir, fi.StringValue(p.Source), fi.StringValue(p.Hash)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		files, err := b.downloadPackage(dir, p.Name)
+		if err != nil {
+			return err
+		}
+
+		bundlePackage := &nodebundle.Package{Name: p.Name}
+		for _, f := range files {
+			hash, err := hashing.HashAlgorithmSHA256.HashFile(filepath.Join(dir, f))
+			if err != nil {
+				return fmt.Errorf("error hashing %q: %v", f, err)
+			}
+			manifest.Files = append(manifest.Files, &nodebundle.File{
+				Path: f,
+				Hash: hash.Hex(),
+			})
+			bundlePackage.Files = append(bundlePackage.Files, f)
+		}
+		manifest.Packages = append(manifest.Packages, bundlePackage)
+	}
+
+	return nil
+}
+
+// downloadPackage downloads the package and the dependencies it needs into packages/<name>,
+// returning the paths of the files relative to dir.  We run the package manager in a container,
+// so that we resolve the packages (and their dependencies) exactly as they would be resolved on the node.
+func (b *Builder) downloadPackage(dir string, name string) ([]string, error) {
+	image := b.PackagesImage
+	if image == "" {
+		image = b.Distribution.Info().PackagesImage
+		if image == "" {
+			return nil, fmt.Errorf("no default image for downloading %s packages; please specify one", b.Distribution)
+		}
+	}
+
+	packageDir := path.Join("packages", name)
+	out := "/out/" + name
+
+	var script string
+	switch b.Distribution.PackageManager() {
+	case distros.PackageManagerApt:
+		script = "mkdir -p " + out + "/partial && apt-get update && DEBIAN_FRONTEND=noninteractive apt-get install --yes --download-only --reinstall --no-install-recommends -o Dir::Cache::archives=" + out + " " + name + " && rm -rf " + out + "/partial " + out + "/lock"
+	case distros.PackageManagerYum:
+		script = "yum install -y yum-utils && yumdownloader --resolve --destdir=" + out + " " + name
+	default:
+		return nil, fmt.Errorf("packages are not supported on %s", b.Distribution)
+	}
+	// The files are written by root in the container
+	script += " && chown -R " + strconv.Itoa(os.Getuid()) + ":" + strconv.Itoa(os.Getgid()) + " " + out
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error finding absolute path for %q: %v", dir, err)
+	}
+
+	args := []string{"docker", "run", "--rm", "-v", absDir + ":/out", image, "sh", "-c", script}
+	glog.Infof("downloading package %q with %s", name, strings.Join(args, " "))
+	cmd := exec.Command(args[0], args[1:]...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error downloading package %q: %v: %s", name, err, string(output))
+	}
+
+	entries, err := ioutil.ReadDir(filepath.Join(dir, packageDir))
+	if err != nil {
+		return nil, fmt.Errorf("error reading downloaded packages for %q: %v", name, err)
+	}
+
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(e.Name(), ".deb") || strings.HasSuffix(e.Name(), ".rpm") {
+			files = append(files, path.Join(packageDir, e.Name()))
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no package files were downloaded for %q", name)
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
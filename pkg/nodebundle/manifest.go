@@ -0,0 +1,78 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodebundle
+
+// ManifestPath is the path of the manifest within a bundle; it is always the first entry in the tarball
+const ManifestPath = "manifest.yaml"
+
+// Manifest describes the contents of a node bundle: everything nodeup downloads or installs for an instance group,
+// on a particular distribution and architecture
+type Manifest struct {
+	// ClusterName is the name of the cluster the bundle was built for
+	ClusterName string `json:"clusterName,omitempty"`
+	// InstanceGroup is the name of the instance group the bundle was built for
+	InstanceGroup string `json:"instanceGroup,omitempty"`
+	// KubernetesVersion is the kubernetes version of the cluster when the bundle was built
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	// Distribution is the OS distribution the packages were resolved for, e.g. xenial
+	Distribution string `json:"distribution,omitempty"`
+	// Architecture is the machine architecture of the files and packages, e.g. amd64
+	Architecture string `json:"architecture,omitempty"`
+
+	// Files are all the files in the bundle
+	Files []*File `json:"files,omitempty"`
+	// Packages are the OS packages nodeup installs, with the package files needed to install them
+	Packages []*Package `json:"packages,omitempty"`
+}
+
+// File is a file in the bundle
+type File struct {
+	// Path is the path of the file within the bundle
+	Path string `json:"path,omitempty"`
+	// Source is the location the file was downloaded from, if nodeup would otherwise download it
+	Source string `json:"source,omitempty"`
+	// Hash is the hash of the file; it is the hash nodeup expects for the source, when there is one
+	Hash string `json:"hash,omitempty"`
+}
+
+// Package is an OS package in the bundle
+type Package struct {
+	// Name is the name of the package, as nodeup would install it from the package repositories
+	Name string `json:"name,omitempty"`
+	// Files are the paths of the package files (the package and any dependencies not in the base OS) within the bundle
+	Files []string `json:"files,omitempty"`
+}
+
+// FindSource returns the file that was downloaded from source, or nil if there is none
+func (m *Manifest) FindSource(source string) *File {
+	for _, f := range m.Files {
+		if f.Source == source {
+			return f
+		}
+	}
+	return nil
+}
+
+// FindPackage returns the package with the given name, or nil if there is none
+func (m *Manifest) FindPackage(name string) *Package {
+	for _, p := range m.Packages {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodebundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/upup/pkg/fi/utils"
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+// Bundle is a node bundle that has been extracted to the local disk
+type Bundle struct {
+	// Manifest describes the contents of the bundle
+	Manifest *Manifest
+	// Dir is the directory into which the bundle was extracted
+	Dir string
+}
+
+// Extract extracts the bundle tarball into dir, verifying the hashes of all the files.
+// If dir already exists it is assumed to hold the verified contents of the same bundle.
+func Extract(tarball string, dir string) (*Bundle, error) {
+	if _, err := os.Stat(dir); err == nil {
+		return Load(dir)
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error checking for extracted bundle %q: %v", dir, err)
+	}
+
+	// We extract to a temporary dir which we then rename so this is atomic
+	extractedTemp := dir + ".tmp-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	if err := os.MkdirAll(extractedTemp, 0755); err != nil {
+		return nil, fmt.Errorf("error creating directories %q: %v", extractedTemp, err)
+	}
+	defer os.RemoveAll(extractedTemp)
+
+	if err := extractTarball(tarball, extractedTemp); err != nil {
+		return nil, err
+	}
+
+	b, err := Load(extractedTemp)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.verify(); err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(extractedTemp, dir); err != nil {
+		return nil, fmt.Errorf("error renaming extracted temp dir %s -> %s: %v", extractedTemp, dir, err)
+	}
+	b.Dir = dir
+	return b, nil
+}
+
+// Load reads the manifest of a bundle that has already been extracted into dir
+func Load(dir string) (*Bundle, error) {
+	p := filepath.Join(dir, ManifestPath)
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("error reading bundle manifest %q: %v", p, err)
+	}
+
+	manifest := &Manifest{}
+	if err := utils.YamlUnmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("error parsing bundle manifest %q: %v", p, err)
+	}
+
+	return &Bundle{Manifest: manifest, Dir: dir}, nil
+}
+
+// extractTarball writes the files in the gzipped tarball into dir
+func extractTarball(tarball string, dir string) error {
+	f, err := os.Open(tarball)
+	if err != nil {
+		return fmt.Errorf("error opening bundle %q: %v", tarball, err)
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("error reading bundle %q: %v", tarball, err)
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading bundle %q: %v", tarball, err)
+		}
+
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA {
+			return fmt.Errorf("unexpected entry %q in bundle %q", header.Name, tarball)
+		}
+
+		name := path.Clean(header.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid path %q in bundle %q", header.Name, tarball)
+		}
+
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			return fmt.Errorf("error creating directories %q: %v", filepath.Dir(p), err)
+		}
+		if err := writeExtractedFile(p, tr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeExtractedFile(p string, r io.Reader) error {
+	out, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("error creating %q: %v", p, err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, r); err != nil {
+		return fmt.Errorf("error writing %q: %v", p, err)
+	}
+	return nil
+}
+
+// verify checks that every file in the manifest is present and matches its hash
+func (b *Bundle) verify() error {
+	for _, f := range b.Manifest.Files {
+		expected, err := hashing.FromString(f.Hash)
+		if err != nil {
+			return fmt.Errorf("invalid hash for %q in bundle manifest: %v", f.Path, err)
+		}
+
+		actual, err := expected.Algorithm.HashFile(b.LocalPath(f))
+		if err != nil {
+			return fmt.Errorf("error hashing %q from bundle: %v", f.Path, err)
+		}
+		if !actual.Equal(expected) {
+			return fmt.Errorf("hash of %q from bundle was %s, expected %s", f.Path, actual, expected)
+		}
+	}
+
+	for _, p := range b.Manifest.Packages {
+		for _, f := range p.Files {
+			if b.findPath(f) == nil {
+				return fmt.Errorf("file %q for package %q is not in the bundle manifest", f, p.Name)
+			}
+		}
+	}
+
+	glog.V(2).Infof("verified %d files in bundle", len(b.Manifest.Files))
+	return nil
+}
+
+// LocalPath returns the path of the extracted file
+func (b *Bundle) LocalPath(f *File) string {
+	return filepath.Join(b.Dir, filepath.FromSlash(f.Path))
+}
+
+// findPath returns the file with the given path within the bundle, or nil if there is none
+func (b *Bundle) findPath(p string) *File {
+	for _, f := range b.Manifest.Files {
+		if f.Path == p {
+			return f
+		}
+	}
+	return nil
+}
+
+// FindSource returns the local path of the file that was downloaded from source.
+// If hash is not empty, the file must have been downloaded with that hash.
+func (b *Bundle) FindSource(source string, hash string) (string, error) {
+	f := b.Manifest.FindSource(source)
+	if f == nil {
+		return "", fmt.Errorf("%q is not in the node bundle; the bundle must be rebuilt", source)
+	}
+
+	if hash != "" {
+		expected, err := hashing.FromString(hash)
+		if err != nil {
+			return "", fmt.Errorf("error parsing hash %q for %q: %v", hash, source, err)
+		}
+		actual, err := hashing.FromString(f.Hash)
+		if err != nil {
+			return "", fmt.Errorf("invalid hash for %q in bundle manifest: %v", f.Path, err)
+		}
+		if !actual.Equal(expected) {
+			return "", fmt.Errorf("%q in the node bundle has hash %s, but %s is expected; the bundle must be rebuilt", source, actual, expected)
+		}
+	}
+
+	return b.LocalPath(f), nil
+}
+
+// PackageFiles returns the local paths of the package files needed to install the named package
+func (b *Bundle) PackageFiles(name string) ([]string, error) {
+	p := b.Manifest.FindPackage(name)
+	if p == nil {
+		return nil, fmt.Errorf("package %q is not in the node bundle; the bundle must be rebuilt", name)
+	}
+
+	var files []string
+	for _, f := range p.Files {
+		files = append(files, filepath.Join(b.Dir, filepath.FromSlash(f)))
+	}
+	return files, nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodebundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"k8s.io/kops/upup/pkg/fi/utils"
+)
+
+// Write writes a bundle to out as a gzipped tarball: the manifest, followed by the files it lists, which are read from dir
+func Write(out io.Writer, manifest *Manifest, dir string) error {
+	gw := gzip.NewWriter(out)
+	tw := tar.NewWriter(gw)
+
+	data, err := utils.YamlMarshal(manifest)
+	if err != nil {
+		return fmt.Errorf("error serializing bundle manifest: %v", err)
+	}
+	header := &tar.Header{
+		Name: ManifestPath,
+		Mode: 0644,
+		Size: int64(len(data)),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("error writing tar file header: %v", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("error writing tar file data: %v", err)
+	}
+
+	for _, file := range manifest.Files {
+		if err := writeFile(tw, dir, file.Path); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("error writing tar file: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		return fmt.Errorf("error writing gzip file: %v", err)
+	}
+	return nil
+}
+
+// writeFile adds the file at relativePath within dir to the tarball
+func writeFile(tw *tar.Writer, dir string, relativePath string) error {
+	p := filepath.Join(dir, relativePath)
+	f, err := os.Open(p)
+	if err != nil {
+		return fmt.Errorf("error opening %q: %v", p, err)
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("error reading %q: %v", p, err)
+	}
+
+	header := &tar.Header{
+		Name:    relativePath,
+		Mode:    0644,
+		Size:    stat.Size(),
+		ModTime: stat.ModTime(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("error writing tar file header: %v", err)
+	}
+	if _, err := io.Copy(tw, f); err != nil {
+		return fmt.Errorf("error writing %q to tar file: %v", p, err)
+	}
+	return nil
+}
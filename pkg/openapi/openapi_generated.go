@@ -30112,6 +30112,204 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 			Dependencies: []string{
 				"k8s.io/kops/pkg/apis/kops/v1alpha2.AmazonVPCNetworkingSpec", "k8s.io/kops/pkg/apis/kops/v1alpha2.CNINetworkingSpec", "k8s.io/kops/pkg/apis/kops/v1alpha2.CalicoNetworkingSpec", "k8s.io/kops/pkg/apis/kops/v1alpha2.CanalNetworkingSpec", "k8s.io/kops/pkg/apis/kops/v1alpha2.CiliumNetworkingSpec", "k8s.io/kops/pkg/apis/kops/v1alpha2.ClassicNetworkingSpec", "k8s.io/kops/pkg/apis/kops/v1alpha2.ExternalNetworkingSpec", "k8s.io/kops/pkg/apis/kops/v1alpha2.FlannelNetworkingSpec", "k8s.io/kops/pkg/apis/kops/v1alpha2.KopeioNetworkingSpec", "k8s.io/kops/pkg/apis/kops/v1alpha2.KubenetNetworkingSpec", "k8s.io/kops/pkg/apis/kops/v1alpha2.KuberouterNetworkingSpec", "k8s.io/kops/pkg/apis/kops/v1alpha2.RomanaNetworkingSpec", "k8s.io/kops/pkg/apis/kops/v1alpha2.WeaveNetworkingSpec"},
 		},
+		"k8s.io/kops/pkg/apis/kops/v1alpha2.Operation": {
+			Schema: spec.Schema{
+				SchemaProps: spec.SchemaProps{
+					Description: "Operation records an action (such as an update or a rolling-update) that the kops server runs against a cluster",
+					Properties: map[string]spec.Schema{
+						"kind": {
+							SchemaProps: spec.SchemaProps{
+								Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+								Type:        []string{"string"},
+								Format:      "",
+							},
+						},
+						"apiVersion": {
+							SchemaProps: spec.SchemaProps{
+								Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+								Type:        []string{"string"},
+								Format:      "",
+							},
+						},
+						"metadata": {
+							SchemaProps: spec.SchemaProps{
+								Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+							},
+						},
+						"spec": {
+							SchemaProps: spec.SchemaProps{
+								Ref: ref("k8s.io/kops/pkg/apis/kops/v1alpha2.OperationSpec"),
+							},
+						},
+						"status": {
+							SchemaProps: spec.SchemaProps{
+								Ref: ref("k8s.io/kops/pkg/apis/kops/v1alpha2.OperationStatus"),
+							},
+						},
+					},
+				},
+			},
+			Dependencies: []string{
+				"k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta", "k8s.io/kops/pkg/apis/kops/v1alpha2.OperationSpec", "k8s.io/kops/pkg/apis/kops/v1alpha2.OperationStatus"},
+		},
+		"k8s.io/kops/pkg/apis/kops/v1alpha2.OperationList": {
+			Schema: spec.Schema{
+				SchemaProps: spec.SchemaProps{
+					Properties: map[string]spec.Schema{
+						"kind": {
+							SchemaProps: spec.SchemaProps{
+								Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+								Type:        []string{"string"},
+								Format:      "",
+							},
+						},
+						"apiVersion": {
+							SchemaProps: spec.SchemaProps{
+								Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+								Type:        []string{"string"},
+								Format:      "",
+							},
+						},
+						"metadata": {
+							SchemaProps: spec.SchemaProps{
+								Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+							},
+						},
+						"items": {
+							SchemaProps: spec.SchemaProps{
+								Type: []string{"array"},
+								Items: &spec.SchemaOrArray{
+									Schema: &spec.Schema{
+										SchemaProps: spec.SchemaProps{
+											Ref: ref("k8s.io/kops/pkg/apis/kops/v1alpha2.Operation"),
+										},
+									},
+								},
+							},
+						},
+					},
+					Required: []string{"items"},
+				},
+			},
+			Dependencies: []string{
+				"k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta", "k8s.io/kops/pkg/apis/kops/v1alpha2.Operation"},
+		},
+		"k8s.io/kops/pkg/apis/kops/v1alpha2.OperationSpec": {
+			Schema: spec.Schema{
+				SchemaProps: spec.SchemaProps{
+					Description: "OperationSpec describes the action requested",
+					Properties: map[string]spec.Schema{
+						"clusterName": {
+							SchemaProps: spec.SchemaProps{
+								Description: "ClusterName is the name of the cluster the operation acts on",
+								Type:        []string{"string"},
+								Format:      "",
+							},
+						},
+						"action": {
+							SchemaProps: spec.SchemaProps{
+								Description: "Action is the action performed",
+								Type:        []string{"string"},
+								Format:      "",
+							},
+						},
+						"user": {
+							SchemaProps: spec.SchemaProps{
+								Description: "User is the name of the user that requested the operation",
+								Type:        []string{"string"},
+								Format:      "",
+							},
+						},
+						"instanceGroups": {
+							SchemaProps: spec.SchemaProps{
+								Description: "InstanceGroups restricts a rolling-update to the named instance groups",
+								Type:        []string{"array"},
+								Items: &spec.SchemaOrArray{
+									Schema: &spec.Schema{
+										SchemaProps: spec.SchemaProps{
+											Type:   []string{"string"},
+											Format: "",
+										},
+									},
+								},
+							},
+						},
+						"force": {
+							SchemaProps: spec.SchemaProps{
+								Description: "Force replaces instances in a rolling-update even if they are up to date",
+								Type:        []string{"boolean"},
+								Format:      "",
+							},
+						},
+						"cloudOnly": {
+							SchemaProps: spec.SchemaProps{
+								Description: "CloudOnly performs a rolling-update without draining nodes or validating the cluster",
+								Type:        []string{"boolean"},
+								Format:      "",
+							},
+						},
+					},
+				},
+			},
+			Dependencies: []string{},
+		},
+		"k8s.io/kops/pkg/apis/kops/v1alpha2.OperationStatus": {
+			Schema: spec.Schema{
+				SchemaProps: spec.SchemaProps{
+					Description: "OperationStatus reports the progress and the result of an operation",
+					Properties: map[string]spec.Schema{
+						"phase": {
+							SchemaProps: spec.SchemaProps{
+								Description: "Phase is the lifecycle phase of the operation",
+								Type:        []string{"string"},
+								Format:      "",
+							},
+						},
+						"startTime": {
+							SchemaProps: spec.SchemaProps{
+								Description: "StartTime is the time the operation started running",
+								Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+							},
+						},
+						"completionTime": {
+							SchemaProps: spec.SchemaProps{
+								Description: "CompletionTime is the time the operation succeeded or failed",
+								Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+							},
+						},
+						"message": {
+							SchemaProps: spec.SchemaProps{
+								Description: "Message is a human readable description of the result, set when the operation completes",
+								Type:        []string{"string"},
+								Format:      "",
+							},
+						},
+						"output": {
+							SchemaProps: spec.SchemaProps{
+								Description: "Output is the output of the action, as the equivalent kops command would print it",
+								Type:        []string{"string"},
+								Format:      "",
+							},
+						},
+						"owner": {
+							SchemaProps: spec.SchemaProps{
+								Description: "Owner identifies the kops-server instance running the operation",
+								Type:        []string{"string"},
+								Format:      "",
+							},
+						},
+						"renewTime": {
+							SchemaProps: spec.SchemaProps{
+								Description: "RenewTime is the last time the owner confirmed it is still running the operation. An unfinished operation whose owner stops renewing it is marked as failed by the other instances.",
+								Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+							},
+						},
+					},
+				},
+			},
+			Dependencies: []string{
+				"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+		},
 		"k8s.io/kops/pkg/apis/kops/v1alpha2.RBACAuthorizationSpec": {
 			Schema: spec.Schema{
 				SchemaProps: spec.SchemaProps{
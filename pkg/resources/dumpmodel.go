@@ -16,6 +16,8 @@ limitations under the License.
 
 package resources
 
+import "k8s.io/kops/pkg/apis/nodeup"
+
 // Instance is the type for an instance in a dump
 type Instance struct {
 	Name            string   `json:"name,omitempty"`
@@ -40,4 +42,7 @@ type Dump struct {
 	Instances []*Instance   `json:"instances,omitempty"`
 	Subnets   []*Subnet     `json:"subnets,omitempty"`
 	VPC       *VPC          `json:"vpc,omitempty"`
+
+	// BootReports are the nodeup boot reports uploaded by the nodes, if UploadBootReports is enabled
+	BootReports []*nodeup.BootReport `json:"bootReports,omitempty"`
 }
@@ -30,7 +30,8 @@ import (
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
 	"k8s.io/kops/protokube/pkg/gossip"
 	gossipdns "k8s.io/kops/protokube/pkg/gossip/dns"
-	"k8s.io/kops/protokube/pkg/gossip/mesh"
+	"k8s.io/kops/protokube/pkg/gossip/protocols"
+	gossipsrv "k8s.io/kops/protokube/pkg/gossip/srv"
 	"k8s.io/kops/protokube/pkg/protokube"
 	// Load DNS plugins
 	"github.com/golang/glog"
@@ -60,7 +61,8 @@ func main() {
 func run() error {
 	var zones []string
 	var applyTaints, initializeRBAC, containerized, master, tlsAuth bool
-	var cloud, clusterID, dnsServer, dnsProviderID, dnsInternalSuffix, gossipSecret, gossipListen string
+	var cloud, clusterID, dnsServer, dnsProviderID, dnsInternalSuffix, gossipSecret, gossipSecretSecondary, gossipListen, gossipStatusListen string
+	var gossipProtocol, gossipProtocolSecondary, gossipListenSecondary, gossipSeedSRV string
 	var flagChannels, tlsCert, tlsKey, tlsCA, peerCert, peerKey, peerCA string
 	var etcdBackupImage, etcdBackupStore, etcdImageSource, etcdElectionTimeout, etcdHeartbeatInterval string
 
@@ -68,12 +70,14 @@ func run() error {
 	flag.BoolVar(&containerized, "containerized", containerized, "Set if we are running containerized.")
 	flag.BoolVar(&initializeRBAC, "initialize-rbac", initializeRBAC, "Set if we should initialize RBAC")
 	flag.BoolVar(&master, "master", master, "Whether or not this node is a master")
-	flag.StringVar(&cloud, "cloud", "aws", "CloudProvider we are using (aws,digitalocean,gce)")
+	flag.StringVar(&cloud, "cloud", "aws", "CloudProvider we are using (aws,digitalocean,gce,openstack,vsphere,baremetal)")
 	flag.StringVar(&clusterID, "cluster-id", clusterID, "Cluster ID")
 	flag.StringVar(&dnsInternalSuffix, "dns-internal-suffix", dnsInternalSuffix, "DNS suffix for internal domain names")
 	flag.StringVar(&dnsServer, "dns-server", dnsServer, "DNS Server")
 	flag.StringVar(&flagChannels, "channels", flagChannels, "channels to install")
 	flag.StringVar(&gossipListen, "gossip-listen", "0.0.0.0:3999", "address:port on which to bind for gossip")
+	flag.StringVar(&gossipListenSecondary, "gossip-listen-secondary", "0.0.0.0:4000", "address:port on which to bind for gossip using the secondary protocol")
+	flag.StringVar(&gossipStatusListen, "gossip-status-listen", "127.0.0.1:3997", "address:port on which to serve the read-only gossip status, or empty to disable")
 	flag.StringVar(&peerCA, "peer-ca", peerCA, "Path to a file containing the peer ca in PEM format")
 	flag.StringVar(&peerCert, "peer-cert", peerCert, "Path to a file containing the peer certificate")
 	flag.StringVar(&peerKey, "peer-key", peerKey, "Path to a file containing the private key for the peers")
@@ -89,6 +93,10 @@ func run() error {
 	flags.StringVar(&etcdElectionTimeout, "etcd-election-timeout", etcdElectionTimeout, "time in ms for an election to timeout")
 	flags.StringVar(&etcdHeartbeatInterval, "etcd-heartbeat-interval", etcdHeartbeatInterval, "time in ms of a heartbeat interval")
 	flags.StringVar(&gossipSecret, "gossip-secret", gossipSecret, "Secret to use to secure gossip")
+	flags.StringVar(&gossipSecretSecondary, "gossip-secret-secondary", gossipSecretSecondary, "If set, gossip with peers using this secret as well as --gossip-secret, while the secret is rotated")
+	flags.StringVar(&gossipProtocol, "gossip-protocol", "mesh", "Gossip protocol to use (mesh, memberlist)")
+	flags.StringVar(&gossipSeedSRV, "gossip-seed-srv", gossipSeedSRV, "DNS SRV record listing the gossip seeds, e.g. _gossip._tcp.example.com; by default seeds are discovered from the cloud, or from _gossip._tcp.<cluster-id> on vsphere and baremetal")
+	flags.StringVar(&gossipProtocolSecondary, "gossip-protocol-secondary", "", "If set, also gossip using this protocol on --gossip-listen-secondary, copying values between the two, while the protocol is changed")
 
 	// Trick to avoid 'logging before flag.Parse' warning
 	flag.CommandLine.Parse([]string{})
@@ -164,7 +172,7 @@ func run() error {
 			internalIP = vsphereVolumes.InternalIp()
 		}
 
-	} else if cloud == "baremetal" {
+	} else if cloud == "baremetal" || cloud == "openstack" {
 		if internalIP == nil {
 			ip, err := findInternalIP()
 			if err != nil {
@@ -214,23 +222,9 @@ func run() error {
 			Path: path.Join(rootfs, "etc/hosts"),
 		}
 
-		var gossipSeeds gossip.SeedProvider
-		var err error
-		var gossipName string
-		if cloud == "aws" {
-			gossipSeeds, err = volumes.(*protokube.AWSVolumes).GossipSeeds()
-			if err != nil {
-				return err
-			}
-			gossipName = volumes.(*protokube.AWSVolumes).InstanceID()
-		} else if cloud == "gce" {
-			gossipSeeds, err = volumes.(*protokube.GCEVolumes).GossipSeeds()
-			if err != nil {
-				return err
-			}
-			gossipName = volumes.(*protokube.GCEVolumes).InstanceName()
-		} else {
-			glog.Fatalf("seed provider for %q not yet implemented", cloud)
+		gossipSeeds, gossipName, err := buildGossipSeeds(cloud, volumes, clusterID, gossipSeedSRV)
+		if err != nil {
+			return err
 		}
 
 		id := os.Getenv("HOSTNAME")
@@ -238,13 +232,44 @@ func run() error {
 			glog.Warningf("Unable to fetch HOSTNAME for use as node identifier")
 		}
 
+		// An empty secondary secret is valid: it is the secret of a cluster which has never set one
+		var secondarySecret []byte
+		if flags.Changed("gossip-secret-secondary") {
+			secondarySecret = []byte(gossipSecretSecondary)
+		}
+
+		gossipConfig := &protocols.Config{
+			Protocol:        gossipProtocol,
+			Listen:          gossipListen,
+			Secret:          []byte(gossipSecret),
+			SecondarySecret: secondarySecret,
+			Seeds:           gossipSeeds,
+		}
+		var gossipConfigSecondary *protocols.Config
+		if gossipProtocolSecondary != "" {
+			gossipConfigSecondary = &protocols.Config{
+				Protocol:        gossipProtocolSecondary,
+				Listen:          gossipListenSecondary,
+				Secret:          []byte(gossipSecret),
+				SecondarySecret: secondarySecret,
+				Seeds:           gossipSeeds,
+			}
+		}
+
 		channelName := "dns"
-		gossipState, err := mesh.NewMeshGossiper(gossipListen, channelName, gossipName, []byte(gossipSecret), gossipSeeds)
+		gossipState, err := protocols.NewBridgedGossiper(gossipConfig, gossipConfigSecondary, channelName, gossipName)
 		if err != nil {
 			glog.Errorf("Error initializing gossip: %v", err)
 			os.Exit(1)
 		}
 
+		if gossipStatusListen != "" {
+			go func() {
+				err := gossip.ListenAndServeStatus(gossipStatusListen, gossipState)
+				glog.Errorf("gossip status server exited: %v", err)
+			}()
+		}
+
 		go func() {
 			err := gossipState.Start()
 			if err != nil {
@@ -290,7 +315,7 @@ func run() error {
 				return fmt.Errorf("unexpected zone flags: %q", err)
 			}
 
-			dnsController, err = dns.NewDNSController([]dnsprovider.Interface{dnsProvider}, zoneRules)
+			dnsController, err = dns.NewDNSController([]dnsprovider.Interface{dnsProvider}, zoneRules, "", nil, nil)
 			if err != nil {
 				return err
 			}
@@ -351,6 +376,59 @@ func run() error {
 	return fmt.Errorf("Unexpected exit")
 }
 
+// buildGossipSeeds returns the seed provider for the cloud, and the name of this node in the gossip network
+func buildGossipSeeds(cloud string, volumes protokube.Volumes, clusterID string, seedSRV string) (gossip.SeedProvider, string, error) {
+	var gossipName string
+	switch cloud {
+	case "aws":
+		gossipName = volumes.(*protokube.AWSVolumes).InstanceID()
+	case "gce":
+		gossipName = volumes.(*protokube.GCEVolumes).InstanceName()
+	case "digitalocean":
+		gossipName = volumes.(*protokube.DOVolumes).DropletName()
+	default:
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, "", fmt.Errorf("error getting hostname: %v", err)
+		}
+		gossipName = hostname
+	}
+
+	// An explicit SRV record takes precedence over discovery through the cloud
+	if seedSRV != "" {
+		glog.Infof("Discovering gossip seeds from DNS SRV record %q", seedSRV)
+		seeds, err := gossipsrv.NewSeedProvider(seedSRV)
+		if err != nil {
+			return nil, "", err
+		}
+		return seeds, gossipName, nil
+	}
+
+	var seeds gossip.SeedProvider
+	var err error
+	switch cloud {
+	case "aws":
+		seeds, err = volumes.(*protokube.AWSVolumes).GossipSeeds()
+	case "gce":
+		seeds, err = volumes.(*protokube.GCEVolumes).GossipSeeds()
+	case "digitalocean":
+		seeds, err = volumes.(*protokube.DOVolumes).GossipSeeds()
+	case "openstack":
+		seeds, err = protokube.OpenstackGossipSeeds(clusterID)
+	case "vsphere", "baremetal":
+		// There is no API we can query for our peers, so we look them up in DNS
+		name := "_gossip._tcp." + clusterID
+		glog.Infof("Discovering gossip seeds from DNS SRV record %q", name)
+		seeds, err = gossipsrv.NewSeedProvider(name)
+	default:
+		return nil, "", fmt.Errorf("seed provider for %q not yet implemented; set --gossip-seed-srv", cloud)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("error building gossip seed provider: %v", err)
+	}
+	return seeds, gossipName, nil
+}
+
 // findInternalIP attempts to discover the internal IP address by inspecting the network interfaces
 func findInternalIP() (net.IP, error) {
 	var ips []net.IP
@@ -0,0 +1,148 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gossip
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// bridgeSyncInterval is how often the Bridge copies values between the gossip networks
+const bridgeSyncInterval = 10 * time.Second
+
+// Bridge is a member of several gossip networks, normally using different protocols, which copies values between
+// them.  It lets a cluster move to another gossip protocol online: while the nodes are updated, the updated nodes
+// are members of both networks, and the values gossiped on either network reach every node.
+//
+// Values are copied with their versions, so a value is only copied to a network where it is missing or older,
+// and copying values back and forth converges.
+type Bridge struct {
+	// gossipers are the members of each network; the first is the primary, whose values we return
+	gossipers []Gossiper
+
+	// mutex serializes the copying of values
+	mutex sync.Mutex
+}
+
+var _ Gossiper = &Bridge{}
+
+// NewBridge builds a Bridge between the networks of primary and secondaries
+func NewBridge(primary Gossiper, secondaries ...Gossiper) *Bridge {
+	return &Bridge{
+		gossipers: append([]Gossiper{primary}, secondaries...),
+	}
+}
+
+// Start starts every gossiper, and then copies values between them until one of them stops
+func (b *Bridge) Start() error {
+	stopped := make(chan error, len(b.gossipers))
+	for _, g := range b.gossipers {
+		go func(g Gossiper) {
+			err := g.Start()
+			if err == nil {
+				err = fmt.Errorf("gossip exited unexpectedly, but without error")
+			}
+			stopped <- err
+		}(g)
+	}
+
+	ticker := time.NewTicker(bridgeSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-stopped:
+			return err
+		case <-ticker.C:
+			b.sync()
+		}
+	}
+}
+
+// sync copies every value to the networks where it is missing or has an older version
+func (b *Bridge) sync() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	values := make([]map[string]VersionedValue, len(b.gossipers))
+	newest := make(map[string]VersionedValue)
+	for i, g := range b.gossipers {
+		values[i] = g.VersionedValues()
+		for k, v := range values[i] {
+			if existing, found := newest[k]; !found || v.Version > existing.Version {
+				newest[k] = v
+			}
+		}
+	}
+
+	for i, g := range b.gossipers {
+		changes := make(map[string]VersionedValue)
+		for k, v := range newest {
+			if existing, found := values[i][k]; !found || existing.Version < v.Version {
+				changes[k] = v
+			}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		glog.V(2).Infof("copying %d gossip values to network %d", len(changes), i)
+		if err := g.MergeVersionedValues(changes); err != nil {
+			glog.Warningf("error copying gossip values to network %d: %v", i, err)
+		}
+	}
+}
+
+// Snapshot implements GossipState, returning the values of the primary network
+func (b *Bridge) Snapshot() *GossipStateSnapshot {
+	return b.gossipers[0].Snapshot()
+}
+
+// UpdateValues implements GossipState, updating the primary network and copying the changes to the others
+func (b *Bridge) UpdateValues(removeKeys []string, putEntries map[string]string) error {
+	if err := b.gossipers[0].UpdateValues(removeKeys, putEntries); err != nil {
+		return err
+	}
+	b.sync()
+	return nil
+}
+
+// VersionedValues implements VersionedGossipState, returning the values of the primary network
+func (b *Bridge) VersionedValues() map[string]VersionedValue {
+	return b.gossipers[0].VersionedValues()
+}
+
+// MergeVersionedValues implements VersionedGossipState, merging the values into every network
+func (b *Bridge) MergeVersionedValues(values map[string]VersionedValue) error {
+	for _, g := range b.gossipers {
+		if err := g.MergeVersionedValues(values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Status implements GossipStatusProvider, listing the networks of every gossiper
+func (b *Bridge) Status() *GossipStatus {
+	status := b.gossipers[0].Status()
+	for _, g := range b.gossipers[1:] {
+		status.Meshes = append(status.Meshes, g.Status().Meshes...)
+	}
+	return status
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gossip
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// fakeGossiper is an in-memory Gossiper, with a last-write-wins map of values
+type fakeGossiper struct {
+	mutex    sync.Mutex
+	protocol string
+	clock    *uint64
+	values   map[string]VersionedValue
+	merges   int
+}
+
+func newFakeGossiper(protocol string, clock *uint64) *fakeGossiper {
+	return &fakeGossiper{protocol: protocol, clock: clock, values: make(map[string]VersionedValue)}
+}
+
+func (g *fakeGossiper) Start() error {
+	select {}
+}
+
+func (g *fakeGossiper) Snapshot() *GossipStateSnapshot {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	snapshot := &GossipStateSnapshot{Values: make(map[string]string)}
+	for k, v := range g.values {
+		if !v.Deleted {
+			snapshot.Values[k] = v.Value
+		}
+	}
+	return snapshot
+}
+
+func (g *fakeGossiper) UpdateValues(removeKeys []string, putEntries map[string]string) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	*g.clock++
+	for _, k := range removeKeys {
+		g.values[k] = VersionedValue{Version: *g.clock, Deleted: true}
+	}
+	for k, v := range putEntries {
+		g.values[k] = VersionedValue{Value: v, Version: *g.clock}
+	}
+	return nil
+}
+
+func (g *fakeGossiper) VersionedValues() map[string]VersionedValue {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	values := make(map[string]VersionedValue)
+	for k, v := range g.values {
+		values[k] = v
+	}
+	return values
+}
+
+func (g *fakeGossiper) MergeVersionedValues(values map[string]VersionedValue) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.merges++
+	for k, v := range values {
+		if existing, found := g.values[k]; found && existing.Version >= v.Version {
+			continue
+		}
+		g.values[k] = v
+	}
+	return nil
+}
+
+func (g *fakeGossiper) Status() *GossipStatus {
+	return &GossipStatus{Meshes: []GossipMeshStatus{{Protocol: g.protocol}}}
+}
+
+func TestBridgeCopiesValues(t *testing.T) {
+	var clock uint64
+	primary := newFakeGossiper("memberlist", &clock)
+	secondary := newFakeGossiper("mesh", &clock)
+	bridge := NewBridge(primary, secondary)
+
+	// A value gossiped on the old network reaches the new network
+	secondary.UpdateValues(nil, map[string]string{"a": "1"})
+	bridge.sync()
+	if v := bridge.Snapshot().Values["a"]; v != "1" {
+		t.Fatalf("expected value from secondary network, got %q", v)
+	}
+
+	// Our own updates reach both networks
+	if err := bridge.UpdateValues(nil, map[string]string{"b": "2"}); err != nil {
+		t.Fatalf("error updating values: %v", err)
+	}
+	if v := secondary.Snapshot().Values["b"]; v != "2" {
+		t.Fatalf("expected our value on the secondary network, got %q", v)
+	}
+
+	// Versions are preserved, so both networks end up identical
+	if !reflect.DeepEqual(primary.VersionedValues(), secondary.VersionedValues()) {
+		t.Fatalf("networks differ: %v and %v", primary.VersionedValues(), secondary.VersionedValues())
+	}
+
+	// Once converged, syncing copies nothing
+	primaryMerges, secondaryMerges := primary.merges, secondary.merges
+	bridge.sync()
+	if primary.merges != primaryMerges || secondary.merges != secondaryMerges {
+		t.Errorf("converged bridge copied values again")
+	}
+}
+
+func TestBridgeNewestVersionWins(t *testing.T) {
+	var clock uint64
+	primary := newFakeGossiper("memberlist", &clock)
+	secondary := newFakeGossiper("mesh", &clock)
+	bridge := NewBridge(primary, secondary)
+
+	primary.UpdateValues(nil, map[string]string{"a": "old", "b": "keep"})
+	secondary.UpdateValues(nil, map[string]string{"a": "new"})
+	secondary.UpdateValues([]string{"b"}, nil)
+	bridge.sync()
+
+	for name, g := range map[string]*fakeGossiper{"primary": primary, "secondary": secondary} {
+		values := g.Snapshot().Values
+		if values["a"] != "new" {
+			t.Errorf("%s: expected newest value, got %q", name, values["a"])
+		}
+		if v, found := values["b"]; found {
+			t.Errorf("%s: expected removal to win, got %q", name, v)
+		}
+	}
+}
+
+func TestBridgeStatus(t *testing.T) {
+	var clock uint64
+	bridge := NewBridge(newFakeGossiper("memberlist", &clock), newFakeGossiper("mesh", &clock))
+
+	status := bridge.Status()
+	var protocols []string
+	for _, m := range status.Meshes {
+		protocols = append(protocols, m.Protocol)
+	}
+	if !reflect.DeepEqual(protocols, []string{"memberlist", "mesh"}) {
+		t.Errorf("unexpected networks %v", protocols)
+	}
+}
@@ -38,7 +38,7 @@ func (h *HostsFile) Update(snapshot *DNSViewSnapshot) error {
 		records := snapshot.RecordsForZone(zone)
 
 		for _, record := range records {
-			if record.RrsType != "A" {
+			if record.RrsType != "A" && record.RrsType != "AAAA" {
 				glog.Warningf("skipping record of unhandled type: %v", record)
 				continue
 			}
@@ -0,0 +1,85 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package do
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/digitalocean/godo"
+	"github.com/golang/glog"
+	"k8s.io/kops/protokube/pkg/gossip"
+)
+
+// Each page can have 200 results, but we cap how many pages
+// are iterated through to prevent infinite loops if the API
+// were to continuously return a next page.
+const maxPages = 100
+
+// SeedProvider discovers the gossip seeds from the droplets with the tag of the cluster
+type SeedProvider struct {
+	droplets godo.DropletsService
+	tag      string
+}
+
+var _ gossip.SeedProvider = &SeedProvider{}
+
+func (p *SeedProvider) GetSeeds() ([]string, error) {
+	var seeds []string
+
+	opt := &godo.ListOptions{PerPage: 200}
+	page := 0
+	for ; page < maxPages; page++ {
+		droplets, resp, err := p.droplets.ListByTag(context.TODO(), p.tag, opt)
+		if err != nil {
+			return nil, fmt.Errorf("error listing droplets with tag %q: %v", p.tag, err)
+		}
+
+		for i := range droplets {
+			ip, err := droplets[i].PrivateIPv4()
+			if err != nil {
+				glog.Warningf("error getting private IP of droplet %q: %v", droplets[i].Name, err)
+				continue
+			}
+			if ip != "" {
+				seeds = append(seeds, ip)
+			}
+		}
+
+		if resp == nil || resp.Links == nil || resp.Links.IsLastPage() {
+			break
+		}
+		current, err := resp.Links.CurrentPage()
+		if err != nil {
+			return nil, fmt.Errorf("error paging droplets with tag %q: %v", p.tag, err)
+		}
+		opt.Page = current + 1
+	}
+	if page >= maxPages {
+		glog.Errorf("GetSeeds exceeded maxPages=%d for Droplets.ListByTag: truncating.", maxPages)
+	}
+
+	return seeds, nil
+}
+
+// NewSeedProvider builds a SeedProvider for the droplets with the tag, e.g. KubernetesCluster:example-k8s-local
+func NewSeedProvider(droplets godo.DropletsService, tag string) (*SeedProvider, error) {
+	return &SeedProvider{
+		droplets: droplets,
+		tag:      tag,
+	}, nil
+}
@@ -25,3 +25,31 @@ type GossipState interface {
 	Snapshot() *GossipStateSnapshot
 	UpdateValues(removeKeys []string, putKeys map[string]string) error
 }
+
+// VersionedValue is a gossiped value with its version.  The value with the highest version wins;
+// a removed value is kept as a Deleted tombstone, so that the removal wins too.
+type VersionedValue struct {
+	Value   string
+	Version uint64
+	Deleted bool
+}
+
+// VersionedGossipState is a GossipState which can read and merge values with their versions,
+// so that values can be copied between gossip networks without changing their versions
+type VersionedGossipState interface {
+	GossipState
+
+	// VersionedValues returns every value, including tombstones
+	VersionedValues() map[string]VersionedValue
+	// MergeVersionedValues merges values into our state, keeping our value if its version is at least as high
+	MergeVersionedValues(values map[string]VersionedValue) error
+}
+
+// Gossiper is a VersionedGossipState which is a member of a gossip network
+type Gossiper interface {
+	VersionedGossipState
+	GossipStatusProvider
+
+	// Start joins the gossip network; it does not return unless there is an error
+	Start() error
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memberlist
+
+import (
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+// glogWriter sends memberlist log messages to glog.  memberlist logs routine events at [DEBUG], so those are verbose.
+type glogWriter struct {
+}
+
+func (w *glogWriter) Write(p []byte) (int, error) {
+	line := strings.TrimSpace(string(p))
+	switch {
+	case strings.Contains(line, "[DEBUG]"):
+		glog.V(4).Info(line)
+	case strings.Contains(line, "[WARN]"), strings.Contains(line, "[ERR]"):
+		glog.Warning(line)
+	default:
+		glog.Info(line)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,291 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memberlist
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/hashicorp/memberlist"
+	"k8s.io/kops/protokube/pkg/gossip"
+)
+
+type MemberlistGossiper struct {
+	seeds gossip.SeedProvider
+
+	name  string
+	port  int
+	state *state
+
+	broadcasts *memberlist.TransmitLimitedQueue
+
+	// mutex guards list, which is set once memberlist is running; memberlist calls our delegate before that
+	mutex sync.Mutex
+	list  *memberlist.Memberlist
+}
+
+var _ gossip.Gossiper = &MemberlistGossiper{}
+
+// NewMemberlistGossiper builds a MemberlistGossiper, which listens on listen, and encrypts messages with password.
+// If secondaryPassword is not nil we also accept messages encrypted with secondaryPassword, so that the secret
+// can be rotated.
+func NewMemberlistGossiper(listen string, nodeName string, password []byte, secondaryPassword []byte, seeds gossip.SeedProvider) (*MemberlistGossiper, error) {
+	host, portString, err := net.SplitHostPort(listen)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse -listen flag: %v", listen)
+	}
+	port, err := strconv.Atoi(portString)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse -listen flag: %v", listen)
+	}
+
+	config := memberlist.DefaultLANConfig()
+	config.BindAddr = host
+	config.BindPort = port
+	config.AdvertisePort = port
+
+	return newMemberlistGossiper(config, nodeName, password, secondaryPassword, seeds)
+}
+
+// newMemberlistGossiper builds a MemberlistGossiper with config, which tests use to speed up the protocol
+func newMemberlistGossiper(config *memberlist.Config, nodeName string, password []byte, secondaryPassword []byte, seeds gossip.SeedProvider) (*MemberlistGossiper, error) {
+	var secondaryKeys [][]byte
+	if secondaryPassword != nil {
+		secondaryKeys = append(secondaryKeys, secretKey(secondaryPassword))
+	}
+	keyring, err := memberlist.NewKeyring(secondaryKeys, secretKey(password))
+	if err != nil {
+		return nil, fmt.Errorf("error building gossip keyring: %v", err)
+	}
+
+	g := &MemberlistGossiper{
+		seeds: seeds,
+		name:  nodeName,
+		port:  config.BindPort,
+		state: newState(),
+	}
+	g.broadcasts = &memberlist.TransmitLimitedQueue{
+		NumNodes:       g.numMembers,
+		RetransmitMult: config.RetransmitMult,
+	}
+
+	config.Name = nodeName
+	config.Keyring = keyring
+	config.Delegate = &delegate{g: g}
+	config.Logger = log.New(&glogWriter{}, "", 0)
+
+	list, err := memberlist.Create(config)
+	if err != nil {
+		return nil, fmt.Errorf("error starting memberlist: %v", err)
+	}
+	g.mutex.Lock()
+	g.list = list
+	g.mutex.Unlock()
+	// The port is chosen by memberlist if we asked for port 0
+	g.port = int(list.LocalNode().Port)
+
+	return g, nil
+}
+
+// numMembers returns the number of members of the cluster, which memberlist uses to decide how often to retransmit a broadcast
+func (g *MemberlistGossiper) numMembers() int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.list == nil {
+		return 1
+	}
+	return g.list.NumMembers()
+}
+
+// secretKey derives a memberlist encryption key, which must be 16, 24 or 32 bytes, from a gossip secret.
+// Like mesh, we always encrypt: the empty secret gives a well-known key.
+func secretKey(secret []byte) []byte {
+	key := sha256.Sum256(secret)
+	return key[:]
+}
+
+// Start joins the cluster through the seeds; memberlist is already running, so it only returns on error
+func (g *MemberlistGossiper) Start() error {
+	for {
+		glog.V(2).Infof("Querying for seeds")
+
+		seeds, err := g.seeds.GetSeeds()
+		if err != nil {
+			glog.Warningf("error getting seeds: %v", err)
+			time.Sleep(1 * time.Minute)
+			continue
+		}
+
+		glog.Infof("Got seeds: %s", seeds)
+
+		if len(seeds) != 0 {
+			if _, err := g.list.Join(seeds); err != nil {
+				glog.Infof("error joining seeds: %v", err)
+				time.Sleep(1 * time.Minute)
+				continue
+			}
+		}
+
+		glog.V(2).Infof("Seeding successful")
+
+		// Rejoin periodically, just in case of partitions
+		time.Sleep(60 * time.Minute)
+	}
+}
+
+func (g *MemberlistGossiper) Snapshot() *gossip.GossipStateSnapshot {
+	return g.state.snapshot()
+}
+
+func (g *MemberlistGossiper) UpdateValues(removeKeys []string, putEntries map[string]string) error {
+	glog.V(2).Infof("UpdateValues: remove=%s, put=%s", removeKeys, putEntries)
+	g.queueBroadcasts(g.state.updateValues(removeKeys, putEntries))
+	return nil
+}
+
+// VersionedValues implements gossip.VersionedGossipState
+func (g *MemberlistGossiper) VersionedValues() map[string]gossip.VersionedValue {
+	return g.state.versionedValues()
+}
+
+// MergeVersionedValues implements gossip.VersionedGossipState
+func (g *MemberlistGossiper) MergeVersionedValues(values map[string]gossip.VersionedValue) error {
+	g.queueBroadcasts(g.state.mergeVersionedValues(values))
+	return nil
+}
+
+// Status implements gossip.GossipStatusProvider
+func (g *MemberlistGossiper) Status() *gossip.GossipStatus {
+	snapshot := g.state.snapshot()
+	status := &gossip.GossipStatus{
+		Name:          g.name,
+		Version:       snapshot.Version,
+		Values:        snapshot.Values,
+		ValueVersions: make(map[string]uint64),
+	}
+	for k, v := range g.state.versionedValues() {
+		status.ValueVersions[k] = v.Version
+	}
+
+	meshStatus := gossip.GossipMeshStatus{
+		Protocol:  "memberlist",
+		Secret:    "primary",
+		Encrypted: true,
+		Port:      g.port,
+	}
+	// memberlist doesn't keep connections to the other members, so we only list the members
+	for _, member := range g.list.Members() {
+		meshStatus.Peers = append(meshStatus.Peers, gossip.GossipPeerStatus{
+			Name:     member.Address(),
+			NickName: member.Name,
+		})
+	}
+	status.Meshes = append(status.Meshes, meshStatus)
+	return status
+}
+
+// queueBroadcasts broadcasts changed records, with a message for each key so that a newer value replaces an older one in the queue
+func (g *MemberlistGossiper) queueBroadcasts(changes map[string]record) {
+	for k, v := range changes {
+		data, err := encodeRecords(map[string]record{k: v})
+		if err != nil {
+			glog.Warningf("error encoding broadcast: %v", err)
+			continue
+		}
+		g.broadcasts.QueueBroadcast(&broadcast{key: k, message: data})
+	}
+}
+
+// delegate implements memberlist.Delegate, exchanging our state with the other members
+type delegate struct {
+	g *MemberlistGossiper
+}
+
+var _ memberlist.Delegate = &delegate{}
+
+func (d *delegate) NodeMeta(limit int) []byte {
+	return nil
+}
+
+// NotifyMsg merges a broadcast from another member, and broadcasts the records that were new to us, so an update
+// spreads even if the member which made it stops sending it
+func (d *delegate) NotifyMsg(buf []byte) {
+	records, err := decodeRecords(buf)
+	if err != nil {
+		glog.Warningf("error decoding gossip broadcast: %v", err)
+		return
+	}
+	changes := d.g.state.merge(records)
+	glog.V(4).Infof("NotifyMsg %v => delta %v", records, changes)
+	d.g.queueBroadcasts(changes)
+}
+
+func (d *delegate) GetBroadcasts(overhead, limit int) [][]byte {
+	return d.g.broadcasts.GetBroadcasts(overhead, limit)
+}
+
+// LocalState returns our complete state, which memberlist periodically exchanges with a random member
+func (d *delegate) LocalState(join bool) []byte {
+	data, err := d.g.state.encode()
+	if err != nil {
+		glog.Warningf("error encoding gossip state: %v", err)
+		return nil
+	}
+	return data
+}
+
+// MergeRemoteState merges the complete state of another member
+func (d *delegate) MergeRemoteState(buf []byte, join bool) {
+	if len(buf) == 0 {
+		return
+	}
+	records, err := decodeRecords(buf)
+	if err != nil {
+		glog.Warningf("error decoding gossip state: %v", err)
+		return
+	}
+	changes := d.g.state.merge(records)
+	glog.V(4).Infof("MergeRemoteState => delta %v", changes)
+	d.g.queueBroadcasts(changes)
+}
+
+// broadcast is a message with a single record; it replaces an earlier broadcast of the same key
+type broadcast struct {
+	key     string
+	message []byte
+}
+
+var _ memberlist.Broadcast = &broadcast{}
+
+func (b *broadcast) Invalidates(other memberlist.Broadcast) bool {
+	o, ok := other.(*broadcast)
+	return ok && o.key == b.key
+}
+
+func (b *broadcast) Message() []byte {
+	return b.message
+}
+
+func (b *broadcast) Finished() {
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memberlist
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/memberlist"
+	"k8s.io/kops/protokube/pkg/gossip"
+)
+
+// startNode starts a member on a free loopback port, with fast protocol timings
+func startNode(t *testing.T, name string, password []byte, secondaryPassword []byte, seeds ...*MemberlistGossiper) *MemberlistGossiper {
+	config := memberlist.DefaultLocalConfig()
+	config.BindAddr = "127.0.0.1"
+	config.BindPort = 0
+	config.PushPullInterval = 500 * time.Millisecond
+
+	var addresses []string
+	for _, seed := range seeds {
+		addresses = append(addresses, fmt.Sprintf("127.0.0.1:%d", seed.port))
+	}
+
+	g, err := newMemberlistGossiper(config, name, password, secondaryPassword, gossip.NewStaticSeedProvider(addresses))
+	if err != nil {
+		t.Fatalf("error starting %s: %v", name, err)
+	}
+	go g.Start()
+	return g
+}
+
+func stopNodes(nodes []*MemberlistGossiper) {
+	for _, n := range nodes {
+		n.list.Shutdown()
+	}
+}
+
+// waitForValues waits for every node to have the expected values
+func waitForValues(t *testing.T, nodes []*MemberlistGossiper, expected map[string]string) {
+	deadline := time.Now().Add(20 * time.Second)
+	for {
+		converged := true
+		for _, n := range nodes {
+			if !reflect.DeepEqual(n.Snapshot().Values, expected) {
+				converged = false
+			}
+		}
+		if converged {
+			return
+		}
+		if time.Now().After(deadline) {
+			for _, n := range nodes {
+				t.Errorf("%s has values %v", n.name, n.Snapshot().Values)
+			}
+			t.Fatalf("nodes did not converge on %v", expected)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func TestConvergence(t *testing.T) {
+	seed := startNode(t, "node-0", []byte("secret"), nil)
+	nodes := []*MemberlistGossiper{seed}
+	for i := 1; i < 5; i++ {
+		nodes = append(nodes, startNode(t, fmt.Sprintf("node-%d", i), []byte("secret"), nil, seed))
+	}
+	defer stopNodes(nodes)
+
+	expected := make(map[string]string)
+	for i, n := range nodes {
+		key := fmt.Sprintf("dns/local/A/node-%d.internal.example.k8s.local", i)
+		value := fmt.Sprintf("10.0.0.%d", i)
+		if err := n.UpdateValues(nil, map[string]string{key: value}); err != nil {
+			t.Fatalf("error updating values: %v", err)
+		}
+		expected[key] = value
+	}
+	waitForValues(t, nodes, expected)
+
+	// Removals converge too
+	if err := nodes[3].UpdateValues([]string{"dns/local/A/node-0.internal.example.k8s.local"}, nil); err != nil {
+		t.Fatalf("error updating values: %v", err)
+	}
+	delete(expected, "dns/local/A/node-0.internal.example.k8s.local")
+	waitForValues(t, nodes, expected)
+
+	status := nodes[2].Status()
+	if len(status.Meshes) != 1 || len(status.Meshes[0].Peers) != len(nodes) {
+		t.Errorf("expected every node in the status, got %+v", status.Meshes)
+	}
+}
+
+func TestPartition(t *testing.T) {
+	// Two halves of a cluster which can't reach each other
+	a0 := startNode(t, "a-0", []byte("secret"), nil)
+	a1 := startNode(t, "a-1", []byte("secret"), nil, a0)
+	b0 := startNode(t, "b-0", []byte("secret"), nil)
+	b1 := startNode(t, "b-1", []byte("secret"), nil, b0)
+	nodes := []*MemberlistGossiper{a0, a1, b0, b1}
+	defer stopNodes(nodes)
+
+	// Before the partition both halves had the same value
+	for _, n := range nodes {
+		n.state.merge(map[string]record{"shared": {Data: "0", Version: 1}, "removed": {Data: "0", Version: 1}})
+	}
+
+	// During the partition each half makes changes, with the b half changing the shared value last
+	a0.UpdateValues(nil, map[string]string{"a": "1", "shared": "a"})
+	b1.UpdateValues([]string{"removed"}, nil)
+	waitForValues(t, []*MemberlistGossiper{a0, a1}, map[string]string{"a": "1", "shared": "a", "removed": "0"})
+	waitForValues(t, []*MemberlistGossiper{b0, b1}, map[string]string{"shared": "0"})
+
+	b0.state.now = func() uint64 { return uint64(time.Now().Unix()) + 10 }
+	b0.UpdateValues(nil, map[string]string{"b": "2", "shared": "b"})
+
+	// When the partition heals, the newest value of each key wins everywhere
+	if _, err := a1.list.Join([]string{fmt.Sprintf("127.0.0.1:%d", b1.port)}); err != nil {
+		t.Fatalf("error healing partition: %v", err)
+	}
+	waitForValues(t, nodes, map[string]string{"a": "1", "b": "2", "shared": "b"})
+}
+
+func TestSecretRotation(t *testing.T) {
+	// During a rotation the nodes accept both secrets, and switch the secret they send with
+	oldSecret := []byte("old")
+	newSecret := []byte("new")
+
+	a := startNode(t, "a", oldSecret, newSecret)
+	b := startNode(t, "b", newSecret, oldSecret, a)
+	nodes := []*MemberlistGossiper{a, b}
+	defer stopNodes(nodes)
+
+	a.UpdateValues(nil, map[string]string{"a": "1"})
+	b.UpdateValues(nil, map[string]string{"b": "2"})
+	waitForValues(t, nodes, map[string]string{"a": "1", "b": "2"})
+
+	// A node which doesn't know the secret can't join
+	c := startNode(t, "c", []byte("other"), nil)
+	defer stopNodes([]*MemberlistGossiper{c})
+	if _, err := c.list.Join([]string{fmt.Sprintf("127.0.0.1:%d", a.port)}); err == nil {
+		t.Errorf("node with a different secret joined the cluster")
+	}
+	if v, found := c.Snapshot().Values["a"]; found {
+		t.Errorf("node with a different secret received value %q", v)
+	}
+}
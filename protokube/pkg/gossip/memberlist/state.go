@@ -0,0 +1,190 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memberlist
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/kops/protokube/pkg/gossip"
+)
+
+// record is a gossiped value; records are encoded as JSON in our messages
+type record struct {
+	Data      string `json:"d,omitempty"`
+	Version   uint64 `json:"v"`
+	Tombstone bool   `json:"t,omitempty"`
+}
+
+// state is a last-write-wins map, with the same semantics as the mesh implementation: the record with the
+// highest version wins, versions are the time of the update in seconds, and removed values are kept as tombstones.
+// Because the versions are comparable, values can be copied between the two implementations.
+type state struct {
+	mtx     sync.RWMutex
+	records map[string]record
+
+	// version is incremented on every change
+	version      uint64
+	lastSnapshot *gossip.GossipStateSnapshot
+
+	// now returns the version for an update
+	now func() uint64
+}
+
+func newState() *state {
+	return &state{
+		records: make(map[string]record),
+		now: func() uint64 {
+			// TODO: This relies on NTP, as it does for mesh
+			return uint64(time.Now().Unix())
+		},
+	}
+}
+
+func (s *state) snapshot() *gossip.GossipStateSnapshot {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.lastSnapshot != nil && s.lastSnapshot.Version == s.version {
+		return s.lastSnapshot
+	}
+
+	values := make(map[string]string)
+	for k, v := range s.records {
+		if v.Tombstone {
+			continue
+		}
+		values[k] = v.Data
+	}
+
+	snapshot := &gossip.GossipStateSnapshot{
+		Values:  values,
+		Version: s.version,
+	}
+	s.lastSnapshot = snapshot
+	return snapshot
+}
+
+// updateValues applies a local update, returning the changed records so they can be broadcast
+func (s *state) updateValues(removeKeys []string, putEntries map[string]string) map[string]record {
+	if len(removeKeys) == 0 && len(putEntries) == 0 {
+		return nil
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := s.now()
+	changes := make(map[string]record)
+	for _, k := range removeKeys {
+		changes[k] = record{Version: s.nextVersion(k, now), Tombstone: true}
+	}
+	for k, v := range putEntries {
+		changes[k] = record{Data: v, Version: s.nextVersion(k, now)}
+	}
+
+	for k, v := range changes {
+		s.records[k] = v
+	}
+	s.version++
+	return changes
+}
+
+// nextVersion returns the version for a local update of the key: now, unless the existing record is as new.
+// Otherwise an update made in the same second as the last one would have the same version, and would not replace
+// the last update on the other members.
+func (s *state) nextVersion(key string, now uint64) uint64 {
+	if existing, found := s.records[key]; found && existing.Version >= now {
+		return existing.Version + 1
+	}
+	return now
+}
+
+// merge merges records from another member, returning the records which were newer than ours
+func (s *state) merge(records map[string]record) map[string]record {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	changes := make(map[string]record)
+	for k, update := range records {
+		if existing, found := s.records[k]; found && existing.Version >= update.Version {
+			continue
+		}
+		s.records[k] = update
+		changes[k] = update
+	}
+
+	if len(changes) != 0 {
+		s.version++
+	}
+	return changes
+}
+
+// versionedValues returns every record, including tombstones
+func (s *state) versionedValues() map[string]gossip.VersionedValue {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	values := make(map[string]gossip.VersionedValue)
+	for k, v := range s.records {
+		values[k] = gossip.VersionedValue{
+			Value:   v.Data,
+			Version: v.Version,
+			Deleted: v.Tombstone,
+		}
+	}
+	return values
+}
+
+// mergeVersionedValues merges values copied from another gossip network
+func (s *state) mergeVersionedValues(values map[string]gossip.VersionedValue) map[string]record {
+	records := make(map[string]record)
+	for k, v := range values {
+		r := record{Version: v.Version, Tombstone: v.Deleted}
+		if !v.Deleted {
+			r.Data = v.Value
+		}
+		records[k] = r
+	}
+	return s.merge(records)
+}
+
+// encode serializes all our records, for a full state exchange
+func (s *state) encode() ([]byte, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	return encodeRecords(s.records)
+}
+
+func encodeRecords(records map[string]record) ([]byte, error) {
+	data, err := json.Marshal(records)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding gossip records: %v", err)
+	}
+	return data, nil
+}
+
+func decodeRecords(data []byte) (map[string]record, error) {
+	records := make(map[string]record)
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("error decoding gossip records: %v", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memberlist
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kops/protokube/pkg/gossip"
+)
+
+func newTestState(clock *uint64) *state {
+	s := newState()
+	s.now = func() uint64 { return *clock }
+	return s
+}
+
+func TestStateMerge(t *testing.T) {
+	clock := uint64(100)
+	a := newTestState(&clock)
+	b := newTestState(&clock)
+
+	a.updateValues(nil, map[string]string{"x": "1", "y": "2"})
+	clock++
+	changes := b.updateValues(nil, map[string]string{"x": "3"})
+	if !reflect.DeepEqual(changes, map[string]record{"x": {Data: "3", Version: 101}}) {
+		t.Errorf("unexpected changes %v", changes)
+	}
+
+	// Only the records which are newer are merged and reported
+	data, err := a.encode()
+	if err != nil {
+		t.Fatalf("error encoding: %v", err)
+	}
+	records, err := decodeRecords(data)
+	if err != nil {
+		t.Fatalf("error decoding: %v", err)
+	}
+	changes = b.merge(records)
+	if !reflect.DeepEqual(changes, map[string]record{"y": {Data: "2", Version: 100}}) {
+		t.Errorf("unexpected changes %v", changes)
+	}
+
+	version := b.snapshot().Version
+	if changes := b.merge(records); len(changes) != 0 {
+		t.Errorf("merging again reported changes %v", changes)
+	}
+	if b.snapshot().Version != version {
+		t.Errorf("merging again changed the version")
+	}
+
+	if values := b.snapshot().Values; !reflect.DeepEqual(values, map[string]string{"x": "3", "y": "2"}) {
+		t.Errorf("unexpected values %v", values)
+	}
+}
+
+func TestStateTombstones(t *testing.T) {
+	clock := uint64(100)
+	a := newTestState(&clock)
+	b := newTestState(&clock)
+
+	b.merge(a.updateValues(nil, map[string]string{"x": "1"}))
+	clock++
+	b.merge(a.updateValues([]string{"x"}, nil))
+
+	if v, found := b.snapshot().Values["x"]; found {
+		t.Errorf("removed value is still present: %q", v)
+	}
+	expected := map[string]gossip.VersionedValue{"x": {Version: 101, Deleted: true}}
+	if values := b.versionedValues(); !reflect.DeepEqual(values, expected) {
+		t.Errorf("unexpected versioned values %v", values)
+	}
+
+	// An older value does not resurrect a removed value
+	b.mergeVersionedValues(map[string]gossip.VersionedValue{"x": {Value: "old", Version: 100}})
+	if v, found := b.snapshot().Values["x"]; found {
+		t.Errorf("older value resurrected removed value: %q", v)
+	}
+}
+
+func TestStateUpdatesInTheSameSecond(t *testing.T) {
+	clock := uint64(100)
+	a := newTestState(&clock)
+	b := newTestState(&clock)
+
+	// Each update replaces the last on the other members, even without the clock moving on
+	b.merge(a.updateValues(nil, map[string]string{"x": "1"}))
+	b.merge(a.updateValues([]string{"x"}, nil))
+	if v, found := b.snapshot().Values["x"]; found {
+		t.Errorf("removed value is still present: %q", v)
+	}
+
+	b.merge(a.updateValues(nil, map[string]string{"x": "2"}))
+	if v := b.snapshot().Values["x"]; v != "2" {
+		t.Errorf("unexpected value %q", v)
+	}
+	expected := map[string]gossip.VersionedValue{"x": {Value: "2", Version: 102}}
+	if values := b.versionedValues(); !reflect.DeepEqual(values, expected) {
+		t.Errorf("unexpected versioned values %v", values)
+	}
+}
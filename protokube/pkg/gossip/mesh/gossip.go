@@ -27,18 +27,37 @@ import (
 	"k8s.io/kops/protokube/pkg/gossip"
 )
 
+var _ gossip.Gossiper = &MeshGossiper{}
+
+// The names of the secrets, as reported in the status
+const (
+	secretPrimary   = "primary"
+	secretSecondary = "secondary"
+)
+
 type MeshGossiper struct {
 	seeds gossip.SeedProvider
 
-	router *mesh.Router
-	peer   *peer
+	name string
+
+	// routers holds the router for the primary secret, followed by the router for the secondary secret (if any)
+	routers []*mesh.Router
+	peer    *peer
 
 	version uint64
 
 	lastSnapshot *gossip.GossipStateSnapshot
 }
 
-func NewMeshGossiper(listen string, channelName string, nodeName string, password []byte, seeds gossip.SeedProvider) (*MeshGossiper, error) {
+// NewMeshGossiper builds a MeshGossiper, which listens on listen for peers using password.
+//
+// If secondaryPassword is not nil, we also join the mesh of peers which use secondaryPassword, so that the
+// secret can be rotated without splitting the cluster.  We don't listen for those peers (they would connect
+// to the port of the primary mesh), but we connect to them through the seeds; values are shared between the
+// two meshes, and reach the peers of the other mesh when we next gossip our complete state.
+// Because only the primary mesh listens, the seeds must keep the old secret as primary until every peer
+// accepts the new secret (see docs/gossip.md).
+func NewMeshGossiper(listen string, channelName string, nodeName string, password []byte, secondaryPassword []byte, seeds gossip.SeedProvider) (*MeshGossiper, error) {
 	meshConfig := mesh.Config{
 		ProtocolMinVersion: mesh.ProtocolMinVersion,
 		Password:           password,
@@ -67,27 +86,42 @@ func NewMeshGossiper(listen string, channelName string, nodeName string, passwor
 
 	nickname := nodeName
 	logger := &glogLogger{}
-	router := mesh.NewRouter(meshConfig, meshName, nickname, mesh.NullOverlay{}, logger)
 
 	peer := newPeer(meshName)
-	gossip := router.NewGossip(channelName, peer)
-	peer.register(gossip)
 
 	gossiper := &MeshGossiper{
-		seeds:  seeds,
-		router: router,
-		peer:   peer,
+		seeds: seeds,
+		name:  nodeName,
+		peer:  peer,
+	}
+
+	passwords := [][]byte{password}
+	if secondaryPassword != nil {
+		passwords = append(passwords, secondaryPassword)
+	}
+	for _, password := range passwords {
+		routerConfig := meshConfig
+		routerConfig.Password = password
+
+		// The meshes never connect to each other, so we can use the same name in each
+		router := mesh.NewRouter(routerConfig, meshName, nickname, mesh.NullOverlay{}, logger)
+		peer.register(router.NewGossip(channelName, peer))
+		gossiper.routers = append(gossiper.routers, router)
 	}
+
 	return gossiper, nil
 }
 
 func (g *MeshGossiper) Start() error {
 	//glog.Infof("mesh router starting (%s)", *meshListen)
-	g.router.Start()
+	// Only the primary router listens; see NewMeshGossiper
+	g.routers[0].Start()
 
 	defer func() {
 		glog.Infof("mesh router stopping")
-		g.router.Stop()
+		for _, router := range g.routers {
+			router.Stop()
+		}
 	}()
 
 	g.runSeeding()
@@ -110,7 +144,10 @@ func (g *MeshGossiper) runSeeding() {
 		// TODO: Include ourselves?  Exclude ourselves?
 
 		removeOthers := false
-		errors := g.router.ConnectionMaker.InitiateConnections(seeds, removeOthers)
+		var errors []error
+		for _, router := range g.routers {
+			errors = append(errors, router.ConnectionMaker.InitiateConnections(seeds, removeOthers)...)
+		}
 
 		if len(errors) != 0 {
 			for _, err := range errors {
@@ -137,3 +174,65 @@ func (g *MeshGossiper) UpdateValues(removeKeys []string, putEntries map[string]s
 	glog.V(2).Infof("UpdateValues: remove=%s, put=%s", removeKeys, putEntries)
 	return g.peer.updateValues(removeKeys, putEntries)
 }
+
+// VersionedValues implements gossip.VersionedGossipState
+func (g *MeshGossiper) VersionedValues() map[string]gossip.VersionedValue {
+	return g.peer.st.versionedValues()
+}
+
+// MergeVersionedValues implements gossip.VersionedGossipState
+func (g *MeshGossiper) MergeVersionedValues(values map[string]gossip.VersionedValue) error {
+	return g.peer.mergeVersionedValues(values)
+}
+
+// Status implements gossip.GossipStatusProvider
+func (g *MeshGossiper) Status() *gossip.GossipStatus {
+	snapshot := g.peer.snapshot()
+	status := &gossip.GossipStatus{
+		Name:          g.name,
+		Version:       snapshot.Version,
+		Values:        snapshot.Values,
+		ValueVersions: g.peer.st.versions(),
+	}
+
+	for i, router := range g.routers {
+		secret := secretPrimary
+		if i != 0 {
+			secret = secretSecondary
+		}
+		status.Meshes = append(status.Meshes, buildMeshStatus(secret, mesh.NewStatus(router)))
+	}
+	return status
+}
+
+// buildMeshStatus converts the status of a mesh router to a gossip.GossipMeshStatus
+func buildMeshStatus(secret string, s *mesh.Status) gossip.GossipMeshStatus {
+	status := gossip.GossipMeshStatus{
+		Protocol:  "mesh",
+		Secret:    secret,
+		Encrypted: s.Encryption,
+		Port:      s.Port,
+	}
+	for _, p := range s.Peers {
+		peer := gossip.GossipPeerStatus{
+			Name:     p.Name,
+			NickName: p.NickName,
+			Version:  p.Version,
+		}
+		for _, c := range p.Connections {
+			if c.Established {
+				peer.Connections = append(peer.Connections, c.NickName)
+			}
+		}
+		status.Peers = append(status.Peers, peer)
+	}
+	for _, c := range s.Connections {
+		status.Connections = append(status.Connections, gossip.GossipConnectionStatus{
+			Address:  c.Address,
+			Outbound: c.Outbound,
+			State:    c.State,
+			Info:     c.Info,
+		})
+	}
+	return status
+}
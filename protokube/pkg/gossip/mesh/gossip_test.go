@@ -0,0 +1,172 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mesh
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"k8s.io/kops/protokube/pkg/gossip"
+)
+
+// freeAddress returns a loopback address with a port which is not in use
+func freeAddress(t *testing.T) string {
+	l, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error finding free port: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+// Peer names are MAC addresses, so that the test works with the default peer_name_mac build tag
+func startGossiper(t *testing.T, listen string, name string, password []byte, secondaryPassword []byte, seeds []string) *MeshGossiper {
+	g, err := NewMeshGossiper(listen, "test", name, password, secondaryPassword, gossip.NewStaticSeedProvider(seeds))
+	if err != nil {
+		t.Fatalf("error building gossiper: %v", err)
+	}
+	go g.Start()
+	return g
+}
+
+func waitForValue(g *MeshGossiper, key string, value string) bool {
+	for i := 0; i < 100; i++ {
+		if g.Snapshot().Values[key] == value {
+			return true
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return false
+}
+
+func TestSecondarySecret(t *testing.T) {
+	addressA := freeAddress(t)
+	addressB := freeAddress(t)
+
+	// a is still using the old secret; b has moved to the new secret, but also accepts the old secret
+	a := startGossiper(t, addressA, "00:00:00:00:00:01", []byte("old"), nil, nil)
+	b := startGossiper(t, addressB, "00:00:00:00:00:02", []byte("new"), []byte("old"), []string{addressA})
+
+	if err := a.UpdateValues(nil, map[string]string{"a": "1"}); err != nil {
+		t.Fatalf("error updating values: %v", err)
+	}
+	if !waitForValue(b, "a", "1") {
+		t.Fatalf("value from the old mesh did not reach the node with both secrets")
+	}
+
+	if err := b.UpdateValues(nil, map[string]string{"b": "2"}); err != nil {
+		t.Fatalf("error updating values: %v", err)
+	}
+	if !waitForValue(a, "b", "2") {
+		t.Fatalf("value from the node with both secrets did not reach the old mesh")
+	}
+
+	status := b.Status()
+	if status.Name != "00:00:00:00:00:02" {
+		t.Errorf("unexpected name %q", status.Name)
+	}
+	if status.Values["a"] != "1" || status.Values["b"] != "2" {
+		t.Errorf("unexpected values %v", status.Values)
+	}
+	if status.ValueVersions["a"] == 0 || status.ValueVersions["b"] == 0 {
+		t.Errorf("unexpected value versions %v", status.ValueVersions)
+	}
+	if len(status.Meshes) != 2 {
+		t.Fatalf("expected a mesh for each secret, got %+v", status.Meshes)
+	}
+	if status.Meshes[0].Secret != secretPrimary || status.Meshes[1].Secret != secretSecondary {
+		t.Errorf("unexpected meshes %+v", status.Meshes)
+	}
+	if len(status.Meshes[0].Peers) != 1 {
+		t.Errorf("expected only ourselves in the primary mesh, got %+v", status.Meshes[0].Peers)
+	}
+	if len(status.Meshes[1].Peers) != 2 {
+		t.Errorf("expected both peers in the secondary mesh, got %+v", status.Meshes[1].Peers)
+	}
+}
+
+// TestSecondarySecretSeedUpdatedFirst follows the rotation in docs/gossip.md, updating the seed before the other peers
+func TestSecondarySecretSeedUpdatedFirst(t *testing.T) {
+	// Step 1: the seed accepts the new secret, while a peer which has not been updated still uses the old one
+	{
+		seedAddress := freeAddress(t)
+		seed := startGossiper(t, seedAddress, "00:00:00:00:00:05", []byte("old"), []byte("new"), nil)
+		peer := startGossiper(t, freeAddress(t), "00:00:00:00:00:06", []byte("old"), nil, []string{seedAddress})
+
+		if err := peer.UpdateValues(nil, map[string]string{"peer": "1"}); err != nil {
+			t.Fatalf("error updating values: %v", err)
+		}
+		if !waitForValue(seed, "peer", "1") {
+			t.Fatalf("value from the peer which has not been updated did not reach the seed in step 1")
+		}
+	}
+
+	// Step 2: the seed moves to the new secret, while a peer which has not been updated is still at step 1
+	{
+		seedAddress := freeAddress(t)
+		seed := startGossiper(t, seedAddress, "00:00:00:00:00:07", []byte("new"), []byte("old"), nil)
+		peer := startGossiper(t, freeAddress(t), "00:00:00:00:00:08", []byte("old"), []byte("new"), []string{seedAddress})
+
+		if err := peer.UpdateValues(nil, map[string]string{"peer": "2"}); err != nil {
+			t.Fatalf("error updating values: %v", err)
+		}
+		if !waitForValue(seed, "peer", "2") {
+			t.Fatalf("value from the peer which has not been updated did not reach the seed in step 2")
+		}
+		if err := seed.UpdateValues(nil, map[string]string{"seed": "2"}); err != nil {
+			t.Fatalf("error updating values: %v", err)
+		}
+		if !waitForValue(peer, "seed", "2") {
+			t.Fatalf("value from the seed did not reach the peer which has not been updated in step 2")
+		}
+	}
+
+	// Skipping step 1 cuts off the peers which have not been updated: the seed only listens with its primary secret
+	{
+		seedAddress := freeAddress(t)
+		seed := startGossiper(t, seedAddress, "00:00:00:00:00:09", []byte("new"), []byte("old"), nil)
+		peer := startGossiper(t, freeAddress(t), "00:00:00:00:00:0a", []byte("old"), nil, []string{seedAddress})
+
+		if err := peer.UpdateValues(nil, map[string]string{"peer": "3"}); err != nil {
+			t.Fatalf("error updating values: %v", err)
+		}
+		time.Sleep(2 * time.Second)
+		if v, found := seed.Snapshot().Values["peer"]; found {
+			t.Fatalf("value %q reached the seed without step 1", v)
+		}
+	}
+}
+
+func TestDifferentSecretsDoNotConnect(t *testing.T) {
+	addressA := freeAddress(t)
+	addressB := freeAddress(t)
+
+	a := startGossiper(t, addressA, "00:00:00:00:00:03", []byte("old"), nil, nil)
+	b := startGossiper(t, addressB, "00:00:00:00:00:04", []byte("new"), nil, []string{addressA})
+
+	if err := a.UpdateValues(nil, map[string]string{"a": "1"}); err != nil {
+		t.Fatalf("error updating values: %v", err)
+	}
+	time.Sleep(2 * time.Second)
+	if v, found := b.Snapshot().Values["a"]; found {
+		t.Fatalf("value %q reached a peer with a different secret", v)
+	}
+	if len(b.Status().Meshes[0].Peers) != 1 {
+		t.Errorf("expected only ourselves in the mesh, got %+v", b.Status().Meshes[0].Peers)
+	}
+}
@@ -28,7 +28,7 @@ import (
 // before calling mesh.Router.Start.
 type peer struct {
 	st      *state
-	send    mesh.Gossip
+	send    []mesh.Gossip
 	actions chan<- func()
 	quit    chan struct{}
 }
@@ -63,8 +63,10 @@ func (p *peer) loop(actions <-chan func()) {
 }
 
 // register the result of a mesh.Router.NewGossip.
+// A peer which is a member of several meshes registers the Gossip of each,
+// and broadcasts its own updates to all of them.
 func (p *peer) register(send mesh.Gossip) {
-	p.actions <- func() { p.send = send }
+	p.actions <- func() { p.send = append(p.send, send) }
 }
 
 func (p *peer) snapshot() *gossip.GossipStateSnapshot {
@@ -76,9 +78,11 @@ func (p *peer) updateValues(removeKeys []string, putEntries map[string]string) e
 	p.actions <- func() {
 		defer close(c)
 		p.st.updateValues(removeKeys, putEntries)
-		if p.send != nil {
+		if len(p.send) != 0 {
 			gossipData := p.st.getData()
-			p.send.GossipBroadcast(gossipData)
+			for _, send := range p.send {
+				send.GossipBroadcast(gossipData)
+			}
 		} else {
 			glog.Warningf("no sender configured; not broadcasting update right now")
 		}
@@ -87,6 +91,36 @@ func (p *peer) updateValues(removeKeys []string, putEntries map[string]string) e
 	return nil
 }
 
+// mergeVersionedValues merges values into our state, and broadcasts the values which changed
+func (p *peer) mergeVersionedValues(values map[string]gossip.VersionedValue) error {
+	message := &KVState{Records: make(map[string]*KVStateRecord)}
+	for k, v := range values {
+		record := &KVStateRecord{
+			Version:   v.Version,
+			Tombstone: v.Deleted,
+		}
+		if !v.Deleted {
+			record.Data = []byte(v.Value)
+		}
+		message.Records[k] = record
+	}
+
+	c := make(chan struct{})
+	p.actions <- func() {
+		defer close(c)
+		deltas := &KVState{}
+		p.st.merge(message, deltas)
+		if len(deltas.Records) == 0 {
+			return
+		}
+		for _, send := range p.send {
+			send.GossipBroadcast(deltas)
+		}
+	}
+	<-c
+	return nil
+}
+
 func (p *peer) stop() {
 	close(p.quit)
 }
@@ -93,6 +93,34 @@ func (s *state) snapshot() *gossip.GossipStateSnapshot {
 	return snapshot
 }
 
+// versions returns the version of every key, including keys which have been removed
+func (s *state) versions() map[string]uint64 {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	versions := make(map[string]uint64)
+	for k, v := range s.data.Records {
+		versions[k] = v.Version
+	}
+	return versions
+}
+
+// versionedValues returns every value with its version, including tombstones
+func (s *state) versionedValues() map[string]gossip.VersionedValue {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	values := make(map[string]gossip.VersionedValue)
+	for k, v := range s.data.Records {
+		values[k] = gossip.VersionedValue{
+			Value:   string(v.Data),
+			Version: v.Version,
+			Deleted: v.Tombstone,
+		}
+	}
+	return values
+}
+
 func (s *state) put(key string, data []byte) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
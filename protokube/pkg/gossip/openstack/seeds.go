@@ -0,0 +1,133 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openstack
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/gophercloud/gophercloud"
+	"k8s.io/kops/protokube/pkg/gossip"
+)
+
+// We cap how many pages are iterated through to prevent infinite loops
+// if the API were to continuously return a next link.
+const maxPages = 100
+
+// SeedProvider discovers the gossip seeds from the Nova servers whose metadata matches the tags of the cluster
+type SeedProvider struct {
+	compute *gophercloud.ServiceClient
+	tags    map[string]string
+}
+
+var _ gossip.SeedProvider = &SeedProvider{}
+
+// server is the subset of a Nova server we need
+type server struct {
+	Name      string               `json:"name"`
+	Status    string               `json:"status"`
+	Metadata  map[string]string    `json:"metadata"`
+	Addresses map[string][]address `json:"addresses"`
+}
+
+type address struct {
+	Addr    string `json:"addr"`
+	Version int    `json:"version"`
+	Type    string `json:"OS-EXT-IPS:type"`
+}
+
+type link struct {
+	Href string `json:"href"`
+	Rel  string `json:"rel"`
+}
+
+type listServersResponse struct {
+	Servers []server `json:"servers"`
+	Links   []link   `json:"servers_links"`
+}
+
+func (p *SeedProvider) GetSeeds() ([]string, error) {
+	var seeds []string
+
+	url := p.compute.ServiceURL("servers", "detail")
+	page := 0
+	for ; url != "" && page < maxPages; page++ {
+		var response listServersResponse
+		if _, err := p.compute.Get(url, &response, nil); err != nil {
+			return nil, fmt.Errorf("error listing Nova servers: %v", err)
+		}
+
+		for i := range response.Servers {
+			s := &response.Servers[i]
+			if !p.matches(s) {
+				continue
+			}
+			seeds = append(seeds, fixedAddresses(s)...)
+		}
+
+		url = ""
+		for _, l := range response.Links {
+			if l.Rel == "next" {
+				url = l.Href
+			}
+		}
+	}
+	if page >= maxPages {
+		glog.Errorf("GetSeeds exceeded maxPages=%d for listing Nova servers: truncating.", maxPages)
+	}
+
+	return seeds, nil
+}
+
+// matches returns true if the server is running and has all the tags in its metadata
+func (p *SeedProvider) matches(s *server) bool {
+	if s.Status == "DELETED" || s.Status == "SHUTOFF" || s.Status == "ERROR" {
+		return false
+	}
+	for k, v := range p.tags {
+		if s.Metadata[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// fixedAddresses returns the fixed (private) IPv4 addresses of the server
+func fixedAddresses(s *server) []string {
+	var ips []string
+	for _, addresses := range s.Addresses {
+		for _, a := range addresses {
+			if a.Version != 4 || a.Addr == "" {
+				continue
+			}
+			// Older deployments don't report the type; floating IPs are reported as such
+			if a.Type == "floating" {
+				continue
+			}
+			ips = append(ips, a.Addr)
+		}
+	}
+	return ips
+}
+
+// NewSeedProvider builds a SeedProvider for the Nova servers with the tags in their metadata
+func NewSeedProvider(compute *gophercloud.ServiceClient, tags map[string]string) (*SeedProvider, error) {
+	return &SeedProvider{
+		compute: compute,
+		tags:    tags,
+	}, nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openstack
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gophercloud/gophercloud"
+)
+
+const serversPage1 = `{
+  "servers": [
+    {
+      "name": "master-1",
+      "status": "ACTIVE",
+      "metadata": {"KubernetesCluster": "example.k8s.local"},
+      "addresses": {"private": [
+        {"addr": "10.0.0.1", "version": 4, "OS-EXT-IPS:type": "fixed"},
+        {"addr": "203.0.113.1", "version": 4, "OS-EXT-IPS:type": "floating"},
+        {"addr": "fd00::1", "version": 6, "OS-EXT-IPS:type": "fixed"}
+      ]}
+    },
+    {
+      "name": "other-cluster",
+      "status": "ACTIVE",
+      "metadata": {"KubernetesCluster": "other.k8s.local"},
+      "addresses": {"private": [{"addr": "10.0.0.9", "version": 4}]}
+    }
+  ],
+  "servers_links": [{"rel": "next", "href": "%s/servers/detail?marker=master-1"}]
+}`
+
+const serversPage2 = `{
+  "servers": [
+    {
+      "name": "node-1",
+      "status": "ACTIVE",
+      "metadata": {"KubernetesCluster": "example.k8s.local"},
+      "addresses": {"private": [{"addr": "10.0.0.2", "version": 4}]}
+    },
+    {
+      "name": "node-2",
+      "status": "SHUTOFF",
+      "metadata": {"KubernetesCluster": "example.k8s.local"},
+      "addresses": {"private": [{"addr": "10.0.0.3", "version": 4}]}
+    }
+  ]
+}`
+
+func TestGetSeeds(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/servers/detail" {
+			t.Errorf("unexpected request for %q", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("marker") == "" {
+			fmt.Fprintf(w, serversPage1, server.URL)
+		} else {
+			fmt.Fprint(w, serversPage2)
+		}
+	}))
+	defer server.Close()
+
+	compute := &gophercloud.ServiceClient{
+		ProviderClient: &gophercloud.ProviderClient{HTTPClient: *http.DefaultClient},
+		Endpoint:       server.URL + "/",
+	}
+	p, err := NewSeedProvider(compute, map[string]string{"KubernetesCluster": "example.k8s.local"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	seeds, err := p.GetSeeds()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sort.Strings(seeds)
+	expected := []string{"10.0.0.1", "10.0.0.2"}
+	if !reflect.DeepEqual(seeds, expected) {
+		t.Errorf("unexpected seeds: expected %v, got %v", expected, seeds)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protocols
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/protokube/pkg/gossip"
+	"k8s.io/kops/protokube/pkg/gossip/memberlist"
+	"k8s.io/kops/protokube/pkg/gossip/mesh"
+)
+
+const (
+	// ProtocolMesh is the weaveworks mesh protocol, which is the default
+	ProtocolMesh = "mesh"
+	// ProtocolMemberlist is the hashicorp memberlist protocol
+	ProtocolMemberlist = "memberlist"
+)
+
+// Config describes the membership of a gossip network
+type Config struct {
+	// Protocol is the gossip protocol, mesh if empty
+	Protocol string
+	// Listen is the address:port on which we listen for peers
+	Listen string
+	// Secret secures the gossip
+	Secret []byte
+	// SecondarySecret, if not nil, is also accepted while the secret is rotated
+	SecondarySecret []byte
+	// Seeds returns the peers through which we join the network
+	Seeds gossip.SeedProvider
+}
+
+// NewGossiper builds a member of the gossip network described by config
+func NewGossiper(config *Config, channelName string, nodeName string) (gossip.Gossiper, error) {
+	switch config.Protocol {
+	case "", ProtocolMesh:
+		g, err := mesh.NewMeshGossiper(config.Listen, channelName, nodeName, config.Secret, config.SecondarySecret, config.Seeds)
+		if err != nil {
+			return nil, err
+		}
+		return g, nil
+
+	case ProtocolMemberlist:
+		g, err := memberlist.NewMemberlistGossiper(config.Listen, nodeName, config.Secret, config.SecondarySecret, config.Seeds)
+		if err != nil {
+			return nil, err
+		}
+		return g, nil
+
+	default:
+		return nil, fmt.Errorf("unknown gossip protocol %q", config.Protocol)
+	}
+}
+
+// NewBridgedGossiper builds a member of the primary gossip network, and if secondary is not nil also of the
+// secondary network, copying values between the two; this is how a cluster moves between gossip protocols.
+func NewBridgedGossiper(primary *Config, secondary *Config, channelName string, nodeName string) (gossip.Gossiper, error) {
+	primaryGossiper, err := NewGossiper(primary, channelName, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	if secondary == nil {
+		return primaryGossiper, nil
+	}
+
+	glog.Infof("bridging gossip protocol %q to secondary protocol %q on %s", primary.Protocol, secondary.Protocol, secondary.Listen)
+	secondaryGossiper, err := NewGossiper(secondary, channelName, nodeName)
+	if err != nil {
+		return nil, fmt.Errorf("error building secondary gossip: %v", err)
+	}
+	return gossip.NewBridge(primaryGossiper, secondaryGossiper), nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srv
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/protokube/pkg/gossip"
+)
+
+// SeedProvider discovers the gossip seeds from the targets of a DNS SRV record, for clouds without an API
+// we can query (vSphere, bare-metal).  The port of the SRV record is ignored: peers gossip on the port of the
+// protocol, so the same record serves both protocols while a cluster changes protocol.
+type SeedProvider struct {
+	name string
+
+	lookupSRV  func(service, proto, name string) (string, []*net.SRV, error)
+	lookupHost func(host string) ([]string, error)
+}
+
+var _ gossip.SeedProvider = &SeedProvider{}
+
+func (p *SeedProvider) GetSeeds() ([]string, error) {
+	_, records, err := p.lookupSRV("", "", p.name)
+	if err != nil {
+		return nil, fmt.Errorf("error looking up SRV record %q: %v", p.name, err)
+	}
+
+	var seeds []string
+	for _, record := range records {
+		target := strings.TrimSuffix(record.Target, ".")
+		if target == "" {
+			continue
+		}
+
+		if ip := net.ParseIP(target); ip != nil {
+			seeds = append(seeds, ip.String())
+			continue
+		}
+
+		addrs, err := p.lookupHost(target)
+		if err != nil {
+			// The other targets may still let us join the network
+			glog.Warningf("error resolving gossip seed %q: %v", target, err)
+			continue
+		}
+		seeds = append(seeds, addrs...)
+	}
+
+	return seeds, nil
+}
+
+// NewSeedProvider builds a SeedProvider for the SRV record with the full name, e.g. _gossip._tcp.example.com
+func NewSeedProvider(name string) (*SeedProvider, error) {
+	if name == "" {
+		return nil, fmt.Errorf("the name of the SRV record is required")
+	}
+	return &SeedProvider{
+		name:       name,
+		lookupSRV:  net.LookupSRV,
+		lookupHost: net.LookupHost,
+	}, nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srv
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestGetSeeds(t *testing.T) {
+	p, err := NewSeedProvider("_gossip._tcp.example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p.lookupSRV = func(service, proto, name string) (string, []*net.SRV, error) {
+		if service != "" || proto != "" || name != "_gossip._tcp.example.com" {
+			t.Fatalf("unexpected lookup of %q %q %q", service, proto, name)
+		}
+		return "", []*net.SRV{
+			{Target: "master-1.example.com.", Port: 3999},
+			{Target: "10.0.0.2", Port: 3999},
+			{Target: "unknown.example.com.", Port: 3999},
+			{Target: ".", Port: 3999},
+		}, nil
+	}
+	p.lookupHost = func(host string) ([]string, error) {
+		switch host {
+		case "master-1.example.com":
+			return []string{"10.0.0.1"}, nil
+		default:
+			return nil, fmt.Errorf("no such host %q", host)
+		}
+	}
+
+	seeds, err := p.GetSeeds()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"10.0.0.1", "10.0.0.2"}
+	if !reflect.DeepEqual(seeds, expected) {
+		t.Errorf("unexpected seeds: expected %v, got %v", expected, seeds)
+	}
+}
+
+func TestGetSeedsLookupError(t *testing.T) {
+	p, err := NewSeedProvider("_gossip._tcp.example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p.lookupSRV = func(service, proto, name string) (string, []*net.SRV, error) {
+		return "", nil, fmt.Errorf("server misbehaving")
+	}
+
+	if _, err := p.GetSeeds(); err == nil {
+		t.Errorf("expected an error when the SRV record cannot be looked up")
+	}
+}
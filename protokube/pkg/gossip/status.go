@@ -0,0 +1,114 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gossip
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/golang/glog"
+)
+
+// StatusPath is the path on which the gossip status is served
+const StatusPath = "/gossip/state"
+
+// GossipStatus describes a member of the gossip network, for troubleshooting
+type GossipStatus struct {
+	// Name is the name of this member
+	Name string `json:"name"`
+	// Version is the version of the GossipStateSnapshot, which increases whenever the values change
+	Version uint64 `json:"version"`
+	// Values are the values of the GossipStateSnapshot
+	Values map[string]string `json:"values"`
+	// ValueVersions are the versions of each key, including keys which have been removed
+	ValueVersions map[string]uint64 `json:"valueVersions"`
+	// Meshes are the gossip networks we are a member of: one for each protocol we use, and with
+	// the mesh protocol one for each secret we accept
+	Meshes []GossipMeshStatus `json:"meshes"`
+}
+
+// GossipMeshStatus describes our membership of a gossip network
+type GossipMeshStatus struct {
+	// Protocol is the gossip protocol of the network, "mesh" or "memberlist"
+	Protocol string `json:"protocol"`
+	// Secret is "primary" for the network using the primary secret, "secondary" for the network using the secondary secret.
+	// A memberlist network accepts both secrets, and is always "primary".
+	Secret string `json:"secret"`
+	// Encrypted is true if connections are encrypted
+	Encrypted bool `json:"encrypted"`
+	// Port is the port on which the members of the network listen
+	Port int `json:"port"`
+	// Peers are the members of the network which we know about, including ourselves
+	Peers []GossipPeerStatus `json:"peers"`
+	// Connections are our connections to other members
+	Connections []GossipConnectionStatus `json:"connections"`
+}
+
+// GossipPeerStatus describes a member of a gossip network
+type GossipPeerStatus struct {
+	Name     string `json:"name"`
+	NickName string `json:"nickName"`
+	// Version increases whenever the connections of the peer change
+	Version uint64 `json:"version"`
+	// Connections are the nicknames of the peers this peer is connected to
+	Connections []string `json:"connections"`
+}
+
+// GossipConnectionStatus describes a connection to another member of a gossip network
+type GossipConnectionStatus struct {
+	Address  string `json:"address"`
+	Outbound bool   `json:"outbound"`
+	State    string `json:"state"`
+	Info     string `json:"info"`
+}
+
+// GossipStatusProvider is implemented by a GossipState which can describe its membership
+type GossipStatusProvider interface {
+	Status() *GossipStatus
+}
+
+// NewStatusHandler returns a read-only http.Handler which serves the status of p as JSON on StatusPath
+func NewStatusHandler(p GossipStatusProvider) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(StatusPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		data, err := json.MarshalIndent(p.Status(), "", "  ")
+		if err != nil {
+			glog.Warningf("error serializing gossip status: %v", err)
+			http.Error(w, "error serializing gossip status", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+	return mux
+}
+
+// ListenAndServeStatus serves the status of p on listen, which should normally be a loopback address
+func ListenAndServeStatus(listen string, p GossipStatusProvider) error {
+	glog.Infof("serving gossip status on http://%s%s", listen, StatusPath)
+	if err := http.ListenAndServe(listen, NewStatusHandler(p)); err != nil {
+		return fmt.Errorf("error serving gossip status on %s: %v", listen, err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gossip
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type fakeStatusProvider struct {
+	status *GossipStatus
+}
+
+func (p *fakeStatusProvider) Status() *GossipStatus {
+	return p.status
+}
+
+func TestStatusHandler(t *testing.T) {
+	status := &GossipStatus{
+		Name:          "node-1",
+		Version:       3,
+		Values:        map[string]string{"dns/api.internal.example.k8s.local/A": "10.0.0.1"},
+		ValueVersions: map[string]uint64{"dns/api.internal.example.k8s.local/A": 1500000000, "removed": 1500000001},
+		Meshes: []GossipMeshStatus{
+			{
+				Secret:    "primary",
+				Encrypted: true,
+				Port:      3999,
+				Peers:     []GossipPeerStatus{{Name: "node-1", NickName: "node-1", Version: 2, Connections: []string{"node-2"}}},
+				Connections: []GossipConnectionStatus{
+					{Address: "10.0.0.2:3999", Outbound: true, State: "established"},
+				},
+			},
+		},
+	}
+	handler := NewStatusHandler(&fakeStatusProvider{status: status})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, StatusPath, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code %d: %s", w.Code, w.Body.String())
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("unexpected content type %q", contentType)
+	}
+	actual := &GossipStatus{}
+	if err := json.Unmarshal(w.Body.Bytes(), actual); err != nil {
+		t.Fatalf("error parsing response: %v", err)
+	}
+	if !reflect.DeepEqual(actual, status) {
+		t.Errorf("unexpected status %+v, expected %+v", actual, status)
+	}
+}
+
+func TestStatusHandlerIsReadOnly(t *testing.T) {
+	handler := NewStatusHandler(&fakeStatusProvider{status: &GossipStatus{}})
+
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, StatusPath, nil))
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s: expected status code %d, got %d", method, http.StatusMethodNotAllowed, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/other", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status code %d for unknown path, got %d", http.StatusNotFound, w.Code)
+	}
+}
@@ -30,7 +30,10 @@ func applyChannel(channel string) error {
 	// We don't embed the channels code because we expect this will eventually be part of kubectl
 	glog.Infof("checking channel: %q", channel)
 
-	out, err := execChannels("apply", "channel", channel, "--v=4", "--yes")
+	// We don't wait for the addons to roll out: we are called from the sync loop, and workloads often
+	// can't be scheduled until the nodes have joined.  Addons whose dependencies are not yet healthy are
+	// left pending by channels, and applied by a later run of the sync loop.
+	out, err := execChannels("apply", "channel", channel, "--v=4", "--yes", "--wait-timeout=0s")
 	glog.V(4).Infof("apply channel output was: %v", out)
 	return err
 }
@@ -31,6 +31,8 @@ import (
 
 	"k8s.io/kops/pkg/resources/digitalocean"
 	"k8s.io/kops/protokube/pkg/etcd"
+	"k8s.io/kops/protokube/pkg/gossip"
+	gossipdo "k8s.io/kops/protokube/pkg/gossip/do"
 )
 
 const (
@@ -171,6 +173,19 @@ func (d *DOVolumes) FindMountedVolume(volume *Volume) (string, error) {
 	return "", nil
 }
 
+// GossipSeeds returns a SeedProvider for the droplets of the cluster
+func (d *DOVolumes) GossipSeeds() (gossip.SeedProvider, error) {
+	// droplets are tagged with the cluster name, with "." replaced by "-" since the DO API does not accept "."
+	clusterTag := "KubernetesCluster:" + strings.Replace(d.ClusterID, ".", "-", -1)
+
+	return gossipdo.NewSeedProvider(d.Cloud.Droplets(), clusterTag)
+}
+
+// DropletName returns the name of the droplet we are running on
+func (d *DOVolumes) DropletName() string {
+	return d.dropletName
+}
+
 func (d *DOVolumes) getVolumeByID(id string) (*godo.Volume, error) {
 	vol, _, err := d.Cloud.Volumes().GetVolume(context.TODO(), id)
 	return vol, err
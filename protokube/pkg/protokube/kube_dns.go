@@ -62,9 +62,10 @@ func (p *KopsDnsProvider) Replace(fqdn string, values []string) error {
 			RecordType: dns.RecordTypeA,
 			FQDN:       fqdn,
 			Value:      value,
+			Role:       dns.RoleTypeInternal,
 		})
 	}
-	p.DNSScope.Replace(fqdn, records)
+	p.DNSScope.Replace(fqdn, nil, records)
 
 	return nil
 }
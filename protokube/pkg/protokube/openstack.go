@@ -0,0 +1,56 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protokube
+
+import (
+	"fmt"
+
+	os "github.com/gophercloud/gophercloud/openstack"
+	"k8s.io/kops/protokube/pkg/gossip"
+	gossipopenstack "k8s.io/kops/protokube/pkg/gossip/openstack"
+	"k8s.io/kops/upup/pkg/fi/cloudup/openstack"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// OpenstackGossipSeeds returns a SeedProvider for the Nova servers of the cluster, using the same credentials
+// file as kops ($OPENSTACK_CREDENTIAL_FILE), which must have a Nova section
+func OpenstackGossipSeeds(clusterID string) (gossip.SeedProvider, error) {
+	config := vfs.OpenstackConfig{}
+
+	authOption, err := config.GetCredential()
+	if err != nil {
+		return nil, err
+	}
+	provider, err := os.AuthenticatedClient(authOption)
+	if err != nil {
+		return nil, fmt.Errorf("error building openstack authenticated client: %v", err)
+	}
+
+	endpointOpt, err := config.GetServiceConfig("Nova")
+	if err != nil {
+		return nil, err
+	}
+	compute, err := os.NewComputeV2(provider, endpointOpt)
+	if err != nil {
+		return nil, fmt.Errorf("error building nova client: %v", err)
+	}
+
+	tags := make(map[string]string)
+	tags[openstack.TagClusterName] = clusterID
+
+	return gossipopenstack.NewSeedProvider(compute, tags)
+}
@@ -225,27 +225,40 @@ func TestFindImage(t *testing.T) {
 
 	grid := []struct {
 		KubernetesVersion string
+		Architecture      string
 		ExpectedImage     string
 	}{
 		{
 			KubernetesVersion: "1.4.4",
+			Architecture:      "amd64",
 			ExpectedImage:     "kope.io/k8s-1.4-debian-jessie-amd64-hvm-ebs-2016-10-21",
 		},
 		{
 			KubernetesVersion: "1.5.1",
+			Architecture:      "amd64",
 			ExpectedImage:     "kope.io/k8s-1.5-debian-jessie-amd64-hvm-ebs-2017-01-09",
 		},
+		{
+			KubernetesVersion: "1.4.4",
+			Architecture:      "arm64",
+			ExpectedImage:     "",
+		},
+		{
+			KubernetesVersion: "1.5.1",
+			Architecture:      "arm64",
+			ExpectedImage:     "kope.io/k8s-1.5-debian-stretch-arm64-hvm-ebs-2017-01-09",
+		},
 	}
 	for _, g := range grid {
 		kubernetesVersion := semver.MustParse(g.KubernetesVersion)
 
-		image := channel.FindImage(kops.CloudProviderAWS, kubernetesVersion)
+		image := channel.FindImage(kops.CloudProviderAWS, kubernetesVersion, g.Architecture)
 		name := ""
 		if image != nil {
 			name = image.Name
 		}
 		if name != g.ExpectedImage {
-			t.Errorf("unexpected image from FindImage(%q): expected=%q, actual=%q", g.KubernetesVersion, g.ExpectedImage, name)
+			t.Errorf("unexpected image from FindImage(%q, %q): expected=%q, actual=%q", g.KubernetesVersion, g.Architecture, g.ExpectedImage, name)
 		}
 	}
 }
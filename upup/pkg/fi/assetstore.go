@@ -153,26 +153,44 @@ func hashFromHttpHeader(url string) (*hashing.Hash, error) {
 
 // Add an asset into the store, in one of the recognized formats (see Assets in types package)
 func (a *AssetStore) Add(id string) error {
+	return a.add(id, true)
+}
+
+// AddCached adds an asset which has already been downloaded (and extracted) into the cache, without changing
+// the cache.  It returns an error if the asset is not in the cache.
+func (a *AssetStore) AddCached(id string) error {
+	return a.add(id, false)
+}
+
+func (a *AssetStore) add(id string, download bool) error {
 	if strings.HasPrefix(id, "http://") || strings.HasPrefix(id, "https://") {
-		return a.addURL(id, nil)
+		if !download {
+			// Without a hash, we can't tell which file in the cache is the asset
+			return fmt.Errorf("asset %q has no hash, so cannot be found in the cache", id)
+		}
+		return a.addURL(id, nil, download)
 	}
 	i := strings.Index(id, "@http://")
 	if i == -1 {
 		i = strings.Index(id, "@https://")
 	}
+	if i == -1 {
+		// Local files (for example from a node bundle) must have a hash
+		i = strings.Index(id, "@file://")
+	}
 	if i != -1 {
 		url := id[i+1:]
 		hash, err := hashing.FromString(id[:i])
 		if err != nil {
 			return err
 		}
-		return a.addURL(url, hash)
+		return a.addURL(url, hash, download)
 	}
 	// TODO: local files!
 	return fmt.Errorf("unknown asset format: %q", id)
 }
 
-func (a *AssetStore) addURL(url string, hash *hashing.Hash) error {
+func (a *AssetStore) addURL(url string, hash *hashing.Hash, download bool) error {
 	var err error
 
 	if hash == nil {
@@ -183,9 +201,19 @@ func (a *AssetStore) addURL(url string, hash *hashing.Hash) error {
 	}
 
 	localFile := path.Join(a.cacheDir, hash.String()+"_"+utils.SanitizeString(url))
-	_, err = DownloadURL(url, localFile, hash)
-	if err != nil {
-		return err
+	if download {
+		_, err = DownloadURL(url, localFile, hash)
+		if err != nil {
+			return err
+		}
+	} else {
+		match, err := fileHasHash(localFile, hash)
+		if err != nil {
+			return err
+		}
+		if !match {
+			return fmt.Errorf("asset %q is not in the cache", url)
+		}
 	}
 
 	key := path.Base(url)
@@ -207,7 +235,7 @@ func (a *AssetStore) addURL(url string, hash *hashing.Hash) error {
 	file := strings.ToLower(assetPath)
 	// pickup both tar.gz and tgz files
 	if strings.HasSuffix(file, ".tar.gz") || strings.HasSuffix(file, ".tgz") {
-		err = a.addArchive(source, localFile)
+		err = a.addArchive(source, localFile, download)
 		if err != nil {
 			return err
 		}
@@ -258,10 +286,14 @@ func (a *AssetStore) addURL(url string, hash *hashing.Hash) error {
 //	return nil
 //}
 
-func (a *AssetStore) addArchive(archiveSource *Source, archiveFile string) error {
+func (a *AssetStore) addArchive(archiveSource *Source, archiveFile string, extract bool) error {
 	extracted := path.Join(a.cacheDir, "extracted/"+path.Base(archiveFile))
 
 	if _, err := os.Stat(extracted); os.IsNotExist(err) {
+		if !extract {
+			return fmt.Errorf("asset %q has not been extracted in the cache", archiveSource.URL)
+		}
+
 		// We extract to a temporary dir which we then rename so this is atomic
 		// (untarring can be slow, and we might crash / be interrupted half-way through)
 		extractedTemp := extracted + ".tmp-" + strconv.FormatInt(time.Now().UnixNano(), 10)
@@ -142,23 +142,24 @@ func transferFile(c *fi.Context, source string, target string, sha string) error
 		return fmt.Errorf("error building path %q: %v", objectStore, err)
 	}
 
-	shaTarget := objectStore + ".sha1"
+	shaHash, err := hashing.FromString(strings.TrimSpace(sha))
+	if err != nil {
+		return fmt.Errorf("unable to hash sha: %q, %v", sha, err)
+	}
+
+	// The hash file is named for the algorithm of the expected hash, so it is found alongside the file in the same way
+	shaTarget := objectStore + "." + string(shaHash.Algorithm)
 	shaVFS, err := vfs.Context.BuildVfsPath(shaTarget)
 	if err != nil {
 		return fmt.Errorf("error building path %q: %v", shaTarget, err)
 	}
 
 	in := bytes.NewReader(data)
-	dataHash, err := hashing.HashAlgorithmSHA1.Hash(in)
+	dataHash, err := shaHash.Algorithm.Hash(in)
 	if err != nil {
 		return fmt.Errorf("unable to parse sha from file %q downloaded: %v", sha, err)
 	}
 
-	shaHash, err := hashing.FromString(strings.TrimSpace(sha))
-	if err != nil {
-		return fmt.Errorf("unable to hash sha: %q, %v", sha, err)
-	}
-
 	if !shaHash.Equal(dataHash) {
 		return fmt.Errorf("the sha value in %q does not match %q calculated value %q", shaTarget, source, dataHash.String())
 	}
@@ -18,6 +18,7 @@ package cloudup
 
 import (
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"path"
@@ -63,6 +64,7 @@ import (
 	"k8s.io/kops/upup/pkg/fi/cloudup/vsphere"
 	"k8s.io/kops/upup/pkg/fi/cloudup/vspheretasks"
 	"k8s.io/kops/upup/pkg/fi/fitasks"
+	"k8s.io/kops/util/pkg/architectures"
 	"k8s.io/kops/util/pkg/vfs"
 )
 
@@ -89,11 +91,11 @@ type ApplyClusterCmd struct {
 
 	InstanceGroups []*kops.InstanceGroup
 
-	// NodeUpSource is the location from which we download nodeup
-	NodeUpSource string
+	// NodeUpSource is the location from which we download nodeup, for each architecture
+	NodeUpSource map[architectures.Architecture]string
 
-	// NodeUpHash is the sha hash
-	NodeUpHash string
+	// NodeUpHash is the sha hash, for each architecture
+	NodeUpHash map[architectures.Architecture]string
 
 	// Models is a list of cloudup models to apply
 	Models []string
@@ -107,11 +109,14 @@ type ApplyClusterCmd struct {
 	// OutDir is a local directory in which we place output, can cache files etc
 	OutDir string
 
-	// Assets is a list of sources for files (primarily when not using everything containerized)
+	// Assets is a list of sources for files (primarily when not using everything containerized), for each architecture
 	// Formats:
 	//  raw url: http://... or https://...
 	//  url with hash: <hex>@http://... or <hex>@https://...
-	Assets []string
+	Assets map[architectures.Architecture][]string
+
+	// NodeAddonAssets are the assets for the node add-ons of each instance group, in the same formats as Assets
+	NodeAddonAssets map[string][]string
 
 	Clientset simple.Clientset
 
@@ -133,6 +138,9 @@ type ApplyClusterCmd struct {
 
 	// TaskMap is the map of tasks that we built (output)
 	TaskMap map[string]fi.Task
+
+	// Out is where the dry-run target writes the changes it would make; defaults to stdout
+	Out io.Writer
 }
 
 func (c *ApplyClusterCmd) Run() error {
@@ -718,7 +726,11 @@ func (c *ApplyClusterCmd) Run() error {
 		shouldPrecreateDNS = false
 
 	case TargetDryRun:
-		target = fi.NewDryRunTarget(assetBuilder, os.Stdout)
+		out := c.Out
+		if out == nil {
+			out = os.Stdout
+		}
+		target = fi.NewDryRunTarget(assetBuilder, out)
 		dryRun = true
 
 		// Avoid making changes on a dry-run
@@ -928,28 +940,53 @@ func (c *ApplyClusterCmd) validateKubernetesVersion() error {
 	return nil
 }
 
-// AddFileAssets adds the file assets within the assetBuilder
+// AddFileAssets adds the file assets within the assetBuilder, for each architecture used by the instance groups
 func (c *ApplyClusterCmd) AddFileAssets(assetBuilder *assets.AssetBuilder) error {
+	c.Assets = make(map[architectures.Architecture][]string)
+	c.NodeUpSource = make(map[architectures.Architecture]string)
+	c.NodeUpHash = make(map[architectures.Architecture]string)
 
-	var baseURL string
-	var err error
-	if components.IsBaseURL(c.Cluster.Spec.KubernetesVersion) {
-		baseURL = c.Cluster.Spec.KubernetesVersion
-	} else {
-		baseURL = "https://storage.googleapis.com/kubernetes-release/release/v" + c.Cluster.Spec.KubernetesVersion
+	for _, arch := range model.ClusterArchitectures(c.Cluster, c.InstanceGroups) {
+		if err := c.addFileAssets(assetBuilder, arch); err != nil {
+			return err
+		}
+	}
+
+	c.NodeAddonAssets = make(map[string][]string)
+	for _, ig := range c.InstanceGroups {
+		assetIDs, err := findNodeAddonAssets(c.Cluster, assetBuilder, ig)
+		if err != nil {
+			return err
+		}
+		c.NodeAddonAssets[ig.ObjectMeta.Name] = assetIDs
+	}
+
+	return nil
+}
+
+// addFileAssets adds the file assets for machines of the specified architecture
+func (c *ApplyClusterCmd) addFileAssets(assetBuilder *assets.AssetBuilder, arch architectures.Architecture) error {
+	var instanceGroups []*kops.InstanceGroup
+	for _, ig := range c.InstanceGroups {
+		if model.InstanceGroupArchitecture(&c.Cluster.Spec, ig) == arch {
+			instanceGroups = append(instanceGroups, ig)
+		}
 	}
 
+	var err error
+	baseURL := kubernetesReleaseBaseURL(c.Cluster)
+
 	k8sAssetsNames := []string{
-		"/bin/linux/amd64/kubelet",
-		"/bin/linux/amd64/kubectl",
+		"/bin/linux/" + string(arch) + "/kubelet",
+		"/bin/linux/" + string(arch) + "/kubectl",
 	}
-	if needsMounterAsset(c.Cluster, c.InstanceGroups) {
+	if needsMounterAsset(c.Cluster, instanceGroups) {
 		k8sVersion, err := util.ParseKubernetesVersion(c.Cluster.Spec.KubernetesVersion)
 		if err != nil {
 			return fmt.Errorf("unable to determine kubernetes version from %q", c.Cluster.Spec.KubernetesVersion)
 		} else if util.IsKubernetesGTE("1.9", *k8sVersion) {
 			// Available directly
-			k8sAssetsNames = append(k8sAssetsNames, "/bin/linux/amd64/mounter")
+			k8sAssetsNames = append(k8sAssetsNames, "/bin/linux/"+string(arch)+"/mounter")
 		} else {
 			// Only available in the kubernetes-manifests.tar.gz directory
 			k8sAssetsNames = append(k8sAssetsNames, "/kubernetes-manifests.tar.gz")
@@ -967,16 +1004,25 @@ func (c *ApplyClusterCmd) AddFileAssets(assetBuilder *assets.AssetBuilder) error
 		if err != nil {
 			return err
 		}
-		c.Assets = append(c.Assets, hash.Hex()+"@"+u.String())
+		c.Assets[arch] = append(c.Assets[arch], hash.Hex()+"@"+u.String())
 	}
 
 	if usesCNI(c.Cluster) {
-		cniAsset, cniAssetHashString, err := findCNIAssets(c.Cluster, assetBuilder)
+		cniAsset, cniAssetHashString, err := findCNIAssets(c.Cluster, assetBuilder, arch)
 		if err != nil {
 			return err
 		}
 
-		c.Assets = append(c.Assets, cniAssetHashString+"@"+cniAsset.String())
+		c.Assets[arch] = append(c.Assets[arch], cniAssetHashString+"@"+cniAsset.String())
+	}
+
+	if needsContainerdAsset(c.Cluster, instanceGroups) {
+		containerdAsset, containerdHash, err := findContainerdAsset(c.Cluster, assetBuilder, arch)
+		if err != nil {
+			return err
+		}
+
+		c.Assets[arch] = append(c.Assets[arch], containerdHash.Hex()+"@"+containerdAsset.String())
 	}
 
 	// TODO figure out if we can only do this for CoreOS only and GCE Container OS
@@ -984,24 +1030,24 @@ func (c *ApplyClusterCmd) AddFileAssets(assetBuilder *assets.AssetBuilder) error
 	// At this time we just copy the socat binary to all distros.  Most distros will be there own
 	// socat binary.  Container operating systems like CoreOS need to have socat added to them.
 	{
-		utilsLocation, hash, err := KopsFileUrl("linux/amd64/utils.tar.gz", assetBuilder)
+		utilsLocation, hash, err := KopsFileUrl("linux/"+string(arch)+"/utils.tar.gz", assetBuilder)
 		if err != nil {
 			return err
 		}
-		c.Assets = append(c.Assets, hash.Hex()+"@"+utilsLocation.String())
+		c.Assets[arch] = append(c.Assets[arch], hash.Hex()+"@"+utilsLocation.String())
 	}
 
-	n, hash, err := NodeUpLocation(assetBuilder)
+	n, hash, err := NodeUpLocation(assetBuilder, arch)
 	if err != nil {
 		return err
 	}
-	c.NodeUpSource = n.String()
-	c.NodeUpHash = hash.Hex()
+	c.NodeUpSource[arch] = n.String()
+	c.NodeUpHash[arch] = hash.Hex()
 
 	// Explicitly add the protokube image,
 	// otherwise when the Target is DryRun this asset is not added
 	// Is there a better way to call this?
-	_, _, err = ProtokubeImageSource(assetBuilder)
+	_, _, err = ProtokubeImageSource(assetBuilder, arch)
 	if err != nil {
 		return err
 	}
@@ -1026,6 +1072,14 @@ func ChannelForCluster(c *kops.Cluster) (*kops.Channel, error) {
 	return kops.LoadChannel(channelLocation)
 }
 
+// kubernetesReleaseBaseURL returns the location of the kubernetes release files for the cluster
+func kubernetesReleaseBaseURL(cluster *kops.Cluster) string {
+	if components.IsBaseURL(cluster.Spec.KubernetesVersion) {
+		return cluster.Spec.KubernetesVersion
+	}
+	return "https://storage.googleapis.com/kubernetes-release/release/v" + cluster.Spec.KubernetesVersion
+}
+
 // needsMounterAsset checks if we need the mounter program
 // This is only needed currently on ContainerOS i.e. GCE, but we don't have a nice way to detect it yet
 func needsMounterAsset(c *kops.Cluster, instanceGroups []*kops.InstanceGroup) bool {
@@ -1080,27 +1134,43 @@ func (c *ApplyClusterCmd) BuildNodeUpConfig(assetBuilder *assets.AssetBuilder, i
 		config.Tags = append(config.Tags, tag)
 	}
 
-	config.Assets = c.Assets
+	arch := model.InstanceGroupArchitecture(&cluster.Spec, ig)
+	if _, found := c.Assets[arch]; !found {
+		return nil, fmt.Errorf("no assets were found for architecture %q of instance group %q", arch, ig.ObjectMeta.Name)
+	}
+	config.Assets = c.Assets[arch]
+	if addonAssets := c.NodeAddonAssets[ig.ObjectMeta.Name]; len(addonAssets) != 0 {
+		// The assets are shared by the instance groups of the architecture, so we copy them before adding the add-ons
+		config.Assets = append(append([]string{}, config.Assets...), addonAssets...)
+	}
 	config.ClusterName = cluster.ObjectMeta.Name
 	config.ConfigBase = fi.String(configBase.Path())
 	config.InstanceGroupName = ig.ObjectMeta.Name
 
+	if ig.Spec.NodeBundle != nil {
+		config.NodeBundle = &nodeup.NodeBundle{
+			Source: ig.Spec.NodeBundle.Location,
+			Hash:   ig.Spec.NodeBundle.Hash,
+		}
+	}
+
 	var images []*nodeup.Image
 
-	if components.IsBaseURL(cluster.Spec.KubernetesVersion) {
-		// When using a custom version, we want to preload the images over http
+	// When using a custom version, we want to preload the images over http.
+	// With a node bundle the images are preloaded from the bundle, as the node may not be able to reach a registry.
+	if components.IsBaseURL(cluster.Spec.KubernetesVersion) || ig.Spec.NodeBundle != nil {
 		components := []string{"kube-proxy"}
 		if role == kops.InstanceGroupRoleMaster {
 			components = append(components, "kube-apiserver", "kube-controller-manager", "kube-scheduler")
 		}
 
 		for _, component := range components {
-			baseURL, err := url.Parse(c.Cluster.Spec.KubernetesVersion)
+			baseURL, err := url.Parse(kubernetesReleaseBaseURL(c.Cluster))
 			if err != nil {
 				return nil, err
 			}
 
-			baseURL.Path = path.Join(baseURL.Path, "/bin/linux/amd64/", component+".tar")
+			baseURL.Path = path.Join(baseURL.Path, "/bin/linux/", string(arch), component+".tar")
 
 			u, hash, err := assetBuilder.RemapFileAndSHA(baseURL)
 			if err != nil {
@@ -1116,7 +1186,7 @@ func (c *ApplyClusterCmd) BuildNodeUpConfig(assetBuilder *assets.AssetBuilder, i
 	}
 
 	{
-		location, hash, err := ProtokubeImageSource(assetBuilder)
+		location, hash, err := ProtokubeImageSource(assetBuilder, arch)
 		if err != nil {
 			return nil, err
 		}
@@ -39,6 +39,9 @@ type DNSName struct {
 	Zone         *DNSZone
 	ResourceType *string
 
+	// DNSName is the name of the record, if it differs from Name; this allows records with the same name in different zones
+	DNSName *string
+
 	TargetLoadBalancer *LoadBalancer
 }
 
@@ -50,7 +53,7 @@ func (e *DNSName) Find(c *fi.Context) (*DNSName, error) {
 		return nil, nil
 	}
 
-	findName := fi.StringValue(e.Name)
+	findName := fi.StringValue(e.recordName())
 	if findName == "" {
 		return nil, nil
 	}
@@ -103,6 +106,7 @@ func (e *DNSName) Find(c *fi.Context) (*DNSName, error) {
 	actual := &DNSName{}
 	actual.Zone = e.Zone
 	actual.Name = e.Name
+	actual.DNSName = e.DNSName
 	actual.ResourceType = e.ResourceType
 	actual.Lifecycle = e.Lifecycle
 
@@ -136,6 +140,14 @@ func (e *DNSName) Find(c *fi.Context) (*DNSName, error) {
 	return actual, nil
 }
 
+// recordName returns the name of the DNS record
+func (e *DNSName) recordName() *string {
+	if e.DNSName != nil {
+		return e.DNSName
+	}
+	return e.Name
+}
+
 func (e *DNSName) Run(c *fi.Context) error {
 	return fi.DefaultDeltaRunMethod(e, c)
 }
@@ -151,7 +163,7 @@ func (s *DNSName) CheckChanges(a, e, changes *DNSName) error {
 
 func (_ *DNSName) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *DNSName) error {
 	rrs := &route53.ResourceRecordSet{
-		Name: e.Name,
+		Name: e.recordName(),
 		Type: e.ResourceType,
 	}
 
@@ -175,7 +187,7 @@ func (_ *DNSName) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *DNSName) error
 	request.HostedZoneId = e.Zone.ZoneID
 	request.ChangeBatch = changeBatch
 
-	glog.V(2).Infof("Updating DNS record %q", *e.Name)
+	glog.V(2).Infof("Updating DNS record %q", *e.recordName())
 
 	response, err := t.Cloud.Route53().ChangeResourceRecordSets(request)
 	if err != nil {
@@ -205,7 +217,7 @@ type terraformAlias struct {
 
 func (_ *DNSName) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *DNSName) error {
 	tf := &terraformRoute53Record{
-		Name:   e.Name,
+		Name:   e.recordName(),
 		ZoneID: e.Zone.TerraformLink(),
 		Type:   e.ResourceType,
 	}
@@ -243,7 +255,7 @@ type cloudformationAlias struct {
 
 func (_ *DNSName) RenderCloudformation(t *cloudformation.CloudformationTarget, a, e, changes *DNSName) error {
 	cf := &cloudformationRoute53Record{
-		Name:   e.Name,
+		Name:   e.recordName(),
 		ZoneID: e.Zone.CloudformationLink(),
 		Type:   e.ResourceType,
 	}
@@ -44,6 +44,15 @@ type DNSZone struct {
 
 	Private    *bool
 	PrivateVPC *VPC
+
+	// AdditionalVPCs are other VPCs which should be associated with the private zone
+	AdditionalVPCs []*DNSZoneVPC
+}
+
+// DNSZoneVPC is a VPC which is associated with a private zone, but which is not managed by kops
+type DNSZoneVPC struct {
+	ID     *string
+	Region *string
 }
 
 var _ fi.CompareWithID = &DNSZone{}
@@ -88,6 +97,14 @@ func (e *DNSZone) Find(c *fi.Context) (*DNSZone, error) {
 		}
 	}
 
+	for _, additionalVPC := range e.AdditionalVPCs {
+		for _, vpc := range z.VPCs {
+			if aws.StringValue(additionalVPC.ID) == aws.StringValue(vpc.VPCId) && aws.StringValue(additionalVPC.Region) == aws.StringValue(vpc.VPCRegion) {
+				actual.AdditionalVPCs = append(actual.AdditionalVPCs, additionalVPC)
+			}
+		}
+	}
+
 	if e.ZoneID == nil {
 		e.ZoneID = actual.ZoneID
 	}
@@ -169,6 +186,9 @@ func (s *DNSZone) CheckChanges(a, e, changes *DNSZone) error {
 	if fi.StringValue(e.Name) == "" {
 		return fi.RequiredField("Name")
 	}
+	if len(e.AdditionalVPCs) != 0 && !fi.BoolValue(e.Private) {
+		return fmt.Errorf("VPCs can only be associated with private DNS zones")
+	}
 	return nil
 }
 
@@ -195,6 +215,12 @@ func (_ *DNSZone) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *DNSZone) error
 		}
 
 		e.ZoneID = response.HostedZone.Id
+
+		for _, vpc := range e.AdditionalVPCs {
+			if err := associateVPCWithDNSZone(t, e.ZoneID, name, vpc); err != nil {
+				return err
+			}
+		}
 	} else {
 		if changes.PrivateVPC != nil {
 			request := &route53.AssociateVPCWithHostedZoneInput{
@@ -215,6 +241,19 @@ func (_ *DNSZone) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *DNSZone) error
 			}
 		}
 
+		if changes.AdditionalVPCs != nil {
+			for _, vpc := range e.AdditionalVPCs {
+				if dnsZoneHasVPC(a, vpc) {
+					continue
+				}
+				if err := associateVPCWithDNSZone(t, a.ZoneID, name, vpc); err != nil {
+					return err
+				}
+			}
+
+			changes.AdditionalVPCs = nil
+		}
+
 		empty := &DNSZone{}
 		if !reflect.DeepEqual(empty, changes) {
 			glog.Warningf("cannot apply changes to DNSZone %q: %v", name, changes)
@@ -225,9 +264,38 @@ func (_ *DNSZone) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *DNSZone) error
 	return nil
 }
 
+// dnsZoneHasVPC returns true if the additional VPC is already associated with the zone
+func dnsZoneHasVPC(zone *DNSZone, vpc *DNSZoneVPC) bool {
+	for _, v := range zone.AdditionalVPCs {
+		if aws.StringValue(v.ID) == aws.StringValue(vpc.ID) && aws.StringValue(v.Region) == aws.StringValue(vpc.Region) {
+			return true
+		}
+	}
+	return false
+}
+
+func associateVPCWithDNSZone(t *awsup.AWSAPITarget, zoneID *string, name string, vpc *DNSZoneVPC) error {
+	request := &route53.AssociateVPCWithHostedZoneInput{
+		HostedZoneId: zoneID,
+		VPC: &route53.VPC{
+			VPCId:     vpc.ID,
+			VPCRegion: vpc.Region,
+		},
+	}
+
+	glog.V(2).Infof("Associating VPC %q with DNSZone %q", aws.StringValue(vpc.ID), name)
+
+	_, err := t.Cloud.Route53().AssociateVPCWithHostedZone(request)
+	if err != nil {
+		return fmt.Errorf("error associating VPC %q with hosted zone %q: %v", aws.StringValue(vpc.ID), name, err)
+	}
+	return nil
+}
+
 type terraformRoute53ZoneAssociation struct {
 	ZoneID    *terraform.Literal   `json:"zone_id"`
 	VPCID     *terraform.Literal   `json:"vpc_id"`
+	VPCRegion *string              `json:"vpc_region,omitempty"`
 	Lifecycle *terraform.Lifecycle `json:"lifecycle,omitempty"`
 }
 
@@ -250,6 +318,28 @@ func (_ *DNSZone) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *D
 
 		e.ZoneID = z.HostedZone.Id
 
+		// Associate any additional VPCs which the zone doesn't already know about
+		for _, vpc := range e.AdditionalVPCs {
+			assocNeeded := true
+			for _, v := range z.VPCs {
+				if aws.StringValue(v.VPCId) == aws.StringValue(vpc.ID) && aws.StringValue(v.VPCRegion) == aws.StringValue(vpc.Region) {
+					assocNeeded = false
+				}
+			}
+
+			if assocNeeded {
+				glog.Infof("No association between VPC %q and zone %q; adding", aws.StringValue(vpc.ID), aws.StringValue(z.HostedZone.Name))
+				tf := &terraformRoute53ZoneAssociation{
+					ZoneID:    terraform.LiteralFromStringValue(*e.ZoneID),
+					VPCID:     terraform.LiteralFromStringValue(aws.StringValue(vpc.ID)),
+					VPCRegion: vpc.Region,
+				}
+				if err := t.RenderResource("aws_route53_zone_association", *e.Name+"-"+aws.StringValue(vpc.ID), tf); err != nil {
+					return err
+				}
+			}
+		}
+
 		// If the user specifies dns=private we'll have a non-nil PrivateVPC that specifies the VPC
 		// that should used with the private Route53 zone. If the zone doesn't already know about the
 		// VPC, we add that association.
@@ -324,6 +414,11 @@ func (_ *DNSZone) RenderCloudformation(t *cloudformation.CloudformationTarget, a
 
 		e.ZoneID = z.HostedZone.Id
 
+		if len(e.AdditionalVPCs) != 0 {
+			// cloudformation has no resource for associating a VPC with an existing hosted zone
+			glog.Warningf("cannot associate additional VPCs with existing zone %q using cloudformation; associate them manually", aws.StringValue(z.HostedZone.Name))
+		}
+
 		// Don't render a task
 		return nil
 	}
@@ -332,12 +427,21 @@ func (_ *DNSZone) RenderCloudformation(t *cloudformation.CloudformationTarget, a
 		return fmt.Errorf("Creation of public Route53 hosted zones is not supported for cloudformation")
 	}
 
+	// The task name differs from the zone name for the private zone of split-horizon DNS
+	dnsZoneName := e.DNSName
+	if dnsZoneName == nil {
+		dnsZoneName = e.Name
+	}
+
 	// We will create private zones (and delete them)
 	tf := &cloudformationRoute53Zone{
-		Name: e.Name,
+		Name: dnsZoneName,
 		VPCs: []*cloudformation.Literal{e.PrivateVPC.CloudformationLink()},
 		Tags: buildCloudformationTags(cloud.BuildTags(e.Name)),
 	}
+	for _, vpc := range e.AdditionalVPCs {
+		tf.VPCs = append(tf.VPCs, cloudformation.LiteralString(aws.StringValue(vpc.ID)))
+	}
 
 	return t.RenderResource("AWS::Route53::HostedZone", *e.Name, tf)
 }
@@ -33,6 +33,8 @@ type AWSMachineTypeInfo struct {
 	EphemeralDisks []int
 	Burstable      bool
 	GPU            bool
+	// Architecture is the machine architecture, if it is not amd64
+	Architecture string
 }
 
 type EphemeralDevice struct {
@@ -803,4 +805,41 @@ var MachineTypes []AWSMachineTypeInfo = []AWSMachineTypeInfo{
 		Cores:          64,
 		EphemeralDisks: nil,
 	},
+
+	// a1 family (AWS Graviton); AWS does not publish ECUs for these
+	{
+		Name:           "a1.medium",
+		MemoryGB:       2,
+		Cores:          1,
+		EphemeralDisks: nil,
+		Architecture:   "arm64",
+	},
+	{
+		Name:           "a1.large",
+		MemoryGB:       4,
+		Cores:          2,
+		EphemeralDisks: nil,
+		Architecture:   "arm64",
+	},
+	{
+		Name:           "a1.xlarge",
+		MemoryGB:       8,
+		Cores:          4,
+		EphemeralDisks: nil,
+		Architecture:   "arm64",
+	},
+	{
+		Name:           "a1.2xlarge",
+		MemoryGB:       16,
+		Cores:          8,
+		EphemeralDisks: nil,
+		Architecture:   "arm64",
+	},
+	{
+		Name:           "a1.4xlarge",
+		MemoryGB:       32,
+		Cores:          16,
+		EphemeralDisks: nil,
+		Architecture:   "arm64",
+	},
 }
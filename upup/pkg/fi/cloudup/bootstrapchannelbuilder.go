@@ -18,6 +18,7 @@ package cloudup
 
 import (
 	"fmt"
+	"strings"
 
 	channelsapi "k8s.io/kops/channels/pkg/api"
 	"k8s.io/kops/pkg/apis/kops"
@@ -730,5 +731,20 @@ func (b *BootstrapChannelBuilder) buildManifest() (*channelsapi.Addons, map[stri
 		manifests[key] = "addons/" + location
 	}
 
+	// kube-dns can't start until the pod network is up, so it is applied once the networking addon is healthy
+	var networkingAddon string
+	for _, addon := range addons.Spec.Addons {
+		if strings.HasPrefix(fi.StringValue(addon.Name), "networking.") {
+			networkingAddon = fi.StringValue(addon.Name)
+		}
+	}
+	if networkingAddon != "" {
+		for _, addon := range addons.Spec.Addons {
+			if fi.StringValue(addon.Name) == "kube-dns.addons.k8s.io" {
+				addon.DependsOn = []string{networkingAddon}
+			}
+		}
+	}
+
 	return addons, manifests, nil
 }
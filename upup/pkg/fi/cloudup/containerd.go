@@ -0,0 +1,67 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudup
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/golang/glog"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/model"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/architectures"
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+const (
+	// defaultContainerdVersion is the containerd release we install, if one is not specified
+	defaultContainerdVersion = "1.1.0"
+
+	// containerdAssetTemplate is the location of the containerd release tarball (with the CRI plugin and crictl),
+	// for a version and architecture.  We use the release without CNI plugins, as those are provided by the CNI asset.
+	containerdAssetTemplate = "https://storage.googleapis.com/cri-containerd-release/cri-containerd-%s.linux-%s.tar.gz"
+)
+
+// needsContainerdAsset checks if any of the instance groups use containerd, which we install from a release tarball
+func needsContainerdAsset(c *api.Cluster, instanceGroups []*api.InstanceGroup) bool {
+	return model.UsesContainerRuntime(c, instanceGroups, api.ContainerRuntimeContainerd)
+}
+
+// findContainerdAsset returns the location and hash of the containerd release for the cluster and architecture
+func findContainerdAsset(c *api.Cluster, assetBuilder *assets.AssetBuilder, arch architectures.Architecture) (*url.URL, *hashing.Hash, error) {
+	version := defaultContainerdVersion
+	if c.Spec.Containerd != nil && fi.StringValue(c.Spec.Containerd.Version) != "" {
+		version = fi.StringValue(c.Spec.Containerd.Version)
+	}
+
+	u, err := url.Parse(fmt.Sprintf(containerdAssetTemplate, version, arch))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to parse containerd asset url: %v", err)
+	}
+
+	glog.V(2).Infof("Adding containerd asset: %s", u)
+
+	// The containerd releases are published with a sha256 hash file
+	remapped, hash, err := assetBuilder.RemapFileAndSHA(u)
+	if err != nil {
+		// Not every release is published for every architecture
+		return nil, nil, fmt.Errorf("error finding containerd %s release for %s: %v", version, arch, err)
+	}
+	return remapped, hash, nil
+}
@@ -25,6 +25,9 @@ import (
 	"github.com/golang/glog"
 	"k8s.io/kops/dns-controller/pkg/dns"
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	_ "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/azure"
+	_ "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/cloudflare"
+	_ "k8s.io/kops/dnsprovider/pkg/dnsprovider/providers/rfc2136"
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider/rrstype"
 	"k8s.io/kops/pkg/apis/kops"
 	kopsdns "k8s.io/kops/pkg/dns"
@@ -40,8 +43,26 @@ const (
 	PlaceholderTTL = 10
 )
 
+// buildDNSProvider returns the DNS provider for the cluster: the external provider if one is configured, otherwise the DNS service of the cloud
+func buildDNSProvider(cluster *kops.Cluster, cloud fi.Cloud) (dnsprovider.Interface, error) {
+	if cluster.Spec.ExternalDNS == nil || cluster.Spec.ExternalDNS.Provider == "" {
+		return cloud.DNS()
+	}
+
+	provider := cluster.Spec.ExternalDNS.Provider
+	glog.V(2).Infof("Using external DNS provider %q", provider)
+	dns, err := dnsprovider.GetDnsProvider(provider, strings.NewReader(cluster.Spec.ExternalDNS.ProviderConfig))
+	if err != nil {
+		return nil, fmt.Errorf("error building DNS provider %q: %v", provider, err)
+	}
+	if dns == nil {
+		return nil, fmt.Errorf("unknown DNS provider %q", provider)
+	}
+	return dns, nil
+}
+
 func findZone(cluster *kops.Cluster, cloud fi.Cloud) (dnsprovider.Zone, error) {
-	dns, err := cloud.DNS()
+	dns, err := buildDNSProvider(cluster, cloud)
 	if err != nil {
 		return nil, fmt.Errorf("error building DNS provider: %v", err)
 	}
@@ -26,6 +26,7 @@ import (
 	api "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/kops/util"
 	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/util/pkg/architectures"
 )
 
 func usesCNI(c *api.Cluster) bool {
@@ -118,24 +119,27 @@ const (
 	defaultCNIAssetK8s1_9           = "https://storage.googleapis.com/kubernetes-release/network-plugins/cni-plugins-amd64-v0.6.0.tgz"
 	defaultCNIAssetHashStringK8s1_9 = "d595d3ded6499a64e8dac02466e2f5f2ce257c9f"
 
+	// cniAssetTemplateK8s1_9 is the CNI tarball for 1.9.x k8s on other architectures; these are published with a sha1 file
+	cniAssetTemplateK8s1_9 = "https://storage.googleapis.com/kubernetes-release/network-plugins/cni-plugins-%s-v0.6.0.tgz"
+
 	// Environment variable for overriding CNI url
 	ENV_VAR_CNI_VERSION_URL       = "CNI_VERSION_URL"
 	ENV_VAR_CNI_ASSET_HASH_STRING = "CNI_ASSET_HASH_STRING"
 )
 
-func findCNIAssets(c *api.Cluster, assetBuilder *assets.AssetBuilder) (*url.URL, string, error) {
+func findCNIAssets(c *api.Cluster, assetBuilder *assets.AssetBuilder, arch architectures.Architecture) (*url.URL, string, error) {
 
-	if cniVersionURL := os.Getenv(ENV_VAR_CNI_VERSION_URL); cniVersionURL != "" {
+	if cniVersionURL := os.Getenv(arch.EnvVar(ENV_VAR_CNI_VERSION_URL)); cniVersionURL != "" {
 		u, err := url.Parse(cniVersionURL)
 		if err != nil {
 			return nil, "", fmt.Errorf("unable to parse %q as a URL: %v", cniVersionURL, err)
 		}
 
-		glog.Infof("Using CNI asset version %q, as set in %s", cniVersionURL, ENV_VAR_CNI_VERSION_URL)
+		glog.Infof("Using CNI asset version %q, as set in %s", cniVersionURL, arch.EnvVar(ENV_VAR_CNI_VERSION_URL))
 
-		if cniAssetHashString := os.Getenv(ENV_VAR_CNI_ASSET_HASH_STRING); cniAssetHashString != "" {
+		if cniAssetHashString := os.Getenv(arch.EnvVar(ENV_VAR_CNI_ASSET_HASH_STRING)); cniAssetHashString != "" {
 
-			glog.Infof("Using CNI asset hash %q, as set in %s", cniAssetHashString, ENV_VAR_CNI_ASSET_HASH_STRING)
+			glog.Infof("Using CNI asset hash %q, as set in %s", cniAssetHashString, arch.EnvVar(ENV_VAR_CNI_ASSET_HASH_STRING))
 
 			return u, cniAssetHashString, nil
 		} else {
@@ -151,6 +155,24 @@ func findCNIAssets(c *api.Cluster, assetBuilder *assets.AssetBuilder) (*url.URL,
 	sv.Pre = nil
 	sv.Build = nil
 
+	if arch != architectures.ArchitectureAmd64 {
+		if !sv.GTE(semver.Version{Major: 1, Minor: 9, Patch: 0, Pre: nil, Build: nil}) {
+			return nil, "", fmt.Errorf("kubernetes 1.9 or later is required for %s instance groups", arch)
+		}
+
+		u, err := url.Parse(fmt.Sprintf(cniAssetTemplateK8s1_9, arch))
+		if err != nil {
+			return nil, "", err
+		}
+		glog.V(2).Infof("Adding default CNI asset for %s: %s", arch, u)
+
+		u, hash, err := assetBuilder.RemapFileAndSHA(u)
+		if err != nil {
+			return nil, "", err
+		}
+		return u, hash.Hex(), nil
+	}
+
 	var cniAsset, cniAssetHash string
 	if sv.GTE(semver.Version{Major: 1, Minor: 9, Patch: 0, Pre: nil, Build: nil}) {
 		cniAsset = defaultCNIAssetK8s1_9
@@ -22,6 +22,7 @@ import (
 
 	api "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/util/pkg/architectures"
 )
 
 func Test_FindCNIAssetFromEnvironmentVariable(t *testing.T) {
@@ -36,7 +37,7 @@ func Test_FindCNIAssetFromEnvironmentVariable(t *testing.T) {
 	cluster.Spec.KubernetesVersion = "v1.9.0"
 
 	assetBuilder := assets.NewAssetBuilder(cluster, "")
-	cniAsset, cniAssetHashString, err := findCNIAssets(cluster, assetBuilder)
+	cniAsset, cniAssetHashString, err := findCNIAssets(cluster, assetBuilder, architectures.ArchitectureAmd64)
 
 	if err != nil {
 		t.Errorf("Unable to parse k8s version %s", err)
@@ -56,7 +57,7 @@ func Test_FindCNIAssetDefaultValue1_6(t *testing.T) {
 	cluster := &api.Cluster{}
 	cluster.Spec.KubernetesVersion = "v1.7.0"
 	assetBuilder := assets.NewAssetBuilder(cluster, "")
-	cniAsset, cniAssetHashString, err := findCNIAssets(cluster, assetBuilder)
+	cniAsset, cniAssetHashString, err := findCNIAssets(cluster, assetBuilder, architectures.ArchitectureAmd64)
 
 	if err != nil {
 		t.Errorf("Unable to parse k8s version %s", err)
@@ -77,7 +78,7 @@ func Test_FindCNIAssetDefaultValue1_5(t *testing.T) {
 	cluster := &api.Cluster{}
 	cluster.Spec.KubernetesVersion = "v1.5.12"
 	assetBuilder := assets.NewAssetBuilder(cluster, "")
-	cniAsset, cniAssetHashString, err := findCNIAssets(cluster, assetBuilder)
+	cniAsset, cniAssetHashString, err := findCNIAssets(cluster, assetBuilder, architectures.ArchitectureAmd64)
 
 	if err != nil {
 		t.Errorf("Unable to parse k8s version %s", err)
@@ -92,3 +93,15 @@ func Test_FindCNIAssetDefaultValue1_5(t *testing.T) {
 	}
 
 }
+
+func Test_FindCNIAssetArm64RequiresK8s1_9(t *testing.T) {
+
+	cluster := &api.Cluster{}
+	cluster.Spec.KubernetesVersion = "v1.8.4"
+	assetBuilder := assets.NewAssetBuilder(cluster, "")
+	_, _, err := findCNIAssets(cluster, assetBuilder, architectures.ArchitectureArm64)
+
+	if err == nil {
+		t.Errorf("Expected error finding arm64 CNI asset for k8s 1.8")
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudup
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/golang/glog"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+// findNodeAddonAssets returns the assets for the binary node add-ons of the instance group, with their hashes, so nodeup
+// downloads and verifies them with the other assets.  When the cluster uses a file repository, the add-ons must already
+// have been copied there (by the assets phase); otherwise the hash is read from the source, unless it is set in the add-on.
+func findNodeAddonAssets(c *api.Cluster, assetBuilder *assets.AssetBuilder, ig *api.InstanceGroup) ([]string, error) {
+	useFileRepository := c.Spec.Assets != nil && c.Spec.Assets.FileRepository != nil && assetBuilder.Phase != string(PhaseStageAssets)
+
+	var assetIDs []string
+	for _, addon := range ig.Spec.NodeAddons {
+		if addon.Source == "" {
+			continue
+		}
+
+		u, err := url.Parse(addon.Source)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse source %q of node add-on %q: %v", addon.Source, addon.Name, err)
+		}
+
+		var expected *hashing.Hash
+		if addon.Hash != "" {
+			expected, err = hashing.FromString(addon.Hash)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse hash %q of node add-on %q: %v", addon.Hash, addon.Name, err)
+			}
+		}
+
+		glog.V(2).Infof("Adding node add-on %q asset: %s", addon.Name, u)
+
+		if expected != nil && !useFileRepository {
+			location, err := assetBuilder.RemapFileAndSHAValue(u, expected.Hex())
+			if err != nil {
+				return nil, err
+			}
+			assetIDs = append(assetIDs, expected.Hex()+"@"+location.String())
+			continue
+		}
+
+		location, hash, err := assetBuilder.RemapFileAndSHA(u)
+		if err != nil {
+			if useFileRepository {
+				return nil, fmt.Errorf("node add-on %q of instance group %q was not found in the file repository: %v", addon.Name, ig.ObjectMeta.Name, err)
+			}
+			return nil, fmt.Errorf("error finding hash of node add-on %q of instance group %q: %v", addon.Name, ig.ObjectMeta.Name, err)
+		}
+		if expected != nil && expected.Algorithm == hash.Algorithm && !expected.Equal(hash) {
+			return nil, fmt.Errorf("node add-on %q of instance group %q has hash %s in the file repository, but %s was expected", addon.Name, ig.ObjectMeta.Name, hash, expected)
+		}
+		assetIDs = append(assetIDs, hash.Hex()+"@"+location.String())
+	}
+
+	return assetIDs, nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudup
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+const testNodeAddonHash = "0f4eb0ef2b1c1c3c1fb2b4d5ce9a0e7d1dbeba3fbe3d7e9e1a1fa6b3a3b1a2c3"
+
+func buildNodeAddonInstanceGroup(hash string) *api.InstanceGroup {
+	ig := &api.InstanceGroup{}
+	ig.ObjectMeta.Name = "nodes"
+	ig.Spec.NodeAddons = []api.NodeAddonSpec{
+		{
+			Name:    "bandwidth",
+			Type:    api.NodeAddonTypeCNIPlugin,
+			Version: "0.7.1",
+			Source:  "https://example.com/cni/bandwidth",
+			Hash:    hash,
+		},
+		{
+			Name:    "nvidia-device-plugin",
+			Type:    api.NodeAddonTypeDevicePlugin,
+			Version: "1.10",
+			Image:   "nvidia/k8s-device-plugin:1.10",
+		},
+	}
+	return ig
+}
+
+func TestFindNodeAddonAssets(t *testing.T) {
+	cluster := &api.Cluster{}
+	cluster.Spec.KubernetesVersion = "v1.10.0"
+	assetBuilder := assets.NewAssetBuilder(cluster, "")
+
+	actual, err := findNodeAddonAssets(cluster, assetBuilder, buildNodeAddonInstanceGroup("sha256:"+testNodeAddonHash))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{testNodeAddonHash + "@https://example.com/cni/bandwidth"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected assets %v, got %v", expected, actual)
+	}
+}
+
+func TestFindNodeAddonAssetsFileRepository(t *testing.T) {
+	vfs.Context.ResetMemfsContext(true)
+
+	cluster := &api.Cluster{}
+	cluster.Spec.KubernetesVersion = "v1.10.0"
+	cluster.Spec.Assets = &api.Assets{FileRepository: fi.String("memfs://repository/")}
+
+	// The add-on has not been copied to the file repository
+	assetBuilder := assets.NewAssetBuilder(cluster, string(PhaseCluster))
+	_, err := findNodeAddonAssets(cluster, assetBuilder, buildNodeAddonInstanceGroup("sha256:"+testNodeAddonHash))
+	if err == nil || !strings.Contains(err.Error(), "was not found in the file repository") {
+		t.Fatalf("expected error for add-on missing from the file repository, got %v", err)
+	}
+
+	p, err := vfs.Context.BuildVfsPath("memfs://repository/cni/bandwidth.sha256")
+	if err != nil {
+		t.Fatalf("error building path: %v", err)
+	}
+	if err := p.WriteFile(bytes.NewReader([]byte(testNodeAddonHash)), nil); err != nil {
+		t.Fatalf("error writing hash file: %v", err)
+	}
+
+	actual, err := findNodeAddonAssets(cluster, assetBuilder, buildNodeAddonInstanceGroup("sha256:"+testNodeAddonHash))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{testNodeAddonHash + "@memfs://repository/cni/bandwidth"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected assets %v, got %v", expected, actual)
+	}
+
+	// The hash in the file repository must match the hash of the add-on
+	_, err = findNodeAddonAssets(cluster, assetBuilder, buildNodeAddonInstanceGroup("sha256:"+strings.Repeat("0", 64)))
+	if err == nil || !strings.Contains(err.Error(), "was expected") {
+		t.Errorf("expected error for mismatched hash, got %v", err)
+	}
+}
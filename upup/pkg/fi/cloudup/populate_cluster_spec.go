@@ -243,7 +243,7 @@ func (c *populateClusterSpec) run(clientset simple.Clientset) error {
 	}
 
 	if cluster.Spec.DNSZone == "" && !dns.IsGossipHostname(cluster.ObjectMeta.Name) {
-		dns, err := cloud.DNS()
+		dns, err := buildDNSProvider(cluster, cloud)
 		if err != nil {
 			return err
 		}
@@ -24,9 +24,11 @@ import (
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/kops/util"
 	"k8s.io/kops/pkg/apis/kops/validation"
+	"k8s.io/kops/pkg/model"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 	"k8s.io/kops/upup/pkg/fi/utils"
+	"k8s.io/kops/util/pkg/architectures"
 )
 
 // Default Machine types for various types of instance group machine
@@ -110,7 +112,7 @@ func PopulateInstanceGroupSpec(cluster *kops.Cluster, input *kops.InstanceGroup,
 	}
 
 	if ig.Spec.Image == "" {
-		ig.Spec.Image = defaultImage(cluster, channel)
+		ig.Spec.Image = defaultImage(cluster, channel, model.InstanceGroupArchitecture(&cluster.Spec, ig))
 	}
 
 	if ig.Spec.Tenancy != "" && ig.Spec.Tenancy != "default" {
@@ -207,8 +209,8 @@ func defaultMachineType(cluster *kops.Cluster, ig *kops.InstanceGroup) (string,
 	return "", nil
 }
 
-// defaultImage returns the default Image, based on the cloudprovider
-func defaultImage(cluster *kops.Cluster, channel *kops.Channel) string {
+// defaultImage returns the default Image, based on the cloudprovider and the architecture
+func defaultImage(cluster *kops.Cluster, channel *kops.Channel, arch architectures.Architecture) string {
 	if channel != nil {
 		var kubernetesVersion *semver.Version
 		if cluster.Spec.KubernetesVersion != "" {
@@ -219,7 +221,7 @@ func defaultImage(cluster *kops.Cluster, channel *kops.Channel) string {
 			}
 		}
 		if kubernetesVersion != nil {
-			image := channel.FindImage(kops.CloudProviderID(cluster.Spec.CloudProvider), *kubernetesVersion)
+			image := channel.FindImage(kops.CloudProviderID(cluster.Spec.CloudProvider), *kubernetesVersion, string(arch))
 			if image != nil {
 				return image.Name
 			}
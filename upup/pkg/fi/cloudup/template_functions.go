@@ -29,6 +29,7 @@ package cloudup
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"strconv"
 	"strings"
@@ -84,6 +85,8 @@ func (tf *TemplateFunctions) AddTo(dest template.FuncMap) {
 	}
 
 	dest["DnsControllerArgv"] = tf.DnsControllerArgv
+	dest["DnsControllerProvider"] = tf.DnsControllerProvider
+	dest["DnsControllerProviderConfig"] = tf.DnsControllerProviderConfig
 	dest["ExternalDnsArgv"] = tf.ExternalDnsArgv
 
 	// TODO: Only for GCE?
@@ -175,11 +178,46 @@ func (tf *TemplateFunctions) DnsControllerArgv() ([]string, error) {
 		if tf.cluster.Spec.ExternalDNS.WatchNamespace != "" {
 			argv = append(argv, fmt.Sprintf("--watch-namespace=%s", tf.cluster.Spec.ExternalDNS.WatchNamespace))
 		}
+		if tf.cluster.Spec.ExternalDNS.TXTOwnerID != "" {
+			argv = append(argv, fmt.Sprintf("--txt-owner-id=%s", tf.cluster.Spec.ExternalDNS.TXTOwnerID))
+		}
 	}
 
 	if dns.IsGossipHostname(tf.cluster.Spec.MasterInternalName) {
 		argv = append(argv, "--dns=gossip")
-		argv = append(argv, "--gossip-seed=127.0.0.1:3999")
+
+		// We seed the dns-controller with the protokube on the same master, which uses the host network
+		seedPort := "3999"
+		seedPortSecondary := "4000"
+		if g := tf.cluster.Spec.GossipConfig; g != nil {
+			if g.Listen != nil {
+				_, port, err := net.SplitHostPort(*g.Listen)
+				if err != nil {
+					return nil, fmt.Errorf("error parsing gossip listen address %q: %v", *g.Listen, err)
+				}
+				seedPort = port
+			}
+			if g.Protocol != nil {
+				argv = append(argv, "--gossip-protocol="+*g.Protocol)
+			}
+			if g.Secondary != nil && g.Secondary.Protocol != nil {
+				if g.Secondary.Listen != nil {
+					_, port, err := net.SplitHostPort(*g.Secondary.Listen)
+					if err != nil {
+						return nil, fmt.Errorf("error parsing secondary gossip listen address %q: %v", *g.Secondary.Listen, err)
+					}
+					seedPortSecondary = port
+				}
+				argv = append(argv, "--gossip-protocol-secondary="+*g.Secondary.Protocol)
+				argv = append(argv, "--gossip-seed-secondary=127.0.0.1:"+seedPortSecondary)
+			}
+		}
+		argv = append(argv, "--gossip-seed=127.0.0.1:"+seedPort)
+	} else if provider := tf.DnsControllerProvider(); provider != "" {
+		argv = append(argv, "--dns="+provider)
+		if tf.DnsControllerProviderConfig() != "" {
+			argv = append(argv, "--dns-provider-config="+DnsControllerProviderConfigPath)
+		}
 	} else {
 		switch kops.CloudProviderID(tf.cluster.Spec.CloudProvider) {
 		case kops.CloudProviderAWS:
@@ -202,7 +240,12 @@ func (tf *TemplateFunctions) DnsControllerArgv() ([]string, error) {
 	}
 
 	zone := tf.cluster.Spec.DNSZone
-	if zone != "" {
+	topology := tf.cluster.Spec.Topology
+	if zone != "" && topology != nil && topology.DNS != nil && topology.DNS.Type == kops.DNSTypeSplitHorizon {
+		// publish external records in the public zone and internal records in the private zone of the same name
+		argv = append(argv, "--zone="+zone+":external")
+		argv = append(argv, "--zone="+zone+":internal")
+	} else if zone != "" {
 		if strings.Contains(zone, ".") {
 			// match by name
 			argv = append(argv, "--zone="+zone)
@@ -219,6 +262,25 @@ func (tf *TemplateFunctions) DnsControllerArgv() ([]string, error) {
 	return argv, nil
 }
 
+// DnsControllerProviderConfigPath is where the dns-controller addon mounts the configuration of the DNS provider
+const DnsControllerProviderConfigPath = "/etc/dns-controller/provider.conf"
+
+// DnsControllerProvider returns the external DNS provider the dns-controller should use, or "" to use the DNS of the cloud
+func (tf *TemplateFunctions) DnsControllerProvider() string {
+	if tf.cluster.Spec.ExternalDNS == nil || dns.IsGossipHostname(tf.cluster.Spec.MasterInternalName) {
+		return ""
+	}
+	return tf.cluster.Spec.ExternalDNS.Provider
+}
+
+// DnsControllerProviderConfig returns the configuration file of the external DNS provider
+func (tf *TemplateFunctions) DnsControllerProviderConfig() string {
+	if tf.DnsControllerProvider() == "" {
+		return ""
+	}
+	return tf.cluster.Spec.ExternalDNS.ProviderConfig
+}
+
 func (tf *TemplateFunctions) ExternalDnsArgv() ([]string, error) {
 	var argv []string
 
@@ -26,6 +26,7 @@ import (
 	"github.com/golang/glog"
 	"k8s.io/kops"
 	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/util/pkg/architectures"
 	"k8s.io/kops/util/pkg/hashing"
 )
 
@@ -33,17 +34,17 @@ const defaultKopsBaseUrl = "https://kubeupv2.s3.amazonaws.com/kops/%s/"
 
 var kopsBaseUrl *url.URL
 
-// nodeUpLocation caches the nodeUpLocation url
-var nodeUpLocation *url.URL
+// nodeUpLocation caches the nodeUpLocation url for each architecture
+var nodeUpLocation = make(map[architectures.Architecture]*url.URL)
 
-// nodeUpHash caches the hash for nodeup
-var nodeUpHash *hashing.Hash
+// nodeUpHash caches the hash for nodeup for each architecture
+var nodeUpHash = make(map[architectures.Architecture]*hashing.Hash)
 
-// protokubeLocation caches the protokubeLocation url
-var protokubeLocation *url.URL
+// protokubeLocation caches the protokubeLocation url for each architecture
+var protokubeLocation = make(map[architectures.Architecture]*url.URL)
 
-// protokubeHash caches the hash for protokube
-var protokubeHash *hashing.Hash
+// protokubeHash caches the hash for protokube for each architecture
+var protokubeHash = make(map[architectures.Architecture]*hashing.Hash)
 
 // BaseUrl returns the base url for the distribution of kops - in particular for nodeup & docker images
 func BaseUrl() (*url.URL, error) {
@@ -97,36 +98,41 @@ func SetKopsAssetsLocations(assetsBuilder *assets.AssetBuilder) error {
 	return nil
 }
 
-// NodeUpLocation returns the URL where nodeup should be downloaded
-func NodeUpLocation(assetsBuilder *assets.AssetBuilder) (*url.URL, *hashing.Hash, error) {
+// NodeUpLocation returns the URL where nodeup should be downloaded, for machines of the specified architecture
+func NodeUpLocation(assetsBuilder *assets.AssetBuilder, arch architectures.Architecture) (*url.URL, *hashing.Hash, error) {
 	// Avoid repeated logging
-	if nodeUpLocation != nil && nodeUpHash != nil {
+	if nodeUpLocation[arch] != nil && nodeUpHash[arch] != nil {
 		// Avoid repeated logging
-		glog.V(8).Infof("Using cached nodeup location: %q", nodeUpLocation.String())
-		return nodeUpLocation, nodeUpHash, nil
+		glog.V(8).Infof("Using cached nodeup location: %q", nodeUpLocation[arch].String())
+		return nodeUpLocation[arch], nodeUpHash[arch], nil
 	}
-	env := os.Getenv("NODEUP_URL")
+	envVar := arch.EnvVar("NODEUP_URL")
+	env := os.Getenv(envVar)
+	var location *url.URL
+	var hash *hashing.Hash
 	var err error
 	if env == "" {
-		nodeUpLocation, nodeUpHash, err = KopsFileUrl("linux/amd64/nodeup", assetsBuilder)
+		location, hash, err = KopsFileUrl("linux/"+string(arch)+"/nodeup", assetsBuilder)
 		if err != nil {
 			return nil, nil, err
 		}
-		glog.V(8).Infof("Using default nodeup location: %q", nodeUpLocation.String())
+		glog.V(8).Infof("Using default nodeup location: %q", location.String())
 	} else {
-		nodeUpLocation, err = url.Parse(env)
+		location, err = url.Parse(env)
 		if err != nil {
-			return nil, nil, fmt.Errorf("unable to parse env var NODEUP_URL %q as an url: %v", env, err)
+			return nil, nil, fmt.Errorf("unable to parse env var %s %q as an url: %v", envVar, env, err)
 		}
 
-		nodeUpLocation, nodeUpHash, err = assetsBuilder.RemapFileAndSHA(nodeUpLocation)
+		location, hash, err = assetsBuilder.RemapFileAndSHA(location)
 		if err != nil {
 			return nil, nil, err
 		}
-		glog.Warningf("Using nodeup location from NODEUP_URL env var: %q", nodeUpLocation.String())
+		glog.Warningf("Using nodeup location from %s env var: %q", envVar, location.String())
 	}
 
-	return nodeUpLocation, nodeUpHash, nil
+	nodeUpLocation[arch] = location
+	nodeUpHash[arch] = hash
+	return location, hash, nil
 }
 
 // TODO make this a container when hosted assets
@@ -135,35 +141,45 @@ func NodeUpLocation(assetsBuilder *assets.AssetBuilder) (*url.URL, *hashing.Hash
 
 // ProtokubeImageSource returns the source for the docker image for protokube.
 // Either a docker name (e.g. gcr.io/protokube:1.4), or a URL (https://...) in which case we download
-// the contents of the url and docker load it
-func ProtokubeImageSource(assetsBuilder *assets.AssetBuilder) (*url.URL, *hashing.Hash, error) {
+// the contents of the url and docker load it.
+// The amd64 image is images/protokube.tar.gz, other architectures have a suffix e.g. images/protokube-arm64.tar.gz
+func ProtokubeImageSource(assetsBuilder *assets.AssetBuilder, arch architectures.Architecture) (*url.URL, *hashing.Hash, error) {
 	// Avoid repeated logging
-	if protokubeLocation != nil && protokubeHash != nil {
-		glog.V(8).Infof("Using cached protokube location: %q", protokubeLocation)
-		return protokubeLocation, protokubeHash, nil
+	if protokubeLocation[arch] != nil && protokubeHash[arch] != nil {
+		glog.V(8).Infof("Using cached protokube location: %q", protokubeLocation[arch])
+		return protokubeLocation[arch], protokubeHash[arch], nil
 	}
-	env := os.Getenv("PROTOKUBE_IMAGE")
+	envVar := arch.EnvVar("PROTOKUBE_IMAGE")
+	env := os.Getenv(envVar)
+	var location *url.URL
+	var hash *hashing.Hash
 	var err error
 	if env == "" {
-		protokubeLocation, protokubeHash, err = KopsFileUrl("images/protokube.tar.gz", assetsBuilder)
+		file := "images/protokube.tar.gz"
+		if arch != architectures.ArchitectureAmd64 {
+			file = "images/protokube-" + string(arch) + ".tar.gz"
+		}
+		location, hash, err = KopsFileUrl(file, assetsBuilder)
 		if err != nil {
 			return nil, nil, err
 		}
-		glog.V(8).Infof("Using default protokube location: %q", protokubeLocation)
+		glog.V(8).Infof("Using default protokube location: %q", location)
 	} else {
 		protokubeImageSource, err := url.Parse(env)
 		if err != nil {
-			return nil, nil, fmt.Errorf("unable to parse env var PROTOKUBE_IMAGE %q as an url: %v", env, err)
+			return nil, nil, fmt.Errorf("unable to parse env var %s %q as an url: %v", envVar, env, err)
 		}
 
-		protokubeLocation, protokubeHash, err = assetsBuilder.RemapFileAndSHA(protokubeImageSource)
+		location, hash, err = assetsBuilder.RemapFileAndSHA(protokubeImageSource)
 		if err != nil {
 			return nil, nil, err
 		}
-		glog.Warningf("Using protokube location from PROTOKUBE_IMAGE env var: %q", protokubeLocation)
+		glog.Warningf("Using protokube location from %s env var: %q", envVar, location)
 	}
 
-	return protokubeLocation, protokubeHash, nil
+	protokubeLocation[arch] = location
+	protokubeHash[arch] = hash
+	return location, hash, nil
 }
 
 // KopsFileUrl returns the base url for the distribution of kops - in particular for nodeup & docker images
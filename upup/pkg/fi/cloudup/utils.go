@@ -152,6 +152,13 @@ func FindDNSHostedZone(dns dnsprovider.Interface, clusterDNSName string, dnsType
 
 	clusterDNSName = "." + strings.TrimSuffix(clusterDNSName, ".")
 
+	// With split-horizon DNS we look for the public zone, and identify it by name,
+	// because the private zone we manage alongside it is created with the same name
+	splitHorizon := dnsType == kops.DNSTypeSplitHorizon
+	if splitHorizon {
+		dnsType = kops.DNSTypePublic
+	}
+
 	zonesProvider, ok := dns.Zones()
 	if !ok {
 		return "", fmt.Errorf("dns provider %T does not support zones", dns)
@@ -218,6 +225,9 @@ func FindDNSHostedZone(dns dnsprovider.Interface, clusterDNSName string, dnsType
 	}
 
 	if len(maxLengthZones) == 1 {
+		if splitHorizon {
+			return strings.TrimSuffix(maxLengthZones[0].Name(), "."), nil
+		}
 		id := maxLengthZones[0].ID()
 		id = strings.TrimPrefix(id, "/hostedzone/")
 		return id, nil
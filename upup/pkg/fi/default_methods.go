@@ -106,6 +106,40 @@ func DefaultDeltaRunMethod(e Task, c *Context) error {
 	return nil
 }
 
+// DeltaChanges runs the find and check-changes steps of DefaultDeltaRunMethod, without rendering anything.
+// It returns whether the task was found, and the names of the fields that differ from the expected state.
+func DeltaChanges(e Task, c *Context) (bool, []string, error) {
+	a, err := invokeFind(e, c)
+	if err != nil {
+		return false, nil, err
+	}
+
+	found := a != nil
+	if !found {
+		a = reflect.New(reflect.TypeOf(e)).Elem().Interface().(Task)
+	}
+
+	changes := reflect.New(reflect.TypeOf(e).Elem()).Interface().(Task)
+	if !BuildChanges(a, e, changes) {
+		return found, nil, nil
+	}
+
+	if err := invokeCheckChanges(a, e, changes); err != nil {
+		return found, nil, err
+	}
+
+	changeList, err := buildChangeList(a, e, changes)
+	if err != nil {
+		return found, nil, err
+	}
+
+	var fields []string
+	for _, change := range changeList {
+		fields = append(fields, change.FieldName)
+	}
+	return found, fields, nil
+}
+
 // invokeCheckChanges calls the checkChanges method by reflection
 func invokeCheckChanges(a, e, changes Task) error {
 	rv, err := utils.InvokeMethod(e, "CheckChanges", a, e, changes)
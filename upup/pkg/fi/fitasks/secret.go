@@ -82,7 +82,7 @@ func (_ *Secret) Render(c *fi.Context, a, e, changes *Secret) error {
 
 	secrets := c.SecretStore
 
-	secret, err := fi.CreateSecret()
+	secret, err := fi.CreateStaticToken()
 	if err != nil {
 		return fmt.Errorf("error creating secret %q: %v", name, err)
 	}
@@ -22,9 +22,11 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"strings"
 
 	"github.com/golang/glog"
 	"k8s.io/kops/util/pkg/hashing"
+	"k8s.io/kops/util/pkg/vfs"
 )
 
 func DownloadURL(url string, dest string, hash *hashing.Hash) (*hashing.Hash, error) {
@@ -38,6 +40,17 @@ func DownloadURL(url string, dest string, hash *hashing.Hash) (*hashing.Hash, er
 		}
 	}
 
+	cache := vfs.Context.Cache()
+	if hash != nil && cache != nil {
+		found, err := cache.CopyFile(hash, dest)
+		if err != nil {
+			glog.Warningf("error reading %q from cache: %v", url, err)
+		} else if found {
+			glog.V(2).Infof("VFS cache hit for %q (%s)", url, hash)
+			return hash, nil
+		}
+	}
+
 	dirMode := os.FileMode(0755)
 	err := downloadURLAlways(url, dest, dirMode)
 	if err != nil {
@@ -52,6 +65,11 @@ func DownloadURL(url string, dest string, hash *hashing.Hash) (*hashing.Hash, er
 		if !match {
 			return nil, fmt.Errorf("downloaded from %q but hash did not match expected %q", url, hash)
 		}
+		if cache != nil {
+			if err := cache.AddFile(hash, dest); err != nil {
+				glog.Warningf("error adding %q to cache: %v", url, err)
+			}
+		}
 	} else {
 		hash, err = hashing.HashAlgorithmSHA256.HashFile(dest)
 		if err != nil {
@@ -74,6 +92,10 @@ func downloadURLAlways(url string, destPath string, dirMode os.FileMode) error {
 	}
 	defer output.Close()
 
+	if strings.HasPrefix(url, "file://") {
+		return copyLocalFile(strings.TrimPrefix(url, "file://"), output)
+	}
+
 	glog.Infof("Downloading %q", url)
 
 	response, err := http.Get(url)
@@ -88,3 +110,19 @@ func downloadURLAlways(url string, destPath string, dirMode os.FileMode) error {
 	}
 	return nil
 }
+
+// copyLocalFile copies a file:// source, which is already on the machine (for example in a node bundle)
+func copyLocalFile(p string, output io.Writer) error {
+	glog.Infof("Copying %q", p)
+
+	f, err := os.Open(p)
+	if err != nil {
+		return fmt.Errorf("error opening %q: %v", p, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(output, f); err != nil {
+		return fmt.Errorf("error copying %q: %v", p, err)
+	}
+	return nil
+}
@@ -0,0 +1,334 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kops/nodeup/pkg/bootstrap"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/nodeagent"
+	"k8s.io/kops/pkg/systemd"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/local"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+	"k8s.io/kops/upup/pkg/fi/utils"
+)
+
+const (
+	// agentStateDir is where nodeup keeps the configuration it last applied, for the nodeup agent
+	agentStateDir = "/var/lib/kops/nodeup-agent"
+	// appliedClusterFile and appliedInstanceGroupFile are the files in agentStateDir holding the applied configuration
+	appliedClusterFile       = "cluster.yaml"
+	appliedInstanceGroupFile = "instancegroup.yaml"
+
+	// agentServiceName is the systemd unit which runs the nodeup agent
+	agentServiceName = "kops-nodeup-agent.service"
+	// agentTimerName is the systemd timer which periodically starts agentServiceName
+	agentTimerName = "kops-nodeup-agent.timer"
+
+	// agentMaxTaskDuration is how long the agent keeps retrying tasks; unlike at boot, the agent will run again later
+	agentMaxTaskDuration = 5 * time.Minute
+
+	// kubeletKubeconfig is the kubeconfig the agent uses to annotate the node
+	kubeletKubeconfig = "/var/lib/kubelet/kubeconfig"
+)
+
+// errAgentDisabled is returned when the nodeup agent runs, but is no longer enabled for the node
+var errAgentDisabled = errors.New("nodeup agent is not enabled")
+
+// RunAgent re-reads the configuration from the state store and applies the changes which can be made in place
+// to the running node.  The outcome, and any changes which require the node to be replaced, are recorded as annotations on the node.
+func (c *NodeUpCommand) RunAgent() error {
+	c.agent = true
+	c.report = newBootReport(nodeup.BootReportModeAgent)
+
+	runErr := c.runAgent()
+	if runErr == errAgentDisabled {
+		glog.Infof("nodeup agent is not enabled for this node; nothing to do")
+		return nil
+	}
+	if runErr != nil {
+		glog.Warningf("error running nodeup agent: %v", runErr)
+	}
+	if len(c.replacementChanges) != 0 {
+		glog.Warningf("settings which require the node to be replaced have changed: %s", strings.Join(c.replacementChanges, ", "))
+	}
+
+	if err := c.annotateNode(runErr); err != nil {
+		glog.Warningf("error annotating node: %v", err)
+	}
+
+	return runErr
+}
+
+// runAgent builds and runs the tasks for the current configuration, with the changes which require replacement left out
+func (c *NodeUpCommand) runAgent() error {
+	if c.Target != "direct" {
+		return fmt.Errorf("nodeup agent only supports the direct target, not %q", c.Target)
+	}
+
+	taskMap, err := c.buildTasks()
+	if err != nil {
+		return err
+	}
+
+	target := &local.LocalTarget{
+		CacheDir: c.CacheDir,
+		Tags:     c.nodeTags,
+	}
+	if err := c.runTasks(target, true, taskMap, agentMaxTaskDuration); err != nil {
+		return err
+	}
+
+	return c.saveAppliedConfig()
+}
+
+// mergeAppliedConfig replaces the loaded configuration with the configuration last applied to the node,
+// updated with the settings which the agent changes in place
+func (c *NodeUpCommand) mergeAppliedConfig() error {
+	dir := path.Join(c.FSRoot, agentStateDir)
+
+	applied := &api.Cluster{}
+	if err := readYaml(path.Join(dir, appliedClusterFile), applied); err != nil {
+		return err
+	}
+
+	var appliedIG *api.InstanceGroup
+	if c.instanceGroup != nil {
+		appliedIG = &api.InstanceGroup{}
+		if err := readYaml(path.Join(dir, appliedInstanceGroupFile), appliedIG); err != nil {
+			return err
+		}
+	}
+
+	c.replacementChanges = nodeagent.ReplacementChanges(applied, appliedIG, c.cluster, c.instanceGroup)
+	nodeagent.ApplyInPlaceChanges(applied, appliedIG, c.cluster, c.instanceGroup)
+
+	c.cluster = applied
+	c.instanceGroup = appliedIG
+	return nil
+}
+
+// saveAppliedConfig records the configuration applied to the node, so the agent can tell which settings have changed since
+func (c *NodeUpCommand) saveAppliedConfig() error {
+	dir := path.Join(c.FSRoot, agentStateDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("error creating directory %q: %v", dir, err)
+	}
+
+	if err := writeYaml(path.Join(dir, appliedClusterFile), c.appliedCluster); err != nil {
+		return err
+	}
+	if c.appliedInstanceGroup != nil {
+		if err := writeYaml(path.Join(dir, appliedInstanceGroupFile), c.appliedInstanceGroup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readYaml(p string, dest interface{}) error {
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("applied configuration %q not found; the node must be configured by nodeup before the agent can run", p)
+		}
+		return fmt.Errorf("error reading %q: %v", p, err)
+	}
+	if err := utils.YamlUnmarshal(b, dest); err != nil {
+		return fmt.Errorf("error parsing %q: %v", p, err)
+	}
+	return nil
+}
+
+func writeYaml(p string, o interface{}) error {
+	b, err := utils.YamlMarshal(o)
+	if err != nil {
+		return fmt.Errorf("error serializing %q: %v", p, err)
+	}
+	// The configuration can include secrets, such as the docker registry credentials
+	if err := ioutil.WriteFile(p, b, 0600); err != nil {
+		return fmt.Errorf("error writing %q: %v", p, err)
+	}
+	return nil
+}
+
+// addAgentTasks adds the systemd units which periodically run the nodeup agent
+func (c *NodeUpCommand) addAgentTasks(taskMap map[string]fi.Task) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("error finding nodeup executable: %v", err)
+	}
+
+	service, timer := buildAgentUnits(executable, c.ConfigLocation, c.CacheDir, c.FSRoot, nodeagent.Interval(&c.cluster.Spec, c.instanceGroup))
+	taskMap[agentServiceName] = service
+	taskMap[agentTimerName] = timer
+	return nil
+}
+
+// buildAgentUnits builds the service which runs the nodeup agent, and the timer which starts it
+func buildAgentUnits(executable string, configLocation string, cacheDir string, fsRoot string, interval time.Duration) (*nodetasks.Service, *nodetasks.Service) {
+	command := []string{
+		executable,
+		"agent",
+		"--conf=" + configLocation,
+		"--cache=" + cacheDir,
+		"--rootfs=" + fsRoot,
+		"--v=2",
+	}
+
+	manifest := &systemd.Manifest{}
+	manifest.Set("Unit", "Description", "Apply configuration changes to the node (nodeup agent)")
+	manifest.Set("Unit", "Documentation", "https://github.com/kubernetes/kops")
+	if env := bootstrap.ServiceEnvironment(); env != "" {
+		manifest.Set("Service", "Environment", env)
+	}
+	manifest.Set("Service", "EnvironmentFile", "/etc/environment")
+	manifest.Set("Service", "ExecStart", strings.Join(command, " "))
+	manifest.Set("Service", "Type", "oneshot")
+
+	// The service is only started by the timer; nodeup must not start or stop it, as the agent is itself run by the service
+	service := &nodetasks.Service{
+		Name:        agentServiceName,
+		Definition:  fi.String(manifest.Render()),
+		Running:     fi.Bool(false),
+		ManageState: fi.Bool(false),
+	}
+	service.InitDefaults()
+
+	seconds := fmt.Sprintf("%ds", int64(interval/time.Second))
+	timerManifest := &systemd.Manifest{}
+	timerManifest.Set("Unit", "Description", "Periodically run the nodeup agent")
+	timerManifest.Set("Unit", "Documentation", "https://github.com/kubernetes/kops")
+	timerManifest.Set("Timer", "OnBootSec", seconds)
+	timerManifest.Set("Timer", "OnUnitInactiveSec", seconds)
+	timerManifest.Set("Install", "WantedBy", "timers.target")
+
+	timer := &nodetasks.Service{
+		Name:       agentTimerName,
+		Definition: fi.String(timerManifest.Render()),
+	}
+	timer.InitDefaults()
+
+	return service, timer
+}
+
+// nodeName returns the name of the node in kubernetes, which is the hostname unless the kubelet overrides it
+func (c *NodeUpCommand) nodeName() (string, error) {
+	kubelet := c.cluster.Spec.Kubelet
+	for _, tag := range c.config.Tags {
+		if tag == TagMaster {
+			kubelet = c.cluster.Spec.MasterKubelet
+		}
+	}
+	if kubelet != nil {
+		// The spec is not yet evaluated if the agent failed early
+		hostnameOverride, err := evaluateHostnameOverride(kubelet.HostnameOverride)
+		if err != nil {
+			return "", err
+		}
+		if hostnameOverride != "" {
+			return hostnameOverride, nil
+		}
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("error getting hostname: %v", err)
+	}
+	return strings.ToLower(strings.TrimSpace(hostname)), nil
+}
+
+type agentNodePatch struct {
+	Metadata *agentNodePatchMetadata `json:"metadata,omitempty"`
+}
+
+type agentNodePatchMetadata struct {
+	// Annotations with a nil value are removed from the node
+	Annotations map[string]*string `json:"annotations,omitempty"`
+}
+
+// buildAgentNodePatch builds the patch recording the outcome of the agent run on the node
+func buildAgentNodePatch(now time.Time, runErr error, replacementChanges []string) ([]byte, error) {
+	annotations := map[string]*string{
+		nodeagent.AnnotationLastRun:             fi.String(now.UTC().Format(time.RFC3339)),
+		nodeagent.AnnotationStatus:              fi.String(nodeagent.StatusApplied),
+		nodeagent.AnnotationError:               nil,
+		nodeagent.AnnotationReplacementRequired: nil,
+	}
+	if runErr != nil {
+		annotations[nodeagent.AnnotationStatus] = fi.String(nodeagent.StatusFailed)
+		annotations[nodeagent.AnnotationError] = fi.String(runErr.Error())
+	}
+	if len(replacementChanges) != 0 {
+		annotations[nodeagent.AnnotationReplacementRequired] = fi.String(strings.Join(replacementChanges, ","))
+	}
+
+	patch := &agentNodePatch{
+		Metadata: &agentNodePatchMetadata{Annotations: annotations},
+	}
+	b, err := json.Marshal(patch)
+	if err != nil {
+		return nil, fmt.Errorf("error building node patch: %v", err)
+	}
+	return b, nil
+}
+
+// annotateNode records the outcome of the agent run as annotations on the node
+func (c *NodeUpCommand) annotateNode(runErr error) error {
+	if c.cluster == nil || c.config == nil {
+		return fmt.Errorf("node configuration was not loaded")
+	}
+
+	name, err := c.nodeName()
+	if err != nil {
+		return err
+	}
+
+	patch, err := buildAgentNodePatch(time.Now(), runErr, c.replacementChanges)
+	if err != nil {
+		return err
+	}
+
+	config, err := clientcmd.BuildConfigFromFlags("", path.Join(c.FSRoot, kubeletKubeconfig))
+	if err != nil {
+		return fmt.Errorf("cannot load kubeconfig %q: %v", kubeletKubeconfig, err)
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("cannot build kube client: %v", err)
+	}
+
+	glog.V(2).Infof("sending patch for node %q: %q", name, string(patch))
+	if _, err := client.CoreV1().Nodes().Patch(name, types.StrategicMergePatchType, patch); err != nil {
+		return fmt.Errorf("error applying patch to node %q: %v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func TestBuildAgentUnits(t *testing.T) {
+	service, timer := buildAgentUnits("/opt/nodeup", "/var/cache/kubernetes-install/kube_env.yaml", "/var/cache/nodeup", "/", 15*time.Minute)
+
+	definition := fi.StringValue(service.Definition)
+	if !strings.Contains(definition, "ExecStart=/opt/nodeup agent --conf=/var/cache/kubernetes-install/kube_env.yaml --cache=/var/cache/nodeup --rootfs=/ --v=2\n") {
+		t.Errorf("unexpected agent service:\n%s", definition)
+	}
+	if fi.BoolValue(service.ManageState) || fi.BoolValue(service.Running) {
+		t.Errorf("nodeup must not manage the state of the agent service")
+	}
+
+	definition = fi.StringValue(timer.Definition)
+	for _, expected := range []string{"OnBootSec=900s\n", "OnUnitInactiveSec=900s\n", "WantedBy=timers.target\n"} {
+		if !strings.Contains(definition, expected) {
+			t.Errorf("expected %q in agent timer:\n%s", expected, definition)
+		}
+	}
+	if !fi.BoolValue(timer.Running) || !fi.BoolValue(timer.Enabled) {
+		t.Errorf("expected agent timer to be running and enabled")
+	}
+}
+
+func TestBuildAgentNodePatch(t *testing.T) {
+	now := time.Date(2018, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	grid := []struct {
+		Err                error
+		ReplacementChanges []string
+		Expected           string
+	}{
+		{
+			Expected: `{"metadata":{"annotations":{"kops.k8s.io/nodeup-agent-error":null,"kops.k8s.io/nodeup-agent-last-run":"2018-09-01T12:00:00Z","kops.k8s.io/nodeup-agent-replacement-required":null,"kops.k8s.io/nodeup-agent-status":"Applied"}}}`,
+		},
+		{
+			Err:                errors.New("error running tasks"),
+			ReplacementChanges: []string{"spec.kubernetesVersion", "instanceGroup.spec.image"},
+			Expected:           `{"metadata":{"annotations":{"kops.k8s.io/nodeup-agent-error":"error running tasks","kops.k8s.io/nodeup-agent-last-run":"2018-09-01T12:00:00Z","kops.k8s.io/nodeup-agent-replacement-required":"spec.kubernetesVersion,instanceGroup.spec.image","kops.k8s.io/nodeup-agent-status":"Failed"}}}`,
+		},
+	}
+	for i, g := range grid {
+		actual, err := buildAgentNodePatch(now, g.Err, g.ReplacementChanges)
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+		if string(actual) != g.Expected {
+			t.Errorf("case %d: expected patch %s, got %s", i, g.Expected, actual)
+		}
+	}
+}
+
+func TestMergeAppliedConfig(t *testing.T) {
+	fsRoot, err := ioutil.TempDir("", "nodeup-agent")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(fsRoot)
+
+	newConfig := func() (*api.Cluster, *api.InstanceGroup) {
+		cluster := &api.Cluster{
+			Spec: api.ClusterSpec{
+				KubernetesVersion: "1.10.0",
+				Kubelet:           &api.KubeletConfigSpec{MaxPods: fi.Int32(110)},
+			},
+		}
+		ig := &api.InstanceGroup{
+			Spec: api.InstanceGroupSpec{
+				Role:  api.InstanceGroupRoleNode,
+				Image: "kope.io/k8s-1.10-debian-stretch-amd64-hvm-ebs-2018-08-17",
+			},
+		}
+		return cluster, ig
+	}
+
+	c := &NodeUpCommand{FSRoot: fsRoot}
+	c.appliedCluster, c.appliedInstanceGroup = newConfig()
+	if err := c.saveAppliedConfig(); err != nil {
+		t.Fatalf("error saving applied configuration: %v", err)
+	}
+
+	c.cluster, c.instanceGroup = newConfig()
+	c.cluster.Spec.KubernetesVersion = "1.10.5"
+	c.cluster.Spec.Kubelet.MaxPods = fi.Int32(200)
+	if err := c.mergeAppliedConfig(); err != nil {
+		t.Fatalf("error merging applied configuration: %v", err)
+	}
+
+	if !reflect.DeepEqual(c.replacementChanges, []string{"spec.kubernetesVersion"}) {
+		t.Errorf("unexpected replacement changes: %v", c.replacementChanges)
+	}
+	if c.cluster.Spec.KubernetesVersion != "1.10.0" {
+		t.Errorf("expected kubernetes version to be left at the applied version, got %q", c.cluster.Spec.KubernetesVersion)
+	}
+	if fi.Int32Value(c.cluster.Spec.Kubelet.MaxPods) != 200 {
+		t.Errorf("expected kubelet change to be applied in place, got maxPods %d", fi.Int32Value(c.cluster.Spec.Kubelet.MaxPods))
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kops"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+// newBootReport starts a boot report for this run of nodeup
+func newBootReport(mode nodeup.BootReportMode) *nodeup.BootReport {
+	hostname, err := os.Hostname()
+	if err != nil {
+		glog.Warningf("error getting hostname for boot report: %v", err)
+	}
+
+	return &nodeup.BootReport{
+		Hostname:      hostname,
+		NodeupVersion: kops.Version,
+		Mode:          mode,
+		StartTime:     time.Now().UTC(),
+	}
+}
+
+// finishBootReport records the outcome of nodeup in the boot report, then writes it to the node and
+// (if enabled) to the state store.  Only a direct run of nodeup writes a report, as the other targets do not change the node.
+func (c *NodeUpCommand) finishBootReport(runErr error) {
+	if c.report == nil || c.Target != "direct" {
+		return
+	}
+
+	c.report.EndTime = time.Now().UTC()
+	c.report.Success = runErr == nil
+	if runErr != nil {
+		c.report.Error = runErr.Error()
+	}
+	c.report.Services = serviceReports(c.services)
+
+	data, err := json.MarshalIndent(c.report, "", "  ")
+	if err != nil {
+		glog.Warningf("error serializing boot report: %v", err)
+		return
+	}
+
+	p := path.Join(c.FSRoot, nodeup.BootReportPath)
+	if err := os.MkdirAll(path.Dir(p), 0755); err != nil {
+		glog.Warningf("error creating directory for boot report: %v", err)
+	} else if err := ioutil.WriteFile(p, data, 0644); err != nil {
+		glog.Warningf("error writing boot report to %q: %v", p, err)
+	}
+
+	if c.cluster != nil && c.configBase != nil && fi.BoolValue(c.cluster.Spec.UploadBootReports) {
+		if err := c.uploadBootReport(data); err != nil {
+			glog.Warningf("error uploading boot report: %v", err)
+		}
+	}
+}
+
+// uploadBootReport writes the boot report to the state store, where kops toolbox dump collects it
+func (c *NodeUpCommand) uploadBootReport(data []byte) error {
+	if c.report.Hostname == "" {
+		return fmt.Errorf("hostname is not known")
+	}
+
+	p := c.configBase.Join("bootreports", c.report.Hostname+".json")
+	glog.Infof("Uploading boot report to %s", p)
+	if err := p.WriteFile(bytes.NewReader(data), nil); err != nil {
+		return fmt.Errorf("error writing %s: %v", p, err)
+	}
+	return nil
+}
+
+// serviceReports returns the current state of the systemd services
+func serviceReports(services []*nodetasks.Service) []*nodeup.ServiceReport {
+	var reports []*nodeup.ServiceReport
+	for _, s := range services {
+		report := &nodeup.ServiceReport{
+			Name: s.Name,
+		}
+		activeState, subState, err := nodetasks.GetServiceState(s.Name)
+		if err != nil {
+			glog.Warningf("error getting state of service %q: %v", s.Name, err)
+			activeState = "unknown"
+		}
+		report.ActiveState = activeState
+		report.SubState = subState
+		report.Failed = activeState == "failed"
+		reports = append(reports, report)
+	}
+	return reports
+}
@@ -22,6 +22,9 @@ import (
 	"io"
 	"io/ioutil"
 	"os/exec"
+	"path"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -35,6 +38,9 @@ import (
 	"k8s.io/kops/pkg/apis/kops/registry"
 	"k8s.io/kops/pkg/apis/nodeup"
 	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/pkg/featureflag"
+	"k8s.io/kops/pkg/nodeagent"
+	"k8s.io/kops/pkg/nodebundle"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/cloudinit"
 	"k8s.io/kops/upup/pkg/fi/nodeup/local"
@@ -58,11 +64,116 @@ type NodeUpCommand struct {
 	Target         string
 	cluster        *api.Cluster
 	config         *nodeup.Config
+	configBase     vfs.Path
 	instanceGroup  *api.InstanceGroup
+	nodeTags       sets.String
+	report         *nodeup.BootReport
+	services       []*nodetasks.Service
+
+	// agent is true when nodeup is running as the nodeup agent, rather than configuring the node at boot
+	agent bool
+	// appliedCluster and appliedInstanceGroup are the configuration being applied, before it is evaluated for this node
+	appliedCluster       *api.Cluster
+	appliedInstanceGroup *api.InstanceGroup
+	// replacementChanges are the changed settings which the agent did not apply, because they require replacing the node
+	replacementChanges []string
 }
 
 // Run is responsible for perform the nodeup process
 func (c *NodeUpCommand) Run(out io.Writer) error {
+	c.report = newBootReport(nodeup.BootReportModeRun)
+
+	taskMap, err := c.buildTasks()
+	if err != nil {
+		c.finishBootReport(err)
+		return err
+	}
+
+	var target fi.Target
+	checkExisting := true
+
+	switch c.Target {
+	case "direct":
+		target = &local.LocalTarget{
+			CacheDir: c.CacheDir,
+			Tags:     c.nodeTags,
+		}
+	case "dryrun":
+		assetBuilder := assets.NewAssetBuilder(c.cluster, "")
+		target = fi.NewDryRunTarget(assetBuilder, out)
+	case "cloudinit":
+		checkExisting = false
+		target = cloudinit.NewCloudInitTarget(out, c.nodeTags)
+	default:
+		return fmt.Errorf("unsupported target type %q", c.Target)
+	}
+
+	if err := c.runTasks(target, checkExisting, taskMap, MaxTaskDuration); err != nil {
+		c.exitf("%v", err)
+	}
+
+	if c.Target == "direct" && nodeagent.Enabled(&c.cluster.Spec, c.instanceGroup) {
+		if err := c.saveAppliedConfig(); err != nil {
+			glog.Warningf("error saving applied configuration for the nodeup agent: %v", err)
+		}
+	}
+
+	c.finishBootReport(nil)
+
+	return nil
+}
+
+// runTasks runs the tasks against the target
+func (c *NodeUpCommand) runTasks(target fi.Target, checkExisting bool, taskMap map[string]fi.Task, maxTaskDuration time.Duration) error {
+	context, err := fi.NewContext(target, nil, nil, nil, nil, c.configBase, checkExisting, taskMap)
+	if err != nil {
+		return fmt.Errorf("error building context: %v", err)
+	}
+	defer context.Close()
+
+	err = context.RunTasks(maxTaskDuration)
+	if err != nil {
+		return fmt.Errorf("error running tasks: %v", err)
+	}
+
+	err = target.Finish(taskMap)
+	if err != nil {
+		return fmt.Errorf("error closing target: %v", err)
+	}
+
+	return nil
+}
+
+// exitf records the error in the boot report, then exits
+func (c *NodeUpCommand) exitf(format string, args ...interface{}) {
+	c.finishBootReport(fmt.Errorf(format, args...))
+	glog.Exitf(format, args...)
+}
+
+// buildTasks loads the configuration, fetches the assets and runs the model builders, returning the tasks for the node
+func (c *NodeUpCommand) buildTasks() (map[string]fi.Task, error) {
+	if err := c.loadConfig(); err != nil {
+		return nil, err
+	}
+
+	if featureflag.VFSCache.Enabled() {
+		vfs.Context.SetCache(vfs.NewVFSCache(path.Join(c.CacheDir, "vfs")))
+	}
+
+	assetStore, bundle, err := c.loadAssets(true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.loadCluster(); err != nil {
+		return nil, err
+	}
+
+	return c.buildModel(assetStore, bundle)
+}
+
+// loadConfig reads the nodeup configuration
+func (c *NodeUpCommand) loadConfig() error {
 	if c.FSRoot == "" {
 		return fmt.Errorf("FSRoot is required")
 	}
@@ -80,18 +191,59 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	} else {
 		return fmt.Errorf("ConfigLocation is required")
 	}
+	c.report.InstanceGroup = c.config.InstanceGroupName
 
 	if c.CacheDir == "" {
 		return fmt.Errorf("CacheDir is required")
 	}
-	assetStore := fi.NewAssetStore(c.CacheDir)
-	for _, asset := range c.config.Assets {
-		err := assetStore.Add(asset)
+
+	return nil
+}
+
+// loadAssets builds the asset store for the node.  If fetch is false, only assets which are already
+// in the cache are used, and any others are recorded as missing in the report rather than downloaded.
+func (c *NodeUpCommand) loadAssets(fetch bool) (*fi.AssetStore, *nodebundle.Bundle, error) {
+	// With a node bundle, we take all the assets from the bundle and never download them
+	var bundle *nodebundle.Bundle
+	assetIDs := c.config.Assets
+	if c.config.NodeBundle != nil {
+		var err error
+		if fetch {
+			bundle, err = fetchNodeBundle(c.config.NodeBundle, c.CacheDir)
+		} else {
+			bundle, err = loadNodeBundle(c.config.NodeBundle, c.CacheDir)
+		}
 		if err != nil {
-			return fmt.Errorf("error adding asset %q: %v", asset, err)
+			return nil, nil, fmt.Errorf("error loading node bundle %q: %v", c.config.NodeBundle.Source, err)
+		}
+
+		assetIDs, err = nodeBundleAssets(bundle, assetIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	assetStore := fi.NewAssetStore(c.CacheDir)
+	for _, asset := range assetIDs {
+		if !fetch {
+			if err := assetStore.AddCached(asset); err != nil {
+				// We carry on, so that we can check everything else
+				c.report.MissingAssets = append(c.report.MissingAssets, &nodeup.AssetReport{Asset: asset, Error: err.Error()})
+			}
+			continue
+		}
+
+		if err := assetStore.Add(asset); err != nil {
+			c.report.MissingAssets = append(c.report.MissingAssets, &nodeup.AssetReport{Asset: asset, Error: err.Error()})
+			return nil, nil, fmt.Errorf("error adding asset %q: %v", asset, err)
 		}
 	}
 
+	return assetStore, bundle, nil
+}
+
+// loadCluster reads the cluster and instance group from the state store
+func (c *NodeUpCommand) loadCluster() error {
 	var configBase vfs.Path
 	if fi.StringValue(c.config.ConfigBase) != "" {
 		var err error
@@ -114,6 +266,7 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	} else {
 		return fmt.Errorf("ConfigBase is required")
 	}
+	c.configBase = configBase
 
 	c.cluster = &api.Cluster{}
 	{
@@ -130,7 +283,7 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 			p = configBase.Join(registry.PathClusterCompleted)
 		}
 
-		b, err := p.ReadFile()
+		b, err := vfs.Context.Cached(p).ReadFile()
 		if err != nil {
 			return fmt.Errorf("error loading Cluster %q: %v", p, err)
 		}
@@ -145,7 +298,7 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 		instanceGroupLocation := configBase.Join("instancegroup", c.config.InstanceGroupName)
 
 		c.instanceGroup = &api.InstanceGroup{}
-		b, err := instanceGroupLocation.ReadFile()
+		b, err := vfs.Context.Cached(instanceGroupLocation).ReadFile()
 		if err != nil {
 			return fmt.Errorf("error loading InstanceGroup %q: %v", instanceGroupLocation, err)
 		}
@@ -157,14 +310,40 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 		glog.Warningf("No instance group defined in nodeup config")
 	}
 
-	err := evaluateSpec(c.cluster)
+	if c.agent {
+		if !nodeagent.Enabled(&c.cluster.Spec, c.instanceGroup) {
+			return errAgentDisabled
+		}
+		if err := c.mergeAppliedConfig(); err != nil {
+			return err
+		}
+	}
+
+	// We keep the configuration before it is evaluated for this node, for the nodeup agent to compare against
+	c.appliedCluster = c.cluster.DeepCopy()
+	c.appliedInstanceGroup = c.instanceGroup.DeepCopy()
+
+	return nil
+}
+
+// buildModel runs the model builders for the loaded configuration, returning the tasks for the node
+func (c *NodeUpCommand) buildModel(assetStore *fi.AssetStore, bundle *nodebundle.Bundle) (map[string]fi.Task, error) {
+	// When diagnosing we must not change the node, so we don't load kernel modules to choose the docker storage
+	loadModules := c.report.Mode != nodeup.BootReportModeDiagnose
+	err := evaluateSpec(c.cluster, loadModules)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	distribution, err := distros.FindDistribution(c.FSRoot)
 	if err != nil {
-		return fmt.Errorf("error determining OS distribution: %v", err)
+		return nil, fmt.Errorf("error determining OS distribution: %v", err)
+	}
+
+	if bundle != nil {
+		if err := checkNodeBundle(bundle, distribution, model.Architecture(runtime.GOARCH)); err != nil {
+			return nil, err
+		}
 	}
 
 	osTags := distribution.BuildTags()
@@ -173,11 +352,13 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	nodeTags.Insert(osTags...)
 	nodeTags.Insert(c.config.Tags...)
 
+	c.nodeTags = nodeTags
+
 	glog.Infof("Config tags: %v", c.config.Tags)
 	glog.Infof("OS tags: %v", osTags)
 
 	modelContext := &model.NodeupModelContext{
-		Architecture:  model.ArchitectureAmd64,
+		Architecture:  model.Architecture(runtime.GOARCH),
 		Assets:        assetStore,
 		Cluster:       c.cluster,
 		Distribution:  distribution,
@@ -190,33 +371,35 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 		glog.Infof("Building SecretStore at %q", c.cluster.Spec.SecretStore)
 		p, err := vfs.Context.BuildVfsPath(c.cluster.Spec.SecretStore)
 		if err != nil {
-			return fmt.Errorf("error building secret store path: %v", err)
+			return nil, fmt.Errorf("error building secret store path: %v", err)
 		}
 
-		modelContext.SecretStore = secrets.NewVFSSecretStore(c.cluster, p)
+		modelContext.SecretStore = secrets.NewVFSSecretStore(c.cluster, vfs.Context.Cached(p))
 	} else {
-		return fmt.Errorf("SecretStore not set")
+		return nil, fmt.Errorf("SecretStore not set")
 	}
 
 	if c.cluster.Spec.KeyStore != "" {
 		glog.Infof("Building KeyStore at %q", c.cluster.Spec.KeyStore)
 		p, err := vfs.Context.BuildVfsPath(c.cluster.Spec.KeyStore)
 		if err != nil {
-			return fmt.Errorf("error building key store path: %v", err)
+			return nil, fmt.Errorf("error building key store path: %v", err)
 		}
 
-		modelContext.KeyStore = fi.NewVFSCAStore(c.cluster, p, false)
+		modelContext.KeyStore = fi.NewVFSCAStore(c.cluster, vfs.Context.Cached(p), false)
 	} else {
-		return fmt.Errorf("KeyStore not set")
+		return nil, fmt.Errorf("KeyStore not set")
 	}
 
 	if err := modelContext.Init(); err != nil {
-		return err
+		return nil, err
 	}
 
 	loader := NewLoader(c.config, c.cluster, assetStore, nodeTags)
 	loader.Builders = append(loader.Builders, &model.DirectoryBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.DockerBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.ContainerdBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.CRIOBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.ProtokubeBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.CloudConfigBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.FileAssetsBuilder{NodeupModelContext: modelContext})
@@ -230,6 +413,8 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	loader.Builders = append(loader.Builders, &model.FirewallBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.NetworkBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.SysctlBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.NodeAddonsBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.NodeTuningBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.KubeAPIServerBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.KubeControllerManagerBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.KubeSchedulerBuilder{NodeupModelContext: modelContext})
@@ -244,64 +429,50 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 
 	taskMap, err := loader.Build(c.ModelDir)
 	if err != nil {
-		return fmt.Errorf("error building loader: %v", err)
+		return nil, fmt.Errorf("error building loader: %v", err)
 	}
 
-	for i, image := range c.config.Images {
-		taskMap["LoadImage."+strconv.Itoa(i)] = &nodetasks.LoadImageTask{
-			Source: image.Source,
-			Hash:   image.Hash,
+	// The images were loaded when the node booted; the agent does not load them again on every run
+	if !c.agent {
+		for i, image := range c.config.Images {
+			taskMap["LoadImage."+strconv.Itoa(i)] = &nodetasks.LoadImageTask{
+				Source: image.Source,
+				Hash:   image.Hash,
+			}
 		}
-	}
-	if c.config.ProtokubeImage != nil {
-		taskMap["LoadImage.protokube"] = &nodetasks.LoadImageTask{
-			Source: c.config.ProtokubeImage.Source,
-			Hash:   c.config.ProtokubeImage.Hash,
+		if c.config.ProtokubeImage != nil {
+			taskMap["LoadImage.protokube"] = &nodetasks.LoadImageTask{
+				Source: c.config.ProtokubeImage.Source,
+				Hash:   c.config.ProtokubeImage.Hash,
+			}
 		}
 	}
 
-	var cloud fi.Cloud
-	var keyStore fi.Keystore
-	var secretStore fi.SecretStore
-	var target fi.Target
-	checkExisting := true
-
-	switch c.Target {
-	case "direct":
-		target = &local.LocalTarget{
-			CacheDir: c.CacheDir,
-			Tags:     nodeTags,
+	if nodeagent.Enabled(&c.cluster.Spec, c.instanceGroup) {
+		if err := c.addAgentTasks(taskMap); err != nil {
+			return nil, err
 		}
-	case "dryrun":
-		assetBuilder := assets.NewAssetBuilder(c.cluster, "")
-		target = fi.NewDryRunTarget(assetBuilder, out)
-	case "cloudinit":
-		checkExisting = false
-		target = cloudinit.NewCloudInitTarget(out, nodeTags)
-	default:
-		return fmt.Errorf("unsupported target type %q", c.Target)
 	}
 
-	context, err := fi.NewContext(target, nil, cloud, keyStore, secretStore, configBase, checkExisting, taskMap)
-	if err != nil {
-		glog.Exitf("error building context: %v", err)
-	}
-	defer context.Close()
-
-	err = context.RunTasks(MaxTaskDuration)
-	if err != nil {
-		glog.Exitf("error running tasks: %v", err)
+	if bundle != nil {
+		if err := applyNodeBundle(bundle, taskMap); err != nil {
+			return nil, err
+		}
 	}
 
-	err = target.Finish(taskMap)
-	if err != nil {
-		glog.Exitf("error closing target: %v", err)
+	// We report on the state of the services once nodeup has finished
+	for _, t := range taskMap {
+		if service, ok := t.(*nodetasks.Service); ok {
+			c.services = append(c.services, service)
+		}
 	}
+	sort.Slice(c.services, func(i, j int) bool { return c.services[i].Name < c.services[j].Name })
 
-	return nil
+	return taskMap, nil
 }
 
-func evaluateSpec(c *api.Cluster) error {
+// evaluateSpec resolves the settings which depend on the node.  If loadModules is false, no kernel modules are loaded.
+func evaluateSpec(c *api.Cluster, loadModules bool) error {
 	var err error
 
 	c.Spec.Kubelet.HostnameOverride, err = evaluateHostnameOverride(c.Spec.Kubelet.HostnameOverride)
@@ -322,7 +493,7 @@ func evaluateSpec(c *api.Cluster) error {
 	}
 
 	if c.Spec.Docker != nil {
-		err = evaluateDockerSpecStorage(c.Spec.Docker)
+		err = evaluateDockerSpecStorage(c.Spec.Docker, loadModules)
 		if err != nil {
 			return err
 		}
@@ -379,7 +550,7 @@ func evaluateHostnameOverride(hostnameOverride string) (string, error) {
 }
 
 // evaluateDockerSpec selects the first supported storage mode, if it is a list
-func evaluateDockerSpecStorage(spec *api.DockerConfig) error {
+func evaluateDockerSpecStorage(spec *api.DockerConfig, loadModules bool) error {
 	storage := fi.StringValue(spec.Storage)
 	if strings.Contains(fi.StringValue(spec.Storage), ",") {
 		precedence := strings.Split(storage, ",")
@@ -394,7 +565,7 @@ func evaluateDockerSpecStorage(spec *api.DockerConfig) error {
 				continue
 			}
 
-			if !supported {
+			if !supported && loadModules {
 				// overlay -> overlay
 				// aufs -> aufs
 				module := fs
@@ -0,0 +1,274 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import (
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/pki"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/local"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+// Diagnose checks the node against the tasks nodeup would run, without changing anything.
+// Problems with the node are recorded in the returned report; an error is only returned if the check itself could not run.
+func (c *NodeUpCommand) Diagnose() (*nodeup.BootReport, error) {
+	c.report = newBootReport(nodeup.BootReportModeDiagnose)
+
+	// We only use what is already on the node: nothing is downloaded, extracted or cached
+	taskMap, err := c.buildDiagnoseTasks()
+	if err != nil {
+		// Typically a node bundle which was never extracted, or a problem reading the state store
+		c.report.EndTime = time.Now().UTC()
+		c.report.Error = err.Error()
+		return c.report, nil
+	}
+
+	target := &local.LocalTarget{
+		CacheDir: c.CacheDir,
+		Tags:     c.nodeTags,
+	}
+
+	context, err := fi.NewContext(target, nil, nil, nil, nil, c.configBase, true, taskMap)
+	if err != nil {
+		return nil, fmt.Errorf("error building context: %v", err)
+	}
+	defer context.Close()
+
+	var keys []string
+	for k := range taskMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		c.report.Tasks = append(c.report.Tasks, diagnoseTask(context, k, taskMap[k]))
+	}
+
+	c.report.Services = serviceReports(c.services)
+	c.report.Certificates = checkCertificates(taskMap, time.Now())
+
+	c.report.EndTime = time.Now().UTC()
+	c.report.Success = true
+
+	return c.report, nil
+}
+
+// buildDiagnoseTasks loads the configuration and runs the model builders like buildTasks, but only uses assets already in the cache
+func (c *NodeUpCommand) buildDiagnoseTasks() (map[string]fi.Task, error) {
+	if err := c.loadConfig(); err != nil {
+		return nil, err
+	}
+
+	assetStore, bundle, err := c.loadAssets(false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.loadCluster(); err != nil {
+		return nil, err
+	}
+
+	return c.buildModel(assetStore, bundle)
+}
+
+// diagnoseTask compares the node with a single task
+func diagnoseTask(context *fi.Context, name string, task fi.Task) *nodeup.TaskReport {
+	report := &nodeup.TaskReport{Name: name}
+
+	switch task.(type) {
+	case *nodetasks.LoadImageTask, *nodetasks.UpdatePackages, *nodetasks.HealthCheck:
+		// These always run, so there is nothing to compare
+		report.Status = nodeup.TaskStatusUnchecked
+		return report
+	}
+
+	found, changes, err := fi.DeltaChanges(task, context)
+	if err != nil {
+		report.Status = nodeup.TaskStatusError
+		report.Error = err.Error()
+	} else if !found {
+		report.Status = nodeup.TaskStatusMissing
+	} else if len(changes) != 0 {
+		report.Status = nodeup.TaskStatusOutOfSync
+		report.Changes = changes
+	} else {
+		report.Status = nodeup.TaskStatusInSync
+	}
+	return report
+}
+
+// isCertificatePath returns true if the file is one where nodeup installs a certificate
+func isCertificatePath(p string) bool {
+	if strings.Contains(p, "key") {
+		return false
+	}
+	return strings.HasSuffix(p, ".crt") || strings.HasSuffix(p, ".cert") || strings.HasSuffix(p, ".pem")
+}
+
+// checkCertificates checks the certificates on the node are present, currently valid and signed by one of the CAs nodeup installs
+func checkCertificates(taskMap map[string]fi.Task, now time.Time) []*nodeup.CertificateReport {
+	expected := make(map[string]*pki.Certificate)
+	for _, t := range taskMap {
+		file, ok := t.(*nodetasks.File)
+		if !ok || file.Contents == nil || file.Type == nodetasks.FileType_Directory || file.Type == nodetasks.FileType_Symlink || !isCertificatePath(file.Path) {
+			continue
+		}
+
+		data, err := fi.ResourceAsBytes(file.Contents)
+		if err != nil {
+			glog.Warningf("error reading contents of %q: %v", file.Path, err)
+			continue
+		}
+		if !strings.HasPrefix(strings.TrimSpace(string(data)), "-----BEGIN CERTIFICATE-----") {
+			continue
+		}
+		cert, err := pki.ParsePEMCertificate(data)
+		if err != nil {
+			glog.Warningf("error parsing certificate for %q: %v", file.Path, err)
+			continue
+		}
+		expected[file.Path] = cert
+	}
+
+	roots := x509.NewCertPool()
+	for _, cert := range expected {
+		if cert.IsCA {
+			roots.AddCert(cert.Certificate)
+		}
+	}
+
+	var paths []string
+	for p := range expected {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var reports []*nodeup.CertificateReport
+	for _, p := range paths {
+		if problem := checkCertificate(p, expected[p].IsCA, roots, now); problem != "" {
+			reports = append(reports, &nodeup.CertificateReport{Path: p, Problem: problem})
+		}
+	}
+	return reports
+}
+
+// checkCertificate returns a description of the problem with the certificate at p, or "" if it is valid
+func checkCertificate(p string, isCA bool, roots *x509.CertPool, now time.Time) string {
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "certificate is missing"
+		}
+		return fmt.Sprintf("error reading certificate: %v", err)
+	}
+
+	cert, err := pki.ParsePEMCertificate(data)
+	if err != nil {
+		return fmt.Sprintf("error parsing certificate: %v", err)
+	}
+
+	if now.Before(cert.Certificate.NotBefore) {
+		return fmt.Sprintf("certificate is not valid until %s", cert.Certificate.NotBefore.UTC().Format(time.RFC3339))
+	}
+	if now.After(cert.Certificate.NotAfter) {
+		return fmt.Sprintf("certificate expired at %s", cert.Certificate.NotAfter.UTC().Format(time.RFC3339))
+	}
+
+	if !isCA && len(roots.Subjects()) != 0 {
+		opts := x509.VerifyOptions{
+			Roots:       roots,
+			CurrentTime: now,
+			KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		}
+		if _, err := cert.Certificate.Verify(opts); err != nil {
+			return fmt.Sprintf("certificate is not signed by the cluster CA: %v", err)
+		}
+	}
+
+	return ""
+}
+
+// PrintBootReport writes a human-readable summary of the report
+func PrintBootReport(out io.Writer, report *nodeup.BootReport) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Node:\t%s\n", report.Hostname)
+	fmt.Fprintf(w, "InstanceGroup:\t%s\n", report.InstanceGroup)
+	fmt.Fprintf(w, "Nodeup version:\t%s\n", report.NodeupVersion)
+	if report.Error != "" {
+		fmt.Fprintf(w, "Error:\t%s\n", report.Error)
+	}
+
+	if len(report.MissingAssets) != 0 {
+		fmt.Fprintf(w, "\nMISSING ASSET\tERROR\n")
+		for _, a := range report.MissingAssets {
+			fmt.Fprintf(w, "%s\t%s\n", a.Asset, a.Error)
+		}
+	}
+
+	counts := make(map[nodeup.TaskStatus]int)
+	var problems []*nodeup.TaskReport
+	for _, t := range report.Tasks {
+		counts[t.Status]++
+		if t.Status != nodeup.TaskStatusInSync && t.Status != nodeup.TaskStatusUnchecked {
+			problems = append(problems, t)
+		}
+	}
+	if len(report.Tasks) != 0 {
+		fmt.Fprintf(w, "\nTasks:\t%d in sync, %d out of sync, %d missing, %d errors, %d unchecked\n",
+			counts[nodeup.TaskStatusInSync], counts[nodeup.TaskStatusOutOfSync], counts[nodeup.TaskStatusMissing],
+			counts[nodeup.TaskStatusError], counts[nodeup.TaskStatusUnchecked])
+	}
+	if len(problems) != 0 {
+		fmt.Fprintf(w, "\nTASK\tSTATUS\tDETAILS\n")
+		for _, t := range problems {
+			details := strings.Join(t.Changes, ",")
+			if t.Error != "" {
+				details = t.Error
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", t.Name, t.Status, details)
+		}
+	}
+
+	if len(report.Services) != 0 {
+		fmt.Fprintf(w, "\nSERVICE\tACTIVE\tSUB\n")
+		for _, s := range report.Services {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", s.Name, s.ActiveState, s.SubState)
+		}
+	}
+
+	if len(report.Certificates) != 0 {
+		fmt.Fprintf(w, "\nCERTIFICATE\tPROBLEM\n")
+		for _, c := range report.Certificates {
+			fmt.Fprintf(w, "%s\t%s\n", c.Path, c.Problem)
+		}
+	}
+
+	return w.Flush()
+}
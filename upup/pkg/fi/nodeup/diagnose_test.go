@@ -0,0 +1,194 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/pki"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+	"k8s.io/kops/upup/pkg/fi/utils"
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+func signTestCertificate(t *testing.T, name string, isCA bool, signer *pki.Certificate, signerKey *pki.PrivateKey) (*pki.Certificate, *pki.PrivateKey) {
+	key, err := pki.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("error generating private key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	}
+
+	var parent *x509.Certificate
+	if signer != nil {
+		parent = signer.Certificate
+	}
+	cert, err := pki.SignNewCertificate(key, template, parent, signerKey)
+	if err != nil {
+		t.Fatalf("error signing certificate: %v", err)
+	}
+	return cert, key
+}
+
+func certificateFileTask(t *testing.T, p string, cert *pki.Certificate) *nodetasks.File {
+	s, err := cert.AsString()
+	if err != nil {
+		t.Fatalf("error serializing certificate: %v", err)
+	}
+	return &nodetasks.File{
+		Path:     p,
+		Contents: fi.NewStringResource(s),
+		Type:     nodetasks.FileType_File,
+	}
+}
+
+func TestCheckCertificates(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "diagnose")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	ca, caKey := signTestCertificate(t, "kubernetes", true, nil, nil)
+	otherCA, otherCAKey := signTestCertificate(t, "other", true, nil, nil)
+	kubelet, _ := signTestCertificate(t, "kubelet", false, ca, caKey)
+	proxy, _ := signTestCertificate(t, "kube-proxy", false, otherCA, otherCAKey)
+
+	caPath := filepath.Join(tmp, "ca.crt")
+	kubeletPath := filepath.Join(tmp, "kubelet.crt")
+	proxyPath := filepath.Join(tmp, "kube-proxy.crt")
+	missingPath := filepath.Join(tmp, "missing.crt")
+
+	// The CA and kubelet certificates are correct; kube-proxy has a certificate signed by another CA
+	for p, cert := range map[string]*pki.Certificate{caPath: ca, kubeletPath: kubelet, proxyPath: proxy} {
+		s, err := cert.AsString()
+		if err != nil {
+			t.Fatalf("error serializing certificate: %v", err)
+		}
+		if err := ioutil.WriteFile(p, []byte(s), 0644); err != nil {
+			t.Fatalf("error writing certificate: %v", err)
+		}
+	}
+
+	taskMap := map[string]fi.Task{
+		"ca":      certificateFileTask(t, caPath, ca),
+		"kubelet": certificateFileTask(t, kubeletPath, kubelet),
+		"proxy":   certificateFileTask(t, proxyPath, proxy),
+		"missing": certificateFileTask(t, missingPath, kubelet),
+	}
+
+	reports := checkCertificates(taskMap, time.Now())
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 certificate problems, got %d: %v", len(reports), reports)
+	}
+	if reports[0].Path != proxyPath || !strings.Contains(reports[0].Problem, "not signed by the cluster CA") {
+		t.Errorf("unexpected report for kube-proxy: %v", reports[0])
+	}
+	if reports[1].Path != missingPath || reports[1].Problem != "certificate is missing" {
+		t.Errorf("unexpected report for missing certificate: %v", reports[1])
+	}
+
+	reports = checkCertificates(map[string]fi.Task{"kubelet": taskMap["kubelet"]}, time.Now().Add(20*365*24*time.Hour))
+	if len(reports) != 1 || !strings.Contains(reports[0].Problem, "expired") {
+		t.Errorf("expected expired certificate, got %v", reports)
+	}
+}
+
+func TestLoadAssetsFromCache(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "diagnose")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(cacheDir)
+
+	// One asset is already in the cache; the other could only be downloaded, from an address which doesn't respond
+	cachedURL := "http://127.0.0.1:1/cached"
+	cachedHash, err := hashing.HashAlgorithmSHA256.Hash(bytes.NewReader([]byte("cached")))
+	if err != nil {
+		t.Fatalf("error hashing asset: %v", err)
+	}
+	cachedFile := filepath.Join(cacheDir, cachedHash.String()+"_"+utils.SanitizeString(cachedURL))
+	if err := ioutil.WriteFile(cachedFile, []byte("cached"), 0644); err != nil {
+		t.Fatalf("error writing asset: %v", err)
+	}
+	missingURL := "http://127.0.0.1:1/missing"
+	missingHash, err := hashing.HashAlgorithmSHA256.Hash(bytes.NewReader([]byte("missing")))
+	if err != nil {
+		t.Fatalf("error hashing asset: %v", err)
+	}
+
+	c := &NodeUpCommand{
+		CacheDir: cacheDir,
+		config: &nodeup.Config{
+			Assets: []string{
+				cachedHash.Hex() + "@" + cachedURL,
+				missingHash.Hex() + "@" + missingURL,
+			},
+		},
+		report: newBootReport(nodeup.BootReportModeDiagnose),
+	}
+
+	assetStore, _, err := c.loadAssets(false)
+	if err != nil {
+		t.Fatalf("unexpected error loading assets: %v", err)
+	}
+	if asset, err := assetStore.Find("cached", ""); err != nil || asset == nil {
+		t.Errorf("expected cached asset to be found, got %v (%v)", asset, err)
+	}
+	if len(c.report.MissingAssets) != 1 || c.report.MissingAssets[0].Asset != missingHash.Hex()+"@"+missingURL {
+		t.Errorf("expected only the uncached asset to be missing, got %v", c.report.MissingAssets)
+	}
+
+	// A node bundle which has not been extracted is an error, rather than being fetched
+	c.config.NodeBundle = &nodeup.NodeBundle{
+		Source: "http://127.0.0.1:1/nodebundle.tar.gz",
+		Hash:   missingHash.Hex(),
+	}
+	if _, _, err := c.loadAssets(false); err == nil || !strings.Contains(err.Error(), "has not been extracted") {
+		t.Errorf("expected error for node bundle which has not been extracted, got %v", err)
+	}
+
+	// Nothing was added to the cache
+	files, err := ioutil.ReadDir(cacheDir)
+	if err != nil {
+		t.Fatalf("error reading cache dir: %v", err)
+	}
+	if len(files) != 1 || files[0].Name() != filepath.Base(cachedFile) {
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Name())
+		}
+		t.Errorf("expected the cache to be unchanged, found %v", names)
+	}
+}
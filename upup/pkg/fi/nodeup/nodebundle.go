@@ -0,0 +1,251 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kops/nodeup/pkg/distros"
+	"k8s.io/kops/nodeup/pkg/model"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/nodebundle"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+	"k8s.io/kops/util/pkg/hashing"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// fetchNodeBundle downloads the node bundle (if we have not already done so) and extracts it into the cache dir
+func fetchNodeBundle(spec *nodeup.NodeBundle, cacheDir string) (*nodebundle.Bundle, error) {
+	hash, err := hashing.HashAlgorithmSHA256.FromString(spec.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing node bundle hash %q: %v", spec.Hash, err)
+	}
+
+	dir := nodeBundleDir(cacheDir, hash)
+	if _, err := os.Stat(dir); err == nil {
+		glog.Infof("Using node bundle already extracted to %q", dir)
+		return nodebundle.Load(dir)
+	}
+
+	tarball := dir + ".tar.gz"
+	if strings.HasPrefix(spec.Source, "http://") || strings.HasPrefix(spec.Source, "https://") {
+		if _, err := fi.DownloadURL(spec.Source, tarball, hash); err != nil {
+			return nil, fmt.Errorf("error downloading node bundle %q: %v", spec.Source, err)
+		}
+	} else {
+		if err := copyVFSFile(spec.Source, tarball); err != nil {
+			return nil, err
+		}
+
+		actual, err := hash.Algorithm.HashFile(tarball)
+		if err != nil {
+			return nil, fmt.Errorf("error hashing node bundle: %v", err)
+		}
+		if !actual.Equal(hash) {
+			return nil, fmt.Errorf("node bundle %q had hash %s, expected %s", spec.Source, actual, hash)
+		}
+	}
+
+	glog.Infof("Extracting node bundle %q to %q", spec.Source, dir)
+	b, err := nodebundle.Extract(tarball, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// We only need the extracted files
+	if err := os.Remove(tarball); err != nil {
+		glog.Warningf("error removing node bundle tarball %q: %v", tarball, err)
+	}
+
+	return b, nil
+}
+
+// loadNodeBundle loads a node bundle which has already been extracted into the cache, without fetching it
+func loadNodeBundle(spec *nodeup.NodeBundle, cacheDir string) (*nodebundle.Bundle, error) {
+	hash, err := hashing.HashAlgorithmSHA256.FromString(spec.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing node bundle hash %q: %v", spec.Hash, err)
+	}
+
+	dir := nodeBundleDir(cacheDir, hash)
+	if _, err := os.Stat(dir); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("node bundle %q has not been extracted to %q", spec.Source, dir)
+		}
+		return nil, fmt.Errorf("error checking node bundle directory %q: %v", dir, err)
+	}
+	return nodebundle.Load(dir)
+}
+
+// nodeBundleDir is the directory in the cache where a node bundle is extracted
+func nodeBundleDir(cacheDir string, hash *hashing.Hash) string {
+	return path.Join(cacheDir, "nodebundle", hash.Hex())
+}
+
+// copyVFSFile copies a file from the state store (or any VFS location) to the local disk
+func copyVFSFile(src string, dest string) error {
+	p, err := vfs.Context.BuildVfsPath(src)
+	if err != nil {
+		return fmt.Errorf("error parsing node bundle location %q: %v", src, err)
+	}
+
+	if err := os.MkdirAll(path.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("error creating directories %q: %v", path.Dir(dest), err)
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("error creating %q: %v", dest, err)
+	}
+	defer f.Close()
+
+	glog.Infof("Downloading node bundle %q", src)
+	if _, err := p.WriteTo(f); err != nil {
+		return fmt.Errorf("error downloading node bundle %q: %v", src, err)
+	}
+	return nil
+}
+
+// checkNodeBundle verifies that the bundle was built for this machine
+func checkNodeBundle(b *nodebundle.Bundle, distribution distros.Distribution, architecture model.Architecture) error {
+	m := b.Manifest
+	if m.Distribution != string(distribution) {
+		return fmt.Errorf("node bundle was built for distribution %q, but this machine is running %q", m.Distribution, distribution)
+	}
+	if m.Architecture != string(architecture) {
+		return fmt.Errorf("node bundle was built for architecture %q, but this machine is %q", m.Architecture, architecture)
+	}
+	return nil
+}
+
+// nodeBundleAssets maps the assets in the nodeup config to the files in the bundle
+func nodeBundleAssets(b *nodebundle.Bundle, assets []string) ([]string, error) {
+	var mapped []string
+	for _, asset := range assets {
+		source := asset
+		hash := ""
+		if i := strings.Index(asset, "@"); i != -1 && !strings.HasPrefix(asset, "http://") && !strings.HasPrefix(asset, "https://") {
+			hash = asset[:i]
+			source = asset[i+1:]
+		}
+
+		localPath, err := b.FindSource(source, hash)
+		if err != nil {
+			return nil, err
+		}
+
+		// The hash of the file in the bundle has already been verified
+		f := b.Manifest.FindSource(source)
+		mapped = append(mapped, f.Hash+"@file://"+localPath)
+	}
+	return mapped, nil
+}
+
+// applyNodeBundle rewrites the tasks so that everything is installed from the bundle.
+// We fail if anything would be downloaded that is not in the bundle, rather than reaching out to the network.
+func applyNodeBundle(b *nodebundle.Bundle, taskMap map[string]fi.Task) error {
+	for key, task := range taskMap {
+		switch t := task.(type) {
+		case *nodetasks.UpdatePackages:
+			// We do not use the package repositories
+			delete(taskMap, key)
+
+		case *nodetasks.Package:
+			if t.Source != nil {
+				localPath, err := b.FindSource(fi.StringValue(t.Source), fi.StringValue(t.Hash))
+				if err != nil {
+					return err
+				}
+				t.Source = fi.String("file://" + localPath)
+			} else {
+				files, err := b.PackageFiles(t.Name)
+				if err != nil {
+					return err
+				}
+				t.LocalFiles = files
+			}
+
+		case *nodetasks.Archive:
+			localPath, err := b.FindSource(t.Source, t.Hash)
+			if err != nil {
+				return err
+			}
+			t.Source = "file://" + localPath
+
+		case *nodetasks.LoadImageTask:
+			localPath, err := b.FindSource(t.Source, t.Hash)
+			if err != nil {
+				return err
+			}
+			t.Source = "file://" + localPath
+		}
+	}
+
+	return nil
+}
+
+// RequiredPackages returns the packages nodeup installs for the instance group, on the specified distribution and architecture.
+// config is the nodeup configuration for the instance group, and modelDir is the nodeup model.
+func RequiredPackages(config *nodeup.Config, cluster *api.Cluster, ig *api.InstanceGroup, distribution distros.Distribution, architecture model.Architecture, modelDir vfs.Path) ([]*nodetasks.Package, error) {
+	nodeTags := sets.NewString()
+	nodeTags.Insert(distribution.BuildTags()...)
+	nodeTags.Insert(config.Tags...)
+
+	modelContext := &model.NodeupModelContext{
+		Architecture:  architecture,
+		Cluster:       cluster,
+		Distribution:  distribution,
+		InstanceGroup: ig,
+		IsMaster:      nodeTags.Has(TagMaster),
+		NodeupConfig:  config,
+	}
+	if err := modelContext.Init(); err != nil {
+		return nil, err
+	}
+
+	// These are the builders that install packages; they do not need any assets
+	loader := NewLoader(config, cluster, fi.NewAssetStore(""), nodeTags)
+	loader.Builders = append(loader.Builders, &model.DockerBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.CRIOBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.LogrotateBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.PackagesBuilder{NodeupModelContext: modelContext})
+
+	taskMap, err := loader.Build(modelDir)
+	if err != nil {
+		return nil, fmt.Errorf("error building loader: %v", err)
+	}
+
+	var packages []*nodetasks.Package
+	for _, task := range taskMap {
+		if p, ok := task.(*nodetasks.Package); ok {
+			packages = append(packages, p)
+		}
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+
+	return packages, nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodetasks
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/cloudinit"
+	"k8s.io/kops/upup/pkg/fi/nodeup/local"
+)
+
+// healthCheckInterval is the time between attempts of a health check
+const healthCheckInterval = 5 * time.Second
+
+// HealthCheck waits for something installed on the node to become healthy: either a command succeeds,
+// or a unix socket is created.  It runs once the files are written and the services are started.
+type HealthCheck struct {
+	Name string
+
+	// Command is run until it exits zero
+	Command []string `json:"command,omitempty"`
+	// Socket is the path of a unix socket, which must exist
+	Socket string `json:"socket,omitempty"`
+	// Timeout is how long to wait, e.g. 5m
+	Timeout string `json:"timeout,omitempty"`
+}
+
+var _ fi.Task = &HealthCheck{}
+var _ fi.HasName = &HealthCheck{}
+var _ fi.HasDependencies = &HealthCheck{}
+
+func (e *HealthCheck) String() string {
+	return fmt.Sprintf("HealthCheck: %s", e.Name)
+}
+
+func (e *HealthCheck) GetName() *string {
+	return &e.Name
+}
+
+func (e *HealthCheck) SetName(name string) {
+	glog.Fatalf("SetName not supported for HealthCheck task")
+}
+
+// GetDependencies implements HasDependencies::GetDependencies; the check runs after the node is configured
+func (e *HealthCheck) GetDependencies(tasks map[string]fi.Task) []fi.Task {
+	var deps []fi.Task
+	for _, v := range tasks {
+		switch v.(type) {
+		case *File, *Service:
+			deps = append(deps, v)
+		}
+	}
+	return deps
+}
+
+// Find always returns nil, so the check runs on every run of nodeup
+func (e *HealthCheck) Find(c *fi.Context) (*HealthCheck, error) {
+	return nil, nil
+}
+
+func (e *HealthCheck) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(e, c)
+}
+
+func (_ *HealthCheck) CheckChanges(a, e, changes *HealthCheck) error {
+	if e.Name == "" {
+		return fi.RequiredField("Name")
+	}
+	if len(e.Command) == 0 && e.Socket == "" {
+		return fi.RequiredField("Command")
+	}
+	if _, err := time.ParseDuration(e.Timeout); err != nil {
+		return fmt.Errorf("invalid timeout %q for health check %q: %v", e.Timeout, e.Name, err)
+	}
+	return nil
+}
+
+// check returns an error describing why the check failed, or nil if it passed
+func (e *HealthCheck) check() error {
+	if e.Socket != "" {
+		stat, err := os.Stat(e.Socket)
+		if err != nil {
+			return fmt.Errorf("error checking socket %q: %v", e.Socket, err)
+		}
+		if stat.Mode()&os.ModeSocket == 0 {
+			return fmt.Errorf("%q is not a socket", e.Socket)
+		}
+	}
+	if len(e.Command) != 0 {
+		output, err := exec.Command(e.Command[0], e.Command[1:]...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("error running %q: %v\nOutput: %s", strings.Join(e.Command, " "), err, output)
+		}
+	}
+	return nil
+}
+
+func (_ *HealthCheck) RenderLocal(t *local.LocalTarget, a, e, changes *HealthCheck) error {
+	timeout, err := time.ParseDuration(e.Timeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout %q for health check %q: %v", e.Timeout, e.Name, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := e.check()
+		if err == nil {
+			glog.Infof("Health check %q passed", e.Name)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("health check %q did not pass within %v: %v", e.Name, timeout, err)
+		}
+		glog.V(2).Infof("waiting for health check %q: %v", e.Name, err)
+		time.Sleep(healthCheckInterval)
+	}
+}
+
+func (_ *HealthCheck) RenderCloudInit(t *cloudinit.CloudInitTarget, a, e, changes *HealthCheck) error {
+	return fmt.Errorf("HealthCheck::RenderCloudInit not implemented")
+}
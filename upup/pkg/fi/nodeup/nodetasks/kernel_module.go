@@ -0,0 +1,100 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodetasks
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/cloudinit"
+	"k8s.io/kops/upup/pkg/fi/nodeup/local"
+)
+
+// KernelModule loads a kernel module with modprobe.  It does not persist across reboots;
+// the builder should also write the module to /etc/modules-load.d
+type KernelModule struct {
+	Name string
+}
+
+var _ fi.Task = &KernelModule{}
+var _ fi.HasName = &KernelModule{}
+
+func (e *KernelModule) String() string {
+	return fmt.Sprintf("KernelModule: %s", e.Name)
+}
+
+func (e *KernelModule) GetName() *string {
+	return &e.Name
+}
+
+func (e *KernelModule) SetName(name string) {
+	glog.Fatalf("SetName not supported for KernelModule task")
+}
+
+// sysModulePath is where the kernel lists a loaded module; modules are listed with underscores in place of dashes
+func (e *KernelModule) sysModulePath() string {
+	return path.Join("/sys/module", strings.Replace(e.Name, "-", "_", -1))
+}
+
+func (e *KernelModule) Find(c *fi.Context) (*KernelModule, error) {
+	_, err := os.Stat(e.sysModulePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error checking for kernel module %q: %v", e.Name, err)
+	}
+
+	actual := &KernelModule{
+		Name: e.Name,
+	}
+	return actual, nil
+}
+
+func (e *KernelModule) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(e, c)
+}
+
+func (_ *KernelModule) CheckChanges(a, e, changes *KernelModule) error {
+	if e.Name == "" {
+		return fi.RequiredField("Name")
+	}
+	return nil
+}
+
+func (_ *KernelModule) RenderLocal(t *local.LocalTarget, a, e, changes *KernelModule) error {
+	if a != nil {
+		return nil
+	}
+
+	glog.Infof("Loading kernel module %q", e.Name)
+	output, err := exec.Command("modprobe", e.Name).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error loading kernel module %q: %v\nOutput: %s", e.Name, err, output)
+	}
+	return nil
+}
+
+func (_ *KernelModule) RenderCloudInit(t *cloudinit.CloudInitTarget, a, e, changes *KernelModule) error {
+	t.AddCommand(cloudinit.Always, "modprobe", e.Name)
+	return nil
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodetasks
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/cloudinit"
+	"k8s.io/kops/upup/pkg/fi/nodeup/local"
+)
+
+// KernelParameter sets the live value of a kernel parameter in /proc/sys or /sys, such as a sysctl.
+// It does not persist across reboots; the builder should also write sysctls to /etc/sysctl.d
+type KernelParameter struct {
+	// Path is the location of the parameter, e.g. /proc/sys/net/ipv4/ip_forward
+	Path string
+	// Value is the value of the parameter
+	Value string `json:"value"`
+}
+
+// procSys is the directory where the kernel exposes sysctls
+const procSys = "/proc/sys"
+
+var _ fi.Task = &KernelParameter{}
+var _ fi.HasName = &KernelParameter{}
+var _ fi.HasDependencies = &KernelParameter{}
+
+// NewSysctl builds a KernelParameter task for the sysctl key
+func NewSysctl(key string, value string) (*KernelParameter, error) {
+	p, err := SysctlPath(key)
+	if err != nil {
+		return nil, err
+	}
+	return &KernelParameter{
+		Path:  p,
+		Value: normalizeKernelParameter(value),
+	}, nil
+}
+
+// SysctlPath returns the path in /proc/sys for a sysctl key.  As with sysctl, dots separate the
+// components of the key, and slashes stand in for dots within a component (e.g. in an interface name).
+// It returns an error if the key would refer to a path outside of /proc/sys.
+func SysctlPath(key string) (string, error) {
+	p := strings.Map(func(r rune) rune {
+		switch r {
+		case '.':
+			return '/'
+		case '/':
+			return '.'
+		default:
+			return r
+		}
+	}, strings.TrimSpace(key))
+
+	sysctlPath := path.Join(procSys, p)
+	if !strings.HasPrefix(sysctlPath, procSys+"/") || strings.Contains(p, "..") {
+		return "", fmt.Errorf("invalid sysctl key %q", key)
+	}
+	return sysctlPath, nil
+}
+
+// normalizeKernelParameter collapses whitespace, as the kernel separates multiple values with tabs
+func normalizeKernelParameter(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func (e *KernelParameter) String() string {
+	return fmt.Sprintf("KernelParameter: %s=%s", e.Path, e.Value)
+}
+
+func (e *KernelParameter) GetName() *string {
+	return &e.Path
+}
+
+func (e *KernelParameter) SetName(name string) {
+	glog.Fatalf("SetName not supported for KernelParameter task")
+}
+
+// GetDependencies returns the kernel modules, as some parameters only exist once their module is loaded
+func (e *KernelParameter) GetDependencies(tasks map[string]fi.Task) []fi.Task {
+	var deps []fi.Task
+	for _, v := range tasks {
+		if _, ok := v.(*KernelModule); ok {
+			deps = append(deps, v)
+		}
+	}
+	return deps
+}
+
+func (e *KernelParameter) Find(c *fi.Context) (*KernelParameter, error) {
+	b, err := ioutil.ReadFile(e.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading kernel parameter %q: %v", e.Path, err)
+	}
+
+	actual := &KernelParameter{
+		Path:  e.Path,
+		Value: normalizeKernelParameter(string(b)),
+	}
+	return actual, nil
+}
+
+func (e *KernelParameter) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(e, c)
+}
+
+func (_ *KernelParameter) CheckChanges(a, e, changes *KernelParameter) error {
+	if e.Path == "" {
+		return fi.RequiredField("Path")
+	}
+	return nil
+}
+
+func (_ *KernelParameter) RenderLocal(t *local.LocalTarget, a, e, changes *KernelParameter) error {
+	if a == nil {
+		return fmt.Errorf("kernel parameter %q does not exist; is the kernel module loaded?", e.Path)
+	}
+
+	glog.Infof("Setting kernel parameter %s to %q", e.Path, e.Value)
+	if err := ioutil.WriteFile(e.Path, []byte(e.Value+"\n"), 0644); err != nil {
+		return fmt.Errorf("error setting kernel parameter %q: %v", e.Path, err)
+	}
+	return nil
+}
+
+func (_ *KernelParameter) RenderCloudInit(t *cloudinit.CloudInitTarget, a, e, changes *KernelParameter) error {
+	// We pass the value and path as arguments, so they do not need quoting
+	t.AddCommand(cloudinit.Always, "sh", "-c", `echo "$1" > "$2"`, "sh", e.Value, e.Path)
+	return nil
+}
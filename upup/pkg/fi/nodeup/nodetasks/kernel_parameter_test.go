@@ -0,0 +1,48 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodetasks
+
+import "testing"
+
+func Test_SysctlPath(t *testing.T) {
+	grid := []struct {
+		Key      string
+		Expected string
+	}{
+		{Key: "net.ipv4.ip_forward", Expected: "/proc/sys/net/ipv4/ip_forward"},
+		{Key: " kernel.pid_max ", Expected: "/proc/sys/kernel/pid_max"},
+		{Key: "net.ipv4.conf.eth0/100.rp_filter", Expected: "/proc/sys/net/ipv4/conf/eth0.100/rp_filter"},
+		{Key: "net.//.//.etc.passwd"},
+		{Key: "net.//.ipv4.ip_forward"},
+		{Key: ""},
+		{Key: "."},
+	}
+	for _, g := range grid {
+		actual, err := SysctlPath(g.Key)
+		if g.Expected == "" {
+			if err == nil {
+				t.Errorf("expected error for sysctl key %q, got %q", g.Key, actual)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for sysctl key %q: %v", g.Key, err)
+		} else if actual != g.Expected {
+			t.Errorf("sysctl key %q: expected %q, got %q", g.Key, g.Expected, actual)
+		}
+	}
+}
@@ -42,6 +42,10 @@ type Package struct {
 	Hash         *string `json:"hash,omitempty"`
 	PreventStart *bool   `json:"preventStart,omitempty"`
 
+	// LocalFiles are package files on the local disk (the package and the dependencies it needs),
+	// from which an OS package is installed instead of from the package repositories
+	LocalFiles []string `json:"localFiles,omitempty"`
+
 	// Healthy is true if the package installation did not fail
 	Healthy *bool `json:"healthy,omitempty"`
 }
@@ -117,15 +121,21 @@ func NewPackage(name string, contents string, meta string) (fi.Task, error) {
 func (e *Package) Find(c *fi.Context) (*Package, error) {
 	target := c.Target.(*local.LocalTarget)
 
+	var actual *Package
+	var err error
 	if target.HasTag(tags.TagOSFamilyDebian) {
-		return e.findDpkg(c)
+		actual, err = e.findDpkg(c)
+	} else if target.HasTag(tags.TagOSFamilyRHEL) {
+		actual, err = e.findYum(c)
+	} else {
+		return nil, fmt.Errorf("unsupported package system")
 	}
 
-	if target.HasTag(tags.TagOSFamilyRHEL) {
-		return e.findYum(c)
+	// The local files are where we install from, not part of the installed state
+	if actual != nil {
+		actual.LocalFiles = e.LocalFiles
 	}
-
-	return nil, fmt.Errorf("unsupported package system")
+	return actual, err
 }
 
 func (e *Package) findDpkg(c *fi.Context) (*Package, error) {
@@ -233,6 +243,10 @@ func (e *Package) findYum(c *fi.Context) (*Package, error) {
 		return nil, nil
 	}
 
+	if e.Version != nil && yumVersionMatches(installedVersion, *e.Version) {
+		installedVersion = *e.Version
+	}
+
 	return &Package{
 		Name:    e.Name,
 		Version: fi.String(installedVersion),
@@ -240,6 +254,12 @@ func (e *Package) findYum(c *fi.Context) (*Package, error) {
 	}, nil
 }
 
+// yumVersionMatches returns true if the installed version is the version of a package from a repository.
+// A version of a package from a repository can omit trailing components, so 1.10 matches 1.10.3.
+func yumVersionMatches(installed string, version string) bool {
+	return installed == version || strings.HasPrefix(installed, version+".")
+}
+
 func (e *Package) Run(c *fi.Context) error {
 	return fi.DefaultDeltaRunMethod(e, c)
 }
@@ -294,6 +314,26 @@ func (_ *Package) RenderLocal(t *local.LocalTarget, a, e, changes *Package) erro
 			if err != nil {
 				return fmt.Errorf("error installing package %q: %v: %s", e.Name, err, string(output))
 			}
+		} else if len(e.LocalFiles) != 0 {
+			// Install the package and its dependencies together, so they are installed in the right order
+			var args []string
+			env := os.Environ()
+			if t.HasTag(tags.TagOSFamilyDebian) {
+				args = append([]string{"dpkg", "-i", "--skip-same-version"}, e.LocalFiles...)
+				env = append(env, "DEBIAN_FRONTEND=noninteractive")
+			} else if t.HasTag(tags.TagOSFamilyRHEL) {
+				args = append([]string{"/usr/bin/yum", "localinstall", "-y", "--disablerepo=*"}, e.LocalFiles...)
+			} else {
+				return fmt.Errorf("unsupported package system")
+			}
+
+			glog.Infof("running command %s", args)
+			cmd := exec.Command(args[0], args[1:]...)
+			cmd.Env = env
+			output, err := cmd.CombinedOutput()
+			if err != nil {
+				return fmt.Errorf("error installing package %q: %v: %s", e.Name, err, string(output))
+			}
 		} else {
 			var args []string
 			env := os.Environ()
@@ -301,7 +341,12 @@ func (_ *Package) RenderLocal(t *local.LocalTarget, a, e, changes *Package) erro
 				args = []string{"apt-get", "install", "--yes", e.Name}
 				env = append(env, "DEBIAN_FRONTEND=noninteractive")
 			} else if t.HasTag(tags.TagOSFamilyRHEL) {
-				args = []string{"/usr/bin/yum", "install", "-y", e.Name}
+				packageSpec := e.Name
+				if e.Version != nil {
+					// yum matches the spec against name-version-release, so this installs the latest matching release
+					packageSpec += "-" + *e.Version + "*"
+				}
+				args = []string{"/usr/bin/yum", "install", "-y", packageSpec}
 			} else {
 				return fmt.Errorf("unsupported package system")
 			}
@@ -344,6 +389,9 @@ func (_ *Package) RenderLocal(t *local.LocalTarget, a, e, changes *Package) erro
 
 func (_ *Package) RenderCloudInit(t *cloudinit.CloudInitTarget, a, e, changes *Package) error {
 	packageName := e.Name
+	if len(e.LocalFiles) != 0 {
+		return fmt.Errorf("installing package %q from local files is not supported with cloudinit", packageName)
+	}
 	if e.Source != nil {
 		localFile := path.Join(localPackageDir, packageName)
 		t.AddMkdirpCommand(localPackageDir, 0755)
@@ -0,0 +1,40 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodetasks
+
+import "testing"
+
+func Test_YumVersionMatches(t *testing.T) {
+	grid := []struct {
+		Installed string
+		Version   string
+		Expected  bool
+	}{
+		{Installed: "1.10.3", Version: "1.10", Expected: true},
+		{Installed: "1.10.3", Version: "1.10.3", Expected: true},
+		{Installed: "1.10", Version: "1.10", Expected: true},
+		{Installed: "1.11.0", Version: "1.10", Expected: false},
+		{Installed: "1.100.0", Version: "1.10", Expected: false},
+		{Installed: "17.03.2.ce", Version: "17.03.2.ce", Expected: true},
+	}
+	for _, g := range grid {
+		actual := yumVersionMatches(g.Installed, g.Version)
+		if actual != g.Expected {
+			t.Errorf("yumVersionMatches(%q, %q): got %v, expected %v", g.Installed, g.Version, actual, g.Expected)
+		}
+	}
+}
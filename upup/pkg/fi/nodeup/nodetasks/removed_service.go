@@ -0,0 +1,133 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodetasks
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/cloudinit"
+	"k8s.io/kops/upup/pkg/fi/nodeup/local"
+	"k8s.io/kops/upup/pkg/fi/nodeup/tags"
+)
+
+// RemovedService ensures a systemd service nodeup previously installed is no longer present: the service is
+// stopped and disabled, and its unit file and any other files it used are deleted
+type RemovedService struct {
+	Name string
+	// Files are the other files installed for the service, such as the script it runs
+	Files []string `json:"files,omitempty"`
+}
+
+var _ fi.Task = &RemovedService{}
+var _ fi.HasName = &RemovedService{}
+
+func (e *RemovedService) String() string {
+	return fmt.Sprintf("RemovedService: %s", e.Name)
+}
+
+func (e *RemovedService) GetName() *string {
+	return &e.Name
+}
+
+func (e *RemovedService) SetName(name string) {
+	glog.Fatalf("SetName not supported for RemovedService task")
+}
+
+// paths returns the unit file and the other files of the service
+func (e *RemovedService) paths(target tags.HasTags) ([]string, error) {
+	systemdSystemPath, err := (&Service{Name: e.Name}).systemdSystemPath(target)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{path.Join(systemdSystemPath, e.Name)}, e.Files...), nil
+}
+
+// Find returns the task if the service has been removed, or nil if any of its files are still present
+func (e *RemovedService) Find(c *fi.Context) (*RemovedService, error) {
+	paths, err := e.paths(c.Target.(tags.HasTags))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range paths {
+		if _, err := os.Stat(p); err == nil {
+			return nil, nil
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error checking for file %q: %v", p, err)
+		}
+	}
+
+	actual := &RemovedService{
+		Name:  e.Name,
+		Files: e.Files,
+	}
+	return actual, nil
+}
+
+func (e *RemovedService) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(e, c)
+}
+
+func (_ *RemovedService) CheckChanges(a, e, changes *RemovedService) error {
+	if e.Name == "" {
+		return fi.RequiredField("Name")
+	}
+	return nil
+}
+
+func (_ *RemovedService) RenderLocal(t *local.LocalTarget, a, e, changes *RemovedService) error {
+	paths, err := e.paths(t)
+	if err != nil {
+		return err
+	}
+
+	glog.Infof("Removing service %q", e.Name)
+	for _, action := range []string{"stop", "disable"} {
+		// The service may already have been stopped or disabled, or its unit file removed
+		if output, err := exec.Command("systemctl", action, e.Name).CombinedOutput(); err != nil {
+			glog.Warningf("error doing systemd %s %s (ignoring): %v\nOutput: %s", action, e.Name, err, output)
+		}
+	}
+
+	for _, p := range paths {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error removing file %q: %v", p, err)
+		}
+	}
+
+	glog.Infof("Reloading systemd configuration")
+	output, err := exec.Command("systemctl", "daemon-reload").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error doing systemd daemon-reload: %v\nOutput: %s", err, output)
+	}
+	return nil
+}
+
+func (_ *RemovedService) RenderCloudInit(t *cloudinit.CloudInitTarget, a, e, changes *RemovedService) error {
+	paths, err := e.paths(t)
+	if err != nil {
+		return err
+	}
+
+	t.AddCommand(cloudinit.Once, append([]string{"rm", "-f"}, paths...)...)
+	return nil
+}
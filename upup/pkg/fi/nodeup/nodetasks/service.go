@@ -70,9 +70,9 @@ func (p *Service) GetDependencies(tasks map[string]fi.Task) []fi.Task {
 		// launching a custom Kubernetes build), they all depend on
 		// the "docker.service" Service task.
 		switch v.(type) {
-		case *File, *Package, *UpdatePackages, *UserTask, *MountDiskTask:
+		case *File, *Package, *UpdatePackages, *UserTask, *MountDiskTask, *KernelModule, *KernelParameter:
 			deps = append(deps, v)
-		case *Service, *LoadImageTask:
+		case *Service, *RemovedService, *LoadImageTask, *HealthCheck:
 			// ignore
 		default:
 			glog.Warningf("Unhandled type %T in Service::GetDependencies: %v", v, v)
@@ -142,14 +142,23 @@ func getSystemdStatus(name string) (map[string]string, error) {
 	return properties, nil
 }
 
+// GetServiceState returns the systemd ActiveState (e.g. active or failed) and SubState (e.g. running) of a unit
+func GetServiceState(name string) (string, string, error) {
+	properties, err := getSystemdStatus(name)
+	if err != nil {
+		return "", "", err
+	}
+	return properties["ActiveState"], properties["SubState"], nil
+}
+
 func (e *Service) systemdSystemPath(target tags.HasTags) (string, error) {
 	if target.HasTag(tags.TagOSFamilyDebian) {
 		return debianSystemdSystemPath, nil
 	} else if target.HasTag(tags.TagOSFamilyRHEL) {
 		return centosSystemdSystemPath, nil
-	} else if target.HasTag("_coreos") {
+	} else if target.HasTag(tags.TagOSFamilyCoreOS) {
 		return coreosSystemdSystemPath, nil
-	} else if target.HasTag("_containeros") {
+	} else if target.HasTag(tags.TagOSFamilyContainerOS) {
 		return containerosSystemdSystemPath, nil
 	} else {
 		return "", fmt.Errorf("unsupported systemd system")
@@ -210,7 +219,7 @@ func (e *Service) Find(c *fi.Context) (*Service, error) {
 		actual.Enabled = fi.Bool(false)
 
 	// TODO: Can probably do better here!
-	case "multi-user.target", "graphical.target multi-user.target":
+	case "multi-user.target", "graphical.target multi-user.target", "timers.target":
 		actual.Enabled = fi.Bool(true)
 
 	default:
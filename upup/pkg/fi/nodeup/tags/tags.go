@@ -17,8 +17,10 @@ limitations under the License.
 package tags
 
 const (
-	TagOSFamilyRHEL   = "_rhel_family"
-	TagOSFamilyDebian = "_debian_family"
+	TagOSFamilyRHEL        = "_rhel_family"
+	TagOSFamilyDebian      = "_debian_family"
+	TagOSFamilyCoreOS      = "_coreos_family"
+	TagOSFamilyContainerOS = "_containeros_family"
 
 	TagSystemd = "_systemd"
 )
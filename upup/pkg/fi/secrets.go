@@ -21,6 +21,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"strings"
+	"time"
 
 	"k8s.io/kops/util/pkg/vfs"
 )
@@ -43,8 +44,59 @@ type SecretStore interface {
 	MirrorTo(basedir vfs.Path) error
 }
 
+// SecretType describes the contents of a Secret, and so how it can be generated and rotated
+type SecretType string
+
+const (
+	// SecretTypeGeneric is an opaque secret; it is the type of secrets that predate typed secrets
+	SecretTypeGeneric SecretType = "Generic"
+	// SecretTypeStaticToken is a random token, for example a token in the apiserver static token file
+	SecretTypeStaticToken SecretType = "StaticToken"
+	// SecretTypeEncryptionConfig is the apiserver encryption-at-rest configuration, holding the encryption keys
+	SecretTypeEncryptionConfig SecretType = "EncryptionConfig"
+	// SecretTypeDockerConfig is a docker config.json, holding registry credentials
+	SecretTypeDockerConfig SecretType = "DockerConfig"
+)
+
+// SecretTypes is the list of all known secret types
+var SecretTypes = []SecretType{SecretTypeGeneric, SecretTypeStaticToken, SecretTypeEncryptionConfig, SecretTypeDockerConfig}
+
+// ParseSecretType parses a secret type, matching case-insensitively
+func ParseSecretType(s string) (SecretType, error) {
+	for _, t := range SecretTypes {
+		if strings.EqualFold(string(t), s) {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown secret type %q", s)
+}
+
 type Secret struct {
 	Data []byte
+
+	// Type is the type of the secret; secrets without a type are SecretTypeGeneric
+	Type SecretType `json:",omitempty"`
+	// Created is the time the secret was first created, if known
+	Created *time.Time `json:",omitempty"`
+	// Rotated is the time the secret was last rotated, if it has been rotated
+	Rotated *time.Time `json:",omitempty"`
+	// Expires is the time after which the secret should be rotated, if set
+	Expires *time.Time `json:",omitempty"`
+	// RotationPhase records the progress of a multi-step rotation; it is empty when no rotation is in progress
+	RotationPhase string `json:",omitempty"`
+}
+
+// SecretType returns the type of the secret, defaulting to SecretTypeGeneric
+func (s *Secret) SecretType() SecretType {
+	if s.Type == "" {
+		return SecretTypeGeneric
+	}
+	return s.Type
+}
+
+// IsExpired returns true if the secret has an expiry time, and that time has passed
+func (s *Secret) IsExpired(now time.Time) bool {
+	return s.Expires != nil && !now.Before(*s.Expires)
 }
 
 func (s *Secret) AsString() (string, error) {
@@ -68,7 +120,25 @@ func CreateSecret() (*Secret, error) {
 	s = r.Replace(s)
 	s = s[:32]
 
+	return NewSecret(SecretTypeGeneric, []byte(s)), nil
+}
+
+// NewSecret builds a Secret of the specified type, recording the creation time
+func NewSecret(secretType SecretType, data []byte) *Secret {
+	now := time.Now().UTC().Truncate(time.Second)
 	return &Secret{
-		Data: []byte(s),
-	}, nil
+		Data:    data,
+		Type:    secretType,
+		Created: &now,
+	}
+}
+
+// CreateStaticToken creates a new random SecretTypeStaticToken secret
+func CreateStaticToken() (*Secret, error) {
+	s, err := CreateSecret()
+	if err != nil {
+		return nil, err
+	}
+	s.Type = SecretTypeStaticToken
+	return s, nil
 }
@@ -37,6 +37,19 @@ import (
 // NamePrefix is a prefix we use to avoid collisions with other keysets
 const NamePrefix = "token-"
 
+const (
+	// annotationSecretType records the fi.SecretType of the secret on the Keyset
+	annotationSecretType = "kops.k8s.io/secret-type"
+	// annotationSecretCreated records the time the secret was created
+	annotationSecretCreated = "kops.k8s.io/secret-created"
+	// annotationSecretRotated records the time the secret was last rotated
+	annotationSecretRotated = "kops.k8s.io/secret-rotated"
+	// annotationSecretExpires records the time after which the secret should be rotated
+	annotationSecretExpires = "kops.k8s.io/secret-expires"
+	// annotationSecretRotationPhase records the progress of an in-progress rotation
+	annotationSecretRotationPhase = "kops.k8s.io/secret-rotation-phase"
+)
+
 // ClientsetSecretStore is a SecretStore backed by Keyset objects in an API server
 type ClientsetSecretStore struct {
 	cluster   *kops.Cluster
@@ -69,17 +82,17 @@ func (c *ClientsetSecretStore) MirrorTo(basedir vfs.Path) error {
 			continue
 		}
 
-		primary := fi.FindPrimary(keyset)
-		if primary == nil {
+		s, err := parseSecret(keyset)
+		if err != nil {
+			return err
+		}
+		if s == nil {
 			return fmt.Errorf("found secret with no primary data: %s", keyset.Name)
 		}
 
 		name := strings.TrimPrefix(keyset.Name, NamePrefix)
 		p := BuildVfsSecretPath(basedir, name)
 
-		s := &fi.Secret{
-			Data: primary.PrivateMaterial,
-		}
 		data, err := json.Marshal(s)
 		if err != nil {
 			return fmt.Errorf("error serializing secret: %v", err)
@@ -144,6 +157,7 @@ func (c *ClientsetSecretStore) Secret(name string) (*fi.Secret, error) {
 func (c *ClientsetSecretStore) DeleteSecret(name string) error {
 	client := c.clientset.Keysets(c.namespace)
 
+	name = NamePrefix + name
 	keyset, err := client.Get(name, v1.GetOptions{})
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -238,13 +252,65 @@ func parseSecret(keyset *kops.Keyset) (*fi.Secret, error) {
 
 	s := &fi.Secret{}
 	s.Data = primary.PrivateMaterial
+
+	annotations := keyset.ObjectMeta.Annotations
+	if v := annotations[annotationSecretType]; v != "" {
+		s.Type = fi.SecretType(v)
+	}
+	var err error
+	if s.Created, err = parseSecretTime(annotations, annotationSecretCreated); err != nil {
+		return nil, err
+	}
+	if s.Rotated, err = parseSecretTime(annotations, annotationSecretRotated); err != nil {
+		return nil, err
+	}
+	if s.Expires, err = parseSecretTime(annotations, annotationSecretExpires); err != nil {
+		return nil, err
+	}
+	s.RotationPhase = annotations[annotationSecretRotationPhase]
+
 	return s, nil
 }
 
+// parseSecretTime parses the RFC3339 time in the specified annotation, returning nil if not set
+func parseSecretTime(annotations map[string]string, key string) (*time.Time, error) {
+	v := annotations[key]
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing annotation %s=%q: %v", key, v, err)
+	}
+	return &t, nil
+}
+
+// buildSecretAnnotations encodes the type and metadata of the secret as annotations
+func buildSecretAnnotations(s *fi.Secret) map[string]string {
+	annotations := make(map[string]string)
+	if s.Type != "" {
+		annotations[annotationSecretType] = string(s.Type)
+	}
+	for k, t := range map[string]*time.Time{
+		annotationSecretCreated: s.Created,
+		annotationSecretRotated: s.Rotated,
+		annotationSecretExpires: s.Expires,
+	} {
+		if t != nil {
+			annotations[k] = t.UTC().Format(time.RFC3339)
+		}
+	}
+	if s.RotationPhase != "" {
+		annotations[annotationSecretRotationPhase] = s.RotationPhase
+	}
+	return annotations
+}
+
 // createSecret will create the Secret, overwriting an existing secret if replace is true
 func (c *ClientsetSecretStore) createSecret(s *fi.Secret, name string, replace bool) (*kops.Keyset, error) {
 	keyset := &kops.Keyset{}
 	keyset.Name = NamePrefix + name
+	keyset.Annotations = buildSecretAnnotations(s)
 	keyset.Spec.Type = kops.SecretTypeSecret
 
 	t := time.Now().UnixNano()
@@ -256,6 +322,15 @@ func (c *ClientsetSecretStore) createSecret(s *fi.Secret, name string, replace b
 	})
 
 	if replace {
+		// Updates must specify the resourceVersion of the object they replace
+		existing, err := c.clientset.Keysets(c.namespace).Get(keyset.Name, v1.GetOptions{})
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return nil, fmt.Errorf("error reading keyset %q: %v", keyset.Name, err)
+			}
+			return c.clientset.Keysets(c.namespace).Create(keyset)
+		}
+		keyset.ResourceVersion = existing.ResourceVersion
 		return c.clientset.Keysets(c.namespace).Update(keyset)
 	}
 	return c.clientset.Keysets(c.namespace).Create(keyset)
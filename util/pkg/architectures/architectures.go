@@ -0,0 +1,57 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package architectures
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Architecture is a machine architecture, using the names from GOARCH
+type Architecture string
+
+const (
+	ArchitectureAmd64 Architecture = "amd64"
+	ArchitectureArm64 Architecture = "arm64"
+)
+
+// Default is the architecture of instance groups which do not specify one
+const Default = ArchitectureAmd64
+
+// All returns the supported architectures
+func All() []Architecture {
+	return []Architecture{ArchitectureAmd64, ArchitectureArm64}
+}
+
+// Parse returns the architecture with the specified name, e.g. arm64
+func Parse(s string) (Architecture, error) {
+	for _, a := range All() {
+		if string(a) == s {
+			return a, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported architecture %q", s)
+}
+
+// EnvVar returns the name of the environment variable which overrides an asset for the architecture.
+// For backwards compatibility, amd64 uses the plain name (e.g. NODEUP_URL), other architectures add a suffix (e.g. NODEUP_URL_ARM64)
+func (a Architecture) EnvVar(name string) string {
+	if a == ArchitectureAmd64 {
+		return name
+	}
+	return name + "_" + strings.ToUpper(string(a))
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfs
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// azureStorageAPIVersion is the version of the blob service REST API we speak
+const azureStorageAPIVersion = "2017-11-09"
+
+// AzureBlobClient is a minimal client for the Azure Blob Storage REST API, authenticating with a shared key
+type AzureBlobClient struct {
+	account    string
+	key        []byte
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewAzureBlobClient builds an AzureBlobClient from the standard azure storage environment variables:
+//   AZURE_STORAGE_ACCOUNT: the storage account name (required)
+//   AZURE_STORAGE_KEY: the base64 encoded shared key for the account (required)
+//   AZURE_STORAGE_BLOB_ENDPOINT: overrides the blob endpoint, for sovereign clouds or emulators (optional)
+func NewAzureBlobClient() (*AzureBlobClient, error) {
+	account := os.Getenv("AZURE_STORAGE_ACCOUNT")
+	if account == "" {
+		return nil, fmt.Errorf("AZURE_STORAGE_ACCOUNT cannot be empty")
+	}
+	key := os.Getenv("AZURE_STORAGE_KEY")
+	if key == "" {
+		return nil, fmt.Errorf("AZURE_STORAGE_KEY cannot be empty")
+	}
+
+	endpoint := os.Getenv("AZURE_STORAGE_BLOB_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "https://" + account + ".blob.core.windows.net"
+	} else {
+		glog.Infof("Found AZURE_STORAGE_BLOB_ENDPOINT=%q, using as azure blob endpoint", endpoint)
+	}
+
+	return newAzureBlobClient(account, key, endpoint, http.DefaultClient)
+}
+
+func newAzureBlobClient(account string, key string, endpoint string, httpClient *http.Client) (*AzureBlobClient, error) {
+	keyBytes, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("azure storage key is not valid base64: %v", err)
+	}
+
+	return &AzureBlobClient{
+		account:    account,
+		key:        keyBytes,
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		httpClient: httpClient,
+	}, nil
+}
+
+// do performs a signed request against the blob service.
+// resource is the container-relative path, e.g. "container/blob", query holds the query parameters
+func (c *AzureBlobClient) do(method string, resource string, query url.Values, headers http.Header, body []byte) (*http.Response, error) {
+	u := c.endpoint + "/" + escapeAzureResource(resource)
+	if len(query) != 0 {
+		u += "?" + query.Encode()
+	}
+
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, u, r)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureStorageAPIVersion)
+	if body != nil {
+		req.ContentLength = int64(len(body))
+		req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+
+	signature, err := c.sign(req, resource, query)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "SharedKey "+c.account+":"+signature)
+
+	glog.V(8).Infof("Performing azure blob request: %s %s", method, u)
+	return c.httpClient.Do(req)
+}
+
+// sign computes the SharedKey signature for the request
+// See https://docs.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key
+func (c *AzureBlobClient) sign(req *http.Request, resource string, query url.Values) (string, error) {
+	contentLength := req.Header.Get("Content-Length")
+	if contentLength == "0" {
+		contentLength = ""
+	}
+
+	var b bytes.Buffer
+	b.WriteString(req.Method + "\n")
+	for _, h := range []string{"Content-Encoding", "Content-Language"} {
+		b.WriteString(req.Header.Get(h) + "\n")
+	}
+	b.WriteString(contentLength + "\n")
+	for _, h := range []string{"Content-MD5", "Content-Type", "Date", "If-Modified-Since", "If-Match", "If-None-Match", "If-Unmodified-Since", "Range"} {
+		b.WriteString(req.Header.Get(h) + "\n")
+	}
+
+	// Canonicalized headers
+	var msHeaders []string
+	for k := range req.Header {
+		lower := strings.ToLower(k)
+		if strings.HasPrefix(lower, "x-ms-") {
+			msHeaders = append(msHeaders, lower)
+		}
+	}
+	sort.Strings(msHeaders)
+	for _, k := range msHeaders {
+		b.WriteString(k + ":" + strings.TrimSpace(req.Header.Get(k)) + "\n")
+	}
+
+	// Canonicalized resource
+	b.WriteString("/" + c.account + "/" + escapeAzureResource(resource))
+	var keys []string
+	for k := range query {
+		keys = append(keys, strings.ToLower(k))
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		values := append([]string{}, query[k]...)
+		sort.Strings(values)
+		b.WriteString("\n" + k + ":" + strings.Join(values, ","))
+	}
+
+	mac := hmac.New(sha256.New, c.key)
+	if _, err := mac.Write(b.Bytes()); err != nil {
+		return "", fmt.Errorf("error signing azure request: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// escapeAzureResource escapes each segment of a container/blob resource path
+func escapeAzureResource(resource string) string {
+	tokens := strings.Split(resource, "/")
+	for i := range tokens {
+		tokens[i] = url.PathEscape(tokens[i])
+	}
+	return strings.Join(tokens, "/")
+}
@@ -0,0 +1,460 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfs
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+// AzureBlobPath is a vfs path for Azure Blob Storage
+type AzureBlobPath struct {
+	client    *AzureBlobClient
+	container string
+	key       string
+	md5Hash   string
+}
+
+var _ Path = &AzureBlobPath{}
+var _ HasHash = &AzureBlobPath{}
+
+// AzureBlobACL is an ACL implementation for objects on Azure Blob Storage.
+// Azure does not support per-blob ACLs, so the ACL is applied to the container.
+type AzureBlobACL struct {
+	// PublicAccess is the container public access level: "" (private), "blob" or "container"
+	PublicAccess string
+}
+
+var _ ACL = &AzureBlobACL{}
+
+// azureReadBackoff is the backoff strategy for Azure Blob Storage read retries
+var azureReadBackoff = wait.Backoff{
+	Duration: time.Second,
+	Factor:   1.5,
+	Jitter:   0.1,
+	Steps:    4,
+}
+
+// azureWriteBackoff is the backoff strategy for Azure Blob Storage write retries
+var azureWriteBackoff = wait.Backoff{
+	Duration: time.Second,
+	Factor:   1.5,
+	Jitter:   0.1,
+	Steps:    5,
+}
+
+func NewAzureBlobPath(client *AzureBlobClient, container string, key string) *AzureBlobPath {
+	container = strings.TrimSuffix(container, "/")
+	key = strings.TrimPrefix(key, "/")
+
+	return &AzureBlobPath{
+		client:    client,
+		container: container,
+		key:       key,
+	}
+}
+
+func (p *AzureBlobPath) Path() string {
+	return "azureblob://" + p.container + "/" + p.key
+}
+
+func (p *AzureBlobPath) Container() string {
+	return p.container
+}
+
+func (p *AzureBlobPath) Key() string {
+	return p.key
+}
+
+func (p *AzureBlobPath) String() string {
+	return p.Path()
+}
+
+func (p *AzureBlobPath) resource() string {
+	return p.container + "/" + p.key
+}
+
+func (p *AzureBlobPath) Remove() error {
+	done, err := RetryWithBackoff(azureWriteBackoff, func() (bool, error) {
+		glog.V(8).Infof("removing file %s", p)
+
+		response, err := p.client.do("DELETE", p.resource(), nil, nil, nil)
+		if err != nil {
+			return false, fmt.Errorf("error deleting %s: %v", p, err)
+		}
+		defer response.Body.Close()
+
+		switch response.StatusCode {
+		case http.StatusAccepted, http.StatusOK:
+			return true, nil
+		case http.StatusNotFound:
+			return true, os.ErrNotExist
+		default:
+			return false, fmt.Errorf("error deleting %s: %s", p, readAzureError(response))
+		}
+	})
+	if err != nil {
+		return err
+	} else if done {
+		return nil
+	} else {
+		// Shouldn't happen - we always return a non-nil error with false
+		return wait.ErrWaitTimeout
+	}
+}
+
+func (p *AzureBlobPath) Join(relativePath ...string) Path {
+	args := []string{p.key}
+	args = append(args, relativePath...)
+	joined := path.Join(args...)
+	return &AzureBlobPath{
+		client:    p.client,
+		container: p.container,
+		key:       joined,
+	}
+}
+
+func (p *AzureBlobPath) WriteFile(data io.ReadSeeker, acl ACL) error {
+	return p.writeFile(data, acl, false)
+}
+
+// CreateFile writes the file contents, but only if the file does not already exist.
+// Unlike most other implementations, this is atomic: we rely on the If-None-Match precondition.
+func (p *AzureBlobPath) CreateFile(data io.ReadSeeker, acl ACL) error {
+	return p.writeFile(data, acl, true)
+}
+
+func (p *AzureBlobPath) writeFile(data io.ReadSeeker, acl ACL, create bool) error {
+	if acl != nil {
+		azureACL, ok := acl.(*AzureBlobACL)
+		if !ok {
+			return fmt.Errorf("write to %s with ACL of unexpected type %T", p, acl)
+		}
+		if err := p.setContainerACL(azureACL); err != nil {
+			return err
+		}
+	}
+
+	done, err := RetryWithBackoff(azureWriteBackoff, func() (bool, error) {
+		glog.V(4).Infof("Writing file %q", p)
+
+		if _, err := data.Seek(0, 0); err != nil {
+			return false, fmt.Errorf("error seeking to start of data stream for write to %s: %v", p, err)
+		}
+
+		body, err := ioutil.ReadAll(data)
+		if err != nil {
+			return false, fmt.Errorf("error reading from data stream: %v", err)
+		}
+
+		md5Hash := md5.Sum(body)
+
+		headers := make(http.Header)
+		headers.Set("x-ms-blob-type", "BlockBlob")
+		headers.Set("Content-Type", "application/octet-stream")
+		headers.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5Hash[:]))
+		if create {
+			headers.Set("If-None-Match", "*")
+		}
+
+		response, err := p.client.do("PUT", p.resource(), nil, headers, body)
+		if err != nil {
+			return false, fmt.Errorf("error writing %s: %v", p, err)
+		}
+		defer response.Body.Close()
+
+		switch response.StatusCode {
+		case http.StatusCreated, http.StatusOK:
+			return true, nil
+		case http.StatusConflict, http.StatusPreconditionFailed:
+			if create {
+				return true, os.ErrExist
+			}
+			return false, fmt.Errorf("error writing %s: %s", p, readAzureError(response))
+		default:
+			return false, fmt.Errorf("error writing %s: %s", p, readAzureError(response))
+		}
+	})
+	if err != nil {
+		return err
+	} else if done {
+		return nil
+	} else {
+		// Shouldn't happen - we always return a non-nil error with false
+		return wait.ErrWaitTimeout
+	}
+}
+
+// setContainerACL sets the public access level on the container
+func (p *AzureBlobPath) setContainerACL(acl *AzureBlobACL) error {
+	headers := make(http.Header)
+	if acl.PublicAccess != "" {
+		headers.Set("x-ms-blob-public-access", acl.PublicAccess)
+	}
+
+	query := url.Values{}
+	query.Set("restype", "container")
+	query.Set("comp", "acl")
+
+	glog.V(4).Infof("Setting public access %q on azure container %q", acl.PublicAccess, p.container)
+	response, err := p.client.do("PUT", p.container, query, headers, []byte{})
+	if err != nil {
+		return fmt.Errorf("error setting ACL on container %q: %v", p.container, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("error setting ACL on container %q: %s", p.container, readAzureError(response))
+	}
+	return nil
+}
+
+// ReadFile implements Path::ReadFile
+func (p *AzureBlobPath) ReadFile() ([]byte, error) {
+	var b bytes.Buffer
+	done, err := RetryWithBackoff(azureReadBackoff, func() (bool, error) {
+		b.Reset()
+		_, err := p.WriteTo(&b)
+		if err != nil {
+			if os.IsNotExist(err) {
+				// Not recoverable
+				return true, err
+			}
+			return false, err
+		}
+		// Success!
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	} else if done {
+		return b.Bytes(), nil
+	} else {
+		// Shouldn't happen - we always return a non-nil error with false
+		return nil, wait.ErrWaitTimeout
+	}
+}
+
+// WriteTo implements io.WriterTo
+func (p *AzureBlobPath) WriteTo(out io.Writer) (int64, error) {
+	glog.V(4).Infof("Reading file %q", p)
+
+	response, err := p.client.do("GET", p.resource(), nil, nil, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error fetching %s: %v", p, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return 0, os.ErrNotExist
+	}
+	if response.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("error fetching %s: %s", p, readAzureError(response))
+	}
+
+	if md5Hash := response.Header.Get("Content-MD5"); md5Hash != "" {
+		p.md5Hash = md5Hash
+	}
+
+	n, err := io.Copy(out, response.Body)
+	if err != nil {
+		return n, fmt.Errorf("error reading %s: %v", p, err)
+	}
+	return n, nil
+}
+
+func (p *AzureBlobPath) ReadDir() ([]Path, error) {
+	prefix := p.key
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return p.listPaths(prefix, "/")
+}
+
+func (p *AzureBlobPath) ReadTree() ([]Path, error) {
+	prefix := p.key
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	// No delimiter for recursive search
+	return p.listPaths(prefix, "")
+}
+
+// azureEnumerationResults is the XML response to the List Blobs operation
+type azureEnumerationResults struct {
+	Blobs struct {
+		Blob []struct {
+			Name       string `xml:"Name"`
+			Properties struct {
+				ContentMD5 string `xml:"Content-MD5"`
+			} `xml:"Properties"`
+		} `xml:"Blob"`
+		BlobPrefix []struct {
+			Name string `xml:"Name"`
+		} `xml:"BlobPrefix"`
+	} `xml:"Blobs"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+func (p *AzureBlobPath) listPaths(prefix string, delimiter string) ([]Path, error) {
+	var ret []Path
+	done, err := RetryWithBackoff(azureReadBackoff, func() (bool, error) {
+		var paths []Path
+		marker := ""
+		for {
+			query := url.Values{}
+			query.Set("restype", "container")
+			query.Set("comp", "list")
+			if prefix != "" {
+				query.Set("prefix", prefix)
+			}
+			if delimiter != "" {
+				query.Set("delimiter", delimiter)
+			}
+			if marker != "" {
+				query.Set("marker", marker)
+			}
+
+			glog.V(4).Infof("Listing objects in azure container %q with prefix %q", p.container, prefix)
+			response, err := p.client.do("GET", p.container, query, nil, nil)
+			if err != nil {
+				return false, fmt.Errorf("error listing %s: %v", p, err)
+			}
+
+			if response.StatusCode == http.StatusNotFound {
+				response.Body.Close()
+				return true, os.ErrNotExist
+			}
+			if response.StatusCode != http.StatusOK {
+				msg := readAzureError(response)
+				response.Body.Close()
+				return false, fmt.Errorf("error listing %s: %s", p, msg)
+			}
+
+			results := &azureEnumerationResults{}
+			err = xml.NewDecoder(response.Body).Decode(results)
+			response.Body.Close()
+			if err != nil {
+				return false, fmt.Errorf("error parsing listing of %s: %v", p, err)
+			}
+
+			for _, b := range results.Blobs.Blob {
+				if b.Name == prefix {
+					// Tolerate a directory that was created as a blob; see S3Path::ReadDir
+					glog.V(4).Infof("Skipping read of directory: %q", b.Name)
+					continue
+				}
+				child := &AzureBlobPath{
+					client:    p.client,
+					container: p.container,
+					key:       b.Name,
+					md5Hash:   b.Properties.ContentMD5,
+				}
+				paths = append(paths, child)
+			}
+			for _, d := range results.Blobs.BlobPrefix {
+				child := &AzureBlobPath{
+					client:    p.client,
+					container: p.container,
+					key:       d.Name,
+				}
+				paths = append(paths, child)
+			}
+
+			marker = results.NextMarker
+			if marker == "" {
+				break
+			}
+		}
+		glog.V(8).Infof("Listed files in %v: %v", p, paths)
+		ret = paths
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	} else if done {
+		return ret, nil
+	} else {
+		// Shouldn't happen - we always return a non-nil error with false
+		return nil, wait.ErrWaitTimeout
+	}
+}
+
+func (p *AzureBlobPath) Base() string {
+	return path.Base(p.key)
+}
+
+func (p *AzureBlobPath) PreferredHash() (*hashing.Hash, error) {
+	return p.Hash(hashing.HashAlgorithmMD5)
+}
+
+func (p *AzureBlobPath) Hash(a hashing.HashAlgorithm) (*hashing.Hash, error) {
+	if a != hashing.HashAlgorithmMD5 {
+		return nil, nil
+	}
+
+	if p.md5Hash == "" {
+		return nil, nil
+	}
+
+	md5Bytes, err := base64.StdEncoding.DecodeString(p.md5Hash)
+	if err != nil {
+		return nil, fmt.Errorf("Content-MD5 was not a valid MD5 sum: %q", p.md5Hash)
+	}
+
+	return &hashing.Hash{Algorithm: hashing.HashAlgorithmMD5, HashValue: md5Bytes}, nil
+}
+
+// RemoteHash implements HasRemoteHash, issuing a HEAD request for the Content-MD5
+func (p *AzureBlobPath) RemoteHash() (*hashing.Hash, error) {
+	response, err := p.client.do("HEAD", p.resource(), nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting metadata for %s: %v", p, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, os.ErrNotExist
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error getting metadata for %s: %s", p, readAzureError(response))
+	}
+
+	p.md5Hash = response.Header.Get("Content-MD5")
+	return p.Hash(hashing.HashAlgorithmMD5)
+}
+
+// readAzureError returns a description of a failed azure response, including the error body
+func readAzureError(response *http.Response) string {
+	body, _ := ioutil.ReadAll(response.Body)
+	return fmt.Sprintf("unexpected response code %q: %s", response.Status, string(body))
+}
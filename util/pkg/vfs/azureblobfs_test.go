@@ -0,0 +1,214 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfs
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+// fakeAzureBlobServer is a minimal in-memory implementation of the blob service REST API
+type fakeAzureBlobServer struct {
+	t     *testing.T
+	mutex sync.Mutex
+	blobs map[string][]byte
+}
+
+func (f *fakeAzureBlobServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey testaccount:") {
+		f.t.Errorf("request was not signed: %s %s", r.Method, r.URL)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	query := r.URL.Query()
+
+	switch {
+	case r.Method == "GET" && query.Get("comp") == "list":
+		f.list(w, key, query.Get("prefix"), query.Get("delimiter"))
+
+	case r.Method == "PUT":
+		if r.Header.Get("If-None-Match") == "*" && f.blobs[key] != nil {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		f.blobs[key] = body
+		w.WriteHeader(http.StatusCreated)
+
+	case r.Method == "GET" || r.Method == "HEAD":
+		body := f.blobs[key]
+		if body == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		sum := md5.Sum(body)
+		w.Header().Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+		if r.Method == "GET" {
+			w.Write(body)
+		}
+
+	case r.Method == "DELETE":
+		if f.blobs[key] == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.blobs, key)
+		w.WriteHeader(http.StatusAccepted)
+
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func (f *fakeAzureBlobServer) list(w http.ResponseWriter, container string, prefix string, delimiter string) {
+	results := &azureEnumerationResults{}
+	prefixes := make(map[string]bool)
+	var keys []string
+	for k := range f.blobs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		name := strings.TrimPrefix(k, container+"/")
+		if !strings.HasPrefix(k, container+"/") || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if delimiter != "" {
+			if i := strings.Index(name[len(prefix):], delimiter); i != -1 {
+				dir := name[:len(prefix)+i+1]
+				if !prefixes[dir] {
+					prefixes[dir] = true
+					results.Blobs.BlobPrefix = append(results.Blobs.BlobPrefix, struct {
+						Name string `xml:"Name"`
+					}{Name: dir})
+				}
+				continue
+			}
+		}
+		blob := struct {
+			Name       string `xml:"Name"`
+			Properties struct {
+				ContentMD5 string `xml:"Content-MD5"`
+			} `xml:"Properties"`
+		}{Name: name}
+		sum := md5.Sum(f.blobs[k])
+		blob.Properties.ContentMD5 = base64.StdEncoding.EncodeToString(sum[:])
+		results.Blobs.Blob = append(results.Blobs.Blob, blob)
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	if err := xml.NewEncoder(w).Encode(results); err != nil {
+		f.t.Errorf("error encoding listing: %v", err)
+	}
+}
+
+func newFakeAzureBlobClient(t *testing.T) (*AzureBlobClient, func()) {
+	server := httptest.NewServer(&fakeAzureBlobServer{t: t, blobs: make(map[string][]byte)})
+	key := base64.StdEncoding.EncodeToString([]byte("secret"))
+	client, err := newAzureBlobClient("testaccount", key, server.URL, server.Client())
+	if err != nil {
+		t.Fatalf("error building client: %v", err)
+	}
+	return client, server.Close
+}
+
+func Test_AzureBlobPath_Conformance(t *testing.T) {
+	client, cleanup := newFakeAzureBlobClient(t)
+	defer cleanup()
+
+	testPathConformance(t, NewAzureBlobPath(client, "container", "state"))
+}
+
+func Test_AzureBlobPath_Hash(t *testing.T) {
+	client, cleanup := newFakeAzureBlobClient(t)
+	defer cleanup()
+
+	base := NewAzureBlobPath(client, "container", "state")
+	if err := base.Join("file").WriteFile(strings.NewReader("contents"), nil); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+
+	children, err := base.ReadDir()
+	if err != nil {
+		t.Fatalf("error reading dir: %v", err)
+	}
+	if len(children) != 1 {
+		t.Fatalf("unexpected children: %v", children)
+	}
+
+	hash, err := children[0].(HasHash).PreferredHash()
+	if err != nil {
+		t.Fatalf("error getting hash: %v", err)
+	}
+	expected, err := hashing.HashAlgorithmMD5.Hash(strings.NewReader("contents"))
+	if err != nil {
+		t.Fatalf("error hashing: %v", err)
+	}
+	if hash == nil || !hash.Equal(expected) {
+		t.Fatalf("unexpected hash %v, expected %v", hash, expected)
+	}
+}
+
+func Test_AzureBlobPath_Parse(t *testing.T) {
+	grid := []struct {
+		Input             string
+		ExpectError       bool
+		ExpectedContainer string
+		ExpectedPath      string
+	}{
+		{
+			Input:             "azureblob://container/path/subpath",
+			ExpectedContainer: "container",
+			ExpectedPath:      "path/subpath",
+		},
+		{
+			Input:       "azureblob:///path",
+			ExpectError: true,
+		},
+	}
+	for _, g := range grid {
+		c := &VFSContext{azureBlobClient: &AzureBlobClient{}}
+		p, err := c.buildAzureBlobPath(g.Input)
+		if g.ExpectError {
+			if err == nil {
+				t.Fatalf("expected error parsing %q", g.Input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error parsing %q: %v", g.Input, err)
+		}
+		if p.Container() != g.ExpectedContainer || p.Key() != g.ExpectedPath {
+			t.Fatalf("unexpected azure blob path: %v", p)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+// VFSCache is a local, content-addressed cache of files.
+// Entries are stored under <dir>/<algorithm>/<hex>, so an entry is shared by every path with the same contents,
+// and an entry can never be stale: it is only served when the remote store reports a matching hash.
+type VFSCache struct {
+	dir string
+}
+
+// NewVFSCache builds a VFSCache storing its entries under dir
+func NewVFSCache(dir string) *VFSCache {
+	return &VFSCache{dir: dir}
+}
+
+func (c *VFSCache) pathFor(hash *hashing.Hash) string {
+	return filepath.Join(c.dir, string(hash.Algorithm), hash.Hex())
+}
+
+// Get returns the cached contents for the hash, or nil if they are not cached.
+// Entries which don't match their hash (e.g. a partial write) are removed.
+func (c *VFSCache) Get(hash *hashing.Hash) ([]byte, error) {
+	p := c.pathFor(hash)
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading cache entry %q: %v", p, err)
+	}
+
+	actual, err := hash.Algorithm.Hash(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if !actual.Equal(hash) {
+		glog.Warningf("removing corrupt cache entry %q", p)
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error removing corrupt cache entry %q: %v", p, err)
+		}
+		return nil, nil
+	}
+
+	return data, nil
+}
+
+// Put stores the contents in the cache, if they match the hash
+func (c *VFSCache) Put(hash *hashing.Hash, data []byte) error {
+	actual, err := hash.Algorithm.Hash(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if !actual.Equal(hash) {
+		return fmt.Errorf("not caching contents with hash %s as %s was expected", actual, hash)
+	}
+
+	return c.writeEntry(hash, bytes.NewReader(data))
+}
+
+// CopyFile copies the cached contents for the hash to dest, returning false if they are not cached
+func (c *VFSCache) CopyFile(hash *hashing.Hash, dest string) (bool, error) {
+	p := c.pathFor(hash)
+	actual, err := hash.Algorithm.HashFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error hashing cache entry %q: %v", p, err)
+	}
+	if !actual.Equal(hash) {
+		glog.Warningf("ignoring corrupt cache entry %q", p)
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return false, fmt.Errorf("error creating directories for %q: %v", dest, err)
+	}
+
+	in, err := os.Open(p)
+	if err != nil {
+		return false, fmt.Errorf("error opening cache entry %q: %v", p, err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return false, fmt.Errorf("error creating %q: %v", dest, err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return false, fmt.Errorf("error copying cache entry %q to %q: %v", p, dest, err)
+	}
+	return true, nil
+}
+
+// AddFile stores the file src in the cache; the caller is responsible for having verified its hash
+func (c *VFSCache) AddFile(hash *hashing.Hash, src string) error {
+	p := c.pathFor(hash)
+	if _, err := os.Stat(p); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return fmt.Errorf("error creating cache directory: %v", err)
+	}
+
+	// Prefer a hard link, so we don't store large assets twice
+	if err := os.Link(src, p); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("error opening %q: %v", src, err)
+	}
+	defer in.Close()
+
+	return c.writeEntry(hash, in)
+}
+
+// writeEntry atomically writes a cache entry, via a temporary file
+func (c *VFSCache) writeEntry(hash *hashing.Hash, r io.Reader) error {
+	p := c.pathFor(hash)
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return fmt.Errorf("error creating cache directory: %v", err)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(p), ".tmp-")
+	if err != nil {
+		return fmt.Errorf("error creating temporary cache file: %v", err)
+	}
+	_, err = io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), p)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("error writing cache entry %q: %v", p, err)
+	}
+	return nil
+}
+
+// CachingPath is a Path which serves reads from a VFSCache, when the remote store reports a matching hash
+type CachingPath struct {
+	cache *VFSCache
+	path  Path
+}
+
+var _ Path = &CachingPath{}
+var _ HasHash = &CachingPath{}
+var _ HasClusterReadable = &CachingPath{}
+
+// NewCachingPath wraps p so that reads are served from the cache where possible
+func NewCachingPath(p Path, cache *VFSCache) *CachingPath {
+	return &CachingPath{cache: cache, path: p}
+}
+
+// Unwrap returns the underlying path, if p is a CachingPath, otherwise p
+func Unwrap(p Path) Path {
+	if c, ok := p.(*CachingPath); ok {
+		return c.path
+	}
+	return p
+}
+
+func (p *CachingPath) Join(relativePath ...string) Path {
+	return NewCachingPath(p.path.Join(relativePath...), p.cache)
+}
+
+// remoteHash returns the hash of the remote file, or nil if we can't determine it cheaply
+func (p *CachingPath) remoteHash() (*hashing.Hash, error) {
+	if hasHash, ok := p.path.(HasHash); ok {
+		hash, err := hasHash.PreferredHash()
+		if err != nil {
+			glog.V(4).Infof("unable to get hash for %s: %v", p.path, err)
+		} else if hash != nil {
+			return hash, nil
+		}
+	}
+	if hasRemoteHash, ok := p.path.(HasRemoteHash); ok {
+		return hasRemoteHash.RemoteHash()
+	}
+	return nil, nil
+}
+
+// ReadFile implements Path::ReadFile
+func (p *CachingPath) ReadFile() ([]byte, error) {
+	hash, err := p.remoteHash()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, err
+		}
+		glog.V(4).Infof("unable to get remote hash for %s, reading without cache: %v", p.path, err)
+		return p.path.ReadFile()
+	}
+	if hash == nil {
+		return p.path.ReadFile()
+	}
+
+	data, err := p.cache.Get(hash)
+	if err != nil {
+		glog.Warningf("error reading cache for %s: %v", p.path, err)
+	} else if data != nil {
+		glog.V(2).Infof("VFS cache hit for %s (%s)", p.path, hash)
+		return data, nil
+	}
+
+	data, err = p.path.ReadFile()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.cache.Put(hash, data); err != nil {
+		glog.V(2).Infof("not caching %s: %v", p.path, err)
+	}
+	return data, nil
+}
+
+// WriteTo implements io.WriterTo
+func (p *CachingPath) WriteTo(out io.Writer) (int64, error) {
+	data, err := p.ReadFile()
+	if err != nil {
+		return 0, err
+	}
+	n, err := out.Write(data)
+	return int64(n), err
+}
+
+func (p *CachingPath) WriteFile(data io.ReadSeeker, acl ACL) error {
+	return p.path.WriteFile(data, acl)
+}
+
+func (p *CachingPath) CreateFile(data io.ReadSeeker, acl ACL) error {
+	return p.path.CreateFile(data, acl)
+}
+
+func (p *CachingPath) Remove() error {
+	return p.path.Remove()
+}
+
+func (p *CachingPath) Base() string {
+	return p.path.Base()
+}
+
+func (p *CachingPath) Path() string {
+	return p.path.Path()
+}
+
+func (p *CachingPath) String() string {
+	return p.path.Path()
+}
+
+func (p *CachingPath) ReadDir() ([]Path, error) {
+	children, err := p.path.ReadDir()
+	if err != nil {
+		return nil, err
+	}
+	return p.wrap(children), nil
+}
+
+func (p *CachingPath) ReadTree() ([]Path, error) {
+	children, err := p.path.ReadTree()
+	if err != nil {
+		return nil, err
+	}
+	return p.wrap(children), nil
+}
+
+func (p *CachingPath) wrap(paths []Path) []Path {
+	var wrapped []Path
+	for _, child := range paths {
+		wrapped = append(wrapped, NewCachingPath(child, p.cache))
+	}
+	return wrapped
+}
+
+func (p *CachingPath) IsClusterReadable() bool {
+	return IsClusterReadable(p.path)
+}
+
+func (p *CachingPath) PreferredHash() (*hashing.Hash, error) {
+	hasHash, ok := p.path.(HasHash)
+	if !ok {
+		return nil, nil
+	}
+	return hasHash.PreferredHash()
+}
+
+func (p *CachingPath) Hash(a hashing.HashAlgorithm) (*hashing.Hash, error) {
+	hasHash, ok := p.path.(HasHash)
+	if !ok {
+		return nil, nil
+	}
+	return hasHash.Hash(a)
+}
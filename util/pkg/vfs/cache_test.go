@@ -0,0 +1,120 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+func Test_CachingPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vfscache")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	client, cleanup := newFakeAzureBlobClient(t)
+	defer cleanup()
+
+	cache := NewVFSCache(dir)
+	remote := NewAzureBlobPath(client, "container", "state")
+	cached := NewCachingPath(remote, cache)
+
+	if err := cached.Join("config").WriteFile(strings.NewReader("v1"), nil); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+
+	data, err := cached.Join("config").ReadFile()
+	if err != nil || string(data) != "v1" {
+		t.Fatalf("unexpected read result %q: %v", string(data), err)
+	}
+
+	hash, err := hashing.HashAlgorithmMD5.Hash(strings.NewReader("v1"))
+	if err != nil {
+		t.Fatalf("error hashing: %v", err)
+	}
+	entry := filepath.Join(dir, "md5", hash.Hex())
+	if _, err := os.Stat(entry); err != nil {
+		t.Fatalf("expected cache entry %q: %v", entry, err)
+	}
+
+	// A changed remote file must not be served from the cache
+	if err := remote.Join("config").WriteFile(strings.NewReader("v2"), nil); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	data, err = cached.Join("config").ReadFile()
+	if err != nil || string(data) != "v2" {
+		t.Fatalf("unexpected read result after change %q: %v", string(data), err)
+	}
+
+	// A corrupt entry is discarded rather than served
+	if err := ioutil.WriteFile(entry, []byte("corrupt"), 0600); err != nil {
+		t.Fatalf("error corrupting cache entry: %v", err)
+	}
+	cachedData, err := cache.Get(hash)
+	if err != nil || cachedData != nil {
+		t.Fatalf("expected corrupt entry to be discarded, got %q: %v", string(cachedData), err)
+	}
+
+	// Listed children carry their hashes, and are served from the cache
+	children, err := cached.ReadDir()
+	if err != nil {
+		t.Fatalf("error listing: %v", err)
+	}
+	if len(children) != 1 {
+		t.Fatalf("unexpected children: %v", children)
+	}
+	if _, ok := children[0].(*CachingPath); !ok {
+		t.Fatalf("expected listed child to be a CachingPath, was %T", children[0])
+	}
+	data, err = children[0].ReadFile()
+	if err != nil || string(data) != "v2" {
+		t.Fatalf("unexpected read result for listed child %q: %v", string(data), err)
+	}
+
+	if _, err := cached.Join("missing").ReadFile(); !os.IsNotExist(err) {
+		t.Fatalf("expected not-exist for missing file, got %v", err)
+	}
+}
+
+func Test_VFSContext_Cached(t *testing.T) {
+	c := &VFSContext{}
+	memfs := NewMemFSPath(NewMemFSContext(), "state")
+	if c.Cached(memfs) != Path(memfs) {
+		t.Fatalf("expected path to be unchanged when caching is disabled")
+	}
+
+	c.SetCache(NewVFSCache("/nonexistent"))
+	if c.Cached(memfs) != Path(memfs) {
+		t.Fatalf("expected memfs path not to be cached")
+	}
+
+	remote := NewAzureBlobPath(&AzureBlobClient{}, "container", "state")
+	cached := c.Cached(remote)
+	if _, ok := cached.(*CachingPath); !ok {
+		t.Fatalf("expected remote path to be cached, was %T", cached)
+	}
+	if Unwrap(cached) != Path(remote) {
+		t.Fatalf("expected Unwrap to return the underlying path")
+	}
+}
@@ -41,7 +41,7 @@ type VFSContext struct {
 	s3Context    *S3Context
 	k8sContext   *KubernetesContext
 	memfsContext *MemFSContext
-	// mutex guards gcsClient
+	// mutex guards gcsClient and azureBlobClient
 	mutex sync.Mutex
 	// The google cloud storage client, if initialized
 	gcsClient *storage.Service
@@ -49,6 +49,10 @@ type VFSContext struct {
 	swiftClient *gophercloud.ServiceClient
 	// ossClient is the Aliyun Open Source Storage client
 	ossClient *oss.Client
+	// azureBlobClient is the Azure Blob Storage client
+	azureBlobClient *AzureBlobClient
+	// cache is the local cache for reads, if enabled
+	cache *VFSCache
 }
 
 var Context = VFSContext{
@@ -98,7 +102,34 @@ func (c *VFSContext) ReadFile(location string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	return p.ReadFile()
+	return c.Cached(p).ReadFile()
+}
+
+// SetCache enables caching of reads in the specified cache; a nil cache disables caching
+func (c *VFSContext) SetCache(cache *VFSCache) {
+	c.cache = cache
+}
+
+// Cache returns the cache for reads, or nil if caching is not enabled
+func (c *VFSContext) Cache() *VFSCache {
+	return c.cache
+}
+
+// Cached returns a Path which serves reads from the cache, if caching is enabled and p is in a remote store.
+// Callers that need the concrete Path type (e.g. for ACLs) should use the path before wrapping, or Unwrap.
+func (c *VFSContext) Cached(p Path) Path {
+	if c.cache == nil {
+		return p
+	}
+
+	switch p.(type) {
+	case *FSPath, *MemFSPath, *CachingPath:
+		return p
+	}
+	if _, ok := p.(HasHash); !ok {
+		return p
+	}
+	return NewCachingPath(p, c.cache)
 }
 
 func (c *VFSContext) BuildVfsPath(p string) (Path, error) {
@@ -139,6 +170,10 @@ func (c *VFSContext) BuildVfsPath(p string) (Path, error) {
 		return c.buildOSSPath(p)
 	}
 
+	if strings.HasPrefix(p, "azureblob://") {
+		return c.buildAzureBlobPath(p)
+	}
+
 	return nil, fmt.Errorf("unknown / unhandled path type: %q", p)
 }
 
@@ -248,7 +283,18 @@ func (c *VFSContext) buildS3Path(p string) (*S3Path, error) {
 		return nil, fmt.Errorf("invalid s3 path: %q", p)
 	}
 
+	if u.RawQuery != "" {
+		config, err := ParseS3CompatibleConfig(u.Query())
+		if err != nil {
+			return nil, fmt.Errorf("invalid s3 path %q: %v", p, err)
+		}
+		if config != nil && c.s3Context != nil {
+			c.s3Context.SetBucketConfig(bucket, config)
+		}
+	}
+
 	s3path := newS3Path(c.s3Context, u.Scheme, bucket, u.Path, true)
+	s3path.query = u.Query().Encode()
 	return s3path, nil
 }
 
@@ -406,3 +452,32 @@ func (c *VFSContext) buildOSSPath(p string) (*OSSPath, error) {
 
 	return NewOSSPath(c.ossClient, bucket, u.Path)
 }
+
+func (c *VFSContext) buildAzureBlobPath(p string) (*AzureBlobPath, error) {
+	u, err := url.Parse(p)
+	if err != nil {
+		return nil, fmt.Errorf("invalid azure blob path: %q", p)
+	}
+
+	if u.Scheme != "azureblob" {
+		return nil, fmt.Errorf("invalid azure blob path: %q", p)
+	}
+
+	container := strings.TrimSuffix(u.Host, "/")
+	if container == "" {
+		return nil, fmt.Errorf("invalid azure blob path: %q", p)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.azureBlobClient == nil {
+		azureBlobClient, err := NewAzureBlobClient()
+		if err != nil {
+			return nil, err
+		}
+		c.azureBlobClient = azureBlobClient
+	}
+
+	return NewAzureBlobPath(c.azureBlobClient, container, u.Path), nil
+}
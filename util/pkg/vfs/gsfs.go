@@ -19,7 +19,6 @@ package vfs
 import (
 	"bytes"
 	"encoding/base64"
-	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
@@ -267,6 +266,15 @@ func (p *GSPath) ReadDir() ([]Path, error) {
 				}
 				paths = append(paths, child)
 			}
+			// Prefixes represent directories
+			for _, prefix := range page.Prefixes {
+				child := &GSPath{
+					client: p.client,
+					bucket: p.bucket,
+					key:    prefix,
+				}
+				paths = append(paths, child)
+			}
 			return nil
 		})
 		if err != nil {
@@ -351,7 +359,8 @@ func (p *GSPath) Hash(a hashing.HashAlgorithm) (*hashing.Hash, error) {
 		return nil, nil
 	}
 
-	md5Bytes, err := hex.DecodeString(md5)
+	// GCS reports the md5 base64 encoded
+	md5Bytes, err := base64.StdEncoding.DecodeString(md5)
 	if err != nil {
 		return nil, fmt.Errorf("Etag was not a valid MD5 sum: %q", md5)
 	}
@@ -359,6 +368,19 @@ func (p *GSPath) Hash(a hashing.HashAlgorithm) (*hashing.Hash, error) {
 	return &hashing.Hash{Algorithm: hashing.HashAlgorithmMD5, HashValue: md5Bytes}, nil
 }
 
+// RemoteHash implements HasRemoteHash, fetching the object metadata
+func (p *GSPath) RemoteHash() (*hashing.Hash, error) {
+	obj, err := p.client.Objects.Get(p.bucket, p.key).Do()
+	if err != nil {
+		if isGCSNotFound(err) {
+			return nil, os.ErrNotExist
+		}
+		return nil, fmt.Errorf("error getting metadata for %s: %v", p, err)
+	}
+	p.md5Hash = obj.Md5Hash
+	return p.Hash(hashing.HashAlgorithmMD5)
+}
+
 func isGCSNotFound(err error) bool {
 	if err == nil {
 		return false
@@ -0,0 +1,223 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfs
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	storage "google.golang.org/api/storage/v1"
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+func Test_GSPath_Hash(t *testing.T) {
+	data := []byte("hello world")
+	sum := md5.Sum(data)
+
+	// GCS reports the md5 of an object base64 encoded, e.g. XrY7u+Ae7tCTyyK7j1rNww==
+	p := &GSPath{bucket: "bucket", key: "key", md5Hash: base64.StdEncoding.EncodeToString(sum[:])}
+
+	hash, err := p.Hash(hashing.HashAlgorithmMD5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hash == nil {
+		t.Fatalf("expected hash")
+	}
+	if string(hash.HashValue) != string(sum[:]) {
+		t.Fatalf("unexpected hash %x, expected %x", hash.HashValue, sum)
+	}
+
+	// Other algorithms are not reported by GCS
+	hash, err = p.Hash(hashing.HashAlgorithmSHA256)
+	if err != nil || hash != nil {
+		t.Fatalf("unexpected result for sha256: %v, %v", hash, err)
+	}
+
+	p.md5Hash = "not-base64!"
+	if _, err := p.Hash(hashing.HashAlgorithmMD5); err == nil {
+		t.Fatalf("expected error for invalid md5")
+	}
+}
+
+// fakeGCSServer is a minimal in-memory implementation of the GCS JSON API
+type fakeGCSServer struct {
+	t       *testing.T
+	mutex   sync.Mutex
+	objects map[string][]byte
+}
+
+// RoundTrip implements http.RoundTripper, serving requests (including those to the fixed upload URL) in-process
+func (f *fakeGCSServer) RoundTrip(r *http.Request) (*http.Response, error) {
+	w := httptest.NewRecorder()
+	f.ServeHTTP(w, r)
+	return w.Result(), nil
+}
+
+func (f *fakeGCSServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	upload := strings.HasPrefix(r.URL.Path, "/upload/")
+	tokens := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 6)
+	// [upload/]storage/v1/b/<bucket>/o[/<object>]
+	if upload {
+		tokens = tokens[1:]
+	}
+	if len(tokens) < 5 || tokens[0] != "storage" || tokens[2] != "b" || tokens[4] != "o" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	bucket := tokens[3]
+	object := ""
+	if len(tokens) == 6 {
+		object = tokens[5]
+	}
+
+	switch {
+	case r.Method == "POST" && upload:
+		f.insert(w, r, bucket)
+
+	case r.Method == "GET" && object == "":
+		f.list(w, bucket, r.URL.Query().Get("prefix"), r.URL.Query().Get("delimiter"))
+
+	case r.Method == "GET":
+		body := f.objects[bucket+"/"+object]
+		if body == nil {
+			f.notFound(w)
+			return
+		}
+		if r.URL.Query().Get("alt") == "media" {
+			w.Write(body)
+			return
+		}
+		f.writeJSON(w, f.object(object, body))
+
+	case r.Method == "DELETE":
+		if f.objects[bucket+"/"+object] == nil {
+			f.notFound(w)
+			return
+		}
+		delete(f.objects, bucket+"/"+object)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// insert handles a multipart upload, of the object metadata followed by the media
+func (f *fakeGCSServer) insert(w http.ResponseWriter, r *http.Request, bucket string) {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		f.t.Errorf("error parsing upload content type: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	reader := multipart.NewReader(r.Body, params["boundary"])
+
+	obj := &storage.Object{}
+	part, err := reader.NextPart()
+	if err == nil {
+		err = json.NewDecoder(part).Decode(obj)
+	}
+	if err != nil {
+		f.t.Errorf("error reading upload metadata: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var body []byte
+	part, err = reader.NextPart()
+	if err == nil {
+		body, err = ioutil.ReadAll(part)
+	}
+	if err != nil {
+		f.t.Errorf("error reading upload media: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	f.objects[bucket+"/"+obj.Name] = body
+	f.writeJSON(w, f.object(obj.Name, body))
+}
+
+func (f *fakeGCSServer) list(w http.ResponseWriter, bucket string, prefix string, delimiter string) {
+	results := &storage.Objects{}
+	prefixes := make(map[string]bool)
+	var keys []string
+	for k := range f.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		name := strings.TrimPrefix(k, bucket+"/")
+		if !strings.HasPrefix(k, bucket+"/") || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if delimiter != "" {
+			if i := strings.Index(name[len(prefix):], delimiter); i != -1 {
+				dir := name[:len(prefix)+i+1]
+				if !prefixes[dir] {
+					prefixes[dir] = true
+					results.Prefixes = append(results.Prefixes, dir)
+				}
+				continue
+			}
+		}
+		results.Items = append(results.Items, f.object(name, f.objects[k]))
+	}
+
+	f.writeJSON(w, results)
+}
+
+func (f *fakeGCSServer) object(name string, body []byte) *storage.Object {
+	sum := md5.Sum(body)
+	return &storage.Object{Name: name, Md5Hash: base64.StdEncoding.EncodeToString(sum[:])}
+}
+
+func (f *fakeGCSServer) notFound(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	w.Write([]byte(`{"error":{"code":404,"message":"Not Found"}}`))
+}
+
+func (f *fakeGCSServer) writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		f.t.Errorf("error encoding response: %v", err)
+	}
+}
+
+func Test_GSPath_Conformance(t *testing.T) {
+	client, err := storage.New(&http.Client{Transport: &fakeGCSServer{t: t, objects: make(map[string][]byte)}})
+	if err != nil {
+		t.Fatalf("error building client: %v", err)
+	}
+
+	testPathConformance(t, NewGSPath(client, "bucket", "state"))
+}
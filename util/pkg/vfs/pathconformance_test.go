@@ -0,0 +1,101 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfs
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"testing"
+)
+
+// testPathConformance runs the common Path behaviour checks against a (empty) base path
+func testPathConformance(t *testing.T, base Path) {
+	a := base.Join("dir", "a")
+	b := base.Join("dir", "sub", "b")
+
+	if _, err := a.ReadFile(); !os.IsNotExist(err) {
+		t.Fatalf("expected not-exist reading %s, got %v", a, err)
+	}
+
+	if err := a.WriteFile(bytes.NewReader([]byte("hello")), nil); err != nil {
+		t.Fatalf("error writing %s: %v", a, err)
+	}
+	if err := b.CreateFile(bytes.NewReader([]byte("world")), nil); err != nil {
+		t.Fatalf("error creating %s: %v", b, err)
+	}
+	if err := b.CreateFile(bytes.NewReader([]byte("again")), nil); !os.IsExist(err) {
+		t.Fatalf("expected exists error creating %s a second time, got %v", b, err)
+	}
+
+	data, err := a.ReadFile()
+	if err != nil {
+		t.Fatalf("error reading %s: %v", a, err)
+	}
+	if string(data) != "hello" {
+		t.Fatalf("unexpected contents of %s: %q", a, string(data))
+	}
+
+	var buf bytes.Buffer
+	if _, err := b.WriteTo(&buf); err != nil {
+		t.Fatalf("error reading %s: %v", b, err)
+	}
+	if buf.String() != "world" {
+		t.Fatalf("unexpected contents of %s: %q", b, buf.String())
+	}
+
+	if a.Base() != "a" {
+		t.Fatalf("unexpected base name for %s: %q", a, a.Base())
+	}
+
+	tree, err := base.Join("dir").ReadTree()
+	if err != nil {
+		t.Fatalf("error reading tree: %v", err)
+	}
+	var relativePaths []string
+	for _, p := range tree {
+		relativePath, err := RelativePath(base, p)
+		if err != nil {
+			t.Fatalf("error computing relative path: %v", err)
+		}
+		relativePaths = append(relativePaths, relativePath)
+	}
+	sort.Strings(relativePaths)
+	if len(relativePaths) != 2 || relativePaths[0] != "dir/a" || relativePaths[1] != "dir/sub/b" {
+		t.Fatalf("unexpected tree: %v", relativePaths)
+	}
+
+	children, err := base.Join("dir").ReadDir()
+	if err != nil {
+		t.Fatalf("error reading dir: %v", err)
+	}
+	var names []string
+	for _, p := range children {
+		names = append(names, p.Base())
+	}
+	sort.Strings(names)
+	if len(names) != 2 || names[0] != "a" || names[1] != "sub" {
+		t.Fatalf("unexpected dir listing: %v", names)
+	}
+
+	if err := a.Remove(); err != nil {
+		t.Fatalf("error removing %s: %v", a, err)
+	}
+	if _, err := a.ReadFile(); !os.IsNotExist(err) {
+		t.Fatalf("expected not-exist reading %s after removal, got %v", a, err)
+	}
+}
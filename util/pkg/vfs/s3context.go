@@ -17,7 +17,10 @@ limitations under the License.
 package vfs
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"net/url"
 	"os"
 	"regexp"
 	"sync"
@@ -44,13 +47,113 @@ type S3Context struct {
 	mutex           sync.Mutex
 	clients         map[string]*s3.S3
 	bucketLocations map[string]string
+	// bucketConfigs holds the S3-compatible endpoint configuration for buckets not hosted on AWS
+	bucketConfigs map[string]*S3CompatibleConfig
+}
+
+// S3CompatibleConfig configures access to a non-AWS, S3-compatible endpoint (for example MinIO or Ceph)
+type S3CompatibleConfig struct {
+	// Endpoint is the URL of the S3-compatible service
+	Endpoint string
+	// Region is the region to sign requests for; most S3-compatible services accept any value
+	Region string
+	// ForcePathStyle uses path-style addressing (http://endpoint/bucket/key) rather than virtual hosts
+	ForcePathStyle bool
+	// CABundle is the path to a PEM file of CA certificates trusted for the endpoint
+	CABundle string
+}
+
+// ParseS3CompatibleConfig extracts the S3-compatible configuration from the query of a state-store URL,
+// for example s3://bucket/path?endpoint=https://minio:9000&region=us-east-1&pathStyle=true&caBundle=/etc/ca.pem
+// It returns nil if no endpoint is specified.
+func ParseS3CompatibleConfig(query url.Values) (*S3CompatibleConfig, error) {
+	endpoint := query.Get("endpoint")
+	if endpoint == "" {
+		for k := range query {
+			return nil, fmt.Errorf("s3 option %q is only supported with an endpoint", k)
+		}
+		return nil, nil
+	}
+
+	config := &S3CompatibleConfig{
+		Endpoint:       endpoint,
+		Region:         query.Get("region"),
+		ForcePathStyle: true,
+		CABundle:       query.Get("caBundle"),
+	}
+
+	for k := range query {
+		switch k {
+		case "endpoint", "region", "caBundle":
+		case "pathStyle":
+			switch query.Get(k) {
+			case "true":
+				config.ForcePathStyle = true
+			case "false":
+				config.ForcePathStyle = false
+			default:
+				return nil, fmt.Errorf("invalid value for pathStyle: %q", query.Get(k))
+			}
+		default:
+			return nil, fmt.Errorf("unknown s3 option %q", k)
+		}
+	}
+
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	return config, nil
+}
+
+// s3CompatibleConfigFromEnv returns the S3-compatible configuration from the S3_ENDPOINT environment variables,
+// or nil if S3_ENDPOINT is not set
+func s3CompatibleConfigFromEnv() *S3CompatibleConfig {
+	endpoint := os.Getenv("S3_ENDPOINT")
+	if endpoint == "" {
+		return nil
+	}
+
+	region := os.Getenv("S3_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &S3CompatibleConfig{
+		Endpoint:       endpoint,
+		Region:         region,
+		ForcePathStyle: true,
+		CABundle:       os.Getenv("S3_CA_BUNDLE"),
+	}
 }
 
 func NewS3Context() *S3Context {
 	return &S3Context{
 		clients:         make(map[string]*s3.S3),
 		bucketLocations: make(map[string]string),
+		bucketConfigs:   make(map[string]*S3CompatibleConfig),
+	}
+}
+
+// SetBucketConfig registers the S3-compatible endpoint to use for the bucket
+func (s *S3Context) SetBucketConfig(bucket string, config *S3CompatibleConfig) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.bucketConfigs[bucket] = config
+}
+
+// getBucketConfig returns the S3-compatible endpoint configuration for the bucket,
+// falling back to the S3_ENDPOINT environment variables; it returns nil for AWS buckets
+func (s *S3Context) getBucketConfig(bucket string) *S3CompatibleConfig {
+	s.mutex.Lock()
+	config := s.bucketConfigs[bucket]
+	s.mutex.Unlock()
+
+	if config != nil {
+		return config
 	}
+	return s3CompatibleConfigFromEnv()
 }
 
 func (s *S3Context) getClient(region string) (*s3.S3, error) {
@@ -59,47 +162,69 @@ func (s *S3Context) getClient(region string) (*s3.S3, error) {
 
 	s3Client := s.clients[region]
 	if s3Client == nil {
-		var config *aws.Config
-		var err error
-		endpoint := os.Getenv("S3_ENDPOINT")
-		if endpoint == "" {
-			config = aws.NewConfig().WithRegion(region)
-			config = config.WithCredentialsChainVerboseErrors(true)
-		} else {
-			// Use customized S3 storage
-			glog.Infof("Found S3_ENDPOINT=%q, using as non-AWS S3 backend", endpoint)
-			config, err = getCustomS3Config(endpoint, region)
+		config := aws.NewConfig().WithRegion(region)
+		config = config.WithCredentialsChainVerboseErrors(true)
+
+		sess, err := session.NewSession(config)
+		if err != nil {
+			return nil, fmt.Errorf("error starting new AWS session: %v", err)
+		}
+		s3Client = s3.New(sess, config)
+		s.clients[region] = s3Client
+	}
+
+	return s3Client, nil
+}
+
+// getCompatibleClient returns a client for an S3-compatible endpoint
+func (s *S3Context) getCompatibleClient(c *S3CompatibleConfig) (*s3.S3, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := fmt.Sprintf("%s|%s|%t|%s", c.Endpoint, c.Region, c.ForcePathStyle, c.CABundle)
+	s3Client := s.clients[key]
+	if s3Client == nil {
+		glog.Infof("Using %q as non-AWS S3 backend", c.Endpoint)
+		config, err := getCustomS3Config(c)
+		if err != nil {
+			return nil, err
+		}
+
+		options := session.Options{Config: *config}
+		if c.CABundle != "" {
+			caBundle, err := ioutil.ReadFile(c.CABundle)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("error reading S3 CA bundle %q: %v", c.CABundle, err)
 			}
+			options.CustomCABundle = bytes.NewReader(caBundle)
 		}
 
-		sess, err := session.NewSession(config)
+		sess, err := session.NewSessionWithOptions(options)
 		if err != nil {
 			return nil, fmt.Errorf("error starting new AWS session: %v", err)
 		}
 		s3Client = s3.New(sess, config)
-		s.clients[region] = s3Client
+		s.clients[key] = s3Client
 	}
 
 	return s3Client, nil
 }
 
-func getCustomS3Config(endpoint string, region string) (*aws.Config, error) {
+func getCustomS3Config(c *S3CompatibleConfig) (*aws.Config, error) {
 	accessKeyID := os.Getenv("S3_ACCESS_KEY_ID")
 	if accessKeyID == "" {
-		return nil, fmt.Errorf("S3_ACCESS_KEY_ID cannot be empty when S3_ENDPOINT is not empty")
+		return nil, fmt.Errorf("S3_ACCESS_KEY_ID cannot be empty when using S3 endpoint %q", c.Endpoint)
 	}
 	secretAccessKey := os.Getenv("S3_SECRET_ACCESS_KEY")
 	if secretAccessKey == "" {
-		return nil, fmt.Errorf("S3_SECRET_ACCESS_KEY cannot be empty when S3_ENDPOINT is not empty")
+		return nil, fmt.Errorf("S3_SECRET_ACCESS_KEY cannot be empty when using S3 endpoint %q", c.Endpoint)
 	}
 
 	s3Config := &aws.Config{
 		Credentials:      credentials.NewStaticCredentials(accessKeyID, secretAccessKey, ""),
-		Endpoint:         aws.String(endpoint),
-		Region:           aws.String(region),
-		S3ForcePathStyle: aws.Bool(true),
+		Endpoint:         aws.String(c.Endpoint),
+		Region:           aws.String(c.Region),
+		S3ForcePathStyle: aws.Bool(c.ForcePathStyle),
 	}
 	s3Config = s3Config.WithCredentialsChainVerboseErrors(true)
 
@@ -118,14 +243,9 @@ func (s *S3Context) getRegionForBucket(bucket string) (string, error) {
 	}
 
 	// Probe to find correct region for bucket
-	endpoint := os.Getenv("S3_ENDPOINT")
-	if endpoint != "" {
+	if config := s.getBucketConfig(bucket); config != nil {
 		// If customized S3 storage is set, return user-defined region
-		region = os.Getenv("S3_REGION")
-		if region == "" {
-			region = "us-east-1"
-		}
-		return region, nil
+		return config.Region, nil
 	}
 
 	awsRegion := os.Getenv("AWS_REGION")
@@ -45,6 +45,9 @@ type S3Path struct {
 	scheme string
 	// sse specifies if server side encryption should be enabled
 	sse bool
+	// query holds the options of an S3 compatible endpoint, encoded as in the URL (see ParseS3CompatibleConfig).
+	// We keep it in the path, so that other processes which build the path from Path() use the same endpoint.
+	query string
 }
 
 var _ Path = &S3Path{}
@@ -69,6 +72,14 @@ func newS3Path(s3Context *S3Context, scheme string, bucket string, key string, s
 }
 
 func (p *S3Path) Path() string {
+	if p.query != "" {
+		return p.unqualifiedPath() + "?" + p.query
+	}
+	return p.unqualifiedPath()
+}
+
+// unqualifiedPath returns the path without the options of an S3 compatible endpoint
+func (p *S3Path) unqualifiedPath() string {
 	return p.scheme + "://" + p.bucket + "/" + p.key
 }
 
@@ -116,6 +127,7 @@ func (p *S3Path) Join(relativePath ...string) Path {
 		key:       joined,
 		scheme:    p.scheme,
 		sse:       p.sse,
+		query:     p.query,
 	}
 }
 
@@ -264,6 +276,19 @@ func (p *S3Path) ReadDir() ([]Path, error) {
 				etag:      o.ETag,
 				scheme:    p.scheme,
 				sse:       p.sse,
+				query:     p.query,
+			}
+			paths = append(paths, child)
+		}
+		// CommonPrefixes represent directories
+		for _, d := range page.CommonPrefixes {
+			child := &S3Path{
+				s3Context: p.s3Context,
+				bucket:    p.bucket,
+				key:       aws.StringValue(d.Prefix),
+				scheme:    p.scheme,
+				sse:       p.sse,
+				query:     p.query,
 			}
 			paths = append(paths, child)
 		}
@@ -302,6 +327,7 @@ func (p *S3Path) ReadTree() ([]Path, error) {
 				etag:      o.ETag,
 				scheme:    p.scheme,
 				sse:       p.sse,
+				query:     p.query,
 			}
 			paths = append(paths, child)
 		}
@@ -314,6 +340,10 @@ func (p *S3Path) ReadTree() ([]Path, error) {
 }
 
 func (p *S3Path) client() (*s3.S3, error) {
+	if config := p.s3Context.getBucketConfig(p.bucket); config != nil {
+		return p.s3Context.getCompatibleClient(config)
+	}
+
 	var err error
 	if p.region == "" {
 		p.region, err = p.s3Context.getRegionForBucket(p.bucket)
@@ -357,6 +387,34 @@ func (p *S3Path) Hash(a hashing.HashAlgorithm) (*hashing.Hash, error) {
 	return &hashing.Hash{Algorithm: hashing.HashAlgorithmMD5, HashValue: md5Bytes}, nil
 }
 
+// RemoteHash implements HasRemoteHash, issuing a HEAD request for the ETag
+func (p *S3Path) RemoteHash() (*hashing.Hash, error) {
+	client, err := p.client()
+	if err != nil {
+		return nil, err
+	}
+
+	request := &s3.HeadObjectInput{}
+	request.Bucket = aws.String(p.bucket)
+	request.Key = aws.String(p.key)
+
+	response, err := client.HeadObject(request)
+	if err != nil {
+		if AWSErrorCode(err) == "NotFound" {
+			return nil, os.ErrNotExist
+		}
+		return nil, fmt.Errorf("error getting metadata for %s: %v", p, err)
+	}
+
+	// Multipart uploads (and some encryption modes) have ETags which are not the MD5 of the contents
+	if response.ETag == nil || strings.Contains(*response.ETag, "-") {
+		return nil, nil
+	}
+
+	p.etag = response.ETag
+	return p.Hash(hashing.HashAlgorithmMD5)
+}
+
 // AWSErrorCode returns the aws error code, if it is an awserr.Error, otherwise ""
 func AWSErrorCode(err error) string {
 	if awsError, ok := err.(awserr.Error); ok {
@@ -16,7 +16,19 @@ limitations under the License.
 
 package vfs
 
-import "testing"
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
 
 func Test_S3Path_Parse(t *testing.T) {
 	grid := []struct {
@@ -64,3 +76,236 @@ func Test_S3Path_Parse(t *testing.T) {
 		}
 	}
 }
+
+func Test_S3Path_CompatibleConfig(t *testing.T) {
+	grid := []struct {
+		Input          string
+		ExpectError    bool
+		ExpectedPath   string
+		ExpectedConfig *S3CompatibleConfig
+	}{
+		{
+			Input:        "s3://bucket/path",
+			ExpectedPath: "s3://bucket/path",
+		},
+		{
+			Input:        "s3://bucket/path?endpoint=https://minio.example.com:9000",
+			ExpectedPath: "s3://bucket/path?endpoint=https%3A%2F%2Fminio.example.com%3A9000",
+			ExpectedConfig: &S3CompatibleConfig{
+				Endpoint:       "https://minio.example.com:9000",
+				Region:         "us-east-1",
+				ForcePathStyle: true,
+			},
+		},
+		{
+			Input:        "s3://bucket/path?endpoint=https://ceph.example.com&region=default&pathStyle=false&caBundle=/etc/ceph-ca.pem",
+			ExpectedPath: "s3://bucket/path?caBundle=%2Fetc%2Fceph-ca.pem&endpoint=https%3A%2F%2Fceph.example.com&pathStyle=false&region=default",
+			ExpectedConfig: &S3CompatibleConfig{
+				Endpoint:       "https://ceph.example.com",
+				Region:         "default",
+				ForcePathStyle: false,
+				CABundle:       "/etc/ceph-ca.pem",
+			},
+		},
+		{
+			Input:       "s3://bucket/path?region=us-east-1",
+			ExpectError: true,
+		},
+		{
+			Input:       "s3://bucket/path?endpoint=https://minio&pathStyle=maybe",
+			ExpectError: true,
+		},
+		{
+			Input:       "s3://bucket/path?endpoint=https://minio&unknown=1",
+			ExpectError: true,
+		},
+	}
+	for _, g := range grid {
+		c := &VFSContext{s3Context: NewS3Context()}
+		s3path, err := c.buildS3Path(g.Input)
+		if g.ExpectError {
+			if err == nil {
+				t.Fatalf("expected error parsing %q", g.Input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error parsing %q: %v", g.Input, err)
+		}
+		if s3path.Path() != g.ExpectedPath {
+			t.Fatalf("unexpected s3 path %q for %q", s3path.Path(), g.Input)
+		}
+		config := c.s3Context.bucketConfigs[s3path.Bucket()]
+		if g.ExpectedConfig == nil {
+			if config != nil {
+				t.Fatalf("unexpected s3 config for %q: %v", g.Input, config)
+			}
+			continue
+		}
+		if config == nil || *config != *g.ExpectedConfig {
+			t.Fatalf("unexpected s3 config for %q: %v", g.Input, config)
+		}
+
+		// Another process which builds the path from Path() must use the same endpoint
+		child := s3path.Join("cluster", "config")
+		c2 := &VFSContext{s3Context: NewS3Context()}
+		rebuilt, err := c2.buildS3Path(child.Path())
+		if err != nil {
+			t.Fatalf("unexpected error parsing %q: %v", child.Path(), err)
+		}
+		if rebuilt.Path() != child.Path() || rebuilt.Key() != "path/cluster/config" {
+			t.Fatalf("path %q changed to %q when rebuilt", child.Path(), rebuilt.Path())
+		}
+		if config := c2.s3Context.bucketConfigs[rebuilt.Bucket()]; config == nil || *config != *g.ExpectedConfig {
+			t.Fatalf("unexpected s3 config for rebuilt path %q: %v", child.Path(), config)
+		}
+
+		relative, err := RelativePath(s3path, child)
+		if err != nil || relative != "cluster/config" {
+			t.Fatalf("unexpected relative path %q of %q (%v)", relative, child.Path(), err)
+		}
+	}
+}
+
+// fakeS3Server is a minimal in-memory implementation of a path-style S3-compatible service
+type fakeS3Server struct {
+	t       *testing.T
+	mutex   sync.Mutex
+	objects map[string][]byte
+}
+
+// fakeS3ListResult is the XML response to the ListObjects operation
+type fakeS3ListResult struct {
+	XMLName  xml.Name `xml:"ListBucketResult"`
+	Name     string   `xml:"Name"`
+	Prefix   string   `xml:"Prefix"`
+	Contents []struct {
+		Key  string `xml:"Key"`
+		ETag string `xml:"ETag"`
+	} `xml:"Contents"`
+	CommonPrefixes []struct {
+		Prefix string `xml:"Prefix"`
+	} `xml:"CommonPrefixes"`
+	IsTruncated bool `xml:"IsTruncated"`
+}
+
+func (f *fakeS3Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=testaccesskey/") {
+		f.t.Errorf("request was not signed: %s %s", r.Method, r.URL)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	tokens := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	bucket := tokens[0]
+	key := ""
+	if len(tokens) == 2 {
+		key = tokens[1]
+	}
+
+	switch {
+	case r.Method == "GET" && key == "":
+		f.list(w, bucket, r.URL.Query().Get("prefix"), r.URL.Query().Get("delimiter"))
+
+	case r.Method == "PUT":
+		body, _ := ioutil.ReadAll(r.Body)
+		f.objects[bucket+"/"+key] = body
+		w.WriteHeader(http.StatusOK)
+
+	case r.Method == "GET" || r.Method == "HEAD":
+		body := f.objects[bucket+"/"+key]
+		if body == nil {
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == "GET" {
+				w.Write([]byte("<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>"))
+			}
+			return
+		}
+		w.Header().Set("ETag", fakeS3ETag(body))
+		if r.Method == "GET" {
+			w.Write(body)
+		}
+
+	case r.Method == "DELETE":
+		delete(f.objects, bucket+"/"+key)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func (f *fakeS3Server) list(w http.ResponseWriter, bucket string, prefix string, delimiter string) {
+	results := &fakeS3ListResult{Name: bucket, Prefix: prefix}
+	prefixes := make(map[string]bool)
+	var keys []string
+	for k := range f.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		name := strings.TrimPrefix(k, bucket+"/")
+		if !strings.HasPrefix(k, bucket+"/") || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if delimiter != "" {
+			if i := strings.Index(name[len(prefix):], delimiter); i != -1 {
+				dir := name[:len(prefix)+i+1]
+				if !prefixes[dir] {
+					prefixes[dir] = true
+					results.CommonPrefixes = append(results.CommonPrefixes, struct {
+						Prefix string `xml:"Prefix"`
+					}{Prefix: dir})
+				}
+				continue
+			}
+		}
+		results.Contents = append(results.Contents, struct {
+			Key  string `xml:"Key"`
+			ETag string `xml:"ETag"`
+		}{Key: name, ETag: fakeS3ETag(f.objects[k])})
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	if err := xml.NewEncoder(w).Encode(results); err != nil {
+		f.t.Errorf("error encoding listing: %v", err)
+	}
+}
+
+func fakeS3ETag(body []byte) string {
+	sum := md5.Sum(body)
+	return "\"" + hex.EncodeToString(sum[:]) + "\""
+}
+
+func setEnv(t *testing.T, key string, value string) func() {
+	previous, found := os.LookupEnv(key)
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatalf("error setting %s: %v", key, err)
+	}
+	return func() {
+		if found {
+			os.Setenv(key, previous)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
+
+func Test_S3Path_CompatibleConformance(t *testing.T) {
+	server := httptest.NewServer(&fakeS3Server{t: t, objects: make(map[string][]byte)})
+	defer server.Close()
+
+	defer setEnv(t, "S3_ACCESS_KEY_ID", "testaccesskey")()
+	defer setEnv(t, "S3_SECRET_ACCESS_KEY", "testsecretkey")()
+
+	c := &VFSContext{s3Context: NewS3Context()}
+	base, err := c.buildS3Path("s3://bucket/state?endpoint=" + server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error parsing path: %v", err)
+	}
+
+	testPathConformance(t, base)
+}
@@ -81,9 +81,17 @@ type HasHash interface {
 	Hash(algorithm hashing.HashAlgorithm) (*hashing.Hash, error)
 }
 
+// HasRemoteHash is implemented by paths that can fetch the hash of the file from the remote store,
+// without downloading the file contents
+type HasRemoteHash interface {
+	// RemoteHash returns the hash of the file contents as reported by the store, or nil if it is not known.
+	// If the file does not exist, err = os.ErrNotExist
+	RemoteHash() (*hashing.Hash, error)
+}
+
 func RelativePath(base Path, child Path) (string, error) {
-	basePath := base.Path()
-	childPath := child.Path()
+	basePath := unqualifiedPath(base)
+	childPath := unqualifiedPath(child)
 	if !strings.HasSuffix(basePath, "/") {
 		basePath += "/"
 	}
@@ -96,13 +104,21 @@ func RelativePath(base Path, child Path) (string, error) {
 	return relativePath, nil
 }
 
+// unqualifiedPath returns the path without any options in its query, such as the endpoint of an S3 compatible store
+func unqualifiedPath(p Path) string {
+	if s3Path, ok := p.(*S3Path); ok {
+		return s3Path.unqualifiedPath()
+	}
+	return p.Path()
+}
+
 func IsClusterReadable(p Path) bool {
 	if hcr, ok := p.(HasClusterReadable); ok {
 		return hcr.IsClusterReadable()
 	}
 
 	switch p.(type) {
-	case *S3Path, *GSPath, *SwiftPath, *OSSPath:
+	case *S3Path, *GSPath, *SwiftPath, *OSSPath, *AzureBlobPath:
 		return true
 
 	case *KubernetesPath: